@@ -19,6 +19,7 @@ require (
 	github.com/go-ole/go-ole v1.2.4 // indirect
 	github.com/go-zeromq/zmq4 v0.12.0
 	github.com/gobuffalo/packr/v2 v2.8.0
+	github.com/golang/snappy v0.0.2-0.20190904063534-ff6b7dc882cf
 	github.com/google/go-github v17.0.0+incompatible // indirect
 	github.com/google/go-querystring v1.0.0 // indirect
 	github.com/gorilla/websocket v1.4.2
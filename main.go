@@ -11,6 +11,7 @@ import (
 	"github.com/gohornet/hornet/plugins/curl"
 	"github.com/gohornet/hornet/plugins/dashboard"
 	"github.com/gohornet/hornet/plugins/database"
+	"github.com/gohornet/hornet/plugins/fleet"
 	"github.com/gohornet/hornet/plugins/gossip"
 	"github.com/gohornet/hornet/plugins/gracefulshutdown"
 	"github.com/gohornet/hornet/plugins/metrics"
@@ -19,11 +20,14 @@ import (
 	"github.com/gohornet/hornet/plugins/pow"
 	"github.com/gohornet/hornet/plugins/profiling"
 	"github.com/gohornet/hornet/plugins/prometheus"
+	"github.com/gohornet/hornet/plugins/replica"
+	"github.com/gohornet/hornet/plugins/scheduler"
 	"github.com/gohornet/hornet/plugins/snapshot"
 	"github.com/gohornet/hornet/plugins/spammer"
 	"github.com/gohornet/hornet/plugins/tangle"
 	"github.com/gohornet/hornet/plugins/urts"
 	"github.com/gohornet/hornet/plugins/warpsync"
+	"github.com/gohornet/hornet/plugins/watchdog"
 	"github.com/gohornet/hornet/plugins/webapi"
 	"github.com/gohornet/hornet/plugins/zmq"
 )
@@ -40,12 +44,24 @@ func main() {
 		gracefulshutdown.PLUGIN,
 		profiling.PLUGIN,
 		database.PLUGIN,
+		scheduler.PLUGIN,
 		curl.PLUGIN,
 		autopeering.PLUGIN,
 		webapi.PLUGIN,
 	}
 
-	if !config.NodeConfig.GetBool(config.CfgNetAutopeeringRunAsEntryNode) {
+	if cli.IsSafeMode() {
+		// no gossip, no pruning and no snapshots in safe mode
+		plugins = []*node.Plugin{
+			cli.PLUGIN,
+			gracefulshutdown.PLUGIN,
+			profiling.PLUGIN,
+			database.PLUGIN,
+			scheduler.PLUGIN,
+			replica.PLUGIN,
+			webapi.PLUGIN,
+		}
+	} else if !config.NodeConfig.GetBool(config.CfgNetAutopeeringRunAsEntryNode) {
 		plugins = append(plugins, []*node.Plugin{
 			pow.PLUGIN,
 			gossip.PLUGIN,
@@ -61,6 +77,9 @@ func main() {
 			spammer.PLUGIN,
 			coordinator.PLUGIN,
 			prometheus.PLUGIN,
+			watchdog.PLUGIN,
+			fleet.PLUGIN,
+			replica.PLUGIN,
 		}...)
 	}
 
@@ -0,0 +1,28 @@
+package config
+
+const (
+	// whether the node collects the status reports of the other nodes of the fleet
+	CfgFleetAggregator = "fleet.aggregator"
+	// the bind address on which the aggregator listens for status reports
+	CfgFleetBindAddress = "fleet.bindAddress"
+	// the URL of the aggregator the status of the node is reported to (e.g. "http://aggregator:14266")
+	CfgFleetAggregatorURL = "fleet.aggregatorURL"
+	// the secret shared by all nodes of the fleet to authenticate the status reports
+	CfgFleetSharedSecret = "fleet.sharedSecret"
+	// the interval in seconds in which the status of the node is reported
+	CfgFleetReportIntervalSeconds = "fleet.reportIntervalSeconds"
+	// the time in seconds after which a node which didn't report its status is considered offline
+	CfgFleetStaleAfterSeconds = "fleet.staleAfterSeconds"
+	// the path to the configuration file the aggregator shares with the nodes of the fleet
+	CfgFleetSharedConfigPath = "fleet.sharedConfigPath"
+)
+
+func init() {
+	configFlagSet.Bool(CfgFleetAggregator, false, "whether the node collects the status reports of the other nodes of the fleet")
+	configFlagSet.String(CfgFleetBindAddress, "0.0.0.0:14266", "the bind address on which the aggregator listens for status reports")
+	configFlagSet.String(CfgFleetAggregatorURL, "", "the URL of the aggregator the status of the node is reported to (e.g. \"http://aggregator:14266\")")
+	configFlagSet.String(CfgFleetSharedSecret, "", "the secret shared by all nodes of the fleet to authenticate the status reports")
+	configFlagSet.Int(CfgFleetReportIntervalSeconds, 10, "the interval in seconds in which the status of the node is reported")
+	configFlagSet.Int(CfgFleetStaleAfterSeconds, 60, "the time in seconds after which a node which didn't report its status is considered offline")
+	configFlagSet.String(CfgFleetSharedConfigPath, "", "the path to the configuration file the aggregator shares with the nodes of the fleet (the fleet, node and logger sections are not shared)")
+}
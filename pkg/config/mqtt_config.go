@@ -3,8 +3,32 @@ package config
 const (
 	// path to the MQTT broker config file
 	CfgMQTTConfig = "mqtt.config"
+	// whether to publish to an external MQTT broker instead of the embedded one
+	CfgMQTTBridgeEnabled = "mqtt.bridge.enabled"
+	// the URL of the external MQTT broker
+	CfgMQTTBridgeBrokerURL = "mqtt.bridge.brokerURL"
+	// the client ID used to connect to the external MQTT broker
+	CfgMQTTBridgeClientID = "mqtt.bridge.clientID"
+	// the username used to connect to the external MQTT broker
+	CfgMQTTBridgeUsername = "mqtt.bridge.username"
+	// the password used to connect to the external MQTT broker
+	CfgMQTTBridgePassword = "mqtt.bridge.password"
+	// the topic filters which are always published to the external MQTT broker
+	CfgMQTTBridgeTopics = "mqtt.bridge.topics"
+	// the topic on which the external MQTT broker announces new subscriptions of its clients
+	CfgMQTTBridgeSubscribedEventTopic = "mqtt.bridge.subscribedEventTopic"
+	// the topic on which the external MQTT broker announces removed subscriptions of its clients
+	CfgMQTTBridgeUnsubscribedEventTopic = "mqtt.bridge.unsubscribedEventTopic"
 )
 
 func init() {
 	configFlagSet.String(CfgMQTTConfig, "mqtt_config.json", "path to the MQTT broker config file")
+	configFlagSet.Bool(CfgMQTTBridgeEnabled, false, "whether to publish to an external MQTT broker instead of the embedded one")
+	configFlagSet.String(CfgMQTTBridgeBrokerURL, "tcp://localhost:1883", "the URL of the external MQTT broker")
+	configFlagSet.String(CfgMQTTBridgeClientID, "hornet", "the client ID used to connect to the external MQTT broker")
+	configFlagSet.String(CfgMQTTBridgeUsername, "", "the username used to connect to the external MQTT broker")
+	configFlagSet.String(CfgMQTTBridgePassword, "", "the password used to connect to the external MQTT broker")
+	configFlagSet.StringSlice(CfgMQTTBridgeTopics, []string{}, "the topic filters which are always published to the external MQTT broker")
+	configFlagSet.String(CfgMQTTBridgeSubscribedEventTopic, "$SYS/brokers/+/clients/+/subscribed", "the topic on which the external MQTT broker announces new subscriptions of its clients (empty to disable)")
+	configFlagSet.String(CfgMQTTBridgeUnsubscribedEventTopic, "$SYS/brokers/+/clients/+/unsubscribed", "the topic on which the external MQTT broker announces removed subscriptions of its clients (empty to disable)")
 }
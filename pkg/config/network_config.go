@@ -5,6 +5,14 @@ type PeerConfig struct {
 	ID         string `json:"identity" mapstructure:"identity"`
 	Alias      string `json:"alias" mapstructure:"alias"`
 	PreferIPv6 bool   `json:"preferIPv6" mapstructure:"preferIPv6"`
+	// the identity of the primary peer, the peer is only connected while the primary is unreachable (empty = no backup)
+	BackupFor string `json:"backupFor,omitempty" mapstructure:"backupFor"`
+}
+
+// ListenerConfig holds an additional address the node accepts inbound connections of peers on.
+type ListenerConfig struct {
+	Address string `json:"address" mapstructure:"address"`
+	Enabled bool   `json:"enabled" mapstructure:"enabled"`
 }
 
 const (
@@ -12,8 +20,149 @@ const (
 	CfgNetPreferIPv6 = "network.preferIPv6"
 	// the bind address of the gossip TCP server
 	CfgNetGossipBindAddress = "network.gossip.bindAddress"
+	// the additional addresses to accept inbound connections on, prefixed with "ws://" or "wss://" for WebSocket listeners
+	CfgNetGossipListeners = "network.gossip.listeners"
+	// the bind address of the listener for WebSocket connections of peers (empty = disabled)
+	CfgNetGossipWebSocketBindAddress = "network.gossip.webSocket.bindAddress"
+	// the HTTP path under which the WebSocket connections of peers are upgraded and dialed
+	CfgNetGossipWebSocketPath = "network.gossip.webSocket.path"
+	// the path to the TLS certificate of the WebSocket listener, secure WebSocket connections are accepted if it and the key are set
+	CfgNetGossipWebSocketTLSCertPath = "network.gossip.webSocket.tlsCertPath"
+	// the path to the TLS key of the WebSocket listener
+	CfgNetGossipWebSocketTLSKeyPath = "network.gossip.webSocket.tlsKeyPath"
 	// the number of seconds to wait before trying to reconnect to a disconnected peer
 	CfgNetGossipReconnectAttemptIntervalSeconds = "network.gossip.reconnectAttemptIntervalSeconds"
+	// whether to announce the experimental hop count capability to peers
+	CfgNetGossipHopCountEnabled = "network.gossip.hopCount.enabled"
+	// the maximum hop count up to which received transactions are relayed (0 = unlimited, at most 255)
+	CfgNetGossipHopCountLimit = "network.gossip.hopCount.limit"
+	// the maximum amount of gossip transactions which are held back while a milestone is confirmed (0 = disabled)
+	CfgNetGossipAdmissionBufferSize = "network.gossip.admissionBufferSize"
+	// whether to exchange neighbor suggestions with peers which support it
+	CfgNetGossipNeighborSuggestionsEnabled = "network.gossip.neighborSuggestions.enabled"
+	// the interval in seconds at which neighbor suggestions are sent to peers
+	CfgNetGossipNeighborSuggestionsIntervalSeconds = "network.gossip.neighborSuggestions.intervalSeconds"
+	// whether to automatically connect to suggested neighbors if peering slots are available
+	CfgNetGossipNeighborSuggestionsAutoConnect = "network.gossip.neighborSuggestions.autoConnect"
+	// whether to advertise the services of the node to peers which support it and to store their advertised services
+	CfgNetGossipCapabilitiesEnabled = "network.gossip.capabilities.enabled"
+	// the interval in seconds at which the capabilities record is sent to peers
+	CfgNetGossipCapabilitiesIntervalSeconds = "network.gossip.capabilities.intervalSeconds"
+	// whether to advertise that the node serves local snapshot files
+	CfgNetGossipCapabilitiesServesSnapshots = "network.gossip.capabilities.servesSnapshots"
+	// the publicly reachable address of the web API which is advertised to peers (empty = not publicly reachable)
+	CfgNetGossipCapabilitiesPublicAPIAddress = "network.gossip.capabilities.publicAPIAddress"
+	// whether to share signed lists of the peers verified by the autopeering with trusted peers and to use theirs as autopeering candidates
+	CfgNetGossipPeerExchangeEnabled = "network.gossip.peerExchange.enabled"
+	// the interval in seconds at which the list of peers is sent to trusted peers
+	CfgNetGossipPeerExchangeIntervalSeconds = "network.gossip.peerExchange.intervalSeconds"
+	// the hex encoded public keys of the capabilities records of the peers with which peers are exchanged
+	CfgNetGossipPeerExchangeTrustedKeys = "network.gossip.peerExchange.trustedKeys"
+	// whether to measure the round trip time to the peers which support it and to answer their latency pings
+	CfgNetGossipLatencyProbeEnabled = "network.gossip.latencyProbe.enabled"
+	// the interval in seconds at which the peers are pinged to measure the latency
+	CfgNetGossipLatencyProbeIntervalSeconds = "network.gossip.latencyProbe.intervalSeconds"
+	// whether to split messages exceeding the frame size into chunks for neighbors which support it
+	CfgNetGossipChunkingEnabled = "network.gossip.chunking.enabled"
+	// the maximum amount of message bytes sent within a single frame if chunking is enabled
+	CfgNetGossipChunkingFrameSize = "network.gossip.chunking.frameSize"
+	// whether to compress messages for neighbors which support it
+	CfgNetGossipCompressionEnabled = "network.gossip.compression.enabled"
+	// the minimum size in bytes of a message to be compressed
+	CfgNetGossipCompressionMinMessageSize = "network.gossip.compression.minMessageSize"
+	// whether to exchange filters of the recently received transactions with peers which support it (requires the capabilities)
+	CfgNetGossipKnownTransactionsEnabled = "network.gossip.knownTransactions.enabled"
+	// the interval in milliseconds at which the filter of the recently received transactions is sent to peers
+	CfgNetGossipKnownTransactionsIntervalMilliseconds = "network.gossip.knownTransactions.intervalMilliseconds"
+	// whether to recognize recently received transactions without hashing them and to keep them across restarts
+	CfgNetGossipRecentMessagesEnabled = "network.gossip.recentMessages.enabled"
+	// the amount of recently received transactions which are recognized without hashing them
+	CfgNetGossipRecentMessagesCacheSize = "network.gossip.recentMessages.cacheSize"
+	// the max amount of inbound connections which are handshaking at the same time (0 = unlimited)
+	CfgNetGossipLimitsMaxPendingInbound = "network.gossip.limits.maxPendingInbound"
+	// the max amount of connected and handshaking inbound peers with the same IP address (0 = unlimited)
+	CfgNetGossipLimitsMaxConnectionsPerIP = "network.gossip.limits.maxConnectionsPerIP"
+	// the max amount of bytes held in the send queue of a single peer (0 = unlimited)
+	CfgNetGossipLimitsMaxSendQueueMemoryBytes = "network.gossip.limits.maxSendQueueMemoryBytes"
+	// the max amount of bytes held in the send queues of all peers together (0 = unlimited)
+	CfgNetGossipLimitsMaxTotalSendQueueMemoryBytes = "network.gossip.limits.maxTotalSendQueueMemoryBytes"
+	// the max amount of connected inbound peers, known peers prune unknown peers if it is reached (0 = unlimited)
+	CfgNetGossipLimitsMaxInbound = "network.gossip.limits.maxInbound"
+	// the max amount of connected outbound peers, known peers prune unknown peers if it is reached (0 = unlimited)
+	CfgNetGossipLimitsMaxOutbound = "network.gossip.limits.maxOutbound"
+	// defines which message is dropped if the send queue of a peer is full ("dropNewest", "dropOldest" or "block")
+	CfgNetGossipSendQueueOverflowPolicy = "network.gossip.sendQueue.overflowPolicy"
+	// the maximum time in milliseconds a message waits for room in the send queue of a peer if the "block" overflow policy is used
+	CfgNetGossipSendQueueBlockTimeoutMilliseconds = "network.gossip.sendQueue.blockTimeoutMilliseconds"
+	// the maximum time in milliseconds to wait for the send queue of a removed peer to be sent before its connection is closed (0 = close immediately)
+	CfgNetGossipSendQueueDrainTimeoutMilliseconds = "network.gossip.sendQueue.drainTimeoutMilliseconds"
+	// the fill level of the send queue of a peer in percent at which the peer is considered congested (0 = disabled)
+	CfgNetGossipSendQueueHighWatermarkPercent = "network.gossip.sendQueue.highWatermarkPercent"
+	// the fill level of the send queue of a congested peer in percent at which the peer is considered relieved
+	CfgNetGossipSendQueueLowWatermarkPercent = "network.gossip.sendQueue.lowWatermarkPercent"
+	// whether to persist hourly and daily rollups of the traffic of the peers
+	CfgNetGossipTrafficHistoryEnabled = "network.gossip.trafficHistory.enabled"
+	// the amount of days the hourly rollups of the traffic of the peers are kept
+	CfgNetGossipTrafficHistoryHourlyRetentionDays = "network.gossip.trafficHistory.hourlyRetentionDays"
+	// the amount of days the daily rollups of the traffic of the peers are kept
+	CfgNetGossipTrafficHistoryDailyRetentionDays = "network.gossip.trafficHistory.dailyRetentionDays"
+	// the time in seconds after which a read from a peer without receiving any data times out (0 = no read deadline)
+	CfgNetGossipDeadlinesReadTimeoutSeconds = "network.gossip.deadlines.readTimeoutSeconds"
+	// the time in seconds after which a write to a peer times out
+	CfgNetGossipDeadlinesWriteTimeoutSeconds = "network.gossip.deadlines.writeTimeoutSeconds"
+	// the amount of consecutive read or write deadlines a connection to a peer may exceed before it is closed
+	CfgNetGossipDeadlinesMaxHits = "network.gossip.deadlines.maxHits"
+	// the interval in seconds in which heartbeats are sent to every peer at least
+	CfgNetGossipHeartbeatIntervalSeconds = "network.gossip.heartbeat.intervalSeconds"
+	// the interval in seconds in which heartbeats are sent while the node isn't synced or a peer is catching up (0 = disable)
+	CfgNetGossipHeartbeatAdaptiveIntervalSeconds = "network.gossip.heartbeat.adaptiveIntervalSeconds"
+	// the time in seconds after which a heartbeat is sent to a peer to which nothing was sent (0 = disable)
+	CfgNetGossipKeepaliveIntervalSeconds = "network.gossip.keepalive.intervalSeconds"
+	// the time in seconds after which the connection to a pinged peer from which nothing was received is dropped
+	// (0 = disable, the heartbeats of the peers are checked instead)
+	CfgNetGossipKeepaliveTimeoutSeconds = "network.gossip.keepalive.timeoutSeconds"
+	// the time in milliseconds after which a request sent to a single peer is sent to another peer (0 = disable)
+	CfgNetGossipRequestsTimeoutMilliseconds = "network.gossip.requests.timeoutMilliseconds"
+	// the bytes per second which may be sent to a statically configured peer (0 = unlimited)
+	CfgNetGossipBandwidthStaticUploadBytesPerSecond = "network.gossip.bandwidth.static.uploadBytesPerSecond"
+	// the bytes per second which may be received from a statically configured peer (0 = unlimited)
+	CfgNetGossipBandwidthStaticDownloadBytesPerSecond = "network.gossip.bandwidth.static.downloadBytesPerSecond"
+	// the bytes per second which may be sent to an autopeered peer (0 = unlimited)
+	CfgNetGossipBandwidthAutopeeredUploadBytesPerSecond = "network.gossip.bandwidth.autopeered.uploadBytesPerSecond"
+	// the bytes per second which may be received from an autopeered peer (0 = unlimited)
+	CfgNetGossipBandwidthAutopeeredDownloadBytesPerSecond = "network.gossip.bandwidth.autopeered.downloadBytesPerSecond"
+	// whether to persist the long-term statistics of the peers and take them into account for their scores
+	CfgNetGossipReputationEnabled = "network.gossip.reputation.enabled"
+	// whether to store the peers added at runtime, so that they are connected to again after a restart
+	CfgNetGossipPeerStoreEnabled = "network.gossip.peerStore.enabled"
+	// the URLs the peer events are posted to as JSON (empty = disabled)
+	CfgNetGossipWebhooksURLs = "network.gossip.webhooks.urls"
+	// the timeout of a webhook request in seconds
+	CfgNetGossipWebhooksTimeoutSeconds = "network.gossip.webhooks.timeoutSeconds"
+	// the duration in seconds after which an unreachable static peer is alerted and the node reported as unhealthy (0 = disable)
+	CfgNetGossipStaticPeerAlertThresholdSeconds = "network.gossip.staticPeerAlertThresholdSeconds"
+	// the duration in seconds a primary peer has to be unreachable before its backup peers are connected
+	CfgNetGossipFailoverDelaySeconds = "network.gossip.failoverDelaySeconds"
+	// whether to cluster recent transactions by tag and payload to detect spam sources
+	CfgNetGossipSpamDetectionEnabled = "network.gossip.spamDetection.enabled"
+	// the time window in seconds in which transactions of a cluster are counted
+	CfgNetGossipSpamDetectionWindowSeconds = "network.gossip.spamDetection.windowSeconds"
+	// the amount of transactions of a cluster within the time window from which on it is considered spam
+	CfgNetGossipSpamDetectionThreshold = "network.gossip.spamDetection.threshold"
+	// the filters which are applied to spam transactions ("noIndex", "noRelayToUnknownPeers")
+	CfgNetGossipSpamDetectionFilters = "network.gossip.spamDetection.filters"
+	// the score from 0 to 1 below which peers get deprioritized (0 = disable scoring)
+	CfgNetGossipScoringMinScore = "network.gossip.scoring.minScore"
+	// whether to drop autopeered neighbors instead of deprioritizing them if their score is low
+	CfgNetGossipScoringDropAutopeers = "network.gossip.scoring.dropAutopeers"
+	// the duration for which peers are banned if their score is low (0 = disable banning)
+	CfgNetGossipScoringBanDurationSeconds = "network.gossip.scoring.banDurationSeconds"
+	// whether to deprioritize peers which are not synced, so that broadcasts and requests go to synced peers first
+	CfgNetGossipPreferSyncedPeers = "network.gossip.preferSyncedPeers"
+	// the relations of the peers with which transactions are gossiped ("static", "autopeered"), connections to other peers are kept without gossip
+	CfgNetGossipRelations = "network.gossip.relations"
+	// the ID of the network exchanged with the peers after the handshake, peers of other networks are rejected (empty = derived from the coordinator address and MWM)
+	CfgNetGossipNetworkID = "network.gossip.networkID"
 
 	// enable inbound connections from unknown peers
 	CfgPeeringAcceptAnyConnection = "acceptAnyConnection"
@@ -21,6 +170,14 @@ const (
 	CfgPeeringMaxPeers = "maxPeers"
 	// set the URLs and IP addresses of peers
 	CfgPeers = "peers"
+	// the IP addresses or CIDRs from which connections are allowed (empty = all)
+	CfgPeeringAllowedIPs = "allowedIPs"
+	// the IP addresses or CIDRs from which connections are refused
+	CfgPeeringDeniedIPs = "deniedIPs"
+	// the IDs (ip:port) of the peers which are allowed to connect (empty = all)
+	CfgPeeringAllowedPeers = "allowedPeers"
+	// the IDs (ip:port) of the peers which are refused
+	CfgPeeringDeniedPeers = "deniedPeers"
 	// sets a list of static peers, this is only used for CLI flags
 	CfgPeersList = "peerslist"
 
@@ -30,6 +187,10 @@ const (
 	CfgNetAutopeeringBindAddr = "network.autopeering.bindAddress"
 	// private key seed used to derive the node identity; optional Base64 encoded 256-bit string
 	CfgNetAutopeeringSeed = "network.autopeering.seed"
+	// the path of the encrypted keystore holding the node identity (empty = the identity is stored in plaintext)
+	CfgNetAutopeeringIdentityFilePath = "network.autopeering.identity.filePath"
+	// the path of the file holding the passphrase of the identity keystore (empty = the passphrase is read from the environment)
+	CfgNetAutopeeringIdentityPassphraseFilePath = "network.autopeering.identity.passphraseFilePath"
 	// whether the node should act as an autopeering entry node
 	CfgNetAutopeeringRunAsEntryNode = "network.autopeering.runAsEntryNode"
 	// the number of inbound autopeers
@@ -40,6 +201,14 @@ const (
 	CfgNetAutopeeringSaltLifetime = "network.autopeering.saltLifetime"
 	// maximum percentage of dropped packets in one minute before an autopeered neighbor gets dropped
 	CfgNetAutopeeringMaxDroppedPacketsPercentage = "network.autopeering.maxDroppedPacketsPercentage"
+	// the max amount of autopeered peers within the same /16 (IPv4) or /32 (IPv6) subnet (0 = unlimited)
+	CfgNetAutopeeringDiversityMaxPeersPerSubnet = "network.autopeering.diversity.maxPeersPerSubnet"
+	// the max amount of autopeered peers located in the same country (0 = unlimited)
+	CfgNetAutopeeringDiversityMaxPeersPerCountry = "network.autopeering.diversity.maxPeersPerCountry"
+	// the max amount of autopeered peers within the same autonomous system (0 = unlimited)
+	CfgNetAutopeeringDiversityMaxPeersPerASN = "network.autopeering.diversity.maxPeersPerASN"
+	// the path of the IP-to-ASN database in the TSV format of iptoasn.com, needed for the country and ASN limits
+	CfgNetAutopeeringDiversityGeoIPDatabasePath = "network.autopeering.diversity.geoIPDatabasePath"
 )
 
 func init() {
@@ -47,12 +216,86 @@ func init() {
 	// gossip
 	configFlagSet.Bool(CfgNetPreferIPv6, false, "defines if IPv6 is preferred for peers added through the API")
 	configFlagSet.String(CfgNetGossipBindAddress, "0.0.0.0:15600", "the bind address of the gossip TCP server")
+	NodeConfig.SetDefault(CfgNetGossipListeners, []ListenerConfig{})
+	configFlagSet.String(CfgNetGossipWebSocketBindAddress, "", "the bind address of the listener for WebSocket connections of peers (empty = disabled)")
+	configFlagSet.String(CfgNetGossipWebSocketPath, "/gossip", "the HTTP path under which the WebSocket connections of peers are upgraded and dialed")
+	configFlagSet.String(CfgNetGossipWebSocketTLSCertPath, "", "the path to the TLS certificate of the WebSocket listener, secure WebSocket connections are accepted if it and the key are set")
+	configFlagSet.String(CfgNetGossipWebSocketTLSKeyPath, "", "the path to the TLS key of the WebSocket listener")
 	configFlagSet.Int(CfgNetGossipReconnectAttemptIntervalSeconds, 60, "the number of seconds to wait before trying to reconnect to a disconnected peer")
+	configFlagSet.Bool(CfgNetGossipHopCountEnabled, false, "whether to announce the experimental hop count capability to peers")
+	configFlagSet.Int(CfgNetGossipHopCountLimit, 0, "the maximum hop count up to which received transactions are relayed (0 = unlimited, at most 255)")
+	configFlagSet.Int(CfgNetGossipAdmissionBufferSize, 5000, "the maximum amount of gossip transactions which are held back while a milestone is confirmed (0 = disabled)")
+	configFlagSet.Bool(CfgNetGossipNeighborSuggestionsEnabled, false, "whether to exchange neighbor suggestions with peers which support it")
+	configFlagSet.Int(CfgNetGossipNeighborSuggestionsIntervalSeconds, 600, "the interval in seconds at which neighbor suggestions are sent to peers")
+	configFlagSet.Bool(CfgNetGossipNeighborSuggestionsAutoConnect, false, "whether to automatically connect to suggested neighbors if peering slots are available")
+	configFlagSet.Bool(CfgNetGossipCapabilitiesEnabled, false, "whether to advertise the services of the node to peers which support it and to store their advertised services")
+	configFlagSet.Int(CfgNetGossipCapabilitiesIntervalSeconds, 300, "the interval in seconds at which the capabilities record is sent to peers")
+	configFlagSet.Bool(CfgNetGossipCapabilitiesServesSnapshots, false, "whether to advertise that the node serves local snapshot files")
+	configFlagSet.String(CfgNetGossipCapabilitiesPublicAPIAddress, "", "the publicly reachable address of the web API which is advertised to peers (empty = not publicly reachable)")
+	configFlagSet.Bool(CfgNetGossipPeerExchangeEnabled, false, "whether to share signed lists of the peers verified by the autopeering with trusted peers and to use theirs as autopeering candidates (requires the capabilities)")
+	configFlagSet.Int(CfgNetGossipPeerExchangeIntervalSeconds, 300, "the interval in seconds at which the list of peers is sent to trusted peers")
+	configFlagSet.StringSlice(CfgNetGossipPeerExchangeTrustedKeys, []string{}, "the hex encoded public keys of the capabilities records of the peers with which peers are exchanged")
+	configFlagSet.Bool(CfgNetGossipLatencyProbeEnabled, false, "whether to measure the round trip time to the peers which support it and to answer their latency pings (requires the capabilities)")
+	configFlagSet.Int(CfgNetGossipLatencyProbeIntervalSeconds, 30, "the interval in seconds at which the peers are pinged to measure the latency")
+	configFlagSet.Bool(CfgNetGossipChunkingEnabled, false, "whether to split messages exceeding the frame size into chunks for neighbors which support it")
+	configFlagSet.Int(CfgNetGossipChunkingFrameSize, 1200, "the maximum amount of message bytes sent within a single frame if chunking is enabled")
+	configFlagSet.Bool(CfgNetGossipCompressionEnabled, false, "whether to compress messages for neighbors which support it")
+	configFlagSet.Int(CfgNetGossipCompressionMinMessageSize, 256, "the minimum size in bytes of a message to be compressed")
+	configFlagSet.Bool(CfgNetGossipKnownTransactionsEnabled, false, "whether to exchange filters of the recently received transactions with peers which support it (requires the capabilities)")
+	configFlagSet.Int(CfgNetGossipKnownTransactionsIntervalMilliseconds, 1000, "the interval in milliseconds at which the filter of the recently received transactions is sent to peers")
+	configFlagSet.Bool(CfgNetGossipRecentMessagesEnabled, false, "whether to recognize recently received transactions without hashing them and to keep them across restarts")
+	configFlagSet.Int(CfgNetGossipRecentMessagesCacheSize, 50000, "the amount of recently received transactions which are recognized without hashing them")
+	configFlagSet.Int(CfgNetGossipLimitsMaxPendingInbound, 16, "the max amount of inbound connections which are handshaking at the same time (0 = unlimited)")
+	configFlagSet.Int(CfgNetGossipLimitsMaxConnectionsPerIP, 0, "the max amount of connected and handshaking inbound peers with the same IP address (0 = unlimited)")
+	configFlagSet.Int64(CfgNetGossipLimitsMaxSendQueueMemoryBytes, 4*1024*1024, "the max amount of bytes held in the send queue of a single peer (0 = unlimited)")
+	configFlagSet.Int64(CfgNetGossipLimitsMaxTotalSendQueueMemoryBytes, 64*1024*1024, "the max amount of bytes held in the send queues of all peers together (0 = unlimited)")
+	configFlagSet.Int(CfgNetGossipLimitsMaxInbound, 0, "the max amount of connected inbound peers, known peers prune unknown peers if it is reached (0 = unlimited)")
+	configFlagSet.Int(CfgNetGossipLimitsMaxOutbound, 0, "the max amount of connected outbound peers, known peers prune unknown peers if it is reached (0 = unlimited)")
+	configFlagSet.String(CfgNetGossipSendQueueOverflowPolicy, "dropNewest", "defines which message is dropped if the send queue of a peer is full (\"dropNewest\", \"dropOldest\" or \"block\")")
+	configFlagSet.Int(CfgNetGossipSendQueueBlockTimeoutMilliseconds, 100, "the maximum time in milliseconds a message waits for room in the send queue of a peer if the \"block\" overflow policy is used")
+	configFlagSet.Int(CfgNetGossipSendQueueDrainTimeoutMilliseconds, 1000, "the maximum time in milliseconds to wait for the send queue of a removed peer to be sent before its connection is closed (0 = close immediately)")
+	configFlagSet.Int(CfgNetGossipSendQueueHighWatermarkPercent, 80, "the fill level of the send queue of a peer in percent at which the peer is considered congested (0 = disabled)")
+	configFlagSet.Int(CfgNetGossipSendQueueLowWatermarkPercent, 50, "the fill level of the send queue of a congested peer in percent at which the peer is considered relieved")
+	configFlagSet.Bool(CfgNetGossipTrafficHistoryEnabled, false, "whether to persist hourly and daily rollups of the traffic of the peers")
+	configFlagSet.Int(CfgNetGossipTrafficHistoryHourlyRetentionDays, 7, "the amount of days the hourly rollups of the traffic of the peers are kept")
+	configFlagSet.Int(CfgNetGossipTrafficHistoryDailyRetentionDays, 365, "the amount of days the daily rollups of the traffic of the peers are kept")
+	configFlagSet.Int(CfgNetGossipDeadlinesReadTimeoutSeconds, 0, "the time in seconds after which a read from a peer without receiving any data times out (0 = no read deadline)")
+	configFlagSet.Int(CfgNetGossipDeadlinesWriteTimeoutSeconds, 5, "the time in seconds after which a write to a peer times out")
+	configFlagSet.Int(CfgNetGossipDeadlinesMaxHits, 3, "the amount of consecutive read or write deadlines a connection to a peer may exceed before it is closed")
+	configFlagSet.Int(CfgNetGossipHeartbeatIntervalSeconds, 30, "the interval in seconds in which heartbeats are sent to every peer at least")
+	configFlagSet.Int(CfgNetGossipHeartbeatAdaptiveIntervalSeconds, 5, "the interval in seconds in which heartbeats are sent while the node isn't synced or a peer is catching up (0 = disable)")
+	configFlagSet.Int(CfgNetGossipKeepaliveIntervalSeconds, 10, "the time in seconds after which a heartbeat is sent to a peer to which nothing was sent (0 = disable)")
+	configFlagSet.Int(CfgNetGossipKeepaliveTimeoutSeconds, 100, "the time in seconds after which the connection to a pinged peer from which nothing was received is dropped (0 = disable, the heartbeats of the peers are checked instead)")
+	configFlagSet.Int(CfgNetGossipRequestsTimeoutMilliseconds, 1000, "the time in milliseconds after which a request sent to a single peer is sent to another peer (0 = disable)")
+	configFlagSet.Int(CfgNetGossipBandwidthStaticUploadBytesPerSecond, 0, "the bytes per second which may be sent to a statically configured peer (0 = unlimited)")
+	configFlagSet.Int(CfgNetGossipBandwidthStaticDownloadBytesPerSecond, 0, "the bytes per second which may be received from a statically configured peer (0 = unlimited)")
+	configFlagSet.Int(CfgNetGossipBandwidthAutopeeredUploadBytesPerSecond, 0, "the bytes per second which may be sent to an autopeered peer (0 = unlimited)")
+	configFlagSet.Int(CfgNetGossipBandwidthAutopeeredDownloadBytesPerSecond, 0, "the bytes per second which may be received from an autopeered peer (0 = unlimited)")
+	configFlagSet.Bool(CfgNetGossipReputationEnabled, false, "whether to persist the long-term statistics of the peers and take them into account for their scores")
+	configFlagSet.Bool(CfgNetGossipPeerStoreEnabled, false, "whether to store the peers added at runtime, so that they are connected to again after a restart")
+	configFlagSet.StringSlice(CfgNetGossipWebhooksURLs, []string{}, "the URLs the peer events are posted to as JSON (empty = disabled)")
+	configFlagSet.Int(CfgNetGossipWebhooksTimeoutSeconds, 5, "the timeout of a webhook request in seconds")
+	configFlagSet.Int(CfgNetGossipFailoverDelaySeconds, 60, "the duration in seconds a primary peer has to be unreachable before its backup peers are connected")
+	configFlagSet.Int(CfgNetGossipStaticPeerAlertThresholdSeconds, 0, "the duration in seconds after which an unreachable static peer is alerted and the node reported as unhealthy (0 = disable)")
+	configFlagSet.Bool(CfgNetGossipSpamDetectionEnabled, false, "whether to cluster recent transactions by tag and payload to detect spam sources")
+	configFlagSet.Int(CfgNetGossipSpamDetectionWindowSeconds, 60, "the time window in seconds in which transactions of a cluster are counted")
+	configFlagSet.Int(CfgNetGossipSpamDetectionThreshold, 500, "the amount of transactions of a cluster within the time window from which on it is considered spam")
+	configFlagSet.StringSlice(CfgNetGossipSpamDetectionFilters, []string{}, "the filters which are applied to spam transactions (\"noIndex\", \"noRelayToUnknownPeers\")")
+	configFlagSet.Float64(CfgNetGossipScoringMinScore, 0, "the score from 0 to 1 below which peers get deprioritized (0 = disable scoring)")
+	configFlagSet.Bool(CfgNetGossipScoringDropAutopeers, true, "whether to drop autopeered neighbors instead of deprioritizing them if their score is low")
+	configFlagSet.Int(CfgNetGossipScoringBanDurationSeconds, 0, "the duration for which peers are banned if their score is low (0 = disable banning)")
+	configFlagSet.Bool(CfgNetGossipPreferSyncedPeers, false, "whether to deprioritize peers which are not synced, so that broadcasts and requests go to synced peers first")
+	configFlagSet.StringSlice(CfgNetGossipRelations, []string{"static", "autopeered"}, "the relations of the peers with which transactions are gossiped (\"static\", \"autopeered\"), connections to other peers are kept without gossip")
+	configFlagSet.String(CfgNetGossipNetworkID, "", "the ID of the network exchanged with the peers after the handshake, peers of other networks are rejected (empty = derived from the coordinator address and MWM)")
 
 	// peering
 	peeringFlagSet.Bool(CfgPeeringAcceptAnyConnection, false, "enable inbound connections from unknown peers")
 	peeringFlagSet.Int(CfgPeeringMaxPeers, 5, "set the maximum number of peers (non-autopeering)")
 	PeeringConfig.SetDefault(CfgPeers, []PeerConfig{})
+	peeringFlagSet.StringSlice(CfgPeeringAllowedIPs, []string{}, "the IP addresses or CIDRs from which connections are allowed (empty = all)")
+	peeringFlagSet.StringSlice(CfgPeeringDeniedIPs, []string{}, "the IP addresses or CIDRs from which connections are refused")
+	peeringFlagSet.StringSlice(CfgPeeringAllowedPeers, []string{}, "the IDs (ip:port) of the peers which are allowed to connect (empty = all)")
+	peeringFlagSet.StringSlice(CfgPeeringDeniedPeers, []string{}, "the IDs (ip:port) of the peers which are refused")
 
 	// this is added to the configFlagSet on purpose, because it should not be added to the peering.json after neighbors changed
 	configFlagSet.StringSlice(CfgPeersList, []string{}, "a list of peers to connect to")
@@ -66,9 +309,15 @@ func init() {
 	}, "list of autopeering entry nodes to use")
 	configFlagSet.String(CfgNetAutopeeringBindAddr, "0.0.0.0:14626", "bind address for global services such as autopeering and gossip")
 	configFlagSet.String(CfgNetAutopeeringSeed, "", "private key seed used to derive the node identity; optional Base64 encoded 256-bit string")
+	configFlagSet.String(CfgNetAutopeeringIdentityFilePath, "", "the path of the encrypted keystore holding the node identity (empty = the identity is stored in plaintext)")
+	configFlagSet.String(CfgNetAutopeeringIdentityPassphraseFilePath, "", "the path of the file holding the passphrase of the identity keystore (empty = the passphrase is read from the environment)")
 	configFlagSet.Bool(CfgNetAutopeeringRunAsEntryNode, false, "whether the node should act as an autopeering entry node")
 	configFlagSet.Int(CfgNetAutopeeringInboundPeers, 2, "the number of inbound autopeers")
 	configFlagSet.Int(CfgNetAutopeeringOutboundPeers, 2, "the number of outbound autopeers")
 	configFlagSet.Int(CfgNetAutopeeringSaltLifetime, 30, "lifetime (in minutes) of the private and public local salt")
 	configFlagSet.Int(CfgNetAutopeeringMaxDroppedPacketsPercentage, 0, "maximum percentage of dropped packets in one minute before an autopeered neighbor gets dropped (0 = disable)")
+	configFlagSet.Int(CfgNetAutopeeringDiversityMaxPeersPerSubnet, 0, "the max amount of autopeered peers within the same /16 (IPv4) or /32 (IPv6) subnet (0 = unlimited)")
+	configFlagSet.Int(CfgNetAutopeeringDiversityMaxPeersPerCountry, 0, "the max amount of autopeered peers located in the same country (0 = unlimited)")
+	configFlagSet.Int(CfgNetAutopeeringDiversityMaxPeersPerASN, 0, "the max amount of autopeered peers within the same autonomous system (0 = unlimited)")
+	configFlagSet.String(CfgNetAutopeeringDiversityGeoIPDatabasePath, "", "the path of the IP-to-ASN database in the TSV format of iptoasn.com, needed for the country and ASN limits")
 }
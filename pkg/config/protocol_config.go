@@ -0,0 +1,13 @@
+package config
+
+const (
+	// the protocol features which are enabled in the network (all nodes of the network have to enable the same features)
+	CfgProtocolFeatures = "protocol.features"
+	// how transactions with a payload without a registered validator are handled ("store" or "reject")
+	CfgProtocolUnknownPayloads = "protocol.unknownPayloads"
+)
+
+func init() {
+	configFlagSet.StringSlice(CfgProtocolFeatures, []string{}, "the protocol features which are enabled in the network (all nodes of the network have to enable the same features)")
+	configFlagSet.String(CfgProtocolUnknownPayloads, "store", "how transactions with a payload without a registered validator are handled (\"store\" or \"reject\")")
+}
@@ -0,0 +1,19 @@
+package config
+
+const (
+	// the bind address on which the invalidation notices are streamed to the replicas
+	CfgReplicaFeedBindAddress = "replica.feed.bindAddress"
+	// the interval in seconds in which the state of the node is announced to the replicas
+	CfgReplicaFeedStatusIntervalSeconds = "replica.feed.statusIntervalSeconds"
+	// the URL of the feed of the primary a replica started in safe mode subscribes to (e.g. "http://primary:14267")
+	CfgReplicaPrimaryFeedURL = "replica.primaryFeedURL"
+	// the time in seconds after which a replica which didn't receive a notice from the primary is considered unsynced
+	CfgReplicaStaleAfterSeconds = "replica.staleAfterSeconds"
+)
+
+func init() {
+	configFlagSet.String(CfgReplicaFeedBindAddress, "0.0.0.0:14267", "the bind address on which the invalidation notices are streamed to the replicas")
+	configFlagSet.Int(CfgReplicaFeedStatusIntervalSeconds, 10, "the interval in seconds in which the state of the node is announced to the replicas")
+	configFlagSet.String(CfgReplicaPrimaryFeedURL, "", "the URL of the feed of the primary a replica started in safe mode subscribes to (e.g. \"http://primary:14267\")")
+	configFlagSet.Int(CfgReplicaStaleAfterSeconds, 30, "the time in seconds after which a replica which didn't receive a notice from the primary is considered unsynced")
+}
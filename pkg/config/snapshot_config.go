@@ -3,6 +3,10 @@ package config
 const (
 	// which snapshot type to load. 'local' or 'global'
 	CfgSnapshotLoadType = "snapshots.loadType"
+	// the order in which the bootstrap sources are tried at startup ('database', 'local', 'download', 'global').
+	// it has to start with 'database', so only the order of the snapshot sources can be pinned.
+	// if empty, the order is derived from the snapshot load type
+	CfgSnapshotBootstrapOrder = "snapshots.bootstrapOrder"
 	// the depth, respectively the starting point, at which a local snapshot of the ledger is generated
 	CfgLocalSnapshotsDepth = "snapshots.local.depth"
 	// interval, in milestone transactions, at which snapshot files are created if the ledger is fully synchronized
@@ -11,8 +15,46 @@ const (
 	CfgLocalSnapshotsIntervalUnsynced = "snapshots.local.intervalUnsynced"
 	// path to the local snapshot file
 	CfgLocalSnapshotsPath = "snapshots.local.path"
+	// path to the delta snapshot file, which contains the ledger changes since the local snapshot file
+	CfgLocalSnapshotsDeltaPath = "snapshots.local.deltaPath"
+	// interval, in milestone transactions, at which full local snapshot files are created.
+	// in between, delta snapshot files are created. if 0, only full local snapshot files are created
+	CfgLocalSnapshotsFullSnapshotInterval = "snapshots.local.fullSnapshotInterval"
+	// cron spec (minute, hour, day of month, month, day of week) at which additional local snapshot files are created,
+	// e.g. '0 3 * * *' for daily at 03:00. if empty, no scheduled snapshot files are created
+	CfgLocalSnapshotsScheduleCron = "snapshots.local.schedule.cron"
+	// path to the directory of the scheduled snapshot files
+	CfgLocalSnapshotsSchedulePath = "snapshots.local.schedule.path"
+	// amount of scheduled snapshot files which are kept, older ones are removed (0 = keep all)
+	CfgLocalSnapshotsScheduleRetention = "snapshots.local.schedule.retention"
+	// the pause in milliseconds after each walked milestone cone and each batch of written entries while creating a local snapshot,
+	// to reduce the I/O load on slow disks (0 = no pause)
+	CfgLocalSnapshotsPauseMilliseconds = "snapshots.local.pauseMilliseconds"
+	// path to the checkpoint file, which allows an interrupted snapshot creation to be resumed
+	CfgLocalSnapshotsCheckpointPath = "snapshots.local.checkpointPath"
 	// URL to load the local snapshot file from
 	CfgLocalSnapshotsDownloadURLs = "snapshots.local.downloadURLs"
+	// URLs to load the delta snapshot file from, which is chained onto the downloaded local snapshot file
+	CfgLocalSnapshotsDeltaDownloadURLs = "snapshots.local.deltaDownloadURLs"
+	// hex encoded sha256 checksum of the local snapshot file to download.
+	// if set, the downloaded file is only loaded if it matches the checksum
+	CfgLocalSnapshotsDownloadSHA256 = "snapshots.local.downloadSHA256"
+	// whether to sign created local snapshot files with the ed25519 key in the environment variable 'SNAPSHOT_SIGNING_KEY'
+	CfgLocalSnapshotsSigningEnabled = "snapshots.local.signing.enabled"
+	// hex encoded ed25519 public keys which are trusted to sign local snapshot files.
+	// if set, only local snapshot files with a valid signature of one of these keys are loaded
+	CfgLocalSnapshotsTrustedKeys = "snapshots.local.trustedKeys"
+	// the type of the storage the created local snapshot files are uploaded to ('s3', 'gcs' or 'webdav').
+	// if empty, the local snapshot files are not uploaded
+	CfgSnapshotUploadType = "snapshots.upload.type"
+	// the URL of the bucket (and prefix) respectively the WebDAV directory the local snapshot files are uploaded to
+	CfgSnapshotUploadURL = "snapshots.upload.url"
+	// the region of the S3 bucket
+	CfgSnapshotUploadRegion = "snapshots.upload.region"
+	// the access key ID (S3 or GCS HMAC key) respectively the username (WebDAV) to upload the local snapshot files
+	CfgSnapshotUploadAccessKeyID = "snapshots.upload.accessKeyID"
+	// the amount of uploaded scheduled snapshot files which are kept, older ones are removed (0 = keep all)
+	CfgSnapshotUploadRetention = "snapshots.upload.retention"
 	// path to the global snapshot file containing the ledger state
 	CfgGlobalSnapshotPath = "snapshots.global.path"
 	// paths to the spent addresses files
@@ -23,17 +65,48 @@ const (
 	CfgPruningEnabled = "snapshots.pruning.enabled"
 	// amount of milestone transactions to keep in the database
 	CfgPruningDelay = "snapshots.pruning.delay"
+	// the amount of hours of history to keep in the database, based on the milestone timestamps.
+	// if set, it is used instead of the pruning delay (0 = use the pruning delay)
+	CfgPruningRetentionHours = "snapshots.pruning.retentionHours"
+	// the maximum size of the database (e.g. '50GB'). if exceeded, more milestones than the pruning delay are pruned,
+	// but at least the minimum history is kept. if empty, only the pruning delay is used
+	CfgPruningMaxDatabaseSize = "snapshots.pruning.maxDatabaseSize"
+	// the maximum amount of transactions which are deleted per second by the pruning,
+	// to reduce the I/O load on slow disks (0 = unlimited)
+	CfgPruningMaxDeletionsPerSecond = "snapshots.pruning.maxDeletionsPerSecond"
+	// the amount of workers which prune the transactions of a milestone in parallel (0 = amount of CPUs)
+	CfgPruningWorkerCount = "snapshots.pruning.workerCount"
+	// rules to delete zero-value transactions with the given tag prefix after the given amount of milestones ('TAGPREFIX:milestones'),
+	// independent of the regular pruning
+	CfgPruningExpiryRules = "snapshots.pruning.expiryRules"
 	// enable support for wereAddressesSpentFrom (needed for Trinity, but local snapshots are much bigger)
 	CfgSpentAddressesEnabled = "spentAddresses.enabled"
 )
 
 func init() {
 	configFlagSet.String(CfgSnapshotLoadType, "local", "which snapshot type to load. 'local' or 'global'")
+	configFlagSet.StringSlice(CfgSnapshotBootstrapOrder, []string{}, "the order in which the bootstrap sources are tried at startup ('database', 'local', 'download', 'global'). it has to start with 'database', so only the order of the snapshot sources can be pinned. if empty, the order is derived from the snapshot load type")
 	configFlagSet.Int(CfgLocalSnapshotsDepth, 50, "the depth, respectively the starting point, at which a local snapshot of the ledger is generated")
 	configFlagSet.Int(CfgLocalSnapshotsIntervalSynced, 50, "interval, in milestone transactions, at which snapshot files are created if the ledger is fully synchronized")
 	configFlagSet.Int(CfgLocalSnapshotsIntervalUnsynced, 1000, "interval, in milestone transactions, at which snapshot files are created if the ledger is not fully synchronized")
 	configFlagSet.String(CfgLocalSnapshotsPath, "snapshots/mainnet/export.bin", "path to the local snapshot file")
+	configFlagSet.String(CfgLocalSnapshotsDeltaPath, "snapshots/mainnet/export_delta.bin", "path to the delta snapshot file, which contains the ledger changes since the local snapshot file")
+	configFlagSet.Int(CfgLocalSnapshotsFullSnapshotInterval, 0, "interval, in milestone transactions, at which full local snapshot files are created. in between, delta snapshot files are created. if 0, only full local snapshot files are created")
+	configFlagSet.String(CfgLocalSnapshotsScheduleCron, "", "cron spec (minute, hour, day of month, month, day of week) at which additional local snapshot files are created, e.g. '0 3 * * *' for daily at 03:00. if empty, no scheduled snapshot files are created")
+	configFlagSet.String(CfgLocalSnapshotsSchedulePath, "snapshots/mainnet/scheduled", "path to the directory of the scheduled snapshot files")
+	configFlagSet.Int(CfgLocalSnapshotsScheduleRetention, 7, "amount of scheduled snapshot files which are kept, older ones are removed (0 = keep all)")
+	configFlagSet.Int(CfgLocalSnapshotsPauseMilliseconds, 0, "the pause in milliseconds after each walked milestone cone and each batch of written entries while creating a local snapshot, to reduce the I/O load on slow disks (0 = no pause)")
+	configFlagSet.String(CfgLocalSnapshotsCheckpointPath, "snapshots/mainnet/checkpoint.bin", "path to the checkpoint file of the local snapshot creation, which allows an aborted or interrupted snapshot creation to resume instead of starting over. if empty, the snapshot creation always starts over")
 	configFlagSet.StringSlice(CfgLocalSnapshotsDownloadURLs, []string{}, "URLs to load the local snapshot file from. Provide multiple URLs as fall back sources")
+	configFlagSet.StringSlice(CfgLocalSnapshotsDeltaDownloadURLs, []string{}, "URLs to load the delta snapshot file from, which is chained onto the local snapshot file downloaded at bootstrap. Provide multiple URLs as fall back sources")
+	configFlagSet.String(CfgLocalSnapshotsDownloadSHA256, "", "hex encoded sha256 checksum of the local snapshot file to download. if set, the downloaded file is only loaded if it matches the checksum")
+	configFlagSet.Bool(CfgLocalSnapshotsSigningEnabled, false, "whether to sign created local snapshot files with the ed25519 key in the environment variable 'SNAPSHOT_SIGNING_KEY'")
+	configFlagSet.StringSlice(CfgLocalSnapshotsTrustedKeys, []string{}, "hex encoded ed25519 public keys which are trusted to sign local snapshot files. if set, only local snapshot files with a valid signature of one of these keys are loaded")
+	configFlagSet.String(CfgSnapshotUploadType, "", "the type of the storage the created local snapshot files are uploaded to ('s3', 'gcs' or 'webdav'). the secret is read from the environment variable 'SNAPSHOT_UPLOAD_SECRET'. if empty, the local snapshot files are not uploaded")
+	configFlagSet.String(CfgSnapshotUploadURL, "", "the URL of the bucket (and prefix) respectively the WebDAV directory the local snapshot files are uploaded to, e.g. 'https://s3.eu-central-1.amazonaws.com/bucket/mainnet' or 'https://storage.googleapis.com/bucket/mainnet'")
+	configFlagSet.String(CfgSnapshotUploadRegion, "us-east-1", "the region of the S3 bucket")
+	configFlagSet.String(CfgSnapshotUploadAccessKeyID, "", "the access key ID (S3 or GCS HMAC key) respectively the username (WebDAV) to upload the local snapshot files")
+	configFlagSet.Int(CfgSnapshotUploadRetention, 0, "the amount of uploaded scheduled snapshot files which are kept, older ones are removed (0 = keep all)")
 	configFlagSet.String(CfgGlobalSnapshotPath, "snapshotMainnet.txt", "path to the global snapshot file containing the ledger state")
 	configFlagSet.StringSlice(CfgGlobalSnapshotSpentAddressesPaths, []string{
 		"previousEpochsSpentAddresses1.txt",
@@ -43,5 +116,10 @@ func init() {
 	configFlagSet.Int(CfgGlobalSnapshotIndex, 1050000, "milestone index of the global snapshot")
 	configFlagSet.Bool(CfgPruningEnabled, true, "whether to delete old transaction data from the database")
 	configFlagSet.Int(CfgPruningDelay, 60480, "amount of milestone transactions to keep in the database")
+	configFlagSet.Int(CfgPruningRetentionHours, 0, "the amount of hours of history to keep in the database, based on the milestone timestamps. if set, it is used instead of the pruning delay (0 = use the pruning delay)")
+	configFlagSet.String(CfgPruningMaxDatabaseSize, "", "the maximum size of the database (e.g. '50GB'). if exceeded, more milestones than the pruning delay are pruned, but at least the minimum history is kept. if empty, only the pruning delay is used")
+	configFlagSet.Int(CfgPruningMaxDeletionsPerSecond, 0, "the maximum amount of transactions which are deleted per second by the pruning, to reduce the I/O load on slow disks (0 = unlimited)")
+	configFlagSet.Int(CfgPruningWorkerCount, 0, "the amount of workers which prune the transactions of a milestone in parallel (0 = amount of CPUs)")
+	configFlagSet.StringSlice(CfgPruningExpiryRules, []string{}, "rules to delete zero-value transactions with the given tag prefix after the given amount of milestones ('TAGPREFIX:milestones'), independent of the regular pruning")
 	configFlagSet.Bool(CfgSpentAddressesEnabled, true, "enable support for wereAddressesSpentFrom (needed for Trinity, but local snapshots are much bigger)")
 }
@@ -5,9 +5,30 @@ const (
 	CfgDatabasePath = "db.path"
 	// ignore the check for corrupted databases (should only be used for debug reasons)
 	CfgDatabaseDebug = "db.debug"
+	// whether to keep a filter of the stored transaction hashes to answer lookups of unknown transactions without a database read
+	CfgDatabaseTransactionFilterEnabled = "db.transactionFilter.enabled"
+	// the maximum amount of database entries scanned per second by the database consistency check (0 = unlimited)
+	CfgDatabaseConsistencyCheckMaxEntriesPerSecond = "db.consistencyCheck.maxEntriesPerSecond"
+	// the time in seconds after which the requests of a milestone cone which couldn't be solidified are sent to all neighbors (0 to disable)
+	CfgTangleSolidifierEscalationWidenFanOutSeconds = "tangle.solidifier.escalation.widenFanOutSeconds"
+	// the time in seconds after which the requests of a milestone cone which couldn't be solidified are preferably sent to neighbors with full history (0 to disable)
+	CfgTangleSolidifierEscalationPreferFullHistorySeconds = "tangle.solidifier.escalation.preferFullHistorySeconds"
+	// the time in seconds after which the solidification of a milestone is reported as stuck together with the missing transactions (0 to disable)
+	CfgTangleSolidifierEscalationStuckSeconds = "tangle.solidifier.escalation.stuckSeconds"
+	// the time in seconds without a new milestone after which a partition is suspected if all peers report the same latest milestone (0 to disable)
+	CfgTanglePartitionDetectionStaleSeconds = "tangle.partitionDetection.staleSeconds"
+	// the minimum amount of peers which have to report their latest milestone to detect a partition
+	CfgTanglePartitionDetectionMinPeers = "tangle.partitionDetection.minPeers"
 )
 
 func init() {
 	configFlagSet.String(CfgDatabasePath, "mainnetdb", "the path to the database folder")
 	configFlagSet.Bool(CfgDatabaseDebug, false, "ignore the check for corrupted databases (should only be used for debug reasons)")
+	configFlagSet.Bool(CfgDatabaseTransactionFilterEnabled, true, "whether to keep a filter of the stored transaction hashes to answer lookups of unknown transactions without a database read")
+	configFlagSet.Int(CfgDatabaseConsistencyCheckMaxEntriesPerSecond, 10000, "the maximum amount of database entries scanned per second by the database consistency check (0 = unlimited)")
+	configFlagSet.Int(CfgTangleSolidifierEscalationWidenFanOutSeconds, 30, "the time in seconds after which the requests of a milestone cone which couldn't be solidified are sent to all neighbors (0 to disable)")
+	configFlagSet.Int(CfgTangleSolidifierEscalationPreferFullHistorySeconds, 90, "the time in seconds after which the requests of a milestone cone which couldn't be solidified are preferably sent to neighbors with full history (0 to disable)")
+	configFlagSet.Int(CfgTangleSolidifierEscalationStuckSeconds, 300, "the time in seconds after which the solidification of a milestone is reported as stuck together with the missing transactions (0 to disable)")
+	configFlagSet.Int(CfgTanglePartitionDetectionStaleSeconds, 300, "the time in seconds without a new milestone after which a partition is suspected if all peers report the same latest milestone (0 to disable)")
+	configFlagSet.Int(CfgTanglePartitionDetectionMinPeers, 2, "the minimum amount of peers which have to report their latest milestone to detect a partition")
 }
@@ -27,6 +27,11 @@ const (
 	// CfgTipSelSpammerTipsThreshold is the maximum amount of tips in a tip-pool before the spammer tries to reduce these (0 = disable (semi-lazy), 0 = always (non-lazy))
 	// this is used to support the network if someone attacks the tangle by spamming a lot of tips
 	CfgTipSelSpammerTipsThreshold = "spammerTipsThreshold"
+	// CfgTipSelWriteBackMaxEntries is the maximum amount of transactions with updated root snapshot indexes
+	// which are held back before they are written to the database (0 = disabled, write immediately)
+	CfgTipSelWriteBackMaxEntries = "tipsel.writeBack.maxEntries"
+	// CfgTipSelWriteBackMaxDelayMilliseconds is the maximum time updated root snapshot indexes are held back before they are written to the database
+	CfgTipSelWriteBackMaxDelayMilliseconds = "tipsel.writeBack.maxDelayMilliseconds"
 )
 
 func init() {
@@ -50,4 +55,7 @@ func init() {
 		"before the tip is removed from the tip pool (semi-lazy)")
 	configFlagSet.Int(CfgTipSelSemiLazy+CfgTipSelSpammerTipsThreshold, 30, "the maximum amount of tips in a tip-pool (semi-lazy) before "+
 		"the spammer tries to reduce these (0 = disable)")
+	configFlagSet.Int(CfgTipSelWriteBackMaxEntries, 0, "the maximum amount of transactions with updated root snapshot indexes "+
+		"which are held back before they are written to the database (0 = disabled, write immediately)")
+	configFlagSet.Int(CfgTipSelWriteBackMaxDelayMilliseconds, 2000, "the maximum time updated root snapshot indexes are held back before they are written to the database")
 }
@@ -3,8 +3,17 @@ package config
 const (
 	// the used advancement range per warpsync checkpoint
 	CfgWarpSyncAdvancementRange = "warpsync.advancementRange"
+	// whether to fetch the missing milestones sequentially from the best peer if the node is only a few milestones behind after startup
+	CfgWarpSyncFastResyncEnabled = "warpsync.fastResync.enabled"
+	// the maximum amount of missing milestones for which the fast resync is used instead of the warpsync
+	CfgWarpSyncFastResyncMaxMilestones = "warpsync.fastResync.maxMilestones"
+	// the time in seconds to wait for a single milestone to become solid before falling back to the warpsync
+	CfgWarpSyncFastResyncMilestoneTimeoutSeconds = "warpsync.fastResync.milestoneTimeoutSeconds"
 )
 
 func init() {
 	configFlagSet.Int(CfgWarpSyncAdvancementRange, 50, "the used advancement range per warpsync checkpoint")
+	configFlagSet.Bool(CfgWarpSyncFastResyncEnabled, false, "whether to fetch the missing milestones sequentially from the best peer if the node is only a few milestones behind after startup")
+	configFlagSet.Int(CfgWarpSyncFastResyncMaxMilestones, 15, "the maximum amount of missing milestones for which the fast resync is used instead of the warpsync")
+	configFlagSet.Int(CfgWarpSyncFastResyncMilestoneTimeoutSeconds, 30, "the time in seconds to wait for a single milestone to become solid before falling back to the warpsync")
 }
@@ -0,0 +1,10 @@
+package config
+
+const (
+	// the time in seconds after which a core event loop, which didn't finish its current iteration, is reported as stalled
+	CfgWatchdogDeadlineSeconds = "watchdog.deadlineSeconds"
+)
+
+func init() {
+	configFlagSet.Int(CfgWatchdogDeadlineSeconds, 120, "the time in seconds after which a core event loop, which didn't finish its current iteration, is reported as stalled")
+}
@@ -0,0 +1,97 @@
+package dag
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gohornet/hornet/pkg/model/tangle"
+)
+
+var (
+	rootSnapshotIndexesWriteBack = &writeBack{entries: make(map[string]*tangle.CachedMetadata)}
+)
+
+// writeBack delays the write of updated transaction metadata to the database.
+// The cached metadata is retained until it is flushed, so repeated updates of the same transaction
+// within a confirmation burst are coalesced into a single write.
+type writeBack struct {
+	sync.Mutex
+
+	// the max amount of retained metadata before the write back is flushed. 0 disables the write back.
+	maxEntries int
+	// the max duration updated metadata is retained before it gets flushed.
+	maxDelay time.Duration
+
+	entries    map[string]*tangle.CachedMetadata
+	flushTimer *time.Timer
+}
+
+// ConfigureRootSnapshotIndexesWriteBack configures the delayed write back of updated transaction root snapshot indexes.
+// Updated metadata is written in batches of up to maxEntries, but not later than maxDelay after the update.
+// A maxEntries of 0 disables the write back.
+func ConfigureRootSnapshotIndexesWriteBack(maxEntries int, maxDelay time.Duration) {
+	FlushRootSnapshotIndexes()
+
+	rootSnapshotIndexesWriteBack.Lock()
+	defer rootSnapshotIndexesWriteBack.Unlock()
+
+	rootSnapshotIndexesWriteBack.maxEntries = maxEntries
+	rootSnapshotIndexesWriteBack.maxDelay = maxDelay
+}
+
+// FlushRootSnapshotIndexes writes all delayed transaction root snapshot index updates to the database.
+// It must be called before the storages are shut down.
+func FlushRootSnapshotIndexes() {
+	rootSnapshotIndexesWriteBack.Lock()
+	defer rootSnapshotIndexesWriteBack.Unlock()
+
+	rootSnapshotIndexesWriteBack.flush()
+}
+
+// add retains the given updated metadata until the write back is flushed.
+// meta pass +1
+func (w *writeBack) add(cachedTxMeta *tangle.CachedMetadata) {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.maxEntries == 0 {
+		cachedTxMeta.Release(true) // meta -1
+		return
+	}
+
+	txHash := string(cachedTxMeta.GetMetadata().GetTxHash())
+	if _, exists := w.entries[txHash]; exists {
+		// the metadata is already retained, the update is coalesced
+		cachedTxMeta.Release(true) // meta -1
+		return
+	}
+	w.entries[txHash] = cachedTxMeta
+
+	if len(w.entries) >= w.maxEntries {
+		w.flush()
+		return
+	}
+
+	if w.flushTimer == nil {
+		// bound the staleness of the retained updates
+		w.flushTimer = time.AfterFunc(w.maxDelay, func() {
+			w.Lock()
+			defer w.Unlock()
+			w.flush()
+		})
+	}
+}
+
+// flush releases all retained metadata, which writes the updates to the database.
+// the lock must be held.
+func (w *writeBack) flush() {
+	if w.flushTimer != nil {
+		w.flushTimer.Stop()
+		w.flushTimer = nil
+	}
+
+	for txHash, cachedTxMeta := range w.entries {
+		cachedTxMeta.Release(true) // meta -1
+		delete(w.entries, txHash)
+	}
+}
@@ -0,0 +1,133 @@
+package dag
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/hive.go/objectstorage"
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/transaction"
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/profile"
+)
+
+// configures empty storages and stores the given amount of transactions.
+func storeWriteBackTestTransactions(t *testing.T, count int) []hornet.Hash {
+	tangle.ConfigureStorages(mapdb.NewMapDB(), mapdb.NewMapDB(), mapdb.NewMapDB(), profile.Caches{})
+	t.Cleanup(tangle.ShutdownStorages)
+
+	txHashes := make([]hornet.Hash, count)
+	for i := range txHashes {
+		tx := hornet.NewTransactionFromTx(&transaction.Transaction{
+			Hash:              trinary.IntToTrytes(int64(i+1), consts.HashTrytesSize),
+			Bundle:            hornet.NullHashBytes.Trytes(),
+			TrunkTransaction:  hornet.NullHashBytes.Trytes(),
+			BranchTransaction: hornet.NullHashBytes.Trytes(),
+		}, nil)
+
+		cachedTx, _ := tangle.StoreTransactionIfAbsent(tx)
+		cachedTx.Release(true)
+		txHashes[i] = tx.GetTxHash()
+	}
+	return txHashes
+}
+
+// marks the metadata of the given transaction as updated and passes it to the write back.
+func updateWriteBackTestMetadata(w *writeBack, txHash hornet.Hash) {
+	cachedTxMeta := tangle.GetCachedTxMetadataOrNil(txHash) // meta +1
+	cachedTxMeta.GetMetadata().SetSolid(true)
+	w.add(cachedTxMeta) // meta pass +1
+}
+
+func writeBackEntries(w *writeBack) int {
+	w.Lock()
+	defer w.Unlock()
+	return len(w.entries)
+}
+
+func storedAsSolid(txHash hornet.Hash) bool {
+	storedTxMeta := tangle.GetStoredMetadataOrNil(txHash)
+	return storedTxMeta != nil && storedTxMeta.IsSolid()
+}
+
+func TestWriteBackCoalescing(t *testing.T) {
+	txHashes := storeWriteBackTestTransactions(t, 2)
+	w := &writeBack{entries: make(map[string]*tangle.CachedMetadata), maxEntries: 10, maxDelay: time.Hour}
+
+	// repeated updates of the same transaction are retained only once
+	updateWriteBackTestMetadata(w, txHashes[0])
+	updateWriteBackTestMetadata(w, txHashes[0])
+	updateWriteBackTestMetadata(w, txHashes[1])
+	assert.Equal(t, 2, writeBackEntries(w))
+
+	// the retained updates are not written yet
+	time.Sleep(2 * objectstorage.BatchWriterBatchTimeout)
+	assert.False(t, storedAsSolid(txHashes[0]))
+	assert.False(t, storedAsSolid(txHashes[1]))
+
+	w.Lock()
+	w.flush()
+	w.Unlock()
+
+	assert.Zero(t, writeBackEntries(w))
+	require.Eventually(t, func() bool { return storedAsSolid(txHashes[0]) && storedAsSolid(txHashes[1]) }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestWriteBackMaxEntries(t *testing.T) {
+	txHashes := storeWriteBackTestTransactions(t, 3)
+	w := &writeBack{entries: make(map[string]*tangle.CachedMetadata), maxEntries: 2, maxDelay: time.Hour}
+
+	updateWriteBackTestMetadata(w, txHashes[0])
+	assert.Equal(t, 1, writeBackEntries(w))
+	assert.NotNil(t, w.flushTimer)
+
+	// the write back is flushed as soon as it is full
+	updateWriteBackTestMetadata(w, txHashes[1])
+	assert.Zero(t, writeBackEntries(w))
+	assert.Nil(t, w.flushTimer)
+	require.Eventually(t, func() bool { return storedAsSolid(txHashes[0]) && storedAsSolid(txHashes[1]) }, 5*time.Second, 10*time.Millisecond)
+
+	updateWriteBackTestMetadata(w, txHashes[2])
+	assert.Equal(t, 1, writeBackEntries(w))
+
+	w.Lock()
+	w.flush()
+	w.Unlock()
+}
+
+func TestWriteBackTimer(t *testing.T) {
+	txHashes := storeWriteBackTestTransactions(t, 1)
+	w := &writeBack{entries: make(map[string]*tangle.CachedMetadata), maxEntries: 10, maxDelay: 50 * time.Millisecond}
+
+	// the retained updates are flushed after the max delay
+	updateWriteBackTestMetadata(w, txHashes[0])
+	assert.Equal(t, 1, writeBackEntries(w))
+	require.Eventually(t, func() bool { return writeBackEntries(w) == 0 }, 5*time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool { return storedAsSolid(txHashes[0]) }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestFlushRootSnapshotIndexes(t *testing.T) {
+	txHashes := storeWriteBackTestTransactions(t, 2)
+	defer ConfigureRootSnapshotIndexesWriteBack(0, 0)
+
+	ConfigureRootSnapshotIndexesWriteBack(10, time.Hour)
+	updateWriteBackTestMetadata(rootSnapshotIndexesWriteBack, txHashes[0])
+	assert.Equal(t, 1, writeBackEntries(rootSnapshotIndexesWriteBack))
+
+	FlushRootSnapshotIndexes()
+	assert.Zero(t, writeBackEntries(rootSnapshotIndexesWriteBack))
+	require.Eventually(t, func() bool { return storedAsSolid(txHashes[0]) }, 5*time.Second, 10*time.Millisecond)
+
+	// a disabled write back doesn't retain updates
+	ConfigureRootSnapshotIndexesWriteBack(0, time.Hour)
+	updateWriteBackTestMetadata(rootSnapshotIndexesWriteBack, txHashes[1])
+	assert.Zero(t, writeBackEntries(rootSnapshotIndexesWriteBack))
+	require.Eventually(t, func() bool { return storedAsSolid(txHashes[1]) }, 5*time.Second, 10*time.Millisecond)
+}
@@ -121,6 +121,9 @@ func GetTransactionRootSnapshotIndexes(cachedTxMeta *tangle.CachedMetadata, lsmi
 	// set the new transaction root snapshot indexes in the metadata of the transaction
 	cachedTxMeta.GetMetadata().SetRootSnapshotIndexes(youngestTxRootSnapshotIndex, oldestTxRootSnapshotIndex, lsmi)
 
+	// delay the write of the updated metadata to coalesce further updates in the same confirmation burst
+	rootSnapshotIndexesWriteBack.add(cachedTxMeta.Retain()) // meta pass +1
+
 	return youngestTxRootSnapshotIndex, oldestTxRootSnapshotIndex
 }
 
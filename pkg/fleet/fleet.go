@@ -0,0 +1,160 @@
+package fleet
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iotaledger/hive.go/syncutils"
+
+	"github.com/gohornet/hornet/pkg/model/milestone"
+)
+
+const (
+	// HeaderTimestamp is the HTTP header containing the unix timestamp of a status report.
+	HeaderTimestamp = "X-Fleet-Timestamp"
+	// HeaderSignature is the HTTP header containing the signature of a status report.
+	HeaderSignature = "X-Fleet-Signature"
+)
+
+var (
+	// ErrInvalidSignature is returned when the signature of a status report doesn't match the shared secret.
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrInvalidTimestamp is returned when the timestamp of a status report is missing or too far off the local time.
+	ErrInvalidTimestamp = errors.New("invalid timestamp")
+	// ErrInvalidStatus is returned when a status report doesn't contain a node name.
+	ErrInvalidStatus = errors.New("invalid status")
+)
+
+var (
+	// the top-level sections of the shared configuration which are not applied to the nodes of the fleet,
+	// as they are specific to every node or are evaluated before the shared configuration is fetched.
+	sharedConfigExcludedSections = []string{"fleet", "node", "logger"}
+)
+
+// Status is the status a node of the fleet reports to the aggregator.
+type Status struct {
+	Name                 string          `json:"name"`
+	AppVersion           string          `json:"appVersion"`
+	IsSynced             bool            `json:"isSynced"`
+	IsHealthy            bool            `json:"isHealthy"`
+	LatestMilestoneIndex milestone.Index `json:"latestMilestoneIndex"`
+	SolidMilestoneIndex  milestone.Index `json:"solidMilestoneIndex"`
+	PruningIndex         milestone.Index `json:"pruningIndex"`
+	ConnectedPeers       int             `json:"connectedPeers"`
+	DatabaseSizeBytes    int64           `json:"databaseSizeBytes"`
+}
+
+// NodeStatus is the latest status of a node of the fleet as seen by the aggregator.
+type NodeStatus struct {
+	*Status
+	// The time the latest status of the node was received.
+	LastSeen time.Time `json:"lastSeen"`
+	// Whether the node reported its status within the stale timeout.
+	Online bool `json:"online"`
+}
+
+// Sign returns the hex encoded HMAC-SHA256 signature of the given status report payload and timestamp.
+func Sign(secret []byte, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte{'.'})
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature of the given status report payload and that its timestamp
+// doesn't differ more than maxClockSkew from the local time, to prevent replays of old reports.
+func Verify(secret []byte, timestamp string, signature string, payload []byte, maxClockSkew time.Duration) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+
+	if skew := time.Since(time.Unix(ts, 0)); skew > maxClockSkew || skew < -maxClockSkew {
+		return ErrInvalidTimestamp
+	}
+
+	expected, err := hex.DecodeString(Sign(secret, ts, payload))
+	if err != nil {
+		return err
+	}
+
+	actual, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, actual) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// ParseSharedConfig parses the shared configuration of the fleet and removes the sections
+// which are specific to every node (fleet, node and logger).
+func ParseSharedConfig(data []byte) (map[string]interface{}, error) {
+	sharedConfig := make(map[string]interface{})
+	if err := json.Unmarshal(data, &sharedConfig); err != nil {
+		return nil, err
+	}
+
+	for section := range sharedConfig {
+		for _, excluded := range sharedConfigExcludedSections {
+			if strings.EqualFold(section, excluded) {
+				delete(sharedConfig, section)
+			}
+		}
+	}
+
+	return sharedConfig, nil
+}
+
+// Registry holds the latest status of every node of the fleet.
+type Registry struct {
+	nodes     map[string]*NodeStatus
+	nodesLock syncutils.RWMutex
+}
+
+// NewRegistry creates a new empty registry.
+func NewRegistry() *Registry {
+	return &Registry{nodes: make(map[string]*NodeStatus)}
+}
+
+// Update replaces the status of the node with the name given in the status.
+func (r *Registry) Update(status *Status) error {
+	if status == nil || status.Name == "" {
+		return ErrInvalidStatus
+	}
+
+	r.nodesLock.Lock()
+	defer r.nodesLock.Unlock()
+
+	r.nodes[status.Name] = &NodeStatus{Status: status, LastSeen: time.Now()}
+	return nil
+}
+
+// Nodes returns the latest status of all nodes sorted by name.
+// Nodes which didn't report within staleAfter are marked as offline.
+func (r *Registry) Nodes(staleAfter time.Duration) []*NodeStatus {
+	r.nodesLock.RLock()
+	defer r.nodesLock.RUnlock()
+
+	nodes := make([]*NodeStatus, 0, len(r.nodes))
+	for _, node := range r.nodes {
+		nodes = append(nodes, &NodeStatus{
+			Status:   node.Status,
+			LastSeen: node.LastSeen,
+			Online:   time.Since(node.LastSeen) <= staleAfter,
+		})
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+
+	return nodes
+}
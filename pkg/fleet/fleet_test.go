@@ -0,0 +1,56 @@
+package fleet_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/fleet"
+)
+
+func TestVerify(t *testing.T) {
+	secret := []byte("secret")
+	payload := []byte(`{"name":"node1"}`)
+
+	timestamp := time.Now().Unix()
+	signature := fleet.Sign(secret, timestamp, payload)
+
+	require.NoError(t, fleet.Verify(secret, strconv.FormatInt(timestamp, 10), signature, payload, time.Minute))
+
+	// tampered payload or wrong secret
+	require.True(t, errors.Is(fleet.Verify(secret, strconv.FormatInt(timestamp, 10), signature, []byte(`{"name":"node2"}`), time.Minute), fleet.ErrInvalidSignature))
+	require.True(t, errors.Is(fleet.Verify([]byte("other"), strconv.FormatInt(timestamp, 10), signature, payload, time.Minute), fleet.ErrInvalidSignature))
+
+	// replayed report
+	oldTimestamp := time.Now().Add(-2 * time.Minute).Unix()
+	require.True(t, errors.Is(fleet.Verify(secret, strconv.FormatInt(oldTimestamp, 10), fleet.Sign(secret, oldTimestamp, payload), payload, time.Minute), fleet.ErrInvalidTimestamp))
+	require.True(t, errors.Is(fleet.Verify(secret, "", signature, payload, time.Minute), fleet.ErrInvalidTimestamp))
+}
+
+func TestRegistry(t *testing.T) {
+	registry := fleet.NewRegistry()
+
+	require.True(t, errors.Is(registry.Update(&fleet.Status{}), fleet.ErrInvalidStatus))
+	require.NoError(t, registry.Update(&fleet.Status{Name: "node2"}))
+	require.NoError(t, registry.Update(&fleet.Status{Name: "node1"}))
+
+	nodes := registry.Nodes(time.Minute)
+	require.Len(t, nodes, 2)
+	require.Equal(t, "node1", nodes[0].Name)
+	require.True(t, nodes[0].Online)
+
+	nodes = registry.Nodes(0)
+	require.False(t, nodes[0].Online)
+}
+
+func TestParseSharedConfig(t *testing.T) {
+	sharedConfig, err := fleet.ParseSharedConfig([]byte(`{"snapshots":{"pruning":{"enabled":true}},"Fleet":{"sharedSecret":"other"},"node":{"alias":"node1"},"logger":{"level":"debug"}}`))
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"snapshots": map[string]interface{}{"pruning": map[string]interface{}{"enabled": true}}}, sharedConfig)
+
+	_, err = fleet.ParseSharedConfig([]byte(`["snapshots"]`))
+	require.Error(t, err)
+}
@@ -0,0 +1,149 @@
+// Package geoip looks up the country and the autonomous system (ASN) of IP addresses.
+// The lookups are served from an IP-to-ASN database in the TSV format of iptoasn.com,
+// where each line holds the first and the last IP address of a range, its AS number, its country code and the AS description.
+package geoip
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// the country code of ranges which aren't assigned to a country.
+	unknownCountry = "None"
+)
+
+var (
+	// ErrInvalidDatabase is returned when a line of the database can't be parsed.
+	ErrInvalidDatabase = errors.New("invalid geoip database")
+)
+
+// Location is the country and the autonomous system an IP address belongs to.
+type Location struct {
+	// The ISO 3166-1 alpha-2 code of the country, empty if unknown.
+	Country string
+	// The number of the autonomous system, 0 if the range isn't routed.
+	ASN uint32
+}
+
+// a range of IP addresses in their 16 byte representation.
+type ipRange struct {
+	first    net.IP
+	last     net.IP
+	location Location
+}
+
+// DB is an in-memory IP-to-ASN database.
+type DB struct {
+	ranges []ipRange
+}
+
+// Open reads the database at the given path, which may be gzip compressed if it ends with ".gz".
+func Open(path string) (*DB, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	return Read(r)
+}
+
+// Read parses the database from the given reader.
+func Read(r io.Reader) (*DB, error) {
+	db := &DB{}
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		ipRange, err := parseRange(line)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidDatabase, lineNumber, err)
+		}
+		db.ranges = append(db.ranges, ipRange)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(db.ranges, func(i, j int) bool {
+		return bytes.Compare(db.ranges[i].first, db.ranges[j].first) < 0
+	})
+
+	return db, nil
+}
+
+func parseRange(line string) (ipRange, error) {
+	fields := strings.Split(line, "\t")
+	if len(fields) < 4 {
+		return ipRange{}, errors.New("expected at least 4 fields")
+	}
+
+	first := net.ParseIP(fields[0])
+	last := net.ParseIP(fields[1])
+	if first == nil || last == nil || bytes.Compare(first.To16(), last.To16()) > 0 {
+		return ipRange{}, fmt.Errorf("invalid range '%s - %s'", fields[0], fields[1])
+	}
+
+	asn, err := strconv.ParseUint(fields[2], 10, 32)
+	if err != nil {
+		return ipRange{}, fmt.Errorf("invalid AS number '%s'", fields[2])
+	}
+
+	country := fields[3]
+	if country == unknownCountry {
+		country = ""
+	}
+
+	return ipRange{
+		first:    first.To16(),
+		last:     last.To16(),
+		location: Location{Country: country, ASN: uint32(asn)},
+	}, nil
+}
+
+// Lookup returns the location of the given IP address, or false if the address isn't within any range.
+func (db *DB) Lookup(ip net.IP) (Location, bool) {
+	ip = ip.To16()
+	if db == nil || ip == nil {
+		return Location{}, false
+	}
+
+	// the last range starting at or before the address
+	i := sort.Search(len(db.ranges), func(i int) bool {
+		return bytes.Compare(db.ranges[i].first, ip) > 0
+	}) - 1
+	if i < 0 || bytes.Compare(ip, db.ranges[i].last) > 0 {
+		return Location{}, false
+	}
+
+	return db.ranges[i].location, true
+}
+
+// Len returns the amount of ranges in the database.
+func (db *DB) Len() int {
+	return len(db.ranges)
+}
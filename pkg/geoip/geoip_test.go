@@ -0,0 +1,54 @@
+package geoip_test
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/geoip"
+)
+
+const database = `1.0.0.0	1.0.0.255	13335	US	CLOUDFLARENET
+1.0.4.0	1.0.7.255	38803	AU	GTELECOM-AUSTRALIA
+1.0.1.0	1.0.3.255	0	None	Not routed
+2001:200::	2001:200:ffff:ffff:ffff:ffff:ffff:ffff	2500	JP	WIDE-BB
+`
+
+func TestLookup(t *testing.T) {
+	db, err := geoip.Read(strings.NewReader(database))
+	require.NoError(t, err)
+	require.Equal(t, 4, db.Len())
+
+	location, found := db.Lookup(net.ParseIP("1.0.0.1"))
+	require.True(t, found)
+	require.Equal(t, geoip.Location{Country: "US", ASN: 13335}, location)
+
+	location, found = db.Lookup(net.ParseIP("1.0.7.255"))
+	require.True(t, found)
+	require.Equal(t, geoip.Location{Country: "AU", ASN: 38803}, location)
+
+	location, found = db.Lookup(net.ParseIP("1.0.2.1"))
+	require.True(t, found)
+	require.Equal(t, geoip.Location{}, location)
+
+	location, found = db.Lookup(net.ParseIP("2001:200::1"))
+	require.True(t, found)
+	require.Equal(t, geoip.Location{Country: "JP", ASN: 2500}, location)
+
+	_, found = db.Lookup(net.ParseIP("1.0.8.0"))
+	require.False(t, found)
+
+	_, found = db.Lookup(net.ParseIP("0.255.255.255"))
+	require.False(t, found)
+}
+
+func TestInvalidDatabase(t *testing.T) {
+	_, err := geoip.Read(strings.NewReader("1.0.0.255	1.0.0.0	13335	US	CLOUDFLARENET\n"))
+	require.True(t, errors.Is(err, geoip.ErrInvalidDatabase))
+
+	_, err = geoip.Read(strings.NewReader("1.0.0.0	1.0.0.255	AS13335	US	CLOUDFLARENET\n"))
+	require.True(t, errors.Is(err, geoip.ErrInvalidDatabase))
+}
@@ -0,0 +1,164 @@
+// Package keystore stores secrets encrypted with a passphrase.
+// The key is derived from the passphrase with scrypt and the secret is sealed with XChaCha20-Poly1305.
+package keystore
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// the version of the keystore format.
+	version = 1
+
+	// the scrypt parameters used for new keystores.
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+
+	// the maximum scrypt parameters of a keystore to decrypt, so that a crafted keystore
+	// can't make the node allocate huge amounts of memory or spend hours deriving the key.
+	maxScryptN = 1 << 18
+	maxScryptR = 8
+	maxScryptP = 4
+
+	saltSize = 32
+)
+
+var (
+	// ErrWrongPassphrase is returned when a keystore can't be decrypted with the given passphrase.
+	ErrWrongPassphrase = errors.New("wrong passphrase or corrupted keystore")
+	// ErrInvalidKeystore is returned when a keystore can't be parsed.
+	ErrInvalidKeystore = errors.New("invalid keystore")
+	// ErrEmptyPassphrase is returned when a secret should be encrypted without a passphrase.
+	ErrEmptyPassphrase = errors.New("empty passphrase")
+)
+
+// keystore is the serialized form of an encrypted secret.
+type keystore struct {
+	Version    int    `json:"version"`
+	Salt       []byte `json:"salt"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Encrypt encrypts the given secret with the given passphrase and returns the serialized keystore.
+func Encrypt(secret []byte, passphrase []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, ErrEmptyPassphrase
+	}
+
+	ks := &keystore{
+		Version: version,
+		Salt:    make([]byte, saltSize),
+		N:       scryptN,
+		R:       scryptR,
+		P:       scryptP,
+		Nonce:   make([]byte, chacha20poly1305.NonceSizeX),
+	}
+	if _, err := rand.Read(ks.Salt); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(ks.Nonce); err != nil {
+		return nil, err
+	}
+
+	aead, err := ks.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	ks.Ciphertext = aead.Seal(nil, ks.Nonce, secret, nil)
+
+	return json.MarshalIndent(ks, "", "  ")
+}
+
+// Decrypt decrypts the secret of the given serialized keystore with the given passphrase.
+func Decrypt(data []byte, passphrase []byte) ([]byte, error) {
+	ks := &keystore{}
+	if err := json.Unmarshal(data, ks); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKeystore, err)
+	}
+	if ks.Version != version {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidKeystore, ks.Version)
+	}
+	if len(ks.Nonce) != chacha20poly1305.NonceSizeX {
+		return nil, fmt.Errorf("%w: invalid nonce", ErrInvalidKeystore)
+	}
+	if ks.N > maxScryptN || ks.R > maxScryptR || ks.P > maxScryptP {
+		return nil, fmt.Errorf("%w: scrypt parameters exceed the limits (n=%d, r=%d, p=%d)", ErrInvalidKeystore, ks.N, ks.R, ks.P)
+	}
+
+	aead, err := ks.aead(passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKeystore, err)
+	}
+
+	secret, err := aead.Open(nil, ks.Nonce, ks.Ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return secret, nil
+}
+
+// derives the key from the passphrase and returns the cipher to seal or open the secret.
+func (ks *keystore) aead(passphrase []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, ks.Salt, ks.N, ks.R, ks.P, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, err
+	}
+	return chacha20poly1305.NewX(key)
+}
+
+// Load reads the keystore at the given path and decrypts its secret with the given passphrase.
+func Load(path string, passphrase []byte) ([]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Decrypt(data, passphrase)
+}
+
+// Store encrypts the given secret with the given passphrase and writes it to the given path.
+// The file is replaced atomically and is only readable by the owner.
+func Store(path string, secret []byte, passphrase []byte) error {
+	data, err := Encrypt(secret, passphrase)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,70 @@
+package keystore_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/keystore"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	secret := []byte("secret seed")
+
+	data, err := keystore.Encrypt(secret, []byte("passphrase"))
+	require.NoError(t, err)
+	require.NotContains(t, string(data), string(secret))
+
+	decrypted, err := keystore.Decrypt(data, []byte("passphrase"))
+	require.NoError(t, err)
+	require.Equal(t, secret, decrypted)
+
+	_, err = keystore.Decrypt(data, []byte("other"))
+	require.True(t, errors.Is(err, keystore.ErrWrongPassphrase))
+
+	_, err = keystore.Decrypt([]byte("{"), []byte("passphrase"))
+	require.True(t, errors.Is(err, keystore.ErrInvalidKeystore))
+
+	_, err = keystore.Encrypt(secret, nil)
+	require.True(t, errors.Is(err, keystore.ErrEmptyPassphrase))
+}
+
+func TestDecryptScryptLimits(t *testing.T) {
+	data, err := keystore.Encrypt([]byte("secret seed"), []byte("passphrase"))
+	require.NoError(t, err)
+
+	for _, param := range []string{"n", "r", "p"} {
+		ks := make(map[string]interface{})
+		require.NoError(t, json.Unmarshal(data, &ks))
+		ks[param] = 1 << 30
+
+		crafted, err := json.Marshal(ks)
+		require.NoError(t, err)
+
+		_, err = keystore.Decrypt(crafted, []byte("passphrase"))
+		require.True(t, errors.Is(err, keystore.ErrInvalidKeystore))
+	}
+}
+
+func TestStoreLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "identity.key")
+	require.NoError(t, keystore.Store(path, []byte("first"), []byte("passphrase")))
+	require.NoError(t, keystore.Store(path, []byte("second"), []byte("passphrase")))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	secret, err := keystore.Load(path, []byte("passphrase"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("second"), secret)
+}
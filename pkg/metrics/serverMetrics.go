@@ -1,9 +1,17 @@
 package metrics
 
 import (
+	"time"
+
 	"go.uber.org/atomic"
 )
 
+const (
+	// HopCountBuckets is the amount of buckets used to track the hop count distribution of received transactions.
+	// The last bucket also holds all higher hop counts.
+	HopCountBuckets = 16
+)
+
 var (
 	SharedServerMetrics = &ServerMetrics{}
 )
@@ -56,4 +64,52 @@ type ServerMetrics struct {
 	TipsNonLazy atomic.Uint32
 	// The number of semi-lazy tips.
 	TipsSemiLazy atomic.Uint32
+	// The number of received transactions per hop count.
+	TransactionHopCounts [HopCountBuckets]atomic.Uint32
+	// The number of transactions which were not relayed because they reached the hop limit.
+	HopLimitedTransactions atomic.Uint32
+	// The number of received transactions which were held back while a milestone was confirmed.
+	AdmissionBufferedTransactions atomic.Uint32
+	// The number of received transactions which were processed immediately because the admission buffer was full.
+	AdmissionBufferOverflows atomic.Uint32
+	// The number of transactions which were deleted by the expiry rules.
+	ExpiredTransactions atomic.Uint32
+	// The number of milestones which were confirmed while a local snapshot was created.
+	SnapshotDelayedMilestones atomic.Uint32
+	// The total time in milliseconds the confirmation of milestones waited for the ledger while a local snapshot was created.
+	SnapshotConfirmationDelay atomic.Uint64
+	// The number of transaction sends which were skipped because the peer already sent the transaction to the node.
+	SuppressedDuplicateBroadcasts atomic.Uint32
+	// The number of transaction sends which were skipped because the send queue of the peer was congested.
+	BackpressuredBroadcasts atomic.Uint32
+	// The time transactions received via gossip waited until they were stored.
+	RegularTransactionStoreLatency Latency
+	// The time transactions submitted by the operator waited until they were stored.
+	PriorityTransactionStoreLatency Latency
+	// The time regular broadcasts waited in the broadcast queue.
+	RegularBroadcastLatency Latency
+	// The time prioritized broadcasts (milestones and transactions submitted by the operator) waited in the broadcast queue.
+	PriorityBroadcastLatency Latency
+}
+
+// Latency tracks the amount and the total duration of observed waiting times.
+type Latency struct {
+	// The number of observed waiting times.
+	Count atomic.Uint32
+	// The total of the observed waiting times in microseconds.
+	TotalMicroseconds atomic.Uint64
+}
+
+// Observe adds the time since the given start to the latency metric.
+func (l *Latency) Observe(start time.Time) {
+	l.TotalMicroseconds.Add(uint64(time.Since(start).Microseconds()))
+	l.Count.Inc()
+}
+
+// IncTransactionHopCount increases the hop count distribution metric for the given hop count.
+func (sm *ServerMetrics) IncTransactionHopCount(hopCount byte) {
+	if int(hopCount) >= HopCountBuckets {
+		hopCount = HopCountBuckets - 1
+	}
+	sm.TransactionHopCounts[hopCount].Inc()
 }
@@ -314,7 +314,9 @@ func AddTransactionToStorage(hornetTx *hornet.Transaction, latestMilestoneIndex
 	}
 
 	// Force release Tag, Address, UnconfirmedTx since its not needed for solidification/confirmation
-	StoreTag(cachedTx.GetTransaction().GetTag(), cachedTx.GetTransaction().GetTxHash()).Release(true)
+	if tagIndexed(cachedTx.GetTransaction()) {
+		StoreTag(cachedTx.GetTransaction().GetTag(), cachedTx.GetTransaction().GetTxHash()).Release(true)
+	}
 
 	StoreAddress(cachedTx.GetTransaction().GetAddress(), cachedTx.GetTransaction().GetTxHash(), cachedTx.GetTransaction().IsValue()).Release(true)
 
@@ -18,4 +18,12 @@ const (
 	StorePrefixUnconfirmedTransactions byte = 14
 	StorePrefixSpentAddresses          byte = 15
 	StorePrefixAutopeering             byte = 16
+	StorePrefixPruningIntent           byte = 17
+	StorePrefixPeerTraffic             byte = 18
+	StorePrefixPeerReputation          byte = 19
+	StorePrefixRecentMessages          byte = 20
+	StorePrefixPeers                   byte = 21
+	StorePrefixPeerBans                byte = 22
+	StorePrefixStagedSnapshot          byte = 23
+	StorePrefixStagedSnapshotLedger    byte = 24
 )
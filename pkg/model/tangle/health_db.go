@@ -7,7 +7,7 @@ import (
 )
 
 const (
-	DbVersion = 2
+	DbVersion = 3
 )
 
 var (
@@ -95,16 +95,31 @@ func UpdateDatabaseVersion() bool {
 
 	currentDbVersion := int(value[0])
 
-	if currentDbVersion == 1 && DbVersion == 2 {
+	if currentDbVersion == 1 {
 		// add information about trunk and branch to transaction metadata
 		if err := migrateVersionOneToVersionTwo(); err != nil {
 			panic(errors.Wrap(NewDatabaseError(err), "failed to migrate database to new version"))
 		}
-		setDatabaseVersion()
-		return true
+		currentDbVersion = 2
 	}
 
-	return false
+	if currentDbVersion == 2 {
+		// recompress the ledger diffs
+		if err := migrateVersionTwoToVersionThree(); err != nil {
+			panic(errors.Wrap(NewDatabaseError(err), "failed to migrate database to new version"))
+		}
+		currentDbVersion = 3
+	}
+
+	if currentDbVersion != DbVersion {
+		return false
+	}
+
+	if err := healthStore.Set([]byte("dbVersion"), []byte{DbVersion}); err != nil {
+		panic(errors.Wrap(NewDatabaseError(err), "failed to set database version"))
+	}
+
+	return true
 }
 
 func migrateVersionOneToVersionTwo() error {
@@ -112,3 +127,8 @@ func migrateVersionOneToVersionTwo() error {
 	// trunk an branch hashes were added to the metadata
 	return nil
 }
+
+func migrateVersionTwoToVersionThree() error {
+	// the ledger diffs are stored in a compressed format with one entry per milestone
+	return migrateLedgerDiffsToCompressedFormat()
+}
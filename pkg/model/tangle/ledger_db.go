@@ -3,6 +3,7 @@ package tangle
 import (
 	"encoding/binary"
 	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/pkg/errors"
@@ -25,8 +26,21 @@ var (
 	ledgerTransactionLock sync.RWMutex
 
 	ledgerMilestoneIndex milestone.Index
+
+	// the last stored ledger diff, it is the base of the next one.
+	lastStoredLedgerDiff *resolvedLedgerDiff
+	// protects the last stored ledger diff, which is also read without holding the ledger lock.
+	lastStoredLedgerDiffLock sync.RWMutex
 )
 
+// resolvedLedgerDiff is a decoded ledger diff with the resolved addresses.
+type resolvedLedgerDiff struct {
+	index     milestone.Index
+	depth     byte
+	addresses []string
+	changes   []int64
+}
+
 func ReadLockLedger() {
 	ledgerTransactionLock.RLock()
 }
@@ -57,8 +71,8 @@ func databaseKeyForAddress(address hornet.Hash) []byte {
 	return address[:49]
 }
 
-func databaseKeyForLedgerDiffAndAddress(milestoneIndex milestone.Index, address hornet.Hash) []byte {
-	return append(databaseKeyForMilestoneIndex(milestoneIndex), address[:49]...)
+func databaseKeyForLedgerDiff(milestoneIndex milestone.Index) []byte {
+	return databaseKeyForMilestoneIndex(milestoneIndex)
 }
 
 func bytesFromBalance(balance uint64) []byte {
@@ -71,12 +85,6 @@ func balanceFromBytes(bytes []byte) uint64 {
 	return binary.LittleEndian.Uint64(bytes)
 }
 
-func bytesFromDiff(diff int64) []byte {
-	bytes := make([]byte, 8)
-	binary.LittleEndian.PutUint64(bytes, uint64(diff))
-	return bytes
-}
-
 func diffFromBytes(bytes []byte) int64 {
 	return int64(balanceFromBytes(bytes))
 }
@@ -122,50 +130,122 @@ func GetBalanceForAddress(address hornet.Hash) (uint64, milestone.Index, error)
 	return GetBalanceForAddressWithoutLocking(address)
 }
 
-func DeleteLedgerDiffForMilestone(index milestone.Index) error {
+// loadLastStoredLedgerDiff returns the last stored ledger diff or nil if it isn't cached.
+func loadLastStoredLedgerDiff() *resolvedLedgerDiff {
+	lastStoredLedgerDiffLock.RLock()
+	defer lastStoredLedgerDiffLock.RUnlock()
+	return lastStoredLedgerDiff
+}
 
-	WriteLockLedger()
-	defer WriteUnlockLedger()
+// storeLastStoredLedgerDiff caches the given ledger diff as the last stored one.
+func storeLastStoredLedgerDiff(diff *resolvedLedgerDiff) {
+	lastStoredLedgerDiffLock.Lock()
+	defer lastStoredLedgerDiffLock.Unlock()
+	lastStoredLedgerDiff = diff
+}
 
-	if err := ledgerDiffStore.DeletePrefix(databaseKeyForMilestoneIndex(index)); err != nil {
-		return errors.Wrap(NewDatabaseError(err), "failed to delete ledger diff")
-	}
+// resolveLedgerDiffWithoutLocking reads the ledger diff of the given milestone and resolves
+// the referenced addresses of the previous milestones.
+// Returns nil if no ledger diff exists for the milestone.
+// The ledger lock doesn't have to be held for already confirmed milestones, since their ledger diffs
+// don't change anymore, and the cached last stored ledger diff is protected by its own lock.
+func resolveLedgerDiffWithoutLocking(index milestone.Index) (*resolvedLedgerDiff, error) {
+	return NewLedgerDiffIterator().resolve(index)
+}
 
-	return nil
+// LedgerDiffIterator returns the ledger diffs of consecutive milestones.
+// The resolved ledger diffs are kept and reused as the base of the following ones,
+// so every ledger diff is only decoded once, instead of decoding all the referenced
+// ledger diffs of the previous milestones again for every milestone.
+// The milestones can be iterated in ascending or descending order.
+type LedgerDiffIterator struct {
+	resolved map[milestone.Index]*resolvedLedgerDiff
 }
 
-// GetLedgerDiffForMilestoneWithoutLocking returns the ledger changes of that specific milestone.
-// ReadLockLedger must be held while entering this function.
-func GetLedgerDiffForMilestoneWithoutLocking(index milestone.Index, abortSignal <-chan struct{}) (map[string]int64, error) {
+// NewLedgerDiffIterator creates a new LedgerDiffIterator.
+func NewLedgerDiffIterator() *LedgerDiffIterator {
+	return &LedgerDiffIterator{resolved: make(map[milestone.Index]*resolvedLedgerDiff)}
+}
 
-	diff := make(map[string]int64)
+// resolve returns the resolved ledger diff of the given milestone, nil if no ledger diff exists for the milestone.
+func (it *LedgerDiffIterator) resolve(index milestone.Index) (*resolvedLedgerDiff, error) {
 
-	keyPrefix := databaseKeyForMilestoneIndex(index)
+	if resolvedDiff, exists := it.resolved[index]; exists {
+		return resolvedDiff, nil
+	}
 
-	aborted := false
-	err := ledgerDiffStore.Iterate(keyPrefix, func(key kvstore.Key, value kvstore.Value) bool {
-		select {
-		case <-abortSignal:
-			aborted = true
-			return false
-		default:
+	if lastDiff := loadLastStoredLedgerDiff(); lastDiff != nil && lastDiff.index == index {
+		return lastDiff, nil
+	}
+
+	value, err := ledgerDiffStore.Get(databaseKeyForLedgerDiff(index))
+	if err != nil {
+		if err == kvstore.ErrKeyNotFound {
+			return nil, nil
 		}
-		// Remove prefix from key
-		diff[string(key[len(keyPrefix):len(keyPrefix)+49])] = diffFromBytes(value)
-		return true
-	})
+		return nil, errors.Wrap(NewDatabaseError(err), "failed to retrieve ledger diff")
+	}
 
+	depth, err := ledgerDiffDepth(value)
 	if err != nil {
-		return nil, err
+		return nil, errors.Wrapf(err, "milestone %d", index)
 	}
 
-	if aborted {
+	var baseAddresses []string
+	if depth > 0 {
+		// the depth is limited, so the recursion is as well
+		baseDiff, err := it.resolve(index - 1)
+		if err != nil {
+			return nil, err
+		}
+		if baseDiff == nil {
+			return nil, errors.Wrapf(ErrInvalidLedgerDiff, "milestone %d references the missing ledger diff of milestone %d", index, index-1)
+		}
+		baseAddresses = baseDiff.addresses
+	}
+
+	addresses, changes, err := decodeLedgerDiff(value, baseAddresses)
+	if err != nil {
+		return nil, errors.Wrapf(err, "milestone %d", index)
+	}
+
+	resolvedDiff := &resolvedLedgerDiff{index: index, depth: depth, addresses: addresses, changes: changes}
+
+	// only the ledger diffs which can still be referenced by the following milestones in either direction are kept
+	for resolvedIndex := range it.resolved {
+		if resolvedIndex+ledgerDiffMaxDepth+1 < index || resolvedIndex > index+ledgerDiffMaxDepth+1 {
+			delete(it.resolved, resolvedIndex)
+		}
+	}
+	it.resolved[index] = resolvedDiff
+
+	return resolvedDiff, nil
+}
+
+// LedgerDiffWithoutLocking returns the ledger changes of that specific milestone.
+// ReadLockLedger must be held while entering this function.
+func (it *LedgerDiffIterator) LedgerDiffWithoutLocking(index milestone.Index, abortSignal <-chan struct{}) (map[string]int64, error) {
+
+	select {
+	case <-abortSignal:
 		return nil, ErrOperationAborted
+	default:
+	}
+
+	resolvedDiff, err := it.resolve(index)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := make(map[string]int64)
+	if resolvedDiff == nil {
+		return diff, nil
 	}
 
 	var diffSum int64
-	for _, change := range diff {
-		diffSum += change
+	for i, address := range resolvedDiff.addresses {
+		diff[address] = resolvedDiff.changes[i]
+		diffSum += resolvedDiff.changes[i]
 	}
 
 	if diffSum != 0 {
@@ -175,14 +255,169 @@ func GetLedgerDiffForMilestoneWithoutLocking(index milestone.Index, abortSignal
 	return diff, nil
 }
 
+// LedgerDiff returns the ledger changes of that specific milestone.
+func (it *LedgerDiffIterator) LedgerDiff(index milestone.Index, abortSignal <-chan struct{}) (map[string]int64, error) {
+
+	ReadLockLedger()
+	defer ReadUnlockLedger()
+
+	return it.LedgerDiffWithoutLocking(index, abortSignal)
+}
+
+// storeLedgerDiffWithoutLocking encodes the ledger diff of the given milestone and adds it to the batch.
+// The addresses which were already changed by the previous milestone are deduplicated.
+// WriteLockLedger must be held while entering this function.
+func storeLedgerDiffWithoutLocking(batch kvstore.BatchedMutations, diff map[string]int64, index milestone.Index) (*resolvedLedgerDiff, error) {
+
+	baseDiff, err := resolveLedgerDiffWithoutLocking(index - 1)
+	if err != nil {
+		return nil, err
+	}
+
+	var depth byte
+	var baseAddresses []string
+	if baseDiff != nil && baseDiff.depth < ledgerDiffMaxDepth {
+		depth = baseDiff.depth + 1
+		baseAddresses = baseDiff.addresses
+	}
+
+	value, addresses, changes := encodeLedgerDiff(diff, baseAddresses, depth)
+	batch.Set(databaseKeyForLedgerDiff(index), value)
+
+	return &resolvedLedgerDiff{index: index, depth: depth, addresses: addresses, changes: changes}, nil
+}
+
+// migrateLedgerDiffsToCompressedFormat converts the ledger diffs, which were stored with one entry per milestone and address,
+// to the compressed format with one entry per milestone.
+func migrateLedgerDiffsToCompressedFormat() error {
+
+	WriteLockLedger()
+	defer WriteUnlockLedger()
+
+	// the old format used the milestone index and the address as key
+	const oldKeyLength = 4 + 49
+
+	msIndexesMap := make(map[milestone.Index]struct{})
+	if err := ledgerDiffStore.IterateKeys(kvstore.EmptyPrefix, func(key kvstore.Key) bool {
+		if len(key) == oldKeyLength {
+			msIndexesMap[milestoneIndexFromDatabaseKey(key[:4])] = struct{}{}
+		}
+		return true
+	}); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to iterate ledger diffs")
+	}
+
+	msIndexes := make([]milestone.Index, 0, len(msIndexesMap))
+	for msIndex := range msIndexesMap {
+		msIndexes = append(msIndexes, msIndex)
+	}
+
+	// the ledger diffs are converted in ascending order, so every diff can reference the already converted previous one
+	sort.Slice(msIndexes, func(i, j int) bool { return msIndexes[i] < msIndexes[j] })
+
+	for _, msIndex := range msIndexes {
+		batch := ledgerDiffStore.Batched()
+		diff := make(map[string]int64)
+
+		keyPrefix := databaseKeyForLedgerDiff(msIndex)
+		if err := ledgerDiffStore.Iterate(keyPrefix, func(key kvstore.Key, value kvstore.Value) bool {
+			if len(key) == oldKeyLength {
+				diff[string(key[len(keyPrefix):])] = diffFromBytes(value)
+				batch.Delete(key)
+			}
+			return true
+		}); err != nil {
+			return errors.Wrap(NewDatabaseError(err), "failed to iterate ledger diffs")
+		}
+
+		storedDiff, err := storeLedgerDiffWithoutLocking(batch, diff, msIndex)
+		if err != nil {
+			return err
+		}
+
+		if err := batch.Commit(); err != nil {
+			return errors.Wrap(NewDatabaseError(err), "failed to store ledger diff")
+		}
+		storeLastStoredLedgerDiff(storedDiff)
+	}
+
+	return nil
+}
+
+func DeleteLedgerDiffForMilestone(index milestone.Index) error {
+
+	WriteLockLedger()
+	defer WriteUnlockLedger()
+
+	batch := ledgerDiffStore.Batched()
+
+	// the ledger diff of the next milestone may reference the addresses of the deleted one,
+	// so it has to be stored without references.
+	nextDiff, err := resolveLedgerDiffWithoutLocking(index + 1)
+	if err != nil {
+		return err
+	}
+	if nextDiff != nil && nextDiff.depth > 0 {
+		diff := make(map[string]int64, len(nextDiff.addresses))
+		for i, address := range nextDiff.addresses {
+			diff[address] = nextDiff.changes[i]
+		}
+
+		value, _, _ := encodeLedgerDiff(diff, nil, 0)
+		batch.Set(databaseKeyForLedgerDiff(index+1), value)
+	}
+
+	batch.Delete(databaseKeyForLedgerDiff(index))
+
+	lastStoredLedgerDiffLock.Lock()
+	if lastDiff := lastStoredLedgerDiff; lastDiff != nil && (lastDiff.index == index || lastDiff.index == index+1) {
+		lastStoredLedgerDiff = nil
+	}
+	lastStoredLedgerDiffLock.Unlock()
+
+	if err := batch.Commit(); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to delete ledger diff")
+	}
+
+	return nil
+}
+
+// GetLedgerDiffForMilestoneWithoutLocking returns the ledger changes of that specific milestone.
+// ReadLockLedger must be held while entering this function.
+func GetLedgerDiffForMilestoneWithoutLocking(index milestone.Index, abortSignal <-chan struct{}) (map[string]int64, error) {
+	return NewLedgerDiffIterator().LedgerDiffWithoutLocking(index, abortSignal)
+}
+
 // LedgerDiffHashConsumer consumes the given ledger diff addresses during looping through all ledger diffs in the persistence layer.
 type LedgerDiffHashConsumer func(msIndex milestone.Index, address hornet.Hash) bool
 
 // ForEachLedgerDiffHash loops over all ledger diffs.
 func ForEachLedgerDiffHash(consumer LedgerDiffHashConsumer, skipCache bool) {
+
+	var msIndexes []milestone.Index
 	ledgerDiffStore.IterateKeys([]byte{}, func(key kvstore.Key) bool {
-		return consumer(milestone.Index(binary.LittleEndian.Uint32(key[:4])), key[4:53])
+		msIndexes = append(msIndexes, milestoneIndexFromDatabaseKey(key[:4]))
+		return true
 	})
+
+	// the ledger diffs are resolved in ascending order, so every ledger diff can reuse the previous one
+	sort.Slice(msIndexes, func(i, j int) bool { return msIndexes[i] < msIndexes[j] })
+
+	ledgerDiffs := NewLedgerDiffIterator()
+	for _, msIndex := range msIndexes {
+		ReadLockLedger()
+		resolvedDiff, err := ledgerDiffs.resolve(msIndex)
+		ReadUnlockLedger()
+		if err != nil || resolvedDiff == nil {
+			continue
+		}
+
+		for _, address := range resolvedDiff.addresses {
+			if !consumer(msIndex, hornet.Hash(address)) {
+				return
+			}
+		}
+	}
 }
 
 func GetLedgerDiffForMilestone(index milestone.Index, abortSignal <-chan struct{}) (map[string]int64, error) {
@@ -195,52 +430,77 @@ func GetLedgerDiffForMilestone(index milestone.Index, abortSignal <-chan struct{
 
 func GetLedgerStateForMilestoneWithoutLocking(targetIndex milestone.Index, abortSignal <-chan struct{}) (map[string]uint64, milestone.Index, error) {
 
+	balances, solidMilestoneIndex, targetIndex, err := copyLedgerStateForMilestoneWithoutLocking(targetIndex, abortSignal)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := rollbackLedgerState(balances, solidMilestoneIndex, targetIndex, abortSignal); err != nil {
+		return nil, 0, err
+	}
+
+	return balances, targetIndex, nil
+}
+
+// copyLedgerStateForMilestoneWithoutLocking checks the target index and copies the balances of the current solid milestone.
+// ReadLockLedger must be held while entering this function.
+func copyLedgerStateForMilestoneWithoutLocking(targetIndex milestone.Index, abortSignal <-chan struct{}) (map[string]uint64, milestone.Index, milestone.Index, error) {
+
 	solidMilestoneIndex := GetSolidMilestoneIndex()
 	if targetIndex == 0 {
 		targetIndex = solidMilestoneIndex
 	}
 
 	if targetIndex > solidMilestoneIndex {
-		return nil, 0, fmt.Errorf("target index is too new. maximum: %d, actual: %d", solidMilestoneIndex, targetIndex)
+		return nil, 0, 0, fmt.Errorf("target index is too new. maximum: %d, actual: %d", solidMilestoneIndex, targetIndex)
 	}
 
 	if targetIndex <= snapshot.PruningIndex {
-		return nil, 0, fmt.Errorf("target index is too old. minimum: %d, actual: %d", snapshot.PruningIndex+1, targetIndex)
+		return nil, 0, 0, fmt.Errorf("target index is too old. minimum: %d, actual: %d", snapshot.PruningIndex+1, targetIndex)
 	}
 
 	balances, ledgerMilestone, err := GetLedgerStateForLSMIWithoutLocking(abortSignal)
 	if err != nil {
 		if err == ErrOperationAborted {
-			return nil, 0, err
+			return nil, 0, 0, err
 		}
-		return nil, 0, fmt.Errorf("GetLedgerStateForLSMI failed! %v", err)
+		return nil, 0, 0, fmt.Errorf("GetLedgerStateForLSMI failed! %v", err)
 	}
 
 	if ledgerMilestone != solidMilestoneIndex {
-		return nil, 0, fmt.Errorf("LedgerMilestone wrong! %d/%d", ledgerMilestone, solidMilestoneIndex)
+		return nil, 0, 0, fmt.Errorf("LedgerMilestone wrong! %d/%d", ledgerMilestone, solidMilestoneIndex)
 	}
 
+	return balances, solidMilestoneIndex, targetIndex, nil
+}
+
+// rollbackLedgerState reverts the ledger diffs of the milestones between the solid milestone index and the target index.
+// The ledger diffs of already confirmed milestones are never modified, only pruned.
+func rollbackLedgerState(balances map[string]uint64, solidMilestoneIndex milestone.Index, targetIndex milestone.Index, abortSignal <-chan struct{}) error {
+
+	ledgerDiffs := NewLedgerDiffIterator()
+
 	// Calculate balances for targetIndex
 	for milestoneIndex := solidMilestoneIndex; milestoneIndex > targetIndex; milestoneIndex-- {
-		diff, err := GetLedgerDiffForMilestoneWithoutLocking(milestoneIndex, abortSignal)
+		diff, err := ledgerDiffs.LedgerDiffWithoutLocking(milestoneIndex, abortSignal)
 		if err != nil {
 			if err == ErrOperationAborted {
-				return nil, 0, err
+				return err
 			}
-			return nil, 0, fmt.Errorf("GetLedgerDiffForMilestone: %v", err)
+			return fmt.Errorf("GetLedgerDiffForMilestone: %v", err)
 		}
 
 		for address, change := range diff {
 			select {
 			case <-abortSignal:
-				return nil, 0, ErrOperationAborted
+				return ErrOperationAborted
 			default:
 			}
 
 			newBalance := int64(balances[address]) - change
 
 			if newBalance < 0 {
-				return nil, 0, fmt.Errorf("Ledger diff for milestone %d creates negative balance for address %s: current %d, diff %d", milestoneIndex, hornet.Hash(address).Trytes(), balances[address], change)
+				return fmt.Errorf("Ledger diff for milestone %d creates negative balance for address %s: current %d, diff %d", milestoneIndex, hornet.Hash(address).Trytes(), balances[address], change)
 			} else if newBalance == 0 {
 				delete(balances, address)
 			} else {
@@ -248,7 +508,8 @@ func GetLedgerStateForMilestoneWithoutLocking(targetIndex milestone.Index, abort
 			}
 		}
 	}
-	return balances, targetIndex, nil
+
+	return nil
 }
 
 func GetLedgerStateForMilestone(targetIndex milestone.Index, abortSignal <-chan struct{}) (map[string]uint64, milestone.Index, error) {
@@ -259,6 +520,26 @@ func GetLedgerStateForMilestone(targetIndex milestone.Index, abortSignal <-chan
 	return GetLedgerStateForMilestoneWithoutLocking(targetIndex, abortSignal)
 }
 
+// GetLedgerStateForMilestoneWithoutBlocking returns all balances for the given milestone.
+// The ledger lock is only held while the balances of the solid milestone are copied,
+// the ledger diffs are reverted afterwards without blocking the confirmation of new milestones.
+// The ledger diffs must not be pruned while this function is running.
+func GetLedgerStateForMilestoneWithoutBlocking(targetIndex milestone.Index, abortSignal <-chan struct{}) (map[string]uint64, milestone.Index, error) {
+
+	ReadLockLedger()
+	balances, solidMilestoneIndex, targetIndex, err := copyLedgerStateForMilestoneWithoutLocking(targetIndex, abortSignal)
+	ReadUnlockLedger()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := rollbackLedgerState(balances, solidMilestoneIndex, targetIndex, abortSignal); err != nil {
+		return nil, 0, err
+	}
+
+	return balances, targetIndex, nil
+}
+
 // ApplyLedgerDiffWithoutLocking applies the changes to the ledger.
 // WriteLockLedger must be held while entering this function.
 func ApplyLedgerDiffWithoutLocking(diff map[string]int64, index milestone.Index) error {
@@ -266,6 +547,11 @@ func ApplyLedgerDiffWithoutLocking(diff map[string]int64, index milestone.Index)
 	balanceBatch := ledgerBalanceStore.Batched()
 	diffBatch := ledgerDiffStore.Batched()
 
+	storedDiff, err := storeLedgerDiffWithoutLocking(diffBatch, diff, index)
+	if err != nil {
+		return err
+	}
+
 	var diffSum int64
 
 	for address, change := range diff {
@@ -287,9 +573,6 @@ func ApplyLedgerDiffWithoutLocking(diff map[string]int64, index milestone.Index)
 			balanceBatch.Delete(databaseKeyForAddress(hornet.Hash(address)))
 		}
 
-		//Save diff
-		diffBatch.Set(databaseKeyForLedgerDiffAndAddress(index, hornet.Hash(address)), bytesFromDiff(change))
-
 		diffSum += change
 	}
 
@@ -300,6 +583,7 @@ func ApplyLedgerDiffWithoutLocking(diff map[string]int64, index milestone.Index)
 	if err := diffBatch.Commit(); err != nil {
 		return errors.Wrap(NewDatabaseError(err), "failed to store ledger diff")
 	}
+	storeLastStoredLedgerDiff(storedDiff)
 
 	if err := balanceBatch.Commit(); err != nil {
 		return errors.Wrap(NewDatabaseError(err), "failed to store ledger balance")
@@ -318,6 +602,12 @@ func StoreLedgerBalancesInDatabase(balances map[string]uint64, index milestone.I
 	WriteLockLedger()
 	defer WriteUnlockLedger()
 
+	return storeLedgerBalancesInDatabaseWithoutLocking(balances, index)
+}
+
+// WriteLockLedger must be held while entering this function.
+func storeLedgerBalancesInDatabaseWithoutLocking(balances map[string]uint64, index milestone.Index) error {
+
 	// Delete all ledger balances
 	if err := ledgerBalanceStore.Clear(); err != nil {
 		return errors.Wrap(NewDatabaseError(err), "failed to delete ledger balances")
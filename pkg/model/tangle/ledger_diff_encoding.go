@@ -0,0 +1,157 @@
+package tangle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// the maximum amount of consecutive ledger diffs which reference the addresses of their previous milestone.
+	// this limits the amount of ledger diffs which have to be decoded to resolve the addresses of a single ledger diff.
+	ledgerDiffMaxDepth = 15
+)
+
+var (
+	// ErrInvalidLedgerDiff is returned if a stored ledger diff could not be decoded.
+	ErrInvalidLedgerDiff = errors.New("invalid ledger diff")
+)
+
+// encodeLedgerDiff encodes the changes of a milestone in a compact form.
+// The addresses of the diff are sorted, every address which is also part of the base addresses
+// (the addresses of the previous milestone's diff) is stored as a delta encoded reference instead of the full address.
+// The changes are stored as signed varints.
+//
+// Format:
+//
+//	depth		byte	(0 if the diff doesn't reference the previous one)
+//	count		uvarint
+//	entries:
+//		ref		uvarint	(0 = the address follows, otherwise the distance to the previously referenced base address)
+//		address	[49]byte	(only if ref == 0)
+//		change	varint
+//
+// Returns the encoded diff and the sorted addresses of the diff with their changes.
+func encodeLedgerDiff(diff map[string]int64, baseAddresses []string, depth byte) ([]byte, []string, []int64) {
+
+	keys := make([]string, 0, len(diff))
+	for key := range diff {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	if depth == 0 {
+		baseAddresses = nil
+	}
+
+	buf := make([]byte, binary.MaxVarintLen64)
+	var encoded bytes.Buffer
+	encoded.WriteByte(depth)
+	encoded.Write(buf[:binary.PutUvarint(buf, uint64(len(keys)))])
+
+	addresses := make([]string, 0, len(keys))
+	changes := make([]int64, 0, len(keys))
+
+	// both address lists are sorted, so the references are strictly increasing
+	var basePos, lastRef int
+	for _, key := range keys {
+		address := key[:49]
+		addresses = append(addresses, address)
+		changes = append(changes, diff[key])
+
+		for basePos < len(baseAddresses) && baseAddresses[basePos] < address {
+			basePos++
+		}
+
+		if basePos < len(baseAddresses) && baseAddresses[basePos] == address {
+			// positions are 1-based, 0 marks a full address
+			ref := basePos + 1
+			encoded.Write(buf[:binary.PutUvarint(buf, uint64(ref-lastRef))])
+			lastRef = ref
+		} else {
+			encoded.WriteByte(0)
+			encoded.WriteString(address)
+		}
+
+		encoded.Write(buf[:binary.PutVarint(buf, diff[key])])
+	}
+
+	return encoded.Bytes(), addresses, changes
+}
+
+// ledgerDiffDepth returns the depth of an encoded ledger diff.
+// A depth of 0 means the diff can be decoded without the addresses of the previous milestone.
+func ledgerDiffDepth(encoded []byte) (byte, error) {
+	if len(encoded) == 0 {
+		return 0, ErrInvalidLedgerDiff
+	}
+	return encoded[0], nil
+}
+
+// decodeLedgerDiff decodes a ledger diff which was encoded with encodeLedgerDiff.
+// The base addresses must be the addresses of the previous milestone's diff if the depth of the diff is not 0.
+// Returns the sorted addresses and their changes.
+func decodeLedgerDiff(encoded []byte, baseAddresses []string) ([]string, []int64, error) {
+
+	reader := bytes.NewReader(encoded)
+
+	depth, err := reader.ReadByte()
+	if err != nil {
+		return nil, nil, errors.Wrap(ErrInvalidLedgerDiff, err.Error())
+	}
+
+	count, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return nil, nil, errors.Wrap(ErrInvalidLedgerDiff, err.Error())
+	}
+
+	// every entry needs at least two bytes, this protects against huge allocations
+	if count > uint64(len(encoded)/2) {
+		return nil, nil, errors.Wrapf(ErrInvalidLedgerDiff, "invalid entry count %d", count)
+	}
+
+	addresses := make([]string, 0, count)
+	changes := make([]int64, 0, count)
+
+	var lastRef uint64
+	for i := uint64(0); i < count; i++ {
+		refDistance, err := binary.ReadUvarint(reader)
+		if err != nil {
+			return nil, nil, errors.Wrap(ErrInvalidLedgerDiff, err.Error())
+		}
+
+		if refDistance == 0 {
+			address := make([]byte, 49)
+			if _, err := io.ReadFull(reader, address); err != nil {
+				return nil, nil, errors.Wrap(ErrInvalidLedgerDiff, "address too short")
+			}
+			addresses = append(addresses, string(address))
+		} else {
+			if depth == 0 {
+				return nil, nil, errors.Wrap(ErrInvalidLedgerDiff, "reference in a diff without a base")
+			}
+
+			ref := lastRef + refDistance
+			if ref > uint64(len(baseAddresses)) {
+				return nil, nil, errors.Wrapf(ErrInvalidLedgerDiff, "reference %d out of range", ref)
+			}
+			addresses = append(addresses, baseAddresses[ref-1])
+			lastRef = ref
+		}
+
+		change, err := binary.ReadVarint(reader)
+		if err != nil {
+			return nil, nil, errors.Wrap(ErrInvalidLedgerDiff, err.Error())
+		}
+		changes = append(changes, change)
+	}
+
+	if reader.Len() != 0 {
+		return nil, nil, errors.Wrapf(ErrInvalidLedgerDiff, "%d trailing bytes", reader.Len())
+	}
+
+	return addresses, changes, nil
+}
@@ -0,0 +1,156 @@
+package tangle
+
+import (
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+
+	"github.com/gohornet/hornet/pkg/model/milestone"
+)
+
+func testAddress(char string) string {
+	return strings.Repeat(char, 49)
+}
+
+func TestLedgerDiffEncoding(t *testing.T) {
+
+	baseDiff := map[string]int64{
+		testAddress("A"): -100,
+		testAddress("B"): 60,
+		testAddress("C"): 40,
+	}
+
+	encodedBase, baseAddresses, _ := encodeLedgerDiff(baseDiff, nil, 0)
+	assert.Equal(t, []string{testAddress("A"), testAddress("B"), testAddress("C")}, baseAddresses)
+
+	addresses, changes, err := decodeLedgerDiff(encodedBase, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, baseAddresses, addresses)
+	assert.Equal(t, []int64{-100, 60, 40}, changes)
+
+	diff := map[string]int64{
+		testAddress("A"): 7,
+		testAddress("C"): -10,
+		testAddress("D"): 3,
+	}
+
+	encoded, _, _ := encodeLedgerDiff(diff, baseAddresses, 1)
+	encodedKeyframe, _, _ := encodeLedgerDiff(diff, baseAddresses, 0)
+
+	// the referenced addresses are deduplicated
+	assert.Equal(t, len(encodedKeyframe)-2*49, len(encoded))
+
+	addresses, changes, err = decodeLedgerDiff(encoded, baseAddresses)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{testAddress("A"), testAddress("C"), testAddress("D")}, addresses)
+	assert.Equal(t, []int64{7, -10, 3}, changes)
+
+	_, _, err = decodeLedgerDiff(encoded, baseAddresses[:1])
+	assert.True(t, errors.Is(err, ErrInvalidLedgerDiff))
+
+	_, _, err = decodeLedgerDiff(encoded[:len(encoded)-1], baseAddresses)
+	assert.True(t, errors.Is(err, ErrInvalidLedgerDiff))
+}
+
+func TestLedgerDiffMigration(t *testing.T) {
+
+	configureLedgerStore(mapdb.NewMapDB())
+	defer func() { lastStoredLedgerDiff = nil }()
+
+	// store the ledger diffs in the old format with one entry per milestone and address
+	oldDiffs := map[milestone.Index]map[string]int64{
+		1: {testAddress("A"): -10, testAddress("B"): 10},
+		2: {testAddress("B"): -5, testAddress("C"): 5},
+		4: {testAddress("A"): -1, testAddress("C"): 1},
+	}
+	for msIndex, diff := range oldDiffs {
+		for address, change := range diff {
+			value := make([]byte, 8)
+			binary.LittleEndian.PutUint64(value, uint64(change))
+			assert.NoError(t, ledgerDiffStore.Set(append(databaseKeyForMilestoneIndex(msIndex), address...), value))
+		}
+	}
+
+	assert.NoError(t, migrateLedgerDiffsToCompressedFormat())
+	lastStoredLedgerDiff = nil
+
+	for msIndex, oldDiff := range oldDiffs {
+		diff, err := GetLedgerDiffForMilestone(msIndex, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, oldDiff, diff)
+	}
+
+	// the next ledger diff references the deleted one
+	assert.NoError(t, DeleteLedgerDiffForMilestone(1))
+
+	diff, err := GetLedgerDiffForMilestone(1, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, diff)
+
+	diff, err = GetLedgerDiffForMilestone(2, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, oldDiffs[2], diff)
+}
+
+func TestLedgerDiffIterator(t *testing.T) {
+
+	store := mapdb.NewMapDB()
+	configureLedgerStore(store)
+	defer func() { lastStoredLedgerDiff = nil }()
+
+	// more milestones than fit into a single chain of referencing ledger diffs
+	const milestones = 2*ledgerDiffMaxDepth + 10
+
+	diffs := make(map[milestone.Index]map[string]int64)
+	for msIndex := milestone.Index(1); msIndex <= milestones; msIndex++ {
+		diffs[msIndex] = map[string]int64{
+			testAddress("A"): -int64(msIndex),
+			testAddress(string(rune('B' + msIndex%3))): int64(msIndex),
+		}
+
+		batch := ledgerDiffStore.Batched()
+		storedDiff, err := storeLedgerDiffWithoutLocking(batch, diffs[msIndex], msIndex)
+		assert.NoError(t, err)
+		assert.NoError(t, batch.Commit())
+		storeLastStoredLedgerDiff(storedDiff)
+	}
+	lastStoredLedgerDiff = nil
+
+	var reads int
+	ledgerDiffStore.AccessCallback(func(_ kvstore.Command, _ ...[]byte) { reads++ }, kvstore.GetCommand)
+
+	// every ledger diff is only read once in ascending order
+	ledgerDiffs := NewLedgerDiffIterator()
+	for msIndex := milestone.Index(1); msIndex <= milestones; msIndex++ {
+		diff, err := ledgerDiffs.LedgerDiff(msIndex, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, diffs[msIndex], diff)
+	}
+	assert.Equal(t, milestones, reads)
+
+	// and in descending order
+	reads = 0
+	ledgerDiffs = NewLedgerDiffIterator()
+	for msIndex := milestone.Index(milestones); msIndex >= 1; msIndex-- {
+		diff, err := ledgerDiffs.LedgerDiff(msIndex, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, diffs[msIndex], diff)
+	}
+	assert.Equal(t, milestones, reads)
+
+	// a single ledger diff has to resolve all the referenced ones
+	reads = 0
+	diff, err := GetLedgerDiffForMilestone(ledgerDiffMaxDepth, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, diffs[ledgerDiffMaxDepth], diff)
+	assert.Equal(t, ledgerDiffMaxDepth, reads)
+
+	// the ledger diffs which can't be referenced anymore are dropped
+	assert.LessOrEqual(t, len(ledgerDiffs.resolved), 2*ledgerDiffMaxDepth+3)
+}
@@ -51,6 +51,11 @@ func ConfigureMilestones(cooAddr hornet.Hash, cooSecLvl int, cooMerkleTreeDepth
 	maxMilestoneIndex = 1 << coordinatorMerkleTreeDepth
 }
 
+// IsCoordinatorAddress checks if the given address is the address of the coordinator.
+func IsCoordinatorAddress(address hornet.Hash) bool {
+	return bytes.Equal(address, coordinatorAddress)
+}
+
 func GetMilestoneMerkleHashFunc() crypto.Hash {
 	return coordinatorMilestoneMerkleHashFunc
 }
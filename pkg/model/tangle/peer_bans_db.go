@@ -0,0 +1,74 @@
+package tangle
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/kvstore"
+)
+
+var (
+	peerBansStore kvstore.KVStore
+)
+
+// PeerBan holds the ban of a peer, so that it is kept across restarts.
+type PeerBan struct {
+	// The ID of the banned peer.
+	PeerID string `json:"peerId"`
+	// Why the peer was banned.
+	Reason string `json:"reason"`
+	// The unix timestamp the peer was banned.
+	Since int64 `json:"since"`
+	// The unix timestamp the ban expires.
+	Until int64 `json:"until"`
+}
+
+func configurePeerBansStore(store kvstore.KVStore) {
+	peerBansStore = store.WithRealm([]byte{StorePrefixPeerBans})
+}
+
+// StorePeerBan stores the given ban and replaces an older ban of the same peer.
+func StorePeerBan(ban *PeerBan) error {
+	value, err := json.Marshal(ban)
+	if err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to serialize peer ban")
+	}
+
+	if err := peerBansStore.Set([]byte(ban.PeerID), value); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to store peer ban")
+	}
+	return nil
+}
+
+// DeletePeerBan deletes the ban of the peer with the given ID.
+func DeletePeerBan(peerID string) error {
+	if err := peerBansStore.Delete([]byte(peerID)); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to delete peer ban")
+	}
+	return nil
+}
+
+// LoadPeerBans returns the stored bans.
+func LoadPeerBans() ([]*PeerBan, error) {
+	var bans []*PeerBan
+	var innerErr error
+
+	if err := peerBansStore.Iterate(kvstore.EmptyPrefix, func(key kvstore.Key, value kvstore.Value) bool {
+		ban := &PeerBan{}
+		if err := json.Unmarshal(value, ban); err != nil {
+			innerErr = errors.Wrap(NewDatabaseError(err), "failed to parse peer ban")
+			return false
+		}
+		bans = append(bans, ban)
+		return true
+	}); err != nil {
+		return nil, errors.Wrap(NewDatabaseError(err), "failed to load peer bans")
+	}
+
+	if innerErr != nil {
+		return nil, innerErr
+	}
+
+	return bans, nil
+}
@@ -0,0 +1,108 @@
+package tangle
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/syncutils"
+)
+
+const (
+	peerReputationBytesLength = 5 * 8
+)
+
+var (
+	peerReputationStore kvstore.KVStore
+	// guards the read-modify-write of the reputations.
+	peerReputationLock syncutils.Mutex
+)
+
+// PeerReputation holds the long-term statistics of a peer which are kept across restarts.
+type PeerReputation struct {
+	InvalidMessages  uint64 `json:"invalidMessages"`
+	UsefulAnswers    uint64 `json:"usefulAnswers"`
+	SentRequests     uint64 `json:"sentRequests"`
+	ConnectedSeconds uint64 `json:"connectedSeconds"`
+	KnownSeconds     uint64 `json:"knownSeconds"`
+}
+
+// Add adds the counters of the given reputation.
+func (r *PeerReputation) Add(other *PeerReputation) {
+	r.InvalidMessages += other.InvalidMessages
+	r.UsefulAnswers += other.UsefulAnswers
+	r.SentRequests += other.SentRequests
+	r.ConnectedSeconds += other.ConnectedSeconds
+	r.KnownSeconds += other.KnownSeconds
+}
+
+// GetBytes returns the serialized reputation.
+func (r *PeerReputation) GetBytes() []byte {
+	bytes := make([]byte, peerReputationBytesLength)
+	binary.LittleEndian.PutUint64(bytes[0:8], r.InvalidMessages)
+	binary.LittleEndian.PutUint64(bytes[8:16], r.UsefulAnswers)
+	binary.LittleEndian.PutUint64(bytes[16:24], r.SentRequests)
+	binary.LittleEndian.PutUint64(bytes[24:32], r.ConnectedSeconds)
+	binary.LittleEndian.PutUint64(bytes[32:40], r.KnownSeconds)
+	return bytes
+}
+
+// PeerReputationFromBytes parses the given bytes into the reputation of a peer.
+func PeerReputationFromBytes(bytes []byte) (*PeerReputation, error) {
+	if len(bytes) != peerReputationBytesLength {
+		return nil, fmt.Errorf("parsing of peer reputation failed, invalid length: %d, expected: %d", len(bytes), peerReputationBytesLength)
+	}
+
+	return &PeerReputation{
+		InvalidMessages:  binary.LittleEndian.Uint64(bytes[0:8]),
+		UsefulAnswers:    binary.LittleEndian.Uint64(bytes[8:16]),
+		SentRequests:     binary.LittleEndian.Uint64(bytes[16:24]),
+		ConnectedSeconds: binary.LittleEndian.Uint64(bytes[24:32]),
+		KnownSeconds:     binary.LittleEndian.Uint64(bytes[32:40]),
+	}, nil
+}
+
+func configurePeerReputationStore(store kvstore.KVStore) {
+	peerReputationStore = store.WithRealm([]byte{StorePrefixPeerReputation})
+}
+
+// GetPeerReputation returns the reputation of the peer with the given key.
+// Returns nil if no reputation was stored for the peer yet.
+func GetPeerReputation(peerKey string) (*PeerReputation, error) {
+	value, err := peerReputationStore.Get([]byte(peerKey))
+	if err != nil {
+		if err == kvstore.ErrKeyNotFound {
+			return nil, nil
+		}
+		return nil, errors.Wrap(NewDatabaseError(err), "failed to load peer reputation")
+	}
+
+	reputation, err := PeerReputationFromBytes(value)
+	if err != nil {
+		return nil, errors.Wrap(NewDatabaseError(err), "failed to parse peer reputation")
+	}
+	return reputation, nil
+}
+
+// AddPeerReputation adds the given counters to the reputation of the peer with the given key.
+func AddPeerReputation(peerKey string, reputation *PeerReputation) error {
+	peerReputationLock.Lock()
+	defer peerReputationLock.Unlock()
+
+	stored, err := GetPeerReputation(peerKey)
+	if err != nil {
+		return err
+	}
+	if stored == nil {
+		stored = &PeerReputation{}
+	}
+
+	stored.Add(reputation)
+
+	if err := peerReputationStore.Set([]byte(peerKey), stored.GetBytes()); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to store peer reputation")
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package tangle
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/syncutils"
+)
+
+// PeerTrafficResolution is the length of the periods the traffic of the peers is rolled up into.
+type PeerTrafficResolution byte
+
+const (
+	// PeerTrafficHourly rolls up the traffic of the peers per hour.
+	PeerTrafficHourly PeerTrafficResolution = 'h'
+	// PeerTrafficDaily rolls up the traffic of the peers per day (UTC).
+	PeerTrafficDaily PeerTrafficResolution = 'd'
+)
+
+const (
+	peerTrafficBytesLength = 6 * 8
+)
+
+var (
+	peerTrafficStore kvstore.KVStore
+	// guards the read-modify-write of the rollups.
+	peerTrafficLock syncutils.Mutex
+)
+
+// PeerTraffic holds the traffic and message counters of a peer within a period.
+type PeerTraffic struct {
+	BytesRead            uint64 `json:"bytesRead"`
+	BytesWritten         uint64 `json:"bytesWritten"`
+	ReceivedTransactions uint64 `json:"receivedTransactions"`
+	SentTransactions     uint64 `json:"sentTransactions"`
+	SentPackets          uint64 `json:"sentPackets"`
+	DroppedPackets       uint64 `json:"droppedPackets"`
+}
+
+// Add adds the counters of the given traffic.
+func (t *PeerTraffic) Add(other *PeerTraffic) {
+	t.BytesRead += other.BytesRead
+	t.BytesWritten += other.BytesWritten
+	t.ReceivedTransactions += other.ReceivedTransactions
+	t.SentTransactions += other.SentTransactions
+	t.SentPackets += other.SentPackets
+	t.DroppedPackets += other.DroppedPackets
+}
+
+// GetBytes returns the serialized traffic.
+func (t *PeerTraffic) GetBytes() []byte {
+	bytes := make([]byte, peerTrafficBytesLength)
+	binary.LittleEndian.PutUint64(bytes[0:8], t.BytesRead)
+	binary.LittleEndian.PutUint64(bytes[8:16], t.BytesWritten)
+	binary.LittleEndian.PutUint64(bytes[16:24], t.ReceivedTransactions)
+	binary.LittleEndian.PutUint64(bytes[24:32], t.SentTransactions)
+	binary.LittleEndian.PutUint64(bytes[32:40], t.SentPackets)
+	binary.LittleEndian.PutUint64(bytes[40:48], t.DroppedPackets)
+	return bytes
+}
+
+// PeerTrafficFromBytes parses the given bytes into the traffic of a peer.
+func PeerTrafficFromBytes(bytes []byte) (*PeerTraffic, error) {
+	if len(bytes) != peerTrafficBytesLength {
+		return nil, fmt.Errorf("parsing of peer traffic failed, invalid length: %d, expected: %d", len(bytes), peerTrafficBytesLength)
+	}
+
+	return &PeerTraffic{
+		BytesRead:            binary.LittleEndian.Uint64(bytes[0:8]),
+		BytesWritten:         binary.LittleEndian.Uint64(bytes[8:16]),
+		ReceivedTransactions: binary.LittleEndian.Uint64(bytes[16:24]),
+		SentTransactions:     binary.LittleEndian.Uint64(bytes[24:32]),
+		SentPackets:          binary.LittleEndian.Uint64(bytes[32:40]),
+		DroppedPackets:       binary.LittleEndian.Uint64(bytes[40:48]),
+	}, nil
+}
+
+// PeerTrafficConsumer consumes the traffic of a peer within the period starting at the given time.
+// Returning false aborts the iteration.
+type PeerTrafficConsumer func(periodStart time.Time, peerID string, traffic *PeerTraffic) bool
+
+func configurePeerTrafficStore(store kvstore.KVStore) {
+	peerTrafficStore = store.WithRealm([]byte{StorePrefixPeerTraffic})
+}
+
+// PeriodStart returns the start of the period of the resolution the given time falls into.
+func (r PeerTrafficResolution) PeriodStart(t time.Time) time.Time {
+	if r == PeerTrafficDaily {
+		return t.UTC().Truncate(24 * time.Hour)
+	}
+	return t.UTC().Truncate(time.Hour)
+}
+
+// the key consists of the resolution, the start of the period and the ID of the peer,
+// so that the rollups are ordered by time within a resolution.
+func databaseKeyForPeerTraffic(resolution PeerTrafficResolution, periodStart time.Time, peerID string) []byte {
+	key := make([]byte, 9, 9+len(peerID))
+	key[0] = byte(resolution)
+	binary.BigEndian.PutUint64(key[1:9], uint64(periodStart.Unix()))
+	return append(key, peerID...)
+}
+
+// AddPeerTraffic adds the given traffic of a peer to the rollup of the period the given time falls into.
+func AddPeerTraffic(resolution PeerTrafficResolution, t time.Time, peerID string, traffic *PeerTraffic) error {
+	peerTrafficLock.Lock()
+	defer peerTrafficLock.Unlock()
+
+	key := databaseKeyForPeerTraffic(resolution, resolution.PeriodStart(t), peerID)
+
+	rollup := &PeerTraffic{}
+	value, err := peerTrafficStore.Get(key)
+	switch {
+	case err == nil:
+		if rollup, err = PeerTrafficFromBytes(value); err != nil {
+			return errors.Wrap(NewDatabaseError(err), "failed to parse peer traffic")
+		}
+	case err != kvstore.ErrKeyNotFound:
+		return errors.Wrap(NewDatabaseError(err), "failed to load peer traffic")
+	}
+
+	rollup.Add(traffic)
+
+	if err := peerTrafficStore.Set(key, rollup.GetBytes()); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to store peer traffic")
+	}
+	return nil
+}
+
+// ForEachPeerTraffic loops over the rollups of the given resolution whose period starts within [from, to).
+func ForEachPeerTraffic(resolution PeerTrafficResolution, from time.Time, to time.Time, consumer PeerTrafficConsumer) error {
+	var innerErr error
+	if err := peerTrafficStore.Iterate([]byte{byte(resolution)}, func(key kvstore.Key, value kvstore.Value) bool {
+		periodStart := time.Unix(int64(binary.BigEndian.Uint64(key[1:9])), 0).UTC()
+		if periodStart.Before(from) {
+			return true
+		}
+		if !periodStart.Before(to) {
+			// the keys are ordered by time
+			return false
+		}
+
+		traffic, err := PeerTrafficFromBytes(value)
+		if err != nil {
+			innerErr = err
+			return false
+		}
+
+		return consumer(periodStart, string(key[9:]), traffic)
+	}); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to iterate peer traffic")
+	}
+
+	return innerErr
+}
+
+// DeletePeerTrafficBefore deletes the rollups of the given resolution whose period started before the given time.
+func DeletePeerTrafficBefore(resolution PeerTrafficResolution, before time.Time) error {
+	var keys []kvstore.Key
+	if err := peerTrafficStore.IterateKeys([]byte{byte(resolution)}, func(key kvstore.Key) bool {
+		if int64(binary.BigEndian.Uint64(key[1:9])) >= before.Unix() {
+			return false
+		}
+		keys = append(keys, key)
+		return true
+	}); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to iterate peer traffic")
+	}
+
+	for _, key := range keys {
+		if err := peerTrafficStore.Delete(key); err != nil {
+			return errors.Wrap(NewDatabaseError(err), "failed to delete peer traffic")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package tangle
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/kvstore"
+)
+
+var (
+	peersStore kvstore.KVStore
+)
+
+// PeerRecord holds a peer the node connects to, so that it can be restored after a restart.
+type PeerRecord struct {
+	// The address of the peer (host:port).
+	Address string `json:"address"`
+	// The alias of the peer.
+	Alias string `json:"alias"`
+	// Whether to prefer IPv6 addresses when resolving the address.
+	PreferIPv6 bool `json:"preferIPv6"`
+	// The IP address and port the peer was last connected on.
+	LastSeenAddress string `json:"lastSeenAddress"`
+	// The unix timestamp the peer was last seen connected.
+	LastSeen int64 `json:"lastSeen"`
+}
+
+func configurePeersStore(store kvstore.KVStore) {
+	peersStore = store.WithRealm([]byte{StorePrefixPeers})
+}
+
+// StorePeerRecords replaces the stored peers with the given ones.
+func StorePeerRecords(records []*PeerRecord) error {
+
+	// Delete all old entries
+	if err := peersStore.Clear(); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to delete old peers")
+	}
+
+	batch := peersStore.Batched()
+
+	for _, record := range records {
+		value, err := json.Marshal(record)
+		if err != nil {
+			return errors.Wrap(NewDatabaseError(err), "failed to serialize peer")
+		}
+
+		if err := batch.Set([]byte(record.Address), value); err != nil {
+			return errors.Wrap(NewDatabaseError(err), "failed to set the peer")
+		}
+	}
+
+	if err := batch.Commit(); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to store peers")
+	}
+
+	return nil
+}
+
+// LoadPeerRecords returns the stored peers.
+func LoadPeerRecords() ([]*PeerRecord, error) {
+	var records []*PeerRecord
+	var innerErr error
+
+	if err := peersStore.Iterate(kvstore.EmptyPrefix, func(key kvstore.Key, value kvstore.Value) bool {
+		record := &PeerRecord{}
+		if err := json.Unmarshal(value, record); err != nil {
+			innerErr = errors.Wrap(NewDatabaseError(err), "failed to parse peer")
+			return false
+		}
+		records = append(records, record)
+		return true
+	}); err != nil {
+		return nil, errors.Wrap(NewDatabaseError(err), "failed to load peers")
+	}
+
+	if innerErr != nil {
+		return nil, innerErr
+	}
+
+	return records, nil
+}
@@ -0,0 +1,101 @@
+package tangle
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/kvstore"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+)
+
+const (
+	pruningIntentKey = "pruningIntent"
+)
+
+var (
+	pruningIntentStore kvstore.KVStore
+)
+
+// PruningIntent is written before the data of a milestone is pruned from the database.
+// If the node crashes while pruning, the intent is used to finish the pruning at the next startup.
+type PruningIntent struct {
+	// The index of the milestone which is pruned.
+	MilestoneIndex milestone.Index
+	// The hashes of the transactions which are checked for pruning.
+	TxHashes hornet.Hashes
+}
+
+func configurePruningIntentStore(store kvstore.KVStore) {
+	pruningIntentStore = store.WithRealm([]byte{StorePrefixPruningIntent})
+}
+
+// GetBytes returns the serialized pruning intent.
+func (i *PruningIntent) GetBytes() []byte {
+	bytes := make([]byte, 8, 8+len(i.TxHashes)*49)
+	binary.LittleEndian.PutUint32(bytes[:4], uint32(i.MilestoneIndex))
+	binary.LittleEndian.PutUint32(bytes[4:8], uint32(len(i.TxHashes)))
+	for _, txHash := range i.TxHashes {
+		bytes = append(bytes, txHash[:49]...)
+	}
+	return bytes
+}
+
+// PruningIntentFromBytes parses the given bytes into a pruning intent.
+func PruningIntentFromBytes(bytes []byte) (*PruningIntent, error) {
+	if len(bytes) < 8 {
+		return nil, fmt.Errorf("parsing of pruning intent failed, too few bytes: %d", len(bytes))
+	}
+
+	txCount := int(binary.LittleEndian.Uint32(bytes[4:8]))
+	if len(bytes) != 8+txCount*49 {
+		return nil, fmt.Errorf("parsing of pruning intent failed, invalid length: %d, expected: %d", len(bytes), 8+txCount*49)
+	}
+
+	intent := &PruningIntent{
+		MilestoneIndex: milestoneIndexFromBytes(bytes[:4]),
+		TxHashes:       make(hornet.Hashes, 0, txCount),
+	}
+
+	for offset := 8; offset < len(bytes); offset += 49 {
+		intent.TxHashes = append(intent.TxHashes, hornet.Hash(bytes[offset:offset+49]))
+	}
+
+	return intent, nil
+}
+
+// StorePruningIntent stores the given pruning intent and overwrites the former one.
+func StorePruningIntent(intent *PruningIntent) error {
+	if err := pruningIntentStore.Set([]byte(pruningIntentKey), intent.GetBytes()); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to store pruning intent")
+	}
+	return nil
+}
+
+// GetPruningIntent returns the pending pruning intent or nil if there is none.
+func GetPruningIntent() (*PruningIntent, error) {
+	value, err := pruningIntentStore.Get([]byte(pruningIntentKey))
+	if err != nil {
+		if err != kvstore.ErrKeyNotFound {
+			return nil, errors.Wrap(NewDatabaseError(err), "failed to retrieve pruning intent")
+		}
+		return nil, nil
+	}
+
+	intent, err := PruningIntentFromBytes(value)
+	if err != nil {
+		return nil, errors.Wrap(NewDatabaseError(err), "failed to convert pruning intent")
+	}
+	return intent, nil
+}
+
+// DeletePruningIntent removes the pending pruning intent after the pruning was finished.
+func DeletePruningIntent() error {
+	if err := pruningIntentStore.Delete([]byte(pruningIntentKey)); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to delete pruning intent")
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package tangle
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/kvstore"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+)
+
+var (
+	recentMessagesStore kvstore.KVStore
+)
+
+func configureRecentMessagesStore(store kvstore.KVStore) {
+	recentMessagesStore = store.WithRealm([]byte{StorePrefixRecentMessages})
+}
+
+// StoreRecentMessages replaces the stored recently received messages with the given ones.
+// The messages map the digests of the received transaction bytes to the hashes of the transactions.
+func StoreRecentMessages(messages map[string]hornet.Hash) error {
+
+	// Delete all old entries
+	if err := recentMessagesStore.Clear(); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to delete old recent messages")
+	}
+
+	batch := recentMessagesStore.Batched()
+
+	for digest, txHash := range messages {
+		if err := batch.Set([]byte(digest), txHash); err != nil {
+			return errors.Wrap(NewDatabaseError(err), "failed to set the recent message")
+		}
+	}
+
+	if err := batch.Commit(); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to store recent messages")
+	}
+
+	return nil
+}
+
+// LoadRecentMessages returns the stored recently received messages.
+// The messages map the digests of the received transaction bytes to the hashes of the transactions.
+func LoadRecentMessages() (map[string]hornet.Hash, error) {
+	messages := make(map[string]hornet.Hash)
+
+	if err := recentMessagesStore.Iterate(kvstore.EmptyPrefix, func(key kvstore.Key, value kvstore.Value) bool {
+		messages[string(key)] = hornet.Hash(value)
+		return true
+	}); err != nil {
+		return nil, errors.Wrap(NewDatabaseError(err), "failed to load recent messages")
+	}
+
+	return messages, nil
+}
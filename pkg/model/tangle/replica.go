@@ -0,0 +1,51 @@
+package tangle
+
+import (
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/pkg/errors"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+)
+
+// RefreshFromDatabase drops the cached objects and reloads the snapshot info, the solid entry points
+// and the ledger milestone index from the database.
+// It is used by read-only replicas to pick up the changes which were written to the underlying store.
+func RefreshFromDatabase() error {
+	FlushStorages()
+
+	info, err := readSnapshotInfo()
+	if err != nil {
+		return err
+	}
+	mutex.Lock()
+	snapshot = info
+	mutex.Unlock()
+
+	points, err := readSolidEntryPoints()
+	if err != nil {
+		return err
+	}
+	WriteLockSolidEntryPoints()
+	if points == nil {
+		points = hornet.NewSolidEntryPoints()
+	}
+	solidEntryPoints = points
+	WriteUnlockSolidEntryPoints()
+
+	ReadLockLedger()
+	defer ReadUnlockLedger()
+
+	value, err := ledgerStore.Get([]byte(ledgerMilestoneIndexKey))
+	if err != nil {
+		if err != kvstore.ErrKeyNotFound {
+			return errors.Wrap(NewDatabaseError(err), "failed to load ledger milestone index")
+		}
+		return nil
+	}
+	ledgerMilestoneIndex = milestoneIndexFromBytes(value)
+
+	// the store may have been replaced by an older copy, so the solid milestone index is overwritten
+	OverwriteSolidMilestoneIndex(ledgerMilestoneIndex)
+
+	return nil
+}
@@ -1,8 +1,6 @@
 package tangle
 
 import (
-	"encoding/binary"
-	"io"
 	"sync"
 	"time"
 
@@ -94,14 +92,18 @@ func MarkAddressAsSpentWithoutLocking(address hornet.Hash) bool {
 	return newlyAdded
 }
 
-// StreamSpentAddressesToWriter streams all spent addresses directly to an io.Writer.
-func StreamSpentAddressesToWriter(buf io.Writer, abortSignal <-chan struct{}) (int32, error) {
+// SpentAddressConsumer consumes the given spent address during streaming all spent addresses.
+type SpentAddressConsumer func(address hornet.Hash) error
+
+// StreamSpentAddresses passes all spent addresses to the consumer and returns the amount of streamed addresses.
+func StreamSpentAddresses(consumer SpentAddressConsumer, abortSignal <-chan struct{}) (int32, error) {
 
 	ReadLockSpentAddresses()
 	defer ReadUnlockSpentAddresses()
 
-	var addressesWritten int32
+	var addressesStreamed int32
 
+	var innerErr error
 	wasAborted := false
 	spentAddressesStorage.ForEachKeyOnly(func(key []byte) bool {
 		select {
@@ -111,15 +113,23 @@ func StreamSpentAddressesToWriter(buf io.Writer, abortSignal <-chan struct{}) (i
 		default:
 		}
 
-		addressesWritten++
-		return binary.Write(buf, binary.LittleEndian, key) == nil
+		if innerErr = consumer(key); innerErr != nil {
+			return false
+		}
+
+		addressesStreamed++
+		return true
 	}, false)
 
 	if wasAborted {
 		return 0, ErrOperationAborted
 	}
 
-	return addressesWritten, nil
+	if innerErr != nil {
+		return 0, innerErr
+	}
+
+	return addressesStreamed, nil
 }
 
 func ShutdownSpentAddressesStorage() {
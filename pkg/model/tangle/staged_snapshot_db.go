@@ -0,0 +1,236 @@
+package tangle
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/kvstore"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+)
+
+const (
+	stagedSnapshotInfoKey     = "snapshotInfo"
+	stagedSolidEntryPointsKey = "solidEntryPoints"
+	stagedSnapshotCommitKey   = "commit"
+)
+
+var (
+	// stagedSnapshotStore and stagedSnapshotLedgerStore hold a snapshot which replaces the state of the running node.
+	// They are separated from the live state, which is only touched after the staged snapshot was committed.
+	stagedSnapshotStore       kvstore.KVStore
+	stagedSnapshotLedgerStore kvstore.KVStore
+)
+
+func configureStagedSnapshotStore(store kvstore.KVStore) {
+	stagedSnapshotStore = store.WithRealm([]byte{StorePrefixStagedSnapshot})
+	stagedSnapshotLedgerStore = store.WithRealm([]byte{StorePrefixStagedSnapshotLedger})
+}
+
+// StagedSnapshotFile is a snapshot file whose spent addresses are imported after the staged snapshot was applied.
+type StagedSnapshotFile struct {
+	// The path of the snapshot file.
+	Path string
+	// Whether the file is a delta snapshot file.
+	Delta bool
+}
+
+// StagedSnapshotCommit is written with a single write once the snapshot was staged completely.
+// From then on, the staged snapshot is the state of the node. If the node crashes while the staged snapshot
+// is applied, the commit is used to finish it at the next startup.
+type StagedSnapshotCommit struct {
+	// The snapshot files whose spent addresses are imported.
+	Files []*StagedSnapshotFile
+}
+
+// GetBytes returns the serialized commit of the staged snapshot.
+func (c *StagedSnapshotCommit) GetBytes() []byte {
+	bytes := make([]byte, 4)
+	binary.LittleEndian.PutUint32(bytes, uint32(len(c.Files)))
+	for _, file := range c.Files {
+		var delta byte
+		if file.Delta {
+			delta = 1
+		}
+		bytes = append(bytes, delta)
+		bytes = append(bytes, make([]byte, 4)...)
+		binary.LittleEndian.PutUint32(bytes[len(bytes)-4:], uint32(len(file.Path)))
+		bytes = append(bytes, file.Path...)
+	}
+	return bytes
+}
+
+// StagedSnapshotCommitFromBytes parses the given bytes into a commit of the staged snapshot.
+func StagedSnapshotCommitFromBytes(bytes []byte) (*StagedSnapshotCommit, error) {
+	if len(bytes) < 4 {
+		return nil, fmt.Errorf("parsing of staged snapshot commit failed, too few bytes: %d", len(bytes))
+	}
+
+	fileCount := int(binary.LittleEndian.Uint32(bytes[:4]))
+	commit := &StagedSnapshotCommit{}
+
+	offset := 4
+	for i := 0; i < fileCount; i++ {
+		if len(bytes) < offset+5 {
+			return nil, fmt.Errorf("parsing of staged snapshot commit failed, too few bytes: %d", len(bytes))
+		}
+
+		delta := bytes[offset] == 1
+		pathLength := int(binary.LittleEndian.Uint32(bytes[offset+1 : offset+5]))
+		offset += 5
+
+		if len(bytes)-offset < pathLength {
+			return nil, fmt.Errorf("parsing of staged snapshot commit failed, too few bytes: %d", len(bytes))
+		}
+
+		commit.Files = append(commit.Files, &StagedSnapshotFile{Path: string(bytes[offset : offset+pathLength]), Delta: delta})
+		offset += pathLength
+	}
+
+	if offset != len(bytes) {
+		return nil, fmt.Errorf("parsing of staged snapshot commit failed, invalid length: %d, expected: %d", len(bytes), offset)
+	}
+
+	return commit, nil
+}
+
+// StoreStagedSnapshot replaces the staged snapshot with the given snapshot info, solid entry points and ledger state.
+// The live state of the node is not touched.
+func StoreStagedSnapshot(info *SnapshotInfo, points *hornet.SolidEntryPoints, balances map[string]uint64) error {
+
+	// a former staged snapshot must never get committed with parts of the new one
+	if err := DeleteStagedSnapshot(); err != nil {
+		return err
+	}
+
+	batch := stagedSnapshotLedgerStore.Batched()
+
+	for address, balance := range balances {
+		if balance != 0 {
+			if err := batch.Set(hornet.Hash(address), bytesFromBalance(balance)); err != nil {
+				return errors.Wrap(NewDatabaseError(err), "failed to set the staged balance")
+			}
+		}
+	}
+
+	if err := batch.Commit(); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to store staged ledger state")
+	}
+
+	if err := stagedSnapshotStore.Set([]byte(stagedSolidEntryPointsKey), points.GetBytes()); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to store staged solid entry points")
+	}
+
+	if err := stagedSnapshotStore.Set([]byte(stagedSnapshotInfoKey), info.GetBytes()); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to store staged snapshot info")
+	}
+
+	return nil
+}
+
+// CommitStagedSnapshot switches the state of the node over to the staged snapshot with a single write.
+// The staged snapshot has to be applied afterwards (see ApplyStagedSnapshot).
+func CommitStagedSnapshot(commit *StagedSnapshotCommit) error {
+	if err := stagedSnapshotStore.Set([]byte(stagedSnapshotCommitKey), commit.GetBytes()); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to commit staged snapshot")
+	}
+	return nil
+}
+
+// GetStagedSnapshotCommit returns the commit of a staged snapshot which was not applied completely or nil if there is none.
+func GetStagedSnapshotCommit() (*StagedSnapshotCommit, error) {
+	value, err := stagedSnapshotStore.Get([]byte(stagedSnapshotCommitKey))
+	if err != nil {
+		if err != kvstore.ErrKeyNotFound {
+			return nil, errors.Wrap(NewDatabaseError(err), "failed to retrieve staged snapshot commit")
+		}
+		return nil, nil
+	}
+
+	commit, err := StagedSnapshotCommitFromBytes(value)
+	if err != nil {
+		return nil, errors.Wrap(NewDatabaseError(err), "failed to convert staged snapshot commit")
+	}
+	return commit, nil
+}
+
+// ApplyStagedSnapshot replaces the snapshot info, the solid entry points, the snapshot ledger and the ledger state
+// with the ones of the committed staged snapshot and returns the new snapshot info.
+// Every step overwrites the former state completely, so an interrupted apply can be repeated.
+// The solidifier has to be paused while the staged snapshot is applied.
+func ApplyStagedSnapshot() (*SnapshotInfo, error) {
+
+	value, err := stagedSnapshotStore.Get([]byte(stagedSnapshotInfoKey))
+	if err != nil {
+		return nil, errors.Wrap(NewDatabaseError(err), "failed to retrieve staged snapshot info")
+	}
+
+	info, err := SnapshotInfoFromBytes(value)
+	if err != nil {
+		return nil, errors.Wrap(NewDatabaseError(err), "failed to convert staged snapshot info")
+	}
+
+	value, err = stagedSnapshotStore.Get([]byte(stagedSolidEntryPointsKey))
+	if err != nil {
+		return nil, errors.Wrap(NewDatabaseError(err), "failed to retrieve staged solid entry points")
+	}
+
+	points, err := hornet.SolidEntryPointsFromBytes(value)
+	if err != nil {
+		return nil, errors.Wrap(NewDatabaseError(err), "failed to convert staged solid entry points")
+	}
+
+	balances := make(map[string]uint64)
+	if err := stagedSnapshotLedgerStore.Iterate(kvstore.EmptyPrefix, func(key kvstore.Key, value kvstore.Value) bool {
+		balances[string(key[:49])] = balanceFromBytes(value)
+		return true
+	}); err != nil {
+		return nil, errors.Wrap(NewDatabaseError(err), "failed to retrieve staged ledger state")
+	}
+
+	// readers of the ledger state see either the former or the new state
+	WriteLockLedger()
+	defer WriteUnlockLedger()
+
+	if err := StoreSnapshotBalancesInDatabase(balances, info.SnapshotIndex); err != nil {
+		return nil, err
+	}
+
+	if err := storeLedgerBalancesInDatabaseWithoutLocking(balances, info.SnapshotIndex); err != nil {
+		return nil, err
+	}
+
+	WriteLockSolidEntryPoints()
+	points.SetModified(true)
+	if err := storeSolidEntryPoints(points); err != nil {
+		WriteUnlockSolidEntryPoints()
+		return nil, err
+	}
+	solidEntryPoints = points
+	WriteUnlockSolidEntryPoints()
+
+	SetSnapshotInfo(info)
+
+	return info, nil
+}
+
+// DeleteStagedSnapshot removes the commit and the data of the staged snapshot.
+func DeleteStagedSnapshot() error {
+
+	// the commit is removed first, so that the staged snapshot is never applied partially
+	if err := stagedSnapshotStore.Delete([]byte(stagedSnapshotCommitKey)); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to delete staged snapshot commit")
+	}
+
+	if err := stagedSnapshotStore.Clear(); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to delete staged snapshot")
+	}
+
+	if err := stagedSnapshotLedgerStore.Clear(); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to delete staged ledger state")
+	}
+
+	return nil
+}
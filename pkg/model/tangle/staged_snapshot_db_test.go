@@ -0,0 +1,98 @@
+package tangle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/iotaledger/iota.go/consts"
+
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+)
+
+func TestStagedSnapshotCommitEncoding(t *testing.T) {
+
+	commit := &StagedSnapshotCommit{
+		Files: []*StagedSnapshotFile{
+			{Path: "snapshots/mainnet/export.bin"},
+			{Path: "snapshots/mainnet/delta_export.bin", Delta: true},
+		},
+	}
+
+	parsed, err := StagedSnapshotCommitFromBytes(commit.GetBytes())
+	assert.NoError(t, err)
+	assert.Equal(t, commit, parsed)
+
+	_, err = StagedSnapshotCommitFromBytes(commit.GetBytes()[:10])
+	assert.Error(t, err)
+}
+
+func TestApplyStagedSnapshot(t *testing.T) {
+
+	store := mapdb.NewMapDB()
+	configureLedgerStore(store)
+	configureSnapshotStore(store)
+	configureStagedSnapshotStore(store)
+
+	solidEntryPoints = hornet.NewSolidEntryPoints()
+	defer func() {
+		solidEntryPoints = nil
+		snapshot = nil
+		ledgerMilestoneIndex = 0
+	}()
+
+	assert.NoError(t, StoreLedgerBalancesInDatabase(map[string]uint64{testAddress("A"): consts.TotalSupply}, 10))
+
+	info := &SnapshotInfo{
+		CoordinatorAddress: hornet.Hash(testAddress("C")),
+		Hash:               hornet.Hash(testAddress("M")),
+		SnapshotIndex:      20,
+		EntryPointIndex:    20,
+		PruningIndex:       20,
+	}
+	points := hornet.NewSolidEntryPoints()
+	points.Add(hornet.Hash(testAddress("M")), 20)
+
+	balances := map[string]uint64{testAddress("A"): consts.TotalSupply - 60, testAddress("B"): 60}
+	assert.NoError(t, StoreStagedSnapshot(info, points, balances))
+
+	// the staged snapshot doesn't touch the state before it is committed
+	commit, err := GetStagedSnapshotCommit()
+	assert.NoError(t, err)
+	assert.Nil(t, commit)
+
+	state, index, err := GetLedgerStateForLSMIWithoutLocking(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]uint64{testAddress("A"): consts.TotalSupply}, state)
+	assert.EqualValues(t, 10, index)
+
+	commit = &StagedSnapshotCommit{Files: []*StagedSnapshotFile{{Path: "export.bin"}}}
+	assert.NoError(t, CommitStagedSnapshot(commit))
+
+	stored, err := GetStagedSnapshotCommit()
+	assert.NoError(t, err)
+	assert.Equal(t, commit, stored)
+
+	// an interrupted apply is repeated
+	for i := 0; i < 2; i++ {
+		applied, err := ApplyStagedSnapshot()
+		assert.NoError(t, err)
+		assert.EqualValues(t, 20, applied.SnapshotIndex)
+
+		state, index, err = GetLedgerStateForLSMIWithoutLocking(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, balances, state)
+		assert.EqualValues(t, 20, index)
+
+		assert.EqualValues(t, 20, GetSnapshotInfo().SnapshotIndex)
+		assert.True(t, SolidEntryPointsContain(hornet.Hash(testAddress("M"))))
+	}
+
+	assert.NoError(t, DeleteStagedSnapshot())
+
+	stored, err = GetStagedSnapshotCommit()
+	assert.NoError(t, err)
+	assert.Nil(t, stored)
+}
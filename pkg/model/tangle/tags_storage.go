@@ -10,7 +10,23 @@ import (
 	"github.com/gohornet/hornet/pkg/profile"
 )
 
-var tagsStorage *objectstorage.ObjectStorage
+var (
+	tagsStorage *objectstorage.ObjectStorage
+
+	// returns whether the tag of the given transaction must not be indexed.
+	tagIndexFilter func(tx *hornet.Transaction) bool
+)
+
+// SetTagIndexFilter sets a filter which excludes the tags of transactions from the tag index.
+// It must be set before transactions are added to the storage.
+func SetTagIndexFilter(filter func(tx *hornet.Transaction) bool) {
+	tagIndexFilter = filter
+}
+
+// tagIndexed returns whether the tag of the given transaction should be added to the tag index.
+func tagIndexed(tx *hornet.Transaction) bool {
+	return tagIndexFilter == nil || !tagIndexFilter(tx)
+}
 
 type CachedTag struct {
 	objectstorage.CachedObject
@@ -89,6 +105,13 @@ func ForEachTag(consumer TagConsumer, skipCache bool) {
 	}, skipCache)
 }
 
+// ForEachTagWithPrefix loops over all tags which start with the given binary prefix.
+func ForEachTagWithPrefix(tagPrefix []byte, consumer TagConsumer, skipCache bool) {
+	tagsStorage.ForEachKeyOnly(func(key []byte) bool {
+		return consumer(key[:17], key[17:66])
+	}, skipCache, tagPrefix)
+}
+
 // tag +1
 func StoreTag(txTag hornet.Hash, txHash hornet.Hash) *CachedTag {
 	tag := hornet.NewTag(txTag[:17], txHash[:49])
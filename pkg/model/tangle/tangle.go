@@ -67,8 +67,15 @@ func ConfigureStorages(tangleStore kvstore.KVStore, snapshotStore kvstore.KVStor
 	configureMilestoneStorage(tangleStore, caches.Milestones)
 	configureUnconfirmedTxStorage(tangleStore, caches.UnconfirmedTx)
 	configureLedgerStore(tangleStore)
+	configurePruningIntentStore(tangleStore)
+	configurePeerTrafficStore(tangleStore)
+	configurePeerReputationStore(tangleStore)
+	configureRecentMessagesStore(tangleStore)
+	configurePeersStore(tangleStore)
+	configurePeerBansStore(tangleStore)
 
 	configureSnapshotStore(snapshotStore)
+	configureStagedSnapshotStore(snapshotStore)
 
 	configureSpentAddressesStorage(spentStore, caches.SpentAddresses)
 }
@@ -158,3 +165,15 @@ func GetDatabaseSizes() (tangle int64, snapshot int64, spent int64) {
 
 	return
 }
+
+// GetDatabaseUsedSizes returns the size of the different databases without their free pages,
+// which are reused before the database files grow any further.
+func GetDatabaseUsedSizes() (tangle int64, snapshot int64, spent int64) {
+	tangle, snapshot, spent = GetDatabaseSizes()
+
+	tangle -= int64(tangleDb.Stats().FreeAlloc)
+	snapshot -= int64(snapshotDb.Stats().FreeAlloc)
+	spent -= int64(spentDb.Stats().FreeAlloc)
+
+	return
+}
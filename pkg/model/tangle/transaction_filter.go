@@ -0,0 +1,212 @@
+package tangle
+
+import (
+	"hash/fnv"
+	"sync"
+
+	"go.uber.org/atomic"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/scheduler"
+)
+
+const (
+	// the amount of bits used per transaction in the filter.
+	txFilterBitsPerItem = 10
+	// the amount of hash functions of the filter (optimal for 10 bits per item).
+	txFilterHashFunctions = 7
+	// the minimum amount of transactions the filter is sized for.
+	txFilterMinCapacity = 1000000
+)
+
+var (
+	txFilterEnabled atomic.Bool
+
+	// serializes starting and publishing the filter builds, lookups and adds don't take it.
+	txFilterLock sync.Mutex
+	// the *bloomFilter which is used to answer lookups, nil until the first build finished.
+	txFilter atomic.Value
+	// the *bloomFilter which is currently built, nil if no build is running.
+	txFilterNext atomic.Value
+	// the amount of transactions which were deleted since the last build.
+	txFilterDeleted atomic.Uint64
+)
+
+// bloomFilter is a bloom filter for transaction hashes.
+// The bits are set with atomic operations, so the filter can be used without locking.
+type bloomFilter struct {
+	bits     []atomic.Uint64
+	size     uint
+	capacity uint64
+	count    atomic.Uint64
+}
+
+func newBloomFilter(capacity uint64) *bloomFilter {
+	size := uint(capacity * txFilterBitsPerItem)
+	return &bloomFilter{bits: make([]atomic.Uint64, (size+63)/64), size: size, capacity: capacity}
+}
+
+// loadBloomFilter returns the filter held by the given value, nil if there is none.
+func loadBloomFilter(value *atomic.Value) *bloomFilter {
+	filter, _ := value.Load().(*bloomFilter)
+	return filter
+}
+
+// locations returns the bit locations of the given transaction hash via double hashing.
+func (f *bloomFilter) locations(txHash hornet.Hash) [txFilterHashFunctions]uint {
+	h1 := fnv.New64a()
+	_, _ = h1.Write(txHash)
+	h2 := fnv.New64()
+	_, _ = h2.Write(txHash)
+	a, b := h1.Sum64(), h2.Sum64()|1
+
+	var locations [txFilterHashFunctions]uint
+	for i := range locations {
+		locations[i] = uint((a + uint64(i)*b) % uint64(f.size))
+	}
+	return locations
+}
+
+func (f *bloomFilter) add(txHash hornet.Hash) {
+	for _, l := range f.locations(txHash) {
+		word, mask := &f.bits[l/64], uint64(1)<<(l%64)
+		for {
+			bits := word.Load()
+			if bits&mask != 0 || word.CAS(bits, bits|mask) {
+				break
+			}
+		}
+	}
+	f.count.Inc()
+}
+
+func (f *bloomFilter) mayContain(txHash hornet.Hash) bool {
+	for _, l := range f.locations(txHash) {
+		if f.bits[l/64].Load()&(uint64(1)<<(l%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// outdated returns whether the filter has to be rebuilt, because it is overfilled or too many transactions were deleted.
+func (f *bloomFilter) outdated() bool {
+	count := f.count.Load()
+	return count > f.capacity || txFilterDeleted.Load() > count/4
+}
+
+// ConfigureTransactionFilter enables the filter of stored transaction hashes,
+// which is used to answer lookups of unknown transactions without a database read.
+// The filter is built lazily on the first lookup.
+func ConfigureTransactionFilter(enabled bool) {
+	txFilterLock.Lock()
+	defer txFilterLock.Unlock()
+
+	txFilterEnabled.Store(enabled)
+	txFilter.Store((*bloomFilter)(nil))
+	txFilterNext.Store((*bloomFilter)(nil))
+	txFilterDeleted.Store(0)
+}
+
+// addToTransactionFilter adds the given transaction hash to the current filter and the one which is built.
+func addToTransactionFilter(txHash hornet.Hash) {
+	if !txFilterEnabled.Load() {
+		return
+	}
+
+	// the filter which is built is loaded first, since a finished build is published before it is cleared,
+	// so the hash is added to the published filter even if the build finishes in the meantime.
+	next := loadBloomFilter(&txFilterNext)
+	if filter := loadBloomFilter(&txFilter); filter != nil && filter != next {
+		filter.add(txHash)
+	}
+	if next != nil {
+		next.add(txHash)
+	}
+}
+
+// removedFromTransactionFilter marks that a transaction was deleted, which can't be removed from the filter.
+// The filter is rebuilt lazily if too many transactions were deleted, e.g. after pruning.
+func removedFromTransactionFilter() {
+	if !txFilterEnabled.Load() {
+		return
+	}
+	txFilterDeleted.Inc()
+}
+
+// rebuildTransactionFilter builds a new filter from all stored transaction hashes.
+func rebuildTransactionFilter() {
+	if loadBloomFilter(&txFilterNext) != nil {
+		// a build is already running
+		return
+	}
+
+	txFilterLock.Lock()
+	defer txFilterLock.Unlock()
+
+	if loadBloomFilter(&txFilterNext) != nil {
+		// another build was started in the meantime
+		return
+	}
+
+	capacity := uint64(txFilterMinCapacity)
+	if filter := loadBloomFilter(&txFilter); filter != nil && filter.count.Load()*2 > capacity {
+		capacity = filter.count.Load() * 2
+	}
+
+	next := newBloomFilter(capacity)
+	txFilterNext.Store(next)
+	txFilterDeleted.Store(0)
+
+	scheduler.Submit("Transaction filter rebuild", func(abortSignal <-chan struct{}) error {
+		aborted := false
+		ForEachTransactionHash(func(txHash hornet.Hash) bool {
+			select {
+			case <-abortSignal:
+				aborted = true
+				return false
+			default:
+			}
+
+			next.add(txHash)
+			return true
+		}, false)
+
+		txFilterLock.Lock()
+		defer txFilterLock.Unlock()
+
+		if loadBloomFilter(&txFilterNext) != next {
+			// the filter was reset in the meantime
+			return nil
+		}
+
+		if aborted {
+			// the current filter is kept, the next outdated check triggers a new build
+			txFilterNext.Store((*bloomFilter)(nil))
+			return ErrOperationAborted
+		}
+		txFilter.Store(next)
+		txFilterNext.Store((*bloomFilter)(nil))
+		return nil
+	})
+}
+
+// TransactionMaybeStored returns false if the transaction is definitely not stored.
+// If true is returned, the transaction may be stored and the storage has to be checked.
+func TransactionMaybeStored(txHash hornet.Hash) bool {
+	if !txFilterEnabled.Load() {
+		return true
+	}
+
+	filter := loadBloomFilter(&txFilter)
+	if filter == nil || filter.outdated() {
+		rebuildTransactionFilter()
+	}
+
+	if filter == nil {
+		// the first build is not finished yet
+		return true
+	}
+
+	return filter.mayContain(txHash)
+}
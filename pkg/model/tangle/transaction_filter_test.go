@@ -0,0 +1,129 @@
+package tangle
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/transaction"
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/profile"
+)
+
+func randomTxHash() hornet.Hash {
+	txHash := make(hornet.Hash, 49)
+	rand.Read(txHash)
+	return txHash
+}
+
+func storeFilterTestTransaction(idx int) hornet.Hash {
+	tx := hornet.NewTransactionFromTx(&transaction.Transaction{
+		Hash:              trinary.IntToTrytes(int64(idx), consts.HashTrytesSize),
+		Bundle:            hornet.NullHashBytes.Trytes(),
+		TrunkTransaction:  hornet.NullHashBytes.Trytes(),
+		BranchTransaction: hornet.NullHashBytes.Trytes(),
+	}, nil)
+
+	cachedTx, _ := StoreTransactionIfAbsent(tx)
+	cachedTx.Release(true)
+	return tx.GetTxHash()
+}
+
+func TestBloomFilterFalsePositives(t *testing.T) {
+	rand.Seed(0)
+
+	const items = 10000
+	filter := newBloomFilter(items)
+
+	added := make([]hornet.Hash, items)
+	for i := range added {
+		added[i] = randomTxHash()
+		filter.add(added[i])
+	}
+
+	// there are no false negatives
+	for _, txHash := range added {
+		assert.True(t, filter.mayContain(txHash))
+	}
+
+	// the false positive rate of 10 bits per item and 7 hash functions is about 1%
+	var falsePositives int
+	for i := 0; i < items; i++ {
+		if filter.mayContain(randomTxHash()) {
+			falsePositives++
+		}
+	}
+	assert.Less(t, falsePositives, items/50)
+}
+
+func TestBloomFilterOutdated(t *testing.T) {
+	defer txFilterDeleted.Store(0)
+
+	filter := newBloomFilter(100)
+	for i := 0; i < 100; i++ {
+		filter.add(randomTxHash())
+	}
+	assert.False(t, filter.outdated())
+
+	// too many transactions were deleted
+	txFilterDeleted.Store(26)
+	assert.True(t, filter.outdated())
+	txFilterDeleted.Store(0)
+
+	// the filter is overfilled
+	filter.add(randomTxHash())
+	assert.True(t, filter.outdated())
+}
+
+func TestTransactionFilterRebuild(t *testing.T) {
+	ConfigureStorages(mapdb.NewMapDB(), mapdb.NewMapDB(), mapdb.NewMapDB(), profile.Caches{})
+	defer ShutdownStorages()
+
+	ConfigureTransactionFilter(true)
+	defer ConfigureTransactionFilter(false)
+
+	var stored []hornet.Hash
+	for i := 1; i <= 10; i++ {
+		stored = append(stored, storeFilterTestTransaction(i))
+	}
+	unknown := hornet.HashFromHashTrytes(trinary.IntToTrytes(1000, consts.HashTrytesSize))
+
+	// the first lookup starts the build and has to check the storage until it is finished
+	assert.True(t, TransactionMaybeStored(unknown))
+	require.Eventually(t, func() bool { return loadBloomFilter(&txFilter) != nil }, 5*time.Second, 10*time.Millisecond)
+	firstFilter := loadBloomFilter(&txFilter)
+
+	for _, txHash := range stored {
+		assert.True(t, TransactionMaybeStored(txHash))
+	}
+	assert.False(t, TransactionMaybeStored(unknown))
+
+	// transactions stored after the build are added to the published filter
+	added := storeFilterTestTransaction(11)
+	assert.True(t, TransactionMaybeStored(added))
+
+	// the filter is rebuilt once too many transactions were deleted
+	for _, txHash := range stored[:5] {
+		DeleteTransaction(txHash)
+	}
+	FlushTransactionStorage()
+
+	assert.True(t, loadBloomFilter(&txFilter).outdated())
+	TransactionMaybeStored(unknown)
+	require.Eventually(t, func() bool { return loadBloomFilter(&txFilter) != firstFilter }, 5*time.Second, 10*time.Millisecond)
+	assert.False(t, loadBloomFilter(&txFilter).outdated())
+
+	for _, txHash := range stored[:5] {
+		assert.False(t, TransactionMaybeStored(txHash))
+	}
+	for _, txHash := range append(stored[5:], added) {
+		assert.True(t, TransactionMaybeStored(txHash))
+	}
+}
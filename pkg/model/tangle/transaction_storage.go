@@ -192,6 +192,10 @@ func configureTransactionStorage(store kvstore.KVStore, opts profile.CacheOpts)
 
 // tx +1
 func GetCachedTransactionOrNil(txHash hornet.Hash) *CachedTransaction {
+	if !TransactionMaybeStored(txHash) {
+		return nil
+	}
+
 	cachedTx := txStorage.Load(txHash) // tx +1
 	if !cachedTx.Exists() {
 		cachedTx.Release(true) // tx -1
@@ -258,12 +262,22 @@ func GetStoredMetadataOrNil(txHash hornet.Hash) *hornet.TransactionMetadata {
 
 // ContainsTransaction returns if the given transaction exists in the cache/persistence layer.
 func ContainsTransaction(txHash hornet.Hash) bool {
-	return txStorage.Contains(txHash)
+	return TransactionMaybeStored(txHash) && txStorage.Contains(txHash)
 }
 
 // TransactionExistsInStore returns if the given transaction exists in the persistence layer.
 func TransactionExistsInStore(txHash hornet.Hash) bool {
-	return txStorage.ObjectExistsInStore(txHash)
+	return TransactionMaybeStored(txHash) && txStorage.ObjectExistsInStore(txHash)
+}
+
+// ContainsTransactionMetadata returns if the metadata of the given transaction exists in the cache/persistence layer.
+func ContainsTransactionMetadata(txHash hornet.Hash) bool {
+	return metadataStorage.Contains(txHash)
+}
+
+// MetadataExistsInStore returns if the metadata of the given transaction exists in the persistence layer.
+func MetadataExistsInStore(txHash hornet.Hash) bool {
+	return metadataStorage.ObjectExistsInStore(txHash)
 }
 
 // tx +1
@@ -274,6 +288,7 @@ func StoreTransactionIfAbsent(transaction *hornet.Transaction) (cachedTx *Cached
 
 	cachedTxData := txStorage.ComputeIfAbsent(transaction.ObjectStorageKey(), func(key []byte) objectstorage.StorableObject { // tx +1
 		newlyAdded = true
+		addToTransactionFilter(transaction.GetTxHash())
 
 		metadata := hornet.NewTransactionMetadata(transaction.GetTxHash()[:49])
 		metadata.SetAdditionalTxInfo(transaction.GetTrunkHash(), transaction.GetBranchHash(), transaction.GetBundleHash(), transaction.IsHead(), transaction.IsTail(), transaction.IsValue())
@@ -315,6 +330,14 @@ func DeleteTransaction(txHash hornet.Hash) {
 	// metadata has to be deleted before the tx, otherwise we could run into a data race in the object storage
 	metadataStorage.Delete(txHash)
 	txStorage.Delete(txHash)
+	removedFromTransactionFilter()
+}
+
+// DeleteTransactionKeepMetadata deletes the transaction in the cache/persistence layer, but keeps the metadata.
+// The metadata still contains the trunk, branch and confirmation information needed to walk the tangle.
+func DeleteTransactionKeepMetadata(txHash hornet.Hash) {
+	txStorage.Delete(txHash)
+	removedFromTransactionFilter()
 }
 
 // DeleteTransactionMetadata deletes the metadata in the cache/persistence layer.
@@ -0,0 +1,101 @@
+package mqtt
+
+import (
+	"strings"
+	"sync"
+)
+
+// TopicMatches tells whether the given topic matches the given topic filter,
+// which may contain the single level (+) and multi level (#) wildcards.
+func TopicMatches(filter string, topic string) bool {
+	filterLevels := strings.Split(filter, "/")
+	topicLevels := strings.Split(topic, "/")
+
+	for i, level := range filterLevels {
+		switch {
+		case level == "#":
+			return true
+		case i >= len(topicLevels):
+			return false
+		case level == "+":
+			continue
+		case level != topicLevels[i]:
+			return false
+		}
+	}
+
+	return len(filterLevels) == len(topicLevels)
+}
+
+// Subscriptions keeps track of the topic filters which have active subscriptions on a broker.
+// The static topic filters are always considered subscribed.
+type Subscriptions struct {
+	mu      sync.RWMutex
+	static  []string
+	dynamic map[string]int
+}
+
+// NewSubscriptions creates a new subscription tracker with the given static topic filters.
+func NewSubscriptions(staticFilters []string) *Subscriptions {
+	return &Subscriptions{
+		static:  staticFilters,
+		dynamic: make(map[string]int),
+	}
+}
+
+// Subscribed records a subscription to the given topic filter.
+func (s *Subscriptions) Subscribed(filter string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dynamic[filter]++
+}
+
+// Unsubscribed removes a subscription to the given topic filter.
+func (s *Subscriptions) Unsubscribed(filter string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.dynamic[filter] <= 1 {
+		delete(s.dynamic, filter)
+		return
+	}
+	s.dynamic[filter]--
+}
+
+// Reset removes all recorded subscriptions, the static topic filters are kept.
+func (s *Subscriptions) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dynamic = make(map[string]int)
+}
+
+// HasSubscribers tells whether any static or recorded topic filter matches the given topic.
+func (s *Subscriptions) HasSubscribers(topic string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, filter := range s.static {
+		if TopicMatches(filter, topic) {
+			return true
+		}
+	}
+	for filter := range s.dynamic {
+		if TopicMatches(filter, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filters returns the static and recorded topic filters.
+func (s *Subscriptions) Filters() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	filters := make([]string, 0, len(s.static)+len(s.dynamic))
+	filters = append(filters, s.static...)
+	for filter := range s.dynamic {
+		filters = append(filters, filter)
+	}
+	return filters
+}
@@ -0,0 +1,42 @@
+package mqtt_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/mqtt"
+)
+
+func TestTopicMatches(t *testing.T) {
+	require.True(t, mqtt.TopicMatches("lmi", "lmi"))
+	require.False(t, mqtt.TopicMatches("lmi", "lmsi"))
+	require.True(t, mqtt.TopicMatches("addr/+", "addr/ABC"))
+	require.False(t, mqtt.TopicMatches("addr/+", "addr"))
+	require.False(t, mqtt.TopicMatches("addr/+", "tag/ABC"))
+	require.True(t, mqtt.TopicMatches("#", "tag/ABC"))
+	require.True(t, mqtt.TopicMatches("tag/#", "tag/ABC"))
+	require.False(t, mqtt.TopicMatches("tag/ABC/+", "tag/ABC"))
+}
+
+func TestSubscriptions(t *testing.T) {
+	subscriptions := mqtt.NewSubscriptions([]string{"lmi"})
+
+	require.True(t, subscriptions.HasSubscribers("lmi"))
+	require.False(t, subscriptions.HasSubscribers("addr/ABC"))
+
+	subscriptions.Subscribed("addr/ABC")
+	subscriptions.Subscribed("addr/ABC")
+	require.True(t, subscriptions.HasSubscribers("addr/ABC"))
+
+	// the topic stays subscribed until the last subscription is removed
+	subscriptions.Unsubscribed("addr/ABC")
+	require.True(t, subscriptions.HasSubscribers("addr/ABC"))
+	subscriptions.Unsubscribed("addr/ABC")
+	require.False(t, subscriptions.HasSubscribers("addr/ABC"))
+
+	subscriptions.Subscribed("tag/+")
+	subscriptions.Reset()
+	require.False(t, subscriptions.HasSubscribers("tag/ABC"))
+	require.True(t, subscriptions.HasSubscribers("lmi"))
+}
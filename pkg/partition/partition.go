@@ -0,0 +1,100 @@
+package partition
+
+import (
+	"time"
+
+	"github.com/gohornet/hornet/pkg/model/milestone"
+)
+
+const (
+	// ReasonStalled is reported if all peers report the same latest milestone for longer than the stale timeout.
+	ReasonStalled = "stalled"
+	// ReasonDiverging is reported if at least half of the peers report a latest milestone
+	// which lags behind the latest milestone known to the node or the other peers.
+	ReasonDiverging = "diverging"
+)
+
+// Suspicion describes a probable network partition.
+type Suspicion struct {
+	// The heuristic which detected the partition.
+	Reason string `json:"reason"`
+	// The amount of peers which were taken into account.
+	Peers int `json:"peers"`
+	// The amount of peers which report a lagging latest milestone (ReasonDiverging only).
+	DivergingPeers int `json:"divergingPeers"`
+	// The highest latest milestone index known to the node or reported by its peers.
+	LatestMilestoneIndex milestone.Index `json:"latestMilestoneIndex"`
+	// The time the highest latest milestone index changed for the last time.
+	LatestMilestoneChanged time.Time `json:"latestMilestoneChanged"`
+}
+
+// Detector detects probable network partitions given the latest milestones reported by the peers.
+// The latest milestones of the peers only carry the index, so conflicting milestones are detected by diverging indexes.
+type Detector struct {
+	staleTimeout        time.Duration
+	minPeers            int
+	divergenceThreshold milestone.Index
+	latestIndex         milestone.Index
+	latestIndexChanged  time.Time
+}
+
+// NewDetector creates a new detector.
+// A partition is only suspected if at least minPeers peers report their latest milestone.
+// Peers are considered diverging if they lag more than divergenceThreshold milestones behind the highest latest milestone.
+func NewDetector(staleTimeout time.Duration, minPeers int, divergenceThreshold milestone.Index) *Detector {
+	return &Detector{
+		staleTimeout:        staleTimeout,
+		minPeers:            minPeers,
+		divergenceThreshold: divergenceThreshold,
+	}
+}
+
+// Check returns a suspicion if the given latest milestone indexes of the node and its peers indicate a network partition.
+// Returns nil if no partition is suspected.
+func (d *Detector) Check(now time.Time, ownIndex milestone.Index, peerIndexes []milestone.Index) *Suspicion {
+	highest := ownIndex
+	for _, index := range peerIndexes {
+		if index > highest {
+			highest = index
+		}
+	}
+
+	if highest > d.latestIndex || d.latestIndexChanged.IsZero() {
+		d.latestIndex = highest
+		d.latestIndexChanged = now
+	}
+
+	if len(peerIndexes) == 0 || len(peerIndexes) < d.minPeers {
+		return nil
+	}
+
+	suspicion := &Suspicion{
+		Peers:                  len(peerIndexes),
+		LatestMilestoneIndex:   d.latestIndex,
+		LatestMilestoneChanged: d.latestIndexChanged,
+	}
+
+	// half of the peers lag behind, so they probably follow a different part of the network
+	for _, index := range peerIndexes {
+		if index+d.divergenceThreshold < d.latestIndex {
+			suspicion.DivergingPeers++
+		}
+	}
+	if suspicion.DivergingPeers*2 >= len(peerIndexes) {
+		suspicion.Reason = ReasonDiverging
+		return suspicion
+	}
+
+	// all peers agree on a latest milestone, but no new milestone arrived while the time advanced
+	for _, index := range peerIndexes {
+		if index != peerIndexes[0] {
+			return nil
+		}
+	}
+	if now.Sub(d.latestIndexChanged) > d.staleTimeout {
+		suspicion.Reason = ReasonStalled
+		return suspicion
+	}
+
+	return nil
+}
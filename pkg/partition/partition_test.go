@@ -0,0 +1,44 @@
+package partition_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/partition"
+)
+
+func TestDetectorStalled(t *testing.T) {
+	detector := partition.NewDetector(5*time.Minute, 2, 2)
+	start := time.Now()
+
+	require.Nil(t, detector.Check(start, 100, []milestone.Index{100, 100, 100}))
+	require.Nil(t, detector.Check(start.Add(4*time.Minute), 100, []milestone.Index{100, 100, 100}))
+
+	suspicion := detector.Check(start.Add(6*time.Minute), 100, []milestone.Index{100, 100, 100})
+	require.NotNil(t, suspicion)
+	require.Equal(t, partition.ReasonStalled, suspicion.Reason)
+	require.EqualValues(t, 100, suspicion.LatestMilestoneIndex)
+
+	// a new milestone resolves the suspicion
+	require.Nil(t, detector.Check(start.Add(7*time.Minute), 101, []milestone.Index{101, 101, 100}))
+
+	// not enough peers
+	require.Nil(t, detector.Check(start.Add(20*time.Minute), 101, []milestone.Index{101}))
+}
+
+func TestDetectorDiverging(t *testing.T) {
+	detector := partition.NewDetector(5*time.Minute, 2, 2)
+	start := time.Now()
+
+	// a single lagging peer is no partition
+	require.Nil(t, detector.Check(start, 100, []milestone.Index{100, 100, 90, 100}))
+
+	suspicion := detector.Check(start, 100, []milestone.Index{100, 90, 90, 100})
+	require.NotNil(t, suspicion)
+	require.Equal(t, partition.ReasonDiverging, suspicion.Reason)
+	require.Equal(t, 2, suspicion.DivergingPeers)
+	require.Equal(t, 4, suspicion.Peers)
+}
@@ -0,0 +1,81 @@
+package peering
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/peering/peer"
+)
+
+var (
+	// ErrPeerNotConnected is returned when no peer with the given ID is connected.
+	ErrPeerNotConnected = errors.New("peer is not connected")
+)
+
+// Peer returns the connected peer with the given ID.
+// Returns nil if no such peer is connected or the manager is shut down.
+func (m *Manager) Peer(id string) *peer.Peer {
+	if m.shutdown.Load() {
+		return nil
+	}
+
+	return m.connectedPeers()[id]
+}
+
+// PeerContext returns the connected peer with the given ID.
+// Returns the error of the given context if it is already done.
+func (m *Manager) PeerContext(ctx context.Context, id string) (*peer.Peer, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if m.shutdown.Load() {
+		return nil, ErrManagerIsShutdown
+	}
+
+	p, exists := m.connectedPeers()[id]
+	if !exists {
+		return nil, ErrPeerNotConnected
+	}
+	return p, nil
+}
+
+// ForAllConnectedContext executes the given function for each currently connected and handshaked peer
+// until abort is returned from within the consumer function or the given context is done.
+func (m *Manager) ForAllConnectedContext(ctx context.Context, f PeerConsumerFunc) error {
+	if m.shutdown.Load() {
+		return ErrManagerIsShutdown
+	}
+
+	for _, p := range m.connectedPeers() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !p.Handshaked() {
+			continue
+		}
+		if !f(p) {
+			break
+		}
+	}
+	return nil
+}
+
+// SyncedPeers returns the connected and handshaked peers which are synced according to their latest heartbeat
+// and the given latest milestone index.
+func (m *Manager) SyncedPeers(latestMilestoneIndex milestone.Index) []*peer.Peer {
+	var synced []*peer.Peer
+	m.ForAllConnected(func(p *peer.Peer) bool {
+		if p.IsSynced(latestMilestoneIndex) {
+			synced = append(synced, p)
+		}
+		return true
+	})
+	return synced
+}
+
+// DeprioritizedUnsynced tells whether the given peer should only be used if no synced peer is left,
+// since synced peers are preferred and the peer isn't synced according to the given latest milestone index.
+func (m *Manager) DeprioritizedUnsynced(p *peer.Peer, latestMilestoneIndex milestone.Index) bool {
+	return m.Opts.PreferSyncedPeers && !p.IsSynced(latestMilestoneIndex)
+}
@@ -0,0 +1,198 @@
+package peering
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/iotaledger/hive.go/iputils"
+
+	"github.com/gohornet/hornet/pkg/peering/peer"
+)
+
+const (
+	// BanCheckInterval is the interval at which expired bans are lifted.
+	BanCheckInterval = 1 * time.Minute
+)
+
+var (
+	// ErrPeerBanned is returned when a banned peer tried to connect.
+	ErrPeerBanned = errors.New("peer is banned")
+	// ErrInvalidBanDuration is returned when a peer should be banned for a non-positive duration.
+	ErrInvalidBanDuration = errors.New("invalid ban duration")
+	// ErrPeerNotBanned is returned when a ban should be lifted for a peer which isn't banned.
+	ErrPeerNotBanned = errors.New("peer is not banned")
+)
+
+// Ban describes a peer whose connections are refused until the ban expires.
+type Ban struct {
+	// The ID of the banned peer.
+	PeerID string `json:"peerId"`
+	// Why the peer was banned.
+	Reason string `json:"reason"`
+	// When the peer was banned.
+	Since time.Time `json:"since"`
+	// When the ban expires.
+	Until time.Time `json:"until"`
+}
+
+// Expired tells whether the ban expired at the given time.
+func (b *Ban) Expired(now time.Time) bool {
+	return !now.Before(b.Until)
+}
+
+// BanCaller is the caller of the PeerBanned and PeerUnbanned events.
+func BanCaller(handler interface{}, params ...interface{}) {
+	handler.(func(*Ban))(params[0].(*Ban))
+}
+
+// returns the IDs of the given peer for all IP addresses its address resolves to.
+func banIDs(peerID string) ([]string, error) {
+	originAddr, err := iputils.ParseOriginAddress(peerID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid peer address '%s': %w", peerID, err)
+	}
+
+	possibleIPs, err := iputils.GetIPAddressesFromHost(originAddr.Addr)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(possibleIPs.IPs))
+	for ip := range possibleIPs.IPs {
+		ids = append(ids, peer.NewID(ip.String(), originAddr.Port))
+	}
+	return ids, nil
+}
+
+// Ban disconnects the peer with the given ID and refuses its connections for the given duration.
+// Peers of the reconnect pool stay in it, but aren't connected to until the ban expires.
+// Banning an already banned peer replaces its ban.
+func (m *Manager) Ban(peerID string, duration time.Duration, reason string) error {
+	if duration <= 0 {
+		return fmt.Errorf("%w: %v", ErrInvalidBanDuration, duration)
+	}
+
+	ids, err := banIDs(peerID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	bans := make([]*Ban, 0, len(ids))
+
+	m.bansMu.Lock()
+	for _, id := range ids {
+		ban := &Ban{PeerID: id, Reason: reason, Since: now, Until: now.Add(duration)}
+		m.bans[id] = ban
+		bans = append(bans, ban)
+	}
+	m.bansMu.Unlock()
+
+	// the connections are closed outside of the lock, since closing a connection moves the peer into the reconnect pool
+	var toClose []*peer.Peer
+	m.RLock()
+	for _, id := range ids {
+		if p, exists := m.connected[id]; exists && p.Conn != nil {
+			toClose = append(toClose, p)
+		}
+	}
+	m.RUnlock()
+
+	for _, ban := range bans {
+		m.Events.PeerBanned.Trigger(ban)
+	}
+
+	for _, p := range toClose {
+		_ = p.Conn.Close()
+	}
+
+	return nil
+}
+
+// Unban lifts the ban of the peer with the given ID.
+func (m *Manager) Unban(peerID string) error {
+	ids, err := banIDs(peerID)
+	if err != nil {
+		return err
+	}
+
+	var lifted []*Ban
+	m.bansMu.Lock()
+	for _, id := range ids {
+		if ban, exists := m.bans[id]; exists {
+			delete(m.bans, id)
+			lifted = append(lifted, ban)
+		}
+	}
+	m.bansMu.Unlock()
+
+	if len(lifted) == 0 {
+		return fmt.Errorf("%w: %s", ErrPeerNotBanned, peerID)
+	}
+
+	for _, ban := range lifted {
+		m.Events.PeerUnbanned.Trigger(ban)
+	}
+	return nil
+}
+
+// Banned tells whether the peer with the given ID is banned.
+func (m *Manager) Banned(id string) bool {
+	m.bansMu.Lock()
+	defer m.bansMu.Unlock()
+
+	ban, exists := m.bans[id]
+	return exists && !ban.Expired(time.Now())
+}
+
+// Bans returns the bans which did not expire yet, ordered by their expiry.
+func (m *Manager) Bans() []*Ban {
+	now := time.Now()
+
+	m.bansMu.Lock()
+	bans := make([]*Ban, 0, len(m.bans))
+	for _, ban := range m.bans {
+		if ban.Expired(now) {
+			continue
+		}
+		banCopy := *ban
+		bans = append(bans, &banCopy)
+	}
+	m.bansMu.Unlock()
+
+	sort.Slice(bans, func(i, j int) bool {
+		return bans[i].Until.Before(bans[j].Until)
+	})
+	return bans
+}
+
+// RestoreBans adds the given bans, e.g. the ones persisted before a restart.
+// No PeerBanned events are fired for the restored bans, the expired ones are lifted by the next ExpireBans.
+func (m *Manager) RestoreBans(bans []*Ban) {
+	m.bansMu.Lock()
+	defer m.bansMu.Unlock()
+	for _, ban := range bans {
+		m.bans[ban.PeerID] = ban
+	}
+}
+
+// ExpireBans lifts the expired bans and fires a PeerUnbanned event for each of them.
+func (m *Manager) ExpireBans() {
+	now := time.Now()
+
+	var expired []*Ban
+	m.bansMu.Lock()
+	for id, ban := range m.bans {
+		if ban.Expired(now) {
+			delete(m.bans, id)
+			expired = append(expired, ban)
+		}
+	}
+	m.bansMu.Unlock()
+
+	for _, ban := range expired {
+		m.Events.PeerUnbanned.Trigger(ban)
+	}
+}
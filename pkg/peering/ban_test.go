@@ -0,0 +1,126 @@
+package peering
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/iotaledger/hive.go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// returns the amount of peers in the reconnect pool of the given manager.
+func reconnectPoolSize(m *Manager) int {
+	m.RLock()
+	defer m.RUnlock()
+	return len(m.reconnect)
+}
+
+func TestBanExpiry(t *testing.T) {
+	m := newListeningManager(t, freePort(t))
+	defer m.Shutdown()
+
+	var unbanned []*Ban
+	m.Events.PeerUnbanned.Attach(events.NewClosure(func(ban *Ban) {
+		unbanned = append(unbanned, ban)
+	}))
+
+	assert.True(t, errors.Is(m.Ban("127.0.0.1:15600", 0, "spam"), ErrInvalidBanDuration))
+	assert.True(t, errors.Is(m.Unban("127.0.0.1:15600"), ErrPeerNotBanned))
+
+	require.NoError(t, m.Ban("127.0.0.1:15600", time.Hour, "spam"))
+	require.NoError(t, m.Ban("127.0.0.1:15601", 50*time.Millisecond, "invalid transactions"))
+	assert.True(t, m.Banned("127.0.0.1:15600"))
+	assert.True(t, m.Banned("127.0.0.1:15601"))
+
+	// the bans are ordered by their expiry
+	bans := m.Bans()
+	require.Len(t, bans, 2)
+	assert.Equal(t, "127.0.0.1:15601", bans[0].PeerID)
+	assert.Equal(t, "invalid transactions", bans[0].Reason)
+	assert.Equal(t, "127.0.0.1:15600", bans[1].PeerID)
+
+	// an expired ban doesn't refuse the peer anymore, even before it is lifted
+	time.Sleep(60 * time.Millisecond)
+	assert.False(t, m.Banned("127.0.0.1:15601"))
+	require.Len(t, m.Bans(), 1)
+	assert.Empty(t, unbanned)
+
+	m.ExpireBans()
+	require.Len(t, unbanned, 1)
+	assert.Equal(t, "127.0.0.1:15601", unbanned[0].PeerID)
+
+	// the ban is only lifted once
+	m.ExpireBans()
+	assert.Len(t, unbanned, 1)
+
+	require.NoError(t, m.Unban("127.0.0.1:15600"))
+	assert.False(t, m.Banned("127.0.0.1:15600"))
+	assert.Len(t, unbanned, 2)
+}
+
+func TestRestoreBans(t *testing.T) {
+	m := newListeningManager(t, freePort(t))
+	defer m.Shutdown()
+
+	var banned int
+	m.Events.PeerBanned.Attach(events.NewClosure(func(ban *Ban) { banned++ }))
+
+	now := time.Now()
+	m.RestoreBans([]*Ban{
+		{PeerID: "127.0.0.1:15600", Reason: "spam", Since: now.Add(-time.Hour), Until: now.Add(time.Hour)},
+		{PeerID: "127.0.0.1:15601", Reason: "spam", Since: now.Add(-time.Hour), Until: now.Add(-time.Minute)},
+	})
+
+	// no events are fired for the restored bans, the expired ones are lifted by the next expiry
+	assert.Zero(t, banned)
+	assert.True(t, m.Banned("127.0.0.1:15600"))
+	assert.False(t, m.Banned("127.0.0.1:15601"))
+	assert.Len(t, m.Bans(), 1)
+}
+
+func TestBanDisconnectsPeer(t *testing.T) {
+	portA, portB := freePort(t), freePort(t)
+	a := newListeningManager(t, portA)
+	defer a.Shutdown()
+	b := newListeningManager(t, portB)
+	defer b.Shutdown()
+
+	idOfA, idOfB := fmt.Sprintf("127.0.0.1:%d", portA), fmt.Sprintf("127.0.0.1:%d", portB)
+
+	require.NoError(t, a.Add(idOfB, false, "b"))
+	require.Eventually(t, func() bool { return len(handshakedPeers(a)) == 1 && len(handshakedPeers(b)) == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	// the banned peer is disconnected on both sides, but stays in the reconnect pool
+	require.NoError(t, a.Ban(idOfB, time.Hour, "spam"))
+	require.Eventually(t, func() bool { return len(handshakedPeers(a)) == 0 && len(handshakedPeers(b)) == 0 }, 5*time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool { return reconnectPoolSize(a) == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	// no connection to the banned peer is initiated on reconnect
+	a.Reconnect()
+	time.Sleep(200 * time.Millisecond)
+	assert.Empty(t, handshakedPeers(a))
+	assert.Empty(t, handshakedPeers(b))
+	assert.Equal(t, 1, reconnectPoolSize(a))
+
+	// the connections of the banned peer are refused
+	refused := make(chan error, 10)
+	a.Events.Error.Attach(events.NewClosure(func(err error) { refused <- err }))
+	require.NoError(t, b.Add(idOfA, false, "a"))
+
+	select {
+	case err := <-refused:
+		assert.True(t, errors.Is(err, ErrPeerBanned))
+	case <-time.After(5 * time.Second):
+		t.Fatal("the connection of the banned peer was not refused")
+	}
+	assert.Empty(t, handshakedPeers(a))
+	require.Eventually(t, func() bool { return reconnectPoolSize(b) == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	// the connections of the peer are accepted again once the ban is lifted
+	require.NoError(t, a.Unban(idOfB))
+	b.Reconnect()
+	require.Eventually(t, func() bool { return len(handshakedPeers(a)) == 1 && len(handshakedPeers(b)) == 1 }, 5*time.Second, 10*time.Millisecond)
+}
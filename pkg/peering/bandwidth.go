@@ -0,0 +1,29 @@
+package peering
+
+import (
+	"github.com/gohornet/hornet/pkg/peering/peer"
+)
+
+// BandwidthLimit defines the bytes per second which may be sent to and received from a peer (0 = unlimited).
+type BandwidthLimit struct {
+	UploadBytesPerSecond   int
+	DownloadBytesPerSecond int
+}
+
+// BandwidthLimits defines the bandwidth limits of the peers per peer relation,
+// so that the gossip can't saturate the connection of the node.
+type BandwidthLimits struct {
+	// The bandwidth limit of statically configured peers.
+	Static BandwidthLimit
+	// The bandwidth limit of autopeered peers.
+	Autopeered BandwidthLimit
+}
+
+// applyBandwidthLimit sets the bandwidth limit of the given peer according to its relation.
+func (m *Manager) applyBandwidthLimit(p *peer.Peer) {
+	limit := m.Opts.Bandwidth.Static
+	if p.Autopeering != nil {
+		limit = m.Opts.Bandwidth.Autopeered
+	}
+	p.Protocol.SetBandwidthLimits(limit.UploadBytesPerSecond, limit.DownloadBytesPerSecond)
+}
@@ -0,0 +1,57 @@
+package peering
+
+import (
+	"errors"
+	"net"
+	"syscall"
+)
+
+// ConnectFailureReason classifies why an attempt to connect to a peer failed.
+type ConnectFailureReason string
+
+const (
+	// ConnectFailureTimeout means the peer didn't accept the connection in time.
+	ConnectFailureTimeout ConnectFailureReason = "timeout"
+	// ConnectFailureRefused means the peer actively refused the connection, e.g. because the node isn't running.
+	ConnectFailureRefused ConnectFailureReason = "refused"
+	// ConnectFailureUnreachable means there is no route to the peer.
+	ConnectFailureUnreachable ConnectFailureReason = "unreachable"
+	// ConnectFailureOther means the attempt failed for any other reason.
+	ConnectFailureOther ConnectFailureReason = "other"
+)
+
+// ConnectFailure describes a failed attempt to connect to a peer.
+type ConnectFailure struct {
+	// The ID of the peer.
+	PeerID string
+	// The alias of the peer, empty if it has none.
+	Alias string
+	// The number of the failed attempt, starting at 1.
+	Attempt int
+	// Whether the attempt is going to be retried.
+	Retrying bool
+	// The classification of the error.
+	Reason ConnectFailureReason
+	// The error of the attempt.
+	Err error
+}
+
+// ConnectFailedCaller is the caller of the ConnectFailed event.
+func ConnectFailedCaller(handler interface{}, params ...interface{}) {
+	handler.(func(*ConnectFailure))(params[0].(*ConnectFailure))
+}
+
+// classifies the error of a failed attempt to connect to a peer.
+func classifyConnectError(err error) ConnectFailureReason {
+	var netErr net.Error
+	switch {
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ConnectFailureTimeout
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ConnectFailureRefused
+	case errors.Is(err, syscall.EHOSTUNREACH), errors.Is(err, syscall.ENETUNREACH):
+		return ConnectFailureUnreachable
+	default:
+		return ConnectFailureOther
+	}
+}
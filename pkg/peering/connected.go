@@ -0,0 +1,46 @@
+package peering
+
+import (
+	"github.com/gohornet/hornet/pkg/peering/peer"
+)
+
+// an immutable copy of the connected pool.
+// it is replaced as a whole whenever a peer is added to or removed from the connected pool,
+// so that lookups of the connected peers don't contend with handshakes, reconnects and removals holding the manager lock.
+type connectedSnapshot map[string]*peer.Peer
+
+// returns the latest snapshot of the connected pool.
+func (m *Manager) connectedPeers() connectedSnapshot {
+	snapshot, ok := m.connectedSnapshot.Load().(connectedSnapshot)
+	if !ok {
+		return connectedSnapshot{}
+	}
+	return snapshot
+}
+
+// adds the given peer to the connected pool.
+// the manager lock must be held by the caller.
+func (m *Manager) addConnected(p *peer.Peer) {
+	m.connected[p.ID] = p
+	m.publishConnected()
+}
+
+// removes the peer with the given ID from the connected pool.
+// the manager lock must be held by the caller.
+func (m *Manager) removeConnected(id string) {
+	if _, exists := m.connected[id]; !exists {
+		return
+	}
+	delete(m.connected, id)
+	m.publishConnected()
+}
+
+// replaces the snapshot of the connected pool with a copy of the current connected pool.
+// the manager lock must be held by the caller.
+func (m *Manager) publishConnected() {
+	snapshot := make(connectedSnapshot, len(m.connected))
+	for id, p := range m.connected {
+		snapshot[id] = p
+	}
+	m.connectedSnapshot.Store(snapshot)
+}
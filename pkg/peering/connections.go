@@ -0,0 +1,66 @@
+package peering
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/gohornet/hornet/pkg/peering/peer"
+)
+
+// enforceConnectionLimit checks the connection limit of the direction of the given handshaking peer.
+// Unknown peers are refused if the limit is reached. Known peers are protected: they prune the connection
+// to the unknown peer with the lowest score in the same direction instead, or exceed the limit if there is none,
+// since the amount of known peers is already limited by the peering slots.
+// the manager lock must be held by the caller.
+func (m *Manager) enforceConnectionLimit(p *peer.Peer, known bool) error {
+	resource, limit := ResourceOutboundConnections, m.Opts.Limits.MaxOutbound
+	if p.IsInbound() {
+		resource, limit = ResourceInboundConnections, m.Opts.Limits.MaxInbound
+	}
+
+	if limit == 0 {
+		return nil
+	}
+
+	count := 0
+	var lowest *peer.Peer
+	var lowestScore float64
+	for _, connectedPeer := range m.connected {
+		if connectedPeer == p || connectedPeer.IsInbound() != p.IsInbound() || !connectedPeer.Handshaked() {
+			continue
+		}
+		count++
+
+		if _, whitelisted := m.Whitelisted(connectedPeer.ID); whitelisted {
+			continue
+		}
+		if score := connectedPeer.Score().Score; lowest == nil || score < lowestScore {
+			lowest, lowestScore = connectedPeer, score
+		}
+	}
+
+	if count < limit {
+		return nil
+	}
+
+	if !known {
+		m.resourceLimitReached(resource, int64(limit), p.ID)
+		return errors.Wrapf(ErrResourceLimitReached, "%s connections: %s", resource, p.ID)
+	}
+
+	if lowest != nil {
+		m.prune(lowest)
+	}
+	return nil
+}
+
+// prune closes the connection of the given unknown peer to make room for a known peer.
+// the manager lock must be held by the caller.
+func (m *Manager) prune(p *peer.Peer) {
+	m.metrics.prunedConnections.Inc()
+	p.MoveBackToReconnectPool = false
+	p.Disconnected = true
+	m.removeConnected(p.ID)
+	go m.closeGracefully(p)
+	m.Events.ConnectionPruned.Trigger(p)
+	m.Events.PeerDisconnected.Trigger(p)
+}
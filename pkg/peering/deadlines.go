@@ -0,0 +1,35 @@
+package peering
+
+import (
+	"time"
+
+	"github.com/gohornet/hornet/pkg/peering/peer"
+)
+
+const (
+	// DefaultWriteTimeout is the write timeout of the connections to the peers if none is configured.
+	DefaultWriteTimeout = 5 * time.Second
+)
+
+// Deadlines defines the read and write deadlines of the connections to the peers,
+// so that a stalled peer can't hold a goroutine and the memory of its buffers indefinitely.
+type Deadlines struct {
+	// The time after which a read from a connection without receiving any data times out (0 = no read deadline).
+	ReadTimeout time.Duration
+	// The time after which a write to a connection times out (0 = DefaultWriteTimeout).
+	WriteTimeout time.Duration
+	// The amount of consecutive read or write deadlines a connection may exceed before it is closed.
+	MaxHits int
+}
+
+// applyDeadlines sets the read and write deadlines of the connection of the given peer.
+func (m *Manager) applyDeadlines(p *peer.Peer) {
+	writeTimeout := m.Opts.Deadlines.WriteTimeout
+	if writeTimeout == 0 {
+		writeTimeout = DefaultWriteTimeout
+	}
+
+	p.Conn.SetReadTimeout(m.Opts.Deadlines.ReadTimeout)
+	p.Conn.SetWriteTimeout(writeTimeout)
+	p.Protocol.SetMaxDeadlineHits(int32(m.Opts.Deadlines.MaxHits))
+}
@@ -0,0 +1,92 @@
+package peering
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/gohornet/hornet/pkg/geoip"
+	"github.com/gohornet/hornet/pkg/peering/peer"
+)
+
+const (
+	// the prefix lengths of the subnets whose autopeered peers are limited.
+	diversitySubnetBitsIPv4 = 16
+	diversitySubnetBitsIPv6 = 32
+)
+
+var (
+	// ErrDiversityLimitReached is returned when an autopeered peer is rejected because too many
+	// autopeered peers of the same subnet, country or autonomous system are connected.
+	ErrDiversityLimitReached = errors.New("peer diversity limit reached")
+)
+
+// DiversityLimits defines how many autopeered peers may share a subnet, a country or an autonomous system,
+// so that a single network can't take over all autopeering slots of the node (eclipse attack). 0 disables a limit.
+type DiversityLimits struct {
+	// The max amount of autopeered peers within the same /16 (IPv4) or /32 (IPv6) subnet.
+	MaxPerSubnet int
+	// The max amount of autopeered peers located in the same country.
+	MaxPerCountry int
+	// The max amount of autopeered peers within the same autonomous system.
+	MaxPerASN int
+	// The database to look up the country and the autonomous system of the peers.
+	// The country and ASN limits are not enforced without it.
+	GeoIP *geoip.DB
+}
+
+// returns the subnet of the given IP address whose autopeered peers are limited.
+func diversitySubnet(ip net.IP) *net.IPNet {
+	if ip4 := ip.To4(); ip4 != nil {
+		mask := net.CIDRMask(diversitySubnetBitsIPv4, 8*net.IPv4len)
+		return &net.IPNet{IP: ip4.Mask(mask), Mask: mask}
+	}
+	mask := net.CIDRMask(diversitySubnetBitsIPv6, 8*net.IPv6len)
+	return &net.IPNet{IP: ip.Mask(mask), Mask: mask}
+}
+
+// checkDiversity checks whether the given autopeered peer can be connected without exceeding the diversity limits.
+func (m *Manager) checkDiversity(p *peer.Peer) error {
+	limits := m.Opts.Diversity
+	if p.Autopeering == nil || (limits.MaxPerSubnet == 0 && limits.MaxPerCountry == 0 && limits.MaxPerASN == 0) {
+		return nil
+	}
+
+	subnet := diversitySubnet(p.PrimaryAddress)
+	location, located := limits.GeoIP.Lookup(p.PrimaryAddress)
+
+	var sameSubnet, sameCountry, sameASN int
+	m.ForAllConnected(func(connectedPeer *peer.Peer) bool {
+		if connectedPeer == p || connectedPeer.Autopeering == nil {
+			return true
+		}
+
+		if subnet.Contains(connectedPeer.PrimaryAddress) {
+			sameSubnet++
+		}
+
+		if !located {
+			return true
+		}
+
+		if connectedLocation, ok := limits.GeoIP.Lookup(connectedPeer.PrimaryAddress); ok {
+			if location.Country != "" && connectedLocation.Country == location.Country {
+				sameCountry++
+			}
+			if location.ASN != 0 && connectedLocation.ASN == location.ASN {
+				sameASN++
+			}
+		}
+		return true
+	})
+
+	switch {
+	case limits.MaxPerSubnet != 0 && sameSubnet >= limits.MaxPerSubnet:
+		return fmt.Errorf("%w: %d autopeered peers within %s", ErrDiversityLimitReached, sameSubnet, subnet)
+	case limits.MaxPerCountry != 0 && sameCountry >= limits.MaxPerCountry:
+		return fmt.Errorf("%w: %d autopeered peers located in %s", ErrDiversityLimitReached, sameCountry, location.Country)
+	case limits.MaxPerASN != 0 && sameASN >= limits.MaxPerASN:
+		return fmt.Errorf("%w: %d autopeered peers within AS%d", ErrDiversityLimitReached, sameASN, location.ASN)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package peering
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/gohornet/hornet/pkg/peering/peer"
+	"github.com/gohornet/hornet/pkg/protocol"
+)
+
+const (
+	// the version of the peer export format.
+	peerExportVersion = 1
+)
+
+var (
+	// ErrInvalidPeerExport is returned when a peer export can't be parsed or contains an invalid peer.
+	ErrInvalidPeerExport = errors.New("invalid peer export")
+)
+
+// PeerExport is the peer set of a node, used to migrate the peers to another node or to restore them after a reinstall.
+type PeerExport struct {
+	// The version of the export format.
+	Version int `json:"version"`
+	// The exported peers.
+	Peers []*ExportedPeer `json:"peers"`
+}
+
+// ExportedPeer is a peer within a peer export.
+type ExportedPeer struct {
+	// The address the peer was added with, prefixed with the scheme of its transport if it isn't TCP.
+	Address string `json:"address"`
+	// The alias of the peer.
+	Alias string `json:"alias,omitempty"`
+	// Whether IPv6 addresses of the peer are preferred.
+	PreferIPv6 bool `json:"preferIPv6,omitempty"`
+	// The relation to the peer, only static peers are imported.
+	Relation string `json:"relation"`
+}
+
+// ParsePeerExport parses the given peer export and validates its version and the relations of its peers.
+func ParsePeerExport(data []byte) (*PeerExport, error) {
+	export := &PeerExport{}
+	if err := json.Unmarshal(data, export); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPeerExport, err)
+	}
+	if export.Version != peerExportVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidPeerExport, export.Version)
+	}
+
+	for _, p := range export.Peers {
+		if p == nil || p.Address == "" {
+			return nil, fmt.Errorf("%w: peer without address", ErrInvalidPeerExport)
+		}
+		if _, err := protocol.ParseRelation(p.Relation); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidPeerExport, p.Address, err)
+		}
+	}
+	return export, nil
+}
+
+// StaticPeers returns the peers of the export which are imported, the autopeered peers are selected by the autopeering instead.
+func (e *PeerExport) StaticPeers() []*ExportedPeer {
+	var peers []*ExportedPeer
+	for _, p := range e.Peers {
+		if relation, _ := protocol.ParseRelation(p.Relation); relation == protocol.RelationStatic {
+			peers = append(peers, p)
+		}
+	}
+	return peers
+}
+
+// ExportPeers returns the connected peers and the peers of the reconnect pool together with their relations and aliases
+// as a JSON document. Peers whose relation is unknown are not exported, since they weren't added to the node.
+func (m *Manager) ExportPeers() ([]byte, error) {
+	exported := make(map[string]*ExportedPeer)
+	m.ForAll(func(p *peer.Peer) bool {
+		relation := p.Relation()
+		if p.InitAddress == nil || relation == protocol.RelationUnknown {
+			return true
+		}
+
+		address := p.Transport.Address(p.InitAddress.String())
+		exported[address] = &ExportedPeer{
+			Address:    address,
+			Alias:      p.InitAddress.Alias,
+			PreferIPv6: p.InitAddress.PreferIPv6,
+			Relation:   relation.String(),
+		}
+		return true
+	})
+
+	export := &PeerExport{Version: peerExportVersion, Peers: make([]*ExportedPeer, 0, len(exported))}
+	for _, p := range exported {
+		export.Peers = append(export.Peers, p)
+	}
+	sort.Slice(export.Peers, func(i, j int) bool {
+		return export.Peers[i].Address < export.Peers[j].Address
+	})
+
+	return json.MarshalIndent(export, "", "  ")
+}
+
+// ImportPeers adds the static peers of the given JSON document created by ExportPeers.
+// The import is atomic: the addresses of all peers are resolved first and no peer is added if any of them is invalid.
+// Peers which are already connected or in the reconnect pool are kept. Returns the amount of added peers.
+func (m *Manager) ImportPeers(data []byte) (int, error) {
+	export, err := ParsePeerExport(data)
+	if err != nil {
+		return 0, err
+	}
+
+	var resolved []*resolvedPeerAddress
+	for _, p := range export.StaticPeers() {
+		addr, err := resolvePeerAddress(p.Address, p.PreferIPv6, p.Alias)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrInvalidPeerExport, err)
+		}
+		resolved = append(resolved, addr)
+	}
+
+	added := 0
+	reconnect := false
+
+	m.Lock()
+	for _, addr := range resolved {
+		addedPeer, err := m.add(addr, nil)
+		if err != nil {
+			// already known peers are kept as they are
+			continue
+		}
+		if addedPeer {
+			added++
+			reconnect = true
+		}
+	}
+	m.Unlock()
+
+	if reconnect {
+		m.Reconnect()
+	}
+	return added, nil
+}
@@ -0,0 +1,177 @@
+package peering
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/peering/peer"
+)
+
+const (
+	// FailoverCheckInterval is the interval in which the primaries of the backup peers are checked.
+	FailoverCheckInterval = 5 * time.Second
+)
+
+// FailoverCaller is the caller of the FailoverActivated and FailoverDeactivated events, called with the primary and the backup peer.
+func FailoverCaller(handler interface{}, params ...interface{}) {
+	handler.(func(string, string))(params[0].(string), params[1].(string))
+}
+
+// a peer which is only connected while its primary peer is unreachable.
+type backupPeer struct {
+	config config.PeerConfig
+	// whether the backup was added to the manager, since its primary is unreachable.
+	active bool
+}
+
+// BackupPeer is the state of a backup peer.
+type BackupPeer struct {
+	// The address of the backup peer.
+	Address string `json:"address"`
+	// The alias of the backup peer.
+	Alias string `json:"alias,omitempty"`
+	// The address of the primary peer the backup stands in for.
+	Primary string `json:"primary"`
+	// Whether the backup is dialed, since its primary is unreachable.
+	Active bool `json:"active"`
+}
+
+// SetBackupPeers replaces the backup peers, i.e. the peers with a primary peer (BackupFor) which are only connected
+// while their primary is unreachable. Active backups which are no longer configured are removed.
+func (m *Manager) SetBackupPeers(peers []*config.PeerConfig) {
+	backups := make(map[string]*backupPeer)
+	for _, peerConf := range peers {
+		if peerConf.ID == "" || peerConf.BackupFor == "" {
+			continue
+		}
+		backups[peerConf.ID] = &backupPeer{config: *peerConf}
+	}
+
+	var removed []string
+
+	m.failoverMu.Lock()
+	for id, backup := range m.backups {
+		if !backup.active {
+			continue
+		}
+		if configured, exists := backups[id]; exists && configured.config.BackupFor == backup.config.BackupFor {
+			configured.active = true
+			continue
+		}
+		removed = append(removed, id)
+	}
+	m.backups = backups
+	m.failoverMu.Unlock()
+
+	for _, id := range removed {
+		if err := m.Remove(id); err != nil {
+			m.Events.Error.Trigger(err)
+		}
+	}
+}
+
+// BackupPeers returns the state of the backup peers.
+func (m *Manager) BackupPeers() []*BackupPeer {
+	m.failoverMu.Lock()
+	defer m.failoverMu.Unlock()
+
+	backups := make([]*BackupPeer, 0, len(m.backups))
+	for _, backup := range m.backups {
+		backups = append(backups, &BackupPeer{
+			Address: backup.config.ID,
+			Alias:   backup.config.Alias,
+			Primary: backup.config.BackupFor,
+			Active:  backup.active,
+		})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Address < backups[j].Address
+	})
+	return backups
+}
+
+// IsBackupPeer tells whether the given address belongs to a backup peer.
+func (m *Manager) IsBackupPeer(address string) bool {
+	m.failoverMu.Lock()
+	defer m.failoverMu.Unlock()
+
+	_, isBackup := m.backups[address]
+	return isBackup
+}
+
+// CheckFailover adds the backup peers whose primary is unreachable for longer than the failover delay,
+// and removes them again once their primary is connected.
+func (m *Manager) CheckFailover() {
+	if m.shutdown.Load() {
+		return
+	}
+
+	connected := make(map[string]struct{})
+	m.ForAllConnected(func(p *peer.Peer) bool {
+		connected[strings.ToLower(p.ID)] = struct{}{}
+		if p.InitAddress != nil {
+			connected[strings.ToLower(p.InitAddress.String())] = struct{}{}
+		}
+		return true
+	})
+
+	isConnected := func(address string) bool {
+		_, addr := peer.ParseTransportAddress(address)
+		_, exists := connected[strings.ToLower(addr)]
+		return exists
+	}
+
+	var activate, deactivate []config.PeerConfig
+
+	m.failoverMu.Lock()
+	primaries := make(map[string]struct{})
+	for _, backup := range m.backups {
+		primary := backup.config.BackupFor
+		primaries[primary] = struct{}{}
+
+		if isConnected(primary) {
+			delete(m.primaryOutages, primary)
+			if backup.active {
+				backup.active = false
+				deactivate = append(deactivate, backup.config)
+			}
+			continue
+		}
+
+		since, exists := m.primaryOutages[primary]
+		if !exists {
+			since = time.Now()
+			m.primaryOutages[primary] = since
+		}
+		if !backup.active && time.Since(since) >= m.Opts.FailoverDelay {
+			backup.active = true
+			activate = append(activate, backup.config)
+		}
+	}
+
+	// forget the outages of primaries without backups
+	for primary := range m.primaryOutages {
+		if _, exists := primaries[primary]; !exists {
+			delete(m.primaryOutages, primary)
+		}
+	}
+	m.failoverMu.Unlock()
+
+	for _, backup := range deactivate {
+		if err := m.Remove(backup.ID); err != nil {
+			m.Events.Error.Trigger(err)
+			continue
+		}
+		m.Events.FailoverDeactivated.Trigger(backup.BackupFor, backup.ID)
+	}
+
+	for _, backup := range activate {
+		if err := m.Add(backup.ID, backup.PreferIPv6, backup.Alias); err != nil {
+			m.Events.Error.Trigger(err)
+			continue
+		}
+		m.Events.FailoverActivated.Trigger(backup.BackupFor, backup.ID)
+	}
+}
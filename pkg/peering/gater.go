@@ -0,0 +1,147 @@
+package peering
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/gohornet/hornet/pkg/peering/peer"
+)
+
+var (
+	// ErrConnectionGated is returned when a connection is refused by the allow and deny lists.
+	ErrConnectionGated = errors.New("connection refused by the gater")
+	// ErrInvalidGaterRule is returned when an entry of the allow and deny lists is neither an IP address nor a CIDR.
+	ErrInvalidGaterRule = errors.New("invalid gater rule")
+)
+
+// GaterRules defines by IP address and peer ID which connections are allowed.
+// Denied entries take precedence over allowed ones. If an allow list isn't empty, only the matching connections are allowed.
+type GaterRules struct {
+	allowedNetworks []*net.IPNet
+	deniedNetworks  []*net.IPNet
+	allowedPeers    map[string]struct{}
+	deniedPeers     map[string]struct{}
+}
+
+// NewGaterRules creates the gater rules from the given lists of IP addresses or CIDRs and peer IDs (ip:port).
+func NewGaterRules(allowedIPs []string, deniedIPs []string, allowedPeers []string, deniedPeers []string) (*GaterRules, error) {
+	allowedNetworks, err := parseNetworks(allowedIPs)
+	if err != nil {
+		return nil, err
+	}
+	deniedNetworks, err := parseNetworks(deniedIPs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GaterRules{
+		allowedNetworks: allowedNetworks,
+		deniedNetworks:  deniedNetworks,
+		allowedPeers:    peerIDSet(allowedPeers),
+		deniedPeers:     peerIDSet(deniedPeers),
+	}, nil
+}
+
+// parses the given IP addresses or CIDRs into networks, a single IP address is a network of one address.
+func parseNetworks(entries []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(entries))
+	for _, entry := range entries {
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("%w: '%s'", ErrInvalidGaterRule, entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%w: '%s'", ErrInvalidGaterRule, entry)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// returns the given peer IDs as a set, in the format the IDs of the peers are derived in.
+func peerIDSet(ids []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		if host, port, err := net.SplitHostPort(id); err == nil {
+			id = net.JoinHostPort(host, port)
+		}
+		set[id] = struct{}{}
+	}
+	return set
+}
+
+// tells whether any of the given networks contains the given IP address.
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsIP tells whether connections with the given IP address are allowed.
+func (r *GaterRules) AllowsIP(ip net.IP) bool {
+	if r == nil {
+		return true
+	}
+	if containsIP(r.deniedNetworks, ip) {
+		return false
+	}
+	return len(r.allowedNetworks) == 0 || containsIP(r.allowedNetworks, ip)
+}
+
+// AllowsPeer tells whether connections to the peer with the given ID are allowed.
+func (r *GaterRules) AllowsPeer(id string) bool {
+	if r == nil {
+		return true
+	}
+	if _, denied := r.deniedPeers[id]; denied {
+		return false
+	}
+	if len(r.allowedPeers) == 0 {
+		return true
+	}
+	_, allowed := r.allowedPeers[id]
+	return allowed
+}
+
+// returns the current gater rules, nil if no rules are set.
+func (m *Manager) gaterRules() *GaterRules {
+	rules, _ := m.gater.Load().(*GaterRules)
+	return rules
+}
+
+// SetGaterRules replaces the rules which define the allowed connections
+// and closes the connections of the peers which aren't allowed anymore.
+func (m *Manager) SetGaterRules(rules *GaterRules) {
+	m.gater.Store(rules)
+
+	// the connections are closed outside of the lock, since closing a connection moves the peer into the reconnect pool
+	var toClose []*peer.Peer
+	m.RLock()
+	for _, p := range m.connected {
+		if p.Conn == nil || (rules.AllowsIP(p.PrimaryAddress) && rules.AllowsPeer(p.ID)) {
+			continue
+		}
+		toClose = append(toClose, p)
+	}
+	m.RUnlock()
+
+	for _, p := range toClose {
+		m.metrics.gatedConnections.Inc()
+		_ = p.Conn.Close()
+	}
+}
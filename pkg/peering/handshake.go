@@ -2,6 +2,7 @@ package peering
 
 import (
 	"bytes"
+	"net"
 	"time"
 
 	"github.com/iotaledger/hive.go/events"
@@ -32,6 +33,19 @@ func (m *Manager) setupHandshakeEventHandlers(p *peer.Peer) {
 		}
 	}))
 
+	// verify received node info
+	p.Protocol.Events.Received[handshake.MessageTypeNodeInfo].Attach(events.NewClosure(func(data []byte) {
+		nodeInfo, err := handshake.ParseNodeInfo(data)
+		if err != nil {
+			p.Protocol.Events.Error.Trigger(err)
+			return
+		}
+
+		if err := m.verifyNodeInfo(p, nodeInfo); err != nil {
+			p.Protocol.Events.Error.Trigger(err)
+		}
+	}))
+
 	// propagate handshake completion to the manager
 	p.Protocol.Events.HandshakeCompleted.Attach(events.NewClosure(func() {
 
@@ -43,6 +57,7 @@ func (m *Manager) setupHandshakeEventHandlers(p *peer.Peer) {
 		// first receive timestamp has to be set here, otherwise we could falsely drop the peer if the heartbeat is checked
 		p.HeartbeatReceivedTime = time.Now()
 
+		m.recordConnected(p)
 		m.Events.PeerConnected.Trigger(p)
 	}))
 }
@@ -66,7 +81,7 @@ func (m *Manager) verifyHandshake(p *peer.Peer, handshakeMsg *handshake.Handshak
 	}
 
 	// check feature set compatibility
-	version, err := handshakeMsg.SupportedVersion(protocol.SupportedFeatureSets)
+	version, err := handshakeMsg.NegotiateVersion(protocol.SupportedFeatureSets)
 	if err != nil {
 		return errors.Wrapf(err, "protocol version %d is not supported", version)
 	}
@@ -85,21 +100,53 @@ func (m *Manager) verifyHandshake(p *peer.Peer, handshakeMsg *handshake.Handshak
 			p.Autopeering = autopeeringInfo
 			m.Events.ConnectedAutopeeredPeer.Trigger(p)
 		}
+		m.applyRelation(p)
 	case peer.Outbound:
+		// the port of a WebSocket connection is the one of the WebSocket listener instead of the server socket
 		expectedPort := p.InitAddress.Port
-		if handshakeMsg.ServerSocketPort != expectedPort {
+		if !p.Transport.IsWebSocket() && handshakeMsg.ServerSocketPort != expectedPort {
 			return errors.Wrapf(ErrNonMatchingSrvSocketPort, "expected %d as the server socket port but got %d", expectedPort, handshakeMsg.ServerSocketPort)
 		}
 	}
 
+	if m.Banned(p.ID) {
+		return errors.Wrapf(ErrPeerBanned, p.ID)
+	}
+
+	if !m.gaterRules().AllowsPeer(p.ID) {
+		return errors.Wrapf(ErrConnectionGated, p.ID)
+	}
+
 	// drop the connection if it's not an autopeer and in the meantime
 	// the available peering slots were filled
 	if p.Autopeering == nil && m.SlotsFilled() {
 		return ErrPeeringSlotsFilled
 	}
 
+	// autopeered peers only get a connection as long as not all autopeering slots are filled,
+	// so that autopeering can't take over the connections of the node
+	if p.Autopeering != nil && m.AutopeeringSlotsFilled() {
+		return ErrAutopeeringSlotsFilled
+	}
+
+	// autopeered peers of the same subnet, country or autonomous system are limited,
+	// so that a single network can't eclipse the node
+	if err := m.checkDiversity(p); err != nil {
+		return err
+	}
+
+	// the connections which lost the tie-breaking are closed after the manager lock was released,
+	// since the handlers of the PeerDisconnected event might call back into the manager
+	var duplicates []*peer.Peer
+	defer func() {
+		for _, duplicate := range duplicates {
+			m.closeDuplicate(duplicate)
+		}
+	}()
+
 	// check whether the peer is already connected by checking each peer's IP addresses
 	m.Lock()
+connectedPeersLoop:
 	for _, connectedPeer := range m.connected {
 		// skip self: we must check this now as we no longer have a concept about in-flight connections
 		if connectedPeer == p {
@@ -110,8 +157,16 @@ func (m *Manager) verifyHandshake(p *peer.Peer, handshakeMsg *handshake.Handshak
 			for ip := range connectedPeer.Addresses.IPs {
 				if ip.String() == handshakingPeerIP.String() &&
 					connectedPeer.InitAddress.Port == p.InitAddress.Port {
-					m.Unlock()
-					return errors.Wrapf(ErrPeerAlreadyConnected, p.ID)
+
+					// if both nodes dialed each other simultaneously, exactly one of the connections has to survive
+					if connectedPeer.ConnectionOrigin == p.ConnectionOrigin || !m.keepsSimultaneousDial(p, handshakeMsg) {
+						m.Unlock()
+						return errors.Wrapf(ErrPeerAlreadyConnected, p.ID)
+					}
+
+					m.markDuplicate(connectedPeer)
+					duplicates = append(duplicates, connectedPeer)
+					continue connectedPeersLoop
 				}
 			}
 		}
@@ -125,6 +180,11 @@ func (m *Manager) verifyHandshake(p *peer.Peer, handshakeMsg *handshake.Handshak
 		return errors.Wrapf(ErrUnknownPeerID, p.ID)
 	}
 
+	if err := m.enforceConnectionLimit(p, whitelisted); err != nil {
+		m.Unlock()
+		return err
+	}
+
 	// we mark this peer to be put back into the reconnect pool
 	// if it was whitelisted, which therefore means that we want to keep
 	// a connection to this peer.
@@ -137,7 +197,118 @@ func (m *Manager) verifyHandshake(p *peer.Peer, handshakeMsg *handshake.Handshak
 
 	m.Unlock()
 
-	p.Protocol.FeatureSet = byte(version)
+	// the feature set holds the STING bit (if supported by both) alongside the capabilities,
+	// independent of the negotiated protocol version
+	p.Protocol.FeatureSet = handshakeMsg.FeatureSet(protocol.SupportedFeatureSets, protocol.SupportedCapabilities)
+	p.Protocol.Capabilities = handshakeMsg.SupportedCapabilities(protocol.SupportedCapabilities)
+	p.Protocol.Version = version
+
+	// the handshake of peers which support the node info exchange is completed once their node info was verified
+	if m.exchangesNodeInfo(p) {
+		return m.sendNodeInfo(p)
+	}
+
+	p.Protocol.Handshaked()
+	return nil
+}
+
+// exchangesNodeInfo tells whether the node info is exchanged with the given handshaked peer.
+func (m *Manager) exchangesNodeInfo(p *peer.Peer) bool {
+	return m.Opts.NodeInfo.NetworkID != "" && p.Protocol.Capabilities&handshake.CapabilityNodeInfo != 0
+}
+
+// sends the node info of this node to the given peer.
+func (m *Manager) sendNodeInfo(p *peer.Peer) error {
+	nodeInfo := m.Opts.NodeInfo
+	nodeInfo.Features = protocol.OwnFeatureSets()
+
+	nodeInfoMsg, err := handshake.NewNodeInfoMessage(&nodeInfo)
+	if err != nil {
+		return err
+	}
+	return p.Protocol.Send(nodeInfoMsg)
+}
+
+// verifyNodeInfo checks whether the given peer gossips on the same network and completes its handshake.
+func (m *Manager) verifyNodeInfo(p *peer.Peer, nodeInfo *handshake.NodeInfo) error {
+	if m.shutdown.Load() {
+		return ErrManagerIsShutdown
+	}
+
+	// the node info is only accepted once, after the handshake was verified
+	if !m.exchangesNodeInfo(p) || p.NodeInfo != nil {
+		return errors.Wrapf(ErrUnexpectedNodeInfo, p.ID)
+	}
+
+	if nodeInfo.NetworkID != m.Opts.NodeInfo.NetworkID {
+		return errors.Wrapf(ErrNonMatchingNetworkID, "(%s instead of %s)", nodeInfo.NetworkID, m.Opts.NodeInfo.NetworkID)
+	}
+
+	p.NodeInfo = nodeInfo
 	p.Protocol.Handshaked()
 	return nil
 }
+
+// keepsSimultaneousDial tells whether the connection of the handshaking peer should be kept
+// over the already existing connection in the other direction, if both nodes dialed each other simultaneously.
+// Both nodes come to the same conclusion, as the node with the lower nonce keeps its outbound connection.
+// The nonces are announced in the handshakes and therefore known to both nodes alike, even if one of them is behind a NAT.
+// Peers which don't announce a nonce are compared by their advertised server socket ports and only if they are equal
+// by the IDs derived from the endpoints of the connection, which requires both nodes to see the same addresses.
+func (m *Manager) keepsSimultaneousDial(p *peer.Peer, handshakeMsg *handshake.Handshake) bool {
+	if handshakeMsg.Nonce != 0 && handshakeMsg.Nonce != m.handshakeNonce {
+		return keepsOutbound(m.handshakeNonce < handshakeMsg.Nonce, p)
+	}
+
+	peerServerSocketPort := handshakeMsg.ServerSocketPort
+	if m.serverSocketPort != peerServerSocketPort {
+		return keepsOutbound(m.serverSocketPort < peerServerSocketPort, p)
+	}
+
+	localAddr, ok := p.Conn.LocalAddr().(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+	remoteAddr, ok := p.Conn.RemoteAddr().(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+
+	ownID := peer.NewID(localAddr.IP.String(), m.serverSocketPort)
+	peerID := peer.NewID(remoteAddr.IP.String(), peerServerSocketPort)
+
+	return keepsOutbound(ownID < peerID, p)
+}
+
+// tells whether the connection of the given peer is kept, if the node with the lower ID keeps its outbound connection.
+func keepsOutbound(ownIDLower bool, p *peer.Peer) bool {
+	if ownIDLower {
+		return !p.IsInbound()
+	}
+	return p.IsInbound()
+}
+
+// markDuplicate removes a peer which lost the tie-breaking against another connection to the same peer from the connected pool.
+// The manager lock must be held by the caller, the connection has to be closed with closeDuplicate after the lock was released.
+func (m *Manager) markDuplicate(p *peer.Peer) {
+	m.metrics.droppedDuplicates.Inc()
+	p.Duplicate = true
+	p.MoveBackToReconnectPool = false
+	p.Disconnected = true
+
+	if connectedPeer, exists := m.connected[p.ID]; exists && connectedPeer == p {
+		m.removeConnected(p.ID)
+	}
+}
+
+// closeDuplicate closes the connection of a peer which was marked as duplicate.
+// It must not be called while the manager lock is held.
+func (m *Manager) closeDuplicate(p *peer.Peer) {
+	if p.Conn != nil {
+		_ = p.Conn.Close()
+	}
+
+	if p.Protocol != nil && p.Protocol.IsHandshaked() {
+		m.Events.PeerDisconnected.Trigger(p)
+	}
+}
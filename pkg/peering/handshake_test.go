@@ -0,0 +1,136 @@
+package peering
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/peering/peer"
+	"github.com/gohornet/hornet/pkg/protocol"
+	"github.com/gohornet/hornet/pkg/protocol/handshake"
+)
+
+var (
+	testCooAddress = make([]byte, handshake.ByteEncodedCooAddressBytesLength)
+)
+
+const (
+	testMWM = 14
+)
+
+// returns a free port on the loopback interface.
+func freePort(t *testing.T) int {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+// creates a manager which accepts connections of any peer on the given loopback port and starts listening.
+func newListeningManager(t *testing.T, port int) *Manager {
+	require.NoError(t, protocol.Init(testCooAddress, testMWM, fmt.Sprintf("127.0.0.1:%d", port)))
+
+	m := NewManager(Options{
+		ValidHandshake: handshake.Handshake{MWM: testMWM, ByteEncodedCooAddress: testCooAddress},
+		MaxConnected:   5,
+		AcceptAnyPeer:  true,
+		BindAddress:    fmt.Sprintf("127.0.0.1:%d", port),
+	})
+
+	go func() {
+		assert.NoError(t, m.Listen())
+	}()
+	require.Eventually(t, func() bool { return len(m.ListenAddresses()) != 0 }, 5*time.Second, 10*time.Millisecond)
+
+	return m
+}
+
+// returns the handshaked peers of the given manager.
+func handshakedPeers(m *Manager) []*peer.Peer {
+	var peers []*peer.Peer
+	m.ForAllConnected(func(p *peer.Peer) bool {
+		peers = append(peers, p)
+		return true
+	})
+	return peers
+}
+
+func TestKeepsSimultaneousDial(t *testing.T) {
+	// both nodes use the default port and see different addresses because of a NAT,
+	// the connections don't hold any addresses, so the test fails if they are compared.
+	a := &Manager{serverSocketPort: 15600, handshakeNonce: 1}
+	b := &Manager{serverSocketPort: 15600, handshakeNonce: 2}
+	handshakeOfA := &handshake.Handshake{ServerSocketPort: 15600, Nonce: 1}
+	handshakeOfB := &handshake.Handshake{ServerSocketPort: 15600, Nonce: 2}
+
+	outbound := &peer.Peer{ConnectionOrigin: peer.Outbound}
+	inbound := &peer.Peer{ConnectionOrigin: peer.Inbound}
+
+	// the connection dialed by the node with the lower nonce is kept by both nodes
+	assert.True(t, a.keepsSimultaneousDial(outbound, handshakeOfB))
+	assert.True(t, b.keepsSimultaneousDial(inbound, handshakeOfA))
+
+	// the connection dialed by the other node is dropped by both nodes
+	assert.False(t, a.keepsSimultaneousDial(inbound, handshakeOfB))
+	assert.False(t, b.keepsSimultaneousDial(outbound, handshakeOfA))
+
+	// peers which don't announce a nonce are compared by their advertised server socket ports
+	c := &Manager{serverSocketPort: 15601, handshakeNonce: 3}
+	handshakeOfLegacyB := &handshake.Handshake{ServerSocketPort: 15600}
+	handshakeOfLegacyC := &handshake.Handshake{ServerSocketPort: 15601}
+
+	assert.True(t, b.keepsSimultaneousDial(outbound, handshakeOfLegacyC))
+	assert.True(t, c.keepsSimultaneousDial(inbound, handshakeOfLegacyB))
+	assert.False(t, b.keepsSimultaneousDial(inbound, handshakeOfLegacyC))
+	assert.False(t, c.keepsSimultaneousDial(outbound, handshakeOfLegacyB))
+}
+
+func TestSimultaneousDial(t *testing.T) {
+	for i := 0; i < 5; i++ {
+		portA, portB := freePort(t), freePort(t)
+		a := newListeningManager(t, portA)
+		b := newListeningManager(t, portB)
+
+		// both managers dial each other at the same time
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, a.Add(fmt.Sprintf("127.0.0.1:%d", portB), false, "b"))
+		}()
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, b.Add(fmt.Sprintf("127.0.0.1:%d", portA), false, "a"))
+		}()
+		wg.Wait()
+
+		// exactly one connection survives, and it is the same one on both sides
+		sameConnection := func() bool {
+			peersOfA, peersOfB := handshakedPeers(a), handshakedPeers(b)
+			if len(peersOfA) != 1 || len(peersOfB) != 1 {
+				return false
+			}
+			return peersOfA[0].Conn.LocalAddr().String() == peersOfB[0].Conn.RemoteAddr().String() &&
+				peersOfA[0].Conn.RemoteAddr().String() == peersOfB[0].Conn.LocalAddr().String()
+		}
+		require.Eventually(t, sameConnection, 5*time.Second, 10*time.Millisecond)
+
+		// the dropped connection doesn't take the surviving one down
+		time.Sleep(200 * time.Millisecond)
+		require.True(t, sameConnection())
+
+		a.Shutdown()
+		b.Shutdown()
+	}
+}
+
+func TestNewHandshakeNonce(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		assert.NotZero(t, newHandshakeNonce())
+	}
+}
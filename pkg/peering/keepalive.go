@@ -0,0 +1,78 @@
+package peering
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gohornet/hornet/pkg/peering/peer"
+	"github.com/gohornet/hornet/pkg/protocol/sting"
+)
+
+var (
+	// ErrKeepaliveTimeout is the reason the protocol of a peer is terminated if nothing was received from the peer
+	// within the keepalive timeout.
+	ErrKeepaliveTimeout = errors.New("peer didn't answer within the keepalive timeout")
+)
+
+// Keepalive defines when idle connections to the peers are pinged and when unresponsive peers are dropped,
+// so that half-open connections don't keep occupying the peering slots.
+type Keepalive struct {
+	// The time after which a peer to which nothing was sent gets pinged.
+	Interval time.Duration
+	// The time after which the protocol of a pinged peer from which nothing was received is terminated (0 = never).
+	Timeout time.Duration
+}
+
+// ProtocolTerminatedCaller is the caller of the ProtocolTerminated event.
+func ProtocolTerminatedCaller(handler interface{}, params ...interface{}) {
+	handler.(func(*peer.Peer, error))(params[0].(*peer.Peer), params[1].(error))
+}
+
+// NeedsPing tells whether nothing was sent to the given handshaked peer within the keepalive interval.
+// Only peers supporting STING can be pinged, since the legacy protocol has no message without payload.
+func (m *Manager) NeedsPing(p *peer.Peer) bool {
+	if m.Opts.Keepalive.Interval == 0 || !p.Handshaked() || !p.Protocol.Supports(sting.FeatureSet) {
+		return false
+	}
+	return time.Since(p.Protocol.LastSent()) >= m.Opts.Keepalive.Interval
+}
+
+// KeepaliveNegotiated tells whether the liveness of the given handshaked peer is checked by the keepalive,
+// which is the case if the keepalive timeout is enabled and the peer is pinged when idle.
+// The liveness of other peers has to be checked by their heartbeats.
+func (m *Manager) KeepaliveNegotiated(p *peer.Peer) bool {
+	return m.Opts.Keepalive.Timeout != 0 && m.Opts.Keepalive.Interval != 0 && p.Protocol.Supports(sting.FeatureSet)
+}
+
+// TerminateUnresponsive terminates the protocols of the handshaked peers with a negotiated keepalive
+// from which nothing was received within the keepalive timeout and fires the ProtocolTerminated event for each of them.
+// Autopeered peers are removed to free their slots, the connections to other peers are closed,
+// so that they are moved back into the reconnect pool.
+func (m *Manager) TerminateUnresponsive() {
+	if m.Opts.Keepalive.Timeout == 0 {
+		return
+	}
+
+	var unresponsive []*peer.Peer
+	m.ForAllConnected(func(p *peer.Peer) bool {
+		if m.KeepaliveNegotiated(p) && time.Since(p.Protocol.LastReceived()) >= m.Opts.Keepalive.Timeout {
+			unresponsive = append(unresponsive, p)
+		}
+		return true
+	})
+
+	for _, p := range unresponsive {
+		m.metrics.keepaliveTimeouts.Inc()
+		m.Events.ProtocolTerminated.Trigger(p, fmt.Errorf("%w: nothing received since %v", ErrKeepaliveTimeout, p.Protocol.LastReceived().Truncate(time.Second)))
+
+		if p.Autopeering != nil {
+			if err := m.Remove(p.ID); err != nil {
+				m.Events.Error.Trigger(err)
+			}
+			continue
+		}
+
+		_ = p.Conn.Close()
+	}
+}
@@ -0,0 +1,151 @@
+package peering
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/iotaledger/hive.go/network"
+
+	"github.com/gohornet/hornet/pkg/peering/peer"
+)
+
+var (
+	// ErrInvalidListenAddress is returned when a listen address can't be parsed or its transport isn't supported.
+	ErrInvalidListenAddress = errors.New("invalid listen address")
+)
+
+// ListenAddress is an address on which the node accepts inbound connections.
+type ListenAddress struct {
+	// The transport of the accepted connections.
+	Transport peer.Transport `json:"transport"`
+	// The address the listener is bound to, with the port chosen by the system if port 0 was configured.
+	Address string `json:"address"`
+}
+
+// a listener for inbound connections of peers.
+type listener struct {
+	transport peer.Transport
+	addr      net.Addr
+	// stops the listener, nil for the listeners which are stopped separately.
+	close func()
+}
+
+// registers the given listener, so that its address is listed.
+func (m *Manager) addListener(l *listener) {
+	m.listenersMu.Lock()
+	defer m.listenersMu.Unlock()
+	m.listeners = append(m.listeners, l)
+}
+
+// ListenAddresses returns the addresses on which the node currently accepts inbound connections.
+func (m *Manager) ListenAddresses() []ListenAddress {
+	var addresses []ListenAddress
+	if socket := m.tcpServer.GetSocket(); socket != nil {
+		addresses = append(addresses, ListenAddress{Transport: peer.TransportTCP, Address: socket.Addr().String()})
+	}
+
+	m.listenersMu.Lock()
+	defer m.listenersMu.Unlock()
+	for _, l := range m.listeners {
+		addresses = append(addresses, ListenAddress{Transport: l.transport, Address: l.addr.String()})
+	}
+	return addresses
+}
+
+// listenAdditional binds the additional listeners of the options and starts accepting their connections.
+// All addresses are bound before any listener accepts connections, so that an invalid address doesn't leave a partial set.
+func (m *Manager) listenAdditional() error {
+	type bound struct {
+		transport peer.Transport
+		ln        net.Listener
+	}
+
+	var listeners []bound
+	closeAll := func() {
+		for _, b := range listeners {
+			_ = b.ln.Close()
+		}
+	}
+
+	for _, addr := range m.Opts.Listeners {
+		transport, hostPort := peer.ParseTransportAddress(addr)
+		if _, _, err := net.SplitHostPort(hostPort); err != nil {
+			closeAll()
+			return fmt.Errorf("%w: '%s'", ErrInvalidListenAddress, addr)
+		}
+		if transport == peer.TransportWebSocketSecure && m.Opts.WebSocket.transport() != peer.TransportWebSocketSecure {
+			closeAll()
+			return fmt.Errorf("%w: '%s' requires the TLS certificate and key of the WebSocket listener", ErrInvalidListenAddress, addr)
+		}
+
+		ln, err := net.Listen("tcp", hostPort)
+		if err != nil {
+			closeAll()
+			return err
+		}
+		listeners = append(listeners, bound{transport: transport, ln: ln})
+	}
+
+	for _, b := range listeners {
+		if b.transport.IsWebSocket() {
+			m.serveAdditionalWebSocket(b.ln, b.transport)
+			continue
+		}
+		m.serveAdditionalTCP(b.ln)
+	}
+	return nil
+}
+
+// accepts the TCP connections of the given listener until it is closed.
+func (m *Manager) serveAdditionalTCP(ln net.Listener) {
+	m.addListener(&listener{transport: peer.TransportTCP, addr: ln.Addr(), close: func() { _ = ln.Close() }})
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				if m.shutdown.Load() {
+					return
+				}
+				m.Events.Error.Trigger(err)
+				if netErr, ok := err.(net.Error); ok && netErr.Temporary() {
+					continue
+				}
+				return
+			}
+			go m.acceptInbound(network.NewManagedConnection(conn), peer.TransportTCP)
+		}
+	}()
+}
+
+// accepts the WebSocket connections of the given listener until it is stopped.
+func (m *Manager) serveAdditionalWebSocket(ln net.Listener, transport peer.Transport) {
+	server := &http.Server{Handler: m.webSocketHandler(transport)}
+	m.addListener(&listener{transport: transport, addr: ln.Addr(), close: func() {
+		ctx, cancel := context.WithTimeout(context.Background(), webSocketShutdownTimeout)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	}})
+
+	go func() {
+		if err := m.serveWebSocket(server, ln, transport); err != nil && err != http.ErrServerClosed {
+			m.Events.Error.Trigger(err)
+		}
+	}()
+}
+
+// stops the listeners and forgets their addresses.
+func (m *Manager) shutdownListeners() {
+	m.listenersMu.Lock()
+	defer m.listenersMu.Unlock()
+
+	for _, l := range m.listeners {
+		if l.close != nil {
+			l.close()
+		}
+	}
+	m.listeners = nil
+}
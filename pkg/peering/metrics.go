@@ -0,0 +1,186 @@
+package peering
+
+import (
+	"errors"
+
+	"go.uber.org/atomic"
+
+	"github.com/gohornet/hornet/pkg/protocol"
+)
+
+// the counters of the peering layer, incremented by the manager.
+type metrics struct {
+	// handshakes rejected per reason
+	rejectedPeeringSlotsFilled       atomic.Uint64
+	rejectedAutopeeringSlotsFilled   atomic.Uint64
+	rejectedNonMatchingMWM           atomic.Uint64
+	rejectedNonMatchingCooAddr       atomic.Uint64
+	rejectedNonMatchingNetworkID     atomic.Uint64
+	rejectedNonMatchingSrvSocketPort atomic.Uint64
+	rejectedUnknownPeerID            atomic.Uint64
+	rejectedAlreadyConnected         atomic.Uint64
+	rejectedBanned                   atomic.Uint64
+	rejectedDiversityLimitReached    atomic.Uint64
+	droppedDuplicates                atomic.Uint64
+	gatedConnections                 atomic.Uint64
+
+	// protocols terminated per reason
+	deadlinesExceeded atomic.Uint64
+	keepaliveTimeouts atomic.Uint64
+	invalidMessages   atomic.Uint64
+
+	// connection failures
+	connectFailures  atomic.Uint64
+	connectionErrors atomic.Uint64
+
+	// resources
+	pendingInboundLimitReached   atomic.Uint64
+	connectionsPerIPLimitReached atomic.Uint64
+	sendQueueMemoryLimitReached  atomic.Uint64
+	totalSendQueueMemoryReached  atomic.Uint64
+	sendQueueDrops               atomic.Uint64
+	sendQueueDrainTimeouts       atomic.Uint64
+	inboundLimitReached          atomic.Uint64
+	outboundLimitReached         atomic.Uint64
+	prunedConnections            atomic.Uint64
+}
+
+// Metrics is a snapshot of the counters of the peering layer.
+type Metrics struct {
+	// Handshakes rejected because all peering slots were filled.
+	RejectedPeeringSlotsFilled uint64 `json:"rejectedPeeringSlotsFilled"`
+	// Handshakes of autopeered peers rejected because all autopeering slots were filled.
+	RejectedAutopeeringSlotsFilled uint64 `json:"rejectedAutopeeringSlotsFilled"`
+	// Handshakes rejected because of a different MWM.
+	RejectedNonMatchingMWM uint64 `json:"rejectedNonMatchingMWM"`
+	// Handshakes rejected because of a different coordinator address.
+	RejectedNonMatchingCooAddr uint64 `json:"rejectedNonMatchingCooAddr"`
+	// Handshakes rejected because the node info of the peer announced a different network ID.
+	RejectedNonMatchingNetworkID uint64 `json:"rejectedNonMatchingNetworkID"`
+	// Handshakes rejected because the advertised server socket port didn't match.
+	RejectedNonMatchingSrvSocketPort uint64 `json:"rejectedNonMatchingSrvSocketPort"`
+	// Handshakes rejected because the peer is not known.
+	RejectedUnknownPeerID uint64 `json:"rejectedUnknownPeerID"`
+	// Handshakes rejected because the peer was already connected.
+	RejectedAlreadyConnected uint64 `json:"rejectedAlreadyConnected"`
+	// Handshakes rejected because the peer is banned.
+	RejectedBanned uint64 `json:"rejectedBanned"`
+	// Handshakes of autopeered peers rejected because too many autopeered peers of the same subnet, country or ASN were connected.
+	RejectedDiversityLimitReached uint64 `json:"rejectedDiversityLimitReached"`
+	// Connections dropped because they lost the tie-breaking against another connection to the same peer.
+	DroppedDuplicates uint64 `json:"droppedDuplicates"`
+	// Connections refused or closed because of the allow and deny lists.
+	GatedConnections uint64 `json:"gatedConnections"`
+	// Protocols terminated because the peer repeatedly exceeded the stream deadlines.
+	DeadlinesExceeded uint64 `json:"deadlinesExceeded"`
+	// Protocols terminated because nothing was received within the keepalive timeout.
+	KeepaliveTimeouts uint64 `json:"keepaliveTimeouts"`
+	// Protocols terminated because of an invalid handshake or message.
+	InvalidMessages uint64 `json:"invalidMessages"`
+	// Failed attempts to connect to a peer.
+	ConnectFailures uint64 `json:"connectFailures"`
+	// Errors of established connections, e.g. connections reset by the peer.
+	ConnectionErrors uint64 `json:"connectionErrors"`
+	// Inbound connections refused because too many connections were handshaking.
+	PendingInboundLimitReached uint64 `json:"pendingInboundLimitReached"`
+	// Connections refused because too many connections with the same IP address existed.
+	ConnectionsPerIPLimitReached uint64 `json:"connectionsPerIPLimitReached"`
+	// Messages not enqueued because the send queue of a peer held too much memory.
+	SendQueueMemoryLimitReached uint64 `json:"sendQueueMemoryLimitReached"`
+	// Messages not enqueued because the send queues of all peers together held too much memory.
+	TotalSendQueueMemoryLimitReached uint64 `json:"totalSendQueueMemoryLimitReached"`
+	// Messages dropped because the send queue of a peer was full.
+	SendQueueDrops uint64 `json:"sendQueueDrops"`
+	// Removed peers whose send queues couldn't be sent before the drain timeout.
+	SendQueueDrainTimeouts uint64 `json:"sendQueueDrainTimeouts"`
+	// Handshakes of unknown inbound peers rejected because the inbound connection limit was reached.
+	InboundLimitReached uint64 `json:"inboundLimitReached"`
+	// Handshakes of unknown outbound peers rejected because the outbound connection limit was reached.
+	OutboundLimitReached uint64 `json:"outboundLimitReached"`
+	// Connections to unknown peers closed to make room for known peers.
+	PrunedConnections uint64 `json:"prunedConnections"`
+}
+
+// Metrics returns a snapshot of the counters of the peering layer.
+func (m *Manager) Metrics() *Metrics {
+	return &Metrics{
+		RejectedPeeringSlotsFilled:       m.metrics.rejectedPeeringSlotsFilled.Load(),
+		RejectedAutopeeringSlotsFilled:   m.metrics.rejectedAutopeeringSlotsFilled.Load(),
+		RejectedNonMatchingMWM:           m.metrics.rejectedNonMatchingMWM.Load(),
+		RejectedNonMatchingCooAddr:       m.metrics.rejectedNonMatchingCooAddr.Load(),
+		RejectedNonMatchingNetworkID:     m.metrics.rejectedNonMatchingNetworkID.Load(),
+		RejectedNonMatchingSrvSocketPort: m.metrics.rejectedNonMatchingSrvSocketPort.Load(),
+		RejectedUnknownPeerID:            m.metrics.rejectedUnknownPeerID.Load(),
+		RejectedAlreadyConnected:         m.metrics.rejectedAlreadyConnected.Load(),
+		RejectedBanned:                   m.metrics.rejectedBanned.Load(),
+		RejectedDiversityLimitReached:    m.metrics.rejectedDiversityLimitReached.Load(),
+		DroppedDuplicates:                m.metrics.droppedDuplicates.Load(),
+		GatedConnections:                 m.metrics.gatedConnections.Load(),
+		DeadlinesExceeded:                m.metrics.deadlinesExceeded.Load(),
+		KeepaliveTimeouts:                m.metrics.keepaliveTimeouts.Load(),
+		InvalidMessages:                  m.metrics.invalidMessages.Load(),
+		ConnectFailures:                  m.metrics.connectFailures.Load(),
+		ConnectionErrors:                 m.metrics.connectionErrors.Load(),
+		PendingInboundLimitReached:       m.metrics.pendingInboundLimitReached.Load(),
+		ConnectionsPerIPLimitReached:     m.metrics.connectionsPerIPLimitReached.Load(),
+		SendQueueMemoryLimitReached:      m.metrics.sendQueueMemoryLimitReached.Load(),
+		TotalSendQueueMemoryLimitReached: m.metrics.totalSendQueueMemoryReached.Load(),
+		SendQueueDrops:                   m.metrics.sendQueueDrops.Load(),
+		SendQueueDrainTimeouts:           m.metrics.sendQueueDrainTimeouts.Load(),
+		InboundLimitReached:              m.metrics.inboundLimitReached.Load(),
+		OutboundLimitReached:             m.metrics.outboundLimitReached.Load(),
+		PrunedConnections:                m.metrics.prunedConnections.Load(),
+	}
+}
+
+// counts the error which terminated the protocol of a peer by its reason.
+func (m *Manager) countProtocolError(err error) {
+	switch {
+	case errors.Is(err, ErrPeeringSlotsFilled):
+		m.metrics.rejectedPeeringSlotsFilled.Inc()
+	case errors.Is(err, ErrAutopeeringSlotsFilled):
+		m.metrics.rejectedAutopeeringSlotsFilled.Inc()
+	case errors.Is(err, ErrNonMatchingMWM):
+		m.metrics.rejectedNonMatchingMWM.Inc()
+	case errors.Is(err, ErrNonMatchingCooAddr):
+		m.metrics.rejectedNonMatchingCooAddr.Inc()
+	case errors.Is(err, ErrNonMatchingNetworkID):
+		m.metrics.rejectedNonMatchingNetworkID.Inc()
+	case errors.Is(err, ErrNonMatchingSrvSocketPort):
+		m.metrics.rejectedNonMatchingSrvSocketPort.Inc()
+	case errors.Is(err, ErrUnknownPeerID):
+		m.metrics.rejectedUnknownPeerID.Inc()
+	case errors.Is(err, ErrPeerAlreadyConnected):
+		m.metrics.rejectedAlreadyConnected.Inc()
+	case errors.Is(err, ErrPeerBanned):
+		m.metrics.rejectedBanned.Inc()
+	case errors.Is(err, ErrDiversityLimitReached):
+		m.metrics.rejectedDiversityLimitReached.Inc()
+	case errors.Is(err, ErrConnectionGated):
+		m.metrics.gatedConnections.Inc()
+	case errors.Is(err, protocol.ErrStreamDeadlinesExceeded):
+		m.metrics.deadlinesExceeded.Inc()
+	case errors.Is(err, ErrResourceLimitReached):
+		// already counted by the resource whose limit was reached
+	default:
+		m.metrics.invalidMessages.Inc()
+	}
+}
+
+// counts the refused connection or message by the resource whose limit was reached.
+func (m *Manager) countResourceLimitReached(resource Resource) {
+	switch resource {
+	case ResourcePendingInbound:
+		m.metrics.pendingInboundLimitReached.Inc()
+	case ResourceConnectionsPerIP:
+		m.metrics.connectionsPerIPLimitReached.Inc()
+	case ResourceSendQueueMemory:
+		m.metrics.sendQueueMemoryLimitReached.Inc()
+	case ResourceTotalSendQueueMemory:
+		m.metrics.totalSendQueueMemoryReached.Inc()
+	case ResourceInboundConnections:
+		m.metrics.inboundLimitReached.Inc()
+	case ResourceOutboundConnections:
+		m.metrics.outboundLimitReached.Inc()
+	}
+}
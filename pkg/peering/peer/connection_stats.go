@@ -0,0 +1,23 @@
+package peer
+
+// ConnectionStats holds the uptime, the reconnects and the latency of a peer since the node was started.
+type ConnectionStats struct {
+	// The unix timestamp at which the current connection was established, 0 if the peer isn't connected.
+	ConnectedSince int64 `json:"connectedSince"`
+	// The unix timestamp at which the last connection was closed, 0 if no connection was closed yet.
+	LastDisconnected int64 `json:"lastDisconnected"`
+	// The time the peer was connected over all its connections in seconds.
+	TotalUptimeSeconds int64 `json:"totalUptimeSeconds"`
+	// The amount of established connections.
+	Connections int `json:"connections"`
+	// The amount of connections established after the first one.
+	Reconnects int `json:"reconnects"`
+	// The smoothed round trip time of the pings to the peer in milliseconds (0 if unknown).
+	LatencyMs float64 `json:"latencyMs"`
+	// The round trip time of the last ping to the peer in milliseconds (0 if unknown).
+	LastLatencyMs float64 `json:"lastLatencyMs"`
+}
+
+func ConnectionStatsCaller(handler interface{}, params ...interface{}) {
+	handler.(func(*Peer, *ConnectionStats))(params[0].(*Peer), params[1].(*ConnectionStats))
+}
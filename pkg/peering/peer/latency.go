@@ -0,0 +1,41 @@
+package peer
+
+import (
+	"time"
+)
+
+const (
+	// the weight of a new latency sample in the smoothed latency.
+	latencySmoothingFactor = 0.2
+)
+
+func LatencyCaller(handler interface{}, params ...interface{}) {
+	handler.(func(*Peer, time.Duration))(params[0].(*Peer), params[1].(time.Duration))
+}
+
+// RecordLatency adds the given round trip time of a ping to the smoothed latency of the peer.
+// Returns the new smoothed latency.
+func (p *Peer) RecordLatency(rtt time.Duration) time.Duration {
+	p.lastLatency.Store(int64(rtt))
+
+	for {
+		current := p.latency.Load()
+		smoothed := int64(rtt)
+		if current != 0 {
+			smoothed = int64((1-latencySmoothingFactor)*float64(current) + latencySmoothingFactor*float64(rtt))
+		}
+		if p.latency.CAS(current, smoothed) {
+			return time.Duration(smoothed)
+		}
+	}
+}
+
+// Latency returns the smoothed round trip time of the pings to the peer (0 if the peer was not pinged yet).
+func (p *Peer) Latency() time.Duration {
+	return time.Duration(p.latency.Load())
+}
+
+// LastLatency returns the round trip time of the last ping to the peer (0 if the peer was not pinged yet).
+func (p *Peer) LastLatency() time.Duration {
+	return time.Duration(p.lastLatency.Load())
+}
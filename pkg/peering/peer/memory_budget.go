@@ -0,0 +1,62 @@
+package peer
+
+import (
+	"errors"
+
+	"go.uber.org/atomic"
+)
+
+var (
+	// ErrSendQueueMemoryBudgetExhausted is returned if the send queues of all peers together hold as many bytes as allowed.
+	ErrSendQueueMemoryBudgetExhausted = errors.New("send queue memory budget exhausted")
+)
+
+// MemoryBudget limits the bytes held in the send queues of all peers together,
+// so that many slow peers can't exhaust the memory of the node although each of them stays within its own limit.
+type MemoryBudget struct {
+	limit int64
+	used  atomic.Int64
+	// whether messages are currently dropped because the budget is exhausted.
+	exhausted atomic.Bool
+}
+
+// NewMemoryBudget creates a new memory budget of the given amount of bytes.
+func NewMemoryBudget(limit int64) *MemoryBudget {
+	return &MemoryBudget{limit: limit}
+}
+
+// Limit returns the amount of bytes of the budget.
+func (b *MemoryBudget) Limit() int64 {
+	return b.limit
+}
+
+// Used returns the amount of bytes currently held in the send queues.
+func (b *MemoryBudget) Used() int64 {
+	if b == nil {
+		return 0
+	}
+	return b.used.Load()
+}
+
+// reserves the given amount of bytes, a nil budget is unlimited.
+// the second result tells whether the budget just became exhausted.
+func (b *MemoryBudget) reserve(size int64) (bool, bool) {
+	if b == nil {
+		return true, false
+	}
+
+	if b.used.Add(size) > b.limit {
+		b.used.Sub(size)
+		return false, b.exhausted.CAS(false, true)
+	}
+	b.exhausted.Store(false)
+	return true, false
+}
+
+// releases the given amount of bytes.
+func (b *MemoryBudget) release(size int64) {
+	if b == nil {
+		return
+	}
+	b.used.Sub(size)
+}
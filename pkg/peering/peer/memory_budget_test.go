@@ -0,0 +1,53 @@
+package peer_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/iotaledger/hive.go/events"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gohornet/hornet/pkg/peering/peer"
+	"github.com/gohornet/hornet/pkg/protocol/sting"
+)
+
+func TestSendQueueMemoryBudget(t *testing.T) {
+	msg, err := sting.NewMilestoneRequestMessage(1)
+	assert.NoError(t, err)
+
+	budget := peer.NewMemoryBudget(int64(2 * len(msg)))
+
+	newPeer := func() *peer.Peer {
+		p := peer.NewInboundPeer(&net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 15600})
+		p.SendQueueMemoryBudget = budget
+		return p
+	}
+	p1, p2 := newPeer(), newPeer()
+
+	var exhausted int
+	p2.Events.SendQueueMemoryBudgetExhausted.Attach(events.NewClosure(func() {
+		exhausted++
+	}))
+
+	p1.EnqueueForSending(msg)
+	p2.EnqueueForSending(msg)
+	assert.Equal(t, int64(2*len(msg)), budget.Used())
+
+	// the budget is shared by all peers
+	p2.EnqueueForSending(msg)
+	p2.EnqueueForSending(msg)
+	assert.Equal(t, 1, exhausted)
+	assert.Equal(t, uint32(2), p2.Metrics.DroppedPackets.Load())
+	assert.True(t, errors.Is(p2.EnqueueForSendingContext(context.Background(), msg), peer.ErrSendQueueMemoryBudgetExhausted))
+
+	p1.DequeuedForSending(<-p1.SendQueue)
+	assert.Equal(t, int64(len(msg)), budget.Used())
+
+	// the memory of a disconnected peer is returned to the budget
+	p2.ReleaseSendQueueMemoryBudget()
+	assert.Zero(t, budget.Used())
+	p2.DequeuedForSending(<-p2.SendQueue)
+	assert.Zero(t, budget.Used())
+}
@@ -1,9 +1,12 @@
 package peer
 
 import (
+	"fmt"
 	"net"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"go.uber.org/atomic"
@@ -14,9 +17,10 @@ import (
 	"github.com/iotaledger/hive.go/iputils"
 	"github.com/iotaledger/hive.go/network"
 
-	"github.com/gohornet/hornet/pkg/metrics"
+	"github.com/gohornet/hornet/pkg/model/hornet"
 	"github.com/gohornet/hornet/pkg/model/milestone"
 	"github.com/gohornet/hornet/pkg/protocol"
+	"github.com/gohornet/hornet/pkg/protocol/handshake"
 	"github.com/gohornet/hornet/pkg/protocol/sting"
 	"github.com/gohornet/hornet/pkg/utils"
 )
@@ -34,9 +38,14 @@ const (
 const (
 	// SendQueueSize defines the size of the send queue of every created peer.
 	SendQueueSize = 1500
+	// PrioritySendQueueSize defines the size of the priority send queue of every created peer.
+	PrioritySendQueueSize = 100
 	// CheckStaledAutopeerInterval is the interval autopeered neighbors
 	// are checked whether they are staled.
 	CheckStaledAutopeerInterval = 60 * time.Second
+	// IsSyncedThreshold is the amount of milestones a peer's solid milestone index
+	// may lag behind the latest milestone index to still be considered synced.
+	IsSyncedThreshold = 2
 )
 
 func Caller(handler interface{}, params ...interface{}) {
@@ -59,12 +68,18 @@ func NewInboundPeer(remoteAddr net.Addr) *Peer {
 
 	// InitAddress and ID are set after handshaking
 	return &Peer{
-		PrimaryAddress:   primaryAddr,
-		Addresses:        addresses,
-		ConnectionOrigin: Inbound,
-		SendQueue:        make(chan []byte, SendQueueSize),
+		PrimaryAddress:    primaryAddr,
+		Addresses:         addresses,
+		ConnectionOrigin:  Inbound,
+		SendQueue:         make(chan []byte, SendQueueSize),
+		PrioritySendQueue: make(chan []byte, PrioritySendQueueSize),
 		Events: Events{
-			HeartbeatUpdated: events.NewEvent(sting.HeartbeatCaller),
+			HeartbeatUpdated:               events.NewEvent(sting.HeartbeatCaller),
+			SendQueueMemoryExhausted:       events.NewEvent(events.CallbackCaller),
+			SendQueueMemoryBudgetExhausted: events.NewEvent(events.CallbackCaller),
+			SendQueueMessageDropped:        events.NewEvent(events.CallbackCaller),
+			SendQueueHighWatermarkReached:  events.NewEvent(events.CallbackCaller),
+			SendQueueLowWatermarkReached:   events.NewEvent(events.CallbackCaller),
 		},
 	}
 }
@@ -79,8 +94,14 @@ func NewOutboundPeer(originAddr *iputils.OriginAddress, primaryAddr net.IP, port
 		MoveBackToReconnectPool: true,
 		ConnectionOrigin:        Outbound,
 		SendQueue:               make(chan []byte, SendQueueSize),
+		PrioritySendQueue:       make(chan []byte, PrioritySendQueueSize),
 		Events: Events{
-			HeartbeatUpdated: events.NewEvent(sting.HeartbeatCaller),
+			HeartbeatUpdated:               events.NewEvent(sting.HeartbeatCaller),
+			SendQueueMemoryExhausted:       events.NewEvent(events.CallbackCaller),
+			SendQueueMemoryBudgetExhausted: events.NewEvent(events.CallbackCaller),
+			SendQueueMessageDropped:        events.NewEvent(events.CallbackCaller),
+			SendQueueHighWatermarkReached:  events.NewEvent(events.CallbackCaller),
+			SendQueueLowWatermarkReached:   events.NewEvent(events.CallbackCaller),
 		},
 	}
 }
@@ -88,6 +109,16 @@ func NewOutboundPeer(originAddr *iputils.OriginAddress, primaryAddr net.IP, port
 // Events happening on the peer instance.
 type Events struct {
 	HeartbeatUpdated *events.Event
+	// Fired when messages start to get dropped because the send queue memory limit was reached.
+	SendQueueMemoryExhausted *events.Event
+	// Fired when messages start to get dropped because the send queue memory budget of all peers was exhausted.
+	SendQueueMemoryBudgetExhausted *events.Event
+	// Fired for every message which was dropped instead of being sent to the peer.
+	SendQueueMessageDropped *events.Event
+	// Fired when the fill level of the send queue reached the high watermark.
+	SendQueueHighWatermarkReached *events.Event
+	// Fired when the fill level of the send queue fell to the low watermark after it reached the high watermark.
+	SendQueueLowWatermarkReached *events.Event
 }
 
 // Peer is a node to which the node is connected to.
@@ -108,20 +139,68 @@ type Peer struct {
 	Metrics Metrics
 	// Whether the connection for this peer was handled inbound or was created outbound.
 	ConnectionOrigin ConnectionOrigin
+	// The transport over which the connection to the peer is established (empty = TCP).
+	Transport Transport
 	// Whether to place this peer back into the reconnect pool when the connection is closed.
 	MoveBackToReconnectPool bool
 	// Whether the peer is a duplicate, as it is already connected.
 	Duplicate bool
+	// The node info the peer sent after the handshake, nil if the peer doesn't support the node info exchange.
+	NodeInfo *handshake.NodeInfo
 	// The peer's latest heartbeat message.
 	LatestHeartbeat *sting.Heartbeat
 	// Time the last heartbeat was received.
 	HeartbeatReceivedTime time.Time
 	// Time the last heartbeat was sent.
 	HeartbeatSentTime time.Time
+	// The peer's latest capabilities record, nil if the peer didn't advertise its capabilities.
+	LatestCapabilities *sting.Capabilities
+	// The filter of the transactions the peer recently received, nil if the peer didn't send one.
+	KnownTransactions *sting.KnownTransactionsFilter
+	// The time it took to set up the outbound connection to the peer (0 for inbound connections).
+	ConnectLatency time.Duration
 	// Holds the autopeering info if this peer was added via autopeering.
 	Autopeering *peer.Peer
 	// A channel which contains messages to be sent to the given peer.
 	SendQueue chan []byte
+	// A channel which contains messages to be sent to the given peer before the ones in the send queue
+	// (e.g. heartbeats and milestones), so they don't get stuck behind regular messages.
+	PrioritySendQueue chan []byte
+	// The maximum amount of bytes held in the send queue. 0 disables the limit.
+	SendQueueMemoryLimit int64
+	// The budget of the bytes held in the send queues of all peers together. nil disables the budget.
+	SendQueueMemoryBudget *MemoryBudget
+	// Defines which message is dropped if the send queue is full.
+	SendQueueOverflowPolicy SendQueueOverflowPolicy
+	// The maximum time to wait for room in the send queue if the SendQueueBlock policy is used.
+	SendQueueBlockTimeout time.Duration
+	// The amount of messages which wait for room in the send queue if the SendQueueBlock policy is used.
+	blockedSends atomic.Int32
+	// The amount of messages in the send queue at which the send queue is considered congested. 0 disables the watermarks.
+	SendQueueHighWatermark int
+	// The amount of messages in the send queue at which a congested send queue is considered relieved.
+	SendQueueLowWatermark int
+	// The amount of bytes currently held in the send queue.
+	sendQueueMemory atomic.Int64
+	// Whether messages are currently dropped because of the send queue memory limit.
+	sendQueueMemoryExhausted atomic.Bool
+	// The amount of bytes of the send queue reserved from the memory budget of all peers.
+	budgetUsed int64
+	// Whether the memory of the send queue was returned to the budget, since the peer was disconnected.
+	budgetReleased bool
+	budgetMu       sync.Mutex
+	// Whether the send queue reached the high watermark and didn't fall to the low watermark since.
+	sendQueueCongested atomic.Bool
+	// Whether the peer is deprioritized because of its low score.
+	deprioritized atomic.Bool
+	// The smoothed round trip time of the pings to the peer in nanoseconds (0 = unknown).
+	latency atomic.Int64
+	// The round trip time of the last ping to the peer in nanoseconds (0 = unknown).
+	lastLatency atomic.Int64
+	// The reputation of the peer derived from the statistics collected across restarts.
+	reputation atomic.Value
+	// Whether no transactions are gossiped with the peer, so that the connection is only kept (relay-only).
+	gossipDisabled atomic.Bool
 	// Whether this peer is marked as disconnected.
 	// Used to suppress errors stemming from connection closure.
 	Disconnected bool
@@ -133,6 +212,23 @@ type Peer struct {
 	staledAutopeerCheckLastDroppedPackets uint32
 }
 
+// Alias returns the alias of the peer, or an empty string if it has none.
+func (p *Peer) Alias() string {
+	if p.InitAddress == nil {
+		return ""
+	}
+	return p.InitAddress.Alias
+}
+
+// Name returns the alias of the peer followed by its ID, or only the ID if the peer has no alias,
+// so that operators can tell the peers apart in logs.
+func (p *Peer) Name() string {
+	if alias := p.Alias(); alias != "" && alias != p.ID {
+		return fmt.Sprintf("%s (%s)", alias, p.ID)
+	}
+	return p.ID
+}
+
 // IsInbound tells whether the peer's connection was inbound.
 func (p *Peer) IsInbound() bool {
 	return p.ConnectionOrigin == Inbound
@@ -175,16 +271,47 @@ func (p *Peer) CheckStaledAutopeer(maxPercentage int) (bool, float32) {
 }
 
 // EnqueueForSending enqueues the given data to be sent to the peer.
-// If it can't because the send queue is over capacity, the message gets dropped.
+// If it can't because the send queue is over capacity, a message gets dropped according to the send queue overflow policy.
+// If the send queue memory limit is reached, the message gets dropped as well.
 func (p *Peer) EnqueueForSending(data []byte) {
+	size := int64(len(data))
+	if err := p.reserveSendQueueMemory(size); err != nil {
+		p.messageDropped()
+		return
+	}
+
 	select {
 	case p.SendQueue <- data:
+		p.sendQueueMemoryExhausted.Store(false)
+		p.checkHighWatermark()
+	default:
+		if p.enqueueOnOverflow(data) {
+			p.sendQueueMemoryExhausted.Store(false)
+			p.checkHighWatermark()
+			return
+		}
+
+		p.releaseSendQueueMemory(size)
+		p.messageDropped()
+	}
+}
+
+// EnqueueForPrioritySending enqueues the given data to be sent to the peer before the messages in the send queue.
+// If the priority send queue is over capacity, the message gets dropped.
+func (p *Peer) EnqueueForPrioritySending(data []byte) {
+	select {
+	case p.PrioritySendQueue <- data:
 	default:
-		metrics.SharedServerMetrics.DroppedMessages.Inc()
-		p.Metrics.DroppedPackets.Inc()
+		p.messageDropped()
 	}
 }
 
+// DequeuedForSending frees the send queue memory of the given data, which was taken out of the send queue.
+func (p *Peer) DequeuedForSending(data []byte) {
+	p.releaseSendQueueMemory(int64(len(data)))
+	p.checkLowWatermark()
+}
+
 // Info returns a snapshot of the peer in time of calling Info().
 func (p *Peer) Info() *Info {
 	info := &Info{
@@ -208,10 +335,15 @@ func (p *Peer) Info() *Info {
 		NumberOfSentMilestoneReq:       p.Metrics.SentMilestoneRequests.Load(),
 		NumberOfSentHeartbeats:         p.Metrics.SentHeartbeats.Load(),
 		NumberOfDroppedSentPackets:     p.Metrics.DroppedPackets.Load(),
-		ConnectionType:                 "tcp",
+		NumberOfInvalidMessages:        p.Metrics.InvalidMessages.Load(),
+		NumberOfDuplicateTransactions:  p.Metrics.DuplicateTransactions.Load(),
+		ConnectionType:                 p.Transport.String(),
+		Relation:                       p.Relation().String(),
+		Addresses:                      IPStrings(p.Addresses),
 		Connected:                      false,
 		Autopeered:                     false,
 		AutopeeringID:                  "",
+		NodeInfo:                       p.NodeInfo,
 	}
 	if p.Autopeering != nil {
 		info.Autopeered = true
@@ -220,6 +352,42 @@ func (p *Peer) Info() *Info {
 	return info
 }
 
+// Relation returns the relation to the peer.
+// Peers without a protocol, i.e. the ones in the reconnect pool, are either autopeered or static.
+func (p *Peer) Relation() protocol.Relation {
+	switch {
+	case p.Protocol != nil:
+		return p.Protocol.Relation()
+	case p.Autopeering != nil:
+		return protocol.RelationAutopeered
+	case p.InitAddress == nil:
+		return protocol.RelationUnknown
+	default:
+		return protocol.RelationStatic
+	}
+}
+
+// IPStrings returns the given IP addresses as sorted strings.
+func IPStrings(addresses *iputils.IPAddresses) []string {
+	if addresses == nil {
+		return nil
+	}
+
+	ips := make([]string, 0, len(addresses.IPs))
+	for ip := range addresses.IPs {
+		ips = append(ips, ip.String())
+	}
+	sort.Strings(ips)
+	return ips
+}
+
+// KnowsTransaction tells whether the peer, given its latest known transactions filter, recently received the given transaction.
+// Returns false if no filter was received yet.
+func (p *Peer) KnowsTransaction(hash hornet.Hash) bool {
+	filter := p.KnownTransactions
+	return filter != nil && filter.Contains(hash)
+}
+
 // HasDataFor tells whether the peer given the latest heartbeat message, has the cone data for the given milestone.
 // Returns false if no heartbeat message was received yet.
 func (p *Peer) HasDataFor(index milestone.Index) bool {
@@ -238,11 +406,38 @@ func (p *Peer) CouldHaveDataFor(index milestone.Index) bool {
 	return p.LatestHeartbeat.PrunedMilestoneIndex < index && p.LatestHeartbeat.LatestMilestoneIndex >= index
 }
 
+// IsSynced tells whether the peer, given the latest heartbeat message, is synced.
+// The latest milestone index of the node is used if it is higher than the one of the peer.
+// Returns false if no heartbeat message was received yet.
+func (p *Peer) IsSynced(latestMilestoneIndex milestone.Index) bool {
+	if p.LatestHeartbeat == nil {
+		return false
+	}
+
+	latestIndex := p.LatestHeartbeat.LatestMilestoneIndex
+	if latestIndex < latestMilestoneIndex {
+		latestIndex = latestMilestoneIndex
+	}
+
+	return p.LatestHeartbeat.SolidMilestoneIndex >= (latestIndex - IsSyncedThreshold)
+}
+
 // Handshaked tells whether the peer was handshaked.
 func (p *Peer) Handshaked() bool {
 	return p.Protocol != nil && p.Protocol.IsHandshaked()
 }
 
+// GossipEnabled tells whether transactions and requests are gossiped with the peer.
+// Peers without gossip only exchange heartbeats, so that the connection is kept alive.
+func (p *Peer) GossipEnabled() bool {
+	return !p.gossipDisabled.Load()
+}
+
+// SetGossipEnabled sets whether transactions and requests are gossiped with the peer.
+func (p *Peer) SetGossipEnabled(enabled bool) {
+	p.gossipDisabled.Store(!enabled)
+}
+
 // NewID returns a peer ID which consists of the given IP address and server socket port number.
 func NewID(ip string, port uint16) string {
 	// prevent double square brackets
@@ -279,32 +474,45 @@ type Metrics struct {
 	SentHeartbeats atomic.Uint32
 	// The number of dropped packets.
 	DroppedPackets atomic.Uint32
+	// The number of received invalid messages.
+	InvalidMessages atomic.Uint32
+	// The number of received transactions which were already sent by the same peer.
+	DuplicateTransactions atomic.Uint32
 }
 
 // Info acts as a static snapshot of information about a peer.
 type Info struct {
-	Peer                           *Peer  `json:"-"`
-	Address                        string `json:"address"`
-	Port                           uint16 `json:"port,omitempty"`
-	Domain                         string `json:"domain,omitempty"`
-	DomainWithPort                 string `json:"-"`
-	Alias                          string `json:"alias,omitempty"`
-	PreferIPv6                     bool   `json:"-"`
-	NumberOfAllTransactions        uint32 `json:"numberOfAllTransactions"`
-	NumberOfNewTransactions        uint32 `json:"numberOfNewTransactions"`
-	NumberOfKnownTransactions      uint32 `json:"numberOfKnownTransactions"`
-	NumberOfStaleTransactions      uint32 `json:"numberOfStaleTransactions"`
-	NumberOfReceivedTransactionReq uint32 `json:"numberOfReceivedTransactionReq"`
-	NumberOfReceivedMilestoneReq   uint32 `json:"numberOfReceivedMilestoneReq"`
-	NumberOfReceivedHeartbeats     uint32 `json:"numberOfReceivedHeartbeats"`
-	NumberOfSentPackets            uint32 `json:"numberOfSentPackets"`
-	NumberOfSentTransactions       uint32 `json:"numberOfSentTransactions"`
-	NumberOfSentTransactionsReq    uint32 `json:"numberOfSentTransactionsReq"`
-	NumberOfSentMilestoneReq       uint32 `json:"numberOfSentMilestoneReq"`
-	NumberOfSentHeartbeats         uint32 `json:"numberOfSentHeartbeats"`
-	NumberOfDroppedSentPackets     uint32 `json:"numberOfDroppedSentPackets"`
-	ConnectionType                 string `json:"connectionType"`
-	Connected                      bool   `json:"connected"`
-	Autopeered                     bool   `json:"autopeered"`
-	AutopeeringID                  string `json:"autopeeringId,omitempty"`
+	Peer                           *Peer               `json:"-"`
+	Address                        string              `json:"address"`
+	Port                           uint16              `json:"port,omitempty"`
+	Domain                         string              `json:"domain,omitempty"`
+	DomainWithPort                 string              `json:"-"`
+	Alias                          string              `json:"alias,omitempty"`
+	PreferIPv6                     bool                `json:"-"`
+	NumberOfAllTransactions        uint32              `json:"numberOfAllTransactions"`
+	NumberOfNewTransactions        uint32              `json:"numberOfNewTransactions"`
+	NumberOfKnownTransactions      uint32              `json:"numberOfKnownTransactions"`
+	NumberOfStaleTransactions      uint32              `json:"numberOfStaleTransactions"`
+	NumberOfReceivedTransactionReq uint32              `json:"numberOfReceivedTransactionReq"`
+	NumberOfReceivedMilestoneReq   uint32              `json:"numberOfReceivedMilestoneReq"`
+	NumberOfReceivedHeartbeats     uint32              `json:"numberOfReceivedHeartbeats"`
+	NumberOfSentPackets            uint32              `json:"numberOfSentPackets"`
+	NumberOfSentTransactions       uint32              `json:"numberOfSentTransactions"`
+	NumberOfSentTransactionsReq    uint32              `json:"numberOfSentTransactionsReq"`
+	NumberOfSentMilestoneReq       uint32              `json:"numberOfSentMilestoneReq"`
+	NumberOfSentHeartbeats         uint32              `json:"numberOfSentHeartbeats"`
+	NumberOfDroppedSentPackets     uint32              `json:"numberOfDroppedSentPackets"`
+	NumberOfInvalidMessages        uint32              `json:"numberOfInvalidMessages"`
+	NumberOfDuplicateTransactions  uint32              `json:"numberOfDuplicateTransactions"`
+	ConnectionType                 string              `json:"connectionType"`
+	Relation                       string              `json:"relation"`
+	Addresses                      []string            `json:"addresses,omitempty"`
+	Connected                      bool                `json:"connected"`
+	Autopeered                     bool                `json:"autopeered"`
+	AutopeeringID                  string              `json:"autopeeringId,omitempty"`
+	NodeInfo                       *handshake.NodeInfo `json:"nodeInfo,omitempty"`
+	Quality                        *QualityInfo        `json:"quality,omitempty"`
+	Score                          *ScoreInfo          `json:"score,omitempty"`
+	Stream                         *StreamStats        `json:"stream,omitempty"`
+	Connection                     *ConnectionStats    `json:"connection,omitempty"`
 }
@@ -0,0 +1,106 @@
+package peer
+
+import (
+	"time"
+)
+
+const (
+	// QualityHeartbeatInterval is the expected interval in which heartbeats are received from a peer.
+	QualityHeartbeatInterval = 30 * time.Second
+	// QualityMaxConnectLatency is the ping or connect latency at which the latency score of a peer drops to zero.
+	QualityMaxConnectLatency = 2 * time.Second
+
+	qualityWeightUptime    = 0.3
+	qualityWeightHeartbeat = 0.3
+	qualityWeightAnswers   = 0.2
+	qualityWeightLatency   = 0.2
+)
+
+// QualityInfo holds the computed connection quality of a peer.
+// All scores are in the range of 0 (bad) to 1 (good).
+type QualityInfo struct {
+	// The weighted total quality score.
+	Score float64 `json:"score"`
+	// The percentage of time the peer was connected within the history window.
+	Uptime float64 `json:"uptime"`
+	// How regularly heartbeats are received from the peer.
+	HeartbeatRegularity float64 `json:"heartbeatRegularity"`
+	// The ratio of received transactions to the requests sent to the peer.
+	AnswerRate float64 `json:"answerRate"`
+	// The score derived from the ping latency, or the latency of the connection setup if the peer wasn't pinged yet.
+	Latency float64 `json:"latency"`
+	// The latency of the connection setup in milliseconds (0 if unknown).
+	ConnectLatencyMs int64 `json:"connectLatencyMs"`
+	// The smoothed round trip time of the pings to the peer in milliseconds (0 if unknown).
+	PingLatencyMs int64 `json:"pingLatencyMs"`
+	// The history of the total quality score, oldest sample first.
+	History []float64 `json:"history"`
+}
+
+// Quality computes the current connection quality of the peer given its uptime within the history window.
+func (p *Peer) Quality(uptime float64) *QualityInfo {
+	q := &QualityInfo{
+		Uptime:              uptime,
+		HeartbeatRegularity: p.heartbeatRegularity(),
+		AnswerRate:          p.answerRate(),
+		Latency:             p.latencyScore(),
+		ConnectLatencyMs:    p.ConnectLatency.Milliseconds(),
+		PingLatencyMs:       p.Latency().Milliseconds(),
+	}
+
+	q.Score = qualityWeightUptime*q.Uptime +
+		qualityWeightHeartbeat*q.HeartbeatRegularity +
+		qualityWeightAnswers*q.AnswerRate +
+		qualityWeightLatency*q.Latency
+
+	return q
+}
+
+// returns 1 if heartbeats are received in the expected interval, the score decreases linearly
+// to 0 if no heartbeat was received for four times the expected interval.
+func (p *Peer) heartbeatRegularity() float64 {
+	if p.HeartbeatReceivedTime.IsZero() {
+		return 0
+	}
+
+	since := time.Since(p.HeartbeatReceivedTime)
+	if since <= QualityHeartbeatInterval {
+		return 1
+	}
+
+	return clampScore(1 - float64(since-QualityHeartbeatInterval)/float64(3*QualityHeartbeatInterval))
+}
+
+// returns the score derived from the ping latency, or the latency of the connection setup if the peer wasn't pinged yet.
+// inbound connections and peers with an unknown latency get the full score.
+func (p *Peer) latencyScore() float64 {
+	latency := p.Latency()
+	if latency <= 0 {
+		latency = p.ConnectLatency
+	}
+	if latency <= 0 {
+		return 1
+	}
+
+	return clampScore(1 - float64(latency)/float64(QualityMaxConnectLatency))
+}
+
+// returns the ratio of received transactions to the requests sent to the peer.
+func (p *Peer) answerRate() float64 {
+	requests := p.Metrics.SentTransactionRequests.Load() + p.Metrics.SentMilestoneRequests.Load()
+	if requests == 0 {
+		return 1
+	}
+
+	return clampScore(float64(p.Metrics.ReceivedTransactions.Load()) / float64(requests))
+}
+
+func clampScore(score float64) float64 {
+	if score < 0 {
+		return 0
+	}
+	if score > 1 {
+		return 1
+	}
+	return score
+}
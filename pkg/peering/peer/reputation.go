@@ -0,0 +1,82 @@
+package peer
+
+import (
+	"time"
+)
+
+const (
+	// ReputationMaxInvalidMessages is the amount of invalid messages in the history of a peer
+	// at which the invalid message score of its reputation drops to zero.
+	ReputationMaxInvalidMessages = 20
+	// ReputationMinSentRequests is the amount of requests sent to a peer in its history
+	// needed before the answer rate is taken into account.
+	ReputationMinSentRequests = 100
+	// ReputationInitialWeight is the weight of the reputation in the score of a freshly connected peer.
+	ReputationInitialWeight = 0.5
+	// ReputationFadeOutDuration is the duration after which the reputation no longer influences the score of a connected peer.
+	ReputationFadeOutDuration = 1 * time.Hour
+
+	reputationWeightInvalidMessages = 0.5
+	reputationWeightAnswers         = 0.25
+	reputationWeightUptime          = 0.25
+)
+
+// ReputationInfo holds the reputation of a peer derived from the statistics collected across restarts.
+// All scores are in the range of 0 (bad) to 1 (good).
+type ReputationInfo struct {
+	// The weighted total reputation score.
+	Score float64 `json:"score"`
+	// The total amount of invalid messages sent by the peer.
+	TotalInvalidMessages uint64 `json:"totalInvalidMessages"`
+	// The total amount of new transactions received from the peer.
+	TotalUsefulAnswers uint64 `json:"totalUsefulAnswers"`
+	// The share of time the peer was connected while it was known to the node.
+	Uptime float64 `json:"uptime"`
+	// The time the reputation was loaded.
+	loadedAt time.Time
+}
+
+// NewReputationInfo computes the reputation of a peer given its statistics.
+func NewReputationInfo(invalidMessages uint64, usefulAnswers uint64, sentRequests uint64, connectedSeconds uint64, knownSeconds uint64) *ReputationInfo {
+	r := &ReputationInfo{
+		TotalInvalidMessages: invalidMessages,
+		TotalUsefulAnswers:   usefulAnswers,
+		Uptime:               1,
+		loadedAt:             time.Now(),
+	}
+
+	if knownSeconds != 0 {
+		r.Uptime = clampScore(float64(connectedSeconds) / float64(knownSeconds))
+	}
+
+	answerRate := 1.0
+	if sentRequests >= ReputationMinSentRequests {
+		answerRate = clampScore(float64(usefulAnswers) / float64(sentRequests))
+	}
+
+	r.Score = reputationWeightInvalidMessages*clampScore(1-float64(invalidMessages)/ReputationMaxInvalidMessages) +
+		reputationWeightAnswers*answerRate +
+		reputationWeightUptime*r.Uptime
+
+	return r
+}
+
+// Reputation returns the reputation of the peer. Returns nil if the reputation of the peer is unknown.
+func (p *Peer) Reputation() *ReputationInfo {
+	reputation, _ := p.reputation.Load().(*ReputationInfo)
+	return reputation
+}
+
+// SetReputation sets the reputation of the peer which influences its score after connecting.
+func (p *Peer) SetReputation(reputation *ReputationInfo) {
+	p.reputation.Store(reputation)
+}
+
+// returns the weight of the reputation in the score of the peer.
+// the weight decreases linearly from ReputationInitialWeight to 0 within ReputationFadeOutDuration.
+func (r *ReputationInfo) weight() float64 {
+	if r == nil {
+		return 0
+	}
+	return ReputationInitialWeight * clampScore(1-float64(time.Since(r.loadedAt))/float64(ReputationFadeOutDuration))
+}
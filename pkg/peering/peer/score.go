@@ -0,0 +1,85 @@
+package peer
+
+const (
+	// ScoreMaxInvalidMessages is the amount of invalid messages at which the invalid message score of a peer drops to zero.
+	ScoreMaxInvalidMessages = 5
+	// ScoreMaxDuplicateRatio is the ratio of duplicate to received transactions at which the duplicate score of a peer drops to zero.
+	ScoreMaxDuplicateRatio = 0.1
+	// ScoreMinReceivedTransactions is the amount of received transactions needed before duplicates are taken into account.
+	ScoreMinReceivedTransactions = 100
+
+	scoreWeightInvalidMessages = 0.4
+	scoreWeightHeartbeat       = 0.3
+	scoreWeightDuplicates      = 0.2
+	scoreWeightLatency         = 0.1
+)
+
+func ScoreCaller(handler interface{}, params ...interface{}) {
+	handler.(func(*Peer, *ScoreInfo))(params[0].(*Peer), params[1].(*ScoreInfo))
+}
+
+// ScoreInfo holds the score of a peer derived from its gossip behavior.
+// All scores are in the range of 0 (bad) to 1 (good).
+type ScoreInfo struct {
+	// The weighted total score.
+	Score float64 `json:"score"`
+	// The score derived from the amount of invalid messages sent by the peer.
+	InvalidMessages float64 `json:"invalidMessages"`
+	// How regularly heartbeats are received from the peer.
+	HeartbeatRegularity float64 `json:"heartbeatRegularity"`
+	// The score derived from the ratio of transactions the peer sent more than once.
+	Duplicates float64 `json:"duplicates"`
+	// The score derived from the ping latency, or the latency of the connection setup if the peer wasn't pinged yet.
+	Latency float64 `json:"latency"`
+	// Whether the peer is deprioritized because of its low score.
+	Deprioritized bool `json:"deprioritized"`
+	// The reputation of the peer, nil if it is unknown.
+	Reputation *ReputationInfo `json:"reputation,omitempty"`
+}
+
+// Score computes the current score of the peer given its gossip behavior.
+// The reputation of the peer influences the score right after connecting, so that a peer
+// which misbehaved in the past doesn't start with a clean score.
+func (p *Peer) Score() *ScoreInfo {
+	s := &ScoreInfo{
+		InvalidMessages:     clampScore(1 - float64(p.Metrics.InvalidMessages.Load())/ScoreMaxInvalidMessages),
+		HeartbeatRegularity: p.heartbeatRegularity(),
+		Duplicates:          p.duplicateScore(),
+		Latency:             p.latencyScore(),
+		Deprioritized:       p.Deprioritized(),
+		Reputation:          p.Reputation(),
+	}
+
+	s.Score = scoreWeightInvalidMessages*s.InvalidMessages +
+		scoreWeightHeartbeat*s.HeartbeatRegularity +
+		scoreWeightDuplicates*s.Duplicates +
+		scoreWeightLatency*s.Latency
+
+	if weight := s.Reputation.weight(); weight > 0 {
+		s.Score = (1-weight)*s.Score + weight*s.Reputation.Score
+	}
+
+	return s
+}
+
+// Deprioritized tells whether the peer is deprioritized because of its low score.
+func (p *Peer) Deprioritized() bool {
+	return p.deprioritized.Load()
+}
+
+// SetDeprioritized sets whether the peer is deprioritized and returns whether the state changed.
+func (p *Peer) SetDeprioritized(deprioritized bool) bool {
+	return p.deprioritized.CAS(!deprioritized, deprioritized)
+}
+
+// returns 1 if the peer did not send any transaction more than once, the score decreases linearly
+// to 0 if the ratio of duplicate to received transactions reaches ScoreMaxDuplicateRatio.
+func (p *Peer) duplicateScore() float64 {
+	received := p.Metrics.ReceivedTransactions.Load()
+	if received < ScoreMinReceivedTransactions {
+		return 1
+	}
+
+	duplicateRatio := float64(p.Metrics.DuplicateTransactions.Load()) / float64(received)
+	return clampScore(1 - duplicateRatio/ScoreMaxDuplicateRatio)
+}
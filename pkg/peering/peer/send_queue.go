@@ -0,0 +1,250 @@
+package peer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gohornet/hornet/pkg/metrics"
+)
+
+// SendQueueOverflowPolicy defines which message is dropped if the send queue of a peer is full.
+type SendQueueOverflowPolicy string
+
+const (
+	// SendQueueDropNewest drops the message which should be enqueued.
+	SendQueueDropNewest SendQueueOverflowPolicy = "dropNewest"
+	// SendQueueDropOldest drops the oldest message in the send queue to make room for the new one.
+	SendQueueDropOldest SendQueueOverflowPolicy = "dropOldest"
+	// SendQueueBlock lets the message which should be enqueued wait for room in the send queue without blocking the sender.
+	// It is dropped if the block timeout is reached or as many messages are already waiting as the send queue holds.
+	SendQueueBlock SendQueueOverflowPolicy = "block"
+)
+
+const (
+	// SendQueueDrainPollInterval is the interval at which the send queues are checked while they are drained.
+	SendQueueDrainPollInterval = 10 * time.Millisecond
+)
+
+var (
+	// ErrUnknownSendQueueOverflowPolicy is returned if an unknown send queue overflow policy is configured.
+	ErrUnknownSendQueueOverflowPolicy = errors.New("unknown send queue overflow policy")
+	// ErrSendQueueFull is returned if the send queue of the peer stayed full until the context was done.
+	ErrSendQueueFull = errors.New("send queue is full")
+	// ErrSendQueueMemoryLimitReached is returned if the send queue of the peer holds as many bytes as allowed.
+	ErrSendQueueMemoryLimitReached = errors.New("send queue memory limit reached")
+)
+
+// ParseSendQueueOverflowPolicy parses the given send queue overflow policy.
+func ParseSendQueueOverflowPolicy(policy string) (SendQueueOverflowPolicy, error) {
+	switch SendQueueOverflowPolicy(policy) {
+	case SendQueueDropNewest, SendQueueDropOldest, SendQueueBlock:
+		return SendQueueOverflowPolicy(policy), nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownSendQueueOverflowPolicy, policy)
+	}
+}
+
+// DrainSendQueues waits until all messages in the send queues of the peer were sent or the given timeout is reached.
+// Returns whether the send queues were drained.
+func (p *Peer) DrainSendQueues(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for len(p.SendQueue) != 0 || len(p.PrioritySendQueue) != 0 || p.blockedSends.Load() != 0 {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(SendQueueDrainPollInterval)
+	}
+
+	// wait for the last dequeued message to be written
+	if p.Protocol != nil {
+		p.Protocol.WaitForPendingSend()
+	}
+	return true
+}
+
+// EnqueueForSendingContext enqueues the given data to be sent to the peer.
+// Instead of applying the send queue overflow policy, it waits for room in a full send queue until the given context is done.
+// The message is not counted as dropped if it couldn't be enqueued, the returned ErrSendQueueFull, ErrSendQueueMemoryLimitReached
+// or ErrSendQueueMemoryBudgetExhausted lets the caller decide whether to drop it or to send it to another peer.
+func (p *Peer) EnqueueForSendingContext(ctx context.Context, data []byte) error {
+	size := int64(len(data))
+	if err := p.reserveSendQueueMemory(size); err != nil {
+		return err
+	}
+
+	select {
+	case p.SendQueue <- data:
+	default:
+		select {
+		case p.SendQueue <- data:
+		case <-ctx.Done():
+			p.releaseSendQueueMemory(size)
+			return fmt.Errorf("%w: %s", ErrSendQueueFull, ctx.Err())
+		}
+	}
+
+	p.sendQueueMemoryExhausted.Store(false)
+	p.checkHighWatermark()
+	return nil
+}
+
+// enqueues the given data into the full send queue according to the send queue overflow policy.
+// returns false if the data has to be dropped.
+func (p *Peer) enqueueOnOverflow(data []byte) bool {
+	switch p.SendQueueOverflowPolicy {
+	case SendQueueDropOldest:
+		// the send queue consumer might free up room concurrently, so only drop a message if still needed
+		select {
+		case oldest := <-p.SendQueue:
+			p.DequeuedForSending(oldest)
+			p.messageDropped()
+		default:
+		}
+
+		select {
+		case p.SendQueue <- data:
+			return true
+		default:
+			return false
+		}
+
+	case SendQueueBlock:
+		// the message waits for room in its own goroutine, so that a slow peer doesn't stall the caller,
+		// which usually sends the message to all peers. the amount of waiting messages is limited like the send queue.
+		if p.blockedSends.Inc() > int32(cap(p.SendQueue)) {
+			p.blockedSends.Dec()
+			return false
+		}
+
+		go func() {
+			defer p.blockedSends.Dec()
+
+			timer := time.NewTimer(p.SendQueueBlockTimeout)
+			defer timer.Stop()
+
+			select {
+			case p.SendQueue <- data:
+				p.checkHighWatermark()
+			case <-timer.C:
+				p.releaseSendQueueMemory(int64(len(data)))
+				p.messageDropped()
+			}
+		}()
+		return true
+
+	default:
+		return false
+	}
+}
+
+// counts a message which was dropped instead of being sent to the peer.
+func (p *Peer) messageDropped() {
+	metrics.SharedServerMetrics.DroppedMessages.Inc()
+	p.Metrics.DroppedPackets.Inc()
+	p.Events.SendQueueMessageDropped.Trigger()
+}
+
+// DroppedForSending counts the given message, which was taken out of the send queue, as dropped
+// since it couldn't be written to the peer in time.
+func (p *Peer) DroppedForSending() {
+	p.messageDropped()
+}
+
+// SendQueueCongested tells whether the send queue reached the high watermark and didn't fall to the low watermark since.
+// Messages which can be skipped shouldn't be enqueued for congested peers, so that slow peers don't hold much memory.
+func (p *Peer) SendQueueCongested() bool {
+	return p.sendQueueCongested.Load()
+}
+
+// fires the SendQueueHighWatermarkReached event if the send queue reached the high watermark.
+func (p *Peer) checkHighWatermark() {
+	if p.SendQueueHighWatermark == 0 || len(p.SendQueue) < p.SendQueueHighWatermark {
+		return
+	}
+	if p.sendQueueCongested.CAS(false, true) {
+		p.Events.SendQueueHighWatermarkReached.Trigger()
+	}
+}
+
+// fires the SendQueueLowWatermarkReached event if the congested send queue fell to the low watermark.
+func (p *Peer) checkLowWatermark() {
+	if p.SendQueueHighWatermark == 0 || len(p.SendQueue) > p.SendQueueLowWatermark {
+		return
+	}
+	if p.sendQueueCongested.CAS(true, false) {
+		p.Events.SendQueueLowWatermarkReached.Trigger()
+	}
+}
+
+// reserves the memory of a message to enqueue within the send queue memory limit of the peer and the budget of all peers.
+// the events are fired when messages start to get dropped because of the limit or the budget.
+func (p *Peer) reserveSendQueueMemory(size int64) error {
+	if p.SendQueueMemoryLimit != 0 && p.sendQueueMemory.Add(size) > p.SendQueueMemoryLimit {
+		p.sendQueueMemory.Sub(size)
+		if p.sendQueueMemoryExhausted.CAS(false, true) {
+			p.Events.SendQueueMemoryExhausted.Trigger()
+		}
+		return ErrSendQueueMemoryLimitReached
+	}
+
+	if p.SendQueueMemoryBudget == nil {
+		return nil
+	}
+
+	p.budgetMu.Lock()
+	defer p.budgetMu.Unlock()
+
+	// the memory of a released peer is not accounted anymore
+	if p.budgetReleased {
+		return nil
+	}
+
+	reserved, exhausted := p.SendQueueMemoryBudget.reserve(size)
+	if !reserved {
+		if p.SendQueueMemoryLimit != 0 {
+			p.sendQueueMemory.Sub(size)
+		}
+		if exhausted {
+			p.Events.SendQueueMemoryBudgetExhausted.Trigger()
+		}
+		return ErrSendQueueMemoryBudgetExhausted
+	}
+	p.budgetUsed += size
+	return nil
+}
+
+// frees the memory of a message which was dequeued or dropped.
+func (p *Peer) releaseSendQueueMemory(size int64) {
+	if p.SendQueueMemoryLimit != 0 {
+		p.sendQueueMemory.Sub(size)
+	}
+
+	if p.SendQueueMemoryBudget == nil {
+		return
+	}
+
+	p.budgetMu.Lock()
+	defer p.budgetMu.Unlock()
+	if !p.budgetReleased {
+		p.SendQueueMemoryBudget.release(size)
+		p.budgetUsed -= size
+	}
+}
+
+// ReleaseSendQueueMemoryBudget returns the memory held in the send queue to the budget of all peers,
+// since the messages of a disconnected peer are never dequeued. It has to be called once the connection to the peer was closed.
+func (p *Peer) ReleaseSendQueueMemoryBudget() {
+	if p.SendQueueMemoryBudget == nil {
+		return
+	}
+
+	p.budgetMu.Lock()
+	defer p.budgetMu.Unlock()
+	if !p.budgetReleased {
+		p.budgetReleased = true
+		p.SendQueueMemoryBudget.release(p.budgetUsed)
+		p.budgetUsed = 0
+	}
+}
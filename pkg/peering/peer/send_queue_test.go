@@ -0,0 +1,51 @@
+package peer_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/iotaledger/hive.go/events"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gohornet/hornet/pkg/peering/peer"
+	"github.com/gohornet/hornet/pkg/protocol/protocoltest"
+	"github.com/gohornet/hornet/pkg/protocol/sting"
+)
+
+func TestEnqueueForSendingContext(t *testing.T) {
+	pair := protocoltest.NewPair(sting.FeatureSet, false)
+	defer pair.Close()
+
+	p := pair.Local
+	p.SendQueue = make(chan []byte, 1)
+
+	var dropped int
+	p.Events.SendQueueMessageDropped.Attach(events.NewClosure(func() {
+		dropped++
+	}))
+
+	msg, err := sting.NewMilestoneRequestMessage(1)
+	assert.NoError(t, err)
+
+	assert.NoError(t, p.EnqueueForSendingContext(context.Background(), msg))
+
+	// the send queue is full, so the context deadline is exceeded
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = p.EnqueueForSendingContext(ctx, msg)
+	assert.True(t, errors.Is(err, peer.ErrSendQueueFull))
+	assert.Zero(t, dropped)
+
+	// the message is enqueued once there is room again
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		p.DequeuedForSending(<-p.SendQueue)
+	}()
+	assert.NoError(t, p.EnqueueForSendingContext(context.Background(), msg))
+
+	// the message exceeds the send queue memory limit
+	p.SendQueueMemoryLimit = int64(len(msg)) - 1
+	assert.True(t, errors.Is(p.EnqueueForSendingContext(context.Background(), msg), peer.ErrSendQueueMemoryLimitReached))
+}
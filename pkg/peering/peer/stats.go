@@ -0,0 +1,57 @@
+package peer
+
+import (
+	"time"
+)
+
+// StreamStats holds the statistics of the stream to a connected peer.
+type StreamStats struct {
+	// The amount of bytes received over the stream.
+	BytesIn uint64 `json:"bytesIn"`
+	// The amount of bytes sent over the stream.
+	BytesOut uint64 `json:"bytesOut"`
+	// The amount of messages received over the stream.
+	MessagesIn uint64 `json:"messagesIn"`
+	// The amount of messages sent over the stream.
+	MessagesOut uint64 `json:"messagesOut"`
+	// The amount of messages in the send queue.
+	SendQueueLength int `json:"sendQueueLength"`
+	// The amount of messages in the priority send queue.
+	PrioritySendQueueLength int `json:"prioritySendQueueLength"`
+	// The fill level of the send queue in the range of 0 (empty) to 1 (full).
+	SendQueueFill float64 `json:"sendQueueFill"`
+	// The time since the last heartbeat was received in milliseconds, -1 if no heartbeat was received yet.
+	LastHeartbeatAgeMs int64 `json:"lastHeartbeatAgeMs"`
+	// The time since the stream was established in seconds.
+	UptimeSeconds int64 `json:"uptimeSeconds"`
+}
+
+// StreamStats returns a snapshot of the statistics of the stream to the peer.
+// Returns nil if the peer has no protocol instance yet.
+func (p *Peer) StreamStats() *StreamStats {
+	if p.Protocol == nil {
+		return nil
+	}
+
+	protocolStats := p.Protocol.Stats()
+	stats := &StreamStats{
+		BytesIn:                 protocolStats.BytesIn,
+		BytesOut:                protocolStats.BytesOut,
+		MessagesIn:              protocolStats.MessagesIn,
+		MessagesOut:             protocolStats.MessagesOut,
+		SendQueueLength:         len(p.SendQueue),
+		PrioritySendQueueLength: len(p.PrioritySendQueue),
+		LastHeartbeatAgeMs:      -1,
+		UptimeSeconds:           int64(protocolStats.Uptime / time.Second),
+	}
+
+	if capacity := cap(p.SendQueue); capacity != 0 {
+		stats.SendQueueFill = float64(stats.SendQueueLength) / float64(capacity)
+	}
+
+	if !p.HeartbeatReceivedTime.IsZero() {
+		stats.LastHeartbeatAgeMs = time.Since(p.HeartbeatReceivedTime).Milliseconds()
+	}
+
+	return stats
+}
@@ -0,0 +1,49 @@
+package peer
+
+import (
+	"strings"
+)
+
+// Transport is the transport over which the connection to a peer is established.
+type Transport string
+
+const (
+	// TransportTCP are plain TCP connections.
+	TransportTCP Transport = "tcp"
+	// TransportWebSocket are WebSocket connections.
+	TransportWebSocket Transport = "ws"
+	// TransportWebSocketSecure are WebSocket connections secured by TLS.
+	TransportWebSocketSecure Transport = "wss"
+)
+
+// String returns the name of the transport, TCP if none is set.
+func (t Transport) String() string {
+	if t == "" {
+		return string(TransportTCP)
+	}
+	return string(t)
+}
+
+// IsWebSocket tells whether the transport uses WebSocket connections.
+func (t Transport) IsWebSocket() bool {
+	return t == TransportWebSocket || t == TransportWebSocketSecure
+}
+
+// ParseTransportAddress splits the transport off the given peer address ("ws://host:port", "wss://host:port").
+// Addresses without a scheme or with the "tcp://" scheme use TCP.
+func ParseTransportAddress(addr string) (Transport, string) {
+	for _, transport := range []Transport{TransportTCP, TransportWebSocket, TransportWebSocketSecure} {
+		if prefix := string(transport) + "://"; strings.HasPrefix(addr, prefix) {
+			return transport, strings.TrimPrefix(addr, prefix)
+		}
+	}
+	return TransportTCP, addr
+}
+
+// Address prefixes the given peer address with the scheme of the transport, TCP addresses are kept as they are.
+func (t Transport) Address(addr string) string {
+	if !t.IsWebSocket() {
+		return addr
+	}
+	return string(t) + "://" + addr
+}
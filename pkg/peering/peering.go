@@ -1,9 +1,12 @@
 package peering
 
 import (
+	"crypto/rand"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"strconv"
 	"strings"
 	"sync"
@@ -26,18 +29,24 @@ import (
 )
 
 const (
-	isNeighborSyncedThreshold        = 2
 	updateNeighborsCountCooldownTime = time.Duration(2 * time.Second)
-	connectionWriteTimeout           = 5 * time.Second
 )
 
 var (
+	// ErrSendQueueDrainTimeout is returned when the send queue of a removed peer couldn't be sent before the drain timeout.
+	ErrSendQueueDrainTimeout = errors.New("send queue drain timeout")
 	// ErrPeeringSlotsFilled is returned when all available peering slots are filled.
 	ErrPeeringSlotsFilled = errors.New("peering slots filled")
+	// ErrAutopeeringSlotsFilled is returned when all available slots for autopeered peers are filled.
+	ErrAutopeeringSlotsFilled = errors.New("autopeering slots filled")
 	// ErrNonMatchingMWM is returned when the MWM doesn't match this node's MWM.
 	ErrNonMatchingMWM = errors.New("used MWM doesn't match")
 	// ErrNonMatchingCooAddr is returned when the Coo address doesn't match this node's Coo address.
 	ErrNonMatchingCooAddr = errors.New("used coo addr doesn't match")
+	// ErrNonMatchingNetworkID is returned when the network ID of the node info doesn't match this node's network ID.
+	ErrNonMatchingNetworkID = errors.New("network ID doesn't match")
+	// ErrUnexpectedNodeInfo is returned when a node info is received although it wasn't negotiated or was already received.
+	ErrUnexpectedNodeInfo = errors.New("unexpected node info")
 	// ErrNonMatchingSrvSocketPort is returned when the server socket port doesn't match.
 	ErrNonMatchingSrvSocketPort = errors.New("advertised server socket port doesn't match")
 	// ErrUnknownPeerID is returned when an unknown peer tried to connect.
@@ -70,14 +79,45 @@ func NewManager(opts Options, peers ...*config.PeerConfig) *Manager {
 			IPLookupError:                         events.NewEvent(events.ErrorCaller),
 			Shutdown:                              events.NewEvent(events.CallbackCaller),
 			Error:                                 events.NewEvent(events.ErrorCaller),
+			ResourceLimitReached:                  events.NewEvent(ResourceLimitReachedCaller),
+			ConnectFailed:                         events.NewEvent(ConnectFailedCaller),
+			SendQueueMessageDropped:               events.NewEvent(peer.Caller),
+			SendQueueCongested:                    events.NewEvent(peer.Caller),
+			SendQueueRelieved:                     events.NewEvent(peer.Caller),
+			PeerScoreLow:                          events.NewEvent(peer.ScoreCaller),
+			PeerScoreRecovered:                    events.NewEvent(peer.ScoreCaller),
+			ProtocolTerminated:                    events.NewEvent(ProtocolTerminatedCaller),
+			ConnectionPruned:                      events.NewEvent(peer.Caller),
+			PeerBanned:                            events.NewEvent(BanCaller),
+			PeerUnbanned:                          events.NewEvent(BanCaller),
+			PeerLatencyMeasured:                   events.NewEvent(peer.LatencyCaller),
+			PeerConnectionEnded:                   events.NewEvent(peer.ConnectionStatsCaller),
+			PeerRelationChanged:                   events.NewEvent(RelationChangedCaller),
+			StaticPeerUnreachable:                 events.NewEvent(UnreachablePeerCaller),
+			StaticPeerReachable:                   events.NewEvent(UnreachablePeerCaller),
+			FailoverActivated:                     events.NewEvent(FailoverCaller),
+			FailoverDeactivated:                   events.NewEvent(FailoverCaller),
 		},
-		tcpServer: tcp.NewServer(),
-		connected: map[string]*peer.Peer{},
-		reconnect: map[string]*reconnectinfo{},
-		whitelist: map[string]*autopeering.Peer{},
-		blacklist: map[string]struct{}{},
-		Opts:      opts,
+		tcpServer:         tcp.NewServer(),
+		connected:         map[string]*peer.Peer{},
+		reconnect:         map[string]*reconnectinfo{},
+		whitelist:         map[string]*autopeering.Peer{},
+		blacklist:         map[string]struct{}{},
+		bans:              map[string]*Ban{},
+		qualityHistory:    map[string]*qualityHistory{},
+		pendingInboundIPs: map[string]int{},
+		connHistories:     map[string]*connectionHistory{},
+		staticOutages:     map[string]*staticPeerOutage{},
+		backups:           map[string]*backupPeer{},
+		primaryOutages:    map[string]time.Time{},
+		Opts:              opts,
 	}
+	m.gater.Store(opts.Gater)
+	m.handshakeNonce = newHandshakeNonce()
+	if opts.Limits.MaxTotalSendQueueMemoryBytes != 0 {
+		m.sendQueueMemoryBudget = peer.NewMemoryBudget(opts.Limits.MaxTotalSendQueueMemoryBytes)
+	}
+	m.SetBackupPeers(peers)
 	m.moveInitialPeersToReconnectPool(peers)
 	return m
 }
@@ -96,8 +136,17 @@ type Manager struct {
 
 	// the TCP server instance used to handle incoming connections.
 	tcpServer *tcp.TCPServer
+	// the HTTP server used to handle incoming WebSocket connections, nil if it isn't enabled.
+	webSocketServer *http.Server
+	// the budget of the memory held in the send queues of all peers, nil if it isn't limited.
+	sendQueueMemoryBudget *peer.MemoryBudget
+	// the listeners besides the TCP server, listed by their bound addresses.
+	listeners   []*listener
+	listenersMu sync.Mutex
 	// holds currently connected peers.
 	connected map[string]*peer.Peer
+	// holds a copy of the connected peers for lookups without the manager lock.
+	connectedSnapshot atomic.Value
 	// holds peers to which we want to connect to.
 	reconnect map[string]*reconnectinfo
 	// defines the set of allowed peer identities.
@@ -106,8 +155,37 @@ type Manager struct {
 	// defines a set of blacklisted IP addresses.
 	blacklist   map[string]struct{}
 	blacklistMu sync.Mutex
+	// holds the bans of the peers keyed by their ID.
+	bans   map[string]*Ban
+	bansMu sync.Mutex
+	// holds the rules which define the allowed connections by IP address and peer ID.
+	gater atomic.Value
 	// used to enforce one handshake verification at a time.
 	handshakeVerifyMu sync.Mutex
+	// holds the sampled connection quality of the peers.
+	qualityHistory map[string]*qualityHistory
+	qualityMu      sync.Mutex
+	// holds the connections of the peers since the node was started.
+	connHistories   map[string]*connectionHistory
+	connHistoriesMu sync.Mutex
+	// holds the outages of the static peers in the reconnect pool keyed by their init address.
+	staticOutages   map[string]*staticPeerOutage
+	staticOutagesMu sync.Mutex
+	// holds the backup peers keyed by their address and the time since which their primaries are unreachable.
+	backups        map[string]*backupPeer
+	primaryOutages map[string]time.Time
+	failoverMu     sync.Mutex
+	// the port of the server socket, used to derive the own ID for the tie-breaking of simultaneous dials.
+	serverSocketPort uint16
+	// the random nonce announced in the handshakes, which breaks the tie between the connections of simultaneous dials.
+	handshakeNonce uint32
+	// the amount of inbound connections which did not complete the handshake yet.
+	pendingInbound atomic.Int32
+	// the amount of handshaking inbound connections per IP address.
+	pendingInboundIPs   map[string]int
+	pendingInboundIPsMu sync.Mutex
+	// the counters of the peering layer.
+	metrics metrics
 
 	// only used by ConnectedAndSyncedPeerCount
 	connectedNeighborsCount  uint8
@@ -120,17 +198,60 @@ type reconnectinfo struct {
 	OriginAddr  *iputils.OriginAddress `json:"origin_addr"`
 	CachedIPs   *iputils.IPAddresses   `json:"cached_ips"`
 	Autopeering *autopeering.Peer      `json:"peer"`
+	Transport   peer.Transport         `json:"transport"`
 }
 
 // Options defines options for the Manager.
 type Options struct {
 	ValidHandshake handshake.Handshake
+	// The node info exchanged with the peers which support it before any gossip is sent.
+	// Peers with a different network ID are rejected. The features are filled in from the announced feature sets.
+	// An empty network ID disables the exchange.
+	NodeInfo handshake.NodeInfo
 	// The max amount of connected peers (non-autopeering).
 	MaxConnected int
+	// The max amount of connected autopeered peers (0 = unlimited).
+	MaxAutopeered int
 	// Whether to allow connections from any peer.
 	AcceptAnyPeer bool
+	// The rules which define the allowed connections by IP address and peer ID (nil = allow all).
+	Gater *GaterRules
 	// Inbound connection bind address.
 	BindAddress string
+	// The additional addresses to accept inbound connections on, prefixed with the scheme of their transport
+	// if it isn't TCP (e.g. "[::]:15600" or "ws://0.0.0.0:15700"). WebSocket listeners use the path and TLS certificate of the WebSocket options.
+	Listeners []string
+	// The listener for inbound WebSocket connections.
+	WebSocket WebSocket
+	// The limits of the resources used by the peering layer.
+	Limits ResourceLimits
+	// The limits of the autopeered peers sharing a subnet, country or autonomous system.
+	Diversity DiversityLimits
+	// Defines which message is dropped if the send queue of a peer is full.
+	SendQueueOverflowPolicy peer.SendQueueOverflowPolicy
+	// The maximum time to wait for room in the send queue of a peer if the SendQueueBlock policy is used.
+	SendQueueBlockTimeout time.Duration
+	// The maximum time to wait for the send queue of a removed peer to be sent before its connection is closed.
+	SendQueueDrainTimeout time.Duration
+	// The fill levels of the send queues at which the peers are considered congested and relieved.
+	SendQueueWatermarks SendQueueWatermarks
+	// The score below which peers get deprioritized (0 disables the scoring).
+	MinScore float64
+	// Whether peers which are not synced are deprioritized, so that broadcasts and requests go to synced peers first.
+	PreferSyncedPeers bool
+	// The deadlines of the connections to the peers.
+	Deadlines Deadlines
+	// Defines when idle peers are pinged and unresponsive peers are dropped.
+	Keepalive Keepalive
+	// The duration after which an unreachable static peer is alerted (0 disables the alerts).
+	StaticPeerAlertThreshold time.Duration
+	// The duration a primary peer has to be unreachable before its backup peers are added.
+	FailoverDelay time.Duration
+	// The bandwidth limits of the peers per peer relation.
+	Bandwidth BandwidthLimits
+	// The relations of the peers with which the gossip is started once they are connected (nil = all but unknown).
+	// The connections to other peers are kept without gossiping transactions (relay-only).
+	GossipRelations []protocol.Relation
 }
 
 // Events defines events fired regarding peering.
@@ -165,6 +286,47 @@ type Events struct {
 	Shutdown *events.Event
 	// Fired when internal errors occur.
 	Error *events.Event
+	// Fired when a resource limit clipped the connectivity of the node.
+	ResourceLimitReached *events.Event
+	// Fired for every failed attempt to connect to a peer.
+	ConnectFailed *events.Event
+	// Fired for every message which was dropped instead of being sent to a peer.
+	SendQueueMessageDropped *events.Event
+	// Fired when the send queue of a peer reached the high watermark.
+	SendQueueCongested *events.Event
+	// Fired when the send queue of a congested peer fell to the low watermark.
+	SendQueueRelieved *events.Event
+	// Fired when the score of a peer dropped below the minimum score and the peer got deprioritized.
+	PeerScoreLow *events.Event
+	// Fired when the score of a deprioritized peer reached the minimum score again.
+	PeerScoreRecovered *events.Event
+	// Fired when the protocol of an unresponsive peer is terminated.
+	ProtocolTerminated *events.Event
+	// Fired when the connection to an unknown peer is closed to make room for a known peer.
+	ConnectionPruned *events.Event
+	// Fired when a peer was banned.
+	PeerBanned *events.Event
+	// Fired when the ban of a peer was lifted or expired.
+	PeerUnbanned *events.Event
+	// Fired when the round trip time of a ping to a peer was measured.
+	PeerLatencyMeasured *events.Event
+	// Fired when the connection to a handshaked peer was closed, with the statistics of its connections.
+	PeerConnectionEnded *events.Event
+	// Fired when the relation to a handshaked peer changed, e.g. when an autopeered peer was added as a static peer.
+	PeerRelationChanged *events.Event
+	// Fired when a static peer couldn't be connected to for longer than the alert threshold.
+	StaticPeerUnreachable *events.Event
+	// Fired when an unreachable static peer was connected again.
+	StaticPeerReachable *events.Event
+	// Fired when a backup peer was added, since its primary peer is unreachable.
+	FailoverActivated *events.Event
+	// Fired when a backup peer was removed, since its primary peer is connected again.
+	FailoverDeactivated *events.Event
+}
+
+// RelationChangedCaller is the caller of the PeerRelationChanged event, called with the peer and its previous and new relation.
+func RelationChangedCaller(handler interface{}, params ...interface{}) {
+	handler.(func(*peer.Peer, protocol.Relation, protocol.Relation))(params[0].(*peer.Peer), params[1].(protocol.Relation), params[2].(protocol.Relation))
 }
 
 // IsStaticallyPeered tells if the peer is already statically peered.
@@ -265,11 +427,15 @@ type PeerConsumerFunc func(p *peer.Peer) bool
 
 // ForAllConnected executes the given function for each currently connected peer until
 // abort is returned from within the consumer function. The consumer function is
-// only called on peers who are handshaked.
+// only called on peers who are handshaked. The consumer function is not called after the manager was shut down.
+// The peers are taken from a snapshot of the connected pool, so the consumer function doesn't block
+// the (dis)connecting of peers, but it might be called for a peer which was disconnected in the meantime.
 func (m *Manager) ForAllConnected(f PeerConsumerFunc) {
-	m.RLock()
-	defer m.RUnlock()
-	for _, p := range m.connected {
+	if m.shutdown.Load() {
+		return
+	}
+
+	for _, p := range m.connectedPeers() {
 		if !p.Handshaked() {
 			continue
 		}
@@ -281,7 +447,12 @@ func (m *Manager) ForAllConnected(f PeerConsumerFunc) {
 
 // ForAll executes the given function for each peer until
 // abort is returned from within the consumer function.
+// The consumer function is not called after the manager was shut down.
 func (m *Manager) ForAll(f PeerConsumerFunc) {
+	if m.shutdown.Load() {
+		return
+	}
+
 	m.RLock()
 	defer m.RUnlock()
 	for _, p := range m.connected {
@@ -295,6 +466,7 @@ func (m *Manager) ForAll(f PeerConsumerFunc) {
 			InitAddress: p.OriginAddr,
 			Addresses:   p.CachedIPs,
 			Autopeering: p.Autopeering,
+			Transport:   p.Transport,
 		}
 		if !f(peer) {
 			return
@@ -322,10 +494,18 @@ func (m *Manager) AnySTINGPeerConnected() bool {
 func (m *Manager) PeerInfos() []*peer.Info {
 	m.RLock()
 	defer m.RUnlock()
+
+	m.qualityMu.Lock()
+	defer m.qualityMu.Unlock()
+
 	infos := make([]*peer.Info, 0)
 	for _, p := range m.connected {
 		info := p.Info()
 		info.Connected = true
+		info.Quality = m.qualityInfo(p, true)
+		info.Score = p.Score()
+		info.Stream = p.StreamStats()
+		info.Connection = m.ConnectionStats(p)
 		infos = append(infos, info)
 	}
 	for _, reconnectInfo := range m.reconnect {
@@ -336,7 +516,9 @@ func (m *Manager) PeerInfos() []*peer.Info {
 			Domain:         originAddr.Addr,
 			DomainWithPort: addrStr,
 			Alias:          originAddr.Alias,
-			ConnectionType: "tcp",
+			ConnectionType: reconnectInfo.Transport.String(),
+			Relation:       protocol.RelationStatic.String(),
+			Addresses:      peer.IPStrings(reconnectInfo.CachedIPs),
 			Connected:      false,
 			Autopeered:     false,
 			PreferIPv6:     originAddr.PreferIPv6,
@@ -344,7 +526,10 @@ func (m *Manager) PeerInfos() []*peer.Info {
 		if reconnectInfo.Autopeering != nil {
 			info.Autopeered = true
 			info.AutopeeringID = reconnectInfo.Autopeering.ID().String()
+			info.Relation = protocol.RelationAutopeered.String()
 		}
+		info.Quality = m.qualityInfo(&peer.Peer{InitAddress: originAddr}, false)
+		info.Connection = m.ConnectionStats(&peer.Peer{InitAddress: originAddr})
 		infos = append(infos, info)
 	}
 	return infos
@@ -359,9 +544,7 @@ func (m *Manager) PeerCount() int {
 
 // ConnectedPeerCount returns the current count of connected peers.
 func (m *Manager) ConnectedPeerCount() int {
-	m.RLock()
-	defer m.RUnlock()
-	return len(m.connected)
+	return len(m.connectedPeers())
 }
 
 // ConnectedPeerCount returns the current count of connected peers.
@@ -385,16 +568,7 @@ func (m *Manager) ConnectedAndSyncedPeerCount() (uint8, uint8) {
 			m.connectedNeighborsCount++
 		}
 
-		if p.LatestHeartbeat == nil {
-			continue
-		}
-
-		latestIndex := p.LatestHeartbeat.LatestMilestoneIndex
-		if latestIndex < lsi {
-			latestIndex = lsi
-		}
-
-		if p.LatestHeartbeat.SolidMilestoneIndex < (latestIndex - isNeighborSyncedThreshold) {
+		if !p.IsSynced(lsi) {
 			// node not synced
 			continue
 		}
@@ -426,15 +600,50 @@ func (m *Manager) SlotsFilled() bool {
 	return staticCount >= m.Opts.MaxConnected
 }
 
+// AutopeeringSlotsFilled checks whether all available slots for autopeered peers are filled.
+func (m *Manager) AutopeeringSlotsFilled() bool {
+	if m.Opts.MaxAutopeered == 0 {
+		return false
+	}
+
+	autopeeredCount := 0
+	m.ForAllConnected(func(p *peer.Peer) bool {
+		if p.Autopeering != nil {
+			autopeeredCount++
+		}
+		return true
+	})
+
+	return autopeeredCount >= m.Opts.MaxAutopeered
+}
+
+// newHandshakeNonce returns a random nonce which is not 0, since 0 is announced by peers which don't send a nonce.
+func newHandshakeNonce() uint32 {
+	nonceBytes := make([]byte, 4)
+	for {
+		if _, err := rand.Read(nonceBytes); err != nil {
+			panic(err)
+		}
+		if nonce := binary.LittleEndian.Uint32(nonceBytes); nonce != 0 {
+			return nonce
+		}
+	}
+}
+
 // SetupEventHandlers inits the event handlers for handshaking, the underlying connection and errors.
 func (m *Manager) SetupEventHandlers(p *peer.Peer) {
 
+	p.Protocol.ServerSocketPort = m.serverSocketPort
+	p.Protocol.HandshakeNonce = m.handshakeNonce
+
 	onProtocolReceive := events.NewClosure(p.Protocol.Receive)
 
 	onConnectionError := events.NewClosure(func(err error) {
-		if p.Disconnected {
+		if p.Disconnected || protocol.IsDeadlineExceeded(err) {
+			// exceeded deadlines are handled by the protocol
 			return
 		}
+		m.metrics.connectionErrors.Inc()
 		m.Events.Error.Trigger(err)
 		if closeErr := p.Conn.Close(); closeErr != nil {
 			m.Events.Error.Trigger(closeErr)
@@ -445,6 +654,10 @@ func (m *Manager) SetupEventHandlers(p *peer.Peer) {
 		if p.Disconnected {
 			return
 		}
+		if !errors.Is(err, protocol.ErrStreamDeadlinesExceeded) {
+			p.Metrics.InvalidMessages.Inc()
+		}
+		m.countProtocolError(err)
 		m.Events.Error.Trigger(err)
 		if closeErr := p.Conn.Close(); closeErr != nil {
 			m.Events.Error.Trigger(closeErr)
@@ -452,6 +665,12 @@ func (m *Manager) SetupEventHandlers(p *peer.Peer) {
 	})
 
 	onConnectionClose := events.NewClosure(func() {
+		if p.Protocol.IsHandshaked() {
+			if stats := m.recordDisconnected(p); stats != nil {
+				m.Events.PeerConnectionEnded.Trigger(p, stats)
+			}
+		}
+
 		m.Lock()
 		m.moveFromConnectedToReconnectPool(p)
 		m.Unlock()
@@ -474,37 +693,107 @@ func (m *Manager) SetupEventHandlers(p *peer.Peer) {
 	p.Conn.Events.Close.Attach(onConnectionClose)
 
 	m.setupHandshakeEventHandlers(p)
+	m.applySendQueueLimit(p)
+	m.applySendQueueOverflowPolicy(p)
+	m.applySendQueueWatermarks(p)
+	m.applyDeadlines(p)
+	m.applyRelation(p)
+}
+
+// applyRelation sets the relation of the given peer on its protocol and applies the settings which depend on it.
+// It has to be applied again if the relation of the peer changes.
+func (m *Manager) applyRelation(p *peer.Peer) {
+	if p.Protocol == nil {
+		return
+	}
+
+	relation := protocol.RelationStatic
+	switch {
+	case p.Autopeering != nil:
+		relation = protocol.RelationAutopeered
+	case p.InitAddress == nil:
+		// inbound peers are only known once their handshake was verified
+		relation = protocol.RelationUnknown
+	}
+	previous := p.Protocol.Relation()
+	p.Protocol.SetRelation(relation)
+	p.SetGossipEnabled(m.gossipEnabled(relation))
+	m.applyBandwidthLimit(p)
+
+	if p.Handshaked() && previous != relation {
+		m.Events.PeerRelationChanged.Trigger(p, previous, relation)
+	}
+}
+
+// tells whether the gossip is started with peers of the given relation.
+func (m *Manager) gossipEnabled(relation protocol.Relation) bool {
+	if m.Opts.GossipRelations == nil {
+		return relation != protocol.RelationUnknown
+	}
+
+	for _, gossipRelation := range m.Opts.GossipRelations {
+		if gossipRelation == relation {
+			return true
+		}
+	}
+	return false
 }
 
 // Add adds a new peer to the reconnect pool and immediately invokes a connection attempt.
 // The peer is not added if it is already connected or the given address is invalid.
 func (m *Manager) Add(addr string, preferIPv6 bool, alias string, autoPeer ...*autopeering.Peer) error {
+	resolved, err := resolvePeerAddress(addr, preferIPv6, alias)
+	if err != nil {
+		return err
+	}
+
+	var autopeeringPeer *autopeering.Peer
+	if len(autoPeer) > 0 {
+		autopeeringPeer = autoPeer[0]
+	}
+
+	m.Lock()
+	reconnect, err := m.add(resolved, autopeeringPeer)
+	m.Unlock()
+
+	if reconnect {
+		m.Reconnect()
+	}
+	return err
+}
 
+// the address of a peer to add together with the IP addresses it resolved to.
+type resolvedPeerAddress struct {
+	transport   peer.Transport
+	originAddr  *iputils.OriginAddress
+	possibleIPs *iputils.IPAddresses
+}
+
+// parses the given peer address and resolves its IP addresses.
+func resolvePeerAddress(addr string, preferIPv6 bool, alias string) (*resolvedPeerAddress, error) {
+	transport, addr := peer.ParseTransportAddress(addr)
 	originAddr, err := iputils.ParseOriginAddress(addr)
 	if err != nil {
-		return fmt.Errorf("invalid peer address '%s': %w", addr, err)
+		return nil, fmt.Errorf("invalid peer address '%s': %w", addr, err)
 	}
 
 	originAddr.PreferIPv6 = preferIPv6
 	originAddr.Alias = alias
 
-	// check whether the peer is already connected by examining all IP addresses
+	// the IP addresses are used to check whether the peer is already connected
 	possibleIPs, err := iputils.GetIPAddressesFromHost(originAddr.Addr)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	m.Lock()
+	return &resolvedPeerAddress{transport: transport, originAddr: originAddr, possibleIPs: possibleIPs}, nil
+}
 
-	reconnect := false
-	isAutopeer := len(autoPeer) > 0
-
-	defer func() {
-		m.Unlock()
-		if reconnect {
-			m.Reconnect()
-		}
-	}()
+// adds the peer with the given resolved address to the reconnect pool, the manager must be locked.
+// Returns whether a reconnect has to be triggered once the manager is unlocked.
+func (m *Manager) add(resolved *resolvedPeerAddress, autopeeringPeer *autopeering.Peer) (bool, error) {
+	originAddr, possibleIPs := resolved.originAddr, resolved.possibleIPs
+	isAutopeer := autopeeringPeer != nil
 
 	// check whether the peer is already connected or in the reconnect pool
 	// given any of the IP addresses to which the peer address resolved to
@@ -517,14 +806,15 @@ func (m *Manager) Add(addr string, preferIPv6 bool, alias string, autoPeer ...*a
 
 				// mark the autopeer as statically connected now
 				peer.Autopeering = nil
+				m.applyRelation(peer)
 
 				// Remove the autopeering entry in the Selector (this will not drop the connection because we set "Autopeering" to nil)
 				m.Events.AutopeeredPeerBecameStatic.Trigger(autopeeringIdentity)
 
 				// no need to drop the connection
-				return nil
+				return false, nil
 			}
-			return fmt.Errorf("%w: '%s' is already connected as '%s'", ErrPeerAlreadyConnected, originAddr.String(), id)
+			return false, fmt.Errorf("%w: '%s' is already connected as '%s'", ErrPeerAlreadyConnected, originAddr.String(), id)
 		}
 
 		// check whether already in reconnect pool
@@ -539,28 +829,24 @@ func (m *Manager) Add(addr string, preferIPv6 bool, alias string, autoPeer ...*a
 				m.Events.AutopeeredPeerBecameStatic.Trigger(autopeeringIdentity)
 
 				// force reconnect attempts now
-				reconnect = true
-				return nil
+				return true, nil
 			}
-			return fmt.Errorf("%w: '%s' is already in the reconnect pool", ErrPeerAlreadyInReconnect, originAddr.String())
+			return false, fmt.Errorf("%w: '%s' is already in the reconnect pool", ErrPeerAlreadyInReconnect, originAddr.String())
 		}
 	}
 
 	// construct reconnect info
-	reconnectInfo := &reconnectinfo{OriginAddr: originAddr, CachedIPs: possibleIPs}
-	if isAutopeer {
-		reconnectInfo.Autopeering = autoPeer[0]
-	}
+	reconnectInfo := &reconnectinfo{OriginAddr: originAddr, CachedIPs: possibleIPs, Transport: resolved.transport, Autopeering: autopeeringPeer}
 
 	m.moveToReconnectPool(reconnectInfo)
 
 	// force reconnect attempts now
-	reconnect = true
-	return nil
+	return true, nil
 }
 
 // Remove tries to remove and close any open connections for peers which are identifiable through the given ID.
 func (m *Manager) Remove(id string) error {
+	_, id = peer.ParseTransportAddress(id)
 	originAddr, err := iputils.ParseOriginAddress(id)
 	if err != nil {
 		return fmt.Errorf("%w: invalid peer address '%s'", err, id)
@@ -578,10 +864,10 @@ func (m *Manager) Remove(id string) error {
 			// close the connection of the peer and remove it from the connected pool
 			if p, exists := m.connected[otherID]; exists {
 				p.MoveBackToReconnectPool = false
-				delete(m.connected, otherID)
+				m.removeConnected(otherID)
 				p.Disconnected = true
 				if p.Protocol != nil && p.Conn != nil {
-					_ = p.Conn.Close()
+					go m.closeGracefully(p)
 				}
 				m.Events.PeerDisconnected.Trigger(p)
 			}
@@ -604,8 +890,8 @@ func (m *Manager) Remove(id string) error {
 		}
 		p.Disconnected = true
 		p.MoveBackToReconnectPool = false
-		delete(m.connected, id)
-		_ = p.Conn.Close()
+		m.removeConnected(id)
+		go m.closeGracefully(p)
 		m.Events.PeerDisconnected.Trigger(p)
 		delete(m.reconnect, p.ID)
 		m.WhitelistRemove(p.ID)
@@ -615,7 +901,18 @@ func (m *Manager) Remove(id string) error {
 	return nil
 }
 
-// Listen starts the peering server to listen for incoming connections.
+// closeGracefully closes the connection of the given peer once its send queues were drained,
+// so that queued milestones and request answers aren't lost if a peer is removed.
+// The peer must already be removed from the connected peers, so that no new messages are enqueued.
+func (m *Manager) closeGracefully(p *peer.Peer) {
+	if m.Opts.SendQueueDrainTimeout > 0 && p.Handshaked() && !p.DrainSendQueues(m.Opts.SendQueueDrainTimeout) {
+		m.metrics.sendQueueDrainTimeouts.Inc()
+		m.Events.Error.Trigger(fmt.Errorf("%w: %s, %d messages dropped", ErrSendQueueDrainTimeout, p.ID, len(p.SendQueue)+len(p.PrioritySendQueue)))
+	}
+	_ = p.Conn.Close()
+}
+
+// Listen starts the peering server and the additional listeners to listen for incoming connections.
 func (m *Manager) Listen() error {
 
 	// unfortunately we need to split the bind address as the TCP server API doesn't just use
@@ -634,37 +931,65 @@ func (m *Manager) Listen() error {
 	if err != nil {
 		return fmt.Errorf("%w: '%s' contains an invalid port", err, m.Opts.BindAddress)
 	}
+	m.serverSocketPort = uint16(port)
 
 	m.tcpServer.Events.Connect.Attach(events.NewClosure(func(conn *network.ManagedConnection) {
-		tcpConn := conn.RemoteAddr().(*net.TCPAddr)
-		if m.Blacklisted(tcpConn.IP.String()) {
-			if err := conn.Close(); err != nil {
-				log.Error(err)
-			}
-			return
-		}
-
-		m.Events.PeerHandshakingIncoming.Trigger(conn.RemoteAddr().String())
-
-		// init peer
-		p := peer.NewInboundPeer(conn.Conn.RemoteAddr())
-		p.Conn = conn
-		p.Conn.SetWriteTimeout(connectionWriteTimeout)
-		p.Protocol = protocol.New(conn)
-		m.SetupEventHandlers(p)
-
-		// kick off protocol
-		go p.Protocol.Start()
+		m.acceptInbound(conn, peer.TransportTCP)
 	}))
 
 	m.tcpServer.Events.Error.Attach(events.NewClosure(func(err error) {
 		m.Events.Error.Trigger(err)
 	}))
 
+	if err := m.listenAdditional(); err != nil {
+		return err
+	}
+
 	m.tcpServer.Listen(addr, port)
 	return nil
 }
 
+// acceptInbound initiates the handshake with the peer of the given inbound connection,
+// unless its IP address is refused or a resource limit is reached.
+func (m *Manager) acceptInbound(conn *network.ManagedConnection, transport peer.Transport) {
+	tcpConn := conn.RemoteAddr().(*net.TCPAddr)
+	if m.Blacklisted(tcpConn.IP.String()) {
+		if err := conn.Close(); err != nil {
+			log.Error(err)
+		}
+		return
+	}
+
+	if !m.gaterRules().AllowsIP(tcpConn.IP) {
+		m.metrics.gatedConnections.Inc()
+		if err := conn.Close(); err != nil {
+			log.Error(err)
+		}
+		return
+	}
+
+	release, err := m.reserveInbound(conn)
+	if err != nil {
+		if err := conn.Close(); err != nil {
+			log.Error(err)
+		}
+		return
+	}
+
+	m.Events.PeerHandshakingIncoming.Trigger(conn.RemoteAddr().String())
+
+	// init peer
+	p := peer.NewInboundPeer(conn.Conn.RemoteAddr())
+	p.Transport = transport
+	p.Conn = conn
+	p.Protocol = protocol.New(conn)
+	m.SetupEventHandlers(p)
+	releaseOnHandshakeOrClose(p, release)
+
+	// kick off protocol
+	go p.Protocol.Start()
+}
+
 // Shutdown shuts down the peering server and disconnect all connected peers.
 func (m *Manager) Shutdown() {
 	m.Lock()
@@ -673,6 +998,8 @@ func (m *Manager) Shutdown() {
 
 	// stop listening for incoming connections
 	m.tcpServer.Shutdown()
+	m.shutdownWebSocket()
+	m.shutdownListeners()
 
 	// clear reconnect entries
 	for k := range m.reconnect {
@@ -686,7 +1013,7 @@ func (m *Manager) Shutdown() {
 		// we don't care about errors while shutting down
 		_ = p.Conn.Close()
 		m.Events.PeerDisconnected.Trigger(p)
-		delete(m.connected, k)
+		m.removeConnected(k)
 	}
 
 	m.Events.Shutdown.Trigger()
@@ -694,7 +1021,7 @@ func (m *Manager) Shutdown() {
 
 // moves the given peer into the connected pool and removes any pending reconnects for it.
 func (m *Manager) moveToConnected(p *peer.Peer) {
-	m.connected[p.ID] = p
+	m.addConnected(p)
 	m.removeFromReconnectPool(p)
 }
 
@@ -708,10 +1035,11 @@ func (m *Manager) moveFromReconnectPoolToHandshaking(p *peer.Peer) {
 // moves the given peer from connected to the reconnect pool.
 // and deletes any excess pending reconnects.
 func (m *Manager) moveFromConnectedToReconnectPool(p *peer.Peer) {
-	if _, ok := m.connected[p.ID]; !ok {
+	// the ID could already be used by another connection to the same peer
+	if connectedPeer, ok := m.connected[p.ID]; !ok || connectedPeer != p {
 		return
 	}
-	delete(m.connected, p.ID)
+	m.removeConnected(p.ID)
 
 	// prevent non handshaked, manually removed or autopeering peers to be put back into the reconnect pool
 	if !p.MoveBackToReconnectPool || p.Autopeering != nil {
@@ -721,7 +1049,7 @@ func (m *Manager) moveFromConnectedToReconnectPool(p *peer.Peer) {
 	// remove any other excess reconnect entry
 	m.removeFromReconnectPool(p)
 
-	m.reconnect[p.InitAddress.String()] = &reconnectinfo{OriginAddr: p.InitAddress, CachedIPs: p.Addresses}
+	m.reconnect[p.InitAddress.String()] = &reconnectinfo{OriginAddr: p.InitAddress, CachedIPs: p.Addresses, Transport: p.Transport}
 	m.Events.PeerMovedFromConnectedToReconnectPool.Trigger(p)
 }
 
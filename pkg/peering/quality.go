@@ -0,0 +1,116 @@
+package peering
+
+import (
+	"time"
+
+	"github.com/gohornet/hornet/pkg/peering/peer"
+)
+
+const (
+	// QualitySampleInterval is the interval at which the connection quality of the peers is sampled.
+	QualitySampleInterval = 15 * time.Minute
+	// QualityHistoryLength is the amount of quality samples kept per peer (24h).
+	QualityHistoryLength = int(24 * time.Hour / QualitySampleInterval)
+)
+
+// the sampled connection quality of a peer.
+type qualityHistory struct {
+	// the sampled total quality scores, oldest sample first.
+	scores []float64
+	// whether the peer was connected at the time of the sample.
+	connected []bool
+}
+
+// returns the percentage of samples in which the peer was connected.
+// if there are no samples yet, the current connection state is used.
+func (h *qualityHistory) uptime(connected bool) float64 {
+	if h == nil || len(h.connected) == 0 {
+		if connected {
+			return 1
+		}
+		return 0
+	}
+
+	var connectedSamples int
+	for _, c := range h.connected {
+		if c {
+			connectedSamples++
+		}
+	}
+	return float64(connectedSamples) / float64(len(h.connected))
+}
+
+func (h *qualityHistory) add(score float64, connected bool) {
+	h.scores = append(h.scores, score)
+	h.connected = append(h.connected, connected)
+	if len(h.scores) > QualityHistoryLength {
+		h.scores = h.scores[len(h.scores)-QualityHistoryLength:]
+		h.connected = h.connected[len(h.connected)-QualityHistoryLength:]
+	}
+}
+
+// the key under which the quality history of a peer is stored.
+// the init address is used since it stays the same across reconnects.
+func qualityKey(p *peer.Peer) string {
+	return p.InitAddress.String()
+}
+
+// returns the quality of the given peer including its history.
+// qualityMu must be held while entering this function.
+func (m *Manager) qualityInfo(p *peer.Peer, connected bool) *peer.QualityInfo {
+	history := m.qualityHistory[qualityKey(p)]
+
+	var q *peer.QualityInfo
+	if connected {
+		q = p.Quality(history.uptime(true))
+	} else {
+		q = &peer.QualityInfo{Uptime: history.uptime(false)}
+	}
+
+	q.History = make([]float64, 0)
+	if history != nil {
+		q.History = append(q.History, history.scores...)
+	}
+	return q
+}
+
+// SampleQuality samples the connection quality of all known peers and adds it to their history.
+// Peers which are no longer known are removed from the history.
+func (m *Manager) SampleQuality() {
+	m.RLock()
+	defer m.RUnlock()
+
+	m.qualityMu.Lock()
+	defer m.qualityMu.Unlock()
+
+	known := make(map[string]struct{})
+	sample := func(p *peer.Peer, connected bool) {
+		key := qualityKey(p)
+		known[key] = struct{}{}
+
+		history, exists := m.qualityHistory[key]
+		if !exists {
+			history = &qualityHistory{}
+			m.qualityHistory[key] = history
+		}
+
+		var score float64
+		if connected {
+			score = p.Quality(history.uptime(true)).Score
+		}
+		history.add(score, connected)
+	}
+
+	for _, p := range m.connected {
+		sample(p, true)
+	}
+	for _, reconnectInfo := range m.reconnect {
+		sample(&peer.Peer{InitAddress: reconnectInfo.OriginAddr}, false)
+	}
+
+	for key := range m.qualityHistory {
+		if _, exists := known[key]; !exists {
+			delete(m.qualityHistory, key)
+		}
+	}
+}
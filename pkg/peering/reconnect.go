@@ -2,6 +2,7 @@ package peering
 
 import (
 	"fmt"
+	"math/rand"
 	"net"
 	"time"
 
@@ -14,6 +15,18 @@ import (
 	"github.com/gohornet/hornet/pkg/protocol"
 )
 
+const (
+	// ConnectRetryMaxAttempts is the amount of attempts to connect to a peer before it is moved back into the reconnect pool.
+	ConnectRetryMaxAttempts = 5
+	// ConnectRetryBaseDelay is the delay before the first retry to connect to a peer, it doubles with every further retry.
+	ConnectRetryBaseDelay = 1 * time.Second
+	// ConnectRetryMaxDelay is the maximum delay between two attempts to connect to a peer.
+	ConnectRetryMaxDelay = 16 * time.Second
+	// ConnectRetryJitter is the maximum fraction by which the delay between two attempts is randomly shortened or extended,
+	// so that the retries of several peers don't happen at the same time.
+	ConnectRetryJitter = 0.2
+)
+
 // Reconnect instructs the manager to initiate connections to all peers residing in the reconnect pool.
 func (m *Manager) Reconnect() {
 	m.Lock()
@@ -52,6 +65,12 @@ next:
 				delete(m.reconnect, k)
 				continue next
 			}
+
+			// banned peers stay in the reconnect pool until their ban expired,
+			// peers refused by the gater until its rules changed
+			if m.Banned(id) || !m.gaterRules().AllowsIP(*ip) || !m.gaterRules().AllowsPeer(id) {
+				continue next
+			}
 			ips = append(ips, ip.String())
 		}
 
@@ -63,6 +82,7 @@ next:
 		if reconnectInfo.Autopeering != nil {
 			p.Autopeering = reconnectInfo.Autopeering
 		}
+		p.Transport = reconnectInfo.Transport
 		peersToConnectTo = append(peersToConnectTo, p)
 	}
 	m.Unlock()
@@ -77,29 +97,89 @@ next:
 			m.Events.AutopeeredPeerHandshaking.Trigger(p)
 		}
 
-		if err := m.connect(p); err != nil {
-			m.Events.Error.Trigger(err)
-			m.Lock()
-			m.moveFromConnectedToReconnectPool(p)
-			m.Unlock()
-			continue
+		// the connection attempts are retried in the background, so a failing peer doesn't delay the others
+		go func(p *peer.Peer) {
+			if err := m.connectWithRetry(p); err != nil {
+				m.Events.Error.Trigger(err)
+				m.Lock()
+				m.moveFromConnectedToReconnectPool(p)
+				m.Unlock()
+				return
+			}
+
+			m.SetupEventHandlers(p)
+
+			// kicks of the protocol by sending the handshake packet and then reading inbound data
+			p.Protocol.Start()
+		}(p)
+	}
+}
+
+// returns the delay before the given retry (starting at 0) to connect to a peer.
+// the delay grows exponentially up to ConnectRetryMaxDelay and is randomly shortened or extended by up to ConnectRetryJitter.
+func connectRetryDelay(retry int) time.Duration {
+	delay := ConnectRetryMaxDelay
+	if retry < 32 && ConnectRetryBaseDelay<<uint(retry) < ConnectRetryMaxDelay {
+		delay = ConnectRetryBaseDelay << uint(retry)
+	}
+
+	jitter := (rand.Float64()*2 - 1) * ConnectRetryJitter
+	return time.Duration(float64(delay) * (1 + jitter))
+}
+
+// tells whether the given peer is still handshaking, i.e. it wasn't removed in the meantime and the manager isn't shut down.
+func (m *Manager) isHandshaking(p *peer.Peer) bool {
+	m.RLock()
+	defer m.RUnlock()
+
+	connectedPeer, ok := m.connected[p.ID]
+	return ok && connectedPeer == p && !p.Disconnected && !m.shutdown.Load()
+}
+
+// creates the connection to the given peer. failed attempts are retried with an exponential backoff
+// as long as the peer is still handshaking, at most ConnectRetryMaxAttempts times in total.
+func (m *Manager) connectWithRetry(p *peer.Peer) error {
+	for retry := 0; ; retry++ {
+		err := m.connect(p)
+		if err == nil {
+			return nil
+		}
+		m.metrics.connectFailures.Inc()
+
+		retrying := retry+1 < ConnectRetryMaxAttempts
+		m.Events.ConnectFailed.Trigger(&ConnectFailure{
+			PeerID:   p.ID,
+			Alias:    p.Alias(),
+			Attempt:  retry + 1,
+			Retrying: retrying,
+			Reason:   classifyConnectError(err),
+			Err:      err,
+		})
+
+		if !retrying {
+			return err
 		}
 
-		m.SetupEventHandlers(p)
+		delay := connectRetryDelay(retry)
+		m.Events.Error.Trigger(fmt.Errorf("%w, retrying in %v", err, delay.Truncate(time.Millisecond)))
+		time.Sleep(delay)
 
-		// kicks of the protocol by sending the handshake packet and then reading inbound data
-		go p.Protocol.Start()
+		if !m.isHandshaking(p) {
+			return err
+		}
 	}
 }
 
 // adds the given peers to the reconnect pool.
 func (m *Manager) moveInitialPeersToReconnectPool(peers []*config.PeerConfig) {
 	for _, peerConf := range peers {
-		if peerConf.ID == "" {
+		// backup peers are only added while their primary is unreachable
+		if peerConf.ID == "" || peerConf.BackupFor != "" {
 			continue
 		}
 
-		originAddr, err := iputils.ParseOriginAddress(peerConf.ID)
+		transport, addr := peer.ParseTransportAddress(peerConf.ID)
+		originAddr, err := iputils.ParseOriginAddress(addr)
 		if err != nil {
 			panic(errors.Wrapf(err, "invalid peer address %s", peerConf.ID))
 		}
@@ -108,20 +188,28 @@ func (m *Manager) moveInitialPeersToReconnectPool(peers []*config.PeerConfig) {
 		originAddr.Alias = peerConf.Alias
 
 		// no need to lock the manager in the configure stage
-		m.moveToReconnectPool(&reconnectinfo{OriginAddr: originAddr})
+		m.moveToReconnectPool(&reconnectinfo{OriginAddr: originAddr, Transport: transport})
 	}
 }
 
 // creates and initiates the connection to the given peer.
 func (m *Manager) connect(p *peer.Peer) error {
-	addr := fmt.Sprintf("%s:%d", iputils.IPToString(p.PrimaryAddress), p.InitAddress.Port)
-	conn, err := net.DialTimeout("tcp", addr, time.Duration(2)*time.Second)
+	ts := time.Now()
+
+	var conn net.Conn
+	var err error
+	if p.Transport.IsWebSocket() {
+		conn, err = m.dialWebSocket(p)
+	} else {
+		addr := fmt.Sprintf("%s:%d", iputils.IPToString(p.PrimaryAddress), p.InitAddress.Port)
+		conn, err = net.DialTimeout("tcp", addr, time.Duration(2)*time.Second)
+	}
 	if err != nil {
 		return fmt.Errorf("can't connect to %s: %w", p.ID, err)
 	}
+	p.ConnectLatency = time.Since(ts)
 
 	p.Conn = network.NewManagedConnection(conn)
-	p.Conn.SetWriteTimeout(connectionWriteTimeout)
 	p.Protocol = protocol.New(p.Conn)
 	return nil
 }
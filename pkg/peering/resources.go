@@ -0,0 +1,191 @@
+package peering
+
+import (
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/network"
+
+	"github.com/gohornet/hornet/pkg/peering/peer"
+)
+
+// Resource is a resource of the peering layer which is protected by a limit.
+type Resource string
+
+const (
+	// ResourcePendingInbound are the inbound connections which did not complete the handshake yet.
+	ResourcePendingInbound Resource = "pendingInbound"
+	// ResourceConnectionsPerIP are the connections from the same IP address.
+	ResourceConnectionsPerIP Resource = "connectionsPerIP"
+	// ResourceSendQueueMemory is the memory held in the send queue of a peer.
+	ResourceSendQueueMemory Resource = "sendQueueMemory"
+	// ResourceTotalSendQueueMemory is the memory held in the send queues of all peers together.
+	ResourceTotalSendQueueMemory Resource = "totalSendQueueMemory"
+	// ResourceInboundConnections are the connections of handshaked inbound peers.
+	ResourceInboundConnections Resource = "inboundConnections"
+	// ResourceOutboundConnections are the connections of handshaked outbound peers.
+	ResourceOutboundConnections Resource = "outboundConnections"
+)
+
+var (
+	// ErrResourceLimitReached is returned when a connection is refused because a resource limit was reached.
+	ErrResourceLimitReached = errors.New("resource limit reached")
+)
+
+// SendQueueWatermarks defines the amounts of messages in the send queue of a peer
+// at which the peer is considered congested and relieved again, so that the components enqueueing messages can back off.
+type SendQueueWatermarks struct {
+	// The fill level at which the send queue is considered congested (0 disables the watermarks).
+	High int
+	// The fill level at which a congested send queue is considered relieved.
+	Low int
+}
+
+// ResourceLimits defines the limits of the resources used by the peering layer. 0 disables a limit.
+type ResourceLimits struct {
+	// The max amount of inbound connections which are handshaking at the same time.
+	MaxPendingInbound int
+	// The max amount of connected and handshaking inbound peers with the same IP address.
+	MaxConnectionsPerIP int
+	// The max amount of bytes held in the send queue of a single peer.
+	MaxSendQueueMemoryBytes int64
+	// The max amount of bytes held in the send queues of all peers together.
+	MaxTotalSendQueueMemoryBytes int64
+	// The max amount of connected inbound peers. Known peers prune unknown peers if the limit is reached.
+	MaxInbound int
+	// The max amount of connected outbound peers. Known peers prune unknown peers if the limit is reached.
+	MaxOutbound int
+}
+
+// ResourceLimitReached describes a resource limit which clipped the connectivity of the node.
+type ResourceLimitReached struct {
+	// The resource which reached its limit.
+	Resource Resource
+	// The limit of the resource.
+	Limit int64
+	// The address of the remote, or the ID of the peer if it is already connected.
+	Remote string
+}
+
+func ResourceLimitReachedCaller(handler interface{}, params ...interface{}) {
+	handler.(func(*ResourceLimitReached))(params[0].(*ResourceLimitReached))
+}
+
+// reserveInbound checks the resource limits for a new inbound connection and reserves a pending inbound slot.
+// The returned release function must be called once the handshake completed or the connection was closed.
+func (m *Manager) reserveInbound(conn *network.ManagedConnection) (release func(), err error) {
+	limits := m.Opts.Limits
+	ip := conn.RemoteAddr().(*net.TCPAddr).IP
+
+	if limits.MaxConnectionsPerIP != 0 && m.connectionsFromIP(ip) >= limits.MaxConnectionsPerIP {
+		m.resourceLimitReached(ResourceConnectionsPerIP, int64(limits.MaxConnectionsPerIP), conn.RemoteAddr().String())
+		return nil, ErrResourceLimitReached
+	}
+
+	if limits.MaxPendingInbound != 0 && m.pendingInbound.Inc() > int32(limits.MaxPendingInbound) {
+		m.pendingInbound.Dec()
+		m.resourceLimitReached(ResourcePendingInbound, int64(limits.MaxPendingInbound), conn.RemoteAddr().String())
+		return nil, ErrResourceLimitReached
+	}
+
+	m.pendingInboundIPsMu.Lock()
+	m.pendingInboundIPs[ip.String()]++
+	m.pendingInboundIPsMu.Unlock()
+
+	var releaseOnce sync.Once
+	return func() {
+		releaseOnce.Do(func() {
+			if limits.MaxPendingInbound != 0 {
+				m.pendingInbound.Dec()
+			}
+
+			m.pendingInboundIPsMu.Lock()
+			defer m.pendingInboundIPsMu.Unlock()
+			if m.pendingInboundIPs[ip.String()]--; m.pendingInboundIPs[ip.String()] <= 0 {
+				delete(m.pendingInboundIPs, ip.String())
+			}
+		})
+	}, nil
+}
+
+// connectionsFromIP returns the amount of connected and handshaking inbound peers with the given IP address.
+func (m *Manager) connectionsFromIP(ip net.IP) int {
+	m.pendingInboundIPsMu.Lock()
+	count := m.pendingInboundIPs[ip.String()]
+	m.pendingInboundIPsMu.Unlock()
+
+	m.RLock()
+	defer m.RUnlock()
+	for _, p := range m.connected {
+		if p.IsInbound() && p.PrimaryAddress.Equal(ip) {
+			count++
+		}
+	}
+	return count
+}
+
+// applySendQueueLimit limits the memory of the send queue of the given peer and assigns it the budget of all peers.
+func (m *Manager) applySendQueueLimit(p *peer.Peer) {
+	if budget := m.sendQueueMemoryBudget; budget != nil {
+		p.SendQueueMemoryBudget = budget
+		p.Events.SendQueueMemoryBudgetExhausted.Attach(events.NewClosure(func() {
+			m.resourceLimitReached(ResourceTotalSendQueueMemory, budget.Limit(), p.ID)
+		}))
+		p.Conn.Events.Close.Attach(events.NewClosure(p.ReleaseSendQueueMemoryBudget))
+	}
+
+	limit := m.Opts.Limits.MaxSendQueueMemoryBytes
+	if limit == 0 {
+		return
+	}
+
+	p.SendQueueMemoryLimit = limit
+	p.Events.SendQueueMemoryExhausted.Attach(events.NewClosure(func() {
+		m.resourceLimitReached(ResourceSendQueueMemory, limit, p.ID)
+	}))
+}
+
+// applySendQueueOverflowPolicy sets the policy which defines the message dropped if the send queue of the given peer is full.
+func (m *Manager) applySendQueueOverflowPolicy(p *peer.Peer) {
+	p.SendQueueOverflowPolicy = m.Opts.SendQueueOverflowPolicy
+	p.SendQueueBlockTimeout = m.Opts.SendQueueBlockTimeout
+	p.Events.SendQueueMessageDropped.Attach(events.NewClosure(func() {
+		m.metrics.sendQueueDrops.Inc()
+		m.Events.SendQueueMessageDropped.Trigger(p)
+	}))
+}
+
+// applySendQueueWatermarks sets the fill levels at which the send queue of the given peer is considered congested and relieved.
+func (m *Manager) applySendQueueWatermarks(p *peer.Peer) {
+	if m.Opts.SendQueueWatermarks.High == 0 {
+		return
+	}
+
+	p.SendQueueHighWatermark = m.Opts.SendQueueWatermarks.High
+	p.SendQueueLowWatermark = m.Opts.SendQueueWatermarks.Low
+	p.Events.SendQueueHighWatermarkReached.Attach(events.NewClosure(func() {
+		m.Events.SendQueueCongested.Trigger(p)
+	}))
+	p.Events.SendQueueLowWatermarkReached.Attach(events.NewClosure(func() {
+		m.Events.SendQueueRelieved.Trigger(p)
+	}))
+}
+
+// releaseOnHandshakeOrClose calls the release function once the handshake of the peer completed or its connection was closed.
+func releaseOnHandshakeOrClose(p *peer.Peer, release func()) {
+	p.Protocol.Events.HandshakeCompleted.Attach(events.NewClosure(release))
+	p.Conn.Events.Close.Attach(events.NewClosure(release))
+}
+
+func (m *Manager) resourceLimitReached(resource Resource, limit int64, remote string) {
+	m.countResourceLimitReached(resource)
+	m.Events.ResourceLimitReached.Trigger(&ResourceLimitReached{Resource: resource, Limit: limit, Remote: remote})
+}
+
+// SendQueueMemory returns the amount of bytes held in the send queues of all peers together,
+// 0 if the memory isn't limited by MaxTotalSendQueueMemoryBytes.
+func (m *Manager) SendQueueMemory() int64 {
+	return m.sendQueueMemoryBudget.Used()
+}
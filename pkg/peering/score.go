@@ -0,0 +1,49 @@
+package peering
+
+import (
+	"time"
+
+	"github.com/gohornet/hornet/pkg/peering/peer"
+)
+
+const (
+	// ScoreCheckInterval is the interval at which the scores of the connected peers are checked.
+	ScoreCheckInterval = 30 * time.Second
+)
+
+// CheckScores computes the scores of all connected peers.
+// Peers with a score below the minimum score get deprioritized and a PeerScoreLow event is fired,
+// deprioritized peers which reached the minimum score again fire a PeerScoreRecovered event.
+func (m *Manager) CheckScores() {
+	if m.Opts.MinScore == 0 {
+		// scoring disabled
+		return
+	}
+
+	type scoredPeer struct {
+		peer  *peer.Peer
+		score *peer.ScoreInfo
+	}
+
+	var low, recovered []*scoredPeer
+	m.ForAllConnected(func(p *peer.Peer) bool {
+		score := p.Score()
+		switch {
+		case score.Score < m.Opts.MinScore && p.SetDeprioritized(true):
+			score.Deprioritized = true
+			low = append(low, &scoredPeer{peer: p, score: score})
+		case score.Score >= m.Opts.MinScore && p.SetDeprioritized(false):
+			score.Deprioritized = false
+			recovered = append(recovered, &scoredPeer{peer: p, score: score})
+		}
+		return true
+	})
+
+	// the events are fired outside of the lock so that handlers are able to remove the peers
+	for _, s := range low {
+		m.Events.PeerScoreLow.Trigger(s.peer, s.score)
+	}
+	for _, s := range recovered {
+		m.Events.PeerScoreRecovered.Trigger(s.peer, s.score)
+	}
+}
@@ -0,0 +1,135 @@
+package peering
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// StaticPeerCheckInterval is the interval in which the static peers are checked for outages.
+	StaticPeerCheckInterval = 10 * time.Second
+)
+
+// UnreachablePeer is a static peer which couldn't be connected to for longer than the alert threshold.
+type UnreachablePeer struct {
+	// The address the peer was added with.
+	Address string `json:"address"`
+	// The alias of the peer.
+	Alias string `json:"alias,omitempty"`
+	// The time since which the peer is unreachable.
+	Since time.Time `json:"since"`
+}
+
+// Name returns the alias of the peer along with its address, or only its address if the peer has no alias.
+func (u *UnreachablePeer) Name() string {
+	if u.Alias != "" {
+		return fmt.Sprintf("%s (%s)", u.Alias, u.Address)
+	}
+	return u.Address
+}
+
+// UnreachablePeerCaller is the caller of the StaticPeerUnreachable and StaticPeerReachable events.
+func UnreachablePeerCaller(handler interface{}, params ...interface{}) {
+	handler.(func(*UnreachablePeer))(params[0].(*UnreachablePeer))
+}
+
+// the unreachability of a static peer in the reconnect pool.
+type staticPeerOutage struct {
+	peer *UnreachablePeer
+	// whether the StaticPeerUnreachable event was fired for the outage.
+	alerted bool
+}
+
+// CheckStaticPeers fires the StaticPeerUnreachable event for the static peers which stayed in the reconnect pool
+// for longer than the alert threshold, and the StaticPeerReachable event once such a peer is connected again.
+// Each outage is only alerted once.
+func (m *Manager) CheckStaticPeers() {
+	if m.Opts.StaticPeerAlertThreshold == 0 || m.shutdown.Load() {
+		return
+	}
+
+	// the static peers in the reconnect pool and the addresses of the connected peers
+	inReconnectPool := make(map[string]*UnreachablePeer)
+	connected := make(map[string]struct{})
+
+	m.RLock()
+	for key, reconnectInfo := range m.reconnect {
+		if reconnectInfo.Autopeering != nil {
+			continue
+		}
+		inReconnectPool[key] = &UnreachablePeer{Address: key, Alias: reconnectInfo.OriginAddr.Alias}
+	}
+	for _, p := range m.connected {
+		if p.InitAddress != nil {
+			connected[p.InitAddress.String()] = struct{}{}
+		}
+	}
+	m.RUnlock()
+
+	var unreachable, reachable []*UnreachablePeer
+
+	m.staticOutagesMu.Lock()
+	for key, outage := range m.staticOutages {
+		if _, stillUnreachable := inReconnectPool[key]; stillUnreachable {
+			continue
+		}
+		delete(m.staticOutages, key)
+
+		// removed peers are no longer of interest
+		if _, isConnected := connected[key]; isConnected && outage.alerted {
+			reachable = append(reachable, outage.peer)
+		}
+	}
+
+	for key, unreachablePeer := range inReconnectPool {
+		outage, exists := m.staticOutages[key]
+		if !exists {
+			unreachablePeer.Since = m.lastDisconnected(key)
+			outage = &staticPeerOutage{peer: unreachablePeer}
+			m.staticOutages[key] = outage
+		}
+
+		if !outage.alerted && time.Since(outage.peer.Since) >= m.Opts.StaticPeerAlertThreshold {
+			outage.alerted = true
+			unreachable = append(unreachable, outage.peer)
+		}
+	}
+	m.staticOutagesMu.Unlock()
+
+	for _, unreachablePeer := range unreachable {
+		m.Events.StaticPeerUnreachable.Trigger(unreachablePeer)
+	}
+	for _, reachablePeer := range reachable {
+		m.Events.StaticPeerReachable.Trigger(reachablePeer)
+	}
+}
+
+// returns the time the peer with the given init address was last disconnected,
+// or the current time if it wasn't connected since the node was started.
+func (m *Manager) lastDisconnected(key string) time.Time {
+	m.connHistoriesMu.Lock()
+	defer m.connHistoriesMu.Unlock()
+
+	if history, exists := m.connHistories[key]; exists && !history.lastDisconnected.IsZero() {
+		return history.lastDisconnected
+	}
+	return time.Now()
+}
+
+// UnreachableStaticPeers returns the static peers which are unreachable for longer than the alert threshold.
+func (m *Manager) UnreachableStaticPeers() []*UnreachablePeer {
+	m.staticOutagesMu.Lock()
+	defer m.staticOutagesMu.Unlock()
+
+	var unreachable []*UnreachablePeer
+	for _, outage := range m.staticOutages {
+		if outage.alerted {
+			unreachable = append(unreachable, outage.peer)
+		}
+	}
+	sort.Slice(unreachable, func(i, j int) bool {
+		return unreachable[i].Address < unreachable[j].Address
+	})
+	return unreachable
+}
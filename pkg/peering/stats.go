@@ -0,0 +1,53 @@
+package peering
+
+import (
+	"github.com/gohornet/hornet/pkg/peering/peer"
+)
+
+// Stats holds the aggregated statistics of the streams to all connected peers.
+type Stats struct {
+	// The amount of established streams.
+	Streams int `json:"streams"`
+	// The amount of bytes received over all streams.
+	BytesIn uint64 `json:"bytesIn"`
+	// The amount of bytes sent over all streams.
+	BytesOut uint64 `json:"bytesOut"`
+	// The amount of messages received over all streams.
+	MessagesIn uint64 `json:"messagesIn"`
+	// The amount of messages sent over all streams.
+	MessagesOut uint64 `json:"messagesOut"`
+	// The amount of messages in the send queues of all peers.
+	SendQueueLength int `json:"sendQueueLength"`
+	// The highest send queue fill level of all peers.
+	MaxSendQueueFill float64 `json:"maxSendQueueFill"`
+	// The statistics of the streams per peer ID.
+	Peers map[string]*peer.StreamStats `json:"peers"`
+}
+
+// Stats returns the aggregated statistics of the streams to the connected peers.
+func (m *Manager) Stats() *Stats {
+	stats := &Stats{
+		Peers: make(map[string]*peer.StreamStats),
+	}
+
+	m.ForAllConnected(func(p *peer.Peer) bool {
+		streamStats := p.StreamStats()
+		if streamStats == nil {
+			return true
+		}
+
+		stats.Streams++
+		stats.BytesIn += streamStats.BytesIn
+		stats.BytesOut += streamStats.BytesOut
+		stats.MessagesIn += streamStats.MessagesIn
+		stats.MessagesOut += streamStats.MessagesOut
+		stats.SendQueueLength += streamStats.SendQueueLength
+		if streamStats.SendQueueFill > stats.MaxSendQueueFill {
+			stats.MaxSendQueueFill = streamStats.SendQueueFill
+		}
+		stats.Peers[p.ID] = streamStats
+		return true
+	})
+
+	return stats
+}
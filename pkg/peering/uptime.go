@@ -0,0 +1,125 @@
+package peering
+
+import (
+	"time"
+
+	"github.com/gohornet/hornet/pkg/peering/peer"
+)
+
+// the connections of a peer since the node was started.
+type connectionHistory struct {
+	// the peer of the current connection, nil if the peer isn't connected.
+	current *peer.Peer
+	// the time the current connection was established.
+	connectedSince time.Time
+	// the time the last connection was closed.
+	lastDisconnected time.Time
+	// the uptime of the closed connections.
+	closedUptime time.Duration
+	// the amount of established connections.
+	connections int
+	// the smoothed and the last ping latency of the last closed connection.
+	latency     time.Duration
+	lastLatency time.Duration
+}
+
+// the key under which the connection history of a peer is stored.
+// the init address is used since it stays the same across reconnects.
+func connectionHistoryKey(p *peer.Peer) string {
+	return p.InitAddress.String()
+}
+
+// adds the handshaked connection of the given peer to its connection history.
+func (m *Manager) recordConnected(p *peer.Peer) {
+	m.connHistoriesMu.Lock()
+	defer m.connHistoriesMu.Unlock()
+
+	key := connectionHistoryKey(p)
+	history, exists := m.connHistories[key]
+	if !exists {
+		history = &connectionHistory{}
+		m.connHistories[key] = history
+	}
+
+	history.current = p
+	history.connectedSince = time.Now()
+	history.connections++
+}
+
+// ends the connection of the given handshaked peer in its connection history and returns the resulting statistics.
+// the history is removed if the peer isn't moved back into the reconnect pool.
+// returns nil if the connection of the peer isn't the current one, e.g. because it was a duplicate.
+func (m *Manager) recordDisconnected(p *peer.Peer) *peer.ConnectionStats {
+	m.connHistoriesMu.Lock()
+	defer m.connHistoriesMu.Unlock()
+
+	key := connectionHistoryKey(p)
+	history, exists := m.connHistories[key]
+	if !exists || history.current != p {
+		return nil
+	}
+
+	now := time.Now()
+	history.closedUptime += now.Sub(history.connectedSince)
+	history.lastDisconnected = now
+	history.latency = p.Latency()
+	history.lastLatency = p.LastLatency()
+	history.current = nil
+
+	stats := history.stats()
+	if !p.MoveBackToReconnectPool {
+		delete(m.connHistories, key)
+	}
+	return stats
+}
+
+// returns the statistics of the connection history.
+// the latency of the current connection is used if the peer is connected, otherwise the one of the last connection.
+func (h *connectionHistory) stats() *peer.ConnectionStats {
+	uptime, latency, lastLatency := h.closedUptime, h.latency, h.lastLatency
+	if h.current != nil {
+		uptime += time.Since(h.connectedSince)
+		latency, lastLatency = h.current.Latency(), h.current.LastLatency()
+	}
+
+	stats := &peer.ConnectionStats{
+		TotalUptimeSeconds: int64(uptime / time.Second),
+		Connections:        h.connections,
+		LatencyMs:          float64(latency) / float64(time.Millisecond),
+		LastLatencyMs:      float64(lastLatency) / float64(time.Millisecond),
+	}
+	if h.connections > 1 {
+		stats.Reconnects = h.connections - 1
+	}
+	if h.current != nil {
+		stats.ConnectedSince = h.connectedSince.Unix()
+	}
+	if !h.lastDisconnected.IsZero() {
+		stats.LastDisconnected = h.lastDisconnected.Unix()
+	}
+	return stats
+}
+
+// ConnectionStats returns the uptime, the reconnects and the latency of the given peer since the node was started.
+// Returns nil if the peer was never connected.
+func (m *Manager) ConnectionStats(p *peer.Peer) *peer.ConnectionStats {
+	if p.InitAddress == nil {
+		return nil
+	}
+
+	m.connHistoriesMu.Lock()
+	defer m.connHistoriesMu.Unlock()
+
+	history, exists := m.connHistories[connectionHistoryKey(p)]
+	if !exists {
+		return nil
+	}
+	return history.stats()
+}
+
+// RecordLatency adds the given round trip time of a ping to the latency of the given peer
+// and fires the PeerLatencyMeasured event.
+func (m *Manager) RecordLatency(p *peer.Peer, rtt time.Duration) {
+	p.RecordLatency(rtt)
+	m.Events.PeerLatencyMeasured.Trigger(p, rtt)
+}
@@ -0,0 +1,186 @@
+package peering
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/iotaledger/hive.go/network"
+
+	"github.com/gohornet/hornet/pkg/peering/peer"
+)
+
+const (
+	// DefaultWebSocketPath is the HTTP path under which the WebSocket connections are upgraded.
+	DefaultWebSocketPath = "/gossip"
+
+	webSocketHandshakeTimeout = 2 * time.Second
+	webSocketShutdownTimeout  = 2 * time.Second
+)
+
+// WebSocket defines the listener for the WebSocket connections of peers, e.g. of nodes behind proxies which only allow HTTP(S).
+type WebSocket struct {
+	// The bind address of the WebSocket listener (empty = disabled).
+	BindAddress string
+	// The HTTP path under which the connections are upgraded.
+	Path string
+	// The paths to the TLS certificate and key, the listener accepts secure WebSocket connections (WSS) if both are set.
+	TLSCertPath string
+	TLSKeyPath  string
+}
+
+// the transport of the connections accepted by the listener.
+func (ws WebSocket) transport() peer.Transport {
+	if ws.TLSCertPath != "" && ws.TLSKeyPath != "" {
+		return peer.TransportWebSocketSecure
+	}
+	return peer.TransportWebSocket
+}
+
+// the path under which the connections are upgraded.
+func (ws WebSocket) path() string {
+	if ws.Path == "" {
+		return DefaultWebSocketPath
+	}
+	return ws.Path
+}
+
+// wsConn streams the data of binary WebSocket messages, so that a WebSocket connection can be used like a TCP connection.
+type wsConn struct {
+	*websocket.Conn
+	reader  io.Reader
+	readMu  sync.Mutex
+	writeMu sync.Mutex
+}
+
+// Read reads the data of the received messages across message boundaries.
+func (c *wsConn) Read(b []byte) (int, error) {
+	c.readMu.Lock()
+	defer c.readMu.Unlock()
+
+	for {
+		if c.reader == nil {
+			_, reader, err := c.NextReader()
+			if err != nil {
+				return 0, err
+			}
+			c.reader = reader
+		}
+
+		n, err := c.reader.Read(b)
+		if errors.Is(err, io.EOF) {
+			c.reader = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+// Write sends the given data as a binary message.
+func (c *wsConn) Write(b []byte) (int, error) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if err := c.WriteMessage(websocket.BinaryMessage, b); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// SetDeadline sets the read and write deadline of the connection.
+func (c *wsConn) SetDeadline(t time.Time) error {
+	if err := c.SetReadDeadline(t); err != nil {
+		return err
+	}
+	return c.SetWriteDeadline(t)
+}
+
+// ListenWebSocket starts the listener for the WebSocket connections of peers, if a bind address is set.
+// The connections are handled like the inbound TCP connections once they are upgraded.
+func (m *Manager) ListenWebSocket() error {
+	opts := m.Opts.WebSocket
+	if opts.BindAddress == "" {
+		return nil
+	}
+
+	ln, err := net.Listen("tcp", opts.BindAddress)
+	if err != nil {
+		return err
+	}
+
+	m.webSocketServer = &http.Server{Handler: m.webSocketHandler(opts.transport())}
+	m.addListener(&listener{transport: opts.transport(), addr: ln.Addr()})
+
+	return m.serveWebSocket(m.webSocketServer, ln, opts.transport())
+}
+
+// returns the handler which upgrades the WebSocket connections of peers under the configured path.
+func (m *Manager) webSocketHandler(transport peer.Transport) http.Handler {
+	upgrader := websocket.Upgrader{
+		HandshakeTimeout: webSocketHandshakeTimeout,
+		// peers don't send an origin, browser-based clients are accepted from any origin
+		CheckOrigin: func(_ *http.Request) bool { return true },
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(m.Opts.WebSocket.path(), func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// the upgrader already replied with an error
+			return
+		}
+		m.acceptInbound(network.NewManagedConnection(&wsConn{Conn: conn}), transport)
+	})
+	return mux
+}
+
+// serves the WebSocket connections of the given listener, secured by the configured TLS certificate for WSS.
+func (m *Manager) serveWebSocket(server *http.Server, ln net.Listener, transport peer.Transport) error {
+	if transport == peer.TransportWebSocketSecure {
+		return server.ServeTLS(ln, m.Opts.WebSocket.TLSCertPath, m.Opts.WebSocket.TLSKeyPath)
+	}
+	return server.Serve(ln)
+}
+
+// stops the listener for the WebSocket connections.
+func (m *Manager) shutdownWebSocket() {
+	if m.webSocketServer == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webSocketShutdownTimeout)
+	defer cancel()
+	_ = m.webSocketServer.Shutdown(ctx)
+}
+
+// dials the WebSocket connection to the given peer.
+// The connection is established through the proxy defined by the environment, if any.
+func (m *Manager) dialWebSocket(p *peer.Peer) (net.Conn, error) {
+	// secure connections are established to the domain, so that its certificate can be verified
+	host := p.PrimaryAddress.String()
+	if p.Transport == peer.TransportWebSocketSecure {
+		host = p.InitAddress.Addr
+	}
+
+	dialer := &websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: webSocketHandshakeTimeout,
+	}
+
+	url := fmt.Sprintf("%s://%s%s", p.Transport, net.JoinHostPort(host, strconv.Itoa(int(p.InitAddress.Port))), m.Opts.WebSocket.path())
+	conn, _, err := dialer.Dial(url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &wsConn{Conn: conn}, nil
+}
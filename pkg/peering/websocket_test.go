@@ -0,0 +1,61 @@
+package peering
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/peering/peer"
+)
+
+// returns whether the given manager listens for WebSocket connections.
+func listensWebSocket(m *Manager) bool {
+	for _, addr := range m.ListenAddresses() {
+		if addr.Transport == peer.TransportWebSocket {
+			return true
+		}
+	}
+	return false
+}
+
+func TestWebSocketHandshake(t *testing.T) {
+	portA, portB, webSocketPortA := freePort(t), freePort(t), freePort(t)
+
+	a := newListeningManager(t, portA)
+	defer a.Shutdown()
+	a.Opts.WebSocket = WebSocket{BindAddress: fmt.Sprintf("127.0.0.1:%d", webSocketPortA)}
+	go func() {
+		if err := a.ListenWebSocket(); err != http.ErrServerClosed {
+			assert.NoError(t, err)
+		}
+	}()
+	require.Eventually(t, func() bool { return listensWebSocket(a) }, 5*time.Second, 10*time.Millisecond)
+
+	b := newListeningManager(t, portB)
+	defer b.Shutdown()
+
+	// the port of the WebSocket listener is dialed instead of the server socket port
+	require.NoError(t, b.Add(fmt.Sprintf("ws://127.0.0.1:%d", webSocketPortA), false, "a"))
+	require.Eventually(t, func() bool { return len(handshakedPeers(a)) == 1 && len(handshakedPeers(b)) == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	peerOfA, peerOfB := handshakedPeers(a)[0], handshakedPeers(b)[0]
+	assert.Equal(t, peer.TransportWebSocket, peerOfA.Transport)
+	assert.Equal(t, peer.TransportWebSocket, peerOfB.Transport)
+
+	// the inbound peer is identified by the server socket port of its handshake
+	assert.Equal(t, fmt.Sprintf("127.0.0.1:%d", portB), peerOfA.ID)
+	assert.Equal(t, fmt.Sprintf("127.0.0.1:%d", webSocketPortA), peerOfB.ID)
+
+	// the connection stays up after the handshake
+	time.Sleep(200 * time.Millisecond)
+	assert.Len(t, handshakedPeers(a), 1)
+	assert.Len(t, handshakedPeers(b), 1)
+
+	// the WebSocket connections are closed on shutdown
+	a.Shutdown()
+	require.Eventually(t, func() bool { return len(handshakedPeers(b)) == 0 }, 5*time.Second, 10*time.Millisecond)
+}
@@ -11,6 +11,12 @@ import (
 	"github.com/gohornet/hornet/pkg/config"
 )
 
+const (
+	// PermanodeProfileName is the name of the profile which runs the node as a permanode.
+	// It is never selected automatically.
+	PermanodeProfileName = "permanode"
+)
+
 var (
 	once    = sync.Once{}
 	profile *Profile
@@ -55,6 +61,9 @@ func LoadProfile() *Profile {
 		case "1gb", "light":
 			profile = Profile1GB
 			profile.Name = "1gb"
+		case PermanodeProfileName:
+			profile = ProfilePermanode
+			profile.Name = PermanodeProfileName
 		default:
 			p := &Profile{}
 			if !config.ProfilesConfig.IsSet(profileName) {
@@ -155,6 +164,95 @@ var Profile8GB = &Profile{
 	},
 }
 
+// ProfilePermanode is the profile of a node which keeps the whole history of the tangle.
+// The transactions and bundles are cached for a shorter time, since scans over the history would flush them anyway,
+// while the approvers and milestones, which are needed to walk the history, are kept longer.
+var ProfilePermanode = &Profile{
+	Permanode: true,
+	Caches: Caches{
+		Addresses: CacheOpts{
+			CacheTimeMs: 2000,
+			LeakDetectionOptions: LeakDetectionOpts{
+				Enabled:                false,
+				MaxConsumersPerObject:  20,
+				MaxConsumerHoldTimeSec: 100,
+			},
+		},
+		Approvers: CacheOpts{
+			CacheTimeMs: 60000,
+			LeakDetectionOptions: LeakDetectionOpts{
+				Enabled:                false,
+				MaxConsumersPerObject:  20,
+				MaxConsumerHoldTimeSec: 100,
+			},
+		},
+		Tags: CacheOpts{
+			CacheTimeMs: 2000,
+			LeakDetectionOptions: LeakDetectionOpts{
+				Enabled:                false,
+				MaxConsumersPerObject:  20,
+				MaxConsumerHoldTimeSec: 100,
+			},
+		},
+		Bundles: CacheOpts{
+			CacheTimeMs: 5000,
+			LeakDetectionOptions: LeakDetectionOpts{
+				Enabled:                false,
+				MaxConsumersPerObject:  20,
+				MaxConsumerHoldTimeSec: 100,
+			},
+		},
+		BundleTransactions: CacheOpts{
+			CacheTimeMs: 5000,
+			LeakDetectionOptions: LeakDetectionOpts{
+				Enabled:                false,
+				MaxConsumersPerObject:  20,
+				MaxConsumerHoldTimeSec: 100,
+			},
+		},
+		Milestones: CacheOpts{
+			CacheTimeMs: 60000,
+			LeakDetectionOptions: LeakDetectionOpts{
+				Enabled:                false,
+				MaxConsumersPerObject:  20,
+				MaxConsumerHoldTimeSec: 100,
+			},
+		},
+		Transactions: CacheOpts{
+			CacheTimeMs: 5000,
+			LeakDetectionOptions: LeakDetectionOpts{
+				Enabled:                false,
+				MaxConsumersPerObject:  20,
+				MaxConsumerHoldTimeSec: 100,
+			},
+		},
+		UnconfirmedTx: CacheOpts{
+			CacheTimeMs: 500,
+			LeakDetectionOptions: LeakDetectionOpts{
+				Enabled:                false,
+				MaxConsumersPerObject:  20,
+				MaxConsumerHoldTimeSec: 100,
+			},
+		},
+		IncomingTransactionFilter: CacheOpts{
+			CacheTimeMs: 5000,
+			LeakDetectionOptions: LeakDetectionOpts{
+				Enabled:                false,
+				MaxConsumersPerObject:  20,
+				MaxConsumerHoldTimeSec: 100,
+			},
+		},
+		SpentAddresses: CacheOpts{
+			CacheTimeMs: 0,
+			LeakDetectionOptions: LeakDetectionOpts{
+				Enabled:                false,
+				MaxConsumersPerObject:  20,
+				MaxConsumerHoldTimeSec: 100,
+			},
+		},
+	},
+}
+
 var Profile4GB = &Profile{
 	Caches: Caches{
 		Addresses: CacheOpts{
@@ -411,8 +509,10 @@ var Profile1GB = &Profile{
 }
 
 type Profile struct {
-	Name   string `mapstructure:"name"`
-	Caches Caches `mapstructure:"caches"`
+	Name string `mapstructure:"name"`
+	// Permanode disables the pruning of the database, so the node keeps the whole history of the tangle.
+	Permanode bool   `mapstructure:"permanode"`
+	Caches    Caches `mapstructure:"caches"`
 }
 
 type Caches struct {
@@ -0,0 +1,61 @@
+package protocol
+
+import (
+	"sync"
+	"time"
+)
+
+// a token bucket which limits the bandwidth of a stream.
+// the bucket holds up to one second worth of bytes, so short bursts are not delayed.
+type bandwidthLimiter struct {
+	sync.Mutex
+	// the allowed bytes per second (0 = unlimited).
+	bytesPerSecond float64
+	// the bytes which may currently pass without waiting, negative if the stream is in debt.
+	tokens float64
+	// the time the tokens were last refilled.
+	lastRefill time.Time
+}
+
+// sets the allowed bytes per second of the limiter (0 = unlimited).
+func (l *bandwidthLimiter) setLimit(bytesPerSecond int) {
+	l.Lock()
+	defer l.Unlock()
+
+	l.bytesPerSecond = float64(bytesPerSecond)
+	l.tokens = l.bytesPerSecond
+	l.lastRefill = time.Now()
+}
+
+// blocks until the given amount of bytes may pass the limiter.
+// bytes exceeding the available tokens are passed as soon as the tokens were refilled,
+// which allows messages bigger than the bucket.
+func (l *bandwidthLimiter) wait(bytes int) {
+	l.Lock()
+	if l.bytesPerSecond == 0 {
+		l.Unlock()
+		return
+	}
+
+	now := time.Now()
+	l.tokens += now.Sub(l.lastRefill).Seconds() * l.bytesPerSecond
+	if l.tokens > l.bytesPerSecond {
+		l.tokens = l.bytesPerSecond
+	}
+	l.lastRefill = now
+
+	l.tokens -= float64(bytes)
+	delay := time.Duration(-l.tokens / l.bytesPerSecond * float64(time.Second))
+	l.Unlock()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+// SetBandwidthLimits sets the bytes per second which may be sent and received over the stream (0 = unlimited).
+// Exceeding the download limit delays reading from the stream, which in turn throttles the sending peer.
+func (p *Protocol) SetBandwidthLimits(uploadBytesPerSecond int, downloadBytesPerSecond int) {
+	p.uploadLimiter.setLimit(uploadBytesPerSecond)
+	p.downloadLimiter.setLimit(downloadBytesPerSecond)
+}
@@ -1,7 +1,11 @@
 package bqueue
 
 import (
+	"time"
+
+	"github.com/gohornet/hornet/pkg/metrics"
 	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/tangle"
 	"github.com/gohornet/hornet/pkg/peering"
 	"github.com/gohornet/hornet/pkg/peering/peer"
 	"github.com/gohornet/hornet/pkg/protocol/helpers"
@@ -17,10 +21,26 @@ type Broadcast struct {
 	RequestedTxHash hornet.Hash
 	// The IDs of the peers to exclude from broadcasting.
 	ExcludePeers map[string]struct{}
+	// The amount of times the transaction was already relayed.
+	HopCount byte
+	// Whether the transaction should only be sent to statically configured peers.
+	KnownPeersOnly bool
+	// Returns whether the peer with the given ID already sent the transaction to the node. Optional.
+	// It is checked when the transaction is sent, to also skip peers which sent it while the broadcast was queued.
+	ReceivedFrom func(peerID string) bool
+	// Whether the transaction is broadcasted before the other queued transactions
+	// and sent via the priority send queue of the peers (e.g. milestones and transactions submitted via the API).
+	Priority bool
+	// The time the broadcast was enqueued.
+	enqueuedAt time.Time
 }
 
-// Size defines the default size of the broadcast queue.
-const Size = 1000
+const (
+	// Size defines the default size of the broadcast queue.
+	Size = 1000
+	// PrioritySize defines the default size of the queue for prioritized broadcasts.
+	PrioritySize = 100
+)
 
 // Queue implements a queue which broadcasts its elements to all wanted peers.
 type Queue interface {
@@ -32,39 +52,116 @@ type Queue interface {
 
 // New creates a new Queue.
 func New(manager *peering.Manager, reqQueue rqueue.Queue) Queue {
-	return &queue{c: make(chan *Broadcast, Size), manager: manager, reqQueue: reqQueue}
+	return &queue{
+		c:         make(chan *Broadcast, Size),
+		priorityC: make(chan *Broadcast, PrioritySize),
+		manager:   manager,
+		reqQueue:  reqQueue,
+	}
 }
 
 // queue is a broadcast queue which sends the given messages to all peers.
+// Prioritized broadcasts are sent before the other queued broadcasts.
 type queue struct {
-	c        chan *Broadcast
-	manager  *peering.Manager
-	reqQueue rqueue.Queue
+	c         chan *Broadcast
+	priorityC chan *Broadcast
+	manager   *peering.Manager
+	reqQueue  rqueue.Queue
 }
 
 func (bc *queue) EnqueueForBroadcast(b *Broadcast) {
+	b.enqueuedAt = time.Now()
+	if b.Priority {
+		bc.priorityC <- b
+		return
+	}
 	bc.c <- b
 }
 
 func (bc *queue) Run(shutdownSignal <-chan struct{}) {
 	for {
+		// always drain the prioritized broadcasts first
+		select {
+		case <-shutdownSignal:
+			return
+		case b := <-bc.priorityC:
+			bc.broadcast(b)
+			continue
+		default:
+		}
+
 		select {
 		case <-shutdownSignal:
 			return
+		case b := <-bc.priorityC:
+			bc.broadcast(b)
 		case b := <-bc.c:
-			bc.manager.ForAllConnected(func(p *peer.Peer) bool {
-				if _, excluded := b.ExcludePeers[p.ID]; excluded {
-					return true
-				}
-
-				// just send the transaction when the peer supports STING
-				if p.Protocol.Supports(sting.FeatureSet) {
-					helpers.SendTransaction(p, b.TxData)
-					return true
-				}
-
-				return true
-			})
+			bc.broadcast(b)
+		}
+	}
+}
+
+// broadcast sends the given broadcast to all wanted peers.
+func (bc *queue) broadcast(b *Broadcast) {
+	if b.Priority {
+		metrics.SharedServerMetrics.PriorityBroadcastLatency.Observe(b.enqueuedAt)
+	} else {
+		metrics.SharedServerMetrics.RegularBroadcastLatency.Observe(b.enqueuedAt)
+	}
+
+	// synced peers are sent the transaction first, since they can pass it on right away
+	var unsynced []*peer.Peer
+	latestMilestoneIndex := tangle.GetLatestMilestoneIndex()
+	bc.manager.ForAllConnected(func(p *peer.Peer) bool {
+		if bc.manager.DeprioritizedUnsynced(p, latestMilestoneIndex) {
+			unsynced = append(unsynced, p)
+			return true
 		}
+		bc.send(b, p)
+		return true
+	})
+
+	for _, p := range unsynced {
+		bc.send(b, p)
+	}
+}
+
+// send sends the given broadcast to the given peer, unless the peer doesn't need it.
+func (bc *queue) send(b *Broadcast, p *peer.Peer) {
+	if _, excluded := b.ExcludePeers[p.ID]; excluded {
+		metrics.SharedServerMetrics.SuppressedDuplicateBroadcasts.Inc()
+		return
+	}
+
+	if b.ReceivedFrom != nil && b.ReceivedFrom(p.ID) {
+		metrics.SharedServerMetrics.SuppressedDuplicateBroadcasts.Inc()
+		return
+	}
+
+	// milestones are always sent, since the filter of the peer might report a transaction falsely as known
+	if !b.Priority && b.RequestedTxHash != nil && p.KnowsTransaction(b.RequestedTxHash) {
+		metrics.SharedServerMetrics.SuppressedDuplicateBroadcasts.Inc()
+		return
+	}
+
+	if b.KnownPeersOnly && p.Autopeering != nil {
+		return
+	}
+
+	// just send the transaction when the peer supports STING and gossips
+	if !p.Protocol.Supports(sting.FeatureSet) || !p.GossipEnabled() {
+		return
+	}
+
+	if b.Priority {
+		helpers.SendPriorityTransactionWithHopCount(p, b.HopCount, b.TxData)
+		return
+	}
+
+	// the peer receives the transaction from its other peers, so it isn't enqueued until the send queue was relieved
+	if p.SendQueueCongested() {
+		metrics.SharedServerMetrics.BackpressuredBroadcasts.Inc()
+		return
 	}
+	helpers.SendTransactionWithHopCount(p, b.HopCount, b.TxData)
 }
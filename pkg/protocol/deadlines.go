@@ -0,0 +1,59 @@
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+)
+
+var (
+	// ErrWriteDeadlineExceeded is returned when a message couldn't be written before the write deadline
+	// and got dropped. The stream is still intact since no data of the message was written.
+	ErrWriteDeadlineExceeded = errors.New("write deadline exceeded, message dropped")
+	// ErrStreamDeadlinesExceeded is the reason the protocol is terminated if the stream repeatedly
+	// exceeded its read or write deadlines or if a write timed out in the middle of a message.
+	ErrStreamDeadlinesExceeded = errors.New("stream repeatedly exceeded its deadlines")
+)
+
+// SetMaxDeadlineHits sets the amount of consecutive read or write deadlines the stream may exceed
+// before the protocol is terminated. Must be called before the protocol is started.
+func (p *Protocol) SetMaxDeadlineHits(maxDeadlineHits int32) {
+	p.maxDeadlineHits = maxDeadlineHits
+}
+
+// IsDeadlineExceeded tells whether the given error was caused by an exceeded read or write deadline of the stream.
+func IsDeadlineExceeded(err error) bool {
+	return errors.Is(err, os.ErrDeadlineExceeded)
+}
+
+// counts an exceeded deadline and returns whether the stream exceeded its deadlines too often.
+func (p *Protocol) deadlineHit() bool {
+	return atomic.AddInt32(&p.deadlineHits, 1) >= p.maxDeadlineHits
+}
+
+// resets the exceeded deadlines since the stream made progress.
+func (p *Protocol) resetDeadlineHits() {
+	atomic.StoreInt32(&p.deadlineHits, 0)
+}
+
+// terminates the protocol since the stream exceeded its deadlines too often.
+func (p *Protocol) terminateStalledStream(err error) {
+	p.Events.Error.Trigger(fmt.Errorf("%w: %v", ErrStreamDeadlinesExceeded, err))
+	_ = p.conn.Close()
+}
+
+// returns the error for a failed write of a message.
+// a timed out write which didn't write any data of the message leaves the stream intact,
+// so only the message is dropped unless the stream exceeded its deadlines too often.
+func (p *Protocol) writeFailed(messageStarted bool, err error) error {
+	if !IsDeadlineExceeded(err) {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	if !messageStarted && !p.deadlineHit() {
+		return ErrWriteDeadlineExceeded
+	}
+
+	return fmt.Errorf("%w: %v", ErrStreamDeadlinesExceeded, err)
+}
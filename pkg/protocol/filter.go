@@ -0,0 +1,144 @@
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gohornet/hornet/pkg/protocol/message"
+)
+
+// Relation is the relation to the peer a protocol instance belongs to.
+type Relation int32
+
+const (
+	// RelationUnknown is the relation of a peer whose handshake is not verified yet.
+	RelationUnknown Relation = iota
+	// RelationStatic is the relation of a statically configured peer.
+	RelationStatic
+	// RelationAutopeered is the relation of a peer chosen by autopeering.
+	RelationAutopeered
+)
+
+var (
+	// ErrUnknownRelation is returned when a relation can't be parsed.
+	ErrUnknownRelation = errors.New("unknown relation")
+)
+
+// String returns the name of the relation.
+func (r Relation) String() string {
+	switch r {
+	case RelationStatic:
+		return "static"
+	case RelationAutopeered:
+		return "autopeered"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseRelation parses the given name of a relation.
+func ParseRelation(name string) (Relation, error) {
+	switch strings.ToLower(name) {
+	case "static":
+		return RelationStatic, nil
+	case "autopeered":
+		return RelationAutopeered, nil
+	case "unknown":
+		return RelationUnknown, nil
+	default:
+		return RelationUnknown, fmt.Errorf("%w: %s", ErrUnknownRelation, name)
+	}
+}
+
+// InboundMessage is a received message which is passed to the inbound filters.
+type InboundMessage struct {
+	// The type of the message.
+	Type message.Type
+	// The data of the message without the header.
+	Data []byte
+	// The relation to the peer the message was received from.
+	Relation Relation
+}
+
+// InboundFilter inspects a received message before the received event of its type is fired.
+// It returns the data to pass on, which may be a modified copy of the message data, or false to drop the message.
+type InboundFilter func(p *Protocol, msg *InboundMessage) ([]byte, bool)
+
+// a registered inbound filter, the pointer identifies the registration.
+type registeredInboundFilter struct {
+	filter InboundFilter
+}
+
+var (
+	// serializes the changes of the inbound filters.
+	inboundFiltersLock sync.Mutex
+	// the []*registeredInboundFilter every received message passes. the slice is replaced on every change,
+	// so it can be read without locking.
+	inboundFilters atomic.Value
+)
+
+// loadInboundFilters returns the currently registered inbound filters.
+func loadInboundFilters() []*registeredInboundFilter {
+	filters, _ := inboundFilters.Load().([]*registeredInboundFilter)
+	return filters
+}
+
+// RegisterInboundFilter adds the given filter to the filters every received message passes,
+// so that plugins can drop or modify messages before they reach the processing pipeline.
+// The filters are applied in the order of their registration, chunks are filtered after they were reassembled.
+// The returned function removes the filter again.
+func RegisterInboundFilter(filter InboundFilter) (unregister func()) {
+	inboundFiltersLock.Lock()
+	defer inboundFiltersLock.Unlock()
+
+	registered := &registeredInboundFilter{filter: filter}
+
+	current := loadInboundFilters()
+	filters := make([]*registeredInboundFilter, 0, len(current)+1)
+	inboundFilters.Store(append(append(filters, current...), registered))
+
+	return func() {
+		inboundFiltersLock.Lock()
+		defer inboundFiltersLock.Unlock()
+
+		current := loadInboundFilters()
+		filters := make([]*registeredInboundFilter, 0, len(current))
+		for _, f := range current {
+			if f != registered {
+				filters = append(filters, f)
+			}
+		}
+		inboundFilters.Store(filters)
+	}
+}
+
+// SetRelation sets the relation to the peer this protocol instance belongs to.
+func (p *Protocol) SetRelation(relation Relation) {
+	atomic.StoreInt32(&p.relation, int32(relation))
+}
+
+// Relation returns the relation to the peer this protocol instance belongs to.
+func (p *Protocol) Relation() Relation {
+	return Relation(atomic.LoadInt32(&p.relation))
+}
+
+// fires the received event for the given message type unless the message is dropped by an inbound filter.
+func (p *Protocol) triggerReceived(msgType message.Type, data []byte) {
+	if filters := loadInboundFilters(); len(filters) != 0 {
+		msg := &InboundMessage{Type: msgType, Data: data, Relation: p.Relation()}
+		for _, f := range filters {
+			filtered, ok := f.filter(p, msg)
+			if !ok {
+				atomic.AddUint64(&p.messagesFiltered, 1)
+				return
+			}
+			msg.Data = filtered
+		}
+		data = msg.Data
+	}
+
+	p.Events.Received[msgType].Trigger(data)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"errors"
+	"io"
 	"time"
 
 	"github.com/willf/bitset"
@@ -29,8 +30,12 @@ var (
 	// - time at which the packet was sent (8 bytes)
 	// - own used byte encoded coordinator address (49 bytes)
 	// - own used MWM (1 byte)
-	// - supported protocol versions. we need up to 32 bytes to represent 256 possible protocol
-	//   versions. only up to N bytes are used to communicate the highest supported version.
+	// - supported protocol versions. the binary encoded bitset is prefixed with its length (8 bytes),
+	//   only up to N bytes are used to communicate the highest supported version.
+	// - supported capabilities (2 bytes, optional). they are sent after the protocol versions,
+	//   so that nodes which don't know about them don't interpret them as protocol versions.
+	// - random nonce of the node (4 bytes, optional), which both peers use alike to break the tie
+	//   between the connections of a simultaneous dial.
 	HandshakeMessageDefinition = &message.Definition{
 		ID:             MessageTypeHandshake,
 		MaxBytesLength: 92,
@@ -43,10 +48,18 @@ type HeaderState int32
 const (
 	// The amount of bytes used for the coo address sent in a handshake packet.
 	ByteEncodedCooAddressBytesLength = 49
+
+	// The amount of protocol version bits which are part of the feature set of a connection:
+	// the legacy protocol versions 1 and 2 and STING.
+	LegacyVersionBits = 3
 )
 
 var (
 	ErrVersionNotSupported = errors.New("version not supported")
+	// ErrTooManyVersions is returned if the announced protocol versions don't fit into the handshake message.
+	ErrTooManyVersions = errors.New("too many protocol versions")
+	// ErrInvalidSupportedVersions is returned if the announced protocol versions exceed the handshake message.
+	ErrInvalidSupportedVersions = errors.New("invalid supported protocol versions")
 )
 
 // Handshake defines information exchanged during the handshake phase between two peers.
@@ -56,37 +69,74 @@ type Handshake struct {
 	ByteEncodedCooAddress []byte
 	MWM                   byte
 	SupportedVersions     []byte
+	Capabilities          uint16
+	// The random nonce of the peer, 0 if the peer didn't send one.
+	Nonce uint32
 }
 
-// SupportedVersion returns the highest supported protocol version.
+// SupportedVersion returns the bit of the highest protocol version supported by both the peer and this node.
 func (hs Handshake) SupportedVersion(ownSupportedMessagesBitset *bitset.BitSet) (version int, err error) {
-	hsSupportedMessagesBitset := bitset.New(uint(len(hs.SupportedVersions) * 8))
-	hsSupportedMessagesBitset.UnmarshalBinary(hs.SupportedVersions)
+	negotiated, err := hs.NegotiateVersion(ownSupportedMessagesBitset)
+	if negotiated == 0 {
+		return 0, err
+	}
+	return 1 << (negotiated - 1), err
+}
 
-	bothSupportedMessagesBitset := hsSupportedMessagesBitset.Union(ownSupportedMessagesBitset)
+// NegotiateVersion returns the highest protocol version supported by both the peer and this node.
+// Protocol versions are numbered starting with 1 for the LSB of the announced bitset.
+// If there is no common protocol version, the highest version of the peer is returned alongside ErrVersionNotSupported.
+func (hs Handshake) NegotiateVersion(ownSupportedVersionsBitset *bitset.BitSet) (version int, err error) {
+	hsSupportedVersionsBitset := hs.supportedVersionsBitset()
 
-	if !bothSupportedMessagesBitset.Any() {
+	bothSupportedVersionsBitset := hsSupportedVersionsBitset.Intersection(ownSupportedVersionsBitset)
+
+	if !bothSupportedVersionsBitset.Any() {
 		// we don't support any protocol version the peer supports
 		// return the highest supported version of a given node
-		for i := int(hsSupportedMessagesBitset.Len()) - 1; i >= 0; i-- {
-			if hsSupportedMessagesBitset.Test(uint(i)) {
-				return 1 << i, ErrVersionNotSupported
-			}
-		}
+		return highestVersion(hsSupportedVersionsBitset), ErrVersionNotSupported
+	}
+
+	return highestVersion(bothSupportedVersionsBitset), nil
+}
 
+// returns the highest protocol version set in the given bitset or 0 if none is set.
+func highestVersion(versionsBitset *bitset.BitSet) int {
+	for i := int(versionsBitset.Len()) - 1; i >= 0; i-- {
+		if versionsBitset.Test(uint(i)) {
+			return i + 1
+		}
 	}
+	return 0
+}
 
-	for i := int(bothSupportedMessagesBitset.Len()) - 1; i >= 0; i-- {
-		if bothSupportedMessagesBitset.Test(uint(i)) {
-			return 1 << i, nil
+// SupportedCapabilities returns the capabilities supported by both the peer and this node.
+func (hs Handshake) SupportedCapabilities(ownCapabilities uint16) uint16 {
+	return hs.Capabilities & ownCapabilities
+}
+
+// FeatureSet returns the feature set of the connection to the peer: the bits of the legacy protocol versions and STING
+// supported by both the peer and this node, alongside the capabilities supported by both which fit into the feature set.
+func (hs Handshake) FeatureSet(ownSupportedVersionsBitset *bitset.BitSet, ownCapabilities uint16) byte {
+	var featureSet byte
+	bothSupportedVersionsBitset := hs.supportedVersionsBitset().Intersection(ownSupportedVersionsBitset)
+	for i := uint(0); i < LegacyVersionBits; i++ {
+		if bothSupportedVersionsBitset.Test(i) {
+			featureSet |= 1 << i
 		}
 	}
+	return featureSet | byte(hs.SupportedCapabilities(ownCapabilities))
+}
 
-	return 0, ErrVersionNotSupported
+// returns the bitset of the protocol versions announced by the peer.
+func (hs Handshake) supportedVersionsBitset() *bitset.BitSet {
+	hsSupportedMessagesBitset := bitset.New(uint(len(hs.SupportedVersions) * 8))
+	hsSupportedMessagesBitset.UnmarshalBinary(hs.SupportedVersions)
+	return hsSupportedMessagesBitset
 }
 
 // NewHandshakeMessage creates a new handshake message.
-func NewHandshakeMessage(ownSupportedMessagesBitset *bitset.BitSet, ownSourcePort uint16, ownByteEncodedCooAddress []byte, ownUsedMWM byte) ([]byte, error) {
+func NewHandshakeMessage(ownSupportedMessagesBitset *bitset.BitSet, ownCapabilities uint16, ownSourcePort uint16, ownByteEncodedCooAddress []byte, ownUsedMWM byte, ownNonce uint32) ([]byte, error) {
 
 	maxLength := HandshakeMessageDefinition.MaxBytesLength
 
@@ -95,7 +145,11 @@ func NewHandshakeMessage(ownSupportedMessagesBitset *bitset.BitSet, ownSourcePor
 		return nil, err
 	}
 
-	payloadLengthBytes := maxLength - (maxLength - 60) + uint16(len(supportedMessageTypes))
+	payloadLengthBytes := maxLength - (maxLength - 60) + uint16(len(supportedMessageTypes)) + 2 + 4
+	if payloadLengthBytes > maxLength {
+		return nil, ErrTooManyVersions
+	}
+
 	buf := bytes.NewBuffer(make([]byte, 0, tlv.HeaderMessageDefinition.MaxBytesLength+payloadLengthBytes))
 
 	if err := tlv.WriteHeader(buf, MessageTypeHandshake, payloadLengthBytes); err != nil {
@@ -122,6 +176,14 @@ func NewHandshakeMessage(ownSupportedMessagesBitset *bitset.BitSet, ownSourcePor
 		return nil, err
 	}
 
+	if err := binary.Write(buf, binary.BigEndian, ownCapabilities); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(buf, binary.BigEndian, ownNonce); err != nil {
+		return nil, err
+	}
+
 	return buf.Bytes(), nil
 }
 
@@ -131,7 +193,6 @@ func ParseHandshake(msg []byte) (*Handshake, error) {
 	var sentTimestamp uint64
 	byteEncodedCooAddress := make([]byte, ByteEncodedCooAddressBytesLength)
 	var mwm byte
-	supportedVersions := make([]byte, 8)
 
 	r := bytes.NewReader(msg)
 
@@ -151,10 +212,42 @@ func ParseHandshake(msg []byte) (*Handshake, error) {
 		return nil, err
 	}
 
-	if _, err := r.Read(supportedVersions); err != nil {
+	// the supported versions are the binary encoded bitset prefixed with its length in bits
+	supportedVersionsOffset := len(msg) - r.Len()
+	var supportedVersionsBits uint64
+	if err := binary.Read(r, binary.BigEndian, &supportedVersionsBits); err != nil {
+		return nil, err
+	}
+
+	if supportedVersionsBits > uint64(r.Len())*8 {
+		return nil, ErrInvalidSupportedVersions
+	}
+
+	supportedVersionsWordsLength := (supportedVersionsBits + 63) / 64 * 8
+	if supportedVersionsWordsLength > uint64(r.Len()) {
+		return nil, ErrInvalidSupportedVersions
+	}
+	supportedVersions := msg[supportedVersionsOffset : supportedVersionsOffset+8+int(supportedVersionsWordsLength)]
+	if _, err := r.Seek(int64(supportedVersionsWordsLength), io.SeekCurrent); err != nil {
 		return nil, err
 	}
 
-	hs := &Handshake{ServerSocketPort: serverSocketPort, SentTimestamp: sentTimestamp, ByteEncodedCooAddress: byteEncodedCooAddress, MWM: mwm, SupportedVersions: supportedVersions}
+	// nodes which don't know about capabilities don't send them
+	var capabilities uint16
+	if r.Len() != 0 {
+		if err := binary.Read(r, binary.BigEndian, &capabilities); err != nil {
+			return nil, err
+		}
+	}
+
+	// nodes which don't know about the nonce don't send it
+	var nonce uint32
+	if r.Len() >= 4 {
+		if err := binary.Read(r, binary.BigEndian, &nonce); err != nil {
+			return nil, err
+		}
+	}
+
+	hs := &Handshake{ServerSocketPort: serverSocketPort, SentTimestamp: sentTimestamp, ByteEncodedCooAddress: byteEncodedCooAddress, MWM: mwm, SupportedVersions: supportedVersions, Capabilities: capabilities, Nonce: nonce}
 	return hs, nil
 }
@@ -0,0 +1,132 @@
+package handshake
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/gohornet/hornet/pkg/protocol/message"
+	"github.com/gohornet/hornet/pkg/protocol/tlv"
+)
+
+func init() {
+	if err := message.RegisterType(MessageTypeNodeInfo, NodeInfoMessageDefinition); err != nil {
+		panic(err)
+	}
+}
+
+const (
+	MessageTypeNodeInfo message.Type = 16
+
+	// CapabilityNodeInfo is the capability bit which denotes that the peers exchange their node info after the handshake.
+	// The handshake is only completed after the node info of the peer was received and verified.
+	// It is announced in the capabilities instead of the protocol versions, so that it doesn't change the negotiated version.
+	CapabilityNodeInfo = 1 << 8
+
+	// The maximum length of the network ID and the node version.
+	MaxNodeInfoFieldLength = 255
+	// The maximum amount of features within a node info.
+	MaxNodeInfoFeatures = 16
+	// The maximum length of the name of a feature.
+	MaxNodeInfoFeatureLength = 64
+)
+
+var (
+	// ErrInvalidNodeInfo is returned when a node info can't be parsed or exceeds the maximum lengths.
+	ErrInvalidNodeInfo = errors.New("invalid node info")
+
+	// NodeInfoMessageDefinition defines a node info message's format.
+	// Made up of:
+	// - the network ID prefixed with its length (1 byte)
+	// - the version of the node software prefixed with its length (1 byte)
+	// - the amount of supported features (1 byte), each prefixed with its length (1 byte)
+	NodeInfoMessageDefinition = &message.Definition{
+		ID:             MessageTypeNodeInfo,
+		MaxBytesLength: 2*(1+MaxNodeInfoFieldLength) + 1 + MaxNodeInfoFeatures*(1+MaxNodeInfoFeatureLength),
+		VariableLength: true,
+	}
+)
+
+// NodeInfo is the metadata of a node exchanged after the handshake, before any gossip is sent.
+type NodeInfo struct {
+	// The ID of the network the node gossips on.
+	NetworkID string `json:"networkId"`
+	// The version of the node software.
+	Version string `json:"version"`
+	// The names of the features supported by the node.
+	Features []string `json:"features"`
+}
+
+// NewNodeInfoMessage creates a new node info message.
+func NewNodeInfoMessage(info *NodeInfo) ([]byte, error) {
+	if len(info.NetworkID) > MaxNodeInfoFieldLength || len(info.Version) > MaxNodeInfoFieldLength || len(info.Features) > MaxNodeInfoFeatures {
+		return nil, ErrInvalidNodeInfo
+	}
+
+	payload := bytes.NewBuffer(make([]byte, 0, NodeInfoMessageDefinition.MaxBytesLength))
+	payload.WriteByte(byte(len(info.NetworkID)))
+	payload.WriteString(info.NetworkID)
+	payload.WriteByte(byte(len(info.Version)))
+	payload.WriteString(info.Version)
+	payload.WriteByte(byte(len(info.Features)))
+	for _, feature := range info.Features {
+		if len(feature) > MaxNodeInfoFeatureLength {
+			return nil, ErrInvalidNodeInfo
+		}
+		payload.WriteByte(byte(len(feature)))
+		payload.WriteString(feature)
+	}
+
+	msgBytesLength := uint16(payload.Len())
+	buf := bytes.NewBuffer(make([]byte, 0, tlv.HeaderMessageDefinition.MaxBytesLength+msgBytesLength))
+	if err := tlv.WriteHeader(buf, MessageTypeNodeInfo, msgBytesLength); err != nil {
+		return nil, err
+	}
+	buf.Write(payload.Bytes())
+
+	return buf.Bytes(), nil
+}
+
+// ParseNodeInfo parses the given message into a NodeInfo.
+func ParseNodeInfo(msg []byte) (*NodeInfo, error) {
+	r := bytes.NewReader(msg)
+
+	readField := func(maxLength int) (string, error) {
+		length, err := r.ReadByte()
+		if err != nil || int(length) > maxLength || int(length) > r.Len() {
+			return "", ErrInvalidNodeInfo
+		}
+		field := make([]byte, length)
+		_, _ = r.Read(field)
+		return string(field), nil
+	}
+
+	networkID, err := readField(MaxNodeInfoFieldLength)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := readField(MaxNodeInfoFieldLength)
+	if err != nil {
+		return nil, err
+	}
+
+	featuresCount, err := r.ReadByte()
+	if err != nil || featuresCount > MaxNodeInfoFeatures {
+		return nil, ErrInvalidNodeInfo
+	}
+
+	features := make([]string, 0, featuresCount)
+	for i := 0; i < int(featuresCount); i++ {
+		feature, err := readField(MaxNodeInfoFeatureLength)
+		if err != nil {
+			return nil, err
+		}
+		features = append(features, feature)
+	}
+
+	if r.Len() != 0 {
+		return nil, ErrInvalidNodeInfo
+	}
+
+	return &NodeInfo{NetworkID: networkID, Version: version, Features: features}, nil
+}
@@ -16,6 +16,39 @@ func SendTransaction(p *peer.Peer, txData []byte) {
 	p.EnqueueForSending(transactionMsg)
 }
 
+// SendTransactionWithHopCount sends a transaction message with the given hop count to the given peer.
+// If the peer does not support the hop count capability, a normal transaction message is sent.
+func SendTransactionWithHopCount(p *peer.Peer, hopCount byte, txData []byte) {
+	if transactionMsg := transactionMessageWithHopCount(p, hopCount, txData); transactionMsg != nil {
+		p.EnqueueForSending(transactionMsg)
+	}
+}
+
+// SendPriorityTransactionWithHopCount sends a transaction message with the given hop count to the given peer
+// via its priority send queue, so it bypasses the regular messages queued for the peer (e.g. for milestones).
+// If the peer does not support the hop count capability, a normal transaction message is sent.
+func SendPriorityTransactionWithHopCount(p *peer.Peer, hopCount byte, txData []byte) {
+	if transactionMsg := transactionMessageWithHopCount(p, hopCount, txData); transactionMsg != nil {
+		p.EnqueueForPrioritySending(transactionMsg)
+	}
+}
+
+// builds the transaction message with the given hop count for the given peer.
+// returns nil if the peer doesn't support transaction messages.
+func transactionMessageWithHopCount(p *peer.Peer, hopCount byte, txData []byte) []byte {
+	if p.Protocol.Supports(sting.FeatureSetHopCount) {
+		transactionMsg, _ := sting.NewTransactionWithHopCountMessage(hopCount, txData)
+		return transactionMsg
+	}
+
+	if p.Protocol.Supports(sting.FeatureSet) {
+		transactionMsg, _ := sting.NewTransactionMessage(txData)
+		return transactionMsg
+	}
+
+	return nil
+}
+
 // SendHeartbeat sends a heartbeat message to the given peer.
 func SendHeartbeat(p *peer.Peer, solidMsIndex milestone.Index, pruningMsIndex milestone.Index, latestMsIndex milestone.Index, connectedNeighbors uint8, syncedNeighbors uint8) {
 	if !p.Protocol.Supports(sting.FeatureSet) {
@@ -23,7 +56,7 @@ func SendHeartbeat(p *peer.Peer, solidMsIndex milestone.Index, pruningMsIndex mi
 	}
 
 	heartbeatData, _ := sting.NewHeartbeatMessage(solidMsIndex, pruningMsIndex, latestMsIndex, connectedNeighbors, syncedNeighbors)
-	p.EnqueueForSending(heartbeatData)
+	p.EnqueueForPrioritySending(heartbeatData)
 }
 
 // SendTransactionRequest sends a transaction request message to the given peer.
@@ -36,6 +69,17 @@ func SendTransactionRequest(p *peer.Peer, requestedHash hornet.Hash) {
 	p.EnqueueForSending(txReqData)
 }
 
+// SendPriorityTransactionRequest sends a transaction request message to the given peer
+// via its priority send queue, so it bypasses the regular messages queued for the peer (e.g. for transactions blocking the solidification).
+func SendPriorityTransactionRequest(p *peer.Peer, requestedHash hornet.Hash) {
+	if !p.Protocol.Supports(sting.FeatureSet) {
+		return
+	}
+
+	txReqData, _ := sting.NewTransactionRequestMessage(requestedHash)
+	p.EnqueueForPrioritySending(txReqData)
+}
+
 // SendMilestoneRequest sends a milestone request to the given peer.
 func SendMilestoneRequest(p *peer.Peer, index milestone.Index) {
 	if !p.Protocol.Supports(sting.FeatureSet) {
@@ -43,7 +87,7 @@ func SendMilestoneRequest(p *peer.Peer, index milestone.Index) {
 	}
 
 	milestoneRequestData, _ := sting.NewMilestoneRequestMessage(index)
-	p.EnqueueForSending(milestoneRequestData)
+	p.EnqueueForPrioritySending(milestoneRequestData)
 }
 
 // SendLatestMilestoneRequest sends a milestone request which requests the latest known milestone from the given peer.
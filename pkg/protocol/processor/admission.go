@@ -0,0 +1,81 @@
+package processor
+
+import (
+	"github.com/gohornet/hornet/pkg/metrics"
+	"github.com/gohornet/hornet/pkg/peering/peer"
+	"github.com/gohornet/hornet/pkg/protocol/message"
+	"github.com/gohornet/hornet/pkg/protocol/sting"
+)
+
+// a message which was held back by the admission control.
+type admissionTask struct {
+	p       *peer.Peer
+	msgType message.Type
+	data    []byte
+}
+
+// tells whether the given message type is non-essential gossip which can be held back by the admission control.
+// requests from peers are always processed immediately.
+func isDeferrableMessage(msgType message.Type) bool {
+	return msgType == sting.MessageTypeTransaction || msgType == sting.MessageTypeTransactionWithHopCount
+}
+
+// PauseIngest holds back incoming gossip transactions in a bounded buffer until ResumeIngest is called.
+// This is used to reduce the lock contention on the storage layer while a milestone cone is confirmed.
+// If the buffer is full, transactions are processed immediately again.
+func (proc *Processor) PauseIngest() {
+	if proc.opts.AdmissionBufferSize == 0 {
+		return
+	}
+
+	proc.admissionLock.Lock()
+	defer proc.admissionLock.Unlock()
+	proc.admissionPaused = true
+}
+
+// ResumeIngest submits all held back transactions to the processor and resumes the normal processing.
+func (proc *Processor) ResumeIngest() {
+	if proc.opts.AdmissionBufferSize == 0 {
+		return
+	}
+
+	proc.admissionLock.Lock()
+	buffered := proc.admissionBuffer
+	proc.admissionBuffer = make([]*admissionTask, 0, len(buffered))
+	proc.admissionPaused = false
+	proc.admissionLock.Unlock()
+
+	for _, task := range buffered {
+		proc.wp.Submit(task.p, task.msgType, task.data)
+	}
+}
+
+// AdmissionBufferSize returns the amount of transactions currently held back by the admission control.
+func (proc *Processor) AdmissionBufferSize() int {
+	proc.admissionLock.Lock()
+	defer proc.admissionLock.Unlock()
+	return len(proc.admissionBuffer)
+}
+
+// tries to hold back the given message. returns false if the message has to be processed immediately.
+func (proc *Processor) deferMessage(p *peer.Peer, msgType message.Type, data []byte) bool {
+	if proc.opts.AdmissionBufferSize == 0 || !isDeferrableMessage(msgType) {
+		return false
+	}
+
+	proc.admissionLock.Lock()
+	defer proc.admissionLock.Unlock()
+
+	if !proc.admissionPaused {
+		return false
+	}
+
+	if len(proc.admissionBuffer) >= proc.opts.AdmissionBufferSize {
+		metrics.SharedServerMetrics.AdmissionBufferOverflows.Inc()
+		return false
+	}
+
+	metrics.SharedServerMetrics.AdmissionBufferedTransactions.Inc()
+	proc.admissionBuffer = append(proc.admissionBuffer, &admissionTask{p: p, msgType: msgType, data: data})
+	return true
+}
@@ -0,0 +1,182 @@
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/math"
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+)
+
+// PayloadType denotes the kind of payload a transaction carries.
+type PayloadType string
+
+const (
+	// PayloadTypeTransaction is the payload of value transactions.
+	PayloadTypeTransaction PayloadType = "transaction"
+	// PayloadTypeMilestone is the payload of the transactions issued by the coordinator.
+	PayloadTypeMilestone PayloadType = "milestone"
+	// PayloadTypeIndexation is the payload of zero value transactions, which carry data indexed by their tag.
+	PayloadTypeIndexation PayloadType = "indexation"
+)
+
+// UnknownPayloadPolicy defines how transactions are handled which carry a payload without a registered validator.
+type UnknownPayloadPolicy string
+
+const (
+	// UnknownPayloadPolicyStore stores transactions with unknown payloads opaquely without validating their payload.
+	UnknownPayloadPolicyStore UnknownPayloadPolicy = "store"
+	// UnknownPayloadPolicyReject rejects transactions with unknown payloads as invalid.
+	UnknownPayloadPolicyReject UnknownPayloadPolicy = "reject"
+)
+
+var (
+	// ErrUnknownPayload is returned if a transaction carries a payload without a registered validator and such payloads are rejected.
+	ErrUnknownPayload = errors.New("unknown payload")
+	// ErrPayloadValidatorAlreadyRegistered is returned if a validator for the same payload type and version was already registered.
+	ErrPayloadValidatorAlreadyRegistered = errors.New("payload validator already registered")
+	// ErrUnknownPayloadPolicy is returned if an unknown policy for unknown payloads is configured.
+	ErrUnknownPayloadPolicy = errors.New("unknown policy for unknown payloads")
+)
+
+// PayloadDetector returns the version of the payload the given transaction carries,
+// or false if the transaction doesn't carry a payload of the detector's payload type.
+type PayloadDetector func(tx *hornet.Transaction) (version uint32, ok bool)
+
+// PayloadValidator checks the payload of a specific payload type and version carried by a transaction.
+// If an error is returned, the transaction is invalid.
+type PayloadValidator func(tx *hornet.Transaction) error
+
+type payloadDetector struct {
+	payloadType PayloadType
+	detect      PayloadDetector
+}
+
+type payloadValidatorKey struct {
+	payloadType PayloadType
+	version     uint32
+}
+
+var (
+	payloadsLock sync.RWMutex
+	// the registered payload detectors, the ones registered last are checked first.
+	payloadDetectors []*payloadDetector
+	// the registered payload validators per payload type and version.
+	payloadValidators = make(map[payloadValidatorKey]PayloadValidator)
+)
+
+func init() {
+	RegisterPayloadType(PayloadTypeIndexation, func(tx *hornet.Transaction) (uint32, bool) {
+		return 1, !tx.IsValue()
+	})
+	RegisterPayloadType(PayloadTypeTransaction, func(tx *hornet.Transaction) (uint32, bool) {
+		return 1, tx.IsValue()
+	})
+	RegisterPayloadType(PayloadTypeMilestone, func(tx *hornet.Transaction) (uint32, bool) {
+		return 1, !tx.IsValue() && tangle.IsCoordinatorAddress(tx.GetAddress())
+	})
+
+	// the indexed data is opaque to the node
+	_ = RegisterPayloadValidator(PayloadTypeIndexation, 1, func(tx *hornet.Transaction) error {
+		return nil
+	})
+	_ = RegisterPayloadValidator(PayloadTypeTransaction, 1, validateTransactionPayload)
+	// the signatures of milestones are verified once their bundle is complete
+	_ = RegisterPayloadValidator(PayloadTypeMilestone, 1, func(tx *hornet.Transaction) error {
+		return nil
+	})
+}
+
+// RegisterPayloadType registers a detector for the given payload type.
+// Detectors registered later take precedence over the ones registered before (e.g. the built-in payload types),
+// so plugins can introduce new payload types carried by transactions which would otherwise be detected as a built-in one.
+func RegisterPayloadType(payloadType PayloadType, detector PayloadDetector) {
+	payloadsLock.Lock()
+	defer payloadsLock.Unlock()
+
+	payloadDetectors = append(payloadDetectors, &payloadDetector{payloadType: payloadType, detect: detector})
+}
+
+// RegisterPayloadValidator registers a validator for the given version of the given payload type.
+func RegisterPayloadValidator(payloadType PayloadType, version uint32, validator PayloadValidator) error {
+	payloadsLock.Lock()
+	defer payloadsLock.Unlock()
+
+	key := payloadValidatorKey{payloadType: payloadType, version: version}
+	if _, exists := payloadValidators[key]; exists {
+		return fmt.Errorf("%w: %s v%d", ErrPayloadValidatorAlreadyRegistered, payloadType, version)
+	}
+
+	payloadValidators[key] = validator
+	return nil
+}
+
+// DetectPayload returns the payload type and version the given transaction carries.
+// An empty payload type is returned if none of the registered detectors matches.
+func DetectPayload(tx *hornet.Transaction) (PayloadType, uint32) {
+	payloadsLock.RLock()
+	defer payloadsLock.RUnlock()
+
+	for i := len(payloadDetectors) - 1; i >= 0; i-- {
+		if version, ok := payloadDetectors[i].detect(tx); ok {
+			return payloadDetectors[i].payloadType, version
+		}
+	}
+
+	return "", 0
+}
+
+// returns whether the given transaction carries a milestone payload.
+func isMilestonePayload(tx *hornet.Transaction) bool {
+	payloadType, _ := DetectPayload(tx)
+	return payloadType == PayloadTypeMilestone
+}
+
+// ParseUnknownPayloadPolicy parses the given policy for transactions with unknown payloads.
+func ParseUnknownPayloadPolicy(policy string) (UnknownPayloadPolicy, error) {
+	switch UnknownPayloadPolicy(policy) {
+	case UnknownPayloadPolicyStore, UnknownPayloadPolicyReject:
+		return UnknownPayloadPolicy(policy), nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownPayloadPolicy, policy)
+	}
+}
+
+// validatePayload runs the validator of the payload the given transaction carries.
+// transactions with payloads without a registered validator are handled according to the configured policy.
+func (proc *Processor) validatePayload(tx *hornet.Transaction) error {
+	payloadType, version := DetectPayload(tx)
+
+	payloadsLock.RLock()
+	validator, exists := payloadValidators[payloadValidatorKey{payloadType: payloadType, version: version}]
+	payloadsLock.RUnlock()
+
+	if !exists {
+		if proc.opts.UnknownPayloadPolicy == UnknownPayloadPolicyReject {
+			return fmt.Errorf("%w: %s v%d", ErrUnknownPayload, payloadType, version)
+		}
+		return nil
+	}
+
+	return validator(tx)
+}
+
+// checks the value and the address of a value transaction.
+func validateTransactionPayload(tx *hornet.Transaction) error {
+	if math.AbsInt64(tx.Tx.Value) > consts.TotalSupply {
+		return consts.ErrInsufficientBalance
+	}
+
+	// last trit must be zero because of KERL
+	addressTrits, err := trinary.TrytesToTrits(tx.Tx.Address)
+	if err != nil || addressTrits[consts.AddressTrinarySize-1] != 0 {
+		return consts.ErrInvalidAddress
+	}
+
+	return nil
+}
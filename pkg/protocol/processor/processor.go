@@ -6,6 +6,7 @@ import (
 
 	"github.com/iotaledger/hive.go/events"
 	"github.com/iotaledger/hive.go/objectstorage"
+	"github.com/iotaledger/hive.go/syncutils"
 	"github.com/iotaledger/hive.go/workerpool"
 	"github.com/iotaledger/iota.go/consts"
 	"github.com/iotaledger/iota.go/guards"
@@ -25,6 +26,7 @@ import (
 	"github.com/gohornet/hornet/pkg/protocol/message"
 	"github.com/gohornet/hornet/pkg/protocol/rqueue"
 	"github.com/gohornet/hornet/pkg/protocol/sting"
+	"github.com/gohornet/hornet/pkg/watchdog"
 	"github.com/gohornet/hornet/plugins/curl"
 )
 
@@ -52,11 +54,15 @@ func New(requestQueue rqueue.Queue, peerManager *peering.Manager, opts *Options)
 		pm:           peerManager,
 		requestQueue: requestQueue,
 		Events: Events{
-			TransactionProcessed: events.NewEvent(TransactionProcessedCaller),
-			BroadcastTransaction: events.NewEvent(BroadcastCaller),
+			TransactionProcessed:         events.NewEvent(TransactionProcessedCaller),
+			PriorityTransactionProcessed: events.NewEvent(TransactionProcessedCaller),
+			BroadcastTransaction:         events.NewEvent(BroadcastCaller),
 		},
 		opts: *opts,
 	}
+	if opts.RecentMessagesCacheSize > 0 {
+		proc.recent = newRecentMessages(opts.RecentMessagesCacheSize)
+	}
 	wuCacheOpts := opts.WorkUnitCacheOpts
 	proc.workUnits = objectstorage.New(
 		nil,
@@ -72,13 +78,19 @@ func New(requestQueue rqueue.Queue, peerManager *peering.Manager, opts *Options)
 			}),
 	)
 
+	proc.loop = watchdog.RegisterLoop("MessageProcessor")
 	proc.wp = workerpool.New(func(task workerpool.Task) {
+		end := proc.loop.Begin()
+		defer end()
+
 		p := task.Param(0).(*peer.Peer)
 		data := task.Param(2).([]byte)
 
 		switch task.Param(1).(message.Type) {
 		case sting.MessageTypeTransaction:
-			proc.processTransaction(p, data)
+			proc.processTransaction(p, data, 0)
+		case sting.MessageTypeTransactionWithHopCount:
+			proc.processTransactionWithHopCount(p, data)
 		case sting.MessageTypeTransactionRequest:
 			proc.processTransactionRequest(p, data)
 		case sting.MessageTypeMilestoneRequest:
@@ -103,6 +115,9 @@ func BroadcastCaller(handler interface{}, params ...interface{}) {
 type Events struct {
 	// Fired when a transaction was fully processed.
 	TransactionProcessed *events.Event
+	// Fired instead of TransactionProcessed when a transaction submitted by the operator (e.g. via the API) was processed.
+	// Such transactions should be handled before the ones received via gossip.
+	PriorityTransactionProcessed *events.Event
 	// Fired when a transaction is meant to be broadcasted.
 	BroadcastTransaction *events.Event
 }
@@ -112,15 +127,33 @@ type Processor struct {
 	Events       Events
 	pm           *peering.Manager
 	wp           *workerpool.WorkerPool
+	loop         *watchdog.Loop
 	requestQueue rqueue.Queue
 	workUnits    *objectstorage.ObjectStorage
 	opts         Options
+	// the recently received messages, nil if the cache is disabled.
+	recent *recentMessages
+
+	// admission control
+	admissionLock   syncutils.Mutex
+	admissionPaused bool
+	admissionBuffer []*admissionTask
 }
 
 // The Options for the Processor.
 type Options struct {
 	ValidMWM          uint64
 	WorkUnitCacheOpts profile.CacheOpts
+	// The maximum hop count up to which received transactions are relayed. 0 disables the limit.
+	HopLimit byte
+	// The maximum amount of gossip transactions which are held back while a milestone is confirmed. 0 disables the admission control.
+	AdmissionBufferSize int
+	// Returns whether the given transaction must only be relayed to statically configured peers. Optional.
+	UnknownPeersRelayFilter func(tx *hornet.Transaction) bool
+	// Defines how transactions are handled which carry a payload without a registered validator.
+	UnknownPayloadPolicy UnknownPayloadPolicy
+	// The amount of recently received messages which are recognized without hashing them. 0 disables the cache.
+	RecentMessagesCacheSize int
 }
 
 // Run runs the processor and blocks until the shutdown signal is triggered.
@@ -132,12 +165,15 @@ func (proc *Processor) Run(shutdownSignal <-chan struct{}) {
 
 // Process submits the given message to the processor for processing.
 func (proc *Processor) Process(p *peer.Peer, msgType message.Type, data []byte) {
+	if proc.deferMessage(p, msgType, data) {
+		return
+	}
 	proc.wp.Submit(p, msgType, data)
 }
 
 // ValidateTransactionTrytesAndEmit validates the given transaction trytes which were not received via gossip but
-// through some other mechanism. This function does not run within the Processor's worker pool.
-// Emits a TransactionProcessed and BroadcastTransaction event if the transaction was processed.
+// submitted by the operator (e.g. via the API). This function does not run within the Processor's worker pool.
+// Emits a PriorityTransactionProcessed and a prioritized BroadcastTransaction event if the transaction was processed.
 func (proc *Processor) ValidateTransactionTrytesAndEmit(txTrytes trinary.Trytes) error {
 	if !guards.IsTransactionTrytes(txTrytes) {
 		return consts.ErrInvalidTransactionTrytes
@@ -174,12 +210,18 @@ func (proc *Processor) ValidateTransactionTrytesAndEmit(txTrytes trinary.Trytes)
 		return consts.ErrInvalidTransactionHash
 	}
 
-	return proc.CompressAndEmit(tx, txTrits)
+	return proc.compressAndEmit(tx, txTrits, true)
 }
 
 // CompressAndEmit compresses the given transaction and emits TransactionProcessed and BroadcastTransaction events.
 // This function does not run within the Processor's worker pool.
 func (proc *Processor) CompressAndEmit(tx *transaction.Transaction, txTrits trinary.Trits) error {
+	return proc.compressAndEmit(tx, txTrits, false)
+}
+
+// compresses the given transaction and emits the processed and broadcast events.
+// transactions with priority are handled before the ones received via gossip.
+func (proc *Processor) compressAndEmit(tx *transaction.Transaction, txTrits trinary.Trits, priority bool) error {
 	txBytesTruncated := compressed.TruncateTxTrits(txTrits)
 	hornetTx := hornet.NewTransactionFromTx(tx, txBytesTruncated)
 
@@ -192,10 +234,20 @@ func (proc *Processor) CompressAndEmit(tx *transaction.Transaction, txTrits trin
 		return consts.ErrInvalidTransactionHash
 	}
 
-	proc.Events.TransactionProcessed.Trigger(hornetTx, (*rqueue.Request)(nil), (*peer.Peer)(nil))
+	if err := proc.validatePayload(hornetTx); err != nil {
+		return err
+	}
+
+	if priority {
+		proc.Events.PriorityTransactionProcessed.Trigger(hornetTx, (*rqueue.Request)(nil), (*peer.Peer)(nil))
+	} else {
+		proc.Events.TransactionProcessed.Trigger(hornetTx, (*rqueue.Request)(nil), (*peer.Peer)(nil))
+	}
+
 	proc.Events.BroadcastTransaction.Trigger(&bqueue.Broadcast{
 		TxData:          txBytesTruncated,
 		RequestedTxHash: hornetTx.GetTxHash(),
+		Priority:        priority || isMilestonePayload(hornetTx),
 	})
 	return nil
 }
@@ -239,7 +291,7 @@ func (proc *Processor) processMilestoneRequest(p *peer.Peer, data []byte) {
 	cachedTxs := cachedReqMs.GetBundle().GetTransactions() // txs +1
 	for _, cachedTxToSend := range cachedTxs {
 		transactionMsg, _ := sting.NewTransactionMessage(cachedTxToSend.GetTransaction().RawBytes)
-		p.EnqueueForSending(transactionMsg)
+		p.EnqueueForPrioritySending(transactionMsg)
 	}
 	cachedTxs.Release(true)   // txs -1
 	cachedReqMs.Release(true) // bundle -1
@@ -262,12 +314,33 @@ func (proc *Processor) processTransactionRequest(p *peer.Peer, data []byte) {
 	p.EnqueueForSending(transactionMsg)
 }
 
+// extracts the hop count of the given transaction message and then processes the transaction.
+func (proc *Processor) processTransactionWithHopCount(p *peer.Peer, data []byte) {
+	hopCount, txData, err := sting.ExtractHopCount(data)
+	if err != nil {
+		metrics.SharedServerMetrics.InvalidTransactions.Inc()
+		p.Metrics.InvalidMessages.Inc()
+
+		// drop the connection to the peer
+		proc.pm.Remove(p.ID)
+		return
+	}
+
+	metrics.SharedServerMetrics.IncTransactionHopCount(hopCount)
+	proc.processTransaction(p, txData, hopCount)
+}
+
 // gets or creates a new WorkUnit for the given transaction and then processes the WorkUnit.
-func (proc *Processor) processTransaction(p *peer.Peer, data []byte) {
+// the hop count denotes how many times the transaction was already relayed (0 if unknown).
+func (proc *Processor) processTransaction(p *peer.Peer, data []byte, hopCount byte) {
+	if proc.isRecentKnownMessage(p, data) {
+		return
+	}
+
 	cachedWorkUnit := proc.workUnitFor(data) // workUnit +1
 	defer cachedWorkUnit.Release()           // workUnit -1
 	workUnit := cachedWorkUnit.WorkUnit()
-	workUnit.addReceivedFrom(p, nil)
+	workUnit.addReceivedFrom(p, hopCount)
 	proc.processWorkUnit(workUnit, p)
 }
 
@@ -286,6 +359,7 @@ func (proc *Processor) processWorkUnit(wu *WorkUnit, p *peer.Peer) {
 		wu.processingLock.Unlock()
 
 		metrics.SharedServerMetrics.InvalidTransactions.Inc()
+		p.Metrics.InvalidMessages.Inc()
 
 		// drop the connection to the peer
 		proc.pm.Remove(p.ID)
@@ -354,8 +428,18 @@ func (proc *Processor) processWorkUnit(wu *WorkUnit, p *peer.Peer) {
 		return
 	}
 
+	if err := proc.validatePayload(hornetTx); err != nil {
+		wu.UpdateState(Invalid)
+		wu.punish()
+		return
+	}
+
 	wu.UpdateState(Hashed)
 
+	if proc.recent != nil {
+		proc.recent.add(messageDigest(wu.receivedTxBytes), hornetTx.GetTxHash())
+	}
+
 	// mark the WorkUnit as containing a stale transaction but
 	if request == nil && !timestampValid {
 		wu.wasStale = true
@@ -374,7 +458,22 @@ func (proc *Processor) processWorkUnit(wu *WorkUnit, p *peer.Peer) {
 	// broadcast the transaction if it wasn't requested and the timestamp is
 	// within what we consider a sensible delta from now
 	if request == nil && broadcast && !containsTx {
-		proc.Events.BroadcastTransaction.Trigger(wu.broadcast())
+		b := wu.broadcast()
+
+		// do not relay transactions which were already relayed too many times
+		if proc.opts.HopLimit != 0 && b.HopCount > proc.opts.HopLimit {
+			metrics.SharedServerMetrics.HopLimitedTransactions.Inc()
+			return
+		}
+
+		if proc.opts.UnknownPeersRelayFilter != nil {
+			b.KnownPeersOnly = proc.opts.UnknownPeersRelayFilter(hornetTx)
+		}
+
+		// milestones bypass the regular messages queued for the peers
+		b.Priority = isMilestonePayload(hornetTx)
+
+		proc.Events.BroadcastTransaction.Trigger(b)
 	}
 }
 
@@ -0,0 +1,127 @@
+package processor
+
+import (
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/iotaledger/hive.go/syncutils"
+
+	"github.com/gohornet/hornet/pkg/metrics"
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/peering/peer"
+)
+
+// recentMessages maps the digests of recently received transaction bytes to the hashes of the transactions,
+// so that known transactions don't need to be hashed again if they are received once more.
+// The oldest entries are evicted once the cache is full.
+type recentMessages struct {
+	syncutils.Mutex
+	txHashes map[string]hornet.Hash
+	// the digests in the order they were added, used as a ring buffer.
+	digests []string
+	next    int
+}
+
+func newRecentMessages(size int) *recentMessages {
+	return &recentMessages{
+		txHashes: make(map[string]hornet.Hash, size),
+		digests:  make([]string, size),
+	}
+}
+
+// returns the digest of the given received transaction bytes.
+func messageDigest(receivedTxBytes []byte) string {
+	digest := blake2b.Sum256(receivedTxBytes)
+	return string(digest[:])
+}
+
+// returns the hash of the transaction with the given digest, or nil if the digest is unknown.
+func (r *recentMessages) get(digest string) hornet.Hash {
+	r.Lock()
+	defer r.Unlock()
+	return r.txHashes[digest]
+}
+
+// adds the given digest and transaction hash and evicts the oldest entry if the cache is full.
+func (r *recentMessages) add(digest string, txHash hornet.Hash) {
+	r.Lock()
+	defer r.Unlock()
+
+	if _, exists := r.txHashes[digest]; exists {
+		return
+	}
+
+	if evicted := r.digests[r.next]; evicted != "" {
+		delete(r.txHashes, evicted)
+	}
+	r.digests[r.next] = digest
+	r.next = (r.next + 1) % len(r.digests)
+	r.txHashes[digest] = txHash
+}
+
+// returns a copy of the cached entries.
+func (r *recentMessages) entries() map[string]hornet.Hash {
+	r.Lock()
+	defer r.Unlock()
+
+	entries := make(map[string]hornet.Hash, len(r.txHashes))
+	for digest, txHash := range r.txHashes {
+		entries[digest] = txHash
+	}
+	return entries
+}
+
+// LoadRecentMessages fills the cache of the recently received messages with the ones persisted on the last shutdown,
+// so that the transactions gossiped again after a restart are recognized as known without hashing them.
+// Returns the amount of loaded messages.
+func (proc *Processor) LoadRecentMessages() (int, error) {
+	if proc.recent == nil {
+		return 0, nil
+	}
+
+	messages, err := tangle.LoadRecentMessages()
+	if err != nil {
+		return 0, err
+	}
+
+	loaded := 0
+	for digest, txHash := range messages {
+		if loaded == len(proc.recent.digests) {
+			break
+		}
+		proc.recent.add(digest, txHash)
+		loaded++
+	}
+	return loaded, nil
+}
+
+// PersistRecentMessages stores the cache of the recently received messages, so that it can be loaded after a restart.
+// Returns the amount of persisted messages.
+func (proc *Processor) PersistRecentMessages() (int, error) {
+	if proc.recent == nil {
+		return 0, nil
+	}
+
+	messages := proc.recent.entries()
+	if err := tangle.StoreRecentMessages(messages); err != nil {
+		return 0, err
+	}
+	return len(messages), nil
+}
+
+// tells whether the given received transaction bytes belong to a recently received transaction which is already stored.
+// the transaction is counted as known, since it doesn't need to be processed again.
+func (proc *Processor) isRecentKnownMessage(p *peer.Peer, receivedTxBytes []byte) bool {
+	if proc.recent == nil {
+		return false
+	}
+
+	txHash := proc.recent.get(messageDigest(receivedTxBytes))
+	if txHash == nil || !tangle.ContainsTransaction(txHash) {
+		return false
+	}
+
+	metrics.SharedServerMetrics.KnownTransactions.Inc()
+	p.Metrics.KnownTransactions.Inc()
+	return true
+}
@@ -66,6 +66,8 @@ type WorkUnit struct {
 	// received from
 	receivedFromLock syncutils.RWMutex
 	receivedFrom     []*peer.Peer
+	// the lowest hop count with which the transaction was received
+	hopCount byte
 }
 
 func (wu *WorkUnit) Update(_ objectstorage.StorableObject) {
@@ -95,12 +97,21 @@ func (wu *WorkUnit) Is(state WorkUnitState) bool {
 	return wu.state&state > 0
 }
 
-// adds a Request for the given peer to this WorkUnit.
-// requestedTxHashBytes can be nil to flag that this request just reflects a receive from the given
-// peer and has no associated request.
-func (wu *WorkUnit) addReceivedFrom(p *peer.Peer, requestedTxHash hornet.Hash) {
+// adds the given peer to the peers this WorkUnit was received from.
+// the lowest hop count of all receives is kept.
+func (wu *WorkUnit) addReceivedFrom(p *peer.Peer, hopCount byte) {
 	wu.receivedFromLock.Lock()
 	defer wu.receivedFromLock.Unlock()
+	if len(wu.receivedFrom) == 0 || hopCount < wu.hopCount {
+		wu.hopCount = hopCount
+	}
+	for _, receivedFrom := range wu.receivedFrom {
+		if receivedFrom == p {
+			// the peer sent the same transaction more than once
+			p.Metrics.DuplicateTransactions.Inc()
+			break
+		}
+	}
 	wu.receivedFrom = append(wu.receivedFrom, p)
 }
 
@@ -112,6 +123,7 @@ func (wu *WorkUnit) punish() {
 	defer wu.receivedFromLock.Unlock()
 	for _, p := range wu.receivedFrom {
 		metrics.SharedServerMetrics.InvalidTransactions.Inc()
+		p.Metrics.InvalidMessages.Inc()
 
 		// drop the connection to the peer
 		peering.Manager().Remove(p.ID)
@@ -137,11 +149,29 @@ func (wu *WorkUnit) broadcast() *bqueue.Broadcast {
 	for _, p := range wu.receivedFrom {
 		exclude[p.ID] = struct{}{}
 	}
+	hopCount := wu.hopCount
+	if hopCount < 255 {
+		hopCount++
+	}
 	return &bqueue.Broadcast{
 		TxData:          wu.receivedTxBytes,
 		RequestedTxHash: wu.receivedTxHash,
 		ExcludePeers:    exclude,
+		HopCount:        hopCount,
+		ReceivedFrom:    wu.wasReceivedFrom,
+	}
+}
+
+// tells whether the underlying transaction of this WorkUnit was received from the peer with the given ID.
+func (wu *WorkUnit) wasReceivedFrom(peerID string) bool {
+	wu.receivedFromLock.RLock()
+	defer wu.receivedFromLock.RUnlock()
+	for _, p := range wu.receivedFrom {
+		if p.ID == peerID {
+			return true
+		}
 	}
+	return false
 }
 
 // increases the known transaction metric of all peers
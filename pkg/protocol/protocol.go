@@ -1,11 +1,13 @@
 package protocol
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"strconv"
 	"sync/atomic"
+	"time"
 
 	"github.com/willf/bitset"
 
@@ -33,8 +35,30 @@ var (
 		[01101110, 01010001, 00010001] denotes that this node supports protocol versions 2, 3, 4, 6, 7, 9, 13, 15, 17 and 21.
 	*/
 
-	// supported protocol messages/feature sets
+	// supported protocol messages/feature sets.
+	// further protocol versions are added via RegisterVersion.
 	SupportedFeatureSets = bitset.From([]uint64{sting.FeatureSet})
+
+	// optional capabilities which are announced in their own field after the supported protocol versions.
+	// they are only used if both peers announce them during the handshake.
+	SupportedCapabilities uint16
+)
+
+const (
+	// MinRegisteredVersion is the lowest protocol version which can be registered.
+	// The versions below share the feature set of a connection with the capabilities.
+	MinRegisteredVersion = 9
+	// MaxVersion is the highest protocol version which can be announced in the handshake.
+	// The bitset is encoded alongside its length (8 bytes) and followed by the capabilities (2 bytes) and the nonce (4 bytes),
+	// which leaves 16 of the 32 bytes for the versions.
+	MaxVersion = 128
+)
+
+var (
+	// ErrInvalidProtocolVersion is returned when a protocol version outside of the registrable range is registered.
+	ErrInvalidProtocolVersion = errors.New("invalid protocol version")
+	// ErrInvalidDispatchedMessage is returned when a reassembled message is invalid or of a type which is never chunked.
+	ErrInvalidDispatchedMessage = errors.New("invalid dispatched message")
 )
 
 var (
@@ -59,6 +83,24 @@ func Init(cooAddressBytes []byte, mwm int, gossipBindAddr string) error {
 	return nil
 }
 
+// EnableCapabilities announces the given capability bits during the handshake.
+// Must be called before any connection is established.
+func EnableCapabilities(capabilities uint16) {
+	SupportedCapabilities |= capabilities
+}
+
+// RegisterVersion announces support of the given protocol version during the handshake.
+// The highest protocol version supported by both peers is negotiated per connection,
+// which allows to roll out wire-format changes without a coordinated upgrade of the whole network.
+// Must be called before any connection is established.
+func RegisterVersion(version int) error {
+	if version < MinRegisteredVersion || version > MaxVersion {
+		return fmt.Errorf("%w: %d, must be between %d and %d", ErrInvalidProtocolVersion, version, MinRegisteredVersion, MaxVersion)
+	}
+	SupportedFeatureSets.Set(uint(version - 1))
+	return nil
+}
+
 // Events holds protocol related events.
 type Events struct {
 	// Fired when a handshake was fully completed.
@@ -77,9 +119,31 @@ type Events struct {
 
 // Protocol encapsulates the logic of parsing and sending protocol messages.
 type Protocol struct {
+	// the traffic statistics, kept at the beginning of the struct for the 64-bit alignment of the atomic operations
+	bytesIn     uint64
+	bytesOut    uint64
+	messagesIn  uint64
+	messagesOut uint64
+	// the amount of received messages dropped by the inbound filters
+	messagesFiltered uint64
+	// the times of the last received and sent data in unix nanoseconds
+	lastReceived int64
+	lastSent     int64
+	// the relation to the peer, read by the inbound filters
+	relation int32
 	// The protocol features this instance supports.
 	// This variable is only usable after protocol handshake.
 	FeatureSet byte
+	// The capabilities supported by both peers, including those which are not part of the feature set.
+	// This variable is only usable after protocol handshake.
+	Capabilities uint16
+	// The highest protocol version supported by both peers.
+	// This variable is only usable after protocol handshake.
+	Version int
+	// The server socket port announced in the handshake, 0 announces the one of the gossip bind address given to Init.
+	ServerSocketPort uint16
+	// The random nonce announced in the handshake.
+	HandshakeNonce uint32
 	// Holds events for sent and received messages, handshake completion and generic errors.
 	Events Events
 	// the underlying connection
@@ -96,6 +160,17 @@ type Protocol struct {
 	receiveBufferOffset int
 	// mutex to synchronize multiple sends
 	sendMutex syncutils.Mutex
+	// the ID of the last message which was split into chunks
+	chunkedMessageID uint32
+	// the amount of consecutive read or write deadlines the stream may exceed
+	maxDeadlineHits int32
+	// the amount of consecutive read or write deadlines the stream exceeded
+	deadlineHits int32
+	// the time the protocol instance was created
+	created time.Time
+	// the limiters of the bandwidth used to send and receive data
+	uploadLimiter   bandwidthLimiter
+	downloadLimiter bandwidthLimiter
 }
 
 // New generates a new protocol instance which is ready to read a first message header.
@@ -115,8 +190,11 @@ func New(conn io.ReadWriteCloser) *Protocol {
 		sentHandlers[i] = events.NewEvent(events.CallbackCaller)
 	}
 
+	now := time.Now()
 	protocol := &Protocol{
-		conn: conn,
+		lastReceived: now.UnixNano(),
+		lastSent:     now.UnixNano(),
+		conn:         conn,
 		Events: Events{
 			HandshakeCompleted: events.NewEvent(events.CallbackCaller),
 			Received:           receiveHandlers,
@@ -126,6 +204,7 @@ func New(conn io.ReadWriteCloser) *Protocol {
 		// the first message on the protocol is a TLV header
 		receiveBuffer:    make([]byte, tlv.HeaderMessageDefinition.MaxBytesLength),
 		receivingMessage: tlv.HeaderMessageDefinition,
+		created:          now,
 	}
 
 	return protocol
@@ -138,10 +217,41 @@ func (p *Protocol) Supports(featureSet byte) bool {
 
 // SupportedFeatureSets returns a slice of named supported feature sets.
 func (p *Protocol) SupportedFeatureSets() []string {
+	return FeatureSetNames(p.FeatureSet)
+}
+
+// OwnFeatureSets returns the names of the feature sets announced by this node during the handshake.
+func OwnFeatureSets() []string {
+	featureSet := byte(SupportedCapabilities)
+	for i := uint(0); i < handshake.LegacyVersionBits; i++ {
+		if SupportedFeatureSets.Test(i) {
+			featureSet |= 1 << i
+		}
+	}
+	return FeatureSetNames(featureSet)
+}
+
+// FeatureSetNames returns the names of the feature sets set in the given feature set byte.
+func FeatureSetNames(featureSet byte) []string {
 	var features []string
-	if p.Supports(sting.FeatureSet) {
+	if featureSet&sting.FeatureSet > 0 {
 		features = append(features, sting.FeatureSetName)
 	}
+	if featureSet&sting.FeatureSetHopCount > 0 {
+		features = append(features, sting.FeatureSetHopCountName)
+	}
+	if featureSet&sting.FeatureSetNeighborSuggestions > 0 {
+		features = append(features, sting.FeatureSetNeighborSuggestionsName)
+	}
+	if featureSet&sting.FeatureSetCapabilities > 0 {
+		features = append(features, sting.FeatureSetCapabilitiesName)
+	}
+	if featureSet&sting.FeatureSetChunking > 0 {
+		features = append(features, sting.FeatureSetChunkingName)
+	}
+	if featureSet&sting.FeatureSetCompression > 0 {
+		features = append(features, sting.FeatureSetCompressionName)
+	}
 	return features
 }
 
@@ -149,7 +259,12 @@ func (p *Protocol) SupportedFeatureSets() []string {
 // the connection.
 func (p *Protocol) Start() {
 	// kick off protocol by sending a handshake message
-	handshakeMsg, err := handshake.NewHandshakeMessage(SupportedFeatureSets, ownSrvSocketPort, ownByteEncodedCooAddress, byte(ownMWM))
+	srvSocketPort := p.ServerSocketPort
+	if srvSocketPort == 0 {
+		srvSocketPort = ownSrvSocketPort
+	}
+
+	handshakeMsg, err := handshake.NewHandshakeMessage(SupportedFeatureSets, SupportedCapabilities, srvSocketPort, ownByteEncodedCooAddress, byte(ownMWM), p.HandshakeNonce)
 	if err != nil {
 		fmt.Println("creating handshake message error: ", err)
 		_ = p.conn.Close()
@@ -164,8 +279,20 @@ func (p *Protocol) Start() {
 		return
 	}
 
-	// start reading from the connection
-	_, _ = p.conn.Read(make([]byte, 2048))
+	// start reading from the connection.
+	// reading continues after an exceeded read deadline until the stream exceeded its deadlines too often.
+	buf := make([]byte, 2048)
+	for {
+		_, err := p.conn.Read(buf)
+		if !IsDeadlineExceeded(err) {
+			return
+		}
+
+		if p.deadlineHit() {
+			p.terminateStalledStream(err)
+			return
+		}
+	}
 }
 
 // Handshaked has to be called when a handshake message was received (and finalized) and sent.
@@ -185,6 +312,11 @@ func (p *Protocol) IsHandshaked() bool {
 
 // Receive acts as an event handler for received data.
 func (p *Protocol) Receive(data []byte) {
+	p.downloadLimiter.wait(len(data))
+	p.resetDeadlineHits()
+	atomic.AddUint64(&p.bytesIn, uint64(len(data)))
+	atomic.StoreInt64(&p.lastReceived, time.Now().UnixNano())
+
 	offset := 0
 	length := len(data)
 
@@ -224,10 +356,12 @@ func (p *Protocol) Receive(data []byte) {
 			continue
 		}
 
+		atomic.AddUint64(&p.messagesIn, 1)
+
 		// fire the message type's event handler.
 		// note that the message id is valid here because we verified that the message type
 		// exists while parsing the TLV header
-		p.Events.Received[p.receivingMessage.ID].Trigger(p.receiveBuffer)
+		p.triggerReceived(p.receivingMessage.ID, p.receiveBuffer)
 
 		// reset to receiving a header
 		p.receivingMessage = tlv.HeaderMessageDefinition
@@ -241,13 +375,86 @@ func (p *Protocol) Send(message []byte) error {
 	p.sendMutex.Lock()
 	defer p.sendMutex.Unlock()
 
+	p.uploadLimiter.wait(len(message))
+
 	// write message
-	if _, err := p.conn.Write(message); err != nil {
-		return fmt.Errorf("failed to send message: %w", err)
+	if written, err := p.conn.Write(message); err != nil {
+		return p.writeFailed(written != 0, err)
 	}
+	p.resetDeadlineHits()
+	p.countSent(message)
 
 	// fire event handler for sent message
-	p.Events.Sent[message[0]].Trigger()
+	p.triggerSent(message)
+
+	return nil
+}
+
+// WaitForPendingSend blocks until the message which is currently written to the underlying writer was sent.
+func (p *Protocol) WaitForPendingSend() {
+	p.sendMutex.Lock()
+	defer p.sendMutex.Unlock()
+}
+
+// SendChunked splits the given message (including the message header) into chunks of the given size
+// and sends them consecutively to the underlying writer.
+// It fires the send event for the message type of the chunked message once all chunks were sent.
+func (p *Protocol) SendChunked(message []byte, chunkSize int) error {
+	chunkMsgs, err := sting.NewChunkMessages(atomic.AddUint32(&p.chunkedMessageID, 1), message, chunkSize)
+	if err != nil {
+		return fmt.Errorf("failed to split message into chunks: %w", err)
+	}
+
+	p.sendMutex.Lock()
+	defer p.sendMutex.Unlock()
+
+	for i, chunkMsg := range chunkMsgs {
+		p.uploadLimiter.wait(len(chunkMsg))
+		if written, err := p.conn.Write(chunkMsg); err != nil {
+			return p.writeFailed(i != 0 || written != 0, err)
+		}
+		p.countSent(chunkMsg)
+		p.Events.Sent[sting.MessageTypeChunk].Trigger()
+	}
+	p.resetDeadlineHits()
+
+	p.triggerSent(message)
+
+	return nil
+}
+
+// fires the sent event for the type of the given message (including the message header).
+// compressed messages also fire the sent event for the type of the message they carry.
+func (p *Protocol) triggerSent(msg []byte) {
+	p.Events.Sent[msg[0]].Trigger()
+
+	if message.Type(msg[0]) != sting.MessageTypeCompressed || len(msg) <= tlv.HeaderBytesLength+1 {
+		return
+	}
+
+	if msgType := msg[tlv.HeaderBytesLength+1]; int(msgType) < len(p.Events.Sent) && p.Events.Sent[msgType] != nil {
+		p.Events.Sent[msgType].Trigger()
+	}
+}
+
+// Dispatch fires the received event for the given message (including the message header),
+// which was reassembled from chunks.
+func (p *Protocol) Dispatch(msg []byte) error {
+	if len(msg) < tlv.HeaderBytesLength {
+		return ErrInvalidDispatchedMessage
+	}
+
+	header, err := tlv.ParseHeader(msg)
+	if err != nil {
+		return err
+	}
+
+	// chunks must not be nested and the handshake and the node info are never chunked
+	if header.Definition.ID == sting.MessageTypeChunk || header.Definition.ID == handshake.MessageTypeHandshake || header.Definition.ID == handshake.MessageTypeNodeInfo ||
+		header.Definition.ID == tlv.MessageTypeHeader || int(header.MessageBytesLength) != len(msg)-tlv.HeaderBytesLength {
+		return ErrInvalidDispatchedMessage
+	}
 
+	p.triggerReceived(header.Definition.ID, msg[tlv.HeaderBytesLength:])
 	return nil
 }
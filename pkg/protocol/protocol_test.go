@@ -1,15 +1,25 @@
 package protocol_test
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
 	"io"
+	"io/ioutil"
+	"net"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/gohornet/hornet/pkg/protocol"
 	"github.com/gohornet/hornet/pkg/protocol/handshake"
+	"github.com/gohornet/hornet/pkg/protocol/protocoltest"
 	"github.com/gohornet/hornet/pkg/protocol/sting"
+	"github.com/gohornet/hornet/pkg/protocol/tlv"
 	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/network"
 	"github.com/stretchr/testify/assert"
+	"github.com/willf/bitset"
 )
 
 type fakeconn struct {
@@ -79,7 +89,7 @@ func TestProtocol_Receive(t *testing.T) {
 		handshakeMessageReceived = true
 	}))
 
-	handshakeMsg, err := handshake.NewHandshakeMessage(protocol.SupportedFeatureSets, 100, make([]byte, 49), 14)
+	handshakeMsg, err := handshake.NewHandshakeMessage(protocol.SupportedFeatureSets, protocol.SupportedCapabilities, 100, make([]byte, 49), 14, 0)
 	assert.NoError(t, err)
 
 	wg := consume(t, p, conn, len(handshakeMsg))
@@ -100,7 +110,7 @@ func TestProtocol_Send(t *testing.T) {
 		handshakeMessageSent = true
 	}))
 
-	handshakeMsg, err := handshake.NewHandshakeMessage(protocol.SupportedFeatureSets, 100, make([]byte, 49), 14)
+	handshakeMsg, err := handshake.NewHandshakeMessage(protocol.SupportedFeatureSets, protocol.SupportedCapabilities, 100, make([]byte, 49), 14, 0)
 	assert.NoError(t, err)
 
 	wg := consume(t, p, conn, len(handshakeMsg))
@@ -110,9 +120,580 @@ func TestProtocol_Send(t *testing.T) {
 	assert.True(t, handshakeMessageSent)
 }
 
+func TestProtocol_Stats(t *testing.T) {
+	conn := newFakeConn()
+	defer conn.Close()
+	p := protocol.New(conn)
+	created := p.LastSent()
+
+	heartbeatMsg, err := sting.NewHeartbeatMessage(1, 1, 1, 1, 1)
+	assert.NoError(t, err)
+
+	// the sent message is received by the same protocol instance
+	wg := consume(t, p, conn, len(heartbeatMsg))
+	assert.NoError(t, p.Send(heartbeatMsg))
+	wg.Wait()
+
+	stats := p.Stats()
+	assert.EqualValues(t, len(heartbeatMsg), stats.BytesOut)
+	assert.EqualValues(t, len(heartbeatMsg), stats.BytesIn)
+	assert.EqualValues(t, 1, stats.MessagesOut)
+	assert.EqualValues(t, 1, stats.MessagesIn)
+	assert.False(t, p.LastSent().Before(created))
+	assert.False(t, p.LastReceived().Before(created))
+}
+
 func TestProtocol_Supports(t *testing.T) {
 	p := &protocol.Protocol{}
 	p.FeatureSet = sting.FeatureSet
 	assert.True(t, p.Supports(sting.FeatureSet))
 	assert.False(t, p.Supports(243))
 }
+
+func TestHandshake_SupportedCapabilities(t *testing.T) {
+	var capabilities uint16 = sting.FeatureSetHopCount | sting.FeatureSetCompression
+
+	handshakeMsg, err := handshake.NewHandshakeMessage(protocol.SupportedFeatureSets, capabilities, 100, make([]byte, 49), 14, 0)
+	assert.NoError(t, err)
+
+	hs, err := handshake.ParseHandshake(handshakeMsg[tlv.HeaderMessageDefinition.MaxBytesLength:])
+	assert.NoError(t, err)
+	assert.Equal(t, capabilities, hs.Capabilities)
+
+	// the capabilities are not part of the announced protocol versions
+	versionsBitset := bitset.New(8)
+	assert.NoError(t, versionsBitset.UnmarshalBinary(hs.SupportedVersions))
+	assert.True(t, versionsBitset.Equal(protocol.SupportedFeatureSets))
+
+	version, err := hs.SupportedVersion(protocol.SupportedFeatureSets)
+	assert.NoError(t, err)
+	assert.Equal(t, sting.FeatureSet, version)
+
+	assert.Equal(t, uint16(sting.FeatureSetHopCount), hs.SupportedCapabilities(sting.FeatureSetHopCount))
+	assert.Equal(t, uint16(0), hs.SupportedCapabilities(0))
+	assert.Equal(t, byte(sting.FeatureSet|sting.FeatureSetHopCount), hs.FeatureSet(protocol.SupportedFeatureSets, sting.FeatureSetHopCount))
+}
+
+func TestHandshake_WithoutCapabilities(t *testing.T) {
+	handshakeMsg, err := handshake.NewHandshakeMessage(protocol.SupportedFeatureSets, sting.FeatureSetHopCount, 100, make([]byte, 49), 14, 0)
+	assert.NoError(t, err)
+
+	// nodes which don't know about capabilities end the handshake after the protocol versions
+	msg := handshakeMsg[tlv.HeaderMessageDefinition.MaxBytesLength : len(handshakeMsg)-6]
+	hs, err := handshake.ParseHandshake(msg)
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(0), hs.Capabilities)
+	assert.Equal(t, byte(sting.FeatureSet), hs.FeatureSet(protocol.SupportedFeatureSets, sting.FeatureSetHopCount))
+
+	// the length of the announced protocol versions must not exceed the message
+	invalidMsg := append([]byte{}, msg...)
+	invalidMsg[60] = 0xFF
+	_, err = handshake.ParseHandshake(invalidMsg)
+	assert.True(t, errors.Is(err, handshake.ErrInvalidSupportedVersions))
+}
+
+func TestHandshake_Nonce(t *testing.T) {
+	handshakeMsg, err := handshake.NewHandshakeMessage(protocol.SupportedFeatureSets, sting.FeatureSetHopCount, 100, make([]byte, 49), 14, 0xDEADBEEF)
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, len(handshakeMsg)-int(tlv.HeaderMessageDefinition.MaxBytesLength), int(handshake.HandshakeMessageDefinition.MaxBytesLength))
+
+	hs, err := handshake.ParseHandshake(handshakeMsg[tlv.HeaderMessageDefinition.MaxBytesLength:])
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(0xDEADBEEF), hs.Nonce)
+	assert.Equal(t, uint16(sting.FeatureSetHopCount), hs.Capabilities)
+
+	// nodes which don't know about the nonce end the handshake after the capabilities
+	hs, err = handshake.ParseHandshake(handshakeMsg[tlv.HeaderMessageDefinition.MaxBytesLength : len(handshakeMsg)-4])
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(0), hs.Nonce)
+	assert.Equal(t, uint16(sting.FeatureSetHopCount), hs.Capabilities)
+}
+
+func TestHandshake_NegotiateVersion(t *testing.T) {
+	newHandshake := func(versions ...int) *handshake.Handshake {
+		versionsBitset := bitset.New(8)
+		for _, version := range versions {
+			versionsBitset.Set(uint(version - 1))
+		}
+
+		handshakeMsg, err := handshake.NewHandshakeMessage(versionsBitset, sting.FeatureSetHopCount, 100, make([]byte, 49), 14, 0)
+		assert.NoError(t, err)
+
+		hs, err := handshake.ParseHandshake(handshakeMsg[tlv.HeaderMessageDefinition.MaxBytesLength:])
+		assert.NoError(t, err)
+		return hs
+	}
+
+	ownVersions := bitset.From([]uint64{sting.FeatureSet})
+	ownVersions.Set(protocol.MinRegisteredVersion - 1)
+	ownVersions.Set(protocol.MaxVersion - 1)
+
+	// the highest version supported by both is used
+	version, err := newHandshake(sting.ProtocolVersion, protocol.MinRegisteredVersion, protocol.MinRegisteredVersion+1).NegotiateVersion(ownVersions)
+	assert.NoError(t, err)
+	assert.Equal(t, protocol.MinRegisteredVersion, version)
+
+	version, err = newHandshake(sting.ProtocolVersion, protocol.MaxVersion).NegotiateVersion(ownVersions)
+	assert.NoError(t, err)
+	assert.Equal(t, protocol.MaxVersion, version)
+
+	// peers which don't know about newer versions still use STING
+	version, err = newHandshake(sting.ProtocolVersion).NegotiateVersion(ownVersions)
+	assert.NoError(t, err)
+	assert.Equal(t, sting.ProtocolVersion, version)
+
+	// the highest version of the peer is returned if there is no common version
+	version, err = newHandshake(1, 2).NegotiateVersion(ownVersions)
+	assert.True(t, errors.Is(err, handshake.ErrVersionNotSupported))
+	assert.Equal(t, 2, version)
+
+	// versions beyond the maximum don't fit into the handshake message
+	tooManyVersions := bitset.New(8)
+	tooManyVersions.Set(protocol.MaxVersion)
+	_, err = handshake.NewHandshakeMessage(tooManyVersions, 0, 100, make([]byte, 49), 14, 0)
+	assert.True(t, errors.Is(err, handshake.ErrTooManyVersions))
+
+	assert.True(t, errors.Is(protocol.RegisterVersion(protocol.MinRegisteredVersion-1), protocol.ErrInvalidProtocolVersion))
+	assert.True(t, errors.Is(protocol.RegisterVersion(protocol.MaxVersion+1), protocol.ErrInvalidProtocolVersion))
+}
+
+func TestNodeInfo(t *testing.T) {
+	nodeInfo := &handshake.NodeInfo{
+		NetworkID: "mainnet",
+		Version:   "0.5.6",
+		Features:  []string{sting.FeatureSetName, sting.FeatureSetChunkingName},
+	}
+
+	msg, err := handshake.NewNodeInfoMessage(nodeInfo)
+	assert.NoError(t, err)
+
+	parsed, err := handshake.ParseNodeInfo(msg[tlv.HeaderMessageDefinition.MaxBytesLength:])
+	assert.NoError(t, err)
+	assert.Equal(t, nodeInfo, parsed)
+
+	_, err = handshake.ParseNodeInfo(msg[tlv.HeaderMessageDefinition.MaxBytesLength : len(msg)-1])
+	assert.True(t, errors.Is(err, handshake.ErrInvalidNodeInfo))
+
+	_, err = handshake.ParseNodeInfo(append(msg[tlv.HeaderMessageDefinition.MaxBytesLength:], 0))
+	assert.True(t, errors.Is(err, handshake.ErrInvalidNodeInfo))
+
+	// the node info exchange is a capability which doesn't affect the negotiated protocol version or the feature set
+	handshakeMsg, err := handshake.NewHandshakeMessage(protocol.SupportedFeatureSets, handshake.CapabilityNodeInfo, 100, make([]byte, 49), 14, 0)
+	assert.NoError(t, err)
+
+	hs, err := handshake.ParseHandshake(handshakeMsg[tlv.HeaderMessageDefinition.MaxBytesLength:])
+	assert.NoError(t, err)
+
+	version, err := hs.NegotiateVersion(protocol.SupportedFeatureSets)
+	assert.NoError(t, err)
+	assert.Equal(t, sting.ProtocolVersion, version)
+	assert.Equal(t, byte(sting.FeatureSet), hs.FeatureSet(protocol.SupportedFeatureSets, handshake.CapabilityNodeInfo))
+	assert.Equal(t, uint16(handshake.CapabilityNodeInfo), hs.SupportedCapabilities(handshake.CapabilityNodeInfo))
+
+	// the node info is never chunked
+	conn := newFakeConn()
+	defer conn.Close()
+	p := protocol.New(conn)
+	assert.Equal(t, protocol.ErrInvalidDispatchedMessage, p.Dispatch(msg))
+}
+
+func TestNeighborSuggestions(t *testing.T) {
+	addresses := []string{"example.com:15600", "[::1]:15601"}
+
+	msg, err := sting.NewNeighborSuggestionsMessage(addresses)
+	assert.NoError(t, err)
+
+	parsed, err := sting.ParseNeighborSuggestions(msg[tlv.HeaderMessageDefinition.MaxBytesLength:])
+	assert.NoError(t, err)
+	assert.Equal(t, addresses, parsed)
+
+	_, err = sting.ParseNeighborSuggestions(msg[tlv.HeaderMessageDefinition.MaxBytesLength : len(msg)-1])
+	assert.Error(t, err)
+}
+
+func TestCapabilities(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	capabilities := &sting.Capabilities{
+		Timestamp:    1600000000,
+		PruningIndex: 1234,
+		Services:     sting.ServicePublicAPI | sting.ServicePermanode,
+		APIAddress:   "https://example.com:14265",
+	}
+
+	msg, err := sting.NewCapabilitiesMessage(capabilities, key)
+	assert.NoError(t, err)
+
+	parsed, err := sting.ParseCapabilities(msg[tlv.HeaderMessageDefinition.MaxBytesLength:])
+	assert.NoError(t, err)
+	assert.Equal(t, key.Public(), parsed.PublicKey)
+	assert.Equal(t, capabilities.Timestamp, parsed.Timestamp)
+	assert.Equal(t, capabilities.PruningIndex, parsed.PruningIndex)
+	assert.Equal(t, []string{"publicAPI", "permanode"}, sting.ServiceNames(parsed.Services))
+	assert.Equal(t, capabilities.APIAddress, parsed.APIAddress)
+	assert.True(t, parsed.Offers(sting.ServicePublicAPI))
+	assert.False(t, parsed.Offers(sting.ServicePublicAPI|sting.ServiceSnapshots))
+
+	// tampered records are rejected
+	msg[len(msg)-ed25519.SignatureSize-1] ^= 1
+	_, err = sting.ParseCapabilities(msg[tlv.HeaderMessageDefinition.MaxBytesLength:])
+	assert.Equal(t, sting.ErrInvalidCapabilitiesSignature, err)
+}
+
+func TestPeerExchange(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	pex := &sting.PeerExchange{
+		Timestamp: 1600000000,
+		Peers:     [][]byte{[]byte("peer1"), []byte("peer2")},
+	}
+
+	msg, err := sting.NewPeerExchangeMessage(pex, key)
+	assert.NoError(t, err)
+
+	parsed, err := sting.ParsePeerExchange(msg[tlv.HeaderMessageDefinition.MaxBytesLength:])
+	assert.NoError(t, err)
+	assert.Equal(t, key.Public(), parsed.PublicKey)
+	assert.Equal(t, pex.Timestamp, parsed.Timestamp)
+	assert.Equal(t, pex.Peers, parsed.Peers)
+
+	// tampered lists are rejected
+	msg[len(msg)-ed25519.SignatureSize-1] ^= 1
+	_, err = sting.ParsePeerExchange(msg[tlv.HeaderMessageDefinition.MaxBytesLength:])
+	assert.Equal(t, sting.ErrInvalidPeerExchangeSignature, err)
+
+	_, err = sting.NewPeerExchangeMessage(&sting.PeerExchange{Peers: [][]byte{{}}}, key)
+	assert.Equal(t, sting.ErrInvalidPeerExchangePeer, err)
+}
+
+func TestLatency(t *testing.T) {
+	timestamp := time.Now().UnixNano()
+
+	ping, err := sting.NewLatencyPingMessage(timestamp)
+	assert.NoError(t, err)
+	pong, err := sting.NewLatencyPongMessage(timestamp)
+	assert.NoError(t, err)
+
+	for _, msg := range [][]byte{ping, pong} {
+		parsed, err := sting.ParseLatencyTimestamp(msg[tlv.HeaderMessageDefinition.MaxBytesLength:])
+		assert.NoError(t, err)
+		assert.Equal(t, timestamp, parsed)
+	}
+
+	_, err = sting.ParseLatencyTimestamp(ping[:len(ping)-1])
+	assert.Equal(t, sting.ErrInvalidSourceLength, err)
+}
+
+func TestChunking(t *testing.T) {
+	conn := newFakeConn()
+	defer conn.Close()
+	p := protocol.New(conn)
+
+	addresses := []string{"example.com:15600", "[::1]:15601"}
+	var received []string
+	p.Events.Received[sting.MessageTypeNeighborSuggestions].Attach(events.NewClosure(func(data []byte) {
+		parsed, err := sting.ParseNeighborSuggestions(data)
+		assert.NoError(t, err)
+		received = parsed
+	}))
+
+	msg, err := sting.NewNeighborSuggestionsMessage(addresses)
+	assert.NoError(t, err)
+
+	chunkMsgs, err := sting.NewChunkMessages(1, msg, 10)
+	assert.NoError(t, err)
+	assert.Len(t, chunkMsgs, (len(msg)+9)/10)
+
+	reassembler := &sting.ChunkReassembler{}
+	for i, chunkMsg := range chunkMsgs {
+		chunk, err := sting.ParseChunk(chunkMsg[tlv.HeaderMessageDefinition.MaxBytesLength:])
+		assert.NoError(t, err)
+
+		reassembled, err := reassembler.Add(chunk)
+		assert.NoError(t, err)
+		if i < len(chunkMsgs)-1 {
+			assert.Nil(t, reassembled)
+			continue
+		}
+		assert.Equal(t, msg, reassembled)
+		assert.NoError(t, p.Dispatch(reassembled))
+	}
+	assert.Equal(t, addresses, received)
+
+	// chunks which don't continue the current message are rejected
+	chunk, err := sting.ParseChunk(chunkMsgs[1][tlv.HeaderMessageDefinition.MaxBytesLength:])
+	assert.NoError(t, err)
+	_, err = reassembler.Add(chunk)
+	assert.Equal(t, sting.ErrInvalidChunk, err)
+
+	// chunks must not be nested
+	assert.Equal(t, protocol.ErrInvalidDispatchedMessage, p.Dispatch(chunkMsgs[0]))
+}
+
+func TestCompression(t *testing.T) {
+	conn := newFakeConn()
+	defer conn.Close()
+	p := protocol.New(conn)
+
+	addresses := []string{"example.com:15600", "example.com:15601", "example.com:15602", "example.com:15603"}
+	var received []string
+	p.Events.Received[sting.MessageTypeNeighborSuggestions].Attach(events.NewClosure(func(data []byte) {
+		parsed, err := sting.ParseNeighborSuggestions(data)
+		assert.NoError(t, err)
+		received = parsed
+	}))
+
+	msg, err := sting.NewNeighborSuggestionsMessage(addresses)
+	assert.NoError(t, err)
+
+	compressedMsg, err := sting.NewCompressedMessage(sting.CompressionSnappy, msg)
+	assert.NoError(t, err)
+	assert.Less(t, len(compressedMsg), len(msg))
+
+	decompressed, err := sting.Decompress(compressedMsg[tlv.HeaderMessageDefinition.MaxBytesLength:])
+	assert.NoError(t, err)
+	assert.Equal(t, msg, decompressed)
+	assert.NoError(t, p.Dispatch(decompressed))
+	assert.Equal(t, addresses, received)
+
+	// messages which wouldn't get smaller are not compressed
+	heartbeatMsg, err := sting.NewHeartbeatMessage(1, 1, 1, 1, 1)
+	assert.NoError(t, err)
+	compressedMsg, err = sting.NewCompressedMessage(sting.CompressionSnappy, heartbeatMsg)
+	assert.NoError(t, err)
+	assert.Nil(t, compressedMsg)
+
+	// compressed messages must not be nested
+	nested := append([]byte{byte(sting.CompressionSnappy), byte(sting.MessageTypeCompressed)}, 0)
+	_, err = sting.Decompress(nested)
+	assert.Equal(t, sting.ErrInvalidCompressedMessage, err)
+
+	// unknown codecs are rejected
+	_, err = sting.Decompress([]byte{0xff, byte(sting.MessageTypeNeighborSuggestions), 0})
+	assert.Equal(t, sting.ErrUnknownCompressionCodec, err)
+}
+
+func TestWriteDeadlines(t *testing.T) {
+	local, remote := net.Pipe()
+	defer remote.Close()
+
+	// nobody reads from the remote end, so every write exceeds its deadline
+	conn := network.NewManagedConnection(local)
+	assert.NoError(t, conn.SetWriteTimeout(10*time.Millisecond))
+
+	p := protocol.New(conn)
+	p.SetMaxDeadlineHits(3)
+
+	heartbeatMsg, err := sting.NewHeartbeatMessage(1, 1, 1, 1, 1)
+	assert.NoError(t, err)
+
+	// the messages are dropped until the stream exceeded its deadlines too often
+	assert.True(t, errors.Is(p.Send(heartbeatMsg), protocol.ErrWriteDeadlineExceeded))
+	assert.True(t, errors.Is(p.Send(heartbeatMsg), protocol.ErrWriteDeadlineExceeded))
+	assert.True(t, errors.Is(p.Send(heartbeatMsg), protocol.ErrStreamDeadlinesExceeded))
+}
+
+func TestBandwidthLimits(t *testing.T) {
+	local, remote := net.Pipe()
+	defer local.Close()
+	defer remote.Close()
+	go func() {
+		_, _ = io.Copy(ioutil.Discard, remote)
+	}()
+
+	heartbeatMsg, err := sting.NewHeartbeatMessage(1, 1, 1, 1, 1)
+	assert.NoError(t, err)
+
+	// the burst covers four messages, the other two have to wait half a second
+	p := protocol.New(local)
+	p.SetBandwidthLimits(4*len(heartbeatMsg), 0)
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		assert.NoError(t, p.Send(heartbeatMsg))
+	}
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(400*time.Millisecond))
+}
+
+func TestKnownTransactionsFilter(t *testing.T) {
+	filter, err := sting.NewKnownTransactionsFilter(100)
+	assert.NoError(t, err)
+
+	hashes := make([][]byte, 100)
+	for i := range hashes {
+		hashes[i] = make([]byte, 49)
+		_, err := rand.Read(hashes[i])
+		assert.NoError(t, err)
+		filter.Add(hashes[i])
+	}
+
+	knownTransactionsMsg, err := sting.NewKnownTransactionsMessage(filter)
+	assert.NoError(t, err)
+
+	parsed, err := sting.ParseKnownTransactions(knownTransactionsMsg[tlv.HeaderBytesLength:])
+	assert.NoError(t, err)
+	for _, hash := range hashes {
+		assert.True(t, parsed.Contains(hash))
+	}
+
+	// an empty filter doesn't contain any transaction
+	empty, err := sting.NewKnownTransactionsFilter(0)
+	assert.NoError(t, err)
+	assert.False(t, empty.Contains(hashes[0]))
+
+	_, err = sting.NewKnownTransactionsFilter(sting.MaxKnownTransactionsFilterBytes)
+	assert.Equal(t, sting.ErrTooManyKnownTransactions, err)
+
+	// a filter without hash functions would contain every transaction
+	_, err = sting.ParseKnownTransactions([]byte{0, 0xff})
+	assert.Equal(t, sting.ErrInvalidKnownTransactionsFilter, err)
+}
+
+func TestInboundFilters(t *testing.T) {
+	conn := newFakeConn()
+	defer conn.Close()
+	p := protocol.New(conn)
+
+	// drops the heartbeats of autopeered peers and replaces their neighbor suggestions
+	unregister := protocol.RegisterInboundFilter(func(_ *protocol.Protocol, msg *protocol.InboundMessage) ([]byte, bool) {
+		if msg.Relation != protocol.RelationAutopeered {
+			return msg.Data, true
+		}
+		switch msg.Type {
+		case sting.MessageTypeHeartbeat:
+			return nil, false
+		case sting.MessageTypeNeighborSuggestions:
+			filtered, err := sting.NewNeighborSuggestionsMessage([]string{"example.com:15600"})
+			assert.NoError(t, err)
+			return filtered[tlv.HeaderBytesLength:], true
+		}
+		return msg.Data, true
+	})
+	defer unregister()
+
+	var heartbeats int
+	p.Events.Received[sting.MessageTypeHeartbeat].Attach(events.NewClosure(func(_ []byte) {
+		heartbeats++
+	}))
+	var suggestions []string
+	p.Events.Received[sting.MessageTypeNeighborSuggestions].Attach(events.NewClosure(func(data []byte) {
+		parsed, err := sting.ParseNeighborSuggestions(data)
+		assert.NoError(t, err)
+		suggestions = parsed
+	}))
+
+	heartbeatMsg, err := sting.NewHeartbeatMessage(1, 1, 1, 1, 1)
+	assert.NoError(t, err)
+	suggestionsMsg, err := sting.NewNeighborSuggestionsMessage([]string{"example.com:15601", "[::1]:15601"})
+	assert.NoError(t, err)
+
+	p.SetRelation(protocol.RelationStatic)
+	assert.NoError(t, p.Dispatch(heartbeatMsg))
+	assert.Equal(t, 1, heartbeats)
+
+	p.SetRelation(protocol.RelationAutopeered)
+	assert.NoError(t, p.Dispatch(heartbeatMsg))
+	assert.NoError(t, p.Dispatch(suggestionsMsg))
+	assert.Equal(t, 1, heartbeats)
+	assert.Equal(t, []string{"example.com:15600"}, suggestions)
+	assert.EqualValues(t, 1, p.Stats().MessagesFiltered)
+
+	// the messages aren't filtered anymore once the filter is removed
+	unregister()
+	assert.NoError(t, p.Dispatch(heartbeatMsg))
+	assert.NoError(t, p.Dispatch(suggestionsMsg))
+	assert.Equal(t, 2, heartbeats)
+	assert.Equal(t, []string{"example.com:15601", "[::1]:15601"}, suggestions)
+	assert.EqualValues(t, 1, p.Stats().MessagesFiltered)
+}
+
+func TestProtocolTestPair(t *testing.T) {
+	pair := protocoltest.NewPair(sting.FeatureSet, false)
+	defer pair.Close()
+	assert.True(t, pair.Local.Handshaked())
+	assert.Equal(t, protocol.RelationStatic, pair.Local.Protocol.Relation())
+
+	var heartbeats []*sting.Heartbeat
+	pair.Local.Protocol.Events.Received[sting.MessageTypeHeartbeat].Attach(events.NewClosure(func(data []byte) {
+		heartbeats = append(heartbeats, sting.ParseHeartbeat(data))
+	}))
+
+	// the heartbeat is answered by the local peer
+	requestMsg, err := sting.NewMilestoneRequestMessage(2)
+	assert.NoError(t, err)
+	var requests int
+	pair.Remote.Protocol.Events.Received[sting.MessageTypeMilestoneRequest].Attach(events.NewClosure(func(_ []byte) {
+		requests++
+	}))
+	pair.Local.Protocol.Events.Received[sting.MessageTypeHeartbeat].Attach(events.NewClosure(func(_ []byte) {
+		pair.Local.EnqueueForSending(requestMsg)
+		assert.NoError(t, pair.SendQueued())
+	}))
+
+	heartbeatMsg, err := sting.NewHeartbeatMessage(2, 1, 1, 1, 1)
+	assert.NoError(t, err)
+	pair.Remote.EnqueueForSending(heartbeatMsg)
+
+	// nothing is received before the data is delivered
+	assert.NoError(t, pair.SendQueued())
+	assert.Empty(t, heartbeats)
+
+	assert.Equal(t, len(heartbeatMsg)+len(requestMsg), pair.Deliver())
+	assert.Len(t, heartbeats, 1)
+	assert.EqualValues(t, 2, heartbeats[0].SolidMilestoneIndex)
+	assert.Equal(t, 1, requests)
+}
+
+func TestSendQueueWatermarks(t *testing.T) {
+	pair := protocoltest.NewPair(sting.FeatureSet, false)
+	defer pair.Close()
+
+	p := pair.Local
+	p.SendQueueHighWatermark = 3
+	p.SendQueueLowWatermark = 1
+
+	var congested, relieved int
+	p.Events.SendQueueHighWatermarkReached.Attach(events.NewClosure(func() {
+		congested++
+	}))
+	p.Events.SendQueueLowWatermarkReached.Attach(events.NewClosure(func() {
+		relieved++
+	}))
+
+	msg, err := sting.NewMilestoneRequestMessage(1)
+	assert.NoError(t, err)
+
+	for i := 0; i < 4; i++ {
+		p.EnqueueForSending(msg)
+	}
+	assert.True(t, p.SendQueueCongested())
+	assert.Equal(t, 1, congested)
+
+	// the queue is still above the low watermark
+	p.DequeuedForSending(<-p.SendQueue)
+	p.DequeuedForSending(<-p.SendQueue)
+	assert.True(t, p.SendQueueCongested())
+	assert.Equal(t, 0, relieved)
+
+	assert.NoError(t, pair.SendQueued())
+	assert.False(t, p.SendQueueCongested())
+	assert.Equal(t, 1, relieved)
+	assert.Equal(t, 1, congested)
+}
+
+func TestParseRelation(t *testing.T) {
+	for _, relation := range []protocol.Relation{protocol.RelationUnknown, protocol.RelationStatic, protocol.RelationAutopeered} {
+		parsed, err := protocol.ParseRelation(relation.String())
+		assert.NoError(t, err)
+		assert.Equal(t, relation, parsed)
+	}
+
+	parsed, err := protocol.ParseRelation("Autopeered")
+	assert.NoError(t, err)
+	assert.Equal(t, protocol.RelationAutopeered, parsed)
+
+	_, err = protocol.ParseRelation("relay")
+	assert.True(t, errors.Is(err, protocol.ErrUnknownRelation))
+}
@@ -0,0 +1,230 @@
+// Package protocoltest provides in-memory connections and peers to test the gossip handlers
+// without opening TCP connections.
+// The data written to a connection is buffered until it is delivered to the protocol of the other side,
+// so that tests control when messages are received and don't depend on the scheduling of goroutines.
+package protocoltest
+
+import (
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/hive.go/iputils"
+	"github.com/iotaledger/hive.go/network"
+
+	"github.com/gohornet/hornet/pkg/peering/peer"
+	"github.com/gohornet/hornet/pkg/protocol"
+	"github.com/gohornet/hornet/pkg/protocol/sting"
+)
+
+var (
+	// ErrConnClosed is returned when data is written to a closed connection.
+	ErrConnClosed = errors.New("connection is closed")
+)
+
+// Conn is an in-memory connection which buffers the written data until it is taken by the test.
+// Reads block until the connection is closed, since received data is passed to the protocol directly.
+type Conn struct {
+	mu      sync.Mutex
+	written []byte
+	closed  chan struct{}
+	once    sync.Once
+	local   net.Addr
+	remote  net.Addr
+}
+
+// NewConn creates a new in-memory connection between the given addresses.
+func NewConn(local net.Addr, remote net.Addr) *Conn {
+	return &Conn{closed: make(chan struct{}), local: local, remote: remote}
+}
+
+// Read blocks until the connection is closed.
+func (c *Conn) Read(_ []byte) (int, error) {
+	<-c.closed
+	return 0, io.EOF
+}
+
+// Write buffers the given data until it is taken.
+func (c *Conn) Write(data []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select {
+	case <-c.closed:
+		return 0, ErrConnClosed
+	default:
+	}
+	c.written = append(c.written, data...)
+	return len(data), nil
+}
+
+// Take returns the data written since the last call and clears the buffer.
+func (c *Conn) Take() []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	written := c.written
+	c.written = nil
+	return written
+}
+
+// Close closes the connection, further writes fail.
+func (c *Conn) Close() error {
+	c.once.Do(func() { close(c.closed) })
+	return nil
+}
+
+// Closed tells whether the connection was closed.
+func (c *Conn) Closed() bool {
+	select {
+	case <-c.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// LocalAddr returns the local address of the connection.
+func (c *Conn) LocalAddr() net.Addr {
+	return c.local
+}
+
+// RemoteAddr returns the remote address of the connection.
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+// SetDeadline does nothing, since the in-memory connection never exceeds a deadline.
+func (c *Conn) SetDeadline(_ time.Time) error {
+	return nil
+}
+
+// SetReadDeadline does nothing, since the in-memory connection never exceeds a deadline.
+func (c *Conn) SetReadDeadline(_ time.Time) error {
+	return nil
+}
+
+// SetWriteDeadline does nothing, since the in-memory connection never exceeds a deadline.
+func (c *Conn) SetWriteDeadline(_ time.Time) error {
+	return nil
+}
+
+// Pair are two handshaked peers connected over in-memory connections.
+// The peers are not registered with a peering manager, so their send queues aren't sent automatically.
+type Pair struct {
+	// The peer representing the remote node on the local node.
+	Local *peer.Peer
+	// The peer representing the local node on the remote node.
+	Remote *peer.Peer
+
+	localConn  *Conn
+	remoteConn *Conn
+}
+
+// NewPair creates two peers which are connected to each other and handshaked with the given feature set.
+// The local peer is inbound and the remote peer outbound, autopeered marks both as autopeered.
+func NewPair(featureSet byte, autopeered bool) *Pair {
+	localAddr := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 15600}
+	remoteAddr := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 2), Port: 15600}
+
+	pair := &Pair{
+		localConn:  NewConn(localAddr, remoteAddr),
+		remoteConn: NewConn(remoteAddr, localAddr),
+	}
+	pair.Local = newPeer(pair.localConn, remoteAddr, peer.Inbound, featureSet, autopeered)
+	pair.Remote = newPeer(pair.remoteConn, localAddr, peer.Outbound, featureSet, autopeered)
+	return pair
+}
+
+// creates a handshaked peer which writes to the given connection.
+func newPeer(conn *Conn, remoteAddr *net.TCPAddr, origin peer.ConnectionOrigin, featureSet byte, autopeered bool) *peer.Peer {
+	addresses := iputils.NewIPAddresses()
+	addresses.Add(remoteAddr.IP)
+
+	p := peer.NewOutboundPeer(&iputils.OriginAddress{Addr: remoteAddr.IP.String(), Port: uint16(remoteAddr.Port)}, remoteAddr.IP, uint16(remoteAddr.Port), addresses)
+	p.ConnectionOrigin = origin
+	p.Conn = network.NewManagedConnection(conn)
+	p.Protocol = protocol.New(p.Conn)
+	p.Protocol.FeatureSet = featureSet
+	p.Protocol.Version = 1
+	if p.Protocol.Supports(sting.FeatureSet) {
+		p.Protocol.Version = sting.ProtocolVersion
+	}
+
+	relation := protocol.RelationStatic
+	if autopeered {
+		relation = protocol.RelationAutopeered
+	}
+	p.Protocol.SetRelation(relation)
+
+	p.Protocol.Handshaked()
+	p.Protocol.Handshaked()
+	return p
+}
+
+// Deliver passes the data written by each side to the protocol of the other side
+// until no more data is written in response and returns the amount of delivered bytes.
+func (pair *Pair) Deliver() int {
+	delivered := 0
+	for {
+		toRemote := pair.localConn.Take()
+		if len(toRemote) != 0 {
+			pair.Remote.Protocol.Receive(toRemote)
+		}
+
+		toLocal := pair.remoteConn.Take()
+		if len(toLocal) != 0 {
+			pair.Local.Protocol.Receive(toLocal)
+		}
+
+		if len(toRemote) == 0 && len(toLocal) == 0 {
+			return delivered
+		}
+		delivered += len(toRemote) + len(toLocal)
+	}
+}
+
+// SendQueued sends the messages enqueued for both peers over their protocols,
+// so that they can be delivered afterwards.
+// The messages are sent as they are, large messages aren't split into chunks.
+func (pair *Pair) SendQueued() error {
+	for _, p := range []*peer.Peer{pair.Local, pair.Remote} {
+		if err := sendQueued(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sends the messages of the priority and the normal send queue of the given peer.
+// messages in the priority send queue bypass the ones in the send queue, so the order is deterministic.
+func sendQueued(p *peer.Peer) error {
+	for {
+		select {
+		case msg := <-p.PrioritySendQueue:
+			if err := p.Protocol.Send(msg); err != nil {
+				return err
+			}
+			continue
+		default:
+		}
+
+		select {
+		case msg := <-p.SendQueue:
+			p.DequeuedForSending(msg)
+			if err := p.Protocol.Send(msg); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+// Close closes the connections of both peers.
+func (pair *Pair) Close() {
+	_ = pair.Local.Conn.Close()
+	_ = pair.Remote.Conn.Close()
+}
@@ -18,6 +18,7 @@ type Queue interface {
 	// Peek returns the next request to send without popping it from the queue.
 	Peek() *Request
 	// Enqueue enqueues the given request if it isn't already queued or pending.
+	// If a priority request is enqueued for a hash which is already queued or pending, the existing request is prioritized.
 	Enqueue(*Request) (enqueued bool)
 	// IsQueued tells whether a given request for the given transaction hash is queued.
 	IsQueued(hash hornet.Hash) bool
@@ -55,7 +56,7 @@ type FilterFunc func(r *Request) bool
 
 const DefaultLatencyResolution = 100
 
-// New creates a new Queue where request are prioritized over their milestone index (lower = higher priority).
+// New creates a new Queue where request are prioritized over their priority flag and then over their milestone index (lower = higher priority).
 func New(latencyResolution ...int32) Queue {
 	q := &priorityqueue{
 		queue:      make([]*Request, 0),
@@ -81,12 +82,16 @@ type Request struct {
 	// Tells the request queue to not remove this request if the enqueue time is
 	// over the given threshold.
 	PreventDiscard bool
+	// Tells the request queue to pop this request before all non priority requests,
+	// e.g. because the requested transaction blocks the solidification of a milestone.
+	// Priority requests are never discarded.
+	Priority bool
 	// the time at which this request was first enqueued.
 	// do not modify this time
 	EnqueueTime time.Time
 }
 
-// implements a priority queue where requests with the lowest milestone index are popped first.
+// implements a priority queue where priority requests and then requests with the lowest milestone index are popped first.
 type priorityqueue struct {
 	// must be first field for 64-bit alignment.
 	// otherwise it crashes under 32-bit ARM systems
@@ -116,10 +121,18 @@ func (pq *priorityqueue) Next() (r *Request) {
 func (pq *priorityqueue) Enqueue(r *Request) bool {
 	pq.Lock()
 	defer pq.Unlock()
-	if _, queued := pq.queued[string(r.Hash)]; queued {
+	if queuedRequest, queued := pq.queued[string(r.Hash)]; queued {
+		if r.Priority && !queuedRequest.Priority {
+			queuedRequest.Priority = true
+			heap.Fix(pq, queuedRequest.index)
+		}
 		return false
 	}
-	if _, pending := pq.pending[string(r.Hash)]; pending {
+	if pendingRequest, pending := pq.pending[string(r.Hash)]; pending {
+		if r.Priority {
+			// the request is popped first once it is enqueued again
+			pendingRequest.Priority = true
+		}
 		return false
 	}
 	if _, processing := pq.processing[string(r.Hash)]; processing {
@@ -206,7 +219,7 @@ func (pq *priorityqueue) EnqueuePending(discardOlderThan time.Duration) int {
 			enqueued--
 			continue
 		}
-		if discardOlderThan == 0 || v.PreventDiscard || s.Sub(v.EnqueueTime) < discardOlderThan {
+		if discardOlderThan == 0 || v.PreventDiscard || v.Priority || s.Sub(v.EnqueueTime) < discardOlderThan {
 			// no need to examine the queued set
 			// as addition and removal are synced over Push and Pops
 			heap.Push(pq, v)
@@ -288,6 +301,10 @@ func (pq *priorityqueue) Filter(f FilterFunc) {
 func (pq *priorityqueue) Len() int { return len(pq.queue) }
 
 func (pq *priorityqueue) Less(i, j int) bool {
+	// priority requests are popped before all other requests
+	if pq.queue[i].Priority != pq.queue[j].Priority {
+		return pq.queue[i].Priority
+	}
 	// requests for older milestones (lower number) have priority
 	return pq.queue[i].MilestoneIndex < pq.queue[j].MilestoneIndex
 }
@@ -300,6 +317,7 @@ func (pq *priorityqueue) Swap(i, j int) {
 
 func (pq *priorityqueue) Push(x interface{}) {
 	r := x.(*Request)
+	r.index = len(pq.queue)
 	pq.queue = append(pq.queue, r)
 
 	// mark as queued and remove from pending
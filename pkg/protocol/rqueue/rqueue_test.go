@@ -125,3 +125,32 @@ func TestRequestQueue(t *testing.T) {
 	assert.Zero(t, len(pendingReqs))
 	assert.Zero(t, len(processingReq))
 }
+
+func TestRequestQueuePriority(t *testing.T) {
+	q := rqueue.New()
+
+	var (
+		hashA = hornet.Hash(t5b1.EncodeTrytes("A"))
+		hashB = hornet.Hash(t5b1.EncodeTrytes("B"))
+		hashC = hornet.Hash(t5b1.EncodeTrytes("C"))
+	)
+
+	assert.True(t, q.Enqueue(&rqueue.Request{Hash: hashA, MilestoneIndex: 2}))
+	assert.True(t, q.Enqueue(&rqueue.Request{Hash: hashB, MilestoneIndex: 10, Priority: true}))
+	assert.True(t, q.Enqueue(&rqueue.Request{Hash: hashC, MilestoneIndex: 5}))
+
+	// enqueueing a priority request for a queued hash prioritizes the queued request
+	assert.False(t, q.Enqueue(&rqueue.Request{Hash: hashC, MilestoneIndex: 5, Priority: true}))
+
+	// priority requests are popped first, ordered by their milestone index
+	assert.Equal(t, hashC, q.Next().Hash)
+	assert.Equal(t, hashB, q.Next().Hash)
+	assert.Equal(t, hashA, q.Next().Hash)
+	assert.Nil(t, q.Next())
+
+	// priority requests are never discarded
+	assert.Equal(t, 2, q.EnqueuePending(1))
+	assert.True(t, q.IsQueued(hashB))
+	assert.True(t, q.IsQueued(hashC))
+	assert.False(t, q.IsQueued(hashA))
+}
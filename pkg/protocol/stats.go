@@ -0,0 +1,53 @@
+package protocol
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Stats holds the traffic statistics of a protocol instance.
+type Stats struct {
+	// The amount of bytes received over the stream.
+	BytesIn uint64 `json:"bytesIn"`
+	// The amount of bytes sent over the stream.
+	BytesOut uint64 `json:"bytesOut"`
+	// The amount of messages received over the stream (chunks count as separate messages).
+	MessagesIn uint64 `json:"messagesIn"`
+	// The amount of messages sent over the stream (chunks count as separate messages).
+	MessagesOut uint64 `json:"messagesOut"`
+	// The amount of received messages dropped by the inbound filters.
+	MessagesFiltered uint64 `json:"messagesFiltered"`
+	// The time since the protocol instance was created.
+	Uptime time.Duration `json:"-"`
+}
+
+// Stats returns a snapshot of the traffic statistics of the protocol.
+func (p *Protocol) Stats() *Stats {
+	return &Stats{
+		BytesIn:          atomic.LoadUint64(&p.bytesIn),
+		BytesOut:         atomic.LoadUint64(&p.bytesOut),
+		MessagesIn:       atomic.LoadUint64(&p.messagesIn),
+		MessagesOut:      atomic.LoadUint64(&p.messagesOut),
+		MessagesFiltered: atomic.LoadUint64(&p.messagesFiltered),
+		Uptime:           time.Since(p.created),
+	}
+}
+
+// counts the given message which was written to the underlying writer.
+func (p *Protocol) countSent(msg []byte) {
+	atomic.AddUint64(&p.bytesOut, uint64(len(msg)))
+	atomic.AddUint64(&p.messagesOut, 1)
+	atomic.StoreInt64(&p.lastSent, time.Now().UnixNano())
+}
+
+// LastReceived returns the time data was last received over the stream.
+// Returns the creation time of the protocol if no data was received yet.
+func (p *Protocol) LastReceived() time.Time {
+	return time.Unix(0, atomic.LoadInt64(&p.lastReceived))
+}
+
+// LastSent returns the time a message was last sent over the stream.
+// Returns the creation time of the protocol if no message was sent yet.
+func (p *Protocol) LastSent() time.Time {
+	return time.Unix(0, atomic.LoadInt64(&p.lastSent))
+}
@@ -0,0 +1,182 @@
+package sting
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/binary"
+	"errors"
+
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/protocol/message"
+	"github.com/gohornet/hornet/pkg/protocol/tlv"
+)
+
+// FeatureSetCapabilities denotes the capability bit for the capabilities record extension.
+// It is announced alongside the protocol version in the handshake and only used if both peers support it.
+const FeatureSetCapabilities = 1 << 5
+
+// FeatureSetCapabilitiesName is the name of the capabilities record capability.
+const FeatureSetCapabilitiesName = "Capabilities"
+
+const (
+	MessageTypeCapabilities message.Type = 9
+
+	// The maximum length of the advertised API address.
+	MaxCapabilitiesAPIAddressLength = 255
+)
+
+// Service is a service a node offers to other nodes.
+type Service byte
+
+const (
+	// ServiceSnapshots means the node serves local snapshot files.
+	ServiceSnapshots Service = 1 << 0
+	// ServicePublicAPI means the web API of the node is publicly reachable.
+	ServicePublicAPI Service = 1 << 1
+	// ServicePermanode means the node doesn't prune its database.
+	ServicePermanode Service = 1 << 2
+	// ServiceKnownTransactions means the node exchanges filters of its recently received transactions.
+	ServiceKnownTransactions Service = 1 << 3
+	// ServicePeerExchange means the node shares signed lists of reachable peers with its trusted peers.
+	ServicePeerExchange Service = 1 << 4
+	// ServiceLatencyProbe means the node answers latency pings.
+	ServiceLatencyProbe Service = 1 << 5
+)
+
+var serviceNames = []struct {
+	service Service
+	name    string
+}{
+	{ServiceSnapshots, "snapshots"},
+	{ServicePublicAPI, "publicAPI"},
+	{ServicePermanode, "permanode"},
+	{ServiceKnownTransactions, "knownTransactions"},
+	{ServicePeerExchange, "peerExchange"},
+	{ServiceLatencyProbe, "latencyProbe"},
+}
+
+var (
+	// ErrInvalidCapabilitiesSignature is returned when the signature of a capabilities record is invalid.
+	ErrInvalidCapabilitiesSignature = errors.New("invalid capabilities signature")
+	// ErrInvalidCapabilitiesAPIAddress is returned when the advertised API address is too long.
+	ErrInvalidCapabilitiesAPIAddress = errors.New("invalid capabilities API address")
+	// ErrUnknownService is returned when a service name is unknown.
+	ErrUnknownService = errors.New("unknown service")
+
+	// The capabilities packet.
+	// Made up of the public key of the node (32 bytes), the timestamp (8 bytes), the pruning index (4 bytes),
+	// the offered services (1 byte), the API address prefixed with its length (1 byte)
+	// and the signature over all previous bytes (64 bytes).
+	CapabilitiesMessageDefinition = &message.Definition{
+		ID:             MessageTypeCapabilities,
+		MaxBytesLength: ed25519.PublicKeySize + 8 + 4 + 1 + 1 + MaxCapabilitiesAPIAddressLength + ed25519.SignatureSize,
+		VariableLength: true,
+	}
+)
+
+// Capabilities is the signed record of the services a node offers to other nodes.
+type Capabilities struct {
+	// The public key the record was signed with.
+	PublicKey ed25519.PublicKey
+	// The unix timestamp at which the record was created.
+	Timestamp int64
+	// The index up to which the node pruned its database.
+	PruningIndex milestone.Index
+	// The services the node offers.
+	Services Service
+	// The publicly reachable address of the web API, if advertised.
+	APIAddress string
+}
+
+// Offers tells whether all the given services are offered.
+func (c *Capabilities) Offers(services Service) bool {
+	return c.Services&services == services
+}
+
+// ServiceNames returns the names of the given services.
+func ServiceNames(services Service) []string {
+	names := []string{}
+	for _, s := range serviceNames {
+		if services&s.service != 0 {
+			names = append(names, s.name)
+		}
+	}
+	return names
+}
+
+// ParseServiceNames parses the given service names.
+func ParseServiceNames(names []string) (Service, error) {
+	var services Service
+	for _, name := range names {
+		found := false
+		for _, s := range serviceNames {
+			if s.name == name {
+				services |= s.service
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, ErrUnknownService
+		}
+	}
+	return services, nil
+}
+
+// NewCapabilitiesMessage creates a new capabilities message which is signed with the given key.
+func NewCapabilitiesMessage(capabilities *Capabilities, key ed25519.PrivateKey) ([]byte, error) {
+	if len(capabilities.APIAddress) > MaxCapabilitiesAPIAddressLength {
+		return nil, ErrInvalidCapabilitiesAPIAddress
+	}
+
+	record := bytes.NewBuffer(make([]byte, 0, CapabilitiesMessageDefinition.MaxBytesLength))
+	record.Write(key.Public().(ed25519.PublicKey))
+	if err := binary.Write(record, binary.BigEndian, capabilities.Timestamp); err != nil {
+		return nil, err
+	}
+	if err := binary.Write(record, binary.BigEndian, uint32(capabilities.PruningIndex)); err != nil {
+		return nil, err
+	}
+	record.WriteByte(byte(capabilities.Services))
+	record.WriteByte(byte(len(capabilities.APIAddress)))
+	record.WriteString(capabilities.APIAddress)
+	record.Write(ed25519.Sign(key, record.Bytes()))
+
+	msgBytesLength := uint16(record.Len())
+	buf := bytes.NewBuffer(make([]byte, 0, tlv.HeaderMessageDefinition.MaxBytesLength+msgBytesLength))
+	if err := tlv.WriteHeader(buf, MessageTypeCapabilities, msgBytesLength); err != nil {
+		return nil, err
+	}
+	buf.Write(record.Bytes())
+
+	return buf.Bytes(), nil
+}
+
+// ParseCapabilities parses the given message into a capabilities record and verifies its signature.
+func ParseCapabilities(source []byte) (*Capabilities, error) {
+	const fixedLength = ed25519.PublicKeySize + 8 + 4 + 1 + 1
+
+	if len(source) < fixedLength+ed25519.SignatureSize {
+		return nil, ErrInvalidSourceLength
+	}
+
+	apiAddressLength := int(source[fixedLength-1])
+	signedLength := fixedLength + apiAddressLength
+	if len(source) != signedLength+ed25519.SignatureSize {
+		return nil, ErrInvalidSourceLength
+	}
+
+	publicKey := ed25519.PublicKey(append([]byte{}, source[:ed25519.PublicKeySize]...))
+	if !ed25519.Verify(publicKey, source[:signedLength], source[signedLength:]) {
+		return nil, ErrInvalidCapabilitiesSignature
+	}
+
+	offset := ed25519.PublicKeySize
+	return &Capabilities{
+		PublicKey:    publicKey,
+		Timestamp:    int64(binary.BigEndian.Uint64(source[offset : offset+8])),
+		PruningIndex: milestone.Index(binary.BigEndian.Uint32(source[offset+8 : offset+12])),
+		Services:     Service(source[offset+12]),
+		APIAddress:   string(source[fixedLength:signedLength]),
+	}, nil
+}
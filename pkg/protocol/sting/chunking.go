@@ -0,0 +1,169 @@
+package sting
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	"github.com/gohornet/hornet/pkg/protocol/message"
+	"github.com/gohornet/hornet/pkg/protocol/tlv"
+)
+
+// FeatureSetChunking denotes the capability bit for the chunked message extension.
+// It is announced alongside the protocol version in the handshake and only used if both peers support it.
+const FeatureSetChunking = 1 << 6
+
+// FeatureSetChunkingName is the name of the chunked message capability.
+const FeatureSetChunkingName = "Chunking"
+
+const (
+	MessageTypeChunk message.Type = 10
+
+	// The amount of bytes used for the header of a chunk (message ID, chunk index and chunk count).
+	ChunkHeaderBytesLength = 4 + 2 + 2
+
+	// The maximum amount of message bytes within a single chunk.
+	MaxChunkDataLength = 16384
+
+	// The maximum length of a message which can be split into chunks (TLV header included).
+	MaxChunkedMessageLength = tlv.HeaderBytesLength + 65535
+)
+
+var (
+	// ErrInvalidChunk is returned when a chunk doesn't continue the message which is currently reassembled.
+	ErrInvalidChunk = errors.New("invalid chunk")
+	// ErrInvalidChunkSize is returned when the size of the chunks is out of range.
+	ErrInvalidChunkSize = errors.New("invalid chunk size")
+
+	// The chunk packet.
+	// Made up of the ID of the chunked message (4 bytes), the index of the chunk (2 bytes),
+	// the total amount of chunks of the message (2 bytes) and a part of the chunked message.
+	ChunkMessageDefinition = &message.Definition{
+		ID:             MessageTypeChunk,
+		MaxBytesLength: ChunkHeaderBytesLength + MaxChunkDataLength,
+		VariableLength: true,
+	}
+)
+
+// Chunk is a part of a message which was split to be sent in smaller frames.
+type Chunk struct {
+	// The ID of the chunked message.
+	MessageID uint32
+	// The index of the chunk within the message.
+	Index uint16
+	// The total amount of chunks of the message.
+	Count uint16
+	// The part of the message.
+	Data []byte
+}
+
+// NewChunkMessages splits the given message (TLV header included) into chunk messages
+// which carry at most chunkSize bytes of the message each.
+func NewChunkMessages(messageID uint32, msg []byte, chunkSize int) ([][]byte, error) {
+	if chunkSize <= 0 || chunkSize > MaxChunkDataLength {
+		return nil, ErrInvalidChunkSize
+	}
+
+	if len(msg) == 0 || len(msg) > MaxChunkedMessageLength {
+		return nil, ErrInvalidSourceLength
+	}
+
+	count := (len(msg) + chunkSize - 1) / chunkSize
+
+	chunkMsgs := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		data := msg[i*chunkSize:]
+		if len(data) > chunkSize {
+			data = data[:chunkSize]
+		}
+
+		msgBytesLength := uint16(ChunkHeaderBytesLength + len(data))
+		buf := bytes.NewBuffer(make([]byte, 0, tlv.HeaderMessageDefinition.MaxBytesLength+msgBytesLength))
+		if err := tlv.WriteHeader(buf, MessageTypeChunk, msgBytesLength); err != nil {
+			return nil, err
+		}
+		if err := binary.Write(buf, binary.BigEndian, messageID); err != nil {
+			return nil, err
+		}
+		if err := binary.Write(buf, binary.BigEndian, uint16(i)); err != nil {
+			return nil, err
+		}
+		if err := binary.Write(buf, binary.BigEndian, uint16(count)); err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+
+		chunkMsgs = append(chunkMsgs, buf.Bytes())
+	}
+
+	return chunkMsgs, nil
+}
+
+// ParseChunk parses the given message into a chunk.
+func ParseChunk(source []byte) (*Chunk, error) {
+	if len(source) <= ChunkHeaderBytesLength {
+		return nil, ErrInvalidSourceLength
+	}
+
+	return &Chunk{
+		MessageID: binary.BigEndian.Uint32(source[0:4]),
+		Index:     binary.BigEndian.Uint16(source[4:6]),
+		Count:     binary.BigEndian.Uint16(source[6:8]),
+		Data:      source[ChunkHeaderBytesLength:],
+	}, nil
+}
+
+// ChunkReassembler reassembles the chunked messages received from a single peer.
+// The chunks of a message are sent consecutively, so only one message is reassembled at a time.
+// It is not safe for concurrent use.
+type ChunkReassembler struct {
+	messageID uint32
+	next      uint16
+	count     uint16
+	buf       []byte
+}
+
+// Add adds the given chunk to the message which is currently reassembled.
+// The first chunk of a message discards any incomplete previous message.
+// Returns the complete message (TLV header included) if the chunk was the last one of the message, otherwise nil.
+func (r *ChunkReassembler) Add(chunk *Chunk) ([]byte, error) {
+	if chunk.Count == 0 || chunk.Index >= chunk.Count {
+		return nil, ErrInvalidChunk
+	}
+
+	if chunk.Index == 0 {
+		r.messageID = chunk.MessageID
+		r.count = chunk.Count
+		r.next = 0
+		r.buf = nil
+	}
+
+	// no message is reassembled if the count is 0
+	if chunk.MessageID != r.messageID || chunk.Index != r.next || chunk.Count != r.count {
+		r.reset()
+		return nil, ErrInvalidChunk
+	}
+
+	if len(r.buf)+len(chunk.Data) > MaxChunkedMessageLength {
+		r.reset()
+		return nil, ErrInvalidChunk
+	}
+
+	r.buf = append(r.buf, chunk.Data...)
+	r.next++
+
+	if r.next != r.count {
+		return nil, nil
+	}
+
+	msg := r.buf
+	r.reset()
+	return msg, nil
+}
+
+func (r *ChunkReassembler) reset() {
+	r.messageID = 0
+	r.next = 0
+	r.count = 0
+	r.buf = nil
+}
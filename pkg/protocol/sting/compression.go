@@ -0,0 +1,127 @@
+package sting
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/golang/snappy"
+
+	"github.com/gohornet/hornet/pkg/protocol/message"
+	"github.com/gohornet/hornet/pkg/protocol/tlv"
+)
+
+// FeatureSetCompression denotes the capability bit for the compressed message extension.
+// It is announced alongside the protocol version in the handshake and only used if both peers support it.
+// Peers announcing it must be able to decompress messages of all the codecs defined below.
+const FeatureSetCompression = 1 << 7
+
+// FeatureSetCompressionName is the name of the compressed message capability.
+const FeatureSetCompressionName = "Compression"
+
+// CompressionCodec is the codec used to compress the payload of a message.
+type CompressionCodec byte
+
+const (
+	// CompressionSnappy compresses the payload with snappy.
+	CompressionSnappy CompressionCodec = 1
+)
+
+const (
+	MessageTypeCompressed message.Type = 11
+
+	// The amount of bytes used for the header of a compressed message (codec and message type).
+	CompressedHeaderBytesLength = 2
+
+	// The maximum length of the payload of a message which gets compressed.
+	MaxCompressedPayloadLength = 16384
+)
+
+var (
+	// ErrInvalidCompressedMessage is returned when a compressed message can't be decompressed
+	// or carries a message type which is never compressed.
+	ErrInvalidCompressedMessage = errors.New("invalid compressed message")
+	// ErrUnknownCompressionCodec is returned when a message was compressed with an unknown codec.
+	ErrUnknownCompressionCodec = errors.New("unknown compression codec")
+
+	// The compressed packet.
+	// Made up of the codec (1 byte), the type of the compressed message (1 byte)
+	// and the compressed payload of the message.
+	CompressedMessageDefinition = &message.Definition{
+		ID:             MessageTypeCompressed,
+		MaxBytesLength: uint16(CompressedHeaderBytesLength + snappy.MaxEncodedLen(MaxCompressedPayloadLength)),
+		VariableLength: true,
+	}
+)
+
+// returns whether messages of the given type can be carried by a compressed message.
+func compressible(msgType message.Type) bool {
+	switch msgType {
+	case tlv.MessageTypeHeader, MessageTypeCompressed, MessageTypeChunk:
+		return false
+	default:
+		return true
+	}
+}
+
+// NewCompressedMessage compresses the payload of the given message (TLV header included) with the given codec.
+// Returns nil if the message can't be compressed or the compressed message wouldn't be smaller than the given one.
+func NewCompressedMessage(codec CompressionCodec, msg []byte) ([]byte, error) {
+	if codec != CompressionSnappy {
+		return nil, ErrUnknownCompressionCodec
+	}
+
+	if len(msg) <= tlv.HeaderBytesLength || len(msg)-tlv.HeaderBytesLength > MaxCompressedPayloadLength || !compressible(message.Type(msg[0])) {
+		return nil, nil
+	}
+
+	compressed := snappy.Encode(nil, msg[tlv.HeaderBytesLength:])
+
+	msgBytesLength := uint16(CompressedHeaderBytesLength + len(compressed))
+	if int(msgBytesLength)+tlv.HeaderBytesLength >= len(msg) {
+		return nil, nil
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, tlv.HeaderMessageDefinition.MaxBytesLength+msgBytesLength))
+	if err := tlv.WriteHeader(buf, MessageTypeCompressed, msgBytesLength); err != nil {
+		return nil, err
+	}
+	buf.WriteByte(byte(codec))
+	buf.WriteByte(msg[0])
+	buf.Write(compressed)
+
+	return buf.Bytes(), nil
+}
+
+// Decompress decompresses the given compressed message into the carried message (TLV header included).
+func Decompress(source []byte) ([]byte, error) {
+	if len(source) <= CompressedHeaderBytesLength {
+		return nil, ErrInvalidSourceLength
+	}
+
+	if CompressionCodec(source[0]) != CompressionSnappy {
+		return nil, ErrUnknownCompressionCodec
+	}
+
+	msgType := message.Type(source[1])
+	if !compressible(msgType) {
+		return nil, ErrInvalidCompressedMessage
+	}
+
+	compressed := source[CompressedHeaderBytesLength:]
+	payloadLength, err := snappy.DecodedLen(compressed)
+	if err != nil || payloadLength > MaxCompressedPayloadLength {
+		return nil, ErrInvalidCompressedMessage
+	}
+
+	msg := make([]byte, tlv.HeaderBytesLength+payloadLength)
+	if _, err := snappy.Decode(msg[tlv.HeaderBytesLength:], compressed); err != nil {
+		return nil, ErrInvalidCompressedMessage
+	}
+
+	buf := bytes.NewBuffer(msg[:0])
+	if err := tlv.WriteHeader(buf, msgType, uint16(payloadLength)); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
@@ -0,0 +1,63 @@
+package sting
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/gohornet/hornet/pkg/consts"
+	"github.com/gohornet/hornet/pkg/protocol/message"
+	"github.com/gohornet/hornet/pkg/protocol/tlv"
+)
+
+// FeatureSetHopCount denotes the capability bit for the experimental hop count extension.
+// It is announced alongside the protocol version in the handshake and only used if both peers support it.
+const FeatureSetHopCount = 1 << 3
+
+// FeatureSetHopCountName is the name of the hop count capability.
+const FeatureSetHopCountName = "HopCount"
+
+const (
+	MessageTypeTransactionWithHopCount message.Type = 7
+
+	// The amount of bytes used for the hop count of a relayed transaction.
+	HopCountMsgBytesLength = 1
+)
+
+var (
+	// The transaction gossipping packet with a prepended hop count.
+	// The hop count denotes how many times the transaction was already relayed.
+	TransactionWithHopCountMessageDefinition = &message.Definition{
+		ID:             MessageTypeTransactionWithHopCount,
+		MaxBytesLength: HopCountMsgBytesLength + consts.NonSigTxPartBytesLength + consts.SigDataMaxBytesLength,
+		VariableLength: true,
+	}
+)
+
+// NewTransactionWithHopCountMessage creates a new transaction message with the given hop count.
+func NewTransactionWithHopCountMessage(hopCount byte, txData []byte) ([]byte, error) {
+	msgBytesLength := uint16(HopCountMsgBytesLength + len(txData))
+	buf := bytes.NewBuffer(make([]byte, 0, tlv.HeaderMessageDefinition.MaxBytesLength+msgBytesLength))
+
+	if err := tlv.WriteHeader(buf, MessageTypeTransactionWithHopCount, msgBytesLength); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(buf, binary.BigEndian, hopCount); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(buf, binary.BigEndian, txData); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ExtractHopCount extracts the hop count and the transaction data from the given source.
+func ExtractHopCount(source []byte) (byte, []byte, error) {
+	if len(source) <= HopCountMsgBytesLength {
+		return 0, nil, ErrInvalidSourceLength
+	}
+
+	return source[0], source[HopCountMsgBytesLength:], nil
+}
@@ -0,0 +1,130 @@
+package sting
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/protocol/message"
+	"github.com/gohornet/hornet/pkg/protocol/tlv"
+)
+
+const (
+	// MessageTypeKnownTransactions is only sent to peers which offer the ServiceKnownTransactions
+	// in their capabilities record, since it isn't announced in the handshake.
+	MessageTypeKnownTransactions message.Type = 12
+
+	// The maximum amount of bytes of the bit array of a known transactions filter.
+	MaxKnownTransactionsFilterBytes = 32768
+
+	// The amount of bits per transaction of a known transactions filter, which results in a false positive rate of about 1%.
+	KnownTransactionsFilterBitsPerTransaction = 10
+
+	// The amount of hash functions of a known transactions filter.
+	KnownTransactionsFilterHashFunctions = 7
+
+	// the amount of bytes of a transaction hash which are used to derive the positions within the bit array.
+	knownTransactionsHashBytesLength = 16
+)
+
+var (
+	// ErrTooManyKnownTransactions is returned when a known transactions filter would exceed its maximum size.
+	ErrTooManyKnownTransactions = errors.New("too many known transactions")
+	// ErrInvalidKnownTransactionsFilter is returned when a known transactions filter has no hash functions.
+	ErrInvalidKnownTransactionsFilter = errors.New("invalid known transactions filter")
+
+	// The known transactions packet.
+	// Made up of the amount of hash functions (1 byte) and the bit array of the bloom filter.
+	// An empty bit array denotes that no transactions are known.
+	KnownTransactionsMessageDefinition = &message.Definition{
+		ID:             MessageTypeKnownTransactions,
+		MaxBytesLength: 1 + MaxKnownTransactionsFilterBytes,
+		VariableLength: true,
+	}
+)
+
+// KnownTransactionsFilter is a bloom filter of the transactions a node recently received.
+// It never misses a contained transaction, but might falsely report a transaction as contained.
+type KnownTransactionsFilter struct {
+	hashFunctions byte
+	bits          []byte
+}
+
+// NewKnownTransactionsFilter creates a new empty filter sized for the given amount of transactions.
+func NewKnownTransactionsFilter(transactions int) (*KnownTransactionsFilter, error) {
+	bitsLength := (transactions*KnownTransactionsFilterBitsPerTransaction + 7) / 8
+	if bitsLength > MaxKnownTransactionsFilterBytes {
+		return nil, ErrTooManyKnownTransactions
+	}
+
+	return &KnownTransactionsFilter{
+		hashFunctions: KnownTransactionsFilterHashFunctions,
+		bits:          make([]byte, bitsLength),
+	}, nil
+}
+
+// Add adds the given transaction hash to the filter.
+func (f *KnownTransactionsFilter) Add(hash hornet.Hash) {
+	f.forEachPosition(hash, func(bit uint64) bool {
+		f.bits[bit/8] |= 1 << (bit % 8)
+		return true
+	})
+}
+
+// Contains tells whether the given transaction hash might have been added to the filter.
+func (f *KnownTransactionsFilter) Contains(hash hornet.Hash) bool {
+	contained := len(f.bits) != 0 && len(hash) >= knownTransactionsHashBytesLength
+	f.forEachPosition(hash, func(bit uint64) bool {
+		contained = f.bits[bit/8]&(1<<(bit%8)) != 0
+		return contained
+	})
+	return contained
+}
+
+// calls the given function for the position of every hash function within the bit array until it returns false.
+// the transaction hashes are the result of proof of work, so their first bytes are used as the base hashes.
+func (f *KnownTransactionsFilter) forEachPosition(hash hornet.Hash, consumer func(bit uint64) bool) {
+	if len(f.bits) == 0 || len(hash) < knownTransactionsHashBytesLength {
+		return
+	}
+
+	size := uint64(len(f.bits)) * 8
+	h1 := binary.LittleEndian.Uint64(hash[:8])
+	h2 := binary.LittleEndian.Uint64(hash[8:knownTransactionsHashBytesLength]) | 1
+	for i := uint64(0); i < uint64(f.hashFunctions); i++ {
+		if !consumer((h1 + i*h2) % size) {
+			return
+		}
+	}
+}
+
+// NewKnownTransactionsMessage creates a new known transactions message.
+func NewKnownTransactionsMessage(filter *KnownTransactionsFilter) ([]byte, error) {
+	msgBytesLength := uint16(1 + len(filter.bits))
+
+	buf := bytes.NewBuffer(make([]byte, 0, tlv.HeaderMessageDefinition.MaxBytesLength+msgBytesLength))
+	if err := tlv.WriteHeader(buf, MessageTypeKnownTransactions, msgBytesLength); err != nil {
+		return nil, err
+	}
+	buf.WriteByte(filter.hashFunctions)
+	buf.Write(filter.bits)
+
+	return buf.Bytes(), nil
+}
+
+// ParseKnownTransactions parses the given message into a known transactions filter.
+func ParseKnownTransactions(source []byte) (*KnownTransactionsFilter, error) {
+	if len(source) < 1 || len(source) > int(KnownTransactionsMessageDefinition.MaxBytesLength) {
+		return nil, ErrInvalidSourceLength
+	}
+
+	if source[0] == 0 && len(source) > 1 {
+		return nil, ErrInvalidKnownTransactionsFilter
+	}
+
+	return &KnownTransactionsFilter{
+		hashFunctions: source[0],
+		bits:          append([]byte{}, source[1:]...),
+	}, nil
+}
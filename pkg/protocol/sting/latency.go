@@ -0,0 +1,68 @@
+package sting
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/gohornet/hornet/pkg/protocol/message"
+	"github.com/gohornet/hornet/pkg/protocol/tlv"
+)
+
+const (
+	// MessageTypeLatencyPing and MessageTypeLatencyPong are only sent to peers which offer the ServiceLatencyProbe
+	// in their capabilities record, since they aren't announced in the handshake.
+	MessageTypeLatencyPing message.Type = 14
+	MessageTypeLatencyPong message.Type = 15
+
+	// The amount of bytes used for the timestamp of a latency ping or pong.
+	LatencyTimestampBytesLength = 8
+)
+
+var (
+	// The latency ping packet.
+	// Made up of the unix timestamp in nanoseconds at which the ping was sent (8 bytes).
+	LatencyPingMessageDefinition = &message.Definition{
+		ID:             MessageTypeLatencyPing,
+		MaxBytesLength: LatencyTimestampBytesLength,
+		VariableLength: false,
+	}
+
+	// The latency pong packet.
+	// Made up of the timestamp of the answered ping (8 bytes).
+	LatencyPongMessageDefinition = &message.Definition{
+		ID:             MessageTypeLatencyPong,
+		MaxBytesLength: LatencyTimestampBytesLength,
+		VariableLength: false,
+	}
+)
+
+// NewLatencyPingMessage creates a new latency ping message with the given timestamp.
+func NewLatencyPingMessage(timestamp int64) ([]byte, error) {
+	return newLatencyMessage(MessageTypeLatencyPing, timestamp)
+}
+
+// NewLatencyPongMessage creates a new latency pong message which answers the ping with the given timestamp.
+func NewLatencyPongMessage(timestamp int64) ([]byte, error) {
+	return newLatencyMessage(MessageTypeLatencyPong, timestamp)
+}
+
+func newLatencyMessage(msgType message.Type, timestamp int64) ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, tlv.HeaderMessageDefinition.MaxBytesLength+LatencyTimestampBytesLength))
+	if err := tlv.WriteHeader(buf, msgType, LatencyTimestampBytesLength); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(buf, binary.BigEndian, timestamp); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ParseLatencyTimestamp parses the timestamp of the given latency ping or pong message.
+func ParseLatencyTimestamp(source []byte) (int64, error) {
+	if len(source) != LatencyTimestampBytesLength {
+		return 0, ErrInvalidSourceLength
+	}
+	return int64(binary.BigEndian.Uint64(source)), nil
+}
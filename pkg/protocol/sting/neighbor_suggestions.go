@@ -0,0 +1,113 @@
+package sting
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	"github.com/gohornet/hornet/pkg/protocol/message"
+	"github.com/gohornet/hornet/pkg/protocol/tlv"
+)
+
+// FeatureSetNeighborSuggestions denotes the capability bit for the neighbor suggestion extension.
+// It is announced alongside the protocol version in the handshake and only used if both peers support it.
+const FeatureSetNeighborSuggestions = 1 << 4
+
+// FeatureSetNeighborSuggestionsName is the name of the neighbor suggestions capability.
+const FeatureSetNeighborSuggestionsName = "NeighborSuggestions"
+
+const (
+	MessageTypeNeighborSuggestions message.Type = 8
+
+	// The maximum amount of suggested neighbors within a neighbor suggestions message.
+	MaxNeighborSuggestions = 8
+
+	// The maximum length of a single suggested neighbor address.
+	MaxNeighborSuggestionAddressLength = 255
+)
+
+var (
+	// ErrInvalidNeighborSuggestion is returned when a suggested neighbor address is empty or too long.
+	ErrInvalidNeighborSuggestion = errors.New("invalid neighbor suggestion")
+
+	// The neighbor suggestions packet.
+	// Made up of the amount of suggestions (1 byte), followed by each
+	// suggested neighbor address ("host:port") prefixed with its length (1 byte).
+	NeighborSuggestionsMessageDefinition = &message.Definition{
+		ID:             MessageTypeNeighborSuggestions,
+		MaxBytesLength: 1 + MaxNeighborSuggestions*(1+MaxNeighborSuggestionAddressLength),
+		VariableLength: true,
+	}
+)
+
+// NewNeighborSuggestionsMessage creates a new neighbor suggestions message.
+// Only the first MaxNeighborSuggestions addresses are included.
+func NewNeighborSuggestionsMessage(addresses []string) ([]byte, error) {
+	if len(addresses) > MaxNeighborSuggestions {
+		addresses = addresses[:MaxNeighborSuggestions]
+	}
+
+	msgBytesLength := uint16(1)
+	for _, addr := range addresses {
+		if len(addr) == 0 || len(addr) > MaxNeighborSuggestionAddressLength {
+			return nil, ErrInvalidNeighborSuggestion
+		}
+		msgBytesLength += uint16(1 + len(addr))
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, tlv.HeaderMessageDefinition.MaxBytesLength+msgBytesLength))
+	if err := tlv.WriteHeader(buf, MessageTypeNeighborSuggestions, msgBytesLength); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(buf, binary.BigEndian, byte(len(addresses))); err != nil {
+		return nil, err
+	}
+
+	for _, addr := range addresses {
+		if err := binary.Write(buf, binary.BigEndian, byte(len(addr))); err != nil {
+			return nil, err
+		}
+		if _, err := buf.WriteString(addr); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ParseNeighborSuggestions parses the given message into a list of suggested neighbor addresses.
+func ParseNeighborSuggestions(source []byte) ([]string, error) {
+	if len(source) < 1 {
+		return nil, ErrInvalidSourceLength
+	}
+
+	count := int(source[0])
+	if count > MaxNeighborSuggestions {
+		return nil, ErrInvalidNeighborSuggestion
+	}
+
+	addresses := make([]string, 0, count)
+	offset := 1
+	for i := 0; i < count; i++ {
+		if offset >= len(source) {
+			return nil, ErrInvalidSourceLength
+		}
+
+		addrLength := int(source[offset])
+		offset++
+
+		if addrLength == 0 || offset+addrLength > len(source) {
+			return nil, ErrInvalidSourceLength
+		}
+
+		addresses = append(addresses, string(source[offset:offset+addrLength]))
+		offset += addrLength
+	}
+
+	if offset != len(source) {
+		return nil, ErrInvalidSourceLength
+	}
+
+	return addresses, nil
+}
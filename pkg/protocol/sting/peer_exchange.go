@@ -0,0 +1,130 @@
+package sting
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/binary"
+	"errors"
+
+	"github.com/gohornet/hornet/pkg/protocol/message"
+	"github.com/gohornet/hornet/pkg/protocol/tlv"
+)
+
+const (
+	// MessageTypePeerExchange is only sent to peers which offer the ServicePeerExchange
+	// in their capabilities record, since it isn't announced in the handshake.
+	MessageTypePeerExchange message.Type = 13
+
+	// The maximum amount of peers within a peer exchange message.
+	MaxPeerExchangePeers = 16
+
+	// The maximum length of a single serialized peer.
+	MaxPeerExchangePeerLength = 255
+)
+
+var (
+	// ErrInvalidPeerExchangePeer is returned when a serialized peer is empty or too long.
+	ErrInvalidPeerExchangePeer = errors.New("invalid peer exchange peer")
+	// ErrInvalidPeerExchangeSignature is returned when the signature of a peer exchange message is invalid.
+	ErrInvalidPeerExchangeSignature = errors.New("invalid peer exchange signature")
+
+	// The peer exchange packet.
+	// Made up of the public key of the node (32 bytes), the timestamp (8 bytes), the amount of peers (1 byte),
+	// each serialized autopeering peer prefixed with its length (1 byte) and the signature over all previous bytes (64 bytes).
+	PeerExchangeMessageDefinition = &message.Definition{
+		ID:             MessageTypePeerExchange,
+		MaxBytesLength: ed25519.PublicKeySize + 8 + 1 + MaxPeerExchangePeers*(1+MaxPeerExchangePeerLength) + ed25519.SignatureSize,
+		VariableLength: true,
+	}
+)
+
+// PeerExchange is the signed list of reachable peers a node shares with its trusted peers.
+type PeerExchange struct {
+	// The public key the list was signed with.
+	PublicKey ed25519.PublicKey
+	// The unix timestamp at which the list was created.
+	Timestamp int64
+	// The serialized autopeering peers.
+	Peers [][]byte
+}
+
+// NewPeerExchangeMessage creates a new peer exchange message which is signed with the given key.
+// Only the first MaxPeerExchangePeers peers are included.
+func NewPeerExchangeMessage(pex *PeerExchange, key ed25519.PrivateKey) ([]byte, error) {
+	peers := pex.Peers
+	if len(peers) > MaxPeerExchangePeers {
+		peers = peers[:MaxPeerExchangePeers]
+	}
+
+	record := bytes.NewBuffer(make([]byte, 0, PeerExchangeMessageDefinition.MaxBytesLength))
+	record.Write(key.Public().(ed25519.PublicKey))
+	if err := binary.Write(record, binary.BigEndian, pex.Timestamp); err != nil {
+		return nil, err
+	}
+	record.WriteByte(byte(len(peers)))
+	for _, p := range peers {
+		if len(p) == 0 || len(p) > MaxPeerExchangePeerLength {
+			return nil, ErrInvalidPeerExchangePeer
+		}
+		record.WriteByte(byte(len(p)))
+		record.Write(p)
+	}
+	record.Write(ed25519.Sign(key, record.Bytes()))
+
+	msgBytesLength := uint16(record.Len())
+	buf := bytes.NewBuffer(make([]byte, 0, tlv.HeaderMessageDefinition.MaxBytesLength+msgBytesLength))
+	if err := tlv.WriteHeader(buf, MessageTypePeerExchange, msgBytesLength); err != nil {
+		return nil, err
+	}
+	buf.Write(record.Bytes())
+
+	return buf.Bytes(), nil
+}
+
+// ParsePeerExchange parses the given message into a peer exchange list and verifies its signature.
+func ParsePeerExchange(source []byte) (*PeerExchange, error) {
+	const fixedLength = ed25519.PublicKeySize + 8 + 1
+
+	if len(source) < fixedLength+ed25519.SignatureSize {
+		return nil, ErrInvalidSourceLength
+	}
+
+	signedLength := len(source) - ed25519.SignatureSize
+	publicKey := ed25519.PublicKey(append([]byte{}, source[:ed25519.PublicKeySize]...))
+	if !ed25519.Verify(publicKey, source[:signedLength], source[signedLength:]) {
+		return nil, ErrInvalidPeerExchangeSignature
+	}
+
+	count := int(source[fixedLength-1])
+	if count > MaxPeerExchangePeers {
+		return nil, ErrInvalidPeerExchangePeer
+	}
+
+	peers := make([][]byte, 0, count)
+	offset := fixedLength
+	for i := 0; i < count; i++ {
+		if offset >= signedLength {
+			return nil, ErrInvalidSourceLength
+		}
+
+		peerLength := int(source[offset])
+		offset++
+
+		if peerLength == 0 || offset+peerLength > signedLength {
+			return nil, ErrInvalidSourceLength
+		}
+
+		peers = append(peers, append([]byte{}, source[offset:offset+peerLength]...))
+		offset += peerLength
+	}
+
+	if offset != signedLength {
+		return nil, ErrInvalidSourceLength
+	}
+
+	return &PeerExchange{
+		PublicKey: publicKey,
+		Timestamp: int64(binary.BigEndian.Uint64(source[ed25519.PublicKeySize : ed25519.PublicKeySize+8])),
+		Peers:     peers,
+	}, nil
+}
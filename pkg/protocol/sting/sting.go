@@ -20,6 +20,9 @@ var (
 // FeatureSet denotes the version bit for Chrysalis-Pt1 support.
 const FeatureSet = 1 << 2
 
+// ProtocolVersion is the protocol version denoted by the FeatureSet bit.
+const ProtocolVersion = 3
+
 // FeatureSetName is the name of the feature set.
 const FeatureSetName = "Chrysalis-Pt1"
 
@@ -36,6 +39,33 @@ func init() {
 	if err := message.RegisterType(MessageTypeHeartbeat, HeartbeatMessageDefinition); err != nil {
 		panic(err)
 	}
+	if err := message.RegisterType(MessageTypeTransactionWithHopCount, TransactionWithHopCountMessageDefinition); err != nil {
+		panic(err)
+	}
+	if err := message.RegisterType(MessageTypeNeighborSuggestions, NeighborSuggestionsMessageDefinition); err != nil {
+		panic(err)
+	}
+	if err := message.RegisterType(MessageTypeCapabilities, CapabilitiesMessageDefinition); err != nil {
+		panic(err)
+	}
+	if err := message.RegisterType(MessageTypeChunk, ChunkMessageDefinition); err != nil {
+		panic(err)
+	}
+	if err := message.RegisterType(MessageTypeCompressed, CompressedMessageDefinition); err != nil {
+		panic(err)
+	}
+	if err := message.RegisterType(MessageTypeKnownTransactions, KnownTransactionsMessageDefinition); err != nil {
+		panic(err)
+	}
+	if err := message.RegisterType(MessageTypePeerExchange, PeerExchangeMessageDefinition); err != nil {
+		panic(err)
+	}
+	if err := message.RegisterType(MessageTypeLatencyPing, LatencyPingMessageDefinition); err != nil {
+		panic(err)
+	}
+	if err := message.RegisterType(MessageTypeLatencyPong, LatencyPongMessageDefinition); err != nil {
+		panic(err)
+	}
 }
 
 const (
@@ -0,0 +1,218 @@
+package replica
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/gohornet/hornet/pkg/model/milestone"
+)
+
+const (
+	// NoticeTypeMilestone is sent when the primary received a new latest milestone.
+	NoticeTypeMilestone = "milestone"
+	// NoticeTypeConfirmed is sent when the primary confirmed a range of milestones.
+	NoticeTypeConfirmed = "confirmed"
+	// NoticeTypeStatus is sent periodically and when a replica subscribes, to announce the state of the primary.
+	NoticeTypeStatus = "status"
+
+	// SubscriberBufferSize is the amount of notices which are buffered for a subscriber.
+	// Subscribers which fall further behind are dropped and have to subscribe again.
+	SubscriberBufferSize = 100
+	// IsSyncedThreshold is the amount of milestones the solid milestone index of a replica
+	// may lag behind the solid milestone index of the primary to still be considered synced.
+	IsSyncedThreshold = 2
+)
+
+var (
+	// ErrInvalidNotice is returned when a received notice can't be parsed or has an unknown type.
+	ErrInvalidNotice = errors.New("invalid notice")
+)
+
+// Notice is an invalidation notice which is streamed from the primary to its read-only replicas.
+type Notice struct {
+	// The type of the notice.
+	Type string `json:"type"`
+	// The latest milestone index of the primary.
+	LatestMilestoneIndex milestone.Index `json:"latestMilestoneIndex"`
+	// The solid milestone index of the primary.
+	SolidMilestoneIndex milestone.Index `json:"solidMilestoneIndex"`
+	// The first milestone index of the confirmed range (NoticeTypeConfirmed only).
+	ConfirmedFrom milestone.Index `json:"confirmedFrom,omitempty"`
+	// The last milestone index of the confirmed range (NoticeTypeConfirmed only).
+	ConfirmedTo milestone.Index `json:"confirmedTo,omitempty"`
+}
+
+// WriteNotice writes the given notice as a single JSON line to the given writer.
+func WriteNotice(w io.Writer, notice *Notice) error {
+	return json.NewEncoder(w).Encode(notice)
+}
+
+// ReadNotices reads JSON line encoded notices from the given reader and passes them to the handler
+// until the reader is exhausted or an error occurs.
+func ReadNotices(r io.Reader, handler func(notice *Notice)) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		notice := &Notice{}
+		if err := json.Unmarshal(scanner.Bytes(), notice); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidNotice, err)
+		}
+
+		switch notice.Type {
+		case NoticeTypeMilestone, NoticeTypeConfirmed, NoticeTypeStatus:
+		default:
+			return fmt.Errorf("%w: unknown type '%s'", ErrInvalidNotice, notice.Type)
+		}
+
+		handler(notice)
+	}
+	return scanner.Err()
+}
+
+// Feed distributes the notices of the primary to all subscribed replicas.
+type Feed struct {
+	subscribers     map[chan *Notice]struct{}
+	subscribersLock sync.Mutex
+}
+
+// NewFeed creates a new feed without subscribers.
+func NewFeed() *Feed {
+	return &Feed{subscribers: make(map[chan *Notice]struct{})}
+}
+
+// Subscribe returns a channel which receives all published notices.
+// The channel is closed if the subscriber falls behind or unsubscribes.
+func (f *Feed) Subscribe() chan *Notice {
+	f.subscribersLock.Lock()
+	defer f.subscribersLock.Unlock()
+
+	ch := make(chan *Notice, SubscriberBufferSize)
+	f.subscribers[ch] = struct{}{}
+	return ch
+}
+
+// Unsubscribe removes the given subscriber from the feed and closes its channel.
+func (f *Feed) Unsubscribe(ch chan *Notice) {
+	f.subscribersLock.Lock()
+	defer f.subscribersLock.Unlock()
+
+	if _, exists := f.subscribers[ch]; !exists {
+		return
+	}
+	delete(f.subscribers, ch)
+	close(ch)
+}
+
+// Publish sends the given notice to all subscribers.
+// Subscribers with a full buffer are dropped, since a replica which missed a notice can't rely on its caches anymore.
+func (f *Feed) Publish(notice *Notice) {
+	f.subscribersLock.Lock()
+	defer f.subscribersLock.Unlock()
+
+	for ch := range f.subscribers {
+		select {
+		case ch <- notice:
+		default:
+			delete(f.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Close removes all subscribers from the feed and closes their channels.
+func (f *Feed) Close() {
+	f.subscribersLock.Lock()
+	defer f.subscribersLock.Unlock()
+
+	for ch := range f.subscribers {
+		delete(f.subscribers, ch)
+		close(ch)
+	}
+}
+
+// SubscriberCount returns the amount of subscribers of the feed.
+func (f *Feed) SubscriberCount() int {
+	f.subscribersLock.Lock()
+	defer f.subscribersLock.Unlock()
+
+	return len(f.subscribers)
+}
+
+// Status is the sync status of a replica compared to its primary.
+type Status struct {
+	// Whether the replica is connected to the feed of the primary.
+	Connected bool `json:"connected"`
+	// The latest milestone index of the primary.
+	PrimaryLatestMilestoneIndex milestone.Index `json:"primaryLatestMilestoneIndex"`
+	// The solid milestone index of the primary.
+	PrimarySolidMilestoneIndex milestone.Index `json:"primarySolidMilestoneIndex"`
+	// The solid milestone index of the store the replica serves its reads from.
+	SolidMilestoneIndex milestone.Index `json:"solidMilestoneIndex"`
+	// The time the last notice was received from the primary.
+	LastNotice time.Time `json:"lastNotice"`
+	// Whether the replica serves up to date data.
+	IsSynced bool `json:"isSynced"`
+}
+
+// Tracker keeps track of the state of the primary as announced by its notices.
+type Tracker struct {
+	connected            bool
+	latestMilestoneIndex milestone.Index
+	solidMilestoneIndex  milestone.Index
+	lastNotice           time.Time
+	lock                 sync.RWMutex
+}
+
+// NewTracker creates a new tracker which is not connected to the primary.
+func NewTracker() *Tracker {
+	return &Tracker{}
+}
+
+// SetConnected sets whether the replica is connected to the feed of the primary.
+func (t *Tracker) SetConnected(connected bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.connected = connected
+}
+
+// Apply updates the state of the primary with the given notice.
+func (t *Tracker) Apply(notice *Notice) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.latestMilestoneIndex = notice.LatestMilestoneIndex
+	t.solidMilestoneIndex = notice.SolidMilestoneIndex
+	t.lastNotice = time.Now()
+}
+
+// Status returns the sync status of the replica given the solid milestone index of its store.
+// The replica is only considered synced if it is connected to the primary, received a notice within staleAfter
+// and its solid milestone index doesn't lag more than IsSyncedThreshold milestones behind the primary.
+func (t *Tracker) Status(solidMilestoneIndex milestone.Index, staleAfter time.Duration) *Status {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	status := &Status{
+		Connected:                   t.connected,
+		PrimaryLatestMilestoneIndex: t.latestMilestoneIndex,
+		PrimarySolidMilestoneIndex:  t.solidMilestoneIndex,
+		SolidMilestoneIndex:         solidMilestoneIndex,
+		LastNotice:                  t.lastNotice,
+	}
+
+	if !t.connected || t.lastNotice.IsZero() || time.Since(t.lastNotice) > staleAfter {
+		return status
+	}
+
+	status.IsSynced = solidMilestoneIndex+IsSyncedThreshold >= t.solidMilestoneIndex
+	return status
+}
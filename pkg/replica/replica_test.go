@@ -0,0 +1,70 @@
+package replica_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/replica"
+)
+
+func TestNoticeEncoding(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, replica.WriteNotice(buf, &replica.Notice{Type: replica.NoticeTypeMilestone, LatestMilestoneIndex: 11, SolidMilestoneIndex: 10}))
+	require.NoError(t, replica.WriteNotice(buf, &replica.Notice{Type: replica.NoticeTypeConfirmed, LatestMilestoneIndex: 11, SolidMilestoneIndex: 11, ConfirmedFrom: 11, ConfirmedTo: 11}))
+
+	var notices []*replica.Notice
+	require.NoError(t, replica.ReadNotices(buf, func(notice *replica.Notice) {
+		notices = append(notices, notice)
+	}))
+	require.Len(t, notices, 2)
+	require.Equal(t, replica.NoticeTypeConfirmed, notices[1].Type)
+	require.EqualValues(t, 11, notices[1].ConfirmedTo)
+
+	err := replica.ReadNotices(strings.NewReader(`{"type":"unknown"}`+"\n"), func(_ *replica.Notice) {})
+	require.True(t, errors.Is(err, replica.ErrInvalidNotice))
+}
+
+func TestFeed(t *testing.T) {
+	feed := replica.NewFeed()
+
+	fast := feed.Subscribe()
+	slow := feed.Subscribe()
+	require.Equal(t, 2, feed.SubscriberCount())
+
+	for i := 0; i < replica.SubscriberBufferSize; i++ {
+		feed.Publish(&replica.Notice{Type: replica.NoticeTypeStatus})
+		<-fast
+	}
+
+	// the slow subscriber missed a notice and gets dropped
+	feed.Publish(&replica.Notice{Type: replica.NoticeTypeStatus})
+	require.Equal(t, 1, feed.SubscriberCount())
+	for range slow {
+	}
+
+	feed.Unsubscribe(fast)
+	require.Equal(t, 0, feed.SubscriberCount())
+}
+
+func TestTrackerStatus(t *testing.T) {
+	tracker := replica.NewTracker()
+	require.False(t, tracker.Status(10, time.Minute).IsSynced)
+
+	tracker.SetConnected(true)
+	tracker.Apply(&replica.Notice{Type: replica.NoticeTypeStatus, LatestMilestoneIndex: 20, SolidMilestoneIndex: 20})
+
+	require.True(t, tracker.Status(20, time.Minute).IsSynced)
+	require.True(t, tracker.Status(20-replica.IsSyncedThreshold, time.Minute).IsSynced)
+	require.False(t, tracker.Status(10, time.Minute).IsSynced)
+
+	// no notice received within the stale timeout
+	require.False(t, tracker.Status(20, 0).IsSynced)
+
+	tracker.SetConnected(false)
+	require.False(t, tracker.Status(20, time.Minute).IsSynced)
+}
@@ -0,0 +1,195 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidCronSpec is returned when a cron spec can't be parsed.
+	ErrInvalidCronSpec = errors.New("invalid cron spec")
+
+	// the shortcuts which can be used instead of the five fields of a cron spec.
+	cronShortcuts = map[string]string{
+		"@hourly":   "0 * * * *",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@weekly":   "0 0 * * 0",
+		"@monthly":  "0 0 1 * *",
+		"@yearly":   "0 0 1 1 *",
+	}
+)
+
+// the allowed range of a field of a cron spec.
+type cronField struct {
+	name string
+	min  int
+	max  int
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7},
+}
+
+// CronSchedule is a parsed cron spec with the fields minute, hour, day of month, month and day of week,
+// evaluated in the local time zone. Each field is a bitmask of the allowed values.
+type CronSchedule struct {
+	spec    string
+	minutes uint64
+	hours   uint64
+	days    uint64
+	months  uint64
+	weekday uint64
+	// whether the day of month respectively the day of week is restricted.
+	// if both are restricted, a day matches if either of them matches (like cron).
+	daysRestricted    bool
+	weekdayRestricted bool
+}
+
+// ParseCron parses a cron spec made up of five fields (minute, hour, day of month, month, day of week),
+// e.g. "0 3 * * *" for daily at 03:00. Each field supports '*', values, ranges ('1-5'), lists ('1,15') and steps ('*/15').
+// The shortcuts @hourly, @daily, @midnight, @weekly, @monthly and @yearly are supported as well.
+func ParseCron(spec string) (*CronSchedule, error) {
+	expanded := strings.TrimSpace(spec)
+	if shortcut, exists := cronShortcuts[strings.ToLower(expanded)]; exists {
+		expanded = shortcut
+	}
+
+	fields := strings.Fields(expanded)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("%w: '%s' must have %d fields", ErrInvalidCronSpec, spec, len(cronFields))
+	}
+
+	masks := make([]uint64, len(fields))
+	for i, field := range fields {
+		mask, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("%w: '%s': %s", ErrInvalidCronSpec, spec, err)
+		}
+		masks[i] = mask
+	}
+
+	// sunday is 0 and 7
+	weekday := masks[4]
+	if weekday&(1<<7) != 0 {
+		weekday |= 1
+	}
+
+	return &CronSchedule{
+		spec:              spec,
+		minutes:           masks[0],
+		hours:             masks[1],
+		days:              masks[2],
+		months:            masks[3],
+		weekday:           weekday,
+		daysRestricted:    fields[2] != "*",
+		weekdayRestricted: fields[4] != "*",
+	}, nil
+}
+
+// parses a single field of a cron spec into a bitmask of the allowed values.
+func parseCronField(field string, bounds cronField) (uint64, error) {
+	var mask uint64
+
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i != -1 {
+			var err error
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step in %s '%s'", bounds.name, part)
+			}
+			part = part[:i]
+		}
+
+		from, to := bounds.min, bounds.max
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			rangeParts := strings.SplitN(part, "-", 2)
+			var err1, err2 error
+			from, err1 = strconv.Atoi(rangeParts[0])
+			to, err2 = strconv.Atoi(rangeParts[1])
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("invalid range in %s '%s'", bounds.name, part)
+			}
+		default:
+			value, err := strconv.Atoi(part)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value in %s '%s'", bounds.name, part)
+			}
+			from, to = value, value
+			if step > 1 {
+				// '5/15' means every 15 starting at 5
+				to = bounds.max
+			}
+		}
+
+		if from < bounds.min || to > bounds.max || from > to {
+			return 0, fmt.Errorf("%s '%s' out of range %d-%d", bounds.name, part, bounds.min, bounds.max)
+		}
+
+		for value := from; value <= to; value += step {
+			mask |= 1 << uint(value)
+		}
+	}
+
+	return mask, nil
+}
+
+// String returns the cron spec of the schedule.
+func (c *CronSchedule) String() string {
+	return c.spec
+}
+
+// Next returns the first time after the given time which matches the schedule.
+// The zero time is returned if the schedule never matches (e.g. '0 0 30 2 *').
+func (c *CronSchedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+
+	// a matching time is found within five years, if it exists at all (leap days)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if c.months&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if !c.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if c.hours&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if c.minutes&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+// checks whether the day of the given time matches the schedule.
+func (c *CronSchedule) matchesDay(t time.Time) bool {
+	dayMatches := c.days&(1<<uint(t.Day())) != 0
+	weekdayMatches := c.weekday&(1<<uint(t.Weekday())) != 0
+
+	if c.daysRestricted && c.weekdayRestricted {
+		return dayMatches || weekdayMatches
+	}
+	return dayMatches && weekdayMatches
+}
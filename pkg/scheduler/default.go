@@ -0,0 +1,45 @@
+package scheduler
+
+import (
+	"time"
+)
+
+var (
+	// the scheduler which runs the background jobs of the plugins.
+	defaultScheduler = New()
+)
+
+// Schedule adds a recurring job to the scheduler of the node.
+func Schedule(name string, interval time.Duration, fn JobFunc) error {
+	return defaultScheduler.Schedule(name, interval, fn)
+}
+
+// ScheduleCron adds a recurring job to the scheduler of the node, which is run at the times of the given cron spec.
+func ScheduleCron(name string, spec string, fn JobFunc) error {
+	return defaultScheduler.ScheduleCron(name, spec, fn)
+}
+
+// Run runs the given job in the calling goroutine and tracks it in the scheduler of the node.
+func Run(name string, abortSignal <-chan struct{}, fn JobFunc) error {
+	return defaultScheduler.Run(name, abortSignal, fn)
+}
+
+// Submit runs the given one-off job in the background and tracks it in the scheduler of the node.
+func Submit(name string, fn JobFunc) uint64 {
+	return defaultScheduler.Submit(name, fn)
+}
+
+// Cancel aborts the running job with the given ID.
+func Cancel(id uint64) error {
+	return defaultScheduler.Cancel(id)
+}
+
+// Jobs returns the scheduled, running and finished jobs of the scheduler of the node.
+func Jobs() (scheduled []*JobInfo, running []*JobInfo, finished []*JobInfo) {
+	return defaultScheduler.Jobs()
+}
+
+// Start runs the recurring jobs of the scheduler of the node until the shutdown signal is closed.
+func Start(shutdownSignal <-chan struct{}) {
+	defaultScheduler.Start(shutdownSignal)
+}
@@ -0,0 +1,386 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/hive.go/syncutils"
+)
+
+const (
+	// StateScheduled is the state of a recurring job which waits for its next run.
+	StateScheduled = "scheduled"
+	// StateRunning is the state of a job which is currently running.
+	StateRunning = "running"
+	// StateCompleted is the state of a job which finished successfully.
+	StateCompleted = "completed"
+	// StateFailed is the state of a job which finished with an error.
+	StateFailed = "failed"
+	// StateCanceled is the state of a job which was canceled while it was running.
+	StateCanceled = "canceled"
+
+	// CompletedJobsHistorySize is the amount of finished jobs which are kept for inspection.
+	CompletedJobsHistorySize = 100
+)
+
+var (
+	// ErrJobNotFound is returned when a job with the given ID is not running.
+	ErrJobNotFound = errors.New("job not found or not running")
+	// ErrJobAlreadyScheduled is returned when a recurring job with the same name was already scheduled.
+	ErrJobAlreadyScheduled = errors.New("job already scheduled")
+)
+
+// JobFunc is the function of a job. It must return as soon as possible once the abort signal is closed.
+type JobFunc func(abortSignal <-chan struct{}) error
+
+// JobInfo describes a scheduled, running or finished job.
+type JobInfo struct {
+	// The ID of the run of the job, 0 for scheduled jobs.
+	ID uint64 `json:"id"`
+	// The name of the job.
+	Name string `json:"name"`
+	// The state of the job.
+	State string `json:"state"`
+	// The interval of a recurring job in seconds, 0 for one-off jobs.
+	IntervalSeconds int64 `json:"intervalSeconds"`
+	// The cron spec of a recurring job which is scheduled by a cron spec.
+	Cron string `json:"cron,omitempty"`
+	// The time the job is scheduled to run next (scheduled jobs only).
+	NextRun int64 `json:"nextRun,omitempty"`
+	// The time the job was started.
+	Started int64 `json:"started,omitempty"`
+	// The time the job finished.
+	Finished int64 `json:"finished,omitempty"`
+	// The duration of the job in milliseconds, up to now for running jobs.
+	DurationMs int64 `json:"durationMs"`
+	// The error of a failed job.
+	Error string `json:"error,omitempty"`
+}
+
+// a recurring job which is run by the scheduler at the given interval, or at the times of the given cron schedule.
+type recurringJob struct {
+	name     string
+	interval time.Duration
+	cron     *CronSchedule
+	fn       JobFunc
+	nextRun  time.Time
+	// the run of the job, nil if it is not running.
+	running *run
+}
+
+// returns the time the job is due next after the given time.
+func (j *recurringJob) next(now time.Time) time.Time {
+	if j.cron != nil {
+		return j.cron.Next(now)
+	}
+	return now.Add(j.interval)
+}
+
+// a single run of a job.
+type run struct {
+	info      *JobInfo
+	started   time.Time
+	abort     chan struct{}
+	abortOnce sync.Once
+	canceled  bool
+}
+
+func (r *run) cancel() {
+	r.abortOnce.Do(func() { close(r.abort) })
+}
+
+// Scheduler runs the background jobs of the node and keeps track of the scheduled, running and finished jobs.
+type Scheduler struct {
+	lock      syncutils.Mutex
+	lastID    uint64
+	recurring map[string]*recurringJob
+	running   map[uint64]*run
+	finished  []*JobInfo
+	wakeup    chan struct{}
+	stopped   bool
+}
+
+// New creates a new scheduler.
+func New() *Scheduler {
+	return &Scheduler{
+		recurring: make(map[string]*recurringJob),
+		running:   make(map[uint64]*run),
+		wakeup:    make(chan struct{}, 1),
+	}
+}
+
+// Schedule adds a recurring job which is run at the given interval once the scheduler is started.
+// A run is skipped if the previous run of the job is still running.
+func (s *Scheduler) Schedule(name string, interval time.Duration, fn JobFunc) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.addRecurringJob(&recurringJob{
+		name:     name,
+		interval: interval,
+		fn:       fn,
+	})
+}
+
+// ScheduleCron adds a recurring job which is run at the times of the given cron spec (see ParseCron) once the scheduler is started.
+// A run is skipped if the previous run of the job is still running.
+func (s *Scheduler) ScheduleCron(name string, spec string, fn JobFunc) error {
+	cron, err := ParseCron(spec)
+	if err != nil {
+		return err
+	}
+
+	if cron.Next(time.Now()).IsZero() {
+		return fmt.Errorf("%w: '%s' never matches", ErrInvalidCronSpec, spec)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.addRecurringJob(&recurringJob{
+		name: name,
+		cron: cron,
+		fn:   fn,
+	})
+}
+
+// adds the given recurring job and wakes up the scheduler to take its next run into account.
+// the lock must be held while calling this function.
+func (s *Scheduler) addRecurringJob(job *recurringJob) error {
+	if _, exists := s.recurring[job.name]; exists {
+		return ErrJobAlreadyScheduled
+	}
+
+	job.nextRun = job.next(time.Now())
+	s.recurring[job.name] = job
+
+	select {
+	case s.wakeup <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Run runs the given job in the calling goroutine and returns its error.
+// The job is aborted if the given abort signal is closed, the job gets canceled or the scheduler stops.
+func (s *Scheduler) Run(name string, abortSignal <-chan struct{}, fn JobFunc) error {
+	s.lock.Lock()
+	r := s.startRun(name, 0)
+	s.lock.Unlock()
+
+	if abortSignal != nil {
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			select {
+			case <-abortSignal:
+				s.lock.Lock()
+				r.canceled = true
+				r.cancel()
+				s.lock.Unlock()
+			case <-done:
+			}
+		}()
+	}
+
+	err := fn(r.abort)
+
+	s.lock.Lock()
+	s.finishRun(r, err)
+	s.lock.Unlock()
+
+	return err
+}
+
+// Submit runs the given one-off job in the background and returns its ID.
+func (s *Scheduler) Submit(name string, fn JobFunc) uint64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	r := s.startRun(name, 0)
+	go s.execute(r, fn, nil)
+
+	return r.info.ID
+}
+
+// Cancel aborts the running job with the given ID.
+func (s *Scheduler) Cancel(id uint64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	r, exists := s.running[id]
+	if !exists {
+		return ErrJobNotFound
+	}
+
+	r.canceled = true
+	r.cancel()
+	return nil
+}
+
+// Jobs returns the scheduled, running and finished jobs.
+// The scheduled jobs are ordered by their next run, the others by their start, most recent first.
+func (s *Scheduler) Jobs() (scheduled []*JobInfo, running []*JobInfo, finished []*JobInfo) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+
+	scheduled = make([]*JobInfo, 0, len(s.recurring))
+	for _, job := range s.recurring {
+		info := &JobInfo{
+			Name:            job.name,
+			State:           StateScheduled,
+			IntervalSeconds: int64(job.interval.Seconds()),
+			NextRun:         job.nextRun.Unix(),
+		}
+		if job.cron != nil {
+			info.Cron = job.cron.String()
+		}
+		scheduled = append(scheduled, info)
+	}
+	sort.Slice(scheduled, func(i, j int) bool { return scheduled[i].NextRun < scheduled[j].NextRun })
+
+	running = make([]*JobInfo, 0, len(s.running))
+	for _, r := range s.running {
+		info := *r.info
+		info.DurationMs = now.Sub(r.started).Milliseconds()
+		running = append(running, &info)
+	}
+	sort.Slice(running, func(i, j int) bool { return running[i].ID > running[j].ID })
+
+	finished = make([]*JobInfo, 0, len(s.finished))
+	for i := len(s.finished) - 1; i >= 0; i-- {
+		info := *s.finished[i]
+		finished = append(finished, &info)
+	}
+
+	return scheduled, running, finished
+}
+
+// Start runs the recurring jobs when they are due until the shutdown signal is closed.
+// All running jobs are aborted once the shutdown signal is closed.
+func (s *Scheduler) Start(shutdownSignal <-chan struct{}) {
+	for {
+		s.lock.Lock()
+		nextRun := s.runDueJobs(time.Now())
+		s.lock.Unlock()
+
+		timer := time.NewTimer(time.Until(nextRun))
+		select {
+		case <-shutdownSignal:
+			timer.Stop()
+			s.stop()
+			return
+		case <-s.wakeup:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// aborts all running jobs and prevents the recurring jobs from running again.
+func (s *Scheduler) stop() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.stopped = true
+	for _, r := range s.running {
+		r.canceled = true
+		r.cancel()
+	}
+}
+
+// starts the recurring jobs which are due and returns the time the next job is due.
+// the lock must be held while calling this function.
+func (s *Scheduler) runDueJobs(now time.Time) time.Time {
+	// wake up at least once per hour if no job is scheduled
+	nextRun := now.Add(time.Hour)
+
+	for _, job := range s.recurring {
+		if !job.nextRun.After(now) {
+			job.nextRun = job.next(now)
+
+			if job.running == nil {
+				r := s.startRun(job.name, job.interval)
+				job.running = r
+				go s.execute(r, job.fn, job)
+			}
+		}
+
+		if job.nextRun.Before(nextRun) {
+			nextRun = job.nextRun
+		}
+	}
+
+	return nextRun
+}
+
+// runs the job function of the given run and records its result.
+func (s *Scheduler) execute(r *run, fn JobFunc, job *recurringJob) {
+	err := fn(r.abort)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.finishRun(r, err)
+	if job != nil {
+		job.running = nil
+	}
+}
+
+// registers a new run of the job with the given name.
+// the lock must be held while calling this function.
+func (s *Scheduler) startRun(name string, interval time.Duration) *run {
+	s.lastID++
+	r := &run{
+		info: &JobInfo{
+			ID:              s.lastID,
+			Name:            name,
+			State:           StateRunning,
+			IntervalSeconds: int64(interval.Seconds()),
+		},
+		started: time.Now(),
+		abort:   make(chan struct{}),
+	}
+	r.info.Started = r.started.Unix()
+
+	if s.stopped {
+		// jobs started during the shutdown are aborted right away
+		r.canceled = true
+		r.cancel()
+	}
+
+	s.running[r.info.ID] = r
+	return r
+}
+
+// records the result of the given run and moves it to the finished jobs.
+// the lock must be held while calling this function.
+func (s *Scheduler) finishRun(r *run, err error) {
+	delete(s.running, r.info.ID)
+
+	finished := time.Now()
+	r.info.Finished = finished.Unix()
+	r.info.DurationMs = finished.Sub(r.started).Milliseconds()
+
+	switch {
+	case r.canceled:
+		r.info.State = StateCanceled
+	case err != nil:
+		r.info.State = StateFailed
+	default:
+		r.info.State = StateCompleted
+	}
+	if err != nil {
+		r.info.Error = err.Error()
+	}
+
+	s.finished = append(s.finished, r.info)
+	if len(s.finished) > CompletedJobsHistorySize {
+		s.finished = s.finished[len(s.finished)-CompletedJobsHistorySize:]
+	}
+}
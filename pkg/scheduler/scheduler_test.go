@@ -0,0 +1,124 @@
+package scheduler_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/scheduler"
+)
+
+func TestRun(t *testing.T) {
+	s := scheduler.New()
+
+	errJob := errors.New("job failed")
+	require.NoError(t, s.Run("ok", nil, func(_ <-chan struct{}) error { return nil }))
+	require.Equal(t, errJob, s.Run("failing", nil, func(_ <-chan struct{}) error { return errJob }))
+
+	_, running, finished := s.Jobs()
+	require.Empty(t, running)
+	require.Len(t, finished, 2)
+	require.Equal(t, "failing", finished[0].Name)
+	require.Equal(t, scheduler.StateFailed, finished[0].State)
+	require.Equal(t, errJob.Error(), finished[0].Error)
+	require.Equal(t, "ok", finished[1].Name)
+	require.Equal(t, scheduler.StateCompleted, finished[1].State)
+}
+
+func TestCancel(t *testing.T) {
+	s := scheduler.New()
+
+	started := make(chan struct{})
+	id := s.Submit("blocking", func(abortSignal <-chan struct{}) error {
+		close(started)
+		<-abortSignal
+		return errors.New("aborted")
+	})
+	<-started
+
+	_, running, _ := s.Jobs()
+	require.Len(t, running, 1)
+	require.Equal(t, id, running[0].ID)
+	require.Equal(t, scheduler.StateRunning, running[0].State)
+
+	require.NoError(t, s.Cancel(id))
+	require.Eventually(t, func() bool {
+		_, running, finished := s.Jobs()
+		return len(running) == 0 && len(finished) == 1 && finished[0].State == scheduler.StateCanceled
+	}, time.Second, 10*time.Millisecond)
+
+	require.True(t, errors.Is(s.Cancel(id), scheduler.ErrJobNotFound))
+}
+
+func TestSchedule(t *testing.T) {
+	s := scheduler.New()
+
+	runs := make(chan struct{}, 10)
+	require.NoError(t, s.Schedule("recurring", 10*time.Millisecond, func(_ <-chan struct{}) error {
+		runs <- struct{}{}
+		return nil
+	}))
+	require.True(t, errors.Is(s.Schedule("recurring", time.Second, nil), scheduler.ErrJobAlreadyScheduled))
+
+	shutdownSignal := make(chan struct{})
+	go s.Start(shutdownSignal)
+	defer close(shutdownSignal)
+
+	<-runs
+	<-runs
+
+	scheduled, _, _ := s.Jobs()
+	require.Len(t, scheduled, 1)
+	require.Equal(t, scheduler.StateScheduled, scheduled[0].State)
+}
+
+func TestParseCron(t *testing.T) {
+	at := func(value string) time.Time {
+		parsed, err := time.ParseInLocation("2006-01-02 15:04", value, time.Local)
+		require.NoError(t, err)
+		return parsed
+	}
+
+	daily, err := scheduler.ParseCron("0 3 * * *")
+	require.NoError(t, err)
+	require.Equal(t, at("2020-06-01 03:00"), daily.Next(at("2020-05-31 03:00")))
+	require.Equal(t, at("2020-05-31 03:00"), daily.Next(at("2020-05-31 02:59")))
+
+	quarterly, err := scheduler.ParseCron("*/15 8-9 * * 1-5")
+	require.NoError(t, err)
+	// 2020-05-30 is a saturday
+	require.Equal(t, at("2020-06-01 08:00"), quarterly.Next(at("2020-05-30 08:00")))
+	require.Equal(t, at("2020-06-01 09:45"), quarterly.Next(at("2020-06-01 09:30")))
+
+	weekly, err := scheduler.ParseCron("@weekly")
+	require.NoError(t, err)
+	require.Equal(t, at("2020-05-31 00:00"), weekly.Next(at("2020-05-30 12:00")))
+
+	leapDay, err := scheduler.ParseCron("0 0 29 2 *")
+	require.NoError(t, err)
+	require.Equal(t, at("2024-02-29 00:00"), leapDay.Next(at("2020-03-01 00:00")))
+
+	never, err := scheduler.ParseCron("0 0 30 2 *")
+	require.NoError(t, err)
+	require.True(t, never.Next(at("2020-01-01 00:00")).IsZero())
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		_, err := scheduler.ParseCron(spec)
+		require.True(t, errors.Is(err, scheduler.ErrInvalidCronSpec), spec)
+	}
+}
+
+func TestScheduleCron(t *testing.T) {
+	s := scheduler.New()
+
+	require.NoError(t, s.ScheduleCron("cron", "@daily", func(_ <-chan struct{}) error { return nil }))
+	require.True(t, errors.Is(s.ScheduleCron("never", "0 0 30 2 *", nil), scheduler.ErrInvalidCronSpec))
+	require.True(t, errors.Is(s.ScheduleCron("cron", "@hourly", nil), scheduler.ErrJobAlreadyScheduled))
+
+	scheduled, _, _ := s.Jobs()
+	require.Len(t, scheduled, 1)
+	require.Equal(t, "@daily", scheduled[0].Cron)
+	require.Equal(t, 0, time.Unix(scheduled[0].NextRun, 0).Minute())
+}
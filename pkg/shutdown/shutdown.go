@@ -16,17 +16,30 @@ const (
 	PriorityPeeringTCPServer
 	PriorityPeerReconnecter
 	PriorityHeartbeats
+	PriorityNeighborSuggestions
+	PriorityCapabilities
+	PriorityKnownTransactions
+	PriorityPeerExchange
+	PriorityLatencyProbe
 	PriorityWarpSync
 	PriorityLocalSnapshots
+	PriorityScheduler
 	PriorityMetricsUpdater
+	PriorityPeerTrafficHistory
+	PriorityPeerReputation
+	PriorityPeerStore
+	PriorityPeerWebhooks
+	PriorityStaticPeerHealth
 	PriorityDashboard
 	PriorityPoWHandler
 	PriorityAPI
 	PriorityMetricsPublishers
 	PrioritySpammer
 	PriorityStatusReport
+	PriorityWatchdog
+	PriorityFleet
+	PriorityReplica
 	PriorityAutopeering
 	PriorityCoordinator
-	PriorityUpdateCheck
 	PriorityPrometheus
 )
@@ -0,0 +1,278 @@
+package snapshot
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+)
+
+// IndexEntriesConsumer consumes a chunk of solid entry points or seen milestones.
+type IndexEntriesConsumer func(entries []*IndexEntry) error
+
+// BalanceEntriesConsumer consumes a chunk of the ledger state.
+type BalanceEntriesConsumer func(entries []*BalanceEntry) error
+
+// LedgerChangeEntriesConsumer consumes a chunk of ledger changes.
+type LedgerChangeEntriesConsumer func(entries []*LedgerChangeEntry) error
+
+// AddressesConsumer consumes a chunk of spent addresses.
+type AddressesConsumer func(addresses hornet.Hashes) error
+
+// sectionReader makes sure that the sections are read in order. Skipped sections are discarded.
+type sectionReader struct {
+	r        io.Reader
+	sections []section
+	current  int
+}
+
+// enter starts reading the given section and returns its amount of entries.
+func (s *sectionReader) enter(index int) (int32, error) {
+	if index < s.current {
+		return 0, fmt.Errorf("%w: %s after %s", ErrWrongSectionOrder, s.sections[index].name, s.sections[s.current-1].name)
+	}
+
+	for ; s.current < index; s.current++ {
+		sec := s.sections[s.current]
+		if _, err := io.CopyN(ioutil.Discard, s.r, int64(sec.count)*sec.entrySize); err != nil {
+			return 0, fmt.Errorf("skipping %s: %w", sec.name, err)
+		}
+	}
+	s.current++
+
+	return s.sections[index].count, nil
+}
+
+// readChunks reads the entries of the given section in chunks of the given size.
+// readChunk reads the given amount of entries and passes them to the consumer.
+func (s *sectionReader) readChunks(index int, chunkSize int, readChunk func(size int) error) error {
+	count, err := s.enter(index)
+	if err != nil {
+		return err
+	}
+
+	if chunkSize <= 0 {
+		chunkSize = 1
+	}
+
+	for remaining := int(count); remaining > 0; remaining -= chunkSize {
+		size := chunkSize
+		if remaining < size {
+			size = remaining
+		}
+
+		if err := readChunk(size); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readIndexEntries reads the solid entry points or the seen milestones.
+func (s *sectionReader) readIndexEntries(index int, chunkSize int, consumer IndexEntriesConsumer) error {
+	return s.readChunks(index, chunkSize, func(size int) error {
+		entries := make([]*IndexEntry, size)
+		for i := range entries {
+			hash, err := readHash(s.r)
+			if err != nil {
+				return fmt.Errorf("%s: %w", s.sections[index].name, err)
+			}
+
+			var msIndex milestone.Index
+			if err := binary.Read(s.r, binary.LittleEndian, &msIndex); err != nil {
+				return fmt.Errorf("%s: %w", s.sections[index].name, err)
+			}
+
+			entries[i] = &IndexEntry{Hash: hash, Index: msIndex}
+		}
+		return consumer(entries)
+	})
+}
+
+// readAddresses reads the spent addresses.
+func (s *sectionReader) readAddresses(index int, chunkSize int, consumer AddressesConsumer) error {
+	return s.readChunks(index, chunkSize, func(size int) error {
+		addresses := make(hornet.Hashes, size)
+		for i := range addresses {
+			address, err := readHash(s.r)
+			if err != nil {
+				return fmt.Errorf("%s: %w", s.sections[index].name, err)
+			}
+			addresses[i] = address
+		}
+		return consumer(addresses)
+	})
+}
+
+// Reader streams a local snapshot file.
+type Reader struct {
+	sectionReader
+	header *Header
+}
+
+// NewReader reads the header of a local snapshot file and returns a Reader for its entries.
+func NewReader(r io.Reader) (*Reader, error) {
+	buf := bufio.NewReader(r)
+
+	header := &Header{}
+	if err := binary.Read(buf, binary.LittleEndian, &header.Version); err != nil {
+		return nil, err
+	}
+
+	if err := checkVersion(header.Version, SupportedLocalSnapshotFileVersions, "local snapshot file"); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if header.MilestoneHash, err = readHash(buf); err != nil {
+		return nil, err
+	}
+
+	for _, field := range []interface{}{&header.MilestoneIndex, &header.MilestoneTimestamp, &header.SolidEntryPointsCount, &header.SeenMilestonesCount, &header.LedgerEntriesCount, &header.SpentAddressesCount} {
+		if err := binary.Read(buf, binary.LittleEndian, field); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Reader{
+		sectionReader: sectionReader{
+			r:        buf,
+			sections: localSnapshotSections(header),
+		},
+		header: header,
+	}, nil
+}
+
+// Header returns the header of the local snapshot file.
+func (r *Reader) Header() *Header {
+	return r.header
+}
+
+// ReadSolidEntryPoints reads the solid entry points in chunks of the given size.
+func (r *Reader) ReadSolidEntryPoints(chunkSize int, consumer IndexEntriesConsumer) error {
+	return r.readIndexEntries(sectionSolidEntryPoints, chunkSize, consumer)
+}
+
+// ReadSeenMilestones reads the seen milestones in chunks of the given size.
+func (r *Reader) ReadSeenMilestones(chunkSize int, consumer IndexEntriesConsumer) error {
+	return r.readIndexEntries(sectionSeenMilestones, chunkSize, consumer)
+}
+
+// ReadBalances reads the ledger state in chunks of the given size.
+func (r *Reader) ReadBalances(chunkSize int, consumer BalanceEntriesConsumer) error {
+	return r.readChunks(sectionLedger, chunkSize, func(size int) error {
+		entries := make([]*BalanceEntry, size)
+		for i := range entries {
+			address, err := readHash(r.r)
+			if err != nil {
+				return fmt.Errorf("ledger entries: %w", err)
+			}
+
+			var balance uint64
+			if err := binary.Read(r.r, binary.LittleEndian, &balance); err != nil {
+				return fmt.Errorf("ledger entries: %w", err)
+			}
+
+			entries[i] = &BalanceEntry{Address: address, Balance: balance}
+		}
+		return consumer(entries)
+	})
+}
+
+// ReadSpentAddresses reads the spent addresses in chunks of the given size.
+func (r *Reader) ReadSpentAddresses(chunkSize int, consumer AddressesConsumer) error {
+	return r.readAddresses(sectionSpentAddresses, chunkSize, consumer)
+}
+
+// DeltaReader streams a delta snapshot file.
+type DeltaReader struct {
+	sectionReader
+	header *DeltaHeader
+}
+
+// NewDeltaReader reads the header of a delta snapshot file and returns a DeltaReader for its entries.
+func NewDeltaReader(r io.Reader) (*DeltaReader, error) {
+	buf := bufio.NewReader(r)
+
+	header := &DeltaHeader{}
+	if err := binary.Read(buf, binary.LittleEndian, &header.Version); err != nil {
+		return nil, err
+	}
+
+	if err := checkVersion(header.Version, SupportedDeltaSnapshotFileVersions, "delta snapshot file"); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if header.BaseMilestoneHash, err = readHash(buf); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Read(buf, binary.LittleEndian, &header.BaseMilestoneIndex); err != nil {
+		return nil, err
+	}
+
+	if header.MilestoneHash, err = readHash(buf); err != nil {
+		return nil, err
+	}
+
+	for _, field := range []interface{}{&header.MilestoneIndex, &header.MilestoneTimestamp, &header.SolidEntryPointsCount, &header.SeenMilestonesCount, &header.LedgerChangesCount, &header.SpentAddressesCount} {
+		if err := binary.Read(buf, binary.LittleEndian, field); err != nil {
+			return nil, err
+		}
+	}
+
+	return &DeltaReader{
+		sectionReader: sectionReader{
+			r:        buf,
+			sections: deltaSnapshotSections(header),
+		},
+		header: header,
+	}, nil
+}
+
+// Header returns the header of the delta snapshot file.
+func (r *DeltaReader) Header() *DeltaHeader {
+	return r.header
+}
+
+// ReadSolidEntryPoints reads the solid entry points in chunks of the given size.
+func (r *DeltaReader) ReadSolidEntryPoints(chunkSize int, consumer IndexEntriesConsumer) error {
+	return r.readIndexEntries(sectionSolidEntryPoints, chunkSize, consumer)
+}
+
+// ReadSeenMilestones reads the seen milestones in chunks of the given size.
+func (r *DeltaReader) ReadSeenMilestones(chunkSize int, consumer IndexEntriesConsumer) error {
+	return r.readIndexEntries(sectionSeenMilestones, chunkSize, consumer)
+}
+
+// ReadLedgerChanges reads the ledger changes in chunks of the given size.
+func (r *DeltaReader) ReadLedgerChanges(chunkSize int, consumer LedgerChangeEntriesConsumer) error {
+	return r.readChunks(sectionLedger, chunkSize, func(size int) error {
+		entries := make([]*LedgerChangeEntry, size)
+		for i := range entries {
+			address, err := readHash(r.r)
+			if err != nil {
+				return fmt.Errorf("ledger changes: %w", err)
+			}
+
+			var change int64
+			if err := binary.Read(r.r, binary.LittleEndian, &change); err != nil {
+				return fmt.Errorf("ledger changes: %w", err)
+			}
+
+			entries[i] = &LedgerChangeEntry{Address: address, Change: change}
+		}
+		return consumer(entries)
+	})
+}
+
+// ReadSpentAddresses reads the spent addresses in chunks of the given size.
+func (r *DeltaReader) ReadSpentAddresses(chunkSize int, consumer AddressesConsumer) error {
+	return r.readAddresses(sectionSpentAddresses, chunkSize, consumer)
+}
@@ -0,0 +1,161 @@
+// Package snapshot implements streaming readers and writers for local snapshot files and delta snapshot files.
+//
+// Local snapshot file format (little endian):
+// version (1) | ms hash (49) | ms index (4) | ms timestamp (8) |
+// SEPs count (4) | seen ms count (4) | ledger entries count (4) | spent addresses count (4) |
+// SEPs (49+4 each) | seen milestones (49+4 each) | ledger entries (49+8 each) | spent addresses (49 each) | sha256 (32)
+//
+// Delta snapshot file format (little endian):
+// version (1) | base ms hash (49) | base ms index (4) | ms hash (49) | ms index (4) | ms timestamp (8) |
+// SEPs count (4) | seen ms count (4) | ledger changes count (4) | spent addresses count (4) |
+// SEPs (49+4 each) | seen milestones (49+4 each) | ledger changes (49+8 each) | spent addresses (49 each) | sha256 (32)
+//
+// The sections have to be read and written in this order.
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+)
+
+const (
+	// LocalSnapshotFileVersion is the version of the local snapshot files which are written.
+	LocalSnapshotFileVersion byte = 4
+	// DeltaSnapshotFileVersion is the version of the delta snapshot files which are written.
+	DeltaSnapshotFileVersion byte = 1
+
+	// the length of the hashes and addresses in a snapshot file.
+	hashLength = 49
+
+	// the offset of the spent addresses count in a local snapshot file:
+	// 1 (version) + 49 (ms hash) + 4 (ms index) + 8 (ms timestamp) +
+	// 4 (SEPs count) + 4 (seen ms count) + 4 (ledger entries count) = 74
+	spentAddressesCountOffset = 74
+)
+
+var (
+	// SupportedLocalSnapshotFileVersions are the versions of the local snapshot files which can be read.
+	SupportedLocalSnapshotFileVersions = []byte{LocalSnapshotFileVersion}
+	// SupportedDeltaSnapshotFileVersions are the versions of the delta snapshot files which can be read.
+	SupportedDeltaSnapshotFileVersions = []byte{DeltaSnapshotFileVersion}
+
+	// ErrUnsupportedFileVersion is returned when the version of a snapshot file is not supported.
+	ErrUnsupportedFileVersion = errors.New("unsupported snapshot file version")
+	// ErrWrongSectionOrder is returned when a section is read or written after a later section.
+	ErrWrongSectionOrder = errors.New("snapshot file sections have to be processed in order")
+	// ErrEntryCountMismatch is returned when the amount of entries of a section doesn't match the header.
+	ErrEntryCountMismatch = errors.New("amount of entries does not match the header")
+)
+
+// Header is the header of a local snapshot file.
+type Header struct {
+	Version               byte
+	MilestoneHash         hornet.Hash
+	MilestoneIndex        milestone.Index
+	MilestoneTimestamp    int64
+	SolidEntryPointsCount int32
+	SeenMilestonesCount   int32
+	LedgerEntriesCount    int32
+	SpentAddressesCount   int32
+}
+
+// DeltaHeader is the header of a delta snapshot file, which contains the changes
+// since the local snapshot file of the base milestone.
+type DeltaHeader struct {
+	Version               byte
+	BaseMilestoneHash     hornet.Hash
+	BaseMilestoneIndex    milestone.Index
+	MilestoneHash         hornet.Hash
+	MilestoneIndex        milestone.Index
+	MilestoneTimestamp    int64
+	SolidEntryPointsCount int32
+	SeenMilestonesCount   int32
+	LedgerChangesCount    int32
+	SpentAddressesCount   int32
+}
+
+// IndexEntry is a solid entry point or a seen milestone.
+type IndexEntry struct {
+	Hash  hornet.Hash
+	Index milestone.Index
+}
+
+// BalanceEntry is the balance of an address in the ledger state.
+type BalanceEntry struct {
+	Address hornet.Hash
+	Balance uint64
+}
+
+// LedgerChangeEntry is the change of the balance of an address since the base milestone.
+type LedgerChangeEntry struct {
+	Address hornet.Hash
+	Change  int64
+}
+
+// PatchSpentAddressesCount overwrites the spent addresses count in the header of the local snapshot file.
+// The amount of spent addresses is usually not known before they are streamed into the file.
+func PatchSpentAddressesCount(file io.WriteSeeker, count int32) error {
+	if _, err := file.Seek(spentAddressesCountOffset, io.SeekStart); err != nil {
+		return err
+	}
+
+	if err := binary.Write(file, binary.LittleEndian, count); err != nil {
+		return err
+	}
+
+	_, err := file.Seek(0, io.SeekEnd)
+	return err
+}
+
+// AppendChecksum computes the sha256 checksum of the content of the file and appends it to the file.
+func AppendChecksum(file io.ReadWriteSeeker) ([]byte, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return nil, err
+	}
+
+	checksum := hash.Sum(nil)
+	if _, err := file.Write(checksum); err != nil {
+		return nil, err
+	}
+
+	return checksum, nil
+}
+
+// checks whether the given version is one of the supported versions.
+func checkVersion(version byte, supported []byte, fileType string) error {
+	for _, v := range supported {
+		if v == version {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s version is %d but only %v are supported", ErrUnsupportedFileVersion, fileType, version, supported)
+}
+
+// writes the given hash with the length of the hashes in a snapshot file.
+func writeHash(w io.Writer, hash hornet.Hash) error {
+	if len(hash) < hashLength {
+		return fmt.Errorf("hash too short: %d bytes", len(hash))
+	}
+	_, err := w.Write(hash[:hashLength])
+	return err
+}
+
+// reads a hash with the length of the hashes in a snapshot file.
+func readHash(r io.Reader) (hornet.Hash, error) {
+	hash := make(hornet.Hash, hashLength)
+	if _, err := io.ReadFull(r, hash); err != nil {
+		return nil, err
+	}
+	return hash, nil
+}
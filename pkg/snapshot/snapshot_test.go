@@ -0,0 +1,161 @@
+package snapshot_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/snapshot"
+)
+
+func testHash(b byte) hornet.Hash {
+	return bytes.Repeat([]byte{b}, 49)
+}
+
+func TestLocalSnapshotFile(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "export.bin")
+
+	file, err := os.OpenFile(filePath, os.O_RDWR|os.O_CREATE, 0660)
+	require.NoError(t, err)
+	defer file.Close()
+
+	header := &snapshot.Header{
+		MilestoneHash:         testHash(1),
+		MilestoneIndex:        1000,
+		MilestoneTimestamp:    1600000000,
+		SolidEntryPointsCount: 2,
+		SeenMilestonesCount:   1,
+		LedgerEntriesCount:    3,
+	}
+
+	w, err := snapshot.NewWriter(file, header)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteSolidEntryPoint(testHash(2), 990))
+	require.NoError(t, w.WriteSolidEntryPoint(testHash(3), 995))
+	require.NoError(t, w.WriteSeenMilestone(testHash(4), 1001))
+	for i := byte(0); i < 3; i++ {
+		require.NoError(t, w.WriteBalance(testHash(10+i), uint64(i+1)*100))
+	}
+	require.NoError(t, w.WriteSpentAddress(testHash(20)))
+	require.NoError(t, w.WriteSpentAddress(testHash(21)))
+
+	spentAddressesCount, err := w.Close()
+	require.NoError(t, err)
+	require.EqualValues(t, 2, spentAddressesCount)
+
+	require.NoError(t, snapshot.PatchSpentAddressesCount(file, spentAddressesCount))
+	checksum, err := snapshot.AppendChecksum(file)
+	require.NoError(t, err)
+
+	content, err := ioutil.ReadFile(filePath)
+	require.NoError(t, err)
+	expectedChecksum := sha256.Sum256(content[:len(content)-32])
+	require.Equal(t, expectedChecksum[:], checksum)
+	require.Equal(t, checksum, content[len(content)-32:])
+
+	r, err := snapshot.NewReader(bytes.NewReader(content))
+	require.NoError(t, err)
+	require.Equal(t, snapshot.LocalSnapshotFileVersion, r.Header().Version)
+	require.Equal(t, milestone.Index(1000), r.Header().MilestoneIndex)
+	require.EqualValues(t, 2, r.Header().SpentAddressesCount)
+
+	var solidEntryPoints []*snapshot.IndexEntry
+	require.NoError(t, r.ReadSolidEntryPoints(1, func(entries []*snapshot.IndexEntry) error {
+		require.Len(t, entries, 1)
+		solidEntryPoints = append(solidEntryPoints, entries...)
+		return nil
+	}))
+	require.Equal(t, []*snapshot.IndexEntry{{Hash: testHash(2), Index: 990}, {Hash: testHash(3), Index: 995}}, solidEntryPoints)
+
+	// the seen milestones are skipped
+	var chunks int
+	var total uint64
+	require.NoError(t, r.ReadBalances(2, func(entries []*snapshot.BalanceEntry) error {
+		chunks++
+		for _, entry := range entries {
+			total += entry.Balance
+		}
+		return nil
+	}))
+	require.Equal(t, 2, chunks)
+	require.EqualValues(t, 600, total)
+
+	require.True(t, errors.Is(r.ReadSeenMilestones(10, func([]*snapshot.IndexEntry) error { return nil }), snapshot.ErrWrongSectionOrder))
+
+	var spentAddresses hornet.Hashes
+	require.NoError(t, r.ReadSpentAddresses(10, func(addresses hornet.Hashes) error {
+		spentAddresses = append(spentAddresses, addresses...)
+		return nil
+	}))
+	require.Equal(t, hornet.Hashes{testHash(20), testHash(21)}, spentAddresses)
+}
+
+func TestLocalSnapshotFileEntryCount(t *testing.T) {
+	w, err := snapshot.NewWriter(ioutil.Discard, &snapshot.Header{MilestoneHash: testHash(1), SolidEntryPointsCount: 1})
+	require.NoError(t, err)
+
+	// a missing solid entry point
+	require.True(t, errors.Is(w.WriteSeenMilestone(testHash(2), 1), snapshot.ErrEntryCountMismatch))
+
+	w, err = snapshot.NewWriter(ioutil.Discard, &snapshot.Header{MilestoneHash: testHash(1), SolidEntryPointsCount: 1})
+	require.NoError(t, err)
+	require.NoError(t, w.WriteSolidEntryPoint(testHash(2), 1))
+
+	// too many solid entry points
+	require.True(t, errors.Is(w.WriteSolidEntryPoint(testHash(3), 1), snapshot.ErrEntryCountMismatch))
+}
+
+func TestDeltaSnapshotFile(t *testing.T) {
+	var buf bytes.Buffer
+
+	header := &snapshot.DeltaHeader{
+		BaseMilestoneHash:     testHash(1),
+		BaseMilestoneIndex:    1000,
+		MilestoneHash:         testHash(2),
+		MilestoneIndex:        1050,
+		MilestoneTimestamp:    1600000000,
+		SolidEntryPointsCount: 1,
+		LedgerChangesCount:    2,
+		SpentAddressesCount:   1,
+	}
+
+	w, err := snapshot.NewDeltaWriter(&buf, header)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteSolidEntryPoint(testHash(3), 1040))
+	require.NoError(t, w.WriteLedgerChange(testHash(10), -100))
+	require.NoError(t, w.WriteLedgerChange(testHash(11), 100))
+	require.NoError(t, w.WriteSpentAddress(testHash(10)))
+
+	checksum, err := w.Close()
+	require.NoError(t, err)
+
+	content := buf.Bytes()
+	expectedChecksum := sha256.Sum256(content[:len(content)-32])
+	require.Equal(t, expectedChecksum[:], checksum)
+	require.Equal(t, checksum, content[len(content)-32:])
+
+	r, err := snapshot.NewDeltaReader(bytes.NewReader(content))
+	require.NoError(t, err)
+	require.Equal(t, milestone.Index(1000), r.Header().BaseMilestoneIndex)
+	require.Equal(t, testHash(2), r.Header().MilestoneHash)
+
+	changes := make(map[string]int64)
+	require.NoError(t, r.ReadLedgerChanges(10, func(entries []*snapshot.LedgerChangeEntry) error {
+		for _, entry := range entries {
+			changes[string(entry.Address)] = entry.Change
+		}
+		return nil
+	}))
+	require.Equal(t, map[string]int64{string(testHash(10)): -100, string(testHash(11)): 100}, changes)
+
+	_, err = snapshot.NewReader(bytes.NewReader(content))
+	require.True(t, errors.Is(err, snapshot.ErrUnsupportedFileVersion))
+}
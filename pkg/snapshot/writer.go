@@ -0,0 +1,276 @@
+package snapshot
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+)
+
+const (
+	sectionSolidEntryPoints = iota
+	sectionSeenMilestones
+	sectionLedger
+	sectionSpentAddresses
+)
+
+// section is a section of a snapshot file with the amount of entries announced in the header.
+type section struct {
+	name      string
+	count     int32
+	entrySize int64
+	// whether the amount of entries may differ from the header, since it isn't known when the header is written.
+	unchecked bool
+}
+
+// sectionWriter makes sure that the sections are written in order and with the announced amount of entries.
+type sectionWriter struct {
+	w        io.Writer
+	sections []section
+	current  int
+	written  int32
+}
+
+// enter starts writing an entry of the given section.
+func (s *sectionWriter) enter(index int) error {
+	if index < s.current {
+		return fmt.Errorf("%w: %s after %s", ErrWrongSectionOrder, s.sections[index].name, s.sections[s.current].name)
+	}
+
+	for s.current < index {
+		if err := s.closeSection(); err != nil {
+			return err
+		}
+	}
+
+	if sec := s.sections[s.current]; !sec.unchecked && s.written >= sec.count {
+		return fmt.Errorf("%w: more than %d %s", ErrEntryCountMismatch, sec.count, sec.name)
+	}
+	s.written++
+
+	return nil
+}
+
+// closeSection checks the amount of entries of the current section and moves on to the next section.
+func (s *sectionWriter) closeSection() error {
+	if sec := s.sections[s.current]; !sec.unchecked && s.written != sec.count {
+		return fmt.Errorf("%w: %d/%d %s", ErrEntryCountMismatch, s.written, sec.count, sec.name)
+	}
+
+	s.current++
+	s.written = 0
+	return nil
+}
+
+// finish closes all remaining sections.
+func (s *sectionWriter) finish() (int32, error) {
+	var lastWritten int32
+	for s.current < len(s.sections) {
+		lastWritten = s.written
+		if err := s.closeSection(); err != nil {
+			return 0, err
+		}
+	}
+	return lastWritten, nil
+}
+
+// writeIndexEntry writes a solid entry point or a seen milestone.
+func (s *sectionWriter) writeIndexEntry(index int, hash hornet.Hash, msIndex milestone.Index) error {
+	if err := s.enter(index); err != nil {
+		return err
+	}
+
+	if err := writeHash(s.w, hash); err != nil {
+		return err
+	}
+
+	return binary.Write(s.w, binary.LittleEndian, msIndex)
+}
+
+// writeAddressEntry writes an address with its balance or its change.
+func (s *sectionWriter) writeAddressEntry(index int, address hornet.Hash, value interface{}) error {
+	if err := s.enter(index); err != nil {
+		return err
+	}
+
+	if err := writeHash(s.w, address); err != nil {
+		return err
+	}
+
+	if value == nil {
+		return nil
+	}
+
+	return binary.Write(s.w, binary.LittleEndian, value)
+}
+
+// Writer streams a local snapshot file.
+// The amount of spent addresses isn't checked, since it is usually not known before the spent addresses are written,
+// see PatchSpentAddressesCount. The checksum has to be appended afterwards, see AppendChecksum.
+type Writer struct {
+	sectionWriter
+	buf *bufio.Writer
+}
+
+// NewWriter writes the header of a local snapshot file and returns a Writer for its entries.
+func NewWriter(w io.Writer, header *Header) (*Writer, error) {
+	buf := bufio.NewWriterSize(w, 4096*2)
+
+	if err := binary.Write(buf, binary.LittleEndian, LocalSnapshotFileVersion); err != nil {
+		return nil, err
+	}
+
+	if err := writeHash(buf, header.MilestoneHash); err != nil {
+		return nil, err
+	}
+
+	for _, field := range []interface{}{header.MilestoneIndex, header.MilestoneTimestamp, header.SolidEntryPointsCount, header.SeenMilestonesCount, header.LedgerEntriesCount, header.SpentAddressesCount} {
+		if err := binary.Write(buf, binary.LittleEndian, field); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Writer{
+		sectionWriter: sectionWriter{
+			w:        buf,
+			sections: localSnapshotSections(header),
+		},
+		buf: buf,
+	}, nil
+}
+
+// localSnapshotSections returns the sections of a local snapshot file with the given header.
+func localSnapshotSections(header *Header) []section {
+	return []section{
+		{name: "solid entry points", count: header.SolidEntryPointsCount, entrySize: hashLength + 4},
+		{name: "seen milestones", count: header.SeenMilestonesCount, entrySize: hashLength + 4},
+		{name: "ledger entries", count: header.LedgerEntriesCount, entrySize: hashLength + 8},
+		{name: "spent addresses", count: header.SpentAddressesCount, entrySize: hashLength, unchecked: true},
+	}
+}
+
+// WriteSolidEntryPoint writes a solid entry point.
+func (w *Writer) WriteSolidEntryPoint(hash hornet.Hash, msIndex milestone.Index) error {
+	return w.writeIndexEntry(sectionSolidEntryPoints, hash, msIndex)
+}
+
+// WriteSeenMilestone writes a seen milestone.
+func (w *Writer) WriteSeenMilestone(hash hornet.Hash, msIndex milestone.Index) error {
+	return w.writeIndexEntry(sectionSeenMilestones, hash, msIndex)
+}
+
+// WriteBalance writes the balance of an address in the ledger state.
+func (w *Writer) WriteBalance(address hornet.Hash, balance uint64) error {
+	return w.writeAddressEntry(sectionLedger, address, balance)
+}
+
+// WriteSpentAddress writes a spent address.
+func (w *Writer) WriteSpentAddress(address hornet.Hash) error {
+	return w.writeAddressEntry(sectionSpentAddresses, address, nil)
+}
+
+// Close checks the amount of entries and flushes the written entries.
+// It returns the amount of written spent addresses.
+func (w *Writer) Close() (int32, error) {
+	spentAddressesCount, err := w.finish()
+	if err != nil {
+		return 0, err
+	}
+
+	return spentAddressesCount, w.buf.Flush()
+}
+
+// DeltaWriter streams a delta snapshot file and appends its checksum.
+type DeltaWriter struct {
+	sectionWriter
+	buf      *bufio.Writer
+	checksum hash.Hash
+}
+
+// NewDeltaWriter writes the header of a delta snapshot file and returns a DeltaWriter for its entries.
+func NewDeltaWriter(w io.Writer, header *DeltaHeader) (*DeltaWriter, error) {
+	buf := bufio.NewWriterSize(w, 4096*2)
+	checksum := sha256.New()
+	hashedBuf := io.MultiWriter(buf, checksum)
+
+	if err := binary.Write(hashedBuf, binary.LittleEndian, DeltaSnapshotFileVersion); err != nil {
+		return nil, err
+	}
+
+	if err := writeHash(hashedBuf, header.BaseMilestoneHash); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(hashedBuf, binary.LittleEndian, header.BaseMilestoneIndex); err != nil {
+		return nil, err
+	}
+
+	if err := writeHash(hashedBuf, header.MilestoneHash); err != nil {
+		return nil, err
+	}
+
+	for _, field := range []interface{}{header.MilestoneIndex, header.MilestoneTimestamp, header.SolidEntryPointsCount, header.SeenMilestonesCount, header.LedgerChangesCount, header.SpentAddressesCount} {
+		if err := binary.Write(hashedBuf, binary.LittleEndian, field); err != nil {
+			return nil, err
+		}
+	}
+
+	return &DeltaWriter{
+		sectionWriter: sectionWriter{
+			w:        hashedBuf,
+			sections: deltaSnapshotSections(header),
+		},
+		buf:      buf,
+		checksum: checksum,
+	}, nil
+}
+
+// deltaSnapshotSections returns the sections of a delta snapshot file with the given header.
+func deltaSnapshotSections(header *DeltaHeader) []section {
+	return []section{
+		{name: "solid entry points", count: header.SolidEntryPointsCount, entrySize: hashLength + 4},
+		{name: "seen milestones", count: header.SeenMilestonesCount, entrySize: hashLength + 4},
+		{name: "ledger changes", count: header.LedgerChangesCount, entrySize: hashLength + 8},
+		{name: "spent addresses", count: header.SpentAddressesCount, entrySize: hashLength},
+	}
+}
+
+// WriteSolidEntryPoint writes a solid entry point.
+func (w *DeltaWriter) WriteSolidEntryPoint(hash hornet.Hash, msIndex milestone.Index) error {
+	return w.writeIndexEntry(sectionSolidEntryPoints, hash, msIndex)
+}
+
+// WriteSeenMilestone writes a seen milestone.
+func (w *DeltaWriter) WriteSeenMilestone(hash hornet.Hash, msIndex milestone.Index) error {
+	return w.writeIndexEntry(sectionSeenMilestones, hash, msIndex)
+}
+
+// WriteLedgerChange writes the change of the balance of an address since the base milestone.
+func (w *DeltaWriter) WriteLedgerChange(address hornet.Hash, change int64) error {
+	return w.writeAddressEntry(sectionLedger, address, change)
+}
+
+// WriteSpentAddress writes an address which was spent since the base milestone.
+func (w *DeltaWriter) WriteSpentAddress(address hornet.Hash) error {
+	return w.writeAddressEntry(sectionSpentAddresses, address, nil)
+}
+
+// Close checks the amount of entries, appends the checksum and flushes the written entries.
+// It returns the sha256 checksum of the delta snapshot file.
+func (w *DeltaWriter) Close() ([]byte, error) {
+	if _, err := w.finish(); err != nil {
+		return nil, err
+	}
+
+	checksum := w.checksum.Sum(nil)
+	if _, err := w.buf.Write(checksum); err != nil {
+		return nil, err
+	}
+
+	return checksum, w.buf.Flush()
+}
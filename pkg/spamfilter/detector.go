@@ -0,0 +1,236 @@
+package spamfilter
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/atomic"
+
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+)
+
+const (
+	// the amount of trytes of the signature message fragment which are used to compute the payload fingerprint.
+	payloadFingerprintLength = 243
+	// the maximum amount of clusters which are tracked within a window.
+	maxClusters = 10000
+)
+
+// Filter is an action which is applied to transactions of detected spam sources.
+type Filter string
+
+const (
+	// FilterNoIndex does not add the tag of spam transactions to the tag index.
+	FilterNoIndex Filter = "noIndex"
+	// FilterNoRelayToUnknownPeers only relays spam transactions to statically configured peers.
+	FilterNoRelayToUnknownPeers Filter = "noRelayToUnknownPeers"
+)
+
+var (
+	// ErrUnknownFilter is returned if an unknown spam filter was configured.
+	ErrUnknownFilter = errors.New("unknown spam filter")
+)
+
+// ParseFilters parses the given filter names.
+func ParseFilters(names []string) ([]Filter, error) {
+	filters := make([]Filter, 0, len(names))
+	for _, name := range names {
+		switch f := Filter(name); f {
+		case FilterNoIndex, FilterNoRelayToUnknownPeers:
+			filters = append(filters, f)
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnknownFilter, name)
+		}
+	}
+	return filters, nil
+}
+
+// Source is a cluster of transactions with the same tag and a similar payload.
+type Source struct {
+	Tag                trinary.Trytes `json:"tag"`
+	PayloadFingerprint string         `json:"payloadFingerprint"`
+	// the amount of transactions of the cluster in the current or the last window, whichever is higher.
+	Count         int          `json:"count"`
+	ExampleTxHash trinary.Hash `json:"exampleTxHash"`
+	IsSpam        bool         `json:"isSpam"`
+}
+
+type clusterKey struct {
+	tag         string
+	fingerprint uint64
+}
+
+type cluster struct {
+	tag           hornet.Hash
+	fingerprint   uint64
+	count         int
+	lastCount     int
+	exampleTxHash hornet.Hash
+}
+
+func (c *cluster) rate() int {
+	if c.lastCount > c.count {
+		return c.lastCount
+	}
+	return c.count
+}
+
+// Detector clusters recent transactions by tag and payload similarity
+// and marks clusters which exceed a threshold within a time window as spam.
+type Detector struct {
+	window    time.Duration
+	threshold int
+
+	clustersLock sync.Mutex
+	clusters     map[clusterKey]*cluster
+	windowStart  time.Time
+
+	filters map[Filter]*atomic.Uint64
+}
+
+// NewDetector creates a new Detector.
+// Clusters with at least threshold transactions within the window are considered spam.
+func NewDetector(window time.Duration, threshold int, filters []Filter) *Detector {
+	d := &Detector{
+		window:      window,
+		threshold:   threshold,
+		clusters:    make(map[clusterKey]*cluster),
+		windowStart: time.Now(),
+		filters:     make(map[Filter]*atomic.Uint64),
+	}
+	for _, f := range filters {
+		d.filters[f] = atomic.NewUint64(0)
+	}
+	return d
+}
+
+// payloadFingerprint returns the fingerprint of the start of the signature message fragment.
+func payloadFingerprint(tx *hornet.Transaction) uint64 {
+	payload := tx.Tx.SignatureMessageFragment
+	if len(payload) > payloadFingerprintLength {
+		payload = payload[:payloadFingerprintLength]
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(payload))
+	return h.Sum64()
+}
+
+// rotateWindow starts a new window if the current one elapsed.
+// clustersLock must be held.
+func (d *Detector) rotateWindow(now time.Time) {
+	elapsed := now.Sub(d.windowStart)
+	if elapsed < d.window {
+		return
+	}
+
+	if elapsed >= 2*d.window {
+		// the last window didn't contain any transactions
+		d.clusters = make(map[clusterKey]*cluster)
+		d.windowStart = now
+		return
+	}
+
+	for key, c := range d.clusters {
+		if c.count == 0 {
+			delete(d.clusters, key)
+			continue
+		}
+		c.lastCount = c.count
+		c.count = 0
+	}
+	d.windowStart = d.windowStart.Add(d.window)
+}
+
+// Observe adds the given transaction to its cluster and returns whether the cluster is considered spam.
+func (d *Detector) Observe(tx *hornet.Transaction) bool {
+	key := clusterKey{tag: string(tx.GetTag()), fingerprint: payloadFingerprint(tx)}
+
+	d.clustersLock.Lock()
+	defer d.clustersLock.Unlock()
+
+	d.rotateWindow(time.Now())
+
+	c, exists := d.clusters[key]
+	if !exists {
+		if len(d.clusters) >= maxClusters {
+			return false
+		}
+		c = &cluster{tag: tx.GetTag(), fingerprint: key.fingerprint, exampleTxHash: tx.GetTxHash()}
+		d.clusters[key] = c
+	}
+	c.count++
+
+	return c.rate() >= d.threshold
+}
+
+// IsSpam returns whether the cluster of the given transaction is considered spam, without observing the transaction.
+func (d *Detector) IsSpam(tx *hornet.Transaction) bool {
+	key := clusterKey{tag: string(tx.GetTag()), fingerprint: payloadFingerprint(tx)}
+
+	d.clustersLock.Lock()
+	defer d.clustersLock.Unlock()
+
+	d.rotateWindow(time.Now())
+
+	c, exists := d.clusters[key]
+	if !exists {
+		return false
+	}
+	return c.rate() >= d.threshold
+}
+
+// TopSources returns the clusters with the highest amount of transactions, sorted descending.
+func (d *Detector) TopSources(limit int) []*Source {
+	d.clustersLock.Lock()
+	defer d.clustersLock.Unlock()
+
+	d.rotateWindow(time.Now())
+
+	sources := make([]*Source, 0, len(d.clusters))
+	for _, c := range d.clusters {
+		sources = append(sources, &Source{
+			Tag:                c.tag.Trytes(),
+			PayloadFingerprint: fmt.Sprintf("%016x", c.fingerprint),
+			Count:              c.rate(),
+			ExampleTxHash:      c.exampleTxHash.Trytes(),
+			IsSpam:             c.rate() >= d.threshold,
+		})
+	}
+
+	sort.Slice(sources, func(i, j int) bool {
+		return sources[i].Count > sources[j].Count
+	})
+
+	if limit > 0 && len(sources) > limit {
+		sources = sources[:limit]
+	}
+	return sources
+}
+
+// FilterEnabled returns whether the given filter is enabled.
+func (d *Detector) FilterEnabled(f Filter) bool {
+	_, enabled := d.filters[f]
+	return enabled
+}
+
+// FilterApplied increases the counter of the given filter.
+func (d *Detector) FilterApplied(f Filter) {
+	if counter, enabled := d.filters[f]; enabled {
+		counter.Inc()
+	}
+}
+
+// FilterCounters returns the amount of transactions each enabled filter was applied to.
+func (d *Detector) FilterCounters() map[Filter]uint64 {
+	counters := make(map[Filter]uint64, len(d.filters))
+	for f, counter := range d.filters {
+		counters[f] = counter.Load()
+	}
+	return counters
+}
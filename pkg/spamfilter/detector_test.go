@@ -0,0 +1,61 @@
+package spamfilter_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/transaction"
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/spamfilter"
+)
+
+func newTx(hash trinary.Hash, tag trinary.Trytes, message trinary.Trytes) *hornet.Transaction {
+	return hornet.NewTransactionFromTx(&transaction.Transaction{
+		Hash:                     trinary.MustPad(hash, consts.HashTrytesSize),
+		Tag:                      trinary.MustPad(tag, consts.TagTrinarySize/3),
+		SignatureMessageFragment: trinary.MustPad(message, consts.SignatureMessageFragmentSizeInTrytes),
+	}, nil)
+}
+
+func TestDetector(t *testing.T) {
+	d := spamfilter.NewDetector(time.Minute, 3, []spamfilter.Filter{spamfilter.FilterNoIndex})
+
+	for _, hash := range []trinary.Hash{"A", "B"} {
+		assert.False(t, d.Observe(newTx(hash, "SPAM", "HELLO")))
+	}
+	assert.False(t, d.Observe(newTx("C", "SPAM", "OTHER")))
+	assert.False(t, d.Observe(newTx("D", "VALID", "HELLO")))
+	assert.True(t, d.Observe(newTx("E", "SPAM", "HELLO")))
+
+	assert.True(t, d.IsSpam(newTx("F", "SPAM", "HELLO")))
+	assert.False(t, d.IsSpam(newTx("G", "SPAM", "OTHER")))
+
+	sources := d.TopSources(1)
+	assert.Len(t, sources, 1)
+	assert.Equal(t, 3, sources[0].Count)
+	assert.True(t, sources[0].IsSpam)
+	assert.Equal(t, trinary.MustPad("A", consts.HashTrytesSize), sources[0].ExampleTxHash)
+
+	assert.Len(t, d.TopSources(0), 3)
+
+	assert.True(t, d.FilterEnabled(spamfilter.FilterNoIndex))
+	assert.False(t, d.FilterEnabled(spamfilter.FilterNoRelayToUnknownPeers))
+	d.FilterApplied(spamfilter.FilterNoIndex)
+	d.FilterApplied(spamfilter.FilterNoRelayToUnknownPeers)
+	assert.Equal(t, map[spamfilter.Filter]uint64{spamfilter.FilterNoIndex: 1}, d.FilterCounters())
+}
+
+func TestParseFilters(t *testing.T) {
+	filters, err := spamfilter.ParseFilters([]string{"noIndex", "noRelayToUnknownPeers"})
+	assert.NoError(t, err)
+	assert.Equal(t, []spamfilter.Filter{spamfilter.FilterNoIndex, spamfilter.FilterNoRelayToUnknownPeers}, filters)
+
+	_, err = spamfilter.ParseFilters([]string{"dropAll"})
+	assert.True(t, errors.Is(err, spamfilter.ErrUnknownFilter))
+}
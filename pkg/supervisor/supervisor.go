@@ -0,0 +1,124 @@
+package supervisor
+
+import (
+	"reflect"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/hive.go/syncutils"
+)
+
+const (
+	// the delay before a crashed worker is restarted for the first time.
+	restartBackoffMin = 1 * time.Second
+	// the maximum delay before a crashed worker is restarted.
+	// a worker which ran longer than this without crashing is restarted with the minimum delay again.
+	restartBackoffMax = 1 * time.Minute
+)
+
+var (
+	log     *logger.Logger
+	logOnce sync.Once
+
+	restartCounts     = make(map[string]uint32)
+	restartCountsLock syncutils.RWMutex
+)
+
+func getLogger() *logger.Logger {
+	logOnce.Do(func() {
+		log = logger.NewLogger("Supervisor")
+	})
+	return log
+}
+
+// reports a recovered panic of the given plugin together with the stack trace.
+func logCrashReport(pluginName string, name string, r interface{}) {
+	getLogger().Errorf("crash report: plugin %s, worker %s panicked: %v\n%s", pluginName, name, r, debug.Stack())
+}
+
+// runs the handler and returns whether it panicked.
+func runProtected(pluginName string, name string, handler func()) (panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			logCrashReport(pluginName, name, r)
+			panicked = true
+		}
+	}()
+
+	handler()
+	return false
+}
+
+// Protect runs the given function and recovers from a panic in it, so that a single failing task
+// (e.g. a workerpool task or an event handler) of a plugin doesn't take down the whole node.
+// The panic is logged with a crash report, but not counted as a restart.
+func Protect(pluginName string, name string, handler func()) {
+	runProtected(pluginName, name, handler)
+}
+
+// NewClosure creates an event closure for the given handler of a plugin, which recovers from a panic in the handler like Protect.
+// Event handlers run in the goroutine which triggered the event (e.g. the solidifier), so an unprotected panic would take it down.
+// The handler has to be a function without return values.
+func NewClosure(pluginName string, name string, handler interface{}) *events.Closure {
+	handlerValue := reflect.ValueOf(handler)
+	if handlerValue.Kind() != reflect.Func || handlerValue.Type().NumOut() != 0 {
+		panic("event handler must be a function without return values")
+	}
+
+	return events.NewClosure(reflect.MakeFunc(handlerValue.Type(), func(args []reflect.Value) []reflect.Value {
+		runProtected(pluginName, name, func() { handlerValue.Call(args) })
+		return nil
+	}).Interface())
+}
+
+// BackgroundWorker starts a background worker of the given plugin in the daemon.
+// If the worker panics, the panic is logged with a crash report and the worker is restarted
+// with an exponential backoff, until the daemon shuts down.
+func BackgroundWorker(pluginName string, name string, handler daemon.WorkerFunc, priority ...int) error {
+	return daemon.BackgroundWorker(name, func(shutdownSignal <-chan struct{}) {
+		backoff := restartBackoffMin
+
+		for {
+			started := time.Now()
+			if !runProtected(pluginName, name, func() { handler(shutdownSignal) }) {
+				return
+			}
+
+			restartCountsLock.Lock()
+			restartCounts[pluginName]++
+			restartCountsLock.Unlock()
+
+			if time.Since(started) > restartBackoffMax {
+				backoff = restartBackoffMin
+			}
+
+			getLogger().Warnf("restarting worker %s of plugin %s in %v", name, pluginName, backoff)
+
+			select {
+			case <-shutdownSignal:
+				return
+			case <-time.After(backoff):
+			}
+
+			if backoff *= 2; backoff > restartBackoffMax {
+				backoff = restartBackoffMax
+			}
+		}
+	}, priority...)
+}
+
+// RestartCounts returns the amount of worker restarts after a panic per plugin.
+func RestartCounts() map[string]uint32 {
+	restartCountsLock.RLock()
+	defer restartCountsLock.RUnlock()
+
+	counts := make(map[string]uint32, len(restartCounts))
+	for pluginName, count := range restartCounts {
+		counts[pluginName] = count
+	}
+	return counts
+}
@@ -139,6 +139,7 @@ func (te *TestEnvironment) configureStorages(store kvstore.KVStore) {
 		store.WithRealm([]byte("spent")),
 		profile.Profile2GB.Caches,
 	)
+	tangle.ConfigureTransactionFilter(true)
 
 	setupTangleOnce.Do(func() {
 		tangle.LoadInitialValuesFromDatabase()
@@ -0,0 +1,26 @@
+package toolset
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+func ed25519KeyGen(args []string) error {
+
+	if len(args) > 0 {
+		return errors.New("too many arguments for 'ed25519key'")
+	}
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Your ed25519 private key: ", hex.EncodeToString(privateKey))
+	fmt.Println("Your ed25519 public key:  ", hex.EncodeToString(publicKey))
+
+	return nil
+}
@@ -0,0 +1,520 @@
+package toolset
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/iota.go/bundle"
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/pow"
+	"github.com/iotaledger/iota.go/transaction"
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/gohornet/hornet/pkg/compressed"
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/dag"
+	"github.com/gohornet/hornet/pkg/metrics"
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/mselection"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/profile"
+	"github.com/gohornet/hornet/pkg/tipselect"
+	"github.com/gohornet/hornet/pkg/utils"
+)
+
+const (
+	// the amount of milestone intervals before the end of the simulation in which issued transactions
+	// are not considered for the orphanage, because they didn't have the chance to get confirmed yet.
+	simOrphanageGraceMilestones = 3
+
+	// the attacker strategies of the simulation.
+	simAttackerNone     = "none"
+	simAttackerLazy     = "lazy"
+	simAttackerBlowball = "blowball"
+	simAttackerChain    = "chain"
+)
+
+var (
+	simHonestTag   = trinary.MustPad("TIPSELSIM", consts.TagTrinarySize/3)
+	simAttackerTag = trinary.MustPad("TIPSELSIMATTACKER", consts.TagTrinarySize/3)
+	simCooTag      = trinary.MustPad("TIPSELSIMCOO", consts.TagTrinarySize/3)
+)
+
+// the issuer of a simulated transaction.
+type simIssuer int
+
+const (
+	simIssuerHonest simIssuer = iota
+	simIssuerAttacker
+	simIssuerCoordinator
+)
+
+// the lifecycle of a simulated transaction.
+type simTx struct {
+	issuer      simIssuer
+	issuedAt    time.Time
+	approvedAt  time.Time
+	confirmedAt time.Time
+}
+
+// a transaction which was confirmed by a simulated milestone.
+type simConfirmedTx struct {
+	hash  hornet.Hash
+	index milestone.Index
+}
+
+// tipSelSimulation runs the tip selection of the node and the heaviest branch selection of the coordinator
+// against a synthetic workload on an in-memory tangle.
+type tipSelSimulation struct {
+	tipSelector *tipselect.TipSelector
+	cooSelector *mselection.HeaviestSelector
+	powFunc     pow.ProofOfWorkFunc
+
+	attackerStrategy string
+	belowMaxDepth    milestone.Index
+
+	txs               map[string]*simTx
+	confirmedTxs      []*simConfirmedTx
+	lastMilestoneHash hornet.Hash
+	lastAttackerHash  hornet.Hash
+
+	milestones           int
+	failedTipSelections  int
+	tipPoolSamples       int
+	nonLazyTipsTotal     int
+	semiLazyTipsTotal    int
+	honestTxsIssued      int
+	attackerTxsIssued    int
+	attackerTxsConfirmed int
+}
+
+func tipSelSimulate(args []string) error {
+
+	fs := flag.NewFlagSet("tipselsim", flag.ContinueOnError)
+	duration := fs.Duration("duration", time.Minute, "the duration of the simulation")
+	mps := fs.Float64("mps", 20, "the amount of honest transactions issued per second")
+	milestoneInterval := fs.Duration("milestoneInterval", 10*time.Second, "the interval in which milestones are issued")
+	attackerStrategy := fs.String("attacker", simAttackerNone, fmt.Sprintf("the strategy of the attacker (%s, %s, %s, %s)", simAttackerNone, simAttackerLazy, simAttackerBlowball, simAttackerChain))
+	attackerMPS := fs.Float64("attackerMPS", 10, "the amount of attacker transactions issued per second")
+
+	// the options are passed as "name=value", since flags of tools would be rejected by the flag parsing of the node
+	flagArgs := make([]string, len(args))
+	for i, arg := range args {
+		if !strings.Contains(arg, "=") {
+			return fmt.Errorf("invalid argument for 'tipselsim': %s, options must be passed as name=value", arg)
+		}
+		flagArgs[i] = "--" + arg
+	}
+
+	if err := fs.Parse(flagArgs); err != nil {
+		return err
+	}
+
+	switch *attackerStrategy {
+	case simAttackerNone, simAttackerLazy, simAttackerBlowball, simAttackerChain:
+	default:
+		return fmt.Errorf("unknown attacker strategy: %s", *attackerStrategy)
+	}
+
+	if *mps <= 0 || *milestoneInterval <= 0 || *duration <= 0 || (*attackerStrategy != simAttackerNone && *attackerMPS <= 0) {
+		return errors.New("the duration, the milestone interval and the rates must be greater than zero")
+	}
+
+	sim := newTipSelSimulation(*attackerStrategy)
+
+	fmt.Printf("simulating %v with %0.2f MPS, a milestone interval of %v and attacker strategy '%s'...\n", *duration, *mps, *milestoneInterval, *attackerStrategy)
+
+	if err := sim.run(*duration, *mps, *milestoneInterval, *attackerMPS); err != nil {
+		return err
+	}
+
+	sim.printStats(time.Now().Add(-simOrphanageGraceMilestones * *milestoneInterval))
+	return nil
+}
+
+// newTipSelSimulation configures an in-memory tangle and the tip selectors with the values of the node config.
+func newTipSelSimulation(attackerStrategy string) *tipSelSimulation {
+
+	store := mapdb.NewMapDB()
+	tangle.ConfigureStorages(store.WithRealm([]byte("tangle")), store.WithRealm([]byte("snapshot")), store.WithRealm([]byte("spent")), profile.LoadProfile().Caches)
+	tangle.LoadInitialValuesFromDatabase()
+
+	// the genesis is the only solid entry point
+	tangle.WriteLockSolidEntryPoints()
+	tangle.SolidEntryPointsAdd(hornet.NullHashBytes, 0)
+	tangle.WriteUnlockSolidEntryPoints()
+
+	tangle.SetLatestMilestoneIndex(1)
+	tangle.SetSolidMilestoneIndex(1)
+
+	dag.ConfigureRootSnapshotIndexesWriteBack(
+		config.NodeConfig.GetInt(config.CfgTipSelWriteBackMaxEntries),
+		time.Duration(config.NodeConfig.GetInt(config.CfgTipSelWriteBackMaxDelayMilliseconds))*time.Millisecond,
+	)
+
+	_, powFunc := pow.GetFastestProofOfWorkImpl()
+
+	return &tipSelSimulation{
+		tipSelector: tipselect.New(
+			config.NodeConfig.GetInt(config.CfgTipSelMaxDeltaTxYoungestRootSnapshotIndexToLSMI),
+			config.NodeConfig.GetInt(config.CfgTipSelMaxDeltaTxOldestRootSnapshotIndexToLSMI),
+			config.NodeConfig.GetInt(config.CfgTipSelBelowMaxDepth),
+
+			config.NodeConfig.GetInt(config.CfgTipSelNonLazy+config.CfgTipSelRetentionRulesTipsLimit),
+			time.Duration(time.Second*time.Duration(config.NodeConfig.GetInt(config.CfgTipSelNonLazy+config.CfgTipSelMaxReferencedTipAgeSeconds))),
+			config.NodeConfig.GetUint32(config.CfgTipSelNonLazy+config.CfgTipSelMaxApprovers),
+			config.NodeConfig.GetInt(config.CfgTipSelNonLazy+config.CfgTipSelSpammerTipsThreshold),
+
+			config.NodeConfig.GetInt(config.CfgTipSelSemiLazy+config.CfgTipSelRetentionRulesTipsLimit),
+			time.Duration(time.Second*time.Duration(config.NodeConfig.GetInt(config.CfgTipSelSemiLazy+config.CfgTipSelMaxReferencedTipAgeSeconds))),
+			config.NodeConfig.GetUint32(config.CfgTipSelSemiLazy+config.CfgTipSelMaxApprovers),
+			config.NodeConfig.GetInt(config.CfgTipSelSemiLazy+config.CfgTipSelSpammerTipsThreshold),
+		),
+		cooSelector: mselection.New(
+			config.NodeConfig.GetInt(config.CfgCoordinatorTipselectMinHeaviestBranchUnconfirmedTransactionsThreshold),
+			config.NodeConfig.GetInt(config.CfgCoordinatorTipselectMaxHeaviestBranchTipsPerCheckpoint),
+			config.NodeConfig.GetInt(config.CfgCoordinatorTipselectRandomTipsPerCheckpoint),
+			time.Duration(config.NodeConfig.GetInt(config.CfgCoordinatorTipselectHeaviestBranchSelectionDeadlineMilliseconds))*time.Millisecond,
+		),
+		powFunc:           powFunc,
+		attackerStrategy:  attackerStrategy,
+		belowMaxDepth:     milestone.Index(config.NodeConfig.GetInt(config.CfgTipSelBelowMaxDepth)),
+		txs:               make(map[string]*simTx),
+		lastMilestoneHash: hornet.NullHashBytes,
+	}
+}
+
+// run issues the transactions and milestones of the workload in real time,
+// since the retention rules of the tip selection are based on the wall clock.
+func (sim *tipSelSimulation) run(duration time.Duration, mps float64, milestoneInterval time.Duration, attackerMPS float64) error {
+
+	honestTicker := time.NewTicker(time.Duration(float64(time.Second) / mps))
+	defer honestTicker.Stop()
+
+	milestoneTicker := time.NewTicker(milestoneInterval)
+	defer milestoneTicker.Stop()
+
+	cleanupTicker := time.NewTicker(time.Second)
+	defer cleanupTicker.Stop()
+
+	// the attacker ticker never fires if there is no attacker
+	var attackerTick <-chan time.Time
+	if sim.attackerStrategy != simAttackerNone {
+		attackerTicker := time.NewTicker(time.Duration(float64(time.Second) / attackerMPS))
+		defer attackerTicker.Stop()
+		attackerTick = attackerTicker.C
+	}
+
+	end := time.After(duration)
+	lastStatusTime := time.Now()
+
+	for {
+		select {
+		case <-end:
+			dag.FlushRootSnapshotIndexes()
+			return nil
+
+		case <-honestTicker.C:
+			if err := sim.issueHonestTx(); err != nil {
+				return err
+			}
+
+		case <-attackerTick:
+			if err := sim.issueAttackerTx(); err != nil {
+				return err
+			}
+
+		case <-milestoneTicker.C:
+			if err := sim.issueMilestone(); err != nil {
+				return err
+			}
+
+		case <-cleanupTicker.C:
+			sim.tipSelector.CleanUpReferencedTips()
+
+			sim.tipPoolSamples++
+			sim.nonLazyTipsTotal += int(metrics.SharedServerMetrics.TipsNonLazy.Load())
+			sim.semiLazyTipsTotal += int(metrics.SharedServerMetrics.TipsSemiLazy.Load())
+
+			if time.Since(lastStatusTime) >= printStatusInterval {
+				lastStatusTime = time.Now()
+				fmt.Printf("issued %d honest and %d attacker transactions, %d milestones...\n", sim.honestTxsIssued, sim.attackerTxsIssued, sim.milestones)
+			}
+		}
+	}
+}
+
+// issueHonestTx issues a transaction on top of the tips selected by the tip selection of the node.
+func (sim *tipSelSimulation) issueHonestTx() error {
+	tips, err := sim.tipSelector.SelectNonLazyTips()
+	if err != nil {
+		if err != tipselect.ErrNoTipsAvailable {
+			return err
+		}
+
+		// the tip pool ran empty, attach to the latest milestone instead
+		sim.failedTipSelections++
+		tips = hornet.Hashes{sim.lastMilestoneHash, sim.lastMilestoneHash}
+	}
+
+	if _, err := sim.issueTx(simIssuerHonest, simHonestTag, tips[0], tips[1]); err != nil {
+		return err
+	}
+	sim.honestTxsIssued++
+
+	return nil
+}
+
+// issueAttackerTx issues a transaction according to the strategy of the attacker.
+func (sim *tipSelSimulation) issueAttackerTx() error {
+	var trunk, branch hornet.Hash
+
+	switch sim.attackerStrategy {
+	case simAttackerLazy:
+		// approve transactions which were confirmed below max depth to flood the node with lazy tips
+		trunk, branch = sim.randomConfirmedTxBelowMaxDepth(), sim.randomConfirmedTxBelowMaxDepth()
+
+	case simAttackerBlowball:
+		// approve the latest milestone only to flood the tip pool with non-lazy tips
+		trunk, branch = sim.lastMilestoneHash, sim.lastMilestoneHash
+
+	case simAttackerChain:
+		// build a chain which doesn't approve any honest transactions to attract the coordinator
+		if sim.lastAttackerHash == nil {
+			sim.lastAttackerHash = sim.lastMilestoneHash
+		}
+		trunk, branch = sim.lastAttackerHash, sim.lastAttackerHash
+	}
+
+	txHash, err := sim.issueTx(simIssuerAttacker, simAttackerTag, trunk, branch)
+	if err != nil {
+		return err
+	}
+	sim.lastAttackerHash = txHash
+	sim.attackerTxsIssued++
+
+	return nil
+}
+
+// returns a random transaction which was confirmed below max depth, or the genesis if there is none.
+func (sim *tipSelSimulation) randomConfirmedTxBelowMaxDepth() hornet.Hash {
+	lsmi := tangle.GetSolidMilestoneIndex()
+	if lsmi <= sim.belowMaxDepth+1 {
+		return hornet.NullHashBytes
+	}
+
+	// the transactions are ordered by their confirmation index
+	count := sort.Search(len(sim.confirmedTxs), func(i int) bool {
+		return sim.confirmedTxs[i].index > lsmi-sim.belowMaxDepth-1
+	})
+	if count == 0 {
+		return hornet.NullHashBytes
+	}
+
+	return sim.confirmedTxs[utils.RandomInsecure(0, count-1)].hash
+}
+
+// issueMilestone issues checkpoints on top of the tips selected by the heaviest branch selection of the coordinator,
+// confirms the cone of the milestone and updates the root snapshot indexes and the tip scores like the node does.
+func (sim *tipSelSimulation) issueMilestone() error {
+	tips, err := sim.cooSelector.SelectTips(1)
+	if err != nil && err != mselection.ErrNoTipsAvailable {
+		return err
+	}
+
+	// the first checkpoint approves the previous milestone, the last checkpoint is the milestone itself
+	milestoneHash := sim.lastMilestoneHash
+	for _, tip := range tips {
+		if milestoneHash, err = sim.issueTx(simIssuerCoordinator, simCooTag, milestoneHash, tip); err != nil {
+			return err
+		}
+	}
+	if milestoneHash, err = sim.issueTx(simIssuerCoordinator, simCooTag, milestoneHash, milestoneHash); err != nil {
+		return err
+	}
+
+	msIndex := tangle.GetSolidMilestoneIndex() + 1
+	now := time.Now()
+
+	var tailsReferenced hornet.Hashes
+	if err := dag.TraverseApprovees(milestoneHash,
+		// traversal stops at already confirmed transactions
+		func(cachedTxMeta *tangle.CachedMetadata) (bool, error) { // meta +1
+			defer cachedTxMeta.Release(true) // meta -1
+			confirmed, _ := cachedTxMeta.GetMetadata().GetConfirmed()
+			return !confirmed, nil
+		},
+		// consumer
+		func(cachedTxMeta *tangle.CachedMetadata) error { // meta +1
+			defer cachedTxMeta.Release(true) // meta -1
+
+			cachedTxMeta.GetMetadata().SetConfirmed(true, msIndex)
+
+			txHash := cachedTxMeta.GetMetadata().GetTxHash()
+			if cachedTxMeta.GetMetadata().IsTail() {
+				tailsReferenced = append(tailsReferenced, txHash)
+			}
+			sim.confirmedTxs = append(sim.confirmedTxs, &simConfirmedTx{hash: txHash, index: msIndex})
+
+			if tx, exists := sim.txs[string(txHash)]; exists {
+				tx.confirmedAt = now
+				if tx.issuer == simIssuerAttacker {
+					sim.attackerTxsConfirmed++
+				}
+			}
+			return nil
+		},
+		// called on missing approvees
+		func(approveeHash hornet.Hash) error {
+			return fmt.Errorf("%w: %s", tangle.ErrTransactionNotFound, approveeHash.Trytes())
+		},
+		// called on solid entry points
+		func(txHash hornet.Hash) {}, false, false, nil); err != nil {
+		return err
+	}
+
+	tangle.SetLatestMilestoneIndex(msIndex)
+	tangle.SetSolidMilestoneIndex(msIndex)
+
+	// propagate the new transaction root snapshot indexes to the future cone like the URTS plugin
+	dag.UpdateTransactionRootSnapshotIndexes(tailsReferenced, msIndex)
+	sim.tipSelector.UpdateScores()
+
+	sim.lastMilestoneHash = milestoneHash
+	sim.milestones++
+
+	return nil
+}
+
+// issueTx creates a zero value transaction on top of the given trunk and branch,
+// stores and solidifies it and passes it to the tip selectors like the node does.
+func (sim *tipSelSimulation) issueTx(issuer simIssuer, tag trinary.Trytes, trunk hornet.Hash, branch hornet.Hash) (hornet.Hash, error) {
+
+	b, err := bundle.Finalize(bundle.AddEntry(nil, bundle.BundleEntry{
+		Address:                   utils.RandomKerlHashTrytesInsecure(),
+		Value:                     0,
+		Tag:                       tag,
+		Timestamp:                 uint64(time.Now().Unix()),
+		Length:                    uint64(1),
+		SignatureMessageFragments: []trinary.Trytes{trinary.MustPad("", consts.SignatureMessageFragmentSizeInTrytes)},
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	powed, err := pow.DoPoW(trunk.Trytes(), branch.Trytes(), transaction.MustFinalTransactionTrytes(b), 1, sim.powFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := transaction.AsTransactionObject(powed[0])
+	if err != nil {
+		return nil, err
+	}
+
+	txTrits, err := transaction.TransactionToTrits(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	hornetTx := hornet.NewTransactionFromTx(tx, compressed.TruncateTxTrits(txTrits))
+
+	cachedTx, _ := tangle.AddTransactionToStorage(hornetTx, tangle.GetLatestMilestoneIndex(), false, true, false) // tx +1
+	defer cachedTx.Release(true)                                                                                  // tx -1
+
+	txHash := cachedTx.GetTransaction().GetTxHash()
+
+	now := time.Now()
+	sim.txs[string(txHash)] = &simTx{issuer: issuer, issuedAt: now}
+	for _, approveeHash := range []hornet.Hash{trunk, branch} {
+		if approvee, exists := sim.txs[string(approveeHash)]; exists && approvee.approvedAt.IsZero() {
+			approvee.approvedAt = now
+		}
+	}
+
+	// the approvees are always known, so the transaction is solid immediately
+	cachedTx.GetMetadata().SetSolid(true)
+	tangle.OnTailTransactionSolid(cachedTx.Retain()) // tx pass +1
+
+	cachedBndl := tangle.GetCachedBundleOrNil(txHash) // bundle +1
+	if cachedBndl == nil {
+		return nil, fmt.Errorf("bundle of transaction %s was not constructed", txHash.Trytes())
+	}
+	defer cachedBndl.Release(true) // bundle -1
+
+	if !cachedBndl.GetBundle().IsValid() {
+		return nil, fmt.Errorf("bundle of transaction %s is invalid", txHash.Trytes())
+	}
+
+	sim.tipSelector.AddTip(cachedBndl.GetBundle())
+	sim.cooSelector.OnNewSolidBundle(cachedBndl.GetBundle())
+
+	return txHash, nil
+}
+
+// printStats prints the orphanage and latency statistics of the honest transactions issued before the given time.
+func (sim *tipSelSimulation) printStats(evaluateBefore time.Time) {
+	var evaluated, orphaned int
+	var approvalLatencies, confirmationLatencies []time.Duration
+
+	for _, tx := range sim.txs {
+		if tx.issuer != simIssuerHonest || !tx.issuedAt.Before(evaluateBefore) {
+			continue
+		}
+		evaluated++
+
+		if !tx.approvedAt.IsZero() {
+			approvalLatencies = append(approvalLatencies, tx.approvedAt.Sub(tx.issuedAt))
+		}
+
+		if tx.confirmedAt.IsZero() {
+			orphaned++
+			continue
+		}
+		confirmationLatencies = append(confirmationLatencies, tx.confirmedAt.Sub(tx.issuedAt))
+	}
+
+	fmt.Printf("\nissued %d honest and %d attacker transactions, %d milestones\n", sim.honestTxsIssued, sim.attackerTxsIssued, sim.milestones)
+	if evaluated == 0 {
+		fmt.Println("no transactions were issued early enough to be evaluated, increase the duration of the simulation")
+		return
+	}
+
+	fmt.Printf("orphaned honest transactions: %d/%d (%0.2f%%)\n", orphaned, evaluated, float64(orphaned)*100/float64(evaluated))
+	fmt.Printf("unapproved honest transactions: %d/%d\n", evaluated-len(approvalLatencies), evaluated)
+	fmt.Printf("approval latency: %s\n", formatLatencies(approvalLatencies))
+	fmt.Printf("confirmation latency: %s\n", formatLatencies(confirmationLatencies))
+	fmt.Printf("failed tip selections: %d\n", sim.failedTipSelections)
+	if sim.tipPoolSamples > 0 {
+		fmt.Printf("average tip pool size: %0.2f non-lazy, %0.2f semi-lazy\n", float64(sim.nonLazyTipsTotal)/float64(sim.tipPoolSamples), float64(sim.semiLazyTipsTotal)/float64(sim.tipPoolSamples))
+	}
+	if sim.attackerStrategy != simAttackerNone {
+		fmt.Printf("confirmed attacker transactions: %d/%d\n", sim.attackerTxsConfirmed, sim.attackerTxsIssued)
+	}
+}
+
+// formatLatencies returns the average, median and 95th percentile of the given latencies.
+func formatLatencies(latencies []time.Duration) string {
+	if len(latencies) == 0 {
+		return "-"
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	var total time.Duration
+	for _, latency := range latencies {
+		total += latency
+	}
+
+	return fmt.Sprintf("avg %v, median %v, p95 %v",
+		(total / time.Duration(len(latencies))).Truncate(time.Millisecond),
+		latencies[len(latencies)/2].Truncate(time.Millisecond),
+		latencies[len(latencies)*95/100].Truncate(time.Millisecond))
+}
@@ -8,10 +8,12 @@ import (
 
 var (
 	tools = map[string]func([]string) error{
-		"pwdhash": hashPasswordAndSalt,
-		"seedgen": seedGen,
-		"list":    listTools,
-		"merkle":  merkleTreeCreate,
+		"pwdhash":    hashPasswordAndSalt,
+		"seedgen":    seedGen,
+		"list":       listTools,
+		"merkle":     merkleTreeCreate,
+		"ed25519key": ed25519KeyGen,
+		"tipselsim":  tipSelSimulate,
 	}
 )
 
@@ -58,6 +60,9 @@ func listTools(args []string) error {
 	fmt.Println("pwdhash: generates a sha265 sum from your password and salt")
 	fmt.Println("seedgen: generates an autopeering seed")
 	fmt.Println("merkle: generates a Merkle tree for coordinator plugin")
+	fmt.Println("ed25519key: generates an ed25519 key pair to sign local snapshot files")
+	fmt.Println("tipselsim: simulates the tip selection against a synthetic workload and prints orphanage and latency statistics")
+	fmt.Println("           options: duration=1m mps=20 milestoneInterval=10s attacker=none|lazy|blowball|chain attackerMPS=10")
 
 	return nil
 }
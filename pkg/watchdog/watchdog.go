@@ -0,0 +1,112 @@
+package watchdog
+
+import (
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/iotaledger/hive.go/syncutils"
+)
+
+var (
+	loops     []*Loop
+	loopsLock syncutils.RWMutex
+)
+
+// Loop is a long-lived event loop which reports the begin and the end of every iteration,
+// so that iterations which don't finish within a deadline can be detected.
+type Loop struct {
+	name       string
+	lock       syncutils.Mutex
+	nextID     uint64
+	iterations map[uint64]time.Time
+}
+
+// Stall describes a loop with an iteration which didn't finish within the deadline.
+type Stall struct {
+	// The name of the stalled loop.
+	Loop string
+	// The time the oldest unfinished iteration of the loop began.
+	Since time.Time
+}
+
+// RegisterLoop registers a new loop with the given name to be monitored.
+func RegisterLoop(name string) *Loop {
+	loop := &Loop{
+		name:       name,
+		iterations: make(map[uint64]time.Time),
+	}
+
+	loopsLock.Lock()
+	defer loopsLock.Unlock()
+	loops = append(loops, loop)
+
+	return loop
+}
+
+// Name returns the name of the loop.
+func (l *Loop) Name() string {
+	return l.name
+}
+
+// Begin marks the begin of an iteration of the loop and returns the function which marks its end.
+// Loops which process several iterations concurrently (e.g. worker pools) call Begin for each of them.
+func (l *Loop) Begin() (end func()) {
+	l.lock.Lock()
+	id := l.nextID
+	l.nextID++
+	l.iterations[id] = time.Now()
+	l.lock.Unlock()
+
+	return func() {
+		l.lock.Lock()
+		delete(l.iterations, id)
+		l.lock.Unlock()
+	}
+}
+
+// returns the begin of the oldest unfinished iteration, or false if the loop is idle.
+func (l *Loop) oldestIteration() (time.Time, bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	var oldest time.Time
+	for _, began := range l.iterations {
+		if oldest.IsZero() || began.Before(oldest) {
+			oldest = began
+		}
+	}
+	return oldest, !oldest.IsZero()
+}
+
+// Stalled returns the registered loops with an iteration which didn't finish within the given deadline,
+// sorted by the begin of their oldest unfinished iteration.
+func Stalled(deadline time.Duration) []*Stall {
+	loopsLock.RLock()
+	defer loopsLock.RUnlock()
+
+	var stalls []*Stall
+	for _, loop := range loops {
+		if since, busy := loop.oldestIteration(); busy && time.Since(since) > deadline {
+			stalls = append(stalls, &Stall{Loop: loop.name, Since: since})
+		}
+	}
+
+	sort.Slice(stalls, func(i, j int) bool {
+		return stalls[i].Since.Before(stalls[j].Since)
+	})
+
+	return stalls
+}
+
+// GoroutineStacks returns the stack traces of all goroutines.
+func GoroutineStacks() []byte {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
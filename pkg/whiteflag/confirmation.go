@@ -20,6 +20,9 @@ type ConfirmedMilestoneStats struct {
 	TxsConflicting   int
 	TxsValue         int
 	TxsZeroValue     int
+	TailsReferenced  int
+	ConflictReasons  map[ConflictReason]int
+	WaitingForLedger time.Duration
 	Collecting       time.Duration
 	Total            time.Duration
 }
@@ -47,14 +50,16 @@ func ConfirmMilestone(cachedTxMetas map[string]*tangle.CachedMetadata, cachedMsB
 		cachedBundles[string(cachedMsBundle.GetBundle().GetTailHash())] = cachedMsBundle.Retain()
 	}
 
+	tw := time.Now()
 	tangle.WriteLockLedger()
 	defer tangle.WriteUnlockLedger()
+	waitingForLedger := time.Since(tw)
 
 	milestoneIndex := msBundle.GetMilestoneIndex()
 
 	ts := time.Now()
 
-	mutations, err := ComputeWhiteFlagMutations(cachedTxMetas, cachedBundles, tangle.GetMilestoneMerkleHashFunc(), msBundle.GetTailHash())
+	mutations, err := ComputeWhiteFlagMutations(cachedTxMetas, cachedBundles, tangle.GetMilestoneMerkleHashFunc(), milestoneIndex, msBundle.GetTailHash())
 	if err != nil {
 		// According to the RFC we should panic if we encounter any invalid bundles during confirmation
 		return nil, fmt.Errorf("confirmMilestone: whiteflag.ComputeConfirmation failed with Error: %v", err)
@@ -127,7 +132,9 @@ func ConfirmMilestone(cachedTxMetas map[string]*tangle.CachedMetadata, cachedMsB
 	}
 
 	conf := &ConfirmedMilestoneStats{
-		Index: milestoneIndex,
+		Index:           milestoneIndex,
+		TailsReferenced: len(mutations.TailsReferenced),
+		ConflictReasons: mutations.ConflictReasons,
 	}
 
 	confirmationTime := cachedMsTailTx.GetTransaction().GetTimestamp()
@@ -186,6 +193,7 @@ func ConfirmMilestone(cachedTxMetas map[string]*tangle.CachedMetadata, cachedMsB
 
 	onMilestoneConfirmed(confirmation)
 
+	conf.WaitingForLedger = waitingForLedger
 	conf.Collecting = tc.Sub(ts)
 	conf.Total = time.Since(ts)
 
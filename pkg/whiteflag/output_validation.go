@@ -0,0 +1,72 @@
+package whiteflag
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+)
+
+var (
+	// ErrOutputValidationFailed is returned by an output validator if the outputs of a bundle can't be unlocked.
+	ErrOutputValidationFailed = errors.New("output validation failed")
+	// ErrUnknownProtocolFeature is returned if a protocol feature without registered output validators should be enabled.
+	ErrUnknownProtocolFeature = errors.New("unknown protocol feature")
+)
+
+// OutputValidator checks additional unlock conditions (e.g. timelocks or expirations) of a value bundle
+// before its mutations are applied to the ledger.
+// If an error is returned, the bundle is excluded from the ledger as conflicting.
+type OutputValidator func(bundle *tangle.Bundle, milestoneIndex milestone.Index) error
+
+var (
+	outputValidatorsLock sync.RWMutex
+	// the registered output validators per protocol feature.
+	outputValidators = make(map[string][]OutputValidator)
+	// the output validators of the enabled protocol features.
+	enabledOutputValidators []OutputValidator
+)
+
+// RegisterOutputValidator registers an output validator for the given protocol feature.
+// The validator is only used if the protocol feature was enabled via EnableProtocolFeatures.
+func RegisterOutputValidator(feature string, validator OutputValidator) {
+	outputValidatorsLock.Lock()
+	defer outputValidatorsLock.Unlock()
+
+	outputValidators[feature] = append(outputValidators[feature], validator)
+}
+
+// EnableProtocolFeatures enables the output validators of the given protocol features.
+// All nodes of a network have to enable the same protocol features, otherwise they will compute different ledger states.
+func EnableProtocolFeatures(features ...string) error {
+	outputValidatorsLock.Lock()
+	defer outputValidatorsLock.Unlock()
+
+	var validators []OutputValidator
+	for _, feature := range features {
+		featureValidators, exists := outputValidators[feature]
+		if !exists {
+			return fmt.Errorf("%w: %s", ErrUnknownProtocolFeature, feature)
+		}
+		validators = append(validators, featureValidators...)
+	}
+
+	enabledOutputValidators = validators
+	return nil
+}
+
+// validateOutputs runs all output validators of the enabled protocol features for the given bundle.
+func validateOutputs(bundle *tangle.Bundle, milestoneIndex milestone.Index) error {
+	outputValidatorsLock.RLock()
+	defer outputValidatorsLock.RUnlock()
+
+	for _, validator := range enabledOutputValidators {
+		if err := validator(bundle, milestoneIndex); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package whiteflag
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+)
+
+var (
+	// ErrMilestoneNotFound is returned when the milestone to revalidate is not found in the database.
+	ErrMilestoneNotFound = errors.New("milestone not found")
+)
+
+// LedgerDivergence describes an address whose stored ledger diff doesn't match the recomputed mutation.
+type LedgerDivergence struct {
+	// The address which diverged.
+	Address hornet.Hash
+	// The mutation of the address recomputed under the white-flag rules.
+	Computed int64
+	// The mutation of the address stored in the ledger diff of the milestone.
+	Stored int64
+}
+
+// ConeRevalidation is the result of the revalidation of an already confirmed milestone cone.
+type ConeRevalidation struct {
+	// The index of the revalidated milestone.
+	MilestoneIndex milestone.Index
+	// The recomputed ledger mutations of the milestone cone.
+	Mutations *WhiteFlagMutations
+	// The merkle tree root hash inside the milestone.
+	MilestoneMerkleTreeHash []byte
+	// The addresses whose stored ledger diff diverges from the recomputed mutations, sorted by address.
+	Divergences []*LedgerDivergence
+}
+
+// MerkleTreeHashMatches returns whether the recomputed merkle tree root hash matches the one inside the milestone.
+func (r *ConeRevalidation) MerkleTreeHashMatches() bool {
+	return bytes.Equal(r.Mutations.MerkleTreeHash, r.MilestoneMerkleTreeHash)
+}
+
+// RevalidateMilestoneCone recomputes the white-flag mutations of an already confirmed milestone
+// against the ledger state of the previous milestone and compares them with the stored ledger diff of the milestone.
+// The ledger state of the previous milestone is reconstructed from the stored ledger diffs,
+// so the milestone has to be newer than the pruning index plus one.
+func RevalidateMilestoneCone(msIndex milestone.Index, abortSignal <-chan struct{}) (*ConeRevalidation, error) {
+
+	cachedMs := tangle.GetMilestoneOrNil(msIndex) // bundle +1
+	if cachedMs == nil {
+		return nil, fmt.Errorf("%w: %d", ErrMilestoneNotFound, msIndex)
+	}
+	defer cachedMs.Release(true) // bundle -1
+	msBundle := cachedMs.GetBundle()
+
+	milestoneMerkleTreeHash, err := msBundle.GetMilestoneMerkleTreeHash()
+	if err != nil {
+		return nil, fmt.Errorf("invalid MerkleTreeHash: %w", err)
+	}
+
+	previousBalances, _, err := tangle.GetLedgerStateForMilestone(msIndex-1, abortSignal)
+	if err != nil {
+		return nil, err
+	}
+
+	storedDiff, err := tangle.GetLedgerDiffForMilestone(msIndex, abortSignal)
+	if err != nil {
+		return nil, err
+	}
+
+	cachedTxMetas := make(map[string]*tangle.CachedMetadata)
+	cachedBundles := make(map[string]*tangle.CachedBundle)
+
+	defer func() {
+		// release all tx metadata and bundles at the end
+		for _, cachedTxMeta := range cachedTxMetas {
+			cachedTxMeta.Release(true) // meta -1
+		}
+		for _, cachedBundle := range cachedBundles {
+			cachedBundle.Release(true) // bundle -1
+		}
+	}()
+
+	// only traverse and process the transactions which were confirmed by this milestone (or not confirmed at all),
+	// the cones of the previous milestones are already part of the previous ledger state.
+	traverse := func(txMeta *hornet.TransactionMetadata) bool {
+		confirmed, at := txMeta.GetConfirmed()
+		return !confirmed || at >= msIndex
+	}
+
+	loadBalance := func(addr string) (int64, error) {
+		return int64(previousBalances[addr]), nil
+	}
+
+	mutations, err := computeWhiteFlagMutations(cachedTxMetas, cachedBundles, tangle.GetMilestoneMerkleHashFunc(), msIndex, traverse, loadBalance, msBundle.GetTailHash())
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConeRevalidation{
+		MilestoneIndex:          msIndex,
+		Mutations:               mutations,
+		MilestoneMerkleTreeHash: milestoneMerkleTreeHash,
+		Divergences:             ledgerDivergences(mutations.AddressMutations, storedDiff),
+	}, nil
+}
+
+// returns the addresses whose stored mutation differs from the computed one.
+// addresses missing in one of the diffs are treated as not mutated.
+func ledgerDivergences(computedDiff map[string]int64, storedDiff map[string]int64) []*LedgerDivergence {
+	var divergences []*LedgerDivergence
+
+	for addr, computed := range computedDiff {
+		if stored := storedDiff[addr]; stored != computed {
+			divergences = append(divergences, &LedgerDivergence{Address: hornet.Hash(addr), Computed: computed, Stored: stored})
+		}
+	}
+
+	for addr, stored := range storedDiff {
+		if _, exists := computedDiff[addr]; !exists && stored != 0 {
+			divergences = append(divergences, &LedgerDivergence{Address: hornet.Hash(addr), Computed: 0, Stored: stored})
+		}
+	}
+
+	sort.Slice(divergences, func(i, j int) bool {
+		return bytes.Compare(divergences[i].Address, divergences[j].Address) < 0
+	})
+
+	return divergences
+}
@@ -1,14 +1,19 @@
 package test
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
 	_ "golang.org/x/crypto/blake2b"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/tangle"
 	"github.com/gohornet/hornet/pkg/testsuite"
 	"github.com/gohornet/hornet/pkg/testsuite/utils"
+	"github.com/gohornet/hornet/pkg/whiteflag"
 )
 
 const (
@@ -111,3 +116,98 @@ func TestWhiteFlagWithOnlyZeroTx(t *testing.T) {
 	require.Equal(t, 0, conf.TxsValue)
 	require.Equal(t, 0, conf.TxsConflicting)
 }
+
+func TestWhiteFlagWithOutputValidator(t *testing.T) {
+
+	// Fill up the balances
+	balances := make(map[string]uint64)
+	balances[string(utils.GenerateAddress(t, seed1, 0))] = 1000
+
+	te := testsuite.SetupTestEnvironment(t, balances, 3, showConfirmationGraphs)
+	defer te.CleanupTestEnvironment(!showConfirmationGraphs)
+
+	// bundles with the tag "LOCK" can't be applied before milestone 6
+	whiteflag.RegisterOutputValidator("testTimelock", func(bundle *tangle.Bundle, milestoneIndex milestone.Index) error {
+		cachedTxs := bundle.GetTransactions() // tx +1
+		defer cachedTxs.Release(true)         // tx -1
+
+		for _, cachedTx := range cachedTxs {
+			if strings.HasPrefix(cachedTx.GetTransaction().Tx.Tag, "LOCK") && milestoneIndex < 6 {
+				return whiteflag.ErrOutputValidationFailed
+			}
+		}
+		return nil
+	})
+	require.True(t, errors.Is(whiteflag.EnableProtocolFeatures("unknown"), whiteflag.ErrUnknownProtocolFeature))
+	require.NoError(t, whiteflag.EnableProtocolFeatures("testTimelock"))
+	defer whiteflag.EnableProtocolFeatures()
+
+	// Valid transfer 100 from seed1[0] to seed2[0], but locked until milestone 6
+	bundleA := te.AttachAndStoreBundle(te.Milestones[0].GetBundle().GetTailHash(), te.Milestones[1].GetBundle().GetTailHash(), utils.ValueTx(t, "LOCK", seed1, 0, 1000, seed2, 0, 100))
+
+	// Confirming milestone 5 at bundle A
+	conf := te.IssueAndConfirmMilestoneOnTip(bundleA.GetBundle().GetTailHash(), true)
+	require.Equal(t, 4+3, conf.TxsConfirmed) // 3 are for the milestone itself
+	require.Equal(t, 0, conf.TxsValue)
+	require.Equal(t, 4, conf.TxsConflicting)
+	require.Equal(t, 1, conf.ConflictReasons[whiteflag.ConflictOutputValidationFailed])
+
+	// Verify balances (seed, index, balance)
+	te.AssertAddressBalance(seed1, 0, 1000)
+	te.AssertAddressBalance(seed2, 0, 0)
+
+	// Reattach the transfer, the lock is not active anymore at milestone 6
+	bundleB := te.AttachAndStoreBundle(bundleA.GetBundle().GetTailHash(), te.Milestones[0].GetBundle().GetTailHash(), utils.ValueTx(t, "LOCK", seed1, 0, 1000, seed2, 0, 100))
+
+	// Confirming milestone 6 at bundle B
+	conf = te.IssueAndConfirmMilestoneOnTip(bundleB.GetBundle().GetTailHash(), true)
+	require.Equal(t, 4+3, conf.TxsConfirmed) // 3 are for the milestone itself
+	require.Equal(t, 4, conf.TxsValue)
+	require.Equal(t, 0, conf.TxsConflicting)
+
+	// Verify balances (seed, index, balance)
+	te.AssertAddressBalance(seed1, 0, 0)
+	te.AssertAddressBalance(seed2, 0, 100)
+}
+
+func TestRevalidateMilestoneCone(t *testing.T) {
+
+	// Fill up the balances
+	balances := make(map[string]uint64)
+	balances[string(utils.GenerateAddress(t, seed1, 0))] = 1000
+
+	te := testsuite.SetupTestEnvironment(t, balances, 3, showConfirmationGraphs)
+	defer te.CleanupTestEnvironment(!showConfirmationGraphs)
+
+	// Valid transfer 100 from seed1[0] to seed2[0]
+	bundleA := te.AttachAndStoreBundle(te.Milestones[0].GetBundle().GetTailHash(), te.Milestones[1].GetBundle().GetTailHash(), utils.ValueTx(t, "A", seed1, 0, 1000, seed2, 0, 100))
+	// Invalid transfer 10 from seed3[0] to seed2[0] (insufficient funds)
+	bundleB := te.AttachAndStoreBundle(te.Milestones[2].GetBundle().GetTailHash(), bundleA.GetBundle().GetTailHash(), utils.ValueTx(t, "B", seed3, 0, 99999, seed2, 0, 10))
+
+	te.IssueAndConfirmMilestoneOnTip(bundleB.GetBundle().GetTailHash(), false)
+	msIndexA := tangle.GetSolidMilestoneIndex()
+
+	// Valid transfer 50 from seed2[0] to seed4[0]
+	bundleC := te.AttachAndStoreBundle(bundleB.GetBundle().GetTailHash(), te.Milestones[2].GetBundle().GetTailHash(), utils.ValueTx(t, "C", seed2, 0, 100, seed4, 0, 50))
+
+	te.IssueAndConfirmMilestoneOnTip(bundleC.GetBundle().GetTailHash(), false)
+	msIndexC := tangle.GetSolidMilestoneIndex()
+
+	// the stored ledger diffs of both milestones match the recomputed mutations
+	revalidation, err := whiteflag.RevalidateMilestoneCone(msIndexA, nil)
+	require.NoError(t, err)
+	require.True(t, revalidation.MerkleTreeHashMatches())
+	require.Empty(t, revalidation.Divergences)
+	require.Len(t, revalidation.Mutations.TailsIncluded, 1)
+	require.Len(t, revalidation.Mutations.TailsExcludedConflicting, 1)
+
+	revalidation, err = whiteflag.RevalidateMilestoneCone(msIndexC, nil)
+	require.NoError(t, err)
+	require.True(t, revalidation.MerkleTreeHashMatches())
+	require.Empty(t, revalidation.Divergences)
+	require.Len(t, revalidation.Mutations.TailsIncluded, 1)
+	require.Len(t, revalidation.Mutations.TailsExcludedConflicting, 0)
+
+	_, err = whiteflag.RevalidateMilestoneCone(msIndexC+1, nil)
+	require.True(t, errors.Is(err, whiteflag.ErrMilestoneNotFound))
+}
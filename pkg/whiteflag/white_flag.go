@@ -22,6 +22,18 @@ var (
 	ErrIncludedTailsSumDoesntMatch = errors.New("the sum of the included tails doesn't match the referenced tails minus the excluded tails")
 )
 
+// ConflictReason describes why a bundle was excluded from the ledger as conflicting.
+type ConflictReason string
+
+const (
+	// ConflictInsufficientBalance is used if the bundle spends more funds than available on an address.
+	ConflictInsufficientBalance ConflictReason = "insufficientBalance"
+	// ConflictBalanceOverflow is used if the bundle would exceed the total supply on an address.
+	ConflictBalanceOverflow ConflictReason = "balanceOverflow"
+	// ConflictOutputValidationFailed is used if the bundle didn't pass the output validators of the enabled protocol features.
+	ConflictOutputValidationFailed ConflictReason = "outputValidationFailed"
+)
+
 // Confirmation represents a confirmation done via a milestone under the "white-flag" approach.
 type Confirmation struct {
 	// The index of the milestone that got confirmed.
@@ -42,6 +54,8 @@ type WhiteFlagMutations struct {
 	TailsExcludedZeroValue hornet.Hashes
 	// The tails which were referenced by the milestone (should be the sum of TailsIncluded + TailsExcludedConflicting + TailsExcludedZeroValue).
 	TailsReferenced hornet.Hashes
+	// The amount of conflicting tails per conflict reason.
+	ConflictReasons map[ConflictReason]int
 	// Contains the updated state of the addresses which were mutated by the given confirmation.
 	NewAddressState map[string]int64
 	// Contains the mutations to the state of the addresses for the given confirmation.
@@ -55,16 +69,40 @@ type WhiteFlagMutations struct {
 // in their corresponding order applied/mutated against the previous ledger state, respectively previous applied mutations.
 // Bundles within the approving cone must obey to strict schematics and be valid. Bundles causing conflicts are
 // ignored but do not create an error.
+// Value bundles are additionally checked by the output validators of the enabled protocol features for the given milestone index.
 // It also computes the merkle tree root hash consisting out of the tail transaction hashes
 // of the bundles which are part of the set which mutated the ledger state when applying the white-flag approach.
 // The ledger state must be write locked while this function is getting called in order to ensure consistency.
 // all cachedTxMetas and cachedBundles have to be released outside.
-func ComputeWhiteFlagMutations(cachedTxMetas map[string]*tangle.CachedMetadata, cachedBundles map[string]*tangle.CachedBundle, merkleTreeHashFunc crypto.Hash, trunkHash hornet.Hash, branchHash ...hornet.Hash) (*WhiteFlagMutations, error) {
+func ComputeWhiteFlagMutations(cachedTxMetas map[string]*tangle.CachedMetadata, cachedBundles map[string]*tangle.CachedBundle, merkleTreeHashFunc crypto.Hash, milestoneIndex milestone.Index, trunkHash hornet.Hash, branchHash ...hornet.Hash) (*WhiteFlagMutations, error) {
+
+	// only traverse and process the transactions which were not confirmed yet
+	traverse := func(txMeta *hornet.TransactionMetadata) bool {
+		return !txMeta.IsConfirmed()
+	}
+
+	// load the state of the addresses from the previous milestone
+	loadBalance := func(addr string) (int64, error) {
+		balance, _, err := tangle.GetBalanceForAddressWithoutLocking(hornet.Hash(addr))
+		if err != nil {
+			return 0, err
+		}
+		return int64(balance), nil
+	}
+
+	return computeWhiteFlagMutations(cachedTxMetas, cachedBundles, merkleTreeHashFunc, milestoneIndex, traverse, loadBalance, trunkHash, branchHash...)
+}
+
+// computeWhiteFlagMutations computes the ledger changes in accordance to the white-flag rules for the cone referenced by trunk and branch.
+// The transactions of the cone are only traversed if they pass the given traverse function,
+// and the state of the addresses before the confirmation is retrieved via the given loadBalance function.
+func computeWhiteFlagMutations(cachedTxMetas map[string]*tangle.CachedMetadata, cachedBundles map[string]*tangle.CachedBundle, merkleTreeHashFunc crypto.Hash, milestoneIndex milestone.Index, traverse func(txMeta *hornet.TransactionMetadata) bool, loadBalance func(addr string) (int64, error), trunkHash hornet.Hash, branchHash ...hornet.Hash) (*WhiteFlagMutations, error) {
 	wfConf := &WhiteFlagMutations{
 		TailsIncluded:            make(hornet.Hashes, 0),
 		TailsExcludedConflicting: make(hornet.Hashes, 0),
 		TailsExcludedZeroValue:   make(hornet.Hashes, 0),
 		TailsReferenced:          make(hornet.Hashes, 0),
+		ConflictReasons:          make(map[ConflictReason]int),
 		NewAddressState:          make(map[string]int64),
 		AddressMutations:         make(map[string]int64),
 	}
@@ -99,8 +137,7 @@ func ComputeWhiteFlagMutations(cachedTxMetas map[string]*tangle.CachedMetadata,
 			return false, fmt.Errorf("%w: bundle %s is invalid", ErrMilestoneApprovedInvalidBundle, cachedBundle.GetBundle().GetBundleHash().Trytes())
 		}
 
-		// only traverse and process the transaction if it was not confirmed yet
-		return !cachedTxMeta.GetMetadata().IsConfirmed(), nil
+		return traverse(cachedTxMeta.GetMetadata()), nil
 	}
 
 	// consumer
@@ -123,7 +160,7 @@ func ComputeWhiteFlagMutations(cachedTxMetas map[string]*tangle.CachedMetadata,
 			return nil
 		}
 
-		var conflicting bool
+		var conflictReason ConflictReason
 
 		// contains the updated mutations from this bundle against the
 		// current mutations of the milestone's confirming cone (or previous ledger state).
@@ -137,11 +174,11 @@ func ComputeWhiteFlagMutations(cachedTxMetas map[string]*tangle.CachedMetadata,
 			// load state from milestone cone mutation or previous milestone
 			balance, has := wfConf.NewAddressState[addr]
 			if !has {
-				balanceStateFromPreviousMilestone, _, err := tangle.GetBalanceForAddressWithoutLocking(hornet.Hash(addr))
+				balanceStateFromPreviousMilestone, err := loadBalance(addr)
 				if err != nil {
 					return fmt.Errorf("%w: unable to retrieve balance of address %s", err, addr)
 				}
-				balance = int64(balanceStateFromPreviousMilestone)
+				balance = balanceStateFromPreviousMilestone
 			}
 
 			// note that there's no overflow of int64 values here
@@ -150,8 +187,12 @@ func ComputeWhiteFlagMutations(cachedTxMetas map[string]*tangle.CachedMetadata,
 			newBalance := balance + change
 
 			// on below zero or above total supply the mutation is invalid
-			if newBalance < 0 || math.AbsInt64(newBalance) > consts.TotalSupply {
-				conflicting = true
+			if newBalance < 0 {
+				conflictReason = ConflictInsufficientBalance
+				break
+			}
+			if math.AbsInt64(newBalance) > consts.TotalSupply {
+				conflictReason = ConflictBalanceOverflow
 				break
 			}
 
@@ -159,9 +200,15 @@ func ComputeWhiteFlagMutations(cachedTxMetas map[string]*tangle.CachedMetadata,
 			validMutations[addr] = validMutations[addr] + change
 		}
 
+		// bundles which don't pass the additional unlock conditions of the enabled protocol features are conflicting as well
+		if conflictReason == "" && validateOutputs(bundle, milestoneIndex) != nil {
+			conflictReason = ConflictOutputValidationFailed
+		}
+
 		wfConf.TailsReferenced = append(wfConf.TailsReferenced, cachedTxMeta.GetMetadata().GetTxHash())
 
-		if conflicting {
+		if conflictReason != "" {
+			wfConf.ConflictReasons[conflictReason]++
 			wfConf.TailsExcludedConflicting = append(wfConf.TailsExcludedConflicting, cachedTxMeta.GetMetadata().GetTxHash())
 			return nil
 		}
@@ -0,0 +1,253 @@
+package autopeering
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/mr-tron/base58/base58"
+
+	"github.com/iotaledger/hive.go/crypto/ed25519"
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/kvstore/bolt"
+	"github.com/iotaledger/hive.go/logger"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/keystore"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+)
+
+const (
+	// the environment variable holding the passphrase of the identity keystore,
+	// used if no passphrase file is configured.
+	identityPassphraseEnvironmentVariable = "IDENTITY_PASSPHRASE"
+
+	// the key under which the autopeering database stores the private key of the node in plaintext.
+	plaintextIdentityKey = "local:key"
+)
+
+var (
+	// ErrIdentityKeystoreDisabled is returned when the identity is exported or rotated without a configured keystore.
+	ErrIdentityKeystoreDisabled = errors.New("identity keystore is disabled")
+
+	identityOnce sync.Once
+	identitySeed []byte
+	identityLog  *logger.Logger
+
+	// ensures that the keystore isn't rotated concurrently.
+	identityLock sync.Mutex
+)
+
+// IdentitySeed returns the private key seed of the node identity.
+// If a keystore is configured, the seed is decrypted from it. If the keystore doesn't exist yet,
+// the identity is migrated from the configured seed or the autopeering database into a new keystore.
+// Returns nil if no keystore and no seed are configured, the autopeering uses the key of its database then.
+func IdentitySeed() []byte {
+	identityOnce.Do(func() {
+		identityLog = logger.NewLogger("Identity")
+		identitySeed = loadIdentitySeed()
+	})
+	return identitySeed
+}
+
+// identityKeystoreEnabled tells whether the node identity is stored in an encrypted keystore.
+func identityKeystoreEnabled() bool {
+	return config.NodeConfig.GetString(config.CfgNetAutopeeringIdentityFilePath) != ""
+}
+
+func loadIdentitySeed() []byte {
+	configSeed := configuredSeed()
+
+	if !identityKeystoreEnabled() {
+		return configSeed
+	}
+
+	path := config.NodeConfig.GetString(config.CfgNetAutopeeringIdentityFilePath)
+	passphrase, err := identityPassphrase()
+	if err != nil {
+		identityLog.Fatalf("loading the identity keystore failed: %s", err)
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		seed, err := keystore.Load(path, passphrase)
+		if err != nil {
+			identityLog.Fatalf("loading the identity keystore %s failed: %s", path, err)
+		}
+		if len(seed) != ed25519.SeedSize {
+			identityLog.Fatalf("the identity keystore %s holds an invalid seed", path)
+		}
+		if configSeed != nil {
+			identityLog.Warnf("%s is ignored since the identity is loaded from the keystore, remove it from the config", config.CfgNetAutopeeringSeed)
+		}
+		return seed
+	} else if !os.IsNotExist(err) {
+		identityLog.Fatalf("loading the identity keystore %s failed: %s", path, err)
+	}
+
+	// migrate the plaintext identity into the keystore
+	seed := configSeed
+	switch {
+	case seed != nil:
+		identityLog.Infof("migrating the identity of %s into the keystore %s", config.CfgNetAutopeeringSeed, path)
+	default:
+		seed, err = databaseSeed()
+		if err != nil {
+			identityLog.Fatalf("migrating the identity of the autopeering database failed: %s", err)
+		}
+		if seed != nil {
+			identityLog.Infof("migrating the identity of the autopeering database into the keystore %s", path)
+			break
+		}
+
+		seed = make([]byte, ed25519.SeedSize)
+		if _, err := rand.Read(seed); err != nil {
+			identityLog.Fatalf("generating the identity failed: %s", err)
+		}
+		identityLog.Infof("storing a new identity in the keystore %s", path)
+	}
+
+	if err := keystore.Store(path, seed, passphrase); err != nil {
+		identityLog.Fatalf("storing the identity keystore %s failed: %s", path, err)
+	}
+	if configSeed != nil {
+		identityLog.Warnf("the identity was migrated into the keystore, remove %s from the config", config.CfgNetAutopeeringSeed)
+	}
+
+	return seed
+}
+
+// returns the seed configured in plaintext, or nil if none is configured.
+func configuredSeed() []byte {
+	str := config.NodeConfig.GetString(config.CfgNetAutopeeringSeed)
+	if str == "" {
+		return nil
+	}
+
+	seed, err := base58.Decode(str)
+	if err != nil {
+		identityLog.Fatalf("Invalid %s: %s", config.CfgNetAutopeeringSeed, err)
+	}
+	if l := len(seed); l != ed25519.SeedSize {
+		identityLog.Fatalf("Invalid %s length: %d, need %d", config.CfgNetAutopeeringSeed, l, ed25519.SeedSize)
+	}
+	return seed
+}
+
+// returns the passphrase of the identity keystore from the configured passphrase file or the environment.
+func identityPassphrase() ([]byte, error) {
+	if path := config.NodeConfig.GetString(config.CfgNetAutopeeringIdentityPassphraseFilePath); path != "" {
+		passphrase, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		passphrase = bytes.TrimRight(passphrase, "\r\n")
+		if len(passphrase) == 0 {
+			return nil, fmt.Errorf("passphrase file %s is empty", path)
+		}
+		return passphrase, nil
+	}
+
+	passphrase, exists := os.LookupEnv(identityPassphraseEnvironmentVariable)
+	if !exists || len(passphrase) == 0 {
+		return nil, fmt.Errorf("neither %s nor the environment variable '%s' is set", config.CfgNetAutopeeringIdentityPassphraseFilePath, identityPassphraseEnvironmentVariable)
+	}
+	return []byte(passphrase), nil
+}
+
+// returns the seed of the private key stored in plaintext in the autopeering database, or nil if none is stored.
+func databaseSeed() ([]byte, error) {
+	boltDb, err := bolt.CreateDB(config.NodeConfig.GetString(config.CfgDatabasePath), "peer.db")
+	if err != nil {
+		return nil, err
+	}
+	defer boltDb.Close()
+
+	store := bolt.New(boltDb).WithRealm([]byte{tangle.StorePrefixAutopeering})
+	value, err := store.Get([]byte(plaintextIdentityKey))
+	if err != nil {
+		if errors.Is(err, kvstore.ErrKeyNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	key, err, _ := ed25519.PrivateKeyFromBytes(value)
+	if err != nil {
+		return nil, err
+	}
+	return key.Seed().Bytes(), nil
+}
+
+// removes the private key the autopeering stores in plaintext in its database,
+// since the identity is loaded from the keystore.
+func removePlaintextIdentity(store kvstore.KVStore) error {
+	return store.Delete([]byte(plaintextIdentityKey))
+}
+
+// ExportIdentity returns the encrypted keystore holding the node identity and the public key of the identity.
+// After a rotation, the new identity is exported although the node still uses the previous one until a restart.
+func ExportIdentity() (ed25519.PublicKey, []byte, error) {
+	if !identityKeystoreEnabled() {
+		return ed25519.PublicKey{}, nil, ErrIdentityKeystoreDisabled
+	}
+
+	identityLock.Lock()
+	defer identityLock.Unlock()
+
+	passphrase, err := identityPassphrase()
+	if err != nil {
+		return ed25519.PublicKey{}, nil, err
+	}
+
+	data, err := ioutil.ReadFile(config.NodeConfig.GetString(config.CfgNetAutopeeringIdentityFilePath))
+	if err != nil {
+		return ed25519.PublicKey{}, nil, err
+	}
+
+	seed, err := keystore.Decrypt(data, passphrase)
+	if err != nil {
+		return ed25519.PublicKey{}, nil, err
+	}
+
+	return ed25519.PrivateKeyFromSeed(seed).Public(), data, nil
+}
+
+// RotateIdentity replaces the node identity in the keystore with a new one and returns its public key.
+// The previous keystore is kept with the suffix ".bak". The new identity is used after a restart of the node.
+func RotateIdentity() (ed25519.PublicKey, error) {
+	if !identityKeystoreEnabled() {
+		return ed25519.PublicKey{}, ErrIdentityKeystoreDisabled
+	}
+
+	identityLock.Lock()
+	defer identityLock.Unlock()
+
+	path := config.NodeConfig.GetString(config.CfgNetAutopeeringIdentityFilePath)
+	passphrase, err := identityPassphrase()
+	if err != nil {
+		return ed25519.PublicKey{}, err
+	}
+
+	seed := make([]byte, ed25519.SeedSize)
+	if _, err := rand.Read(seed); err != nil {
+		return ed25519.PublicKey{}, err
+	}
+
+	previous, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ed25519.PublicKey{}, err
+	}
+	if err := ioutil.WriteFile(path+".bak", previous, 0600); err != nil {
+		return ed25519.PublicKey{}, err
+	}
+
+	if err := keystore.Store(path, seed, passphrase); err != nil {
+		return ed25519.PublicKey{}, err
+	}
+
+	return ed25519.PrivateKeyFromSeed(seed).Public(), nil
+}
@@ -4,12 +4,10 @@ import (
 	"net"
 	"strconv"
 
-	"github.com/mr-tron/base58/base58"
 	"go.etcd.io/bbolt"
 
 	"github.com/iotaledger/hive.go/autopeering/peer"
 	"github.com/iotaledger/hive.go/autopeering/peer/service"
-	"github.com/iotaledger/hive.go/crypto/ed25519"
 	"github.com/iotaledger/hive.go/kvstore/bolt"
 	"github.com/iotaledger/hive.go/logger"
 
@@ -64,17 +62,10 @@ func newLocal() *Local {
 		ownServices.Update(services.GossipServiceKey(), "tcp", gossipBindAddrPort)
 	}
 
-	// set the private key from the seed provided in the config
+	// set the private key from the identity keystore or the seed provided in the config
 	var seed [][]byte
-	if str := config.NodeConfig.GetString(config.CfgNetAutopeeringSeed); str != "" {
-		bytes, err := base58.Decode(str)
-		if err != nil {
-			log.Fatalf("Invalid %s: %s", config.CfgNetAutopeeringSeed, err)
-		}
-		if l := len(bytes); l != ed25519.SeedSize {
-			log.Fatalf("Invalid %s length: %d, need %d", config.CfgNetAutopeeringSeed, l, ed25519.SeedSize)
-		}
-		seed = append(seed, bytes)
+	if identitySeed := IdentitySeed(); identitySeed != nil {
+		seed = append(seed, identitySeed)
 	}
 
 	boltDb, err := bolt.CreateDB(config.NodeConfig.GetString(config.CfgDatabasePath), "peer.db")
@@ -82,7 +73,8 @@ func newLocal() *Local {
 		log.Fatalf("Unable to create autopeering database: %s", err)
 	}
 
-	peerDB, err := peer.NewDB(bolt.New(boltDb).WithRealm([]byte{tangle.StorePrefixAutopeering}))
+	peerStore := bolt.New(boltDb).WithRealm([]byte{tangle.StorePrefixAutopeering})
+	peerDB, err := peer.NewDB(peerStore)
 	if err != nil {
 		log.Fatalf("Unable to create autopeering database: %s", err)
 	}
@@ -92,6 +84,13 @@ func newLocal() *Local {
 		log.Fatalf("Error creating local: %s", err)
 	}
 
+	// the autopeering stores the private key in plaintext, which is not needed if it is loaded from the keystore
+	if identityKeystoreEnabled() {
+		if err := removePlaintextIdentity(peerStore); err != nil {
+			log.Fatalf("Unable to remove the plaintext identity from the autopeering database: %s", err)
+		}
+	}
+
 	log.Infof("Initialized local: peer://%s@%s", local.PublicKey().String(), local.Address())
 
 	return &Local{
@@ -0,0 +1,72 @@
+package autopeering
+
+import (
+	"go.uber.org/atomic"
+
+	"github.com/iotaledger/hive.go/autopeering/peer"
+)
+
+var (
+	// whether candidates received via the peer exchange are currently verified.
+	verifyingCandidates atomic.Bool
+)
+
+// ExchangeablePeers returns up to max serialized peers which were verified by the discovery,
+// so that they can be shared with other nodes via the peer exchange.
+func ExchangeablePeers(max int) [][]byte {
+	if discoveryProtocol == nil {
+		return nil
+	}
+
+	var peers [][]byte
+	for _, p := range discoveryProtocol.GetVerifiedPeers() {
+		if len(peers) == max {
+			break
+		}
+
+		data, err := p.Marshal()
+		if err != nil {
+			continue
+		}
+		peers = append(peers, data)
+	}
+	return peers
+}
+
+// AddPeerCandidates adds the given serialized peers received via the peer exchange to the candidates of the discovery.
+// The candidates are pinged and only added once they responded, so that unreachable or forged peers are not selected.
+// Candidates received while the previous ones are still verified are dropped.
+// Returns the amount of candidates which are verified.
+func AddPeerCandidates(data [][]byte) int {
+	if discoveryProtocol == nil || local == nil {
+		return 0
+	}
+
+	var candidates []*peer.Peer
+	for _, d := range data {
+		p, err := peer.Unmarshal(d)
+		if err != nil {
+			continue
+		}
+		if p.ID() == local.PeerLocal.ID() || discoveryProtocol.IsVerified(p.ID(), p.IP()) {
+			continue
+		}
+		candidates = append(candidates, p)
+	}
+
+	if len(candidates) == 0 || !verifyingCandidates.CAS(false, true) {
+		return 0
+	}
+
+	go func() {
+		defer verifyingCandidates.Store(false)
+
+		for _, p := range candidates {
+			if err := discoveryProtocol.Ping(p); err != nil {
+				log.Debugf("verifying exchanged peer %s failed: %s", p.ID(), err)
+			}
+		}
+	}()
+
+	return len(candidates)
+}
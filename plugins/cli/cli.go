@@ -21,6 +21,7 @@ var (
 	version  = flag.BoolP("version", "v", false, "Prints the HORNET version")
 	help     = flag.BoolP("help", "h", false, "Prints the HORNET help (--full for all parameters)")
 	helpFull = flag.Bool("full", false, "Prints full HORNET help (only in combination with -h)")
+	safeMode = flag.Bool("safe-mode", false, "Starts HORNET with the database and a read-only API only (no gossip, no pruning, no snapshots)")
 )
 
 func AddPluginStatus(name string, status int) {
@@ -72,6 +73,12 @@ func ParseFlags() {
 	config.ParseFlags()
 }
 
+// IsSafeMode returns whether HORNET was started in safe mode.
+// In safe mode only the database and a read-only API are started, to inspect or back up a node without modifying its state.
+func IsSafeMode() bool {
+	return *safeMode
+}
+
 // PrintVersion prints out the HORNET version
 func PrintVersion() {
 	if *version {
@@ -7,15 +7,13 @@ import (
 
 	"github.com/tcnksm/go-latest"
 
-	"github.com/iotaledger/hive.go/daemon"
 	"github.com/iotaledger/hive.go/events"
 	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/hive.go/node"
-	"github.com/iotaledger/hive.go/timeutil"
 
 	"github.com/gohornet/hornet/pkg/config"
 	"github.com/gohornet/hornet/pkg/profile"
-	"github.com/gohornet/hornet/pkg/shutdown"
+	"github.com/gohornet/hornet/pkg/scheduler"
 )
 
 var (
@@ -76,7 +74,7 @@ func configure(plugin *node.Plugin) {
                                    v%s
 `+"\n\n", AppVersion)
 
-	checkLatestVersion()
+	_ = checkLatestVersion(nil)
 
 	if config.NodeConfig.GetString(config.CfgProfileUseProfile) == config.AutoProfileName {
 		log.Infof("Profile mode 'auto', Using profile '%s'", profile.LoadProfile().Name)
@@ -84,6 +82,10 @@ func configure(plugin *node.Plugin) {
 		log.Infof("Using profile '%s'", profile.LoadProfile().Name)
 	}
 
+	if IsSafeMode() {
+		log.Warn("Safe mode enabled, only the database and a read-only API are started")
+	}
+
 	log.Info("Loading plugins ...")
 }
 
@@ -108,24 +110,25 @@ func includeVersionInCheck(version string) bool {
 	return !isPrerelease(version)
 }
 
-func checkLatestVersion() {
+func checkLatestVersion(_ <-chan struct{}) error {
 
 	res, err := latest.Check(githubTag, fixVersion(AppVersion))
 	if err != nil {
 		log.Warnf("Update check failed: %s", err.Error())
-		return
+		return err
 	}
 
 	if res.Outdated {
 		log.Infof("Update to %s available on https://github.com/gohornet/hornet/releases/latest", res.Current)
 		LatestGithubVersion = res.Current
 	}
+	return nil
 }
 
 func run(_ *node.Plugin) {
 
-	// create a background worker that checks for latest version every hour
-	daemon.BackgroundWorker("Version update checker", func(shutdownSignal <-chan struct{}) {
-		timeutil.Ticker(checkLatestVersion, 1*time.Hour, shutdownSignal)
-	}, shutdown.PriorityUpdateCheck)
+	// check for the latest version every hour
+	if err := scheduler.Schedule("Version update check", 1*time.Hour, checkLatestVersion); err != nil {
+		log.Panic(err)
+	}
 }
@@ -4,12 +4,12 @@ import (
 	"encoding/json"
 	"time"
 
-	"github.com/iotaledger/hive.go/daemon"
-	"github.com/iotaledger/hive.go/events"
-	"github.com/iotaledger/hive.go/timeutil"
+	"github.com/iotaledger/hive.go/node"
 
 	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/scheduler"
 	"github.com/gohornet/hornet/pkg/shutdown"
+	"github.com/gohornet/hornet/pkg/supervisor"
 	"github.com/gohornet/hornet/plugins/database"
 )
 
@@ -57,23 +57,27 @@ func currentDatabaseSize() *DBSizeMetric {
 	return newValue
 }
 
-func runDatabaseSizeCollector() {
+func runDatabaseSizeCollector(plugin *node.Plugin) {
 
 	// Gather first metric so we have a starting point
 	currentDatabaseSize()
 
-	onDatabaseCleanup := events.NewClosure(func(cleanup *database.DatabaseCleanup) {
+	onDatabaseCleanup := supervisor.NewClosure(plugin.Name, "Dashboard[onDatabaseCleanup]", func(cleanup *database.DatabaseCleanup) {
 		lastDbCleanup = cleanup
 		hub.BroadcastMsg(&Msg{Type: MsgTypeDatabaseCleanupEvent, Data: cleanup})
 	})
 
-	daemon.BackgroundWorker("Dashboard[DBSize]", func(shutdownSignal <-chan struct{}) {
+	supervisor.BackgroundWorker(plugin.Name, "Dashboard[DBSize]", func(shutdownSignal <-chan struct{}) {
 		database.Events.DatabaseCleanup.Attach(onDatabaseCleanup)
 		defer database.Events.DatabaseCleanup.Detach(onDatabaseCleanup)
-
-		timeutil.Ticker(func() {
-			dbSizeMetric := currentDatabaseSize()
-			hub.BroadcastMsg(&Msg{Type: MsgTypeDatabaseSizeMetric, Data: []*DBSizeMetric{dbSizeMetric}})
-		}, 1*time.Minute, shutdownSignal)
+		<-shutdownSignal
 	}, shutdown.PriorityDashboard)
+
+	if err := scheduler.Schedule("Dashboard database size", 1*time.Minute, func(_ <-chan struct{}) error {
+		dbSizeMetric := currentDatabaseSize()
+		hub.BroadcastMsg(&Msg{Type: MsgTypeDatabaseSizeMetric, Data: []*DBSizeMetric{dbSizeMetric}})
+		return nil
+	}); err != nil {
+		log.Panic(err)
+	}
 }
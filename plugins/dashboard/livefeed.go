@@ -3,22 +3,22 @@ package dashboard
 import (
 	"time"
 
-	"github.com/iotaledger/hive.go/daemon"
-	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/node"
 
 	"github.com/gohornet/hornet/pkg/model/hornet"
 	"github.com/gohornet/hornet/pkg/model/milestone"
 	tanglemodel "github.com/gohornet/hornet/pkg/model/tangle"
 	"github.com/gohornet/hornet/pkg/shutdown"
+	"github.com/gohornet/hornet/pkg/supervisor"
 	"github.com/gohornet/hornet/plugins/tangle"
 )
 
-func runLiveFeed() {
+func runLiveFeed(plugin *node.Plugin) {
 
 	newTxZeroValueRateLimiter := time.NewTicker(time.Second / 10)
 	newTxValueRateLimiter := time.NewTicker(time.Second / 20)
 
-	onReceivedNewTransaction := events.NewClosure(func(cachedTx *tanglemodel.CachedTransaction, latestMilestoneIndex milestone.Index, latestSolidMilestoneIndex milestone.Index) {
+	onReceivedNewTransaction := supervisor.NewClosure(plugin.Name, "Dashboard[onReceivedNewTransaction]", func(cachedTx *tanglemodel.CachedTransaction, latestMilestoneIndex milestone.Index, latestSolidMilestoneIndex milestone.Index) {
 		cachedTx.ConsumeTransaction(func(tx *hornet.Transaction) {
 			if !tanglemodel.IsNodeSyncedWithThreshold() {
 				return
@@ -40,13 +40,13 @@ func runLiveFeed() {
 		})
 	})
 
-	onLatestMilestoneIndexChanged := events.NewClosure(func(msIndex milestone.Index) {
+	onLatestMilestoneIndexChanged := supervisor.NewClosure(plugin.Name, "Dashboard[onLatestMilestoneIndexChanged]", func(msIndex milestone.Index) {
 		if msTailTxHash := getMilestoneTailHash(msIndex); msTailTxHash != nil {
 			hub.BroadcastMsg(&Msg{Type: MsgTypeMs, Data: &LivefeedMilestone{Hash: msTailTxHash.Trytes(), Index: msIndex}})
 		}
 	})
 
-	daemon.BackgroundWorker("Dashboard[TxUpdater]", func(shutdownSignal <-chan struct{}) {
+	supervisor.BackgroundWorker(plugin.Name, "Dashboard[TxUpdater]", func(shutdownSignal <-chan struct{}) {
 		tangle.Events.ReceivedNewTransaction.Attach(onReceivedNewTransaction)
 		defer tangle.Events.ReceivedNewTransaction.Detach(onReceivedNewTransaction)
 		tangle.Events.LatestMilestoneIndexChanged.Attach(onLatestMilestoneIndexChanged)
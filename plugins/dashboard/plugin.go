@@ -9,8 +9,6 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 
-	"github.com/iotaledger/hive.go/daemon"
-	"github.com/iotaledger/hive.go/events"
 	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/hive.go/node"
 	"github.com/iotaledger/hive.go/websockethub"
@@ -24,6 +22,7 @@ import (
 	"github.com/gohornet/hornet/pkg/peering/peer"
 	"github.com/gohornet/hornet/pkg/protocol/sting"
 	"github.com/gohornet/hornet/pkg/shutdown"
+	"github.com/gohornet/hornet/pkg/supervisor"
 	"github.com/gohornet/hornet/plugins/autopeering"
 	"github.com/gohornet/hornet/plugins/cli"
 	"github.com/gohornet/hornet/plugins/gossip"
@@ -69,6 +68,10 @@ const (
 	MsgTypeSpamMetrics
 	// MsgTypeAvgSpamMetrics is the type of the AvgSpamMetric message.
 	MsgTypeAvgSpamMetrics
+	// MsgTypePruningProgress is the type of the progress message of the pruning.
+	MsgTypePruningProgress
+	// MsgTypeSnapshotProgress is the type of the progress message of the snapshot creation.
+	MsgTypeSnapshotProgress
 )
 
 const (
@@ -102,7 +105,7 @@ func configure(plugin *node.Plugin) {
 	hub = websockethub.NewHub(log, upgrader, broadcastQueueSize, clientSendChannelSize)
 }
 
-func run(_ *node.Plugin) {
+func run(plugin *node.Plugin) {
 
 	e := echo.New()
 	e.HideBanner = true
@@ -136,21 +139,21 @@ func run(_ *node.Plugin) {
 	log.Infof("You can now access the dashboard using: http://%s", bindAddr)
 	go e.Start(bindAddr)
 
-	onTPSMetricsUpdated := events.NewClosure(func(tpsMetrics *metricsplugin.TPSMetrics) {
+	onTPSMetricsUpdated := supervisor.NewClosure(plugin.Name, "Dashboard[onTPSMetricsUpdated]", func(tpsMetrics *metricsplugin.TPSMetrics) {
 		hub.BroadcastMsg(&Msg{Type: MsgTypeTPSMetric, Data: tpsMetrics})
 		hub.BroadcastMsg(&Msg{Type: MsgTypeNodeStatus, Data: currentNodeStatus()})
 		hub.BroadcastMsg(&Msg{Type: MsgTypePeerMetric, Data: peerMetrics()})
 	})
 
-	onSolidMilestoneIndexChanged := events.NewClosure(func(msIndex milestone.Index) {
+	onSolidMilestoneIndexChanged := supervisor.NewClosure(plugin.Name, "Dashboard[onSolidMilestoneIndexChanged]", func(msIndex milestone.Index) {
 		hub.BroadcastMsg(&Msg{Type: MsgTypeSyncStatus, Data: currentSyncStatus()})
 	})
 
-	onLatestMilestoneIndexChanged := events.NewClosure(func(msIndex milestone.Index) {
+	onLatestMilestoneIndexChanged := supervisor.NewClosure(plugin.Name, "Dashboard[onLatestMilestoneIndexChanged]", func(msIndex milestone.Index) {
 		hub.BroadcastMsg(&Msg{Type: MsgTypeSyncStatus, Data: currentSyncStatus()})
 	})
 
-	onNewConfirmedMilestoneMetric := events.NewClosure(func(metric *tangleplugin.ConfirmedMilestoneMetric) {
+	onNewConfirmedMilestoneMetric := supervisor.NewClosure(plugin.Name, "Dashboard[onNewConfirmedMilestoneMetric]", func(metric *tangleplugin.ConfirmedMilestoneMetric) {
 		cachedMilestoneMetrics = append(cachedMilestoneMetrics, metric)
 		if len(cachedMilestoneMetrics) > 20 {
 			cachedMilestoneMetrics = cachedMilestoneMetrics[len(cachedMilestoneMetrics)-20:]
@@ -158,7 +161,7 @@ func run(_ *node.Plugin) {
 		hub.BroadcastMsg(&Msg{Type: MsgTypeConfirmedMsMetrics, Data: []*tangleplugin.ConfirmedMilestoneMetric{metric}})
 	})
 
-	daemon.BackgroundWorker("Dashboard[WSSend]", func(shutdownSignal <-chan struct{}) {
+	supervisor.BackgroundWorker(plugin.Name, "Dashboard[WSSend]", func(shutdownSignal <-chan struct{}) {
 		go hub.Run(shutdownSignal)
 		metricsplugin.Events.TPSMetricsUpdated.Attach(onTPSMetricsUpdated)
 		tangleplugin.Events.SolidMilestoneIndexChanged.Attach(onSolidMilestoneIndexChanged)
@@ -175,15 +178,17 @@ func run(_ *node.Plugin) {
 	}, shutdown.PriorityDashboard)
 
 	// run the message live feed
-	runLiveFeed()
+	runLiveFeed(plugin)
 	// run the visualizer transaction feed
-	runVisualizer()
+	runVisualizer(plugin)
 	// run the tipselection feed
-	runTipSelMetricWorker()
+	runTipSelMetricWorker(plugin)
 	// run the database size collector
-	runDatabaseSizeCollector()
+	runDatabaseSizeCollector(plugin)
 	// run the spammer feed
-	runSpammerMetricWorker()
+	runSpammerMetricWorker(plugin)
+	// run the pruning and snapshot progress feed
+	runSnapshotProgressFeed(plugin)
 }
 
 func getMilestoneTailHash(index milestone.Index) hornet.Hash {
@@ -239,6 +244,7 @@ type NodeStatus struct {
 	ServerMetrics          *ServerMetrics  `json:"server_metrics"`
 	Mem                    *MemMetrics     `json:"mem"`
 	Caches                 *CachesMetric   `json:"caches"`
+	Gossip                 *GossipMetrics  `json:"gossip"`
 }
 
 // ServerMetrics are global metrics of the server.
@@ -262,6 +268,17 @@ type ServerMetrics struct {
 	NumberOfSeenSpentAddr          uint32 `json:"spent_addr"`
 }
 
+// GossipMetrics are the aggregated metrics of the streams to the connected peers.
+type GossipMetrics struct {
+	Streams          int     `json:"streams"`
+	BytesIn          uint64  `json:"bytes_in"`
+	BytesOut         uint64  `json:"bytes_out"`
+	MessagesIn       uint64  `json:"messages_in"`
+	MessagesOut      uint64  `json:"messages_out"`
+	SendQueueLength  int     `json:"send_queue_length"`
+	MaxSendQueueFill float64 `json:"max_send_queue_fill"`
+}
+
 // MemMetrics represents memory metrics.
 type MemMetrics struct {
 	Sys          uint64 `json:"sys"`
@@ -413,6 +430,18 @@ func currentNodeStatus() *NodeStatus {
 		NumberOfSeenSpentAddr:          metrics.SharedServerMetrics.SeenSpentAddresses.Load(),
 	}
 
+	// gossip metrics
+	gossipStats := peering.Manager().Stats()
+	status.Gossip = &GossipMetrics{
+		Streams:          gossipStats.Streams,
+		BytesIn:          gossipStats.BytesIn,
+		BytesOut:         gossipStats.BytesOut,
+		MessagesIn:       gossipStats.MessagesIn,
+		MessagesOut:      gossipStats.MessagesOut,
+		SendQueueLength:  gossipStats.SendQueueLength,
+		MaxSendQueueFill: gossipStats.MaxSendQueueFill,
+	}
+
 	// memory metrics
 	status.Mem = &MemMetrics{
 		Sys:          m.Sys,
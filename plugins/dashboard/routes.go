@@ -14,6 +14,7 @@ import (
 
 	"github.com/gohornet/hornet/pkg/config"
 	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/plugins/snapshot"
 )
 
 const (
@@ -165,6 +166,16 @@ func websocketRoute(ctx echo.Context) error {
 		case MsgTypeDatabaseCleanupEvent:
 			client.Send(&Msg{Type: MsgTypeDatabaseCleanupEvent, Data: lastDbCleanup})
 
+		case MsgTypePruningProgress:
+			if progress := snapshot.GetPruningProgress(); progress != nil {
+				client.Send(&Msg{Type: MsgTypePruningProgress, Data: progress})
+			}
+
+		case MsgTypeSnapshotProgress:
+			if progress := snapshot.GetSnapshotProgress(); progress != nil {
+				client.Send(&Msg{Type: MsgTypeSnapshotProgress, Data: progress})
+			}
+
 		case MsgTypeMs:
 			start := tangle.GetLatestMilestoneIndex()
 			for i := start - 10; i <= start; i++ {
@@ -0,0 +1,30 @@
+package dashboard
+
+import (
+	"github.com/iotaledger/hive.go/node"
+
+	"github.com/gohornet/hornet/pkg/shutdown"
+	"github.com/gohornet/hornet/pkg/supervisor"
+	"github.com/gohornet/hornet/plugins/snapshot"
+)
+
+func runSnapshotProgressFeed(plugin *node.Plugin) {
+
+	onPruningProgress := supervisor.NewClosure(plugin.Name, "Dashboard[onPruningProgress]", func(progress *snapshot.Progress) {
+		hub.BroadcastMsg(&Msg{Type: MsgTypePruningProgress, Data: progress})
+	})
+
+	onSnapshotProgress := supervisor.NewClosure(plugin.Name, "Dashboard[onSnapshotProgress]", func(progress *snapshot.Progress) {
+		hub.BroadcastMsg(&Msg{Type: MsgTypeSnapshotProgress, Data: progress})
+	})
+
+	supervisor.BackgroundWorker(plugin.Name, "Dashboard[SnapshotProgressUpdater]", func(shutdownSignal <-chan struct{}) {
+		snapshot.Events.PruningProgress.Attach(onPruningProgress)
+		snapshot.Events.SnapshotProgress.Attach(onSnapshotProgress)
+		<-shutdownSignal
+		log.Info("Stopping Dashboard[SnapshotProgressUpdater] ...")
+		snapshot.Events.PruningProgress.Detach(onPruningProgress)
+		snapshot.Events.SnapshotProgress.Detach(onSnapshotProgress)
+		log.Info("Stopping Dashboard[SnapshotProgressUpdater] ... done")
+	}, shutdown.PriorityDashboard)
+}
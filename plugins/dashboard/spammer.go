@@ -1,25 +1,25 @@
 package dashboard
 
 import (
-	"github.com/iotaledger/hive.go/daemon"
-	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/node"
 
 	"github.com/gohornet/hornet/pkg/shutdown"
 	"github.com/gohornet/hornet/pkg/spammer"
+	"github.com/gohornet/hornet/pkg/supervisor"
 	spammerplugin "github.com/gohornet/hornet/plugins/spammer"
 )
 
-func runSpammerMetricWorker() {
+func runSpammerMetricWorker(plugin *node.Plugin) {
 
-	onSpamPerformed := events.NewClosure(func(metrics *spammer.SpamStats) {
+	onSpamPerformed := supervisor.NewClosure(plugin.Name, "Dashboard[onSpamPerformed]", func(metrics *spammer.SpamStats) {
 		hub.BroadcastMsg(&Msg{Type: MsgTypeSpamMetrics, Data: metrics})
 	})
 
-	onAvgSpamMetricsUpdated := events.NewClosure(func(metrics *spammer.AvgSpamMetrics) {
+	onAvgSpamMetricsUpdated := supervisor.NewClosure(plugin.Name, "Dashboard[onAvgSpamMetricsUpdated]", func(metrics *spammer.AvgSpamMetrics) {
 		hub.BroadcastMsg(&Msg{Type: MsgTypeAvgSpamMetrics, Data: metrics})
 	})
 
-	daemon.BackgroundWorker("Dashboard[SpammerMetricUpdater]", func(shutdownSignal <-chan struct{}) {
+	supervisor.BackgroundWorker(plugin.Name, "Dashboard[SpammerMetricUpdater]", func(shutdownSignal <-chan struct{}) {
 		spammerplugin.Events.SpamPerformed.Attach(onSpamPerformed)
 		spammerplugin.Events.AvgSpamMetricsUpdated.Attach(onAvgSpamMetricsUpdated)
 		<-shutdownSignal
@@ -1,8 +1,6 @@
 package dashboard
 
 import (
-	"github.com/iotaledger/hive.go/daemon"
-	"github.com/iotaledger/hive.go/events"
 	"github.com/iotaledger/hive.go/node"
 
 	"github.com/gohornet/hornet/pkg/model/hornet"
@@ -10,6 +8,7 @@ import (
 	tanglePackage "github.com/gohornet/hornet/pkg/model/tangle"
 	tanglemodel "github.com/gohornet/hornet/pkg/model/tangle"
 	"github.com/gohornet/hornet/pkg/shutdown"
+	"github.com/gohornet/hornet/pkg/supervisor"
 	"github.com/gohornet/hornet/pkg/tipselect"
 	"github.com/gohornet/hornet/pkg/whiteflag"
 	coordinatorPlugin "github.com/gohornet/hornet/plugins/coordinator"
@@ -50,9 +49,9 @@ type tipinfo struct {
 	IsTip bool   `json:"is_tip"`
 }
 
-func runVisualizer() {
+func runVisualizer(plugin *node.Plugin) {
 
-	onReceivedNewTransaction := events.NewClosure(func(cachedTx *tanglemodel.CachedTransaction, latestMilestoneIndex milestone.Index, latestSolidMilestoneIndex milestone.Index) {
+	onReceivedNewTransaction := supervisor.NewClosure(plugin.Name, "Dashboard[onReceivedNewTransaction]", func(cachedTx *tanglemodel.CachedTransaction, latestMilestoneIndex milestone.Index, latestSolidMilestoneIndex milestone.Index) {
 		cachedTx.ConsumeTransactionAndMetadata(func(tx *hornet.Transaction, metadata *hornet.TransactionMetadata) { // tx -1
 			if !tanglemodel.IsNodeSyncedWithThreshold() {
 				return
@@ -76,7 +75,7 @@ func runVisualizer() {
 		})
 	})
 
-	onTransactionSolid := events.NewClosure(func(txHash hornet.Hash) {
+	onTransactionSolid := supervisor.NewClosure(plugin.Name, "Dashboard[onTransactionSolid]", func(txHash hornet.Hash) {
 		if !tanglemodel.IsNodeSyncedWithThreshold() {
 			return
 		}
@@ -91,7 +90,7 @@ func runVisualizer() {
 		)
 	})
 
-	onReceivedNewMilestone := events.NewClosure(func(cachedBndl *tanglePackage.CachedBundle) {
+	onReceivedNewMilestone := supervisor.NewClosure(plugin.Name, "Dashboard[onReceivedNewMilestone]", func(cachedBndl *tanglePackage.CachedBundle) {
 		cachedBndl.ConsumeBundle(func(bndl *tanglePackage.Bundle) { // bundle -1
 			if !tanglemodel.IsNodeSyncedWithThreshold() {
 				return
@@ -111,7 +110,7 @@ func runVisualizer() {
 	})
 
 	// show checkpoints as milestones in the coordinator node
-	onIssuedCheckpointTransaction := events.NewClosure(func(checkpointIndex int, tipIndex int, tipsTotal int, txHash hornet.Hash) {
+	onIssuedCheckpointTransaction := supervisor.NewClosure(plugin.Name, "Dashboard[onIssuedCheckpointTransaction]", func(checkpointIndex int, tipIndex int, tipsTotal int, txHash hornet.Hash) {
 		if !tanglemodel.IsNodeSyncedWithThreshold() {
 			return
 		}
@@ -126,7 +125,7 @@ func runVisualizer() {
 		)
 	})
 
-	onMilestoneConfirmed := events.NewClosure(func(confirmation *whiteflag.Confirmation) {
+	onMilestoneConfirmed := supervisor.NewClosure(plugin.Name, "Dashboard[onMilestoneConfirmed]", func(confirmation *whiteflag.Confirmation) {
 		if !tanglemodel.IsNodeSyncedWithThreshold() {
 			return
 		}
@@ -147,7 +146,7 @@ func runVisualizer() {
 		)
 	})
 
-	onTipAdded := events.NewClosure(func(tip *tipselect.Tip) {
+	onTipAdded := supervisor.NewClosure(plugin.Name, "Dashboard[onTipAdded]", func(tip *tipselect.Tip) {
 		if !tanglemodel.IsNodeSyncedWithThreshold() {
 			return
 		}
@@ -163,7 +162,7 @@ func runVisualizer() {
 		)
 	})
 
-	onTipRemoved := events.NewClosure(func(tip *tipselect.Tip) {
+	onTipRemoved := supervisor.NewClosure(plugin.Name, "Dashboard[onTipRemoved]", func(tip *tipselect.Tip) {
 		if !tanglemodel.IsNodeSyncedWithThreshold() {
 			return
 		}
@@ -179,7 +178,7 @@ func runVisualizer() {
 		)
 	})
 
-	daemon.BackgroundWorker("Dashboard[Visualizer]", func(shutdownSignal <-chan struct{}) {
+	supervisor.BackgroundWorker(plugin.Name, "Dashboard[Visualizer]", func(shutdownSignal <-chan struct{}) {
 		tangle.Events.ReceivedNewTransaction.Attach(onReceivedNewTransaction)
 		defer tangle.Events.ReceivedNewTransaction.Detach(onReceivedNewTransaction)
 		tangle.Events.TransactionSolid.Attach(onTransactionSolid)
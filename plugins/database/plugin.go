@@ -13,7 +13,9 @@ import (
 	"github.com/gohornet/hornet/pkg/model/hornet"
 	"github.com/gohornet/hornet/pkg/model/milestone"
 	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/scheduler"
 	"github.com/gohornet/hornet/pkg/shutdown"
+	"github.com/gohornet/hornet/plugins/cli"
 )
 
 var (
@@ -116,8 +118,11 @@ func configure(plugin *node.Plugin) {
 	log = logger.NewLogger(plugin.Name)
 
 	tangle.ConfigureDatabases(config.NodeConfig.GetString(config.CfgDatabasePath))
+	tangle.ConfigureTransactionFilter(config.NodeConfig.GetBool(config.CfgDatabaseTransactionFilterEnabled))
 
-	deleteInvalidMilestones()
+	if !cli.IsSafeMode() {
+		deleteInvalidMilestones()
+	}
 
 	if !tangle.IsCorrectDatabaseVersion() {
 		if !tangle.UpdateDatabaseVersion() {
@@ -125,9 +130,19 @@ func configure(plugin *node.Plugin) {
 		}
 	}
 
+	if cli.IsSafeMode() {
+		// the tangle plugin is not started in safe mode,
+		// but the initial values are needed by the read-only API.
+		tangle.LoadInitialValuesFromDatabase()
+	}
+
 	daemon.BackgroundWorker("Close database", func(shutdownSignal <-chan struct{}) {
 		<-shutdownSignal
-		tangle.MarkDatabaseHealthy()
+		if !cli.IsSafeMode() {
+			// the database health is not touched in safe mode,
+			// otherwise a needed revalidation would be skipped at the next regular startup.
+			tangle.MarkDatabaseHealthy()
+		}
 		log.Info("Syncing databases to disk...")
 		tangle.CloseDatabases()
 		log.Info("Syncing databases to disk... done")
@@ -140,31 +155,34 @@ func RunGarbageCollection() {
 		garbageCollectionLock.Lock()
 		defer garbageCollectionLock.Unlock()
 
-		log.Info("running full database garbage collection. This can take a while...")
+		_ = scheduler.Run("Database garbage collection", nil, func(_ <-chan struct{}) error {
+			log.Info("running full database garbage collection. This can take a while...")
 
-		start := time.Now()
+			start := time.Now()
 
-		Events.DatabaseCleanup.Trigger(&DatabaseCleanup{
-			Start: start,
-		})
+			Events.DatabaseCleanup.Trigger(&DatabaseCleanup{
+				Start: start,
+			})
 
-		err := tangle.CleanupDatabases()
+			err := tangle.CleanupDatabases()
 
-		end := time.Now()
+			end := time.Now()
 
-		Events.DatabaseCleanup.Trigger(&DatabaseCleanup{
-			Start: start,
-			End:   end,
-		})
+			Events.DatabaseCleanup.Trigger(&DatabaseCleanup{
+				Start: start,
+				End:   end,
+			})
 
-		if err != nil {
-			if err != tangle.ErrNothingToCleanUp {
-				log.Warnf("full database garbage collection failed with error: %s. took: %v", err.Error(), end.Sub(start).Truncate(time.Millisecond))
-				return
+			if err != nil {
+				if err != tangle.ErrNothingToCleanUp {
+					log.Warnf("full database garbage collection failed with error: %s. took: %v", err.Error(), end.Sub(start).Truncate(time.Millisecond))
+					return err
+				}
 			}
-		}
 
-		log.Infof("full database garbage collection finished. took %v", end.Sub(start).Truncate(time.Millisecond))
+			log.Infof("full database garbage collection finished. took %v", end.Sub(start).Truncate(time.Millisecond))
+			return nil
+		})
 	}
 }
 
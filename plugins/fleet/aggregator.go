@@ -0,0 +1,88 @@
+package fleet
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/iotaledger/hive.go/node"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/fleet"
+	"github.com/gohornet/hornet/pkg/shutdown"
+	"github.com/gohornet/hornet/pkg/supervisor"
+)
+
+const (
+	reportRoute = "/report"
+
+	// the maximum size of a status report.
+	maxReportBytes = 64 * 1024
+	// the maximum difference between the timestamp of a status report and the local time.
+	maxClockSkew = 1 * time.Minute
+)
+
+func handleReport(c *gin.Context) {
+	payload, err := ioutil.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxReportBytes))
+	if err != nil {
+		c.AbortWithStatus(http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if err := fleet.Verify(sharedSecret, c.GetHeader(fleet.HeaderTimestamp), c.GetHeader(fleet.HeaderSignature), payload, maxClockSkew); err != nil {
+		log.Warnf("Rejected status report from %s: %s", c.ClientIP(), err)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	status := &fleet.Status{}
+	if err := json.Unmarshal(payload, status); err != nil {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	if err := registry.Update(status); err != nil {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func runAggregator(plugin *node.Plugin) {
+	log.Info("Starting fleet aggregator ...")
+
+	supervisor.BackgroundWorker(plugin.Name, "Fleet aggregator", func(shutdownSignal <-chan struct{}) {
+		log.Info("Starting fleet aggregator ... done")
+
+		engine := gin.New()
+		engine.Use(gin.Recovery())
+		engine.POST(reportRoute, handleReport)
+		engine.GET(sharedConfigRoute, handleSharedConfig)
+
+		bindAddr := config.NodeConfig.GetString(config.CfgFleetBindAddress)
+		server := &http.Server{Addr: bindAddr, Handler: engine}
+
+		go func() {
+			log.Infof("The fleet aggregator accepts status reports on: http://%s%s", bindAddr, reportRoute)
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Warnf("Stopping fleet aggregator due to an error: %s", err)
+			}
+		}()
+
+		<-shutdownSignal
+		log.Info("Stopping fleet aggregator ...")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := server.Shutdown(ctx); err != nil {
+			log.Warn(err.Error())
+		}
+		cancel()
+
+		log.Info("Stopping fleet aggregator ... done")
+	}, shutdown.PriorityFleet)
+}
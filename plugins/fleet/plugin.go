@@ -0,0 +1,78 @@
+package fleet
+
+import (
+	"os"
+	"time"
+
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/hive.go/node"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/fleet"
+)
+
+var (
+	PLUGIN = node.NewPlugin("Fleet", node.Disabled, configure, run)
+	log    *logger.Logger
+
+	nodeName       string
+	sharedSecret   []byte
+	reportInterval time.Duration
+	staleAfter     time.Duration
+
+	registry = fleet.NewRegistry()
+)
+
+func init() {
+	// the shared configuration has to be applied before the other plugins are configured
+	PLUGIN.Events.Init.Attach(events.NewClosure(initPlugin))
+}
+
+func initPlugin(plugin *node.Plugin) {
+	if node.IsSkipped(plugin) {
+		return
+	}
+
+	log = logger.NewLogger(plugin.Name)
+
+	sharedSecret = []byte(config.NodeConfig.GetString(config.CfgFleetSharedSecret))
+	if len(sharedSecret) == 0 {
+		log.Panicf("'%s' must be set to authenticate the status reports of the fleet", config.CfgFleetSharedSecret)
+	}
+
+	applySharedConfig(config.NodeConfig.GetBool(config.CfgFleetAggregator), config.NodeConfig.GetString(config.CfgFleetAggregatorURL))
+}
+
+func configure(plugin *node.Plugin) {
+	reportInterval = time.Duration(config.NodeConfig.GetInt(config.CfgFleetReportIntervalSeconds)) * time.Second
+	staleAfter = time.Duration(config.NodeConfig.GetInt(config.CfgFleetStaleAfterSeconds)) * time.Second
+
+	// the node is identified by its alias within the fleet
+	nodeName = config.NodeConfig.GetString(config.CfgNodeAlias)
+	if nodeName == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			log.Panicf("'%s' must be set to identify the node within the fleet", config.CfgNodeAlias)
+		}
+		nodeName = hostname
+	}
+}
+
+func run(plugin *node.Plugin) {
+	isAggregator := config.NodeConfig.GetBool(config.CfgFleetAggregator)
+	aggregatorURL := config.NodeConfig.GetString(config.CfgFleetAggregatorURL)
+
+	if isAggregator {
+		runAggregator(plugin)
+	}
+
+	if isAggregator || aggregatorURL != "" {
+		runStatusReporter(plugin, isAggregator, aggregatorURL)
+	}
+}
+
+// Nodes returns the latest status of all nodes of the fleet, if the node is the aggregator of the fleet.
+func Nodes() []*fleet.NodeStatus {
+	return registry.Nodes(staleAfter)
+}
@@ -0,0 +1,106 @@
+package fleet
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iotaledger/hive.go/node"
+	"github.com/iotaledger/hive.go/timeutil"
+
+	"github.com/gohornet/hornet/pkg/fleet"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/shutdown"
+	"github.com/gohornet/hornet/pkg/supervisor"
+	"github.com/gohornet/hornet/plugins/cli"
+	"github.com/gohornet/hornet/plugins/peering"
+	tanglePlugin "github.com/gohornet/hornet/plugins/tangle"
+)
+
+const (
+	reportTimeout = 5 * time.Second
+)
+
+var (
+	client = &http.Client{Timeout: reportTimeout}
+)
+
+// collects the current status of the node.
+func currentStatus() *fleet.Status {
+	tangleDbSize, snapshotDbSize, spentDbSize := tangle.GetDatabaseSizes()
+
+	status := &fleet.Status{
+		Name:                 nodeName,
+		AppVersion:           cli.AppVersion,
+		IsSynced:             tangle.IsNodeSyncedWithThreshold(),
+		IsHealthy:            tanglePlugin.IsNodeHealthy(),
+		LatestMilestoneIndex: tangle.GetLatestMilestoneIndex(),
+		SolidMilestoneIndex:  tangle.GetSolidMilestoneIndex(),
+		ConnectedPeers:       peering.Manager().ConnectedPeerCount(),
+		DatabaseSizeBytes:    tangleDbSize + snapshotDbSize + spentDbSize,
+	}
+
+	if snapshotInfo := tangle.GetSnapshotInfo(); snapshotInfo != nil {
+		status.PruningIndex = snapshotInfo.PruningIndex
+	}
+
+	return status
+}
+
+// sends the given status to the aggregator, signed with the shared secret.
+func sendStatus(aggregatorURL string, status *fleet.Status) error {
+	payload, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(aggregatorURL, "/")+reportRoute, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(fleet.HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(fleet.HeaderSignature, fleet.Sign(sharedSecret, timestamp, payload))
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("aggregator responded with status %s", res.Status)
+	}
+
+	return nil
+}
+
+// reports the status of the node to the aggregator, or records it directly if the node is the aggregator itself.
+func reportStatus(isAggregator bool, aggregatorURL string) {
+	status := currentStatus()
+
+	if isAggregator {
+		if err := registry.Update(status); err != nil {
+			log.Warnf("Recording the status of the node failed: %s", err)
+		}
+		return
+	}
+
+	if err := sendStatus(aggregatorURL, status); err != nil {
+		log.Warnf("Reporting the status to the aggregator %s failed: %s", aggregatorURL, err)
+	}
+}
+
+func runStatusReporter(plugin *node.Plugin, isAggregator bool, aggregatorURL string) {
+	supervisor.BackgroundWorker(plugin.Name, "Fleet[StatusReporter]", func(shutdownSignal <-chan struct{}) {
+		timeutil.Ticker(func() {
+			reportStatus(isAggregator, aggregatorURL)
+		}, reportInterval, shutdownSignal)
+	}, shutdown.PriorityFleet)
+}
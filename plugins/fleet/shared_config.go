@@ -0,0 +1,127 @@
+package fleet
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/fleet"
+)
+
+const (
+	sharedConfigRoute = "/config"
+
+	// the maximum size of the shared configuration.
+	maxSharedConfigBytes = 1024 * 1024
+)
+
+var (
+	// the shared configuration served by the aggregator.
+	sharedConfig []byte
+)
+
+// applySharedConfig merges the shared configuration of the fleet into the configuration of the node.
+// The aggregator reads it from its shared configuration file, all other nodes fetch it from the aggregator.
+// Values given as flags or environment variables still take precedence over the shared configuration.
+func applySharedConfig(isAggregator bool, aggregatorURL string) {
+	var data []byte
+
+	switch {
+	case isAggregator:
+		sharedConfigPath := config.NodeConfig.GetString(config.CfgFleetSharedConfigPath)
+		if sharedConfigPath == "" {
+			return
+		}
+
+		var err error
+		if data, err = ioutil.ReadFile(sharedConfigPath); err != nil {
+			log.Panicf("Reading the shared configuration %s failed: %s", sharedConfigPath, err)
+		}
+
+	case aggregatorURL != "":
+		var err error
+		if data, err = fetchSharedConfig(aggregatorURL); err != nil {
+			log.Warnf("Fetching the shared configuration from the aggregator %s failed, using the local configuration: %s", aggregatorURL, err)
+			return
+		}
+
+		if data == nil {
+			// the aggregator doesn't share a configuration
+			return
+		}
+
+	default:
+		return
+	}
+
+	parsedConfig, err := fleet.ParseSharedConfig(data)
+	if err != nil {
+		if isAggregator {
+			log.Panicf("Parsing the shared configuration failed: %s", err)
+		}
+		log.Warnf("Parsing the shared configuration of the aggregator failed, using the local configuration: %s", err)
+		return
+	}
+
+	if err := config.NodeConfig.MergeConfigMap(parsedConfig); err != nil {
+		log.Panicf("Applying the shared configuration failed: %s", err)
+	}
+
+	if isAggregator {
+		sharedConfig = data
+	}
+
+	log.Info("Applied the shared configuration of the fleet")
+}
+
+// fetches the shared configuration from the aggregator and verifies its signature.
+// Returns nil if the aggregator doesn't share a configuration.
+func fetchSharedConfig(aggregatorURL string) ([]byte, error) {
+	res, err := client.Get(strings.TrimSuffix(aggregatorURL, "/") + sharedConfigRoute)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("aggregator responded with status %s", res.Status)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(res.Body, maxSharedConfigBytes+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > maxSharedConfigBytes {
+		return nil, fmt.Errorf("shared configuration exceeds %d bytes", maxSharedConfigBytes)
+	}
+
+	if err := fleet.Verify(sharedSecret, res.Header.Get(fleet.HeaderTimestamp), res.Header.Get(fleet.HeaderSignature), data, maxClockSkew); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func handleSharedConfig(c *gin.Context) {
+	if sharedConfig == nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	timestamp := time.Now().Unix()
+	c.Header(fleet.HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	c.Header(fleet.HeaderSignature, fleet.Sign(sharedSecret, timestamp, sharedConfig))
+	c.Data(http.StatusOK, "application/json", sharedConfig)
+}
@@ -27,7 +27,7 @@ func BroadcastHeartbeat(filter func(p *peer.Peer) bool) {
 			return true
 		}
 
-		p.EnqueueForSending(heartbeatMsg)
+		p.EnqueueForPrioritySending(heartbeatMsg)
 		return true
 	})
 }
@@ -63,13 +63,14 @@ func BroadcastMilestoneRequests(rangeToRequest int, onExistingMilestoneInRange f
 	// send each ms request to a random peer who supports the message
 	for _, msIndex := range msIndexes {
 		manager.ForAllConnected(func(p *peer.Peer) bool {
-			if !p.Protocol.Supports(sting.FeatureSet) {
+			if !p.Protocol.Supports(sting.FeatureSet) || !p.GossipEnabled() {
 				return true
 			}
 			if !p.HasDataFor(msIndex) {
 				return true
 			}
 			helpers.SendMilestoneRequest(p, msIndex)
+			requestTracker.trackMilestoneRequest(msIndex, p)
 			return false
 		})
 	}
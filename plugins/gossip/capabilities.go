@@ -0,0 +1,171 @@
+package gossip
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"time"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/peering/peer"
+	"github.com/gohornet/hornet/pkg/protocol"
+	"github.com/gohornet/hornet/pkg/protocol/sting"
+	"github.com/gohornet/hornet/plugins/autopeering"
+)
+
+// NeighborCapabilities are the services a connected neighbor advertised in its capabilities record.
+type NeighborCapabilities struct {
+	// The ID of the neighbor.
+	Identity string `json:"identity"`
+	// The hex encoded public key the capabilities record was signed with.
+	PublicKey string `json:"publicKey"`
+	// The index up to which the neighbor pruned its database.
+	PruningIndex milestone.Index `json:"pruningIndex"`
+	// The services the neighbor offers.
+	Services []string `json:"services"`
+	// The publicly reachable address of the neighbor's web API.
+	APIAddress string `json:"apiAddress,omitempty"`
+	// The time the capabilities record was created.
+	Timestamp time.Time `json:"timestamp"`
+}
+
+var (
+	// the key the own capabilities record is signed with.
+	capabilitiesKey ed25519.PrivateKey
+)
+
+// configureCapabilities announces the capabilities record extension and loads the signing key.
+// The node identity of the autopeering is used if a seed or an identity keystore was configured, otherwise a new key is generated.
+func configureCapabilities() {
+	if !config.NodeConfig.GetBool(config.CfgNetGossipCapabilitiesEnabled) {
+		return
+	}
+
+	protocol.EnableCapabilities(sting.FeatureSetCapabilities)
+
+	if seed := autopeering.IdentitySeed(); seed != nil {
+		capabilitiesKey = ed25519.NewKeyFromSeed(seed)
+	} else {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			log.Fatalf("generating capabilities key failed: %s", err)
+		}
+		capabilitiesKey = key
+	}
+
+	log.Infof("Capabilities are signed with key %s", hex.EncodeToString(capabilitiesKey.Public().(ed25519.PublicKey)))
+}
+
+// returns the current capabilities of the node.
+func ownCapabilities() *sting.Capabilities {
+	capabilities := &sting.Capabilities{
+		Timestamp:  time.Now().Unix(),
+		APIAddress: config.NodeConfig.GetString(config.CfgNetGossipCapabilitiesPublicAPIAddress),
+	}
+
+	if snapshotInfo := tangle.GetSnapshotInfo(); snapshotInfo != nil {
+		capabilities.PruningIndex = snapshotInfo.PruningIndex
+	}
+
+	if config.NodeConfig.GetBool(config.CfgNetGossipCapabilitiesServesSnapshots) {
+		capabilities.Services |= sting.ServiceSnapshots
+	}
+	if capabilities.APIAddress != "" {
+		capabilities.Services |= sting.ServicePublicAPI
+	}
+	if !config.NodeConfig.GetBool(config.CfgPruningEnabled) {
+		capabilities.Services |= sting.ServicePermanode
+	}
+	if knownTransactionsEnabled {
+		capabilities.Services |= sting.ServiceKnownTransactions
+	}
+	if peerExchangeEnabled {
+		capabilities.Services |= sting.ServicePeerExchange
+	}
+	if latencyProbeEnabled {
+		capabilities.Services |= sting.ServiceLatencyProbe
+	}
+
+	return capabilities
+}
+
+// sends the own capabilities record to the given peer.
+func sendCapabilities(p *peer.Peer) {
+	if capabilitiesKey == nil || !p.Protocol.Supports(sting.FeatureSetCapabilities) {
+		return
+	}
+
+	capabilitiesMsg, err := sting.NewCapabilitiesMessage(ownCapabilities(), capabilitiesKey)
+	if err != nil {
+		log.Warnf("creating capabilities for %s failed: %s", p.Name(), err)
+		return
+	}
+	p.EnqueueForSending(capabilitiesMsg)
+}
+
+// BroadcastCapabilities sends the own capabilities record to every connected peer which supports it.
+func BroadcastCapabilities() {
+	manager.ForAllConnected(func(p *peer.Peer) bool {
+		sendCapabilities(p)
+		return true
+	})
+}
+
+// stores the capabilities record received from the given peer.
+func processCapabilities(p *peer.Peer, data []byte) {
+	capabilities, err := sting.ParseCapabilities(data)
+	if err != nil {
+		log.Warnf("received invalid capabilities from %s: %s", p.Name(), err)
+		return
+	}
+
+	// the peer must not change its key during the connection
+	latest := p.LatestCapabilities
+	if latest != nil {
+		if !latest.PublicKey.Equal(capabilities.PublicKey) {
+			log.Warnf("received capabilities from %s signed with a different key", p.Name())
+			return
+		}
+		if capabilities.Timestamp < latest.Timestamp {
+			return
+		}
+	}
+
+	p.LatestCapabilities = capabilities
+
+	// share the known peers as soon as a trusted peer is identified
+	if latest == nil {
+		sendPeerExchange(p)
+	}
+}
+
+// NeighborsCapabilities returns the capabilities of all connected neighbors which offer the given services, sorted by identity.
+func NeighborsCapabilities(services sting.Service) []*NeighborCapabilities {
+	result := []*NeighborCapabilities{}
+
+	manager.ForAllConnected(func(p *peer.Peer) bool {
+		capabilities := p.LatestCapabilities
+		if capabilities == nil || !capabilities.Offers(services) {
+			return true
+		}
+
+		result = append(result, &NeighborCapabilities{
+			Identity:     p.ID,
+			PublicKey:    hex.EncodeToString(capabilities.PublicKey),
+			PruningIndex: capabilities.PruningIndex,
+			Services:     sting.ServiceNames(capabilities.Services),
+			APIAddress:   capabilities.APIAddress,
+			Timestamp:    time.Unix(capabilities.Timestamp, 0),
+		})
+		return true
+	})
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Identity < result[j].Identity
+	})
+
+	return result
+}
@@ -0,0 +1,69 @@
+package gossip
+
+import (
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/peering/peer"
+	"github.com/gohornet/hornet/pkg/protocol"
+	"github.com/gohornet/hornet/pkg/protocol/sting"
+)
+
+var (
+	// the maximum amount of message bytes sent within a single frame, 0 if chunking is disabled.
+	chunkingFrameSize int
+)
+
+// configureChunking announces the chunked message extension if it is enabled.
+func configureChunking() {
+	if !config.NodeConfig.GetBool(config.CfgNetGossipChunkingEnabled) {
+		return
+	}
+
+	frameSize := config.NodeConfig.GetInt(config.CfgNetGossipChunkingFrameSize)
+	if frameSize <= 0 || frameSize > sting.MaxChunkDataLength {
+		log.Fatalf("Invalid %s, must be between 1 and %d", config.CfgNetGossipChunkingFrameSize, sting.MaxChunkDataLength)
+	}
+	chunkingFrameSize = frameSize
+
+	protocol.EnableCapabilities(sting.FeatureSetChunking)
+}
+
+// sends the given message to the peer.
+// the message is compressed if the peer supports compression
+// and split into chunks if it exceeds the frame size and the peer supports chunking.
+func sendMessage(p *peer.Peer, data []byte) error {
+	data = compressMessage(p, data)
+
+	if chunkingFrameSize != 0 && len(data) > chunkingFrameSize && p.Protocol.Supports(sting.FeatureSetChunking) {
+		return p.Protocol.SendChunked(data, chunkingFrameSize)
+	}
+	return p.Protocol.Send(data)
+}
+
+// returns a handler which reassembles the chunks received from the given peer
+// and dispatches the complete messages to the protocol.
+func chunkReassemblyHandler(p *peer.Peer) func(data []byte) {
+	reassembler := &sting.ChunkReassembler{}
+
+	return func(data []byte) {
+		chunk, err := sting.ParseChunk(data)
+		if err != nil {
+			p.Protocol.Events.Error.Trigger(err)
+			return
+		}
+
+		msg, err := reassembler.Add(chunk)
+		if err != nil {
+			p.Protocol.Events.Error.Trigger(err)
+			return
+		}
+
+		if msg == nil {
+			// message not complete yet
+			return
+		}
+
+		if err := p.Protocol.Dispatch(msg); err != nil {
+			p.Protocol.Events.Error.Trigger(err)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package gossip
+
+import (
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/peering/peer"
+	"github.com/gohornet/hornet/pkg/protocol"
+	"github.com/gohornet/hornet/pkg/protocol/sting"
+)
+
+var (
+	// the minimum size of a message to be compressed, 0 if compression is disabled.
+	compressionMinMessageSize int
+)
+
+// configureCompression announces the compressed message extension if compression is enabled,
+// so that compressed messages are exchanged with peers which enabled it as well.
+func configureCompression() {
+	if !config.NodeConfig.GetBool(config.CfgNetGossipCompressionEnabled) {
+		return
+	}
+
+	minMessageSize := config.NodeConfig.GetInt(config.CfgNetGossipCompressionMinMessageSize)
+	if minMessageSize <= 0 {
+		log.Fatalf("Invalid %s, must be greater than 0", config.CfgNetGossipCompressionMinMessageSize)
+	}
+	compressionMinMessageSize = minMessageSize
+
+	protocol.EnableCapabilities(sting.FeatureSetCompression)
+}
+
+// compresses the given message if compression is enabled, the peer supports it and the message is big enough.
+// the given message is returned if it is not compressed.
+func compressMessage(p *peer.Peer, data []byte) []byte {
+	if compressionMinMessageSize == 0 || len(data) < compressionMinMessageSize || !p.Protocol.Supports(sting.FeatureSetCompression) {
+		return data
+	}
+
+	compressed, err := sting.NewCompressedMessage(sting.CompressionSnappy, data)
+	if err != nil || compressed == nil {
+		return data
+	}
+
+	return compressed
+}
+
+// returns a handler which decompresses the messages received from the given peer
+// and dispatches them to the protocol.
+func decompressionHandler(p *peer.Peer) func(data []byte) {
+	return func(data []byte) {
+		msg, err := sting.Decompress(data)
+		if err != nil {
+			p.Protocol.Events.Error.Trigger(err)
+			return
+		}
+
+		if err := p.Protocol.Dispatch(msg); err != nil {
+			p.Protocol.Events.Error.Trigger(err)
+		}
+	}
+}
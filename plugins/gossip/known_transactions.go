@@ -0,0 +1,116 @@
+package gossip
+
+import (
+	"sync"
+	"time"
+
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/timeutil"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/peering/peer"
+	"github.com/gohornet/hornet/pkg/protocol/rqueue"
+	"github.com/gohornet/hornet/pkg/protocol/sting"
+	"github.com/gohornet/hornet/pkg/shutdown"
+)
+
+var (
+	// whether the filters of the recently received transactions are exchanged with the peers.
+	knownTransactionsEnabled bool
+	// the transactions received within the current and the previous interval.
+	recentTransactions         = make(map[string]struct{})
+	previousRecentTransactions = make(map[string]struct{})
+	recentTransactionsLock     sync.Mutex
+
+	onKnownTransactionProcessed *events.Closure
+)
+
+// configureKnownTransactions enables the exchange of the filters of the recently received transactions.
+// The support is advertised in the capabilities record, since the message isn't announced in the handshake.
+func configureKnownTransactions() {
+	if !config.NodeConfig.GetBool(config.CfgNetGossipKnownTransactionsEnabled) {
+		return
+	}
+
+	if !config.NodeConfig.GetBool(config.CfgNetGossipCapabilitiesEnabled) {
+		log.Warnf("%s requires %s, the known transactions are not exchanged", config.CfgNetGossipKnownTransactionsEnabled, config.CfgNetGossipCapabilitiesEnabled)
+		return
+	}
+	knownTransactionsEnabled = true
+
+	onKnownTransactionProcessed = events.NewClosure(func(tx *hornet.Transaction, _ *rqueue.Request, _ *peer.Peer) {
+		recentTransactionsLock.Lock()
+		defer recentTransactionsLock.Unlock()
+		recentTransactions[string(tx.GetTxHash())] = struct{}{}
+	})
+}
+
+func runKnownTransactions() {
+	if !knownTransactionsEnabled {
+		return
+	}
+
+	daemon.BackgroundWorker("KnownTransactions", func(shutdownSignal <-chan struct{}) {
+		log.Info("Running KnownTransactions")
+		msgProcessor.Events.TransactionProcessed.Attach(onKnownTransactionProcessed)
+		msgProcessor.Events.PriorityTransactionProcessed.Attach(onKnownTransactionProcessed)
+		timeutil.Ticker(BroadcastKnownTransactions, time.Duration(config.NodeConfig.GetInt(config.CfgNetGossipKnownTransactionsIntervalMilliseconds))*time.Millisecond, shutdownSignal)
+		msgProcessor.Events.TransactionProcessed.Detach(onKnownTransactionProcessed)
+		msgProcessor.Events.PriorityTransactionProcessed.Detach(onKnownTransactionProcessed)
+		log.Info("Stopped KnownTransactions")
+	}, shutdown.PriorityKnownTransactions)
+}
+
+// returns a filter of the transactions received within the current and the previous interval
+// and starts a new interval.
+// an empty filter is returned if too many transactions were received, so peers drop their outdated filter.
+func rotateKnownTransactions() *sting.KnownTransactionsFilter {
+	recentTransactionsLock.Lock()
+	defer recentTransactionsLock.Unlock()
+
+	filter, err := sting.NewKnownTransactionsFilter(len(recentTransactions) + len(previousRecentTransactions))
+	if err != nil {
+		filter, _ = sting.NewKnownTransactionsFilter(0)
+	} else {
+		for hash := range recentTransactions {
+			filter.Add(hornet.Hash(hash))
+		}
+		for hash := range previousRecentTransactions {
+			filter.Add(hornet.Hash(hash))
+		}
+	}
+
+	previousRecentTransactions = recentTransactions
+	recentTransactions = make(map[string]struct{})
+
+	return filter
+}
+
+// BroadcastKnownTransactions sends the filter of the recently received transactions to every connected peer which supports it.
+func BroadcastKnownTransactions() {
+	knownTransactionsMsg, err := sting.NewKnownTransactionsMessage(rotateKnownTransactions())
+	if err != nil {
+		log.Warnf("creating known transactions filter failed: %s", err)
+		return
+	}
+
+	manager.ForAllConnected(func(p *peer.Peer) bool {
+		if capabilities := p.LatestCapabilities; capabilities != nil && capabilities.Offers(sting.ServiceKnownTransactions) {
+			p.EnqueueForPrioritySending(knownTransactionsMsg)
+		}
+		return true
+	})
+}
+
+// stores the known transactions filter received from the given peer.
+func processKnownTransactions(p *peer.Peer, data []byte) {
+	filter, err := sting.ParseKnownTransactions(data)
+	if err != nil {
+		log.Warnf("received invalid known transactions filter from %s: %s", p.Name(), err)
+		return
+	}
+
+	p.KnownTransactions = filter
+}
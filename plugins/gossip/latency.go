@@ -0,0 +1,97 @@
+package gossip
+
+import (
+	"time"
+
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/timeutil"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/peering/peer"
+	"github.com/gohornet/hornet/pkg/protocol/sting"
+	"github.com/gohornet/hornet/pkg/shutdown"
+)
+
+const (
+	// pongs answering pings older than this are ignored, since the ping was probably delayed by a full send queue.
+	maxLatencyProbeRoundTrip = 1 * time.Minute
+)
+
+var (
+	// whether the latency to the peers is measured.
+	latencyProbeEnabled bool
+)
+
+// configureLatencyProbe enables the measurement of the round trip time to the peers.
+// The support is advertised in the capabilities record, since the messages aren't announced in the handshake.
+func configureLatencyProbe() {
+	if !config.NodeConfig.GetBool(config.CfgNetGossipLatencyProbeEnabled) {
+		return
+	}
+
+	if !config.NodeConfig.GetBool(config.CfgNetGossipCapabilitiesEnabled) {
+		log.Warnf("%s requires %s, the latency is not measured", config.CfgNetGossipLatencyProbeEnabled, config.CfgNetGossipCapabilitiesEnabled)
+		return
+	}
+	latencyProbeEnabled = true
+}
+
+func runLatencyProbe() {
+	if !latencyProbeEnabled {
+		return
+	}
+
+	daemon.BackgroundWorker("LatencyProbe", func(shutdownSignal <-chan struct{}) {
+		log.Info("Running LatencyProbe")
+		timeutil.Ticker(BroadcastLatencyPings, time.Duration(config.NodeConfig.GetInt(config.CfgNetGossipLatencyProbeIntervalSeconds))*time.Second, shutdownSignal)
+		log.Info("Stopped LatencyProbe")
+	}, shutdown.PriorityLatencyProbe)
+}
+
+// BroadcastLatencyPings sends a latency ping to every connected peer which answers them.
+// The pings bypass the send queue, so that the measured latency isn't inflated by queued transactions.
+func BroadcastLatencyPings() {
+	pingMsg, err := sting.NewLatencyPingMessage(time.Now().UnixNano())
+	if err != nil {
+		log.Warnf("creating latency ping failed: %s", err)
+		return
+	}
+
+	manager.ForAllConnected(func(p *peer.Peer) bool {
+		if capabilities := p.LatestCapabilities; capabilities != nil && capabilities.Offers(sting.ServiceLatencyProbe) {
+			p.EnqueueForPrioritySending(pingMsg)
+		}
+		return true
+	})
+}
+
+// answers the latency ping received from the given peer.
+func processLatencyPing(p *peer.Peer, data []byte) {
+	timestamp, err := sting.ParseLatencyTimestamp(data)
+	if err != nil {
+		log.Warnf("received invalid latency ping from %s: %s", p.Name(), err)
+		return
+	}
+
+	pongMsg, err := sting.NewLatencyPongMessage(timestamp)
+	if err != nil {
+		log.Warnf("creating latency pong for %s failed: %s", p.Name(), err)
+		return
+	}
+	p.EnqueueForPrioritySending(pongMsg)
+}
+
+// records the round trip time of the latency ping answered by the given peer.
+func processLatencyPong(p *peer.Peer, data []byte) {
+	timestamp, err := sting.ParseLatencyTimestamp(data)
+	if err != nil {
+		log.Warnf("received invalid latency pong from %s: %s", p.Name(), err)
+		return
+	}
+
+	rtt := time.Since(time.Unix(0, timestamp))
+	if rtt <= 0 || rtt > maxLatencyProbeRoundTrip {
+		return
+	}
+	manager.RecordLatency(p, rtt)
+}
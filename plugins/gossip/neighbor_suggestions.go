@@ -0,0 +1,180 @@
+package gossip
+
+import (
+	"sort"
+	"time"
+
+	"github.com/iotaledger/hive.go/syncutils"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/peering/peer"
+	"github.com/gohornet/hornet/pkg/protocol/sting"
+)
+
+const (
+	// the maximum amount of stored neighbor suggestions.
+	maxStoredNeighborSuggestions = 100
+	// the time after which a neighbor suggestion is removed if it was not suggested again.
+	neighborSuggestionExpiry = 1 * time.Hour
+)
+
+// NeighborSuggestion is a neighbor address which was suggested by connected peers.
+type NeighborSuggestion struct {
+	// The suggested neighbor address ("host:port").
+	Address string `json:"address"`
+	// The IDs of the peers which suggested the neighbor.
+	SuggestedBy []string `json:"suggestedBy"`
+	// The time the neighbor was suggested the last time.
+	LastSuggested time.Time `json:"lastSuggested"`
+}
+
+var (
+	neighborSuggestionsLock syncutils.Mutex
+	neighborSuggestions     = make(map[string]*NeighborSuggestion)
+)
+
+// NeighborSuggestions returns all neighbor suggestions received from peers, sorted by address.
+func NeighborSuggestions() []*NeighborSuggestion {
+	neighborSuggestionsLock.Lock()
+	defer neighborSuggestionsLock.Unlock()
+
+	removeExpiredNeighborSuggestions()
+
+	suggestions := make([]*NeighborSuggestion, 0, len(neighborSuggestions))
+	for _, suggestion := range neighborSuggestions {
+		suggestions = append(suggestions, &NeighborSuggestion{
+			Address:       suggestion.Address,
+			SuggestedBy:   append([]string{}, suggestion.SuggestedBy...),
+			LastSuggested: suggestion.LastSuggested,
+		})
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		return suggestions[i].Address < suggestions[j].Address
+	})
+
+	return suggestions
+}
+
+// removes all suggestions which were not suggested again within the expiry time.
+// neighborSuggestionsLock must be held while entering this function.
+func removeExpiredNeighborSuggestions() {
+	for addr, suggestion := range neighborSuggestions {
+		if time.Since(suggestion.LastSuggested) > neighborSuggestionExpiry {
+			delete(neighborSuggestions, addr)
+		}
+	}
+}
+
+// returns the addresses of all synced and statically connected peers except the given one.
+func healthyNeighborAddresses(exclude *peer.Peer) []string {
+	var addresses []string
+	lmi := tangle.GetLatestMilestoneIndex()
+
+	manager.ForAllConnected(func(p *peer.Peer) bool {
+		if p.ID == exclude.ID || p.Autopeering != nil || !p.IsSynced(lmi) {
+			return true
+		}
+
+		addresses = append(addresses, p.InitAddress.String())
+		return len(addresses) < sting.MaxNeighborSuggestions
+	})
+
+	return addresses
+}
+
+// sends the addresses of our other healthy peers to the given peer.
+func sendNeighborSuggestions(p *peer.Peer) {
+	if !p.Protocol.Supports(sting.FeatureSetNeighborSuggestions) {
+		return
+	}
+
+	addresses := healthyNeighborAddresses(p)
+	if len(addresses) == 0 {
+		return
+	}
+
+	neighborSuggestionsMsg, err := sting.NewNeighborSuggestionsMessage(addresses)
+	if err != nil {
+		log.Warnf("creating neighbor suggestions for %s failed: %s", p.Name(), err)
+		return
+	}
+	p.EnqueueForSending(neighborSuggestionsMsg)
+}
+
+// BroadcastNeighborSuggestions sends neighbor suggestions to every connected peer which supports them.
+func BroadcastNeighborSuggestions() {
+	manager.ForAllConnected(func(p *peer.Peer) bool {
+		sendNeighborSuggestions(p)
+		return true
+	})
+}
+
+// returns whether the given address belongs to a peer which is already known to the peering manager.
+func isKnownNeighbor(addr string) bool {
+	known := false
+	manager.ForAll(func(p *peer.Peer) bool {
+		if p.ID == addr || p.InitAddress.String() == addr {
+			known = true
+			return false
+		}
+		return true
+	})
+	return known
+}
+
+// stores the neighbor suggestions received from the given peer.
+// suggestions are only surfaced to the operator, unless auto connect is enabled.
+func processNeighborSuggestions(p *peer.Peer, data []byte) {
+	addresses, err := sting.ParseNeighborSuggestions(data)
+	if err != nil {
+		log.Warnf("received invalid neighbor suggestions from %s: %s", p.Name(), err)
+		return
+	}
+
+	var newSuggestions []string
+
+	neighborSuggestionsLock.Lock()
+	removeExpiredNeighborSuggestions()
+	for _, addr := range addresses {
+		if isKnownNeighbor(addr) {
+			continue
+		}
+
+		suggestion, exists := neighborSuggestions[addr]
+		if !exists {
+			if len(neighborSuggestions) >= maxStoredNeighborSuggestions {
+				continue
+			}
+			suggestion = &NeighborSuggestion{Address: addr}
+			neighborSuggestions[addr] = suggestion
+			newSuggestions = append(newSuggestions, addr)
+		}
+
+		suggestedBefore := false
+		for _, id := range suggestion.SuggestedBy {
+			if id == p.ID {
+				suggestedBefore = true
+				break
+			}
+		}
+		if !suggestedBefore {
+			suggestion.SuggestedBy = append(suggestion.SuggestedBy, p.ID)
+		}
+		suggestion.LastSuggested = time.Now()
+	}
+	neighborSuggestionsLock.Unlock()
+
+	for _, addr := range newSuggestions {
+		log.Infof("neighbor %s suggested new neighbor %s", p.Name(), addr)
+
+		if !config.NodeConfig.GetBool(config.CfgNetGossipNeighborSuggestionsAutoConnect) || manager.SlotsFilled() {
+			continue
+		}
+
+		if err := manager.Add(addr, config.NodeConfig.GetBool(config.CfgNetPreferIPv6), ""); err != nil {
+			log.Warnf("connecting to suggested neighbor %s failed: %s", addr, err)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package gossip
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"time"
+
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/node"
+	"github.com/iotaledger/hive.go/timeutil"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/peering/peer"
+	"github.com/gohornet/hornet/pkg/protocol/sting"
+	"github.com/gohornet/hornet/pkg/shutdown"
+	"github.com/gohornet/hornet/plugins/autopeering"
+)
+
+var (
+	// whether lists of peers are exchanged with the trusted peers.
+	peerExchangeEnabled bool
+	// the public keys of the capabilities records of the trusted peers.
+	peerExchangeTrustedKeys = make(map[string]struct{})
+)
+
+// configurePeerExchange enables the exchange of the peers verified by the autopeering with the trusted peers.
+// The lists are signed with the key of the capabilities record and only accepted from peers whose key is trusted.
+func configurePeerExchange() {
+	if !config.NodeConfig.GetBool(config.CfgNetGossipPeerExchangeEnabled) {
+		return
+	}
+
+	if !config.NodeConfig.GetBool(config.CfgNetGossipCapabilitiesEnabled) {
+		log.Warnf("%s requires %s, the peers are not exchanged", config.CfgNetGossipPeerExchangeEnabled, config.CfgNetGossipCapabilitiesEnabled)
+		return
+	}
+
+	if node.IsSkipped(autopeering.PLUGIN) {
+		log.Warnf("%s requires the autopeering, the peers are not exchanged", config.CfgNetGossipPeerExchangeEnabled)
+		return
+	}
+
+	for _, keyHex := range config.NodeConfig.GetStringSlice(config.CfgNetGossipPeerExchangeTrustedKeys) {
+		key, err := hex.DecodeString(keyHex)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			log.Fatalf("Invalid %s: %s", config.CfgNetGossipPeerExchangeTrustedKeys, keyHex)
+		}
+		peerExchangeTrustedKeys[string(key)] = struct{}{}
+	}
+
+	if len(peerExchangeTrustedKeys) == 0 {
+		log.Warnf("%s is empty, the peers are not exchanged", config.CfgNetGossipPeerExchangeTrustedKeys)
+		return
+	}
+	peerExchangeEnabled = true
+}
+
+func runPeerExchange() {
+	if !peerExchangeEnabled {
+		return
+	}
+
+	daemon.BackgroundWorker("PeerExchange", func(shutdownSignal <-chan struct{}) {
+		log.Info("Running PeerExchange")
+		timeutil.Ticker(BroadcastPeerExchange, time.Duration(config.NodeConfig.GetInt(config.CfgNetGossipPeerExchangeIntervalSeconds))*time.Second, shutdownSignal)
+		log.Info("Stopped PeerExchange")
+	}, shutdown.PriorityPeerExchange)
+}
+
+// tells whether the given peer is trusted and offers the peer exchange.
+func isPeerExchangePartner(p *peer.Peer) bool {
+	capabilities := p.LatestCapabilities
+	if capabilities == nil || !capabilities.Offers(sting.ServicePeerExchange) {
+		return false
+	}
+	_, trusted := peerExchangeTrustedKeys[string(capabilities.PublicKey)]
+	return trusted
+}
+
+// returns the signed list of the peers verified by the autopeering, or nil if there are none.
+func peerExchangeMessage() []byte {
+	peers := autopeering.ExchangeablePeers(sting.MaxPeerExchangePeers)
+	if len(peers) == 0 {
+		return nil
+	}
+
+	pexMsg, err := sting.NewPeerExchangeMessage(&sting.PeerExchange{Timestamp: time.Now().Unix(), Peers: peers}, capabilitiesKey)
+	if err != nil {
+		log.Warnf("creating peer exchange list failed: %s", err)
+		return nil
+	}
+	return pexMsg
+}
+
+// sends the list of the peers verified by the autopeering to the given peer if it is trusted.
+func sendPeerExchange(p *peer.Peer) {
+	if !peerExchangeEnabled || !isPeerExchangePartner(p) {
+		return
+	}
+
+	if pexMsg := peerExchangeMessage(); pexMsg != nil {
+		p.EnqueueForSending(pexMsg)
+	}
+}
+
+// BroadcastPeerExchange sends the list of the peers verified by the autopeering to every connected trusted peer.
+func BroadcastPeerExchange() {
+	pexMsg := peerExchangeMessage()
+	if pexMsg == nil {
+		return
+	}
+
+	manager.ForAllConnected(func(p *peer.Peer) bool {
+		if isPeerExchangePartner(p) {
+			p.EnqueueForSending(pexMsg)
+		}
+		return true
+	})
+}
+
+// passes the peers of the list received from the given peer to the autopeering as candidates.
+// the list must be signed with the key of the capabilities record of the peer, which has to be trusted.
+func processPeerExchange(p *peer.Peer, data []byte) {
+	pex, err := sting.ParsePeerExchange(data)
+	if err != nil {
+		log.Warnf("received invalid peer exchange list from %s: %s", p.Name(), err)
+		return
+	}
+
+	if !isPeerExchangePartner(p) || !p.LatestCapabilities.PublicKey.Equal(pex.PublicKey) {
+		log.Warnf("received peer exchange list from untrusted peer %s", p.Name())
+		return
+	}
+
+	if candidates := autopeering.AddPeerCandidates(pex.Peers); candidates > 0 {
+		log.Debugf("verifying %d peers received from %s", candidates, p.Name())
+	}
+}
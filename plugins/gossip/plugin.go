@@ -1,8 +1,11 @@
 package gossip
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"sync"
+	"time"
 
 	"github.com/gohornet/hornet/pkg/model/tangle"
 	"github.com/gohornet/hornet/pkg/protocol/helpers"
@@ -10,11 +13,13 @@ import (
 	"github.com/iotaledger/hive.go/events"
 	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/hive.go/node"
+	"github.com/iotaledger/hive.go/timeutil"
 
 	"github.com/gohornet/hornet/pkg/config"
 	"github.com/gohornet/hornet/pkg/peering"
 	"github.com/gohornet/hornet/pkg/peering/peer"
 	"github.com/gohornet/hornet/pkg/profile"
+	"github.com/gohornet/hornet/pkg/protocol"
 	"github.com/gohornet/hornet/pkg/protocol/bqueue"
 	"github.com/gohornet/hornet/pkg/protocol/processor"
 	"github.com/gohornet/hornet/pkg/protocol/rqueue"
@@ -34,6 +39,7 @@ var (
 	broadcastQueue         bqueue.Queue
 	broadcastQueueOnce     sync.Once
 	onBroadcastTransaction *events.Closure
+	unknownPayloadPolicy   = processor.UnknownPayloadPolicyStore
 )
 
 // RequestQueue returns the request queue instance of the gossip plugin.
@@ -55,9 +61,19 @@ func BroadcastQueue() bqueue.Queue {
 // Processor returns the message processor instance of the gossip plugin.
 func Processor() *processor.Processor {
 	msgProcessorOnce.Do(func() {
+		recentMessagesCacheSize := 0
+		if config.NodeConfig.GetBool(config.CfgNetGossipRecentMessagesEnabled) {
+			recentMessagesCacheSize = config.NodeConfig.GetInt(config.CfgNetGossipRecentMessagesCacheSize)
+		}
+
 		msgProcessor = processor.New(requestQueue, peeringplugin.Manager(), &processor.Options{
-			ValidMWM:          config.NodeConfig.GetUint64(config.CfgCoordinatorMWM),
-			WorkUnitCacheOpts: profile.LoadProfile().Caches.IncomingTransactionFilter,
+			ValidMWM:                config.NodeConfig.GetUint64(config.CfgCoordinatorMWM),
+			WorkUnitCacheOpts:       profile.LoadProfile().Caches.IncomingTransactionFilter,
+			HopLimit:                byte(config.NodeConfig.GetInt(config.CfgNetGossipHopCountLimit)),
+			AdmissionBufferSize:     config.NodeConfig.GetInt(config.CfgNetGossipAdmissionBufferSize),
+			UnknownPeersRelayFilter: unknownPeersRelayFilter,
+			UnknownPayloadPolicy:    unknownPayloadPolicy,
+			RecentMessagesCacheSize: recentMessagesCacheSize,
 		})
 	})
 	return msgProcessor
@@ -68,6 +84,33 @@ func configure(plugin *node.Plugin) {
 
 	manager = peeringplugin.Manager()
 
+	if config.NodeConfig.GetBool(config.CfgNetGossipHopCountEnabled) {
+		protocol.EnableCapabilities(sting.FeatureSetHopCount)
+	}
+
+	if hopLimit := config.NodeConfig.GetInt(config.CfgNetGossipHopCountLimit); hopLimit < 0 || hopLimit > math.MaxUint8 {
+		log.Panicf("invalid hop count limit under config option '%s': %d, it has to be between 0 and %d", config.CfgNetGossipHopCountLimit, hopLimit, math.MaxUint8)
+	}
+
+	if config.NodeConfig.GetBool(config.CfgNetGossipNeighborSuggestionsEnabled) {
+		protocol.EnableCapabilities(sting.FeatureSetNeighborSuggestions)
+	}
+
+	policy, err := processor.ParseUnknownPayloadPolicy(config.NodeConfig.GetString(config.CfgProtocolUnknownPayloads))
+	if err != nil {
+		log.Panic(err)
+	}
+	unknownPayloadPolicy = policy
+
+	configureCapabilities()
+	configureChunking()
+	configureCompression()
+	configureSpamDetection()
+	configureRequestTracker()
+	configureKnownTransactions()
+	configurePeerExchange()
+	configureLatencyProbe()
+
 	// create networking queues
 	RequestQueue()
 	BroadcastQueue()
@@ -82,13 +125,26 @@ func configure(plugin *node.Plugin) {
 	manager.Events.PeerConnected.Attach(events.NewClosure(func(p *peer.Peer) {
 
 		if p.Protocol.Supports(sting.FeatureSet) {
-			addSTINGMessageEventHandlers(p)
+			if p.GossipEnabled() {
+				addSTINGMessageEventHandlers(p)
+			} else {
+				// peers without gossip only exchange heartbeats, so that the connection is kept alive
+				log.Infof("not gossiping with %s because of its relation (%s)", p.Name(), p.Protocol.Relation())
+				addSTINGHeartbeatEventHandlers(p)
+			}
 
 			// send heartbeat and latest milestone request
 			if snapshotInfo := tangle.GetSnapshotInfo(); snapshotInfo != nil {
 				connected, synced := manager.ConnectedAndSyncedPeerCount()
 				helpers.SendHeartbeat(p, tangle.GetSolidMilestoneIndex(), snapshotInfo.PruningIndex, tangle.GetLatestMilestoneIndex(), connected, synced)
-				helpers.SendLatestMilestoneRequest(p)
+				if p.GossipEnabled() {
+					helpers.SendLatestMilestoneRequest(p)
+				}
+			}
+
+			if p.GossipEnabled() {
+				sendNeighborSuggestions(p)
+				sendCapabilities(p)
 			}
 		}
 
@@ -100,16 +156,35 @@ func configure(plugin *node.Plugin) {
 
 		// fire up send queue consumer
 		daemon.BackgroundWorker(fmt.Sprintf("send queue %s", p.ID), func(shutdownSignal <-chan struct{}) {
+			send := func(data []byte) {
+				if err := sendMessage(p, data); err != nil {
+					if errors.Is(err, protocol.ErrWriteDeadlineExceeded) {
+						p.DroppedForSending()
+						return
+					}
+					p.Protocol.Events.Error.Trigger(err)
+				}
+			}
+
 			for {
+				// messages in the priority send queue bypass the ones in the send queue
+				select {
+				case data := <-p.PrioritySendQueue:
+					send(data)
+					continue
+				default:
+				}
+
 				select {
 				case <-disconnectSignal:
 					return
 				case <-shutdownSignal:
 					return
+				case data := <-p.PrioritySendQueue:
+					send(data)
 				case data := <-p.SendQueue:
-					if err := p.Protocol.Send(data); err != nil {
-						p.Protocol.Events.Error.Trigger(err)
-					}
+					p.DequeuedForSending(data)
+					send(data)
 				}
 			}
 		}, shutdown.PriorityPeerSendQueue)
@@ -126,11 +201,43 @@ func run(_ *node.Plugin) {
 
 	daemon.BackgroundWorker("MessageProcessor", func(shutdownSignal <-chan struct{}) {
 		log.Info("Running MessageProcessor")
+		if loaded, err := msgProcessor.LoadRecentMessages(); err != nil {
+			log.Warnf("Loading the recent messages failed: %s", err)
+		} else if loaded > 0 {
+			log.Infof("Loaded %d recent messages", loaded)
+		}
+
 		msgProcessor.Events.BroadcastTransaction.Attach(onBroadcastTransaction)
 		msgProcessor.Run(shutdownSignal)
 		msgProcessor.Events.BroadcastTransaction.Detach(onBroadcastTransaction)
+
+		if persisted, err := msgProcessor.PersistRecentMessages(); err != nil {
+			log.Warnf("Persisting the recent messages failed: %s", err)
+		} else if persisted > 0 {
+			log.Infof("Persisted %d recent messages", persisted)
+		}
 		log.Info("Stopped MessageProcessor")
 	}, shutdown.PriorityMessageProcessor)
 
+	if config.NodeConfig.GetBool(config.CfgNetGossipNeighborSuggestionsEnabled) {
+		daemon.BackgroundWorker("NeighborSuggestions", func(shutdownSignal <-chan struct{}) {
+			log.Info("Running NeighborSuggestions")
+			timeutil.Ticker(BroadcastNeighborSuggestions, time.Duration(config.NodeConfig.GetInt(config.CfgNetGossipNeighborSuggestionsIntervalSeconds))*time.Second, shutdownSignal)
+			log.Info("Stopped NeighborSuggestions")
+		}, shutdown.PriorityNeighborSuggestions)
+	}
+
+	if config.NodeConfig.GetBool(config.CfgNetGossipCapabilitiesEnabled) {
+		daemon.BackgroundWorker("Capabilities", func(shutdownSignal <-chan struct{}) {
+			log.Info("Running Capabilities")
+			timeutil.Ticker(BroadcastCapabilities, time.Duration(config.NodeConfig.GetInt(config.CfgNetGossipCapabilitiesIntervalSeconds))*time.Second, shutdownSignal)
+			log.Info("Stopped Capabilities")
+		}, shutdown.PriorityCapabilities)
+	}
+
 	runRequestWorkers()
+	runRequestTracker()
+	runKnownTransactions()
+	runPeerExchange()
+	runLatencyProbe()
 }
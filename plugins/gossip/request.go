@@ -15,6 +15,7 @@ import (
 	"github.com/gohornet/hornet/pkg/protocol/rqueue"
 	"github.com/gohornet/hornet/pkg/protocol/sting"
 	"github.com/gohornet/hornet/pkg/shutdown"
+	"github.com/gohornet/hornet/pkg/watchdog"
 )
 
 var (
@@ -22,6 +23,9 @@ var (
 	enqueuePendingRequestsInterval = 1500 * time.Millisecond
 	discardRequestsOlderThan       = 10 * time.Second
 	requestBackpressureSignals     [](func() bool)
+
+	pendingRequestsEnqueuerLoop = watchdog.RegisterLoop("Gossip[PendingRequestsEnqueuer]")
+	stingRequesterLoop          = watchdog.RegisterLoop("Gossip[STINGRequester]")
 )
 
 func AddRequestBackpressureSignal(reqFunc func() bool) {
@@ -38,9 +42,11 @@ func runRequestWorkers() {
 			case <-shutdownSignal:
 				return
 			case <-enqueueTicker.C:
+				end := pendingRequestsEnqueuerLoop.Begin()
 				for _, reqBackpressureSignal := range requestBackpressureSignals {
 					if reqBackpressureSignal() {
 						// skip enqueueing of the pending requests if a backpressure signal is set to true to reduce pressure
+						end()
 						continue requestQueueEnqueueLoop
 					}
 				}
@@ -53,6 +59,7 @@ func runRequestWorkers() {
 					default:
 					}
 				}
+				end()
 			}
 		}
 	}, shutdown.PriorityRequestsProcessor)
@@ -70,47 +77,97 @@ func runRequestWorkers() {
 				}
 
 				// drain request queue
+				end := stingRequesterLoop.Begin()
 				for r := RequestQueue().Next(); r != nil; r = RequestQueue().Next() {
-					requested := false
-					manager.ForAllConnected(func(p *peer.Peer) bool {
-						if !p.Protocol.Supports(sting.FeatureSet) {
-							return true
-						}
-						// we only send a request message if the peer actually has the data
-						// (r.MilestoneIndex > PrunedMilestoneIndex && r.MilestoneIndex <= SolidMilestoneIndex)
-						if !p.HasDataFor(r.MilestoneIndex) {
-							return true
-						}
-
-						helpers.SendTransactionRequest(p, r.Hash)
-						requested = true
-						return false
-					})
-
-					if !requested {
-						// We have no neighbor that has the data for sure,
-						// so we ask all neighbors that could have the data
-						// (r.MilestoneIndex > PrunedMilestoneIndex && r.MilestoneIndex <= LatestMilestoneIndex)
-						manager.ForAllConnected(func(p *peer.Peer) bool {
-							if !p.Protocol.Supports(sting.FeatureSet) {
-								return true
-							}
-
-							// we only send a request message if the peer could have the data
-							if !p.CouldHaveDataFor(r.MilestoneIndex) {
-								return true
-							}
-
-							helpers.SendTransactionRequest(p, r.Hash)
-							return true
-						})
-					}
+					sendRequest(r)
 				}
+				end()
 			}
 		}
 	}, shutdown.PriorityRequestsProcessor)
 }
 
+// sends the request to the neighbors, depending on the escalation of the milestone cone.
+// priority requests are sent via the priority send queues of the peers.
+func sendRequest(r *rqueue.Request) {
+	escalation := requestEscalationFor(r.MilestoneIndex)
+
+	sendTransactionRequest := helpers.SendTransactionRequest
+	if r.Priority {
+		sendTransactionRequest = helpers.SendPriorityTransactionRequest
+	}
+
+	if escalation == RequestEscalationPreferFullHistory {
+		if peers := fullHistoryPeersFor(r.MilestoneIndex); len(peers) > 0 {
+			for _, p := range peers {
+				sendTransactionRequest(p, r.Hash)
+			}
+			return
+		}
+	}
+
+	if escalation == RequestEscalationNone {
+		// the request tracker sends the request to another peer if the asked one doesn't answer in time
+		if requestTracker.isTransactionTracked(r.Hash) {
+			return
+		}
+
+		var deprioritizedPeer *peer.Peer
+		requested := false
+		latestMilestoneIndex := tangle.GetLatestMilestoneIndex()
+		manager.ForAllConnected(func(p *peer.Peer) bool {
+			if !p.Protocol.Supports(sting.FeatureSet) || !p.GossipEnabled() {
+				return true
+			}
+			// we only send a request message if the peer actually has the data
+			// (r.MilestoneIndex > PrunedMilestoneIndex && r.MilestoneIndex <= SolidMilestoneIndex)
+			if !p.HasDataFor(r.MilestoneIndex) {
+				return true
+			}
+
+			// peers with a low score, a congested send queue or which are not synced are only asked if no other peer has the data
+			if isDeprioritized(p, latestMilestoneIndex) {
+				if deprioritizedPeer == nil {
+					deprioritizedPeer = p
+				}
+				return true
+			}
+
+			sendTransactionRequest(p, r.Hash)
+			requestTracker.trackTransactionRequest(r, p)
+			requested = true
+			return false
+		})
+
+		if requested {
+			return
+		}
+
+		if deprioritizedPeer != nil {
+			sendTransactionRequest(deprioritizedPeer, r.Hash)
+			requestTracker.trackTransactionRequest(r, deprioritizedPeer)
+			return
+		}
+	}
+
+	// We have no neighbor that has the data for sure, or the request was escalated,
+	// so we ask all neighbors that could have the data
+	// (r.MilestoneIndex > PrunedMilestoneIndex && r.MilestoneIndex <= LatestMilestoneIndex)
+	manager.ForAllConnected(func(p *peer.Peer) bool {
+		if !p.Protocol.Supports(sting.FeatureSet) || !p.GossipEnabled() {
+			return true
+		}
+
+		// we only send a request message if the peer could have the data
+		if !p.CouldHaveDataFor(r.MilestoneIndex) {
+			return true
+		}
+
+		sendTransactionRequest(p, r.Hash)
+		return true
+	})
+}
+
 // adds the request to the request queue and signals the request to drain it.
 func enqueueAndSignal(r *rqueue.Request) bool {
 	if !RequestQueue().Enqueue(r) {
@@ -130,14 +187,6 @@ func enqueueAndSignal(r *rqueue.Request) bool {
 // Request enqueues a request to the request queue for the given transaction if it isn't a solid entry point
 // and is not contained in the database already.
 func Request(hash hornet.Hash, msIndex milestone.Index, preventDiscard ...bool) bool {
-	if tangle.SolidEntryPointsContain(hash) {
-		return false
-	}
-
-	if tangle.ContainsTransaction(hash) {
-		return false
-	}
-
 	r := &rqueue.Request{
 		Hash:           hash,
 		MilestoneIndex: msIndex,
@@ -145,6 +194,31 @@ func Request(hash hornet.Hash, msIndex milestone.Index, preventDiscard ...bool)
 	if len(preventDiscard) > 0 {
 		r.PreventDiscard = preventDiscard[0]
 	}
+	return request(r)
+}
+
+// PriorityRequest works like Request but the request is never discarded and sent before all ordinary requests,
+// both from the request queue and from the send queues of the peers.
+// It is used for transactions which block the solidification of a milestone.
+func PriorityRequest(hash hornet.Hash, msIndex milestone.Index) bool {
+	return request(&rqueue.Request{
+		Hash:           hash,
+		MilestoneIndex: msIndex,
+		PreventDiscard: true,
+		Priority:       true,
+	})
+}
+
+// enqueues the given request if the requested transaction isn't a solid entry point and not contained in the database.
+func request(r *rqueue.Request) bool {
+	if tangle.SolidEntryPointsContain(r.Hash) {
+		return false
+	}
+
+	if tangle.ContainsTransaction(r.Hash) {
+		return false
+	}
+
 	return enqueueAndSignal(r)
 }
 
@@ -159,9 +233,34 @@ func RequestMultiple(hashes hornet.Hashes, msIndex milestone.Index, preventDisca
 	return requested
 }
 
+// PriorityRequestMultiple works like PriorityRequest but takes multiple transaction hashes.
+func PriorityRequestMultiple(hashes hornet.Hashes, msIndex milestone.Index) int {
+	requested := 0
+	for _, hash := range hashes {
+		if PriorityRequest(hash, msIndex) {
+			requested++
+		}
+	}
+	return requested
+}
+
 // RequestApprovees enqueues requests for the approvees of the given transaction to the request queue, if the
 // given transaction is not a solid entry point and neither its approvees are and also not in the database.
 func RequestApprovees(cachedTx *tangle.CachedTransaction, msIndex milestone.Index, preventDiscard ...bool) {
+	requestApprovees(cachedTx, func(hash hornet.Hash) {
+		Request(hash, msIndex, preventDiscard...)
+	})
+}
+
+// PriorityRequestApprovees works like RequestApprovees but enqueues priority requests.
+func PriorityRequestApprovees(cachedTx *tangle.CachedTransaction, msIndex milestone.Index) {
+	requestApprovees(cachedTx, func(hash hornet.Hash) {
+		PriorityRequest(hash, msIndex)
+	})
+}
+
+// calls the given request function for the approvees of the given transaction if it is not a solid entry point.
+func requestApprovees(cachedTx *tangle.CachedTransaction, requestFunc func(hash hornet.Hash)) {
 	cachedTx.ConsumeMetadata(func(metadata *hornet.TransactionMetadata) {
 		txHash := metadata.GetTxHash()
 
@@ -169,14 +268,14 @@ func RequestApprovees(cachedTx *tangle.CachedTransaction, msIndex milestone.Inde
 			return
 		}
 
-		Request(metadata.GetTrunkHash(), msIndex, preventDiscard...)
+		requestFunc(metadata.GetTrunkHash())
 		if !bytes.Equal(metadata.GetTrunkHash(), metadata.GetBranchHash()) {
-			Request(metadata.GetBranchHash(), msIndex, preventDiscard...)
+			requestFunc(metadata.GetBranchHash())
 		}
 	})
 }
 
-// RequestMilestoneApprovees enqueues requests for the approvees of the given milestone bundle to the request queue,
+// RequestMilestoneApprovees enqueues priority requests for the approvees of the given milestone bundle to the request queue,
 // if the approvees are not solid entry points and not already in the database.
 func RequestMilestoneApprovees(cachedMsBndl *tangle.CachedBundle) bool {
 	defer cachedMsBndl.Release() // bundle -1
@@ -187,9 +286,9 @@ func RequestMilestoneApprovees(cachedMsBndl *tangle.CachedBundle) bool {
 	msIndex := cachedMsBndl.GetBundle().GetMilestoneIndex()
 
 	txMeta := cachedHeadTxMeta.GetMetadata()
-	enqueued := Request(txMeta.GetTrunkHash(), msIndex, true)
+	enqueued := PriorityRequest(txMeta.GetTrunkHash(), msIndex)
 	if !bytes.Equal(txMeta.GetTrunkHash(), txMeta.GetBranchHash()) {
-		enqueuedTwo := Request(txMeta.GetBranchHash(), msIndex, true)
+		enqueuedTwo := PriorityRequest(txMeta.GetBranchHash(), msIndex)
 		if !enqueued && enqueuedTwo {
 			enqueued = true
 		}
@@ -239,3 +338,9 @@ func MemoizedRequestMissingMilestoneApprovees(preventDiscard ...bool) func(ms mi
 			false, false, nil)
 	}
 }
+
+// tells whether the given peer should only be asked if no other peer has the data,
+// because of its low score, its congested send queue or because it isn't synced while synced peers are preferred.
+func isDeprioritized(p *peer.Peer, latestMilestoneIndex milestone.Index) bool {
+	return p.Deprioritized() || p.SendQueueCongested() || manager.DeprioritizedUnsynced(p, latestMilestoneIndex)
+}
@@ -0,0 +1,87 @@
+package gossip
+
+import (
+	"github.com/iotaledger/hive.go/syncutils"
+
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/peering/peer"
+	"github.com/gohornet/hornet/pkg/protocol/sting"
+)
+
+// RequestEscalation defines how widely the requests of a milestone cone are sent to the neighbors.
+type RequestEscalation int
+
+const (
+	// RequestEscalationNone sends a request to a single neighbor which has the data.
+	RequestEscalationNone RequestEscalation = iota
+	// RequestEscalationWidenFanOut sends a request to all neighbors which could have the data.
+	RequestEscalationWidenFanOut
+	// RequestEscalationPreferFullHistory sends a request to the neighbors with the most history,
+	// and falls back to all neighbors which could have the data.
+	RequestEscalationPreferFullHistory
+)
+
+var (
+	requestEscalation               RequestEscalation
+	requestEscalationMilestoneIndex milestone.Index
+	requestEscalationLock           syncutils.RWMutex
+)
+
+// SetRequestEscalation escalates the requests of all milestone cones up to the given milestone index.
+// RequestEscalationNone resets the escalation.
+func SetRequestEscalation(msIndex milestone.Index, escalation RequestEscalation) {
+	requestEscalationLock.Lock()
+	defer requestEscalationLock.Unlock()
+
+	if escalation == RequestEscalationNone {
+		msIndex = 0
+	}
+
+	requestEscalation = escalation
+	requestEscalationMilestoneIndex = msIndex
+}
+
+// returns the escalation for requests of the given milestone cone.
+func requestEscalationFor(msIndex milestone.Index) RequestEscalation {
+	requestEscalationLock.RLock()
+	defer requestEscalationLock.RUnlock()
+
+	if msIndex > requestEscalationMilestoneIndex {
+		return RequestEscalationNone
+	}
+	return requestEscalation
+}
+
+// fullHistoryPeersFor returns the connected peers with the most history which have the data for the given milestone.
+// Neighbors advertising themselves as permanodes are preferred, otherwise the ones with the lowest pruning index are chosen.
+func fullHistoryPeersFor(msIndex milestone.Index) []*peer.Peer {
+	var permanodes []*peer.Peer
+	var lowestPruned []*peer.Peer
+	var lowestPrunedIndex milestone.Index
+
+	manager.ForAllConnected(func(p *peer.Peer) bool {
+		if !p.Protocol.Supports(sting.FeatureSet) || !p.GossipEnabled() || !p.HasDataFor(msIndex) {
+			return true
+		}
+
+		if capabilities := p.LatestCapabilities; capabilities != nil && capabilities.Offers(sting.ServicePermanode) {
+			permanodes = append(permanodes, p)
+			return true
+		}
+
+		prunedIndex := p.LatestHeartbeat.PrunedMilestoneIndex
+		switch {
+		case len(lowestPruned) == 0 || prunedIndex < lowestPrunedIndex:
+			lowestPruned = []*peer.Peer{p}
+			lowestPrunedIndex = prunedIndex
+		case prunedIndex == lowestPrunedIndex:
+			lowestPruned = append(lowestPruned, p)
+		}
+		return true
+	})
+
+	if len(permanodes) > 0 {
+		return permanodes
+	}
+	return lowestPruned
+}
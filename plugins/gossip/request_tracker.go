@@ -0,0 +1,201 @@
+package gossip
+
+import (
+	"sync"
+	"time"
+
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/timeutil"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/peering/peer"
+	"github.com/gohornet/hornet/pkg/protocol/helpers"
+	"github.com/gohornet/hornet/pkg/protocol/rqueue"
+	"github.com/gohornet/hornet/pkg/protocol/sting"
+	"github.com/gohornet/hornet/pkg/shutdown"
+)
+
+var (
+	requestTracker *tracker
+
+	onTrackedTransactionProcessed *events.Closure
+)
+
+// a request which was sent to a single peer and awaits its response.
+type trackedRequest struct {
+	// the milestone index of the requested milestone or of the cone the requested transaction belongs to.
+	msIndex milestone.Index
+	// whether the request is sent via the priority send queues of the peers.
+	priority bool
+	// the peers which were asked already.
+	asked map[string]struct{}
+	// the time the request was last sent.
+	sentTime time.Time
+}
+
+// tracker correlates the requests sent to single peers with their responses.
+// Requests which weren't answered within the timeout are sent to another peer which has the data.
+type tracker struct {
+	sync.Mutex
+	timeout      time.Duration
+	transactions map[string]*trackedRequest
+	milestones   map[milestone.Index]*trackedRequest
+}
+
+func newTracker(timeout time.Duration) *tracker {
+	return &tracker{
+		timeout:      timeout,
+		transactions: make(map[string]*trackedRequest),
+		milestones:   make(map[milestone.Index]*trackedRequest),
+	}
+}
+
+func configureRequestTracker() {
+	requestTracker = newTracker(time.Duration(config.NodeConfig.GetInt(config.CfgNetGossipRequestsTimeoutMilliseconds)) * time.Millisecond)
+
+	onTrackedTransactionProcessed = events.NewClosure(func(tx *hornet.Transaction, request *rqueue.Request, _ *peer.Peer) {
+		if request != nil {
+			requestTracker.transactionReceived(tx.GetTxHash())
+		}
+	})
+}
+
+func runRequestTracker() {
+	if requestTracker.timeout == 0 {
+		return
+	}
+
+	daemon.BackgroundWorker("RequestTracker", func(shutdownSignal <-chan struct{}) {
+		msgProcessor.Events.TransactionProcessed.Attach(onTrackedTransactionProcessed)
+		msgProcessor.Events.PriorityTransactionProcessed.Attach(onTrackedTransactionProcessed)
+		timeutil.Ticker(requestTracker.reRequestExpired, requestTracker.timeout/2, shutdownSignal)
+		msgProcessor.Events.TransactionProcessed.Detach(onTrackedTransactionProcessed)
+		msgProcessor.Events.PriorityTransactionProcessed.Detach(onTrackedTransactionProcessed)
+	}, shutdown.PriorityRequestsProcessor)
+}
+
+// tracks the given transaction request which was sent to the given peer.
+func (t *tracker) trackTransactionRequest(r *rqueue.Request, p *peer.Peer) {
+	if t == nil || t.timeout == 0 {
+		return
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	t.transactions[string(r.Hash)] = &trackedRequest{msIndex: r.MilestoneIndex, priority: r.Priority, asked: map[string]struct{}{p.ID: {}}, sentTime: time.Now()}
+}
+
+// tracks the request for the given milestone which was sent to the given peer.
+func (t *tracker) trackMilestoneRequest(msIndex milestone.Index, p *peer.Peer) {
+	if t == nil || t.timeout == 0 {
+		return
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	t.milestones[msIndex] = &trackedRequest{msIndex: msIndex, asked: map[string]struct{}{p.ID: {}}, sentTime: time.Now()}
+}
+
+// tells whether a request for the given transaction awaits its response.
+func (t *tracker) isTransactionTracked(hash hornet.Hash) bool {
+	if t == nil {
+		return false
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	_, tracked := t.transactions[string(hash)]
+	return tracked
+}
+
+// stops tracking the request for the given transaction since it was answered.
+func (t *tracker) transactionReceived(hash hornet.Hash) {
+	t.Lock()
+	defer t.Unlock()
+	delete(t.transactions, string(hash))
+}
+
+// sends the requests which weren't answered within the timeout to another peer which has the data.
+// requests which were answered, discarded or for which no other peer is left are no longer tracked,
+// so they are sent again by the request queue.
+func (t *tracker) reRequestExpired() {
+	t.Lock()
+	defer t.Unlock()
+
+	for key, r := range t.transactions {
+		hash := hornet.Hash(key)
+		if time.Since(r.sentTime) < t.timeout {
+			continue
+		}
+
+		if !RequestQueue().IsPending(hash) || tangle.ContainsTransaction(hash) {
+			delete(t.transactions, key)
+			continue
+		}
+
+		p := t.nextPeer(r)
+		if p == nil {
+			delete(t.transactions, key)
+			continue
+		}
+		if r.priority {
+			helpers.SendPriorityTransactionRequest(p, hash)
+			continue
+		}
+		helpers.SendTransactionRequest(p, hash)
+	}
+
+	for msIndex, r := range t.milestones {
+		if time.Since(r.sentTime) < t.timeout {
+			continue
+		}
+
+		if tangle.ContainsMilestone(msIndex) {
+			delete(t.milestones, msIndex)
+			continue
+		}
+
+		p := t.nextPeer(r)
+		if p == nil {
+			delete(t.milestones, msIndex)
+			continue
+		}
+		helpers.SendMilestoneRequest(p, msIndex)
+	}
+}
+
+// returns a peer which has the data of the given request and wasn't asked yet and marks it as asked.
+// peers with a low score, a congested send queue or which are not synced are only chosen if no other peer is left.
+func (t *tracker) nextPeer(r *trackedRequest) *peer.Peer {
+	var next, deprioritized *peer.Peer
+	latestMilestoneIndex := tangle.GetLatestMilestoneIndex()
+	manager.ForAllConnected(func(p *peer.Peer) bool {
+		if !p.Protocol.Supports(sting.FeatureSet) || !p.GossipEnabled() || !p.HasDataFor(r.msIndex) {
+			return true
+		}
+		if _, asked := r.asked[p.ID]; asked {
+			return true
+		}
+		if isDeprioritized(p, latestMilestoneIndex) {
+			if deprioritized == nil {
+				deprioritized = p
+			}
+			return true
+		}
+		next = p
+		return false
+	})
+
+	if next == nil {
+		next = deprioritized
+	}
+	if next != nil {
+		r.asked[next.ID] = struct{}{}
+		r.sentTime = time.Now()
+	}
+	return next
+}
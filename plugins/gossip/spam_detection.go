@@ -0,0 +1,75 @@
+package gossip
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/spamfilter"
+)
+
+var (
+	spamDetector *spamfilter.Detector
+	// transactions of the coordinator are never considered spam.
+	spamDetectionCooAddress hornet.Hash
+)
+
+// SpamDetector returns the spam detector instance of the gossip plugin.
+// Returns nil if the spam detection is disabled.
+func SpamDetector() *spamfilter.Detector {
+	return spamDetector
+}
+
+func configureSpamDetection() {
+	if !config.NodeConfig.GetBool(config.CfgNetGossipSpamDetectionEnabled) {
+		return
+	}
+
+	filters, err := spamfilter.ParseFilters(config.NodeConfig.GetStringSlice(config.CfgNetGossipSpamDetectionFilters))
+	if err != nil {
+		log.Fatalf("invalid config option '%s': %s", config.CfgNetGossipSpamDetectionFilters, err)
+	}
+
+	spamDetectionCooAddress = hornet.HashFromAddressTrytes(config.NodeConfig.GetString(config.CfgCoordinatorAddress))
+	spamDetector = spamfilter.NewDetector(
+		time.Duration(config.NodeConfig.GetInt(config.CfgNetGossipSpamDetectionWindowSeconds))*time.Second,
+		config.NodeConfig.GetInt(config.CfgNetGossipSpamDetectionThreshold),
+		filters,
+	)
+
+	// every new transaction passes the tag index, so it is also used to observe the transactions
+	tangle.SetTagIndexFilter(func(tx *hornet.Transaction) bool {
+		if !spamDetectionApplicable(tx) || !spamDetector.Observe(tx) {
+			return false
+		}
+		if !spamDetector.FilterEnabled(spamfilter.FilterNoIndex) {
+			return false
+		}
+		spamDetector.FilterApplied(spamfilter.FilterNoIndex)
+		return true
+	})
+
+	log.Infof("Spam detection enabled with filters %v", filters)
+}
+
+// spamDetectionApplicable returns whether the given transaction can be considered spam.
+// Value transactions and transactions which could be part of a milestone are always processed normally.
+func spamDetectionApplicable(tx *hornet.Transaction) bool {
+	return !tx.IsValue() && !bytes.Equal(tx.GetAddress(), spamDetectionCooAddress)
+}
+
+// unknownPeersRelayFilter returns whether the given transaction must only be relayed to statically configured peers.
+func unknownPeersRelayFilter(tx *hornet.Transaction) bool {
+	if spamDetector == nil || !spamDetector.FilterEnabled(spamfilter.FilterNoRelayToUnknownPeers) {
+		return false
+	}
+
+	if !spamDetectionApplicable(tx) || !spamDetector.IsSpam(tx) {
+		return false
+	}
+
+	spamDetector.FilterApplied(spamfilter.FilterNoRelayToUnknownPeers)
+	return true
+}
@@ -27,6 +27,89 @@ func addSTINGMessageEventHandlers(p *peer.Peer) {
 		metrics.SharedServerMetrics.SentTransactions.Inc()
 	}))
 
+	if p.Protocol.Supports(sting.FeatureSetHopCount) {
+		p.Protocol.Events.Received[sting.MessageTypeTransactionWithHopCount].Attach(events.NewClosure(func(data []byte) {
+			p.Metrics.ReceivedTransactions.Inc()
+			metrics.SharedServerMetrics.Transactions.Inc()
+			msgProcessor.Process(p, sting.MessageTypeTransactionWithHopCount, data)
+		}))
+
+		p.Protocol.Events.Sent[sting.MessageTypeTransactionWithHopCount].Attach(events.NewClosure(func() {
+			p.Metrics.SentPackets.Inc()
+			p.Metrics.SentTransactions.Inc()
+			metrics.SharedServerMetrics.SentTransactions.Inc()
+		}))
+	}
+
+	if p.Protocol.Supports(sting.FeatureSetNeighborSuggestions) {
+		p.Protocol.Events.Received[sting.MessageTypeNeighborSuggestions].Attach(events.NewClosure(func(data []byte) {
+			processNeighborSuggestions(p, data)
+		}))
+
+		p.Protocol.Events.Sent[sting.MessageTypeNeighborSuggestions].Attach(events.NewClosure(func() {
+			p.Metrics.SentPackets.Inc()
+		}))
+	}
+
+	if p.Protocol.Supports(sting.FeatureSetChunking) {
+		p.Protocol.Events.Received[sting.MessageTypeChunk].Attach(events.NewClosure(chunkReassemblyHandler(p)))
+	}
+
+	if p.Protocol.Supports(sting.FeatureSetCompression) {
+		p.Protocol.Events.Received[sting.MessageTypeCompressed].Attach(events.NewClosure(decompressionHandler(p)))
+	}
+
+	if p.Protocol.Supports(sting.FeatureSetCapabilities) {
+		p.Protocol.Events.Received[sting.MessageTypeCapabilities].Attach(events.NewClosure(func(data []byte) {
+			processCapabilities(p, data)
+		}))
+
+		p.Protocol.Events.Sent[sting.MessageTypeCapabilities].Attach(events.NewClosure(func() {
+			p.Metrics.SentPackets.Inc()
+		}))
+
+		// the filters are only sent by peers which received the own capabilities record
+		if knownTransactionsEnabled {
+			p.Protocol.Events.Received[sting.MessageTypeKnownTransactions].Attach(events.NewClosure(func(data []byte) {
+				processKnownTransactions(p, data)
+			}))
+
+			p.Protocol.Events.Sent[sting.MessageTypeKnownTransactions].Attach(events.NewClosure(func() {
+				p.Metrics.SentPackets.Inc()
+			}))
+		}
+
+		// the lists are only sent by trusted peers which received the own capabilities record
+		if peerExchangeEnabled {
+			p.Protocol.Events.Received[sting.MessageTypePeerExchange].Attach(events.NewClosure(func(data []byte) {
+				processPeerExchange(p, data)
+			}))
+
+			p.Protocol.Events.Sent[sting.MessageTypePeerExchange].Attach(events.NewClosure(func() {
+				p.Metrics.SentPackets.Inc()
+			}))
+		}
+
+		// the pings are only sent by peers which received the own capabilities record
+		if latencyProbeEnabled {
+			p.Protocol.Events.Received[sting.MessageTypeLatencyPing].Attach(events.NewClosure(func(data []byte) {
+				processLatencyPing(p, data)
+			}))
+
+			p.Protocol.Events.Received[sting.MessageTypeLatencyPong].Attach(events.NewClosure(func(data []byte) {
+				processLatencyPong(p, data)
+			}))
+
+			p.Protocol.Events.Sent[sting.MessageTypeLatencyPing].Attach(events.NewClosure(func() {
+				p.Metrics.SentPackets.Inc()
+			}))
+
+			p.Protocol.Events.Sent[sting.MessageTypeLatencyPong].Attach(events.NewClosure(func() {
+				p.Metrics.SentPackets.Inc()
+			}))
+		}
+	}
+
 	p.Protocol.Events.Received[sting.MessageTypeTransactionRequest].Attach(events.NewClosure(func(data []byte) {
 		p.Metrics.ReceivedTransactionRequests.Inc()
 		metrics.SharedServerMetrics.ReceivedTransactionRequests.Inc()
@@ -51,6 +134,12 @@ func addSTINGMessageEventHandlers(p *peer.Peer) {
 		metrics.SharedServerMetrics.SentMilestoneRequests.Inc()
 	}))
 
+	addSTINGHeartbeatEventHandlers(p)
+}
+
+// sets up the event handlers for heartbeats, which are also exchanged with peers without gossip.
+func addSTINGHeartbeatEventHandlers(p *peer.Peer) {
+
 	p.Protocol.Events.Received[sting.MessageTypeHeartbeat].Attach(events.NewClosure(func(data []byte) {
 		p.Metrics.ReceivedHeartbeats.Inc()
 		metrics.SharedServerMetrics.ReceivedHeartbeats.Inc()
@@ -0,0 +1,135 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/mqtt"
+)
+
+const (
+	// the time to wait for the connection to the external broker to be established.
+	bridgeConnectTimeout = 10 * time.Second
+	// the time to wait for the pending messages to be sent before the connection is closed.
+	bridgeDisconnectQuiesceMs = 250
+)
+
+var (
+	// ErrBridgeConnectTimeout is returned if the connection to the external broker could not be established in time.
+	ErrBridgeConnectTimeout = errors.New("connecting to the external MQTT broker timed out")
+)
+
+// the announcement of a (removed) subscription by the external broker.
+// only the topic filter is of interest, the other fields depend on the broker.
+type subscriptionEvent struct {
+	Topic string `json:"topic"`
+}
+
+// Bridge publishes the messages to an external MQTT broker.
+// Only topics with active subscriptions are published, the subscriptions are learned
+// from the subscription events of the external broker or taken from the configured topic filters.
+// If neither are configured, all topics are published.
+// Subscriptions which were made before the bridge connected are not announced by the external broker,
+// so the topics of long-lived subscribers should be part of the configured topic filters.
+type Bridge struct {
+	client            paho.Client
+	brokerURL         string
+	subscriptions     *mqtt.Subscriptions
+	filtered          bool
+	subscribedTopic   string
+	unsubscribedTopic string
+}
+
+// NewBridge creates a new bridge to the external broker configured in the node config.
+func NewBridge() *Bridge {
+	b := &Bridge{
+		brokerURL:         config.NodeConfig.GetString(config.CfgMQTTBridgeBrokerURL),
+		subscriptions:     mqtt.NewSubscriptions(config.NodeConfig.GetStringSlice(config.CfgMQTTBridgeTopics)),
+		subscribedTopic:   config.NodeConfig.GetString(config.CfgMQTTBridgeSubscribedEventTopic),
+		unsubscribedTopic: config.NodeConfig.GetString(config.CfgMQTTBridgeUnsubscribedEventTopic),
+	}
+	b.filtered = len(config.NodeConfig.GetStringSlice(config.CfgMQTTBridgeTopics)) != 0 || b.subscribedTopic != ""
+
+	opts := paho.NewClientOptions().
+		AddBroker(b.brokerURL).
+		SetClientID(config.NodeConfig.GetString(config.CfgMQTTBridgeClientID)).
+		SetUsername(config.NodeConfig.GetString(config.CfgMQTTBridgeUsername)).
+		SetPassword(config.NodeConfig.GetString(config.CfgMQTTBridgePassword)).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetOnConnectHandler(b.onConnect).
+		SetConnectionLostHandler(func(_ paho.Client, err error) {
+			log.Warnf("Connection to the external MQTT broker %s lost: %s", b.brokerURL, err)
+		})
+
+	b.client = paho.NewClient(opts)
+	return b
+}
+
+// Start connects to the external broker.
+func (b *Bridge) Start() error {
+	token := b.client.Connect()
+	if !token.WaitTimeout(bridgeConnectTimeout) {
+		// the client keeps retrying in the background
+		return ErrBridgeConnectTimeout
+	}
+	return token.Error()
+}
+
+// Shutdown closes the connection to the external broker.
+func (b *Bridge) Shutdown() error {
+	b.client.Disconnect(bridgeDisconnectQuiesceMs)
+	return nil
+}
+
+// Send publishes the message to the external broker.
+func (b *Bridge) Send(topic string, message string) error {
+	if !b.client.IsConnectionOpen() {
+		// messages are not queued while the connection is down
+		return nil
+	}
+	b.client.Publish(topic, 0, false, message)
+	return nil
+}
+
+// HasSubscribers tells whether the given topic is subscribed on the external broker.
+func (b *Bridge) HasSubscribers(topic string) bool {
+	return !b.filtered || b.subscriptions.HasSubscribers(topic)
+}
+
+// subscribes to the subscription events of the external broker after (re)connecting.
+// the subscriptions recorded before are dropped, since the external broker might have lost them too.
+func (b *Bridge) onConnect(client paho.Client) {
+	log.Infof("Connected to the external MQTT broker %s", b.brokerURL)
+	b.subscriptions.Reset()
+
+	if b.subscribedTopic != "" {
+		client.Subscribe(b.subscribedTopic, 0, func(_ paho.Client, msg paho.Message) {
+			if topic := parseSubscriptionEvent(msg.Payload()); topic != "" {
+				b.subscriptions.Subscribed(topic)
+			}
+		})
+	}
+
+	if b.unsubscribedTopic != "" {
+		client.Subscribe(b.unsubscribedTopic, 0, func(_ paho.Client, msg paho.Message) {
+			if topic := parseSubscriptionEvent(msg.Payload()); topic != "" {
+				b.subscriptions.Unsubscribed(topic)
+			}
+		})
+	}
+}
+
+// returns the topic filter of the given subscription event, or an empty string if the event is invalid.
+func parseSubscriptionEvent(payload []byte) string {
+	event := &subscriptionEvent{}
+	if err := json.Unmarshal(payload, event); err != nil {
+		log.Debugf("Invalid subscription event of the external MQTT broker: %s", err)
+		return ""
+	}
+	return event.Topic
+}
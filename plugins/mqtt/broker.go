@@ -45,6 +45,12 @@ func (b *Broker) Shutdown() error {
 	return nil
 }
 
+// HasSubscribers tells whether the given topic should be published.
+// The embedded broker doesn't expose its subscriptions, so only the topics which don't need a filter are published.
+func (b *Broker) HasSubscribers(topic string) bool {
+	return !isFilteredTopic(topic)
+}
+
 // Publish a new list of messages.
 func (b *Broker) Send(topic string, message string) error {
 
@@ -1,6 +1,7 @@
 package mqtt
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/gohornet/hornet/pkg/model/hornet"
 	"github.com/gohornet/hornet/pkg/model/milestone"
 	"github.com/gohornet/hornet/pkg/model/tangle"
+	tanglePlugin "github.com/gohornet/hornet/plugins/tangle"
 )
 
 var (
@@ -93,10 +95,20 @@ func onSpentAddress(addr trinary.Hash) {
 	}
 }
 
+func onConfirmationSummary(summary *tanglePlugin.ConfirmationSummary) {
+	if err := publishConfSummary(summary); err != nil {
+		log.Warn(err.Error())
+	}
+}
+
 // Publish latest milestone index
 func publishLMI(lmi milestone.Index) error {
+	if !mqttPublisher.HasSubscribers(topicLMI) {
+		prevLMI = lmi
+		return nil
+	}
 
-	err := mqttBroker.Send(topicLMI, fmt.Sprintf(`{"prevLMI":%d,"lmi":%d,"timestamp":"%s"}`,
+	err := mqttPublisher.Send(topicLMI, fmt.Sprintf(`{"prevLMI":%d,"lmi":%d,"timestamp":"%s"}`,
 		prevLMI, // Index of the previous solid subtangle milestone
 		lmi,     // Index of the latest solid subtangle milestone
 		time.Now().UTC().Format(time.RFC3339)))
@@ -109,8 +121,12 @@ func publishLMI(lmi milestone.Index) error {
 
 // Publish latest solid subtangle milestone index
 func publishLMSI(smi milestone.Index) error {
+	if !mqttPublisher.HasSubscribers(topicLMSI) {
+		prevSMI = smi
+		return nil
+	}
 
-	err := mqttBroker.Send(topicLMSI, fmt.Sprintf(`{"prevSMI":%d,"smi":%d,"timestamp":"%s"}`,
+	err := mqttPublisher.Send(topicLMSI, fmt.Sprintf(`{"prevSMI":%d,"smi":%d,"timestamp":"%s"}`,
 		prevSMI, // Index of the previous solid subtangle milestone
 		smi,     // Index of the latest solid subtangle milestone
 		time.Now().UTC().Format(time.RFC3339)))
@@ -123,14 +139,20 @@ func publishLMSI(smi milestone.Index) error {
 
 // Publish latest solid subtangle milestone hash
 func publishLMHS(solidMilestoneHash trinary.Hash) error {
-	return mqttBroker.Send(topicLMHS, fmt.Sprintf(`{"solidMilestoneHash":"%v","timestamp":"%s"}`,
+	if !mqttPublisher.HasSubscribers(topicLMHS) {
+		return nil
+	}
+	return mqttPublisher.Send(topicLMHS, fmt.Sprintf(`{"solidMilestoneHash":"%v","timestamp":"%s"}`,
 		solidMilestoneHash, // Solid milestone transaction hash
 		time.Now().UTC().Format(time.RFC3339)))
 }
 
 // Publish latest milestone
 func publishLM(bndl *tangle.Bundle) error {
-	return mqttBroker.Send(topicLM, fmt.Sprintf(`{"index":%d,"hash":"%v","timestamp":"%s"}`,
+	if !mqttPublisher.HasSubscribers(topicLM) {
+		return nil
+	}
+	return mqttPublisher.Send(topicLM, fmt.Sprintf(`{"index":%d,"hash":"%v","timestamp":"%s"}`,
 		bndl.GetMilestoneIndex(),         // Milestone transaction index
 		bndl.GetMilestoneHash().Trytes(), // Milestone transaction hash
 		time.Now().UTC().Format(time.RFC3339)))
@@ -138,7 +160,10 @@ func publishLM(bndl *tangle.Bundle) error {
 
 // Publish latest solid subtangle milestone
 func publishLSM(bndl *tangle.Bundle) error {
-	return mqttBroker.Send(topicLSM, fmt.Sprintf(`{"index":%d,"hash":"%v","timestamp":"%s"}`,
+	if !mqttPublisher.HasSubscribers(topicLSM) {
+		return nil
+	}
+	return mqttPublisher.Send(topicLSM, fmt.Sprintf(`{"index":%d,"hash":"%v","timestamp":"%s"}`,
 		bndl.GetMilestoneIndex(),         // Solid milestone transaction index
 		bndl.GetMilestoneHash().Trytes(), // Solid milestone transaction hash
 		time.Now().UTC().Format(time.RFC3339)))
@@ -146,8 +171,11 @@ func publishLSM(bndl *tangle.Bundle) error {
 
 // Publish confirmed transaction
 func publishConfTx(iotaTx *transaction.Transaction, msIndex milestone.Index) error {
+	if !mqttPublisher.HasSubscribers(topicSN) {
+		return nil
+	}
 
-	return mqttBroker.Send(topicSN, fmt.Sprintf(`{"msIndex":%d,"txHash":"%v","address":"%v","trunk":"%v","branch":"%v","bundle":"%v","timestamp":"%s"}`,
+	return mqttPublisher.Send(topicSN, fmt.Sprintf(`{"msIndex":%d,"txHash":"%v","address":"%v","trunk":"%v","branch":"%v","bundle":"%v","timestamp":"%s"}`,
 		msIndex,                  // Index of the milestone that confirmed the transaction
 		iotaTx.Hash,              // Transaction hash
 		iotaTx.Address,           // Address
@@ -159,13 +187,16 @@ func publishConfTx(iotaTx *transaction.Transaction, msIndex milestone.Index) err
 
 // Publish confirmed transaction trytes
 func publishConfTrytes(iotaTx *transaction.Transaction, msIndex milestone.Index) error {
+	if !mqttPublisher.HasSubscribers(topicConfTrytes) {
+		return nil
+	}
 
 	trytes, err := transaction.TransactionToTrytes(iotaTx)
 	if err != nil {
 		return err
 	}
 
-	return mqttBroker.Send(topicConfTrytes, fmt.Sprintf(`{"txHash":"%v","trytes":"%v","msIndex":%d,"timestamp":"%s"}`,
+	return mqttPublisher.Send(topicConfTrytes, fmt.Sprintf(`{"txHash":"%v","trytes":"%v","msIndex":%d,"timestamp":"%s"}`,
 		iotaTx.Hash, // Transaction hash
 		trytes,      // Transaction trytes
 		msIndex,     // Index of the milestone that confirmed the transaction
@@ -175,23 +206,36 @@ func publishConfTrytes(iotaTx *transaction.Transaction, msIndex milestone.Index)
 
 // Publish transaction trytes of an tx that has recently been added to the ledger
 func publishTxTrytes(iotaTx *transaction.Transaction) error {
+	if !mqttPublisher.HasSubscribers(topicTxTrytes) {
+		return nil
+	}
 
 	trytes, err := transaction.TransactionToTrytes(iotaTx)
 	if err != nil {
 		return err
 	}
 
-	err = mqttBroker.Send(topicTxTrytes, fmt.Sprintf(`{"txHash":"%v","trytes":"%v","timestamp":"%s"}`,
+	err = mqttPublisher.Send(topicTxTrytes, fmt.Sprintf(`{"txHash":"%v","trytes":"%v","timestamp":"%s"}`,
 		iotaTx.Hash, // Transaction hash
 		trytes,      // Transaction trytes
 		time.Now().UTC().Format(time.RFC3339)))
 	return err
 }
 
-// Publish a transaction that has recently been added to the ledger
+// Publish a transaction that has recently been added to the ledger.
+// The transaction is published to the topics of its address and tag as well, if they are subscribed.
 func publishTx(iotaTx *transaction.Transaction) error {
+	topics := make([]string, 0, 3)
+	for _, topic := range []string{topicTX, topicPrefixAddress + iotaTx.Address, topicPrefixTag + iotaTx.Tag} {
+		if mqttPublisher.HasSubscribers(topic) {
+			topics = append(topics, topic)
+		}
+	}
+	if len(topics) == 0 {
+		return nil
+	}
 
-	return mqttBroker.Send(topicTX, fmt.Sprintf(`{"txHash":"%v","address":"%v","value":%d,"obsoleteTag":"%v","txTimestamp":%d,"currentIndex":%d,"lastIndex":%d,"bundle":"%v","trunk":"%v","branch":"%v","recTimestamp":%d,"tag":"%v","timestamp":"%s"}`,
+	message := fmt.Sprintf(`{"txHash":"%v","address":"%v","value":%d,"obsoleteTag":"%v","txTimestamp":%d,"currentIndex":%d,"lastIndex":%d,"bundle":"%v","trunk":"%v","branch":"%v","recTimestamp":%d,"tag":"%v","timestamp":"%s"}`,
 		iotaTx.Hash,              // Transaction hash
 		iotaTx.Address,           // Address
 		iotaTx.Value,             // Value
@@ -204,9 +248,31 @@ func publishTx(iotaTx *transaction.Transaction) error {
 		iotaTx.BranchTransaction, // Branch transaction hash
 		time.Now().Unix(),        // Unix timestamp for when the transaction was received
 		iotaTx.Tag,               // Tag
-		time.Now().UTC().Format(time.RFC3339)))
+		time.Now().UTC().Format(time.RFC3339))
+
+	for _, topic := range topics {
+		if err := mqttPublisher.Send(topic, message); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func publishSpentAddress(addr trinary.Hash) error {
-	return mqttBroker.Send(topicSpentAddress, addr)
+	if !mqttPublisher.HasSubscribers(topicSpentAddress) {
+		return nil
+	}
+	return mqttPublisher.Send(topicSpentAddress, addr)
+}
+
+// Publish the confirmation summary of a milestone
+func publishConfSummary(summary *tanglePlugin.ConfirmationSummary) error {
+	if !mqttPublisher.HasSubscribers(topicConfSummary) {
+		return nil
+	}
+	summaryJSON, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+	return mqttPublisher.Send(topicConfSummary, string(summaryJSON))
 }
@@ -3,15 +3,15 @@ package mqtt
 import (
 	"github.com/iotaledger/iota.go/trinary"
 
-	"github.com/iotaledger/hive.go/daemon"
-	"github.com/iotaledger/hive.go/events"
 	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/hive.go/node"
 	"github.com/iotaledger/hive.go/workerpool"
 
+	"github.com/gohornet/hornet/pkg/config"
 	"github.com/gohornet/hornet/pkg/model/milestone"
 	tanglePackage "github.com/gohornet/hornet/pkg/model/tangle"
 	"github.com/gohornet/hornet/pkg/shutdown"
+	"github.com/gohornet/hornet/pkg/supervisor"
 	"github.com/gohornet/hornet/plugins/tangle"
 )
 
@@ -40,53 +40,86 @@ var (
 	spentAddressWorkerQueueSize = 1000
 	spentAddressWorkerPool      *workerpool.WorkerPool
 
+	confSummaryWorkerCount     = 1
+	confSummaryWorkerQueueSize = 100
+	confSummaryWorkerPool      *workerpool.WorkerPool
+
 	wasSyncBefore = false
 
-	mqttBroker *Broker
+	mqttPublisher publisher
 )
 
+// publishes the messages of the plugin to an MQTT broker.
+type publisher interface {
+	Start() error
+	Shutdown() error
+	Send(topic string, message string) error
+	// HasSubscribers tells whether the given topic should be published.
+	HasSubscribers(topic string) bool
+}
+
 // Configure the MQTT plugin
 func configure(plugin *node.Plugin) {
 	log = logger.NewLogger(plugin.Name)
 
 	newTxWorkerPool = workerpool.New(func(task workerpool.Task) {
-		onNewTx(task.Param(0).(*tanglePackage.CachedTransaction)) // tx pass +1
+		supervisor.Protect(plugin.Name, "MQTT[NewTxWorker]", func() {
+			onNewTx(task.Param(0).(*tanglePackage.CachedTransaction)) // tx pass +1
+		})
 		task.Return(nil)
 	}, workerpool.WorkerCount(newTxWorkerCount), workerpool.QueueSize(newTxWorkerQueueSize), workerpool.FlushTasksAtShutdown(true))
 
 	confirmedTxWorkerPool = workerpool.New(func(task workerpool.Task) {
-		onConfirmedTx(task.Param(0).(*tanglePackage.CachedMetadata), task.Param(1).(milestone.Index), task.Param(2).(int64)) // meta pass +1
+		supervisor.Protect(plugin.Name, "MQTT[ConfirmedTxWorker]", func() {
+			onConfirmedTx(task.Param(0).(*tanglePackage.CachedMetadata), task.Param(1).(milestone.Index), task.Param(2).(int64)) // meta pass +1
+		})
 		task.Return(nil)
 	}, workerpool.WorkerCount(confirmedTxWorkerCount), workerpool.QueueSize(confirmedTxWorkerQueueSize), workerpool.FlushTasksAtShutdown(true))
 
 	newLatestMilestoneWorkerPool = workerpool.New(func(task workerpool.Task) {
-		onNewLatestMilestone(task.Param(0).(*tanglePackage.CachedBundle)) // bundle pass +1
+		supervisor.Protect(plugin.Name, "MQTT[NewLatestMilestoneWorker]", func() {
+			onNewLatestMilestone(task.Param(0).(*tanglePackage.CachedBundle)) // bundle pass +1
+		})
 		task.Return(nil)
 	}, workerpool.WorkerCount(newLatestMilestoneWorkerCount), workerpool.QueueSize(newLatestMilestoneWorkerQueueSize), workerpool.FlushTasksAtShutdown(true))
 
 	newSolidMilestoneWorkerPool = workerpool.New(func(task workerpool.Task) {
-		onNewSolidMilestone(task.Param(0).(*tanglePackage.CachedBundle)) // bundle pass +1
+		supervisor.Protect(plugin.Name, "MQTT[NewSolidMilestoneWorker]", func() {
+			onNewSolidMilestone(task.Param(0).(*tanglePackage.CachedBundle)) // bundle pass +1
+		})
 		task.Return(nil)
 	}, workerpool.WorkerCount(newSolidMilestoneWorkerCount), workerpool.QueueSize(newSolidMilestoneWorkerQueueSize), workerpool.FlushTasksAtShutdown(true))
 
 	spentAddressWorkerPool = workerpool.New(func(task workerpool.Task) {
-		onSpentAddress(task.Param(0).(trinary.Hash))
+		supervisor.Protect(plugin.Name, "MQTT[SpentAddressWorker]", func() {
+			onSpentAddress(task.Param(0).(trinary.Hash))
+		})
 		task.Return(nil)
 	}, workerpool.WorkerCount(spentAddressWorkerCount), workerpool.QueueSize(spentAddressWorkerQueueSize))
 
-	var err error
-	mqttBroker, err = NewBroker()
+	confSummaryWorkerPool = workerpool.New(func(task workerpool.Task) {
+		supervisor.Protect(plugin.Name, "MQTT[ConfSummaryWorker]", func() {
+			onConfirmationSummary(task.Param(0).(*tangle.ConfirmationSummary))
+		})
+		task.Return(nil)
+	}, workerpool.WorkerCount(confSummaryWorkerCount), workerpool.QueueSize(confSummaryWorkerQueueSize))
+
+	if config.NodeConfig.GetBool(config.CfgMQTTBridgeEnabled) {
+		mqttPublisher = NewBridge()
+		return
+	}
+
+	broker, err := NewBroker()
 	if err != nil {
 		log.Fatalf("MQTT broker init failed! %v", err)
 	}
+	mqttPublisher = broker
 }
 
 // Start the MQTT plugin
 func run(plugin *node.Plugin) {
 
-	log.Infof("Starting MQTT Broker (port %s) ...", mqttBroker.config.Port)
-
-	onReceivedNewTransaction := events.NewClosure(func(cachedTx *tanglePackage.CachedTransaction, latestMilestoneIndex milestone.Index, latestSolidMilestoneIndex milestone.Index) {
+	onReceivedNewTransaction := supervisor.NewClosure(plugin.Name, "MQTT[onReceivedNewTransaction]", func(cachedTx *tanglePackage.CachedTransaction, latestMilestoneIndex milestone.Index, latestSolidMilestoneIndex milestone.Index) {
 		if !wasSyncBefore {
 			if !tanglePackage.IsNodeSyncedWithThreshold() {
 				cachedTx.Release(true) // tx -1
@@ -101,7 +134,7 @@ func run(plugin *node.Plugin) {
 		cachedTx.Release(true) // tx -1
 	})
 
-	onTransactionConfirmed := events.NewClosure(func(cachedMeta *tanglePackage.CachedMetadata, msIndex milestone.Index, confTime int64) {
+	onTransactionConfirmed := supervisor.NewClosure(plugin.Name, "MQTT[onTransactionConfirmed]", func(cachedMeta *tanglePackage.CachedMetadata, msIndex milestone.Index, confTime int64) {
 		if !wasSyncBefore {
 			// Not sync
 			cachedMeta.Release(true) // meta -1
@@ -116,7 +149,7 @@ func run(plugin *node.Plugin) {
 		cachedMeta.Release(true) // meta -1
 	})
 
-	onLatestMilestoneChanged := events.NewClosure(func(cachedBndl *tanglePackage.CachedBundle) {
+	onLatestMilestoneChanged := supervisor.NewClosure(plugin.Name, "MQTT[onLatestMilestoneChanged]", func(cachedBndl *tanglePackage.CachedBundle) {
 		if !wasSyncBefore {
 			// Not sync
 			cachedBndl.Release(true) // bundle -1
@@ -129,7 +162,7 @@ func run(plugin *node.Plugin) {
 		cachedBndl.Release(true) // bundle -1
 	})
 
-	onSolidMilestoneChanged := events.NewClosure(func(cachedBndl *tanglePackage.CachedBundle) {
+	onSolidMilestoneChanged := supervisor.NewClosure(plugin.Name, "MQTT[onSolidMilestoneChanged]", func(cachedBndl *tanglePackage.CachedBundle) {
 		if !wasSyncBefore {
 			// Not sync
 			cachedBndl.Release(true) // bundle -1
@@ -142,45 +175,31 @@ func run(plugin *node.Plugin) {
 		cachedBndl.Release(true) // bundle -1
 	})
 
-	onAddressSpent := events.NewClosure(func(addr trinary.Hash) {
+	onAddressSpent := supervisor.NewClosure(plugin.Name, "MQTT[onAddressSpent]", func(addr trinary.Hash) {
 		spentAddressWorkerPool.TrySubmit(addr)
 	})
 
-	daemon.BackgroundWorker("MQTT Broker", func(shutdownSignal <-chan struct{}) {
-		go func() {
-			if err := startBroker(plugin); err != nil {
-				log.Errorf("Stopping MQTT Broker: %s", err.Error())
-			} else {
-				log.Infof("Starting MQTT Broker (port %s) ... done", mqttBroker.config.Port)
-			}
-
-		}()
-
-		if mqttBroker.config.Port != "" {
-			log.Infof("You can now listen to MQTT via: http://%s:%s", mqttBroker.config.Host, mqttBroker.config.Port)
-		}
-
-		if mqttBroker.config.TlsPort != "" {
-			log.Infof("You can now listen to MQTT via: https://%s:%s", mqttBroker.config.TlsHost, mqttBroker.config.TlsPort)
+	onConfirmationSummary := supervisor.NewClosure(plugin.Name, "MQTT[onConfirmationSummary]", func(summary *tangle.ConfirmationSummary) {
+		if !wasSyncBefore {
+			// Not sync
+			return
 		}
+		confSummaryWorkerPool.TrySubmit(summary)
+	})
 
-		<-shutdownSignal
-		log.Info("Stopping MQTT Broker ...")
-
-		if err := mqttBroker.Shutdown(); err != nil {
-			log.Errorf("Stopping MQTT Broker: %s", err.Error())
-		} else {
-			log.Info("Stopping MQTT Broker ... done")
-		}
-	}, shutdown.PriorityMetricsPublishers)
+	if bridge, ok := mqttPublisher.(*Bridge); ok {
+		runBridge(plugin, bridge)
+	} else {
+		runBroker(plugin, mqttPublisher.(*Broker))
+	}
 
 	/*
-		daemon.BackgroundWorker("MQTT address topic updater", func(shutdownSignal <-chan struct{}) {
+		supervisor.BackgroundWorker(plugin.Name, "MQTT address topic updater", func(shutdownSignal <-chan struct{}) {
 			timeutil.Ticker(updateAddressTopics, 5*time.Second, shutdownSignal)
 		}, shutdown.PriorityMetricsPublishers)
 	*/
 
-	daemon.BackgroundWorker("MQTT[NewTxWorker]", func(shutdownSignal <-chan struct{}) {
+	supervisor.BackgroundWorker(plugin.Name, "MQTT[NewTxWorker]", func(shutdownSignal <-chan struct{}) {
 		log.Info("Starting MQTT[NewTxWorker] ... done")
 		tangle.Events.ReceivedNewTransaction.Attach(onReceivedNewTransaction)
 		newTxWorkerPool.Start()
@@ -190,7 +209,7 @@ func run(plugin *node.Plugin) {
 		log.Info("Stopping MQTT[NewTxWorker] ... done")
 	}, shutdown.PriorityMetricsPublishers)
 
-	daemon.BackgroundWorker("MQTT[ConfirmedTxWorker]", func(shutdownSignal <-chan struct{}) {
+	supervisor.BackgroundWorker(plugin.Name, "MQTT[ConfirmedTxWorker]", func(shutdownSignal <-chan struct{}) {
 		log.Info("Starting MQTT[ConfirmedTxWorker] ... done")
 		tangle.Events.TransactionConfirmed.Attach(onTransactionConfirmed)
 		confirmedTxWorkerPool.Start()
@@ -200,7 +219,7 @@ func run(plugin *node.Plugin) {
 		log.Info("Stopping MQTT[ConfirmedTxWorker] ... done")
 	}, shutdown.PriorityMetricsPublishers)
 
-	daemon.BackgroundWorker("MQTT[NewLatestMilestoneWorker]", func(shutdownSignal <-chan struct{}) {
+	supervisor.BackgroundWorker(plugin.Name, "MQTT[NewLatestMilestoneWorker]", func(shutdownSignal <-chan struct{}) {
 		log.Info("Starting MQTT[NewLatestMilestoneWorker] ... done")
 		tangle.Events.LatestMilestoneChanged.Attach(onLatestMilestoneChanged)
 		newLatestMilestoneWorkerPool.Start()
@@ -210,7 +229,7 @@ func run(plugin *node.Plugin) {
 		log.Info("Stopping MQTT[NewLatestMilestoneWorker] ... done")
 	}, shutdown.PriorityMetricsPublishers)
 
-	daemon.BackgroundWorker("MQTT[NewSolidMilestoneWorker]", func(shutdownSignal <-chan struct{}) {
+	supervisor.BackgroundWorker(plugin.Name, "MQTT[NewSolidMilestoneWorker]", func(shutdownSignal <-chan struct{}) {
 		log.Info("Starting MQTT[NewSolidMilestoneWorker] ... done")
 		tangle.Events.SolidMilestoneChanged.Attach(onSolidMilestoneChanged)
 		newSolidMilestoneWorkerPool.Start()
@@ -220,7 +239,7 @@ func run(plugin *node.Plugin) {
 		log.Info("Stopping MQTT[NewSolidMilestoneWorker] ... done")
 	}, shutdown.PriorityMetricsPublishers)
 
-	daemon.BackgroundWorker("MQTT[SpentAddress]", func(shutdownSignal <-chan struct{}) {
+	supervisor.BackgroundWorker(plugin.Name, "MQTT[SpentAddress]", func(shutdownSignal <-chan struct{}) {
 		log.Info("Starting MQTT[SpentAddress] ... done")
 		tanglePackage.Events.AddressSpent.Attach(onAddressSpent)
 		spentAddressWorkerPool.Start()
@@ -230,9 +249,67 @@ func run(plugin *node.Plugin) {
 		spentAddressWorkerPool.StopAndWait()
 		log.Info("Stopping MQTT[SpentAddress] ... done")
 	}, shutdown.PriorityMetricsPublishers)
+
+	supervisor.BackgroundWorker(plugin.Name, "MQTT[ConfirmationSummaryWorker]", func(shutdownSignal <-chan struct{}) {
+		log.Info("Starting MQTT[ConfirmationSummaryWorker] ... done")
+		tangle.Events.ConfirmationSummary.Attach(onConfirmationSummary)
+		confSummaryWorkerPool.Start()
+		<-shutdownSignal
+		tangle.Events.ConfirmationSummary.Detach(onConfirmationSummary)
+		confSummaryWorkerPool.StopAndWait()
+		log.Info("Stopping MQTT[ConfirmationSummaryWorker] ... done")
+	}, shutdown.PriorityMetricsPublishers)
 }
 
-// Start the mqtt broker.
-func startBroker(_ *node.Plugin) error {
-	return mqttBroker.Start()
+// runs the embedded broker.
+func runBroker(plugin *node.Plugin, broker *Broker) {
+	log.Infof("Starting MQTT Broker (port %s) ...", broker.config.Port)
+
+	supervisor.BackgroundWorker(plugin.Name, "MQTT Broker", func(shutdownSignal <-chan struct{}) {
+		go func() {
+			if err := broker.Start(); err != nil {
+				log.Errorf("Stopping MQTT Broker: %s", err.Error())
+			} else {
+				log.Infof("Starting MQTT Broker (port %s) ... done", broker.config.Port)
+			}
+
+		}()
+
+		if broker.config.Port != "" {
+			log.Infof("You can now listen to MQTT via: http://%s:%s", broker.config.Host, broker.config.Port)
+		}
+
+		if broker.config.TlsPort != "" {
+			log.Infof("You can now listen to MQTT via: https://%s:%s", broker.config.TlsHost, broker.config.TlsPort)
+		}
+
+		<-shutdownSignal
+		log.Info("Stopping MQTT Broker ...")
+
+		if err := broker.Shutdown(); err != nil {
+			log.Errorf("Stopping MQTT Broker: %s", err.Error())
+		} else {
+			log.Info("Stopping MQTT Broker ... done")
+		}
+	}, shutdown.PriorityMetricsPublishers)
+}
+
+// runs the bridge to the external broker.
+func runBridge(plugin *node.Plugin, bridge *Bridge) {
+	log.Infof("Connecting to the external MQTT broker %s ...", bridge.brokerURL)
+
+	supervisor.BackgroundWorker(plugin.Name, "MQTT Bridge", func(shutdownSignal <-chan struct{}) {
+		if err := bridge.Start(); err != nil {
+			log.Warnf("Connecting to the external MQTT broker %s failed, retrying in the background: %s", bridge.brokerURL, err)
+		}
+
+		<-shutdownSignal
+		log.Info("Stopping MQTT Bridge ...")
+
+		if err := bridge.Shutdown(); err != nil {
+			log.Errorf("Stopping MQTT Bridge: %s", err.Error())
+		} else {
+			log.Info("Stopping MQTT Bridge ... done")
+		}
+	}, shutdown.PriorityMetricsPublishers)
 }
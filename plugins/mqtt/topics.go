@@ -1,5 +1,9 @@
 package mqtt
 
+import (
+	"strings"
+)
+
 // Topic names
 const (
 	topicLMI          = "lmi"
@@ -12,9 +16,19 @@ const (
 	topicTxTrytes     = "trytes"
 	topicTX           = "tx"
 	topicSpentAddress = "spent_address"
-	//topicPrefixAddress = "addr/"
+	topicConfSummary  = "conf_summary"
+
+	// the transactions of an address, only published to subscribed topics of an external broker
+	topicPrefixAddress = "addr/"
+	// the transactions with a tag, only published to subscribed topics of an external broker
+	topicPrefixTag = "tag/"
 )
 
+// tells whether the given topic is only published if it is subscribed.
+func isFilteredTopic(topic string) bool {
+	return strings.HasPrefix(topic, topicPrefixAddress) || strings.HasPrefix(topic, topicPrefixTag)
+}
+
 /*
 var (
 	addressTopics AddressTopics
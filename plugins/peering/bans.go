@@ -0,0 +1,61 @@
+package peering
+
+import (
+	"time"
+
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/timeutil"
+
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/peering"
+	"github.com/gohornet/hornet/pkg/shutdown"
+)
+
+// restores the bans persisted before the last shutdown and keeps the persisted bans in sync with the manager.
+func configureBans() {
+	stored, err := tangle.LoadPeerBans()
+	if err != nil {
+		log.Warnf("restoring the peer bans failed: %s", err)
+	}
+
+	bans := make([]*peering.Ban, 0, len(stored))
+	for _, ban := range stored {
+		bans = append(bans, &peering.Ban{
+			PeerID: ban.PeerID,
+			Reason: ban.Reason,
+			Since:  time.Unix(ban.Since, 0),
+			Until:  time.Unix(ban.Until, 0),
+		})
+	}
+	manager.RestoreBans(bans)
+
+	manager.Events.PeerBanned.Attach(events.NewClosure(func(ban *peering.Ban) {
+		log.Warnf("banned %s until %s: %s", ban.PeerID, ban.Until.Format(time.RFC3339), ban.Reason)
+
+		if err := tangle.StorePeerBan(&tangle.PeerBan{
+			PeerID: ban.PeerID,
+			Reason: ban.Reason,
+			Since:  ban.Since.Unix(),
+			Until:  ban.Until.Unix(),
+		}); err != nil {
+			log.Warnf("storing the ban of %s failed: %s", ban.PeerID, err)
+		}
+	}))
+
+	manager.Events.PeerUnbanned.Attach(events.NewClosure(func(ban *peering.Ban) {
+		log.Infof("lifted the ban of %s", ban.PeerID)
+
+		if err := tangle.DeletePeerBan(ban.PeerID); err != nil {
+			log.Warnf("deleting the ban of %s failed: %s", ban.PeerID, err)
+		}
+	}))
+}
+
+func runBans() {
+	daemon.BackgroundWorker("Peering[Bans]", func(shutdownSignal <-chan struct{}) {
+		// lift the bans which expired while the node was offline
+		manager.ExpireBans()
+		timeutil.Ticker(manager.ExpireBans, peering.BanCheckInterval, shutdownSignal)
+	}, shutdown.PriorityPeerReconnecter)
+}
@@ -0,0 +1,69 @@
+package peering
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/hive.go/logger"
+
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/peering"
+	"github.com/gohornet/hornet/pkg/profile"
+)
+
+// returns the stored bans by the IDs of the banned peers.
+func storedBans(t *testing.T) map[string]*tangle.PeerBan {
+	stored, err := tangle.LoadPeerBans()
+	require.NoError(t, err)
+
+	bans := make(map[string]*tangle.PeerBan, len(stored))
+	for _, ban := range stored {
+		bans[ban.PeerID] = ban
+	}
+	return bans
+}
+
+func TestBansPersistence(t *testing.T) {
+	tangle.ConfigureStorages(mapdb.NewMapDB(), mapdb.NewMapDB(), mapdb.NewMapDB(), profile.Caches{})
+	defer tangle.ShutdownStorages()
+
+	log = logger.NewExampleLogger(PLUGIN.Name)
+	defer func() { log, manager = nil, nil }()
+
+	// the bans persisted before the restart
+	now := time.Now()
+	require.NoError(t, tangle.StorePeerBan(&tangle.PeerBan{PeerID: "127.0.0.1:15600", Reason: "spam", Since: now.Add(-time.Hour).Unix(), Until: now.Add(time.Hour).Unix()}))
+	require.NoError(t, tangle.StorePeerBan(&tangle.PeerBan{PeerID: "127.0.0.1:15601", Reason: "spam", Since: now.Add(-time.Hour).Unix(), Until: now.Add(-time.Minute).Unix()}))
+
+	manager = peering.NewManager(peering.Options{})
+	configureBans()
+
+	// the bans are restored
+	assert.True(t, manager.Banned("127.0.0.1:15600"))
+	assert.False(t, manager.Banned("127.0.0.1:15601"))
+	bans := manager.Bans()
+	require.Len(t, bans, 1)
+	assert.Equal(t, "spam", bans[0].Reason)
+	assert.Equal(t, now.Add(time.Hour).Unix(), bans[0].Until.Unix())
+
+	// the bans which expired while the node was offline are deleted
+	manager.ExpireBans()
+	assert.NotContains(t, storedBans(t), "127.0.0.1:15601")
+
+	// new bans are stored
+	require.NoError(t, manager.Ban("127.0.0.1:15602", time.Hour, "invalid transactions"))
+	stored := storedBans(t)
+	require.Contains(t, stored, "127.0.0.1:15602")
+	assert.Equal(t, "invalid transactions", stored["127.0.0.1:15602"].Reason)
+	assert.Equal(t, manager.Bans()[1].Until.Unix(), stored["127.0.0.1:15602"].Until)
+
+	// lifted bans are deleted
+	require.NoError(t, manager.Unban("127.0.0.1:15600"))
+	stored = storedBans(t)
+	assert.NotContains(t, stored, "127.0.0.1:15600")
+	assert.Len(t, stored, 1)
+}
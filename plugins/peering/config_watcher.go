@@ -5,6 +5,8 @@ import (
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/peering"
+	"github.com/gohornet/hornet/pkg/peering/peer"
 )
 
 func configurePeerConfigWatcher() {
@@ -24,6 +26,16 @@ func runConfigWatcher() {
 			Manager().Opts.AcceptAnyPeer = acceptAnyPeer
 		}
 
+		// the allow and deny lists of the connections
+		if gaterRules, err := gaterRulesFromConfig(); err != nil {
+			log.Warnf("keeping the previous allow and deny lists, the changed ones are invalid: %s", err)
+		} else {
+			Manager().SetGaterRules(gaterRules)
+		}
+
+		// the backup peers are added and removed depending on their primaries
+		setBackupPeersFromConfig()
+
 		modified, added, removed := getPeerConfigDiff()
 
 		// remove peers if we do not accept connections from unknown peers
@@ -64,6 +76,26 @@ func runConfigWatcher() {
 	})
 }
 
+// creates the rules of the allowed connections from the allow and deny lists of the peering config.
+func gaterRulesFromConfig() (*peering.GaterRules, error) {
+	return peering.NewGaterRules(
+		config.PeeringConfig.GetStringSlice(config.CfgPeeringAllowedIPs),
+		config.PeeringConfig.GetStringSlice(config.CfgPeeringDeniedIPs),
+		config.PeeringConfig.GetStringSlice(config.CfgPeeringAllowedPeers),
+		config.PeeringConfig.GetStringSlice(config.CfgPeeringDeniedPeers),
+	)
+}
+
+// replaces the backup peers of the manager with the ones of the peering config.
+func setBackupPeersFromConfig() {
+	var configPeers []*config.PeerConfig
+	if err := config.PeeringConfig.UnmarshalKey(config.CfgPeers, &configPeers); err != nil {
+		log.Warn(err)
+		return
+	}
+	Manager().SetBackupPeers(configPeers)
+}
+
 // calculates the diffs between the loaded peers and the modified config.
 func getPeerConfigDiff() (modified, added, removed []config.PeerConfig) {
 	currentPeers := Manager().PeerInfos()
@@ -81,9 +113,14 @@ func getPeerConfigDiff() (modified, added, removed []config.PeerConfig) {
 
 		found := false
 		for _, configPeer := range configPeers {
-			if strings.EqualFold(currentPeer.Address, configPeer.ID) || strings.EqualFold(currentPeer.DomainWithPort, configPeer.ID) {
+			transport, configAddr := peer.ParseTransportAddress(configPeer.ID)
+			if strings.EqualFold(currentPeer.Address, configAddr) || strings.EqualFold(currentPeer.DomainWithPort, configAddr) {
 				found = true
-				if (currentPeer.PreferIPv6 != configPeer.PreferIPv6) || (currentPeer.Alias != configPeer.Alias) {
+				if configPeer.BackupFor != "" {
+					// active backup peers are replaced by SetBackupPeers
+					break
+				}
+				if (currentPeer.PreferIPv6 != configPeer.PreferIPv6) || (currentPeer.Alias != configPeer.Alias) || (currentPeer.ConnectionType != transport.String()) {
 					modified = append(modified, configPeer)
 				}
 				break
@@ -97,8 +134,8 @@ func getPeerConfigDiff() (modified, added, removed []config.PeerConfig) {
 
 	for _, configPeer := range configPeers {
 
-		// ignore the example peer
-		if configPeer.ID == ExamplePeerURI {
+		// ignore the example peer and the backup peers
+		if configPeer.ID == ExamplePeerURI || configPeer.BackupFor != "" {
 			continue
 		}
 
@@ -110,7 +147,8 @@ func getPeerConfigDiff() (modified, added, removed []config.PeerConfig) {
 				break
 			}
 
-			if strings.EqualFold(currentPeer.Address, configPeer.ID) || strings.EqualFold(currentPeer.DomainWithPort, configPeer.ID) {
+			_, configAddr := peer.ParseTransportAddress(configPeer.ID)
+			if strings.EqualFold(currentPeer.Address, configAddr) || strings.EqualFold(currentPeer.DomainWithPort, configAddr) {
 				found = true
 				break
 			}
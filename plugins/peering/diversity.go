@@ -0,0 +1,33 @@
+package peering
+
+import (
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/geoip"
+	"github.com/gohornet/hornet/pkg/peering"
+)
+
+// returns the configured limits of the autopeered peers sharing a subnet, country or autonomous system.
+func diversityLimitsFromConfig() (peering.DiversityLimits, error) {
+	limits := peering.DiversityLimits{
+		MaxPerSubnet:  config.NodeConfig.GetInt(config.CfgNetAutopeeringDiversityMaxPeersPerSubnet),
+		MaxPerCountry: config.NodeConfig.GetInt(config.CfgNetAutopeeringDiversityMaxPeersPerCountry),
+		MaxPerASN:     config.NodeConfig.GetInt(config.CfgNetAutopeeringDiversityMaxPeersPerASN),
+	}
+
+	path := config.NodeConfig.GetString(config.CfgNetAutopeeringDiversityGeoIPDatabasePath)
+	if path == "" {
+		if limits.MaxPerCountry != 0 || limits.MaxPerASN != 0 {
+			log.Warnf("%s is not set, the autopeered peers are not limited per country and ASN", config.CfgNetAutopeeringDiversityGeoIPDatabasePath)
+		}
+		return limits, nil
+	}
+
+	db, err := geoip.Open(path)
+	if err != nil {
+		return limits, err
+	}
+	log.Infof("loaded %d IP ranges of the GeoIP database %s", db.Len(), path)
+	limits.GeoIP = db
+
+	return limits, nil
+}
@@ -0,0 +1,27 @@
+package peering
+
+import (
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/timeutil"
+
+	"github.com/gohornet/hornet/pkg/peering"
+	"github.com/gohornet/hornet/pkg/shutdown"
+)
+
+// logs the backup peers which are added and removed depending on the reachability of their primaries.
+func configureFailover() {
+	manager.Events.FailoverActivated.Attach(events.NewClosure(func(primary string, backup string) {
+		log.Warnf("primary peer %s is unreachable, connecting to its backup peer %s", primary, backup)
+	}))
+
+	manager.Events.FailoverDeactivated.Attach(events.NewClosure(func(primary string, backup string) {
+		log.Infof("primary peer %s is connected again, removed its backup peer %s", primary, backup)
+	}))
+}
+
+func runFailover() {
+	daemon.BackgroundWorker("Peering[Failover]", func(shutdownSignal <-chan struct{}) {
+		timeutil.Ticker(manager.CheckFailover, peering.FailoverCheckInterval, shutdownSignal)
+	}, shutdown.PriorityPeerReconnecter)
+}
@@ -0,0 +1,174 @@
+package peering
+
+import (
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/iputils"
+	"github.com/iotaledger/hive.go/syncutils"
+	"github.com/iotaledger/hive.go/timeutil"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/peering/peer"
+	"github.com/gohornet/hornet/pkg/shutdown"
+)
+
+const (
+	peerStoreFlushInterval = 1 * time.Minute
+)
+
+var (
+	// the stored peers keyed by their address, used to keep the last seen address of disconnected peers.
+	// peers restored with their last seen address are also keyed by it, so that their original address is kept.
+	peerRecords     = make(map[string]*tangle.PeerRecord)
+	peerRecordsLock syncutils.Mutex
+
+	// the amount of peers restored from the database.
+	restoredPeers int
+	// the error which occurred while restoring the peers.
+	restorePeersErr error
+)
+
+// returns the peers which were added at runtime (e.g. via neighbor suggestions) before the last shutdown,
+// so that they are put into the reconnect pool together with the given peers of the peering config.
+// peers whose address can't be resolved anymore are restored with the address they were last seen on.
+func loadStoredPeers(configPeers []*config.PeerConfig) []*config.PeerConfig {
+	records, err := tangle.LoadPeerRecords()
+	if err != nil {
+		restorePeersErr = err
+		return nil
+	}
+
+	configured := make(map[string]struct{}, len(configPeers))
+	for _, p := range configPeers {
+		configured[p.ID] = struct{}{}
+	}
+
+	peerRecordsLock.Lock()
+	defer peerRecordsLock.Unlock()
+
+	var peers []*config.PeerConfig
+	for _, record := range records {
+		if _, isConfigured := configured[record.Address]; isConfigured {
+			continue
+		}
+		peerRecords[record.Address] = record
+
+		id := record.Address
+		_, addr := peer.ParseTransportAddress(id)
+		if originAddr, err := iputils.ParseOriginAddress(addr); err != nil || !resolvable(originAddr.Addr) {
+			if record.LastSeenAddress == "" {
+				continue
+			}
+			id = record.LastSeenAddress
+			peerRecords[id] = record
+		}
+
+		peers = append(peers, &config.PeerConfig{ID: id, Alias: record.Alias, PreferIPv6: record.PreferIPv6})
+	}
+
+	restoredPeers = len(peers)
+	return peers
+}
+
+// tells whether the given host can be resolved to an IP address.
+func resolvable(host string) bool {
+	_, err := iputils.GetIPAddressesFromHost(host)
+	return err == nil
+}
+
+func configurePeerStore() {
+	if !config.NodeConfig.GetBool(config.CfgNetGossipPeerStoreEnabled) {
+		return
+	}
+
+	if restorePeersErr != nil {
+		log.Warnf("restoring the stored peers failed: %s", restorePeersErr)
+		return
+	}
+	if restoredPeers > 0 {
+		log.Infof("restored %d stored peers", restoredPeers)
+	}
+}
+
+func runPeerStore() {
+	if !config.NodeConfig.GetBool(config.CfgNetGossipPeerStoreEnabled) {
+		return
+	}
+
+	daemon.BackgroundWorker("Peering[PeerStore]", func(shutdownSignal <-chan struct{}) {
+		timeutil.Ticker(storePeers, peerStoreFlushInterval, shutdownSignal)
+
+		// store the peers added since the last flush
+		storePeers()
+	}, shutdown.PriorityPeerStore)
+}
+
+// storePeers replaces the stored peers with the static peers the node connects to which are not part of the peering config.
+// inbound peers are not stored, since the node only knows their IP address instead of the address they were added with.
+func storePeers() {
+	var configPeers []*config.PeerConfig
+	if err := config.PeeringConfig.UnmarshalKey(config.CfgPeers, &configPeers); err != nil {
+		log.Warnf("storing the peers failed: %s", err)
+		return
+	}
+
+	configured := make(map[string]struct{}, len(configPeers))
+	for _, p := range configPeers {
+		configured[p.ID] = struct{}{}
+	}
+
+	peerRecordsLock.Lock()
+	defer peerRecordsLock.Unlock()
+
+	now := time.Now().Unix()
+	current := make(map[string]*tangle.PeerRecord)
+	manager.ForAll(func(p *peer.Peer) bool {
+		if p.Autopeering != nil || p.InitAddress == nil {
+			return true
+		}
+
+		connected := p.Conn != nil
+		if connected && p.ConnectionOrigin == peer.Inbound {
+			return true
+		}
+
+		address := p.Transport.Address(p.InitAddress.String())
+		if _, isConfigured := configured[address]; isConfigured {
+			return true
+		}
+
+		record, exists := peerRecords[address]
+		if !exists {
+			record = &tangle.PeerRecord{Address: address, PreferIPv6: p.InitAddress.PreferIPv6}
+		}
+		record.Alias = p.InitAddress.Alias
+
+		if connected && p.Handshaked() {
+			record.LastSeenAddress = p.Transport.Address(net.JoinHostPort(p.PrimaryAddress.String(), strconv.Itoa(int(p.InitAddress.Port))))
+			record.LastSeen = now
+		}
+
+		current[address] = record
+		return true
+	})
+	peerRecords = current
+
+	// peers restored with their last seen address are stored with their original address
+	stored := make(map[string]*tangle.PeerRecord, len(current))
+	for _, record := range current {
+		stored[record.Address] = record
+	}
+
+	records := make([]*tangle.PeerRecord, 0, len(stored))
+	for _, record := range stored {
+		records = append(records, record)
+	}
+
+	if err := tangle.StorePeerRecords(records); err != nil {
+		log.Warnf("storing the peers failed: %s", err)
+	}
+}
@@ -2,6 +2,7 @@ package peering
 
 import (
 	"fmt"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
@@ -20,6 +21,7 @@ import (
 	"github.com/gohornet/hornet/pkg/protocol"
 	"github.com/gohornet/hornet/pkg/protocol/handshake"
 	"github.com/gohornet/hornet/pkg/shutdown"
+	"github.com/gohornet/hornet/plugins/cli"
 )
 
 const (
@@ -44,6 +46,9 @@ func Manager() *peering.Manager {
 			log.Fatalf("couldn't initialize protocol: %s", err)
 		}
 
+		// announce the node info exchange, so that peers of other networks are rejected before any gossip is sent
+		protocol.EnableCapabilities(handshake.CapabilityNodeInfo)
+
 		// load initial config peers
 		var peers []*config.PeerConfig
 		if err := config.PeeringConfig.UnmarshalKey(config.CfgPeers, &peers); err != nil {
@@ -63,20 +68,121 @@ func Manager() *peering.Manager {
 			peers = append(peers, &config.PeerConfig{ID: p})
 		}
 
+		if config.NodeConfig.GetBool(config.CfgNetGossipPeerStoreEnabled) {
+			peers = append(peers, loadStoredPeers(peers)...)
+		}
+
+		sendQueueOverflowPolicy, err := peer.ParseSendQueueOverflowPolicy(config.NodeConfig.GetString(config.CfgNetGossipSendQueueOverflowPolicy))
+		if err != nil {
+			log.Fatalf("couldn't initialize peering: %s", err)
+		}
+
+		gaterRules, err := gaterRulesFromConfig()
+		if err != nil {
+			log.Fatalf("couldn't initialize peering: %s", err)
+		}
+
+		diversityLimits, err := diversityLimitsFromConfig()
+		if err != nil {
+			log.Fatalf("couldn't initialize peering: %s", err)
+		}
+
+		var listenerConfigs []config.ListenerConfig
+		if err := config.NodeConfig.UnmarshalKey(config.CfgNetGossipListeners, &listenerConfigs); err != nil {
+			log.Fatalf("couldn't initialize peering: %s", err)
+		}
+
+		var listeners []string
+		for _, l := range listenerConfigs {
+			if l.Enabled {
+				listeners = append(listeners, l.Address)
+			}
+		}
+
+		gossipRelations := []protocol.Relation{}
+		for _, name := range config.NodeConfig.GetStringSlice(config.CfgNetGossipRelations) {
+			relation, err := protocol.ParseRelation(name)
+			if err != nil {
+				log.Fatalf("couldn't initialize peering: %s", err)
+			}
+			gossipRelations = append(gossipRelations, relation)
+		}
+
 		// init peer manager
 		manager = peering.NewManager(peering.Options{
 			BindAddress: config.NodeConfig.GetString(config.CfgNetGossipBindAddress),
+			Listeners:   listeners,
+			WebSocket: peering.WebSocket{
+				BindAddress: config.NodeConfig.GetString(config.CfgNetGossipWebSocketBindAddress),
+				Path:        config.NodeConfig.GetString(config.CfgNetGossipWebSocketPath),
+				TLSCertPath: config.NodeConfig.GetString(config.CfgNetGossipWebSocketTLSCertPath),
+				TLSKeyPath:  config.NodeConfig.GetString(config.CfgNetGossipWebSocketTLSKeyPath),
+			},
 			ValidHandshake: handshake.Handshake{
 				ByteEncodedCooAddress: cooAddrBytes,
 				MWM:                   byte(mwm),
 			},
+			NodeInfo: handshake.NodeInfo{
+				NetworkID: networkID(),
+				Version:   cli.AppVersion,
+			},
 			MaxConnected:  config.PeeringConfig.GetInt(config.CfgPeeringMaxPeers),
+			MaxAutopeered: config.NodeConfig.GetInt(config.CfgNetAutopeeringInboundPeers) + config.NodeConfig.GetInt(config.CfgNetAutopeeringOutboundPeers),
 			AcceptAnyPeer: config.PeeringConfig.GetBool(config.CfgPeeringAcceptAnyConnection),
+			Gater:         gaterRules,
+			Limits: peering.ResourceLimits{
+				MaxPendingInbound:            config.NodeConfig.GetInt(config.CfgNetGossipLimitsMaxPendingInbound),
+				MaxConnectionsPerIP:          config.NodeConfig.GetInt(config.CfgNetGossipLimitsMaxConnectionsPerIP),
+				MaxSendQueueMemoryBytes:      config.NodeConfig.GetInt64(config.CfgNetGossipLimitsMaxSendQueueMemoryBytes),
+				MaxTotalSendQueueMemoryBytes: config.NodeConfig.GetInt64(config.CfgNetGossipLimitsMaxTotalSendQueueMemoryBytes),
+				MaxInbound:                   config.NodeConfig.GetInt(config.CfgNetGossipLimitsMaxInbound),
+				MaxOutbound:                  config.NodeConfig.GetInt(config.CfgNetGossipLimitsMaxOutbound),
+			},
+			Diversity:               diversityLimits,
+			SendQueueOverflowPolicy: sendQueueOverflowPolicy,
+			SendQueueBlockTimeout:   time.Duration(config.NodeConfig.GetInt(config.CfgNetGossipSendQueueBlockTimeoutMilliseconds)) * time.Millisecond,
+			SendQueueDrainTimeout:   time.Duration(config.NodeConfig.GetInt(config.CfgNetGossipSendQueueDrainTimeoutMilliseconds)) * time.Millisecond,
+			MinScore:                config.NodeConfig.GetFloat64(config.CfgNetGossipScoringMinScore),
+			PreferSyncedPeers:       config.NodeConfig.GetBool(config.CfgNetGossipPreferSyncedPeers),
+			SendQueueWatermarks: peering.SendQueueWatermarks{
+				High: peer.SendQueueSize * config.NodeConfig.GetInt(config.CfgNetGossipSendQueueHighWatermarkPercent) / 100,
+				Low:  peer.SendQueueSize * config.NodeConfig.GetInt(config.CfgNetGossipSendQueueLowWatermarkPercent) / 100,
+			},
+			Deadlines: peering.Deadlines{
+				ReadTimeout:  time.Duration(config.NodeConfig.GetInt(config.CfgNetGossipDeadlinesReadTimeoutSeconds)) * time.Second,
+				WriteTimeout: time.Duration(config.NodeConfig.GetInt(config.CfgNetGossipDeadlinesWriteTimeoutSeconds)) * time.Second,
+				MaxHits:      config.NodeConfig.GetInt(config.CfgNetGossipDeadlinesMaxHits),
+			},
+			Keepalive: peering.Keepalive{
+				Interval: time.Duration(config.NodeConfig.GetInt(config.CfgNetGossipKeepaliveIntervalSeconds)) * time.Second,
+				Timeout:  time.Duration(config.NodeConfig.GetInt(config.CfgNetGossipKeepaliveTimeoutSeconds)) * time.Second,
+			},
+			GossipRelations:          gossipRelations,
+			StaticPeerAlertThreshold: time.Duration(config.NodeConfig.GetInt(config.CfgNetGossipStaticPeerAlertThresholdSeconds)) * time.Second,
+			FailoverDelay:            time.Duration(config.NodeConfig.GetInt(config.CfgNetGossipFailoverDelaySeconds)) * time.Second,
+			Bandwidth: peering.BandwidthLimits{
+				Static: peering.BandwidthLimit{
+					UploadBytesPerSecond:   config.NodeConfig.GetInt(config.CfgNetGossipBandwidthStaticUploadBytesPerSecond),
+					DownloadBytesPerSecond: config.NodeConfig.GetInt(config.CfgNetGossipBandwidthStaticDownloadBytesPerSecond),
+				},
+				Autopeered: peering.BandwidthLimit{
+					UploadBytesPerSecond:   config.NodeConfig.GetInt(config.CfgNetGossipBandwidthAutopeeredUploadBytesPerSecond),
+					DownloadBytesPerSecond: config.NodeConfig.GetInt(config.CfgNetGossipBandwidthAutopeeredDownloadBytesPerSecond),
+				},
+			},
 		}, peers...)
 	})
 	return manager
 }
 
+// networkID returns the configured network ID or derives it from the coordinator address and MWM.
+func networkID() string {
+	if id := config.NodeConfig.GetString(config.CfgNetGossipNetworkID); id != "" {
+		return id
+	}
+	return fmt.Sprintf("%s-%d", config.NodeConfig.GetString(config.CfgCoordinatorAddress), config.NodeConfig.GetInt(config.CfgCoordinatorMWM))
+}
+
 func configure(plugin *node.Plugin) {
 	log = logger.NewLogger(plugin.Name)
 
@@ -88,11 +194,32 @@ func configure(plugin *node.Plugin) {
 
 	// react to peer config changes
 	configurePeerConfigWatcher()
+
+	// persist the traffic of the peers
+	configureTrafficHistory()
+
+	// persist the long-term statistics of the peers
+	configureReputation()
+
+	// persist the peers added at runtime
+	configurePeerStore()
+
+	// persist the bans of the peers
+	configureBans()
+
+	// notify the webhooks about the events of the peers
+	configureWebhooks()
+
+	// alert the static peers which are unreachable for too long
+	configureStaticPeerHealth()
+
+	// connect the backup peers while their primaries are unreachable
+	configureFailover()
 }
 
 func configureManagerEventHandlers() {
 	manager.Events.PeerHandshakingOutgoing.Attach(events.NewClosure(func(p *peer.Peer) {
-		log.Infof("handshaking with %s...", p.ID)
+		log.Infof("handshaking with %s...", p.Name())
 	}))
 
 	manager.Events.PeerHandshakingIncoming.Attach(events.NewClosure(func(addr string) {
@@ -104,12 +231,12 @@ func configureManagerEventHandlers() {
 		if p.Autopeering != nil {
 			autopeeringMeta = fmt.Sprintf(" [autopeered %s]", p.Autopeering.ID())
 		}
-		featureSetMeta := fmt.Sprintf(" [feature set(s): %s]", strings.Join(p.Protocol.SupportedFeatureSets(), ","))
-		var aliasMeta string
-		if len(p.InitAddress.Alias) > 0 {
-			aliasMeta = fmt.Sprintf(" [alias: %s]", p.InitAddress.Alias)
+		featureSetMeta := fmt.Sprintf(" [protocol version: %d, feature set(s): %s]", p.Protocol.Version, strings.Join(p.Protocol.SupportedFeatureSets(), ","))
+		var nodeInfoMeta string
+		if p.NodeInfo != nil {
+			nodeInfoMeta = fmt.Sprintf(" [version: %s]", p.NodeInfo.Version)
 		}
-		log.Infof("connected with %s%s%s%s", p.ID, aliasMeta, featureSetMeta, autopeeringMeta)
+		log.Infof("connected with %s%s%s%s", p.Name(), featureSetMeta, nodeInfoMeta, autopeeringMeta)
 	}))
 
 	manager.Events.PeerMovedIntoReconnectPool.Attach(events.NewClosure(func(addr *iputils.OriginAddress) {
@@ -117,15 +244,15 @@ func configureManagerEventHandlers() {
 	}))
 
 	manager.Events.PeerMovedFromConnectedToReconnectPool.Attach(events.NewClosure(func(p *peer.Peer) {
-		log.Infof("moved disconnected %s into the reconnect pool", p.ID)
+		log.Infof("moved disconnected %s into the reconnect pool", p.Name())
 	}))
 
 	manager.Events.PeerDisconnected.Attach(events.NewClosure(func(p *peer.Peer) {
-		log.Infof("disconnected %s", p.ID)
+		log.Infof("disconnected %s", p.Name())
 	}))
 
 	manager.Events.AutopeeredPeerHandshaking.Attach(events.NewClosure(func(p *peer.Peer) {
-		log.Infof("handshaking with autopeered peer %s / %s", p.ID, p.Autopeering.ID())
+		log.Infof("handshaking with autopeered peer %s / %s", p.Name(), p.Autopeering.ID())
 	}))
 
 	manager.Events.Reconnecting.Attach(events.NewClosure(func(count int32) {
@@ -133,17 +260,69 @@ func configureManagerEventHandlers() {
 	}))
 
 	manager.Events.ReconnectRemovedAlreadyConnected.Attach(events.NewClosure(func(p *peer.Peer) {
-		log.Infof("removed already connected peer %s from reconnect pool", p.ID)
+		log.Infof("removed already connected peer %s from reconnect pool", p.Name())
 	}))
 
 	manager.Events.Error.Attach(events.NewClosure(func(err error) {
 		log.Warnf("error %s", err)
 	}))
+
+	manager.Events.ResourceLimitReached.Attach(events.NewClosure(func(limitReached *peering.ResourceLimitReached) {
+		log.Warnf("resource limit '%s' (%d) reached for %s", limitReached.Resource, limitReached.Limit, limitReached.Remote)
+	}))
+
+	manager.Events.ProtocolTerminated.Attach(events.NewClosure(func(p *peer.Peer, err error) {
+		log.Infof("terminated protocol with %s: %s", p.Name(), err)
+	}))
+
+	manager.Events.ConnectionPruned.Attach(events.NewClosure(func(p *peer.Peer) {
+		log.Infof("pruned connection to unknown peer %s to make room for a known peer", p.Name())
+	}))
+
+	manager.Events.SendQueueCongested.Attach(events.NewClosure(func(p *peer.Peer) {
+		log.Debugf("send queue of %s is congested", p.Name())
+	}))
+
+	manager.Events.SendQueueRelieved.Attach(events.NewClosure(func(p *peer.Peer) {
+		log.Debugf("send queue of %s is relieved", p.Name())
+	}))
+
+	manager.Events.PeerScoreLow.Attach(events.NewClosure(func(p *peer.Peer, score *peer.ScoreInfo) {
+		if p.Autopeering != nil && config.NodeConfig.GetBool(config.CfgNetGossipScoringDropAutopeers) {
+			// peer is connected via autopeering and misbehaves.
+			// it's better to drop the connection and free the slots for other peers.
+			log.Infof("dropping autopeered neighbor %s / %s because of its low score (%0.2f)", p.Autopeering.Address(), p.Autopeering.ID(), score.Score)
+			manager.Remove(p.ID)
+			return
+		}
+		if banDuration := time.Duration(config.NodeConfig.GetInt(config.CfgNetGossipScoringBanDurationSeconds)) * time.Second; banDuration > 0 {
+			if err := manager.Ban(p.ID, banDuration, fmt.Sprintf("low score (%0.2f)", score.Score)); err != nil {
+				log.Warnf("banning %s failed: %s", p.Name(), err)
+			}
+			return
+		}
+		log.Warnf("deprioritized %s because of its low score (%0.2f)", p.Name(), score.Score)
+	}))
+
+	manager.Events.PeerScoreRecovered.Attach(events.NewClosure(func(p *peer.Peer, score *peer.ScoreInfo) {
+		log.Infof("%s is no longer deprioritized, score recovered (%0.2f)", p.Name(), score.Score)
+	}))
+
+	manager.Events.PeerConnectionEnded.Attach(events.NewClosure(func(p *peer.Peer, stats *peer.ConnectionStats) {
+		log.Debugf("connection to %s ended [total uptime: %v, reconnects: %d, latency: %0.1fms]", p.Name(), time.Duration(stats.TotalUptimeSeconds)*time.Second, stats.Reconnects, stats.LatencyMs)
+	}))
 }
 
 func run(_ *node.Plugin) {
 
 	runConfigWatcher()
+	runTrafficHistory()
+	runReputation()
+	runPeerStore()
+	runBans()
+	runWebhooks()
+	runStaticPeerHealth()
+	runFailover()
 
 	peeringBindAddr := config.NodeConfig.GetString(config.CfgNetGossipBindAddress)
 	daemon.BackgroundWorker("Peering Server", func(shutdownSignal <-chan struct{}) {
@@ -154,6 +333,15 @@ func run(_ *node.Plugin) {
 				log.Fatal(err)
 			}
 		}()
+		if webSocketBindAddr := config.NodeConfig.GetString(config.CfgNetGossipWebSocketBindAddress); webSocketBindAddr != "" {
+			log.Infof("Peering WebSocket Server (%s) ...", webSocketBindAddr)
+			go func() {
+				// start listening for incoming WebSocket connections
+				if err := manager.ListenWebSocket(); err != nil && err != http.ErrServerClosed {
+					log.Fatal(err)
+				}
+			}()
+		}
 		log.Infof("Peering Server (%s) ... done", peeringBindAddr)
 		<-shutdownSignal
 		log.Info("Stopping Peering Server ...")
@@ -182,6 +370,14 @@ func run(_ *node.Plugin) {
 		}
 	}, shutdown.PriorityPeerReconnecter)
 
+	daemon.BackgroundWorker("Peering QualitySampler", func(shutdownSignal <-chan struct{}) {
+		timeutil.Ticker(manager.SampleQuality, peering.QualitySampleInterval, shutdownSignal)
+	}, shutdown.PriorityPeerReconnecter)
+
+	daemon.BackgroundWorker("Peering ScoreCheck", func(shutdownSignal <-chan struct{}) {
+		timeutil.Ticker(manager.CheckScores, peering.ScoreCheckInterval, shutdownSignal)
+	}, shutdown.PriorityPeerReconnecter)
+
 	if config.NodeConfig.GetInt(config.CfgNetAutopeeringMaxDroppedPacketsPercentage) != 0 {
 		// create a background worker that checks for staled autopeers every minute
 		daemon.BackgroundWorker("Peering StaleCheck", func(shutdownSignal <-chan struct{}) {
@@ -0,0 +1,157 @@
+package peering
+
+import (
+	"time"
+
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/syncutils"
+	"github.com/iotaledger/hive.go/timeutil"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/peering/peer"
+	"github.com/gohornet/hornet/pkg/shutdown"
+	"github.com/gohornet/hornet/pkg/utils"
+)
+
+const (
+	reputationFlushInterval = 1 * time.Minute
+)
+
+// the counters of a connected peer at the time they were last added to its reputation.
+type reputationSample struct {
+	invalidMessages uint32
+	usefulAnswers   uint32
+	sentRequests    uint32
+	time            time.Time
+}
+
+var (
+	// the samples of the connected peers, keyed by peer instance since every connection starts with fresh counters.
+	reputationSamples     = make(map[*peer.Peer]*reputationSample)
+	reputationSamplesLock syncutils.Mutex
+
+	// the time the reputations were last flushed, used to account the time the disconnected peers are known.
+	lastReputationFlush time.Time
+)
+
+// the key under which the reputation of a peer is stored.
+// the init address is used since it stays the same across reconnects and restarts.
+func reputationKey(p *peer.Peer) string {
+	return p.InitAddress.String()
+}
+
+func configureReputation() {
+	if !config.NodeConfig.GetBool(config.CfgNetGossipReputationEnabled) {
+		return
+	}
+
+	manager.Events.PeerConnected.Attach(events.NewClosure(func(p *peer.Peer) {
+		reputation, err := tangle.GetPeerReputation(reputationKey(p))
+		if err != nil {
+			log.Warnf("loading the reputation of %s failed: %s", p.Name(), err)
+		}
+		if reputation != nil {
+			p.SetReputation(peer.NewReputationInfo(reputation.InvalidMessages, reputation.UsefulAnswers, reputation.SentRequests, reputation.ConnectedSeconds, reputation.KnownSeconds))
+		}
+
+		reputationSamplesLock.Lock()
+		reputationSamples[p] = &reputationSample{time: time.Now()}
+		reputationSamplesLock.Unlock()
+
+		// add the remaining statistics of a peer to its reputation once its connection is closed
+		p.Conn.Events.Close.Attach(events.NewClosure(func() {
+			reputationSamplesLock.Lock()
+			defer reputationSamplesLock.Unlock()
+
+			rollUpReputation(p, time.Now())
+			delete(reputationSamples, p)
+		}))
+	}))
+}
+
+func runReputation() {
+	if !config.NodeConfig.GetBool(config.CfgNetGossipReputationEnabled) {
+		return
+	}
+
+	lastReputationFlush = time.Now()
+
+	daemon.BackgroundWorker("Peering[Reputation]", func(shutdownSignal <-chan struct{}) {
+		timeutil.Ticker(flushReputation, reputationFlushInterval, shutdownSignal)
+
+		// add the statistics since the last flush
+		flushReputation()
+	}, shutdown.PriorityPeerReputation)
+}
+
+// flushReputation adds the statistics of all connected peers since the last flush to their reputation
+// and accounts the time since the last flush to the known time of the disconnected peers.
+func flushReputation() {
+	now := time.Now()
+	sinceLastFlush := now.Sub(lastReputationFlush)
+	lastReputationFlush = now
+
+	var disconnected []string
+
+	reputationSamplesLock.Lock()
+	manager.ForAll(func(p *peer.Peer) bool {
+		if _, connected := reputationSamples[p]; connected {
+			rollUpReputation(p, now)
+			return true
+		}
+		if p.InitAddress != nil {
+			disconnected = append(disconnected, reputationKey(p))
+		}
+		return true
+	})
+	reputationSamplesLock.Unlock()
+
+	known := &tangle.PeerReputation{KnownSeconds: uint64(sinceLastFlush.Seconds())}
+	if known.KnownSeconds == 0 {
+		return
+	}
+
+	for _, key := range disconnected {
+		if err := tangle.AddPeerReputation(key, known); err != nil {
+			log.Warnf("storing the reputation of %s failed: %s", key, err)
+		}
+	}
+}
+
+// rollUpReputation adds the statistics of the given peer since its last sample to its reputation.
+// reputationSamplesLock must be held while calling this function.
+func rollUpReputation(p *peer.Peer, now time.Time) {
+	last, exists := reputationSamples[p]
+	if !exists {
+		return
+	}
+
+	current := &reputationSample{
+		invalidMessages: p.Metrics.InvalidMessages.Load(),
+		usefulAnswers:   p.Metrics.NewTransactions.Load(),
+		sentRequests:    p.Metrics.SentTransactionRequests.Load() + p.Metrics.SentMilestoneRequests.Load(),
+		time:            now,
+	}
+
+	connectedSeconds := uint64(current.time.Sub(last.time).Seconds())
+	if connectedSeconds == 0 && current.invalidMessages == last.invalidMessages &&
+		current.usefulAnswers == last.usefulAnswers && current.sentRequests == last.sentRequests {
+		// keep the old sample, so the connected time isn't lost due to rounding
+		return
+	}
+	reputationSamples[p] = current
+
+	reputation := &tangle.PeerReputation{
+		InvalidMessages:  uint64(utils.GetUint32Diff(current.invalidMessages, last.invalidMessages)),
+		UsefulAnswers:    uint64(utils.GetUint32Diff(current.usefulAnswers, last.usefulAnswers)),
+		SentRequests:     uint64(utils.GetUint32Diff(current.sentRequests, last.sentRequests)),
+		ConnectedSeconds: connectedSeconds,
+		KnownSeconds:     connectedSeconds,
+	}
+
+	if err := tangle.AddPeerReputation(reputationKey(p), reputation); err != nil {
+		log.Warnf("storing the reputation of %s failed: %s", p.Name(), err)
+	}
+}
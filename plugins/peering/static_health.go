@@ -0,0 +1,37 @@
+package peering
+
+import (
+	"time"
+
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/timeutil"
+
+	"github.com/gohornet/hornet/pkg/peering"
+	"github.com/gohornet/hornet/pkg/shutdown"
+)
+
+// logs the outages of the static peers, since losing them silently degrades the node.
+func configureStaticPeerHealth() {
+	if manager.Opts.StaticPeerAlertThreshold == 0 {
+		return
+	}
+
+	manager.Events.StaticPeerUnreachable.Attach(events.NewClosure(func(unreachablePeer *peering.UnreachablePeer) {
+		log.Warnf("static peer %s is unreachable since %s", unreachablePeer.Name(), unreachablePeer.Since.Format(time.RFC3339))
+	}))
+
+	manager.Events.StaticPeerReachable.Attach(events.NewClosure(func(reachablePeer *peering.UnreachablePeer) {
+		log.Infof("static peer %s is reachable again after %v", reachablePeer.Name(), time.Since(reachablePeer.Since).Truncate(time.Second))
+	}))
+}
+
+func runStaticPeerHealth() {
+	if manager.Opts.StaticPeerAlertThreshold == 0 {
+		return
+	}
+
+	daemon.BackgroundWorker("Peering[StaticPeerHealth]", func(shutdownSignal <-chan struct{}) {
+		timeutil.Ticker(manager.CheckStaticPeers, peering.StaticPeerCheckInterval, shutdownSignal)
+	}, shutdown.PriorityStaticPeerHealth)
+}
@@ -0,0 +1,139 @@
+package peering
+
+import (
+	"time"
+
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/syncutils"
+	"github.com/iotaledger/hive.go/timeutil"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/peering/peer"
+	"github.com/gohornet/hornet/pkg/shutdown"
+	"github.com/gohornet/hornet/pkg/utils"
+)
+
+const (
+	trafficHistoryFlushInterval = 1 * time.Minute
+)
+
+// the counters of a peer at the time they were last rolled up.
+type trafficSample struct {
+	bytesRead            uint64
+	bytesWritten         uint64
+	receivedTransactions uint32
+	sentTransactions     uint32
+	sentPackets          uint32
+	droppedPackets       uint32
+}
+
+var (
+	// the samples of the connected peers, keyed by peer instance since every connection starts with fresh counters.
+	trafficSamples     = make(map[*peer.Peer]*trafficSample)
+	trafficSamplesLock syncutils.Mutex
+
+	hourlyTrafficRetention time.Duration
+	dailyTrafficRetention  time.Duration
+	lastTrafficPruning     time.Time
+)
+
+func configureTrafficHistory() {
+	if !config.NodeConfig.GetBool(config.CfgNetGossipTrafficHistoryEnabled) {
+		return
+	}
+
+	hourlyTrafficRetention = time.Duration(config.NodeConfig.GetInt(config.CfgNetGossipTrafficHistoryHourlyRetentionDays)) * 24 * time.Hour
+	dailyTrafficRetention = time.Duration(config.NodeConfig.GetInt(config.CfgNetGossipTrafficHistoryDailyRetentionDays)) * 24 * time.Hour
+
+	// roll up the remaining traffic of a peer once its connection is closed
+	manager.Events.PeerConnected.Attach(events.NewClosure(func(p *peer.Peer) {
+		p.Conn.Events.Close.Attach(events.NewClosure(func() {
+			trafficSamplesLock.Lock()
+			defer trafficSamplesLock.Unlock()
+
+			rollUpTraffic(p, time.Now())
+			delete(trafficSamples, p)
+		}))
+	}))
+}
+
+func runTrafficHistory() {
+	if !config.NodeConfig.GetBool(config.CfgNetGossipTrafficHistoryEnabled) {
+		return
+	}
+
+	daemon.BackgroundWorker("Peering[TrafficHistory]", func(shutdownSignal <-chan struct{}) {
+		timeutil.Ticker(flushTrafficHistory, trafficHistoryFlushInterval, shutdownSignal)
+
+		// roll up the traffic since the last flush
+		flushTrafficHistory()
+	}, shutdown.PriorityPeerTrafficHistory)
+}
+
+// flushTrafficHistory rolls up the traffic of all connected peers since the last flush and prunes outdated rollups.
+func flushTrafficHistory() {
+	now := time.Now()
+
+	trafficSamplesLock.Lock()
+	manager.ForAllConnected(func(p *peer.Peer) bool {
+		rollUpTraffic(p, now)
+		return true
+	})
+	trafficSamplesLock.Unlock()
+
+	if now.Sub(lastTrafficPruning) < time.Hour {
+		return
+	}
+	lastTrafficPruning = now
+
+	if err := tangle.DeletePeerTrafficBefore(tangle.PeerTrafficHourly, now.Add(-hourlyTrafficRetention)); err != nil {
+		log.Warnf("pruning of the hourly peer traffic failed: %s", err)
+	}
+	if err := tangle.DeletePeerTrafficBefore(tangle.PeerTrafficDaily, now.Add(-dailyTrafficRetention)); err != nil {
+		log.Warnf("pruning of the daily peer traffic failed: %s", err)
+	}
+}
+
+// rollUpTraffic adds the traffic of the given peer since its last sample to the hourly and daily rollups.
+// trafficSamplesLock must be held while calling this function.
+func rollUpTraffic(p *peer.Peer, now time.Time) {
+	if p.Conn == nil {
+		return
+	}
+
+	current := &trafficSample{
+		bytesRead:            p.Conn.BytesRead(),
+		bytesWritten:         p.Conn.BytesWritten(),
+		receivedTransactions: p.Metrics.ReceivedTransactions.Load(),
+		sentTransactions:     p.Metrics.SentTransactions.Load(),
+		sentPackets:          p.Metrics.SentPackets.Load(),
+		droppedPackets:       p.Metrics.DroppedPackets.Load(),
+	}
+
+	last, exists := trafficSamples[p]
+	if !exists {
+		last = &trafficSample{}
+	}
+	trafficSamples[p] = current
+
+	traffic := &tangle.PeerTraffic{
+		BytesRead:            current.bytesRead - last.bytesRead,
+		BytesWritten:         current.bytesWritten - last.bytesWritten,
+		ReceivedTransactions: uint64(utils.GetUint32Diff(current.receivedTransactions, last.receivedTransactions)),
+		SentTransactions:     uint64(utils.GetUint32Diff(current.sentTransactions, last.sentTransactions)),
+		SentPackets:          uint64(utils.GetUint32Diff(current.sentPackets, last.sentPackets)),
+		DroppedPackets:       uint64(utils.GetUint32Diff(current.droppedPackets, last.droppedPackets)),
+	}
+
+	if *traffic == (tangle.PeerTraffic{}) {
+		return
+	}
+
+	for _, resolution := range []tangle.PeerTrafficResolution{tangle.PeerTrafficHourly, tangle.PeerTrafficDaily} {
+		if err := tangle.AddPeerTraffic(resolution, now, p.ID, traffic); err != nil {
+			log.Warnf("storing the traffic of %s failed: %s", p.Name(), err)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package peering
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/events"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/peering"
+	"github.com/gohornet/hornet/pkg/peering/peer"
+	"github.com/gohornet/hornet/pkg/protocol"
+	"github.com/gohornet/hornet/pkg/shutdown"
+)
+
+const (
+	// the amount of webhook notifications which are buffered while the webhooks are slow.
+	webhookQueueSize = 100
+
+	webhookEventPeerConnected       = "peerConnected"
+	webhookEventPeerDisconnected    = "peerDisconnected"
+	webhookEventPeerRelationChanged = "peerRelationChanged"
+	webhookEventPeerBanned          = "peerBanned"
+)
+
+var (
+	webhookURLs   []string
+	webhookClient *http.Client
+	webhookQueue  chan *webhookNotification
+)
+
+// webhookNotification is the JSON payload posted to the webhooks.
+type webhookNotification struct {
+	// The type of the event.
+	Event string `json:"event"`
+	// The unix timestamp of the event.
+	Timestamp int64 `json:"timestamp"`
+	// The alias of the node which observed the event.
+	NodeAlias string `json:"nodeAlias,omitempty"`
+	// A human readable summary of the event, so that the payload can be posted to chat webhooks (e.g. Slack) as it is.
+	Text string `json:"text"`
+	// The ID of the peer.
+	PeerID string `json:"peerId"`
+	// The alias of the peer.
+	PeerAlias string `json:"peerAlias,omitempty"`
+	// The relation to the peer.
+	Relation string `json:"relation,omitempty"`
+	// The previous relation to the peer, only set for peerRelationChanged.
+	PreviousRelation string `json:"previousRelation,omitempty"`
+	// The statistics of the connections to the peer, only set for peerDisconnected.
+	Connection *peer.ConnectionStats `json:"connection,omitempty"`
+	// The ban of the peer, only set for peerBanned.
+	Ban *peering.Ban `json:"ban,omitempty"`
+}
+
+// configureWebhooks posts the connects, disconnects, relation changes and bans of the peers to the configured webhooks.
+func configureWebhooks() {
+	webhookURLs = config.NodeConfig.GetStringSlice(config.CfgNetGossipWebhooksURLs)
+	if len(webhookURLs) == 0 {
+		return
+	}
+
+	webhookClient = &http.Client{Timeout: time.Duration(config.NodeConfig.GetInt(config.CfgNetGossipWebhooksTimeoutSeconds)) * time.Second}
+	webhookQueue = make(chan *webhookNotification, webhookQueueSize)
+
+	manager.Events.PeerConnected.Attach(events.NewClosure(func(p *peer.Peer) {
+		notifyWebhooks(newWebhookNotification(webhookEventPeerConnected, p, fmt.Sprintf("connected to %s", p.Name())))
+	}))
+
+	manager.Events.PeerConnectionEnded.Attach(events.NewClosure(func(p *peer.Peer, stats *peer.ConnectionStats) {
+		notification := newWebhookNotification(webhookEventPeerDisconnected, p, fmt.Sprintf("disconnected from %s", p.Name()))
+		notification.Connection = stats
+		notifyWebhooks(notification)
+	}))
+
+	manager.Events.PeerRelationChanged.Attach(events.NewClosure(func(p *peer.Peer, previous protocol.Relation, relation protocol.Relation) {
+		notification := newWebhookNotification(webhookEventPeerRelationChanged, p, fmt.Sprintf("relation to %s changed from %s to %s", p.Name(), previous, relation))
+		notification.PreviousRelation = previous.String()
+		notifyWebhooks(notification)
+	}))
+
+	manager.Events.PeerBanned.Attach(events.NewClosure(func(ban *peering.Ban) {
+		notifyWebhooks(&webhookNotification{
+			Event:     webhookEventPeerBanned,
+			Timestamp: time.Now().Unix(),
+			NodeAlias: config.NodeConfig.GetString(config.CfgNodeAlias),
+			Text:      fmt.Sprintf("banned %s until %s: %s", ban.PeerID, ban.Until.Format(time.RFC3339), ban.Reason),
+			PeerID:    ban.PeerID,
+			Ban:       ban,
+		})
+	}))
+}
+
+func runWebhooks() {
+	if len(webhookURLs) == 0 {
+		return
+	}
+
+	daemon.BackgroundWorker("Peering[Webhooks]", func(shutdownSignal <-chan struct{}) {
+		for {
+			select {
+			case <-shutdownSignal:
+				return
+			case notification := <-webhookQueue:
+				postWebhookNotification(notification)
+			}
+		}
+	}, shutdown.PriorityPeerWebhooks)
+}
+
+func newWebhookNotification(event string, p *peer.Peer, text string) *webhookNotification {
+	return &webhookNotification{
+		Event:     event,
+		Timestamp: time.Now().Unix(),
+		NodeAlias: config.NodeConfig.GetString(config.CfgNodeAlias),
+		Text:      text,
+		PeerID:    p.ID,
+		PeerAlias: p.Alias(),
+		Relation:  p.Relation().String(),
+	}
+}
+
+// queues the given notification to be posted, it is dropped if the webhooks can't keep up,
+// so that the events of the peering manager are never blocked.
+func notifyWebhooks(notification *webhookNotification) {
+	select {
+	case webhookQueue <- notification:
+	default:
+		log.Warnf("dropped webhook notification '%s' of %s, the webhooks can't keep up", notification.Event, notification.PeerID)
+	}
+}
+
+// posts the given notification to all webhooks.
+func postWebhookNotification(notification *webhookNotification) {
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		log.Warnf("serializing webhook notification failed: %s", err)
+		return
+	}
+
+	for _, url := range webhookURLs {
+		if err := postWebhook(url, payload); err != nil {
+			log.Warnf("posting webhook notification '%s' to %s failed: %s", notification.Event, url, err)
+		}
+	}
+}
+
+func postWebhook(url string, payload []byte) error {
+	res, err := webhookClient.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %s", res.Status)
+	}
+	return nil
+}
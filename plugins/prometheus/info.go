@@ -6,6 +6,7 @@ import (
 	"github.com/gohornet/hornet/pkg/model/tangle"
 	"github.com/gohornet/hornet/plugins/cli"
 	"github.com/gohornet/hornet/plugins/gossip"
+	tanglePlugin "github.com/gohornet/hornet/plugins/tangle"
 	"github.com/iotaledger/iota.go/consts"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -20,6 +21,8 @@ var (
 	infoPruningIndex          prometheus.Gauge
 	infoTips                  prometheus.Gauge
 	infoTransactionsToRequest prometheus.Gauge
+	infoAdmissionBufferSize   prometheus.Gauge
+	infoPartitionSuspected    prometheus.Gauge
 )
 
 func init() {
@@ -68,6 +71,15 @@ func init() {
 		Name: "iota_info_transactions_to_request",
 		Help: "Number of transactions to request.",
 	})
+	infoAdmissionBufferSize = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "iota_info_admission_buffer_size",
+		Help: "Number of received transactions currently held back by the admission control.",
+	})
+
+	infoPartitionSuspected = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "iota_info_partition_suspected",
+		Help: "Whether a network partition is suspected (1) or not (0).",
+	})
 
 	infoApp.WithLabelValues(cli.AppName, cli.AppVersion).Set(1)
 
@@ -80,6 +92,8 @@ func init() {
 	registry.MustRegister(infoPruningIndex)
 	registry.MustRegister(infoTips)
 	registry.MustRegister(infoTransactionsToRequest)
+	registry.MustRegister(infoAdmissionBufferSize)
+	registry.MustRegister(infoPartitionSuspected)
 
 	addCollect(collectInfo)
 }
@@ -130,4 +144,13 @@ func collectInfo() {
 	// Transactions to request
 	queued, pending, _ := gossip.RequestQueue().Size()
 	infoTransactionsToRequest.Set(float64(queued + pending))
+
+	// Transactions held back by the admission control
+	infoAdmissionBufferSize.Set(float64(gossip.Processor().AdmissionBufferSize()))
+
+	// Network partition
+	infoPartitionSuspected.Set(0)
+	if tanglePlugin.PartitionSuspicion() != nil {
+		infoPartitionSuspected.Set(1)
+	}
 }
@@ -0,0 +1,120 @@
+package prometheus
+
+import (
+	"github.com/gohornet/hornet/pkg/peering"
+	peeringplugin "github.com/gohornet/hornet/plugins/peering"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	peeringHandshakeRejections    *prometheus.GaugeVec
+	peeringProtocolTerminations   *prometheus.GaugeVec
+	peeringConnectionFailures     *prometheus.GaugeVec
+	peeringResourceLimitsReached  *prometheus.GaugeVec
+	peeringSendQueueDrops         prometheus.Gauge
+	peeringSendQueueMemory        prometheus.Gauge
+	peeringSendQueueDrainTimeouts prometheus.Gauge
+	peeringPrunedConnections      prometheus.Gauge
+	peeringUnreachableStaticPeers prometheus.Gauge
+)
+
+func init() {
+	peeringHandshakeRejections = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "iota_peering_handshake_rejections",
+			Help: "Number of rejected handshakes by reason.",
+		},
+		[]string{"reason"},
+	)
+	peeringProtocolTerminations = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "iota_peering_protocol_terminations",
+			Help: "Number of terminated peer protocols by reason.",
+		},
+		[]string{"reason"},
+	)
+	peeringConnectionFailures = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "iota_peering_connection_failures",
+			Help: "Number of failed connection attempts and errors of established connections.",
+		},
+		[]string{"type"},
+	)
+	peeringResourceLimitsReached = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "iota_peering_resource_limits_reached",
+			Help: "Number of refused connections and messages by the resource whose limit was reached.",
+		},
+		[]string{"resource"},
+	)
+	peeringSendQueueDrops = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "iota_peering_send_queue_drops",
+		Help: "Number of messages dropped because the send queue of a peer was full.",
+	})
+	peeringSendQueueMemory = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "iota_peering_send_queue_memory_bytes",
+		Help: "Bytes held in the send queues of all peers together (only tracked if the total send queue memory is limited).",
+	})
+	peeringSendQueueDrainTimeouts = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "iota_peering_send_queue_drain_timeouts",
+		Help: "Number of removed peers whose send queues couldn't be sent before the drain timeout.",
+	})
+	peeringPrunedConnections = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "iota_peering_pruned_connections",
+		Help: "Number of connections to unknown peers closed to make room for known peers.",
+	})
+
+	peeringUnreachableStaticPeers = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "iota_peering_unreachable_static_peers",
+		Help: "Number of static peers which are unreachable for longer than the alert threshold.",
+	})
+
+	registry.MustRegister(peeringHandshakeRejections)
+	registry.MustRegister(peeringProtocolTerminations)
+	registry.MustRegister(peeringConnectionFailures)
+	registry.MustRegister(peeringResourceLimitsReached)
+	registry.MustRegister(peeringSendQueueDrops)
+	registry.MustRegister(peeringSendQueueMemory)
+	registry.MustRegister(peeringSendQueueDrainTimeouts)
+	registry.MustRegister(peeringPrunedConnections)
+	registry.MustRegister(peeringUnreachableStaticPeers)
+
+	addCollect(collectPeering)
+}
+
+func collectPeering() {
+	metrics := peeringplugin.Manager().Metrics()
+
+	peeringHandshakeRejections.WithLabelValues("peering_slots_filled").Set(float64(metrics.RejectedPeeringSlotsFilled))
+	peeringHandshakeRejections.WithLabelValues("autopeering_slots_filled").Set(float64(metrics.RejectedAutopeeringSlotsFilled))
+	peeringHandshakeRejections.WithLabelValues("non_matching_mwm").Set(float64(metrics.RejectedNonMatchingMWM))
+	peeringHandshakeRejections.WithLabelValues("non_matching_coo_addr").Set(float64(metrics.RejectedNonMatchingCooAddr))
+	peeringHandshakeRejections.WithLabelValues("non_matching_network_id").Set(float64(metrics.RejectedNonMatchingNetworkID))
+	peeringHandshakeRejections.WithLabelValues("non_matching_srv_socket_port").Set(float64(metrics.RejectedNonMatchingSrvSocketPort))
+	peeringHandshakeRejections.WithLabelValues("unknown_peer_id").Set(float64(metrics.RejectedUnknownPeerID))
+	peeringHandshakeRejections.WithLabelValues("already_connected").Set(float64(metrics.RejectedAlreadyConnected))
+	peeringHandshakeRejections.WithLabelValues("banned").Set(float64(metrics.RejectedBanned))
+	peeringHandshakeRejections.WithLabelValues("diversity_limit_reached").Set(float64(metrics.RejectedDiversityLimitReached))
+	peeringHandshakeRejections.WithLabelValues("duplicate").Set(float64(metrics.DroppedDuplicates))
+	peeringHandshakeRejections.WithLabelValues("gated").Set(float64(metrics.GatedConnections))
+
+	peeringProtocolTerminations.WithLabelValues("deadlines_exceeded").Set(float64(metrics.DeadlinesExceeded))
+	peeringProtocolTerminations.WithLabelValues("keepalive_timeout").Set(float64(metrics.KeepaliveTimeouts))
+	peeringProtocolTerminations.WithLabelValues("invalid_message").Set(float64(metrics.InvalidMessages))
+
+	peeringConnectionFailures.WithLabelValues("connect").Set(float64(metrics.ConnectFailures))
+	peeringConnectionFailures.WithLabelValues("connection").Set(float64(metrics.ConnectionErrors))
+
+	peeringResourceLimitsReached.WithLabelValues(string(peering.ResourcePendingInbound)).Set(float64(metrics.PendingInboundLimitReached))
+	peeringResourceLimitsReached.WithLabelValues(string(peering.ResourceConnectionsPerIP)).Set(float64(metrics.ConnectionsPerIPLimitReached))
+	peeringResourceLimitsReached.WithLabelValues(string(peering.ResourceSendQueueMemory)).Set(float64(metrics.SendQueueMemoryLimitReached))
+	peeringResourceLimitsReached.WithLabelValues(string(peering.ResourceTotalSendQueueMemory)).Set(float64(metrics.TotalSendQueueMemoryLimitReached))
+	peeringResourceLimitsReached.WithLabelValues(string(peering.ResourceInboundConnections)).Set(float64(metrics.InboundLimitReached))
+	peeringResourceLimitsReached.WithLabelValues(string(peering.ResourceOutboundConnections)).Set(float64(metrics.OutboundLimitReached))
+
+	peeringSendQueueDrops.Set(float64(metrics.SendQueueDrops))
+	peeringSendQueueMemory.Set(float64(peeringplugin.Manager().SendQueueMemory()))
+	peeringSendQueueDrainTimeouts.Set(float64(metrics.SendQueueDrainTimeouts))
+	peeringPrunedConnections.Set(float64(metrics.PrunedConnections))
+	peeringUnreachableStaticPeers.Set(float64(len(peeringplugin.Manager().UnreachableStaticPeers())))
+}
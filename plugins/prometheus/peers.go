@@ -21,6 +21,13 @@ var (
 	peersSentHeartbeats              *prometheus.GaugeVec
 	peersDroppedSentPackets          *prometheus.GaugeVec
 	peersConnected                   *prometheus.GaugeVec
+	peersStreamBytesIn               *prometheus.GaugeVec
+	peersStreamBytesOut              *prometheus.GaugeVec
+	peersStreamMessagesIn            *prometheus.GaugeVec
+	peersStreamMessagesOut           *prometheus.GaugeVec
+	peersSendQueueFill               *prometheus.GaugeVec
+	peersLastHeartbeatAge            *prometheus.GaugeVec
+	peersStreamUptime                *prometheus.GaugeVec
 )
 
 func init() {
@@ -116,6 +123,56 @@ func init() {
 		[]string{"address", "port", "domain", "alias", "type", "autopeering_id"},
 	)
 
+	peersStreamBytesIn = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "iota_peers_stream_bytes_in",
+			Help: "Number of bytes received over the stream by peer.",
+		},
+		[]string{"address", "port", "domain", "alias", "type", "autopeering_id"},
+	)
+	peersStreamBytesOut = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "iota_peers_stream_bytes_out",
+			Help: "Number of bytes sent over the stream by peer.",
+		},
+		[]string{"address", "port", "domain", "alias", "type", "autopeering_id"},
+	)
+	peersStreamMessagesIn = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "iota_peers_stream_messages_in",
+			Help: "Number of messages received over the stream by peer.",
+		},
+		[]string{"address", "port", "domain", "alias", "type", "autopeering_id"},
+	)
+	peersStreamMessagesOut = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "iota_peers_stream_messages_out",
+			Help: "Number of messages sent over the stream by peer.",
+		},
+		[]string{"address", "port", "domain", "alias", "type", "autopeering_id"},
+	)
+	peersSendQueueFill = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "iota_peers_send_queue_fill",
+			Help: "Fill level of the send queue by peer.",
+		},
+		[]string{"address", "port", "domain", "alias", "type", "autopeering_id"},
+	)
+	peersLastHeartbeatAge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "iota_peers_last_heartbeat_age_seconds",
+			Help: "Time since the last heartbeat was received by peer.",
+		},
+		[]string{"address", "port", "domain", "alias", "type", "autopeering_id"},
+	)
+	peersStreamUptime = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "iota_peers_stream_uptime_seconds",
+			Help: "Uptime of the stream by peer.",
+		},
+		[]string{"address", "port", "domain", "alias", "type", "autopeering_id"},
+	)
+
 	registry.MustRegister(peersAllTransactions)
 	registry.MustRegister(peersNewTransactions)
 	registry.MustRegister(peersKnownTransactions)
@@ -129,6 +186,13 @@ func init() {
 	registry.MustRegister(peersSentHeartbeats)
 	registry.MustRegister(peersDroppedSentPackets)
 	registry.MustRegister(peersConnected)
+	registry.MustRegister(peersStreamBytesIn)
+	registry.MustRegister(peersStreamBytesOut)
+	registry.MustRegister(peersStreamMessagesIn)
+	registry.MustRegister(peersStreamMessagesOut)
+	registry.MustRegister(peersSendQueueFill)
+	registry.MustRegister(peersLastHeartbeatAge)
+	registry.MustRegister(peersStreamUptime)
 
 	addCollect(collectPeers)
 }
@@ -147,6 +211,13 @@ func collectPeers() {
 	peersSentHeartbeats.Reset()
 	peersDroppedSentPackets.Reset()
 	peersConnected.Reset()
+	peersStreamBytesIn.Reset()
+	peersStreamBytesOut.Reset()
+	peersStreamMessagesIn.Reset()
+	peersStreamMessagesOut.Reset()
+	peersSendQueueFill.Reset()
+	peersLastHeartbeatAge.Reset()
+	peersStreamUptime.Reset()
 
 	for _, peer := range peering.Manager().PeerInfos() {
 		address, port, _ := net.SplitHostPort(peer.Address)
@@ -175,5 +246,15 @@ func collectPeers() {
 		if peer.Connected {
 			peersConnected.With(labels).Set(1)
 		}
+
+		if peer.Stream != nil {
+			peersStreamBytesIn.With(labels).Set(float64(peer.Stream.BytesIn))
+			peersStreamBytesOut.With(labels).Set(float64(peer.Stream.BytesOut))
+			peersStreamMessagesIn.With(labels).Set(float64(peer.Stream.MessagesIn))
+			peersStreamMessagesOut.With(labels).Set(float64(peer.Stream.MessagesOut))
+			peersSendQueueFill.With(labels).Set(peer.Stream.SendQueueFill)
+			peersLastHeartbeatAge.With(labels).Set(float64(peer.Stream.LastHeartbeatAgeMs) / 1000)
+			peersStreamUptime.With(labels).Set(float64(peer.Stream.UptimeSeconds))
+		}
 	}
 }
@@ -0,0 +1,32 @@
+package prometheus
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/gohornet/hornet/pkg/supervisor"
+)
+
+var (
+	pluginRestarts *prometheus.GaugeVec
+)
+
+func init() {
+	pluginRestarts = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "iota_plugin_restarts",
+			Help: "Number of worker restarts after a panic per plugin.",
+		},
+		[]string{"plugin"},
+	)
+
+	registry.MustRegister(pluginRestarts)
+
+	addCollect(collectPluginRestarts)
+}
+
+func collectPluginRestarts() {
+	pluginRestarts.Reset()
+	for pluginName, restarts := range supervisor.RestartCounts() {
+		pluginRestarts.WithLabelValues(pluginName).Set(float64(restarts))
+	}
+}
@@ -1,6 +1,8 @@
 package prometheus
 
 import (
+	"strconv"
+
 	"github.com/gohornet/hornet/pkg/metrics"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -24,6 +26,19 @@ var (
 	serverSentSpamTransactions        prometheus.Gauge
 	serverValidatedBundles            prometheus.Gauge
 	serverSeenSpentAddresses          prometheus.Gauge
+	serverTransactionHopCounts        *prometheus.GaugeVec
+	serverHopLimitedTransactions      prometheus.Gauge
+	serverExpiredTransactions         prometheus.Gauge
+	serverSnapshotDelayedMilestones   prometheus.Gauge
+	serverSnapshotConfirmationDelay   prometheus.Gauge
+	serverSuppressedBroadcasts        prometheus.Gauge
+	serverBackpressuredBroadcasts     prometheus.Gauge
+	serverAdmissionBufferedTxs        prometheus.Gauge
+	serverAdmissionBufferOverflows    prometheus.Gauge
+	serverTransactionStoreLatency     *prometheus.GaugeVec
+	serverTransactionStoreLatencies   *prometheus.GaugeVec
+	serverBroadcastLatency            *prometheus.GaugeVec
+	serverBroadcastLatencies          *prometheus.GaugeVec
 )
 
 func init() {
@@ -99,6 +114,73 @@ func init() {
 		Name: "iota_server_seen_spent_addresses",
 		Help: "Number of seen spent addresses.",
 	})
+	serverTransactionHopCounts = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "iota_server_transaction_hop_counts",
+			Help: "Number of received transactions per hop count.",
+		},
+		[]string{"hops"},
+	)
+	serverHopLimitedTransactions = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "iota_server_hop_limited_transactions",
+		Help: "Number of transactions which were not relayed because they reached the hop limit.",
+	})
+	serverAdmissionBufferedTxs = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "iota_server_admission_buffered_transactions",
+		Help: "Number of received transactions which were held back while a milestone was confirmed.",
+	})
+	serverAdmissionBufferOverflows = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "iota_server_admission_buffer_overflows",
+		Help: "Number of received transactions which were processed immediately because the admission buffer was full.",
+	})
+	serverExpiredTransactions = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "iota_server_expired_transactions",
+		Help: "Number of transactions which were deleted by the expiry rules.",
+	})
+	serverSnapshotDelayedMilestones = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "iota_server_snapshot_delayed_milestones",
+		Help: "Number of milestones which were confirmed while a local snapshot was created.",
+	})
+	serverSnapshotConfirmationDelay = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "iota_server_snapshot_confirmation_delay_ms",
+		Help: "Total time in milliseconds the confirmation of milestones waited for the ledger while a local snapshot was created.",
+	})
+	serverSuppressedBroadcasts = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "iota_server_suppressed_duplicate_broadcasts",
+		Help: "Number of transaction sends which were skipped because the peer already sent the transaction to the node.",
+	})
+	serverBackpressuredBroadcasts = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "iota_server_backpressured_broadcasts",
+		Help: "Number of transaction sends which were skipped because the send queue of the peer was congested.",
+	})
+	serverTransactionStoreLatency = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "iota_server_transaction_store_latency_us",
+			Help: "Total time in microseconds processed transactions waited until they were stored.",
+		},
+		[]string{"priority"},
+	)
+	serverTransactionStoreLatencies = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "iota_server_transaction_store_latency_count",
+			Help: "Number of stored transactions of which the store latency was tracked.",
+		},
+		[]string{"priority"},
+	)
+	serverBroadcastLatency = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "iota_server_broadcast_latency_us",
+			Help: "Total time in microseconds broadcasts waited in the broadcast queue.",
+		},
+		[]string{"priority"},
+	)
+	serverBroadcastLatencies = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "iota_server_broadcast_latency_count",
+			Help: "Number of broadcasts of which the latency was tracked.",
+		},
+		[]string{"priority"},
+	)
 
 	registry.MustRegister(serverAllTransactions)
 	registry.MustRegister(serverNewTransactions)
@@ -118,6 +200,19 @@ func init() {
 	registry.MustRegister(serverSentSpamTransactions)
 	registry.MustRegister(serverValidatedBundles)
 	registry.MustRegister(serverSeenSpentAddresses)
+	registry.MustRegister(serverTransactionHopCounts)
+	registry.MustRegister(serverHopLimitedTransactions)
+	registry.MustRegister(serverAdmissionBufferedTxs)
+	registry.MustRegister(serverAdmissionBufferOverflows)
+	registry.MustRegister(serverExpiredTransactions)
+	registry.MustRegister(serverSnapshotDelayedMilestones)
+	registry.MustRegister(serverSnapshotConfirmationDelay)
+	registry.MustRegister(serverSuppressedBroadcasts)
+	registry.MustRegister(serverBackpressuredBroadcasts)
+	registry.MustRegister(serverTransactionStoreLatency)
+	registry.MustRegister(serverTransactionStoreLatencies)
+	registry.MustRegister(serverBroadcastLatency)
+	registry.MustRegister(serverBroadcastLatencies)
 
 	addCollect(collectServer)
 }
@@ -141,4 +236,24 @@ func collectServer() {
 	serverSentSpamTransactions.Set(float64(metrics.SharedServerMetrics.SentSpamTransactions.Load()))
 	serverValidatedBundles.Set(float64(metrics.SharedServerMetrics.ValidatedBundles.Load()))
 	serverSeenSpentAddresses.Set(float64(metrics.SharedServerMetrics.SeenSpentAddresses.Load()))
+	for hopCount := range metrics.SharedServerMetrics.TransactionHopCounts {
+		serverTransactionHopCounts.WithLabelValues(strconv.Itoa(hopCount)).Set(float64(metrics.SharedServerMetrics.TransactionHopCounts[hopCount].Load()))
+	}
+	serverHopLimitedTransactions.Set(float64(metrics.SharedServerMetrics.HopLimitedTransactions.Load()))
+	serverAdmissionBufferedTxs.Set(float64(metrics.SharedServerMetrics.AdmissionBufferedTransactions.Load()))
+	serverAdmissionBufferOverflows.Set(float64(metrics.SharedServerMetrics.AdmissionBufferOverflows.Load()))
+	serverExpiredTransactions.Set(float64(metrics.SharedServerMetrics.ExpiredTransactions.Load()))
+	serverSnapshotDelayedMilestones.Set(float64(metrics.SharedServerMetrics.SnapshotDelayedMilestones.Load()))
+	serverSnapshotConfirmationDelay.Set(float64(metrics.SharedServerMetrics.SnapshotConfirmationDelay.Load()))
+	serverSuppressedBroadcasts.Set(float64(metrics.SharedServerMetrics.SuppressedDuplicateBroadcasts.Load()))
+	serverBackpressuredBroadcasts.Set(float64(metrics.SharedServerMetrics.BackpressuredBroadcasts.Load()))
+	collectLatency(serverTransactionStoreLatency, serverTransactionStoreLatencies, "regular", &metrics.SharedServerMetrics.RegularTransactionStoreLatency)
+	collectLatency(serverTransactionStoreLatency, serverTransactionStoreLatencies, "priority", &metrics.SharedServerMetrics.PriorityTransactionStoreLatency)
+	collectLatency(serverBroadcastLatency, serverBroadcastLatencies, "regular", &metrics.SharedServerMetrics.RegularBroadcastLatency)
+	collectLatency(serverBroadcastLatency, serverBroadcastLatencies, "priority", &metrics.SharedServerMetrics.PriorityBroadcastLatency)
+}
+
+func collectLatency(total *prometheus.GaugeVec, count *prometheus.GaugeVec, priority string, latency *metrics.Latency) {
+	total.WithLabelValues(priority).Set(float64(latency.TotalMicroseconds.Load()))
+	count.WithLabelValues(priority).Set(float64(latency.Count.Load()))
 }
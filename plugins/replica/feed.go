@@ -0,0 +1,133 @@
+package replica
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/node"
+	"github.com/iotaledger/hive.go/timeutil"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/replica"
+	"github.com/gohornet/hornet/pkg/shutdown"
+	"github.com/gohornet/hornet/pkg/supervisor"
+	tanglePlugin "github.com/gohornet/hornet/plugins/tangle"
+)
+
+const (
+	feedRoute = "/feed"
+)
+
+var (
+	// the solid milestone index which was last announced to the replicas.
+	lastConfirmedIndex milestone.Index
+)
+
+// returns a notice of the given type containing the current state of the node.
+func currentNotice(noticeType string) *replica.Notice {
+	return &replica.Notice{
+		Type:                 noticeType,
+		LatestMilestoneIndex: tangle.GetLatestMilestoneIndex(),
+		SolidMilestoneIndex:  tangle.GetSolidMilestoneIndex(),
+	}
+}
+
+func configureFeedEvents() {
+	tanglePlugin.Events.LatestMilestoneIndexChanged.Attach(events.NewClosure(func(_ milestone.Index) {
+		feed.Publish(currentNotice(replica.NoticeTypeMilestone))
+	}))
+
+	tanglePlugin.Events.SolidMilestoneIndexChanged.Attach(events.NewClosure(func(msIndex milestone.Index) {
+		notice := currentNotice(replica.NoticeTypeConfirmed)
+		notice.ConfirmedFrom = msIndex
+		if lastConfirmedIndex != 0 && lastConfirmedIndex < msIndex {
+			notice.ConfirmedFrom = lastConfirmedIndex + 1
+		}
+		notice.ConfirmedTo = msIndex
+		lastConfirmedIndex = msIndex
+
+		feed.Publish(notice)
+	}))
+}
+
+// streams the notices to the replica until it disconnects, falls behind or the node shuts down.
+func handleFeed(c *gin.Context) {
+	ch := feed.Subscribe()
+	defer feed.Unsubscribe(ch)
+
+	log.Infof("Replica %s subscribed to the feed", c.ClientIP())
+	defer log.Infof("Replica %s unsubscribed from the feed", c.ClientIP())
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+
+	// announce the current state, so the replica can check its caches right away
+	if err := replica.WriteNotice(c.Writer, currentNotice(replica.NoticeTypeStatus)); err != nil {
+		return
+	}
+	c.Writer.Flush()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case notice, ok := <-ch:
+			if !ok {
+				return
+			}
+			if err := replica.WriteNotice(c.Writer, notice); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+func runFeed(plugin *node.Plugin) {
+	log.Info("Starting replica feed ...")
+
+	supervisor.BackgroundWorker(plugin.Name, "Replica feed", func(shutdownSignal <-chan struct{}) {
+		log.Info("Starting replica feed ... done")
+
+		engine := gin.New()
+		engine.Use(gin.Recovery())
+		engine.GET(feedRoute, handleFeed)
+
+		bindAddr := config.NodeConfig.GetString(config.CfgReplicaFeedBindAddress)
+		server := &http.Server{Addr: bindAddr, Handler: engine}
+
+		go func() {
+			log.Infof("The replica feed is available on: http://%s%s", bindAddr, feedRoute)
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Warnf("Stopping replica feed due to an error: %s", err)
+			}
+		}()
+
+		<-shutdownSignal
+		log.Info("Stopping replica feed ...")
+
+		// end the streams of all replicas, otherwise the server waits for them
+		feed.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := server.Shutdown(ctx); err != nil {
+			log.Warn(err.Error())
+		}
+		cancel()
+
+		log.Info("Stopping replica feed ... done")
+	}, shutdown.PriorityReplica)
+
+	statusInterval := time.Duration(config.NodeConfig.GetInt(config.CfgReplicaFeedStatusIntervalSeconds)) * time.Second
+	supervisor.BackgroundWorker(plugin.Name, "Replica[StatusAnnouncer]", func(shutdownSignal <-chan struct{}) {
+		timeutil.Ticker(func() {
+			feed.Publish(currentNotice(replica.NoticeTypeStatus))
+		}, statusInterval, shutdownSignal)
+	}, shutdown.PriorityReplica)
+}
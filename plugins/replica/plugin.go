@@ -0,0 +1,64 @@
+package replica
+
+import (
+	"time"
+
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/hive.go/node"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/replica"
+	"github.com/gohornet/hornet/plugins/cli"
+)
+
+var (
+	PLUGIN = node.NewPlugin("Replica", node.Disabled, configure, run)
+	log    *logger.Logger
+
+	feed = replica.NewFeed()
+
+	// only set if the node runs as a read-only replica.
+	tracker    *replica.Tracker
+	staleAfter time.Duration
+)
+
+func configure(plugin *node.Plugin) {
+	log = logger.NewLogger(plugin.Name)
+
+	if !cli.IsSafeMode() {
+		// the node is the primary and streams the invalidation notices to its replicas
+		configureFeedEvents()
+		return
+	}
+
+	if config.NodeConfig.GetString(config.CfgReplicaPrimaryFeedURL) == "" {
+		log.Panicf("'%s' must be set to run as a read-only replica", config.CfgReplicaPrimaryFeedURL)
+	}
+
+	tracker = replica.NewTracker()
+	staleAfter = time.Duration(config.NodeConfig.GetInt(config.CfgReplicaStaleAfterSeconds)) * time.Second
+}
+
+func run(plugin *node.Plugin) {
+	if IsReplica() {
+		runSubscriber(plugin, config.NodeConfig.GetString(config.CfgReplicaPrimaryFeedURL))
+		return
+	}
+
+	runFeed(plugin)
+}
+
+// IsReplica returns whether the node runs as a read-only replica of a primary.
+func IsReplica() bool {
+	return tracker != nil
+}
+
+// Status returns the sync status of the replica compared to its primary.
+// Returns nil if the node doesn't run as a read-only replica.
+func Status() *replica.Status {
+	if !IsReplica() {
+		return nil
+	}
+	return tracker.Status(tangle.GetSolidMilestoneIndex(), staleAfter)
+}
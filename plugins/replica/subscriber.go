@@ -0,0 +1,86 @@
+package replica
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/iotaledger/hive.go/node"
+
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/replica"
+	"github.com/gohornet/hornet/pkg/shutdown"
+	"github.com/gohornet/hornet/pkg/supervisor"
+)
+
+const (
+	// the time to wait before subscribing to the feed of the primary again after the stream ended.
+	resubscribeInterval = 5 * time.Second
+)
+
+// refreshes the caches of the replica if the given notice announces a change of the primary.
+// status notices only cause a refresh if the store of the replica lags behind the primary,
+// since the copy of the store might have caught up in the meantime.
+func processNotice(notice *replica.Notice) {
+	tracker.Apply(notice)
+
+	if notice.Type == replica.NoticeTypeStatus && tangle.GetSolidMilestoneIndex() >= notice.SolidMilestoneIndex {
+		return
+	}
+
+	if err := tangle.RefreshFromDatabase(); err != nil {
+		log.Warnf("Refreshing the caches failed: %s", err)
+	}
+}
+
+// subscribes to the feed of the primary and processes the notices until the stream ends.
+func subscribe(ctx context.Context, feedURL string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("primary responded with status %s", res.Status)
+	}
+
+	tracker.SetConnected(true)
+	defer tracker.SetConnected(false)
+
+	log.Infof("Subscribed to the feed of the primary %s", feedURL)
+	return replica.ReadNotices(res.Body, processNotice)
+}
+
+func runSubscriber(plugin *node.Plugin, primaryFeedURL string) {
+	feedURL := strings.TrimSuffix(primaryFeedURL, "/") + feedRoute
+
+	supervisor.BackgroundWorker(plugin.Name, "Replica[Subscriber]", func(shutdownSignal <-chan struct{}) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go func() {
+			<-shutdownSignal
+			cancel()
+		}()
+
+		for {
+			if err := subscribe(ctx, feedURL); err != nil && ctx.Err() == nil {
+				log.Warnf("Subscription to the feed of the primary %s failed: %s", feedURL, err)
+			}
+
+			select {
+			case <-shutdownSignal:
+				return
+			case <-time.After(resubscribeInterval):
+			}
+		}
+	}, shutdown.PriorityReplica)
+}
@@ -0,0 +1,27 @@
+package scheduler
+
+import (
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/hive.go/node"
+
+	"github.com/gohornet/hornet/pkg/scheduler"
+	"github.com/gohornet/hornet/pkg/shutdown"
+)
+
+var (
+	PLUGIN = node.NewPlugin("Scheduler", node.Enabled, configure, run)
+	log    *logger.Logger
+)
+
+func configure(plugin *node.Plugin) {
+	log = logger.NewLogger(plugin.Name)
+}
+
+func run(_ *node.Plugin) {
+	daemon.BackgroundWorker("Scheduler", func(shutdownSignal <-chan struct{}) {
+		log.Info("Starting Scheduler ... done")
+		scheduler.Start(shutdownSignal)
+		log.Info("Stopping Scheduler ... done")
+	}, shutdown.PriorityScheduler)
+}
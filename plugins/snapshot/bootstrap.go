@@ -0,0 +1,211 @@
+package snapshot
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+)
+
+// BootstrapSource is a source the node can use to bootstrap its ledger state at startup.
+// The bootstrap plan only chooses between the existing database and the snapshot files.
+// The ledger state is never transferred from peers, the history after the loaded ledger state
+// is always fetched from peers via warpsync.
+type BootstrapSource string
+
+const (
+	// BootstrapSourceDatabase reuses the existing database, the missing history is fetched from peers via warpsync.
+	BootstrapSourceDatabase BootstrapSource = "database"
+	// BootstrapSourceLocal loads the local snapshot file.
+	BootstrapSourceLocal BootstrapSource = "local"
+	// BootstrapSourceDownload downloads the local snapshot file from one of the configured URLs and loads it.
+	BootstrapSourceDownload BootstrapSource = "download"
+	// BootstrapSourceGlobal loads the global snapshot file.
+	BootstrapSourceGlobal BootstrapSource = "global"
+)
+
+var (
+	// ErrUnknownBootstrapSource is returned if an unknown source was configured in the bootstrap order.
+	ErrUnknownBootstrapSource = errors.New("unknown bootstrap source")
+	// ErrNoBootstrapSourceAvailable is returned if none of the configured bootstrap sources is available.
+	ErrNoBootstrapSourceAvailable = errors.New("no bootstrap source available")
+	// ErrInvalidBootstrapOrder is returned if the configured bootstrap order doesn't start with the database.
+	ErrInvalidBootstrapOrder = errors.New("bootstrap order has to start with 'database'")
+)
+
+// bootstrapPlan is the ordered list of bootstrap sources the node tries at startup.
+type bootstrapPlan []BootstrapSource
+
+func (p bootstrapPlan) String() string {
+	sources := make([]string, len(p))
+	for i, source := range p {
+		sources[i] = string(source)
+	}
+	return strings.Join(sources, " -> ")
+}
+
+// bootstrapOrder returns the configured bootstrap order.
+// If no order was configured, it is derived from the snapshot load type.
+// The order has to start with the database, so that an existing database is never overwritten by a snapshot,
+// unless the global snapshot is enforced. Operators can only pin the order of the snapshot sources.
+func bootstrapOrder() (bootstrapPlan, error) {
+
+	if *forceGlobalSnapshot {
+		return bootstrapPlan{BootstrapSourceGlobal}, nil
+	}
+
+	var plan bootstrapPlan
+	for _, source := range config.NodeConfig.GetStringSlice(config.CfgSnapshotBootstrapOrder) {
+		switch s := BootstrapSource(strings.ToLower(source)); s {
+		case BootstrapSourceDatabase, BootstrapSourceLocal, BootstrapSourceDownload, BootstrapSourceGlobal:
+			plan = append(plan, s)
+		default:
+			return nil, errors.Wrapf(ErrUnknownBootstrapSource, "'%s' under config option '%s'", source, config.CfgSnapshotBootstrapOrder)
+		}
+	}
+
+	if len(plan) > 0 {
+		if plan[0] != BootstrapSourceDatabase {
+			return nil, errors.Wrapf(ErrInvalidBootstrapOrder, "'%s' under config option '%s'", plan, config.CfgSnapshotBootstrapOrder)
+		}
+		return plan, nil
+	}
+
+	switch strings.ToLower(config.NodeConfig.GetString(config.CfgSnapshotLoadType)) {
+	case "global":
+		return bootstrapPlan{BootstrapSourceDatabase, BootstrapSourceGlobal}, nil
+	case "local":
+		return bootstrapPlan{BootstrapSourceDatabase, BootstrapSourceLocal, BootstrapSourceDownload}, nil
+	default:
+		return nil, fmt.Errorf("invalid snapshot type under config option '%s': %s", config.CfgSnapshotLoadType, config.NodeConfig.GetString(config.CfgSnapshotLoadType))
+	}
+}
+
+// bootstrapSourceAvailable checks whether the given bootstrap source can be used.
+// If not, the reason is returned.
+func bootstrapSourceAvailable(source BootstrapSource, databaseExists bool) (bool, string) {
+	switch source {
+	case BootstrapSourceDatabase:
+		if !databaseExists {
+			return false, "no existing database found"
+		}
+		return true, ""
+
+	case BootstrapSourceLocal:
+		path := config.NodeConfig.GetString(config.CfgLocalSnapshotsPath)
+		if path == "" {
+			return false, "no local snapshot path configured"
+		}
+		if _, err := os.Stat(path); err != nil {
+			return false, fmt.Sprintf("local snapshot file '%s' not found", path)
+		}
+		return true, ""
+
+	case BootstrapSourceDownload:
+		if config.NodeConfig.GetString(config.CfgLocalSnapshotsPath) == "" {
+			return false, "no local snapshot path configured"
+		}
+		if len(config.NodeConfig.GetStringSlice(config.CfgLocalSnapshotsDownloadURLs)) == 0 {
+			return false, ErrNoSnapshotDownloadURL.Error()
+		}
+		return true, ""
+
+	case BootstrapSourceGlobal:
+		path := config.NodeConfig.GetString(config.CfgGlobalSnapshotPath)
+		if path == "" {
+			return false, "no global snapshot path configured"
+		}
+		if _, err := os.Stat(path); err != nil {
+			return false, fmt.Sprintf("global snapshot file '%s' not found", path)
+		}
+		return true, ""
+	}
+
+	return false, ErrUnknownBootstrapSource.Error()
+}
+
+// availableBootstrapSources returns the sources of the plan which can be used, in the order of the plan,
+// and the reasons why the other sources can't be used.
+func availableBootstrapSources(plan bootstrapPlan, databaseExists bool) (bootstrapPlan, map[BootstrapSource]string) {
+	var available bootstrapPlan
+	skipped := make(map[BootstrapSource]string)
+
+	for _, source := range plan {
+		if ok, reason := bootstrapSourceAvailable(source, databaseExists); !ok {
+			skipped[source] = reason
+			continue
+		}
+		available = append(available, source)
+	}
+	return available, skipped
+}
+
+// bootstrap walks through the bootstrap plan and uses the first available source.
+// If a snapshot download fails, the next source in the plan is tried, since no data was written to the database yet.
+func bootstrap(plan bootstrapPlan, databaseExists bool) error {
+
+	log.Infof("Bootstrap plan: %s", plan)
+
+	available, skipped := availableBootstrapSources(plan, databaseExists)
+	for _, source := range plan {
+		if reason, isSkipped := skipped[source]; isSkipped {
+			log.Infof("Bootstrap source '%s' skipped: %s", source, reason)
+		}
+	}
+
+	for _, source := range available {
+		log.Infof("Bootstrapping from source '%s' ...", source)
+
+		switch source {
+		case BootstrapSourceDatabase:
+			// the ledger state of the current database is checked,
+			// the missing milestones are requested from peers via warpsync afterwards.
+			tangle.GetLedgerStateForLSMI(nil)
+
+		case BootstrapSourceLocal:
+			if err := LoadSnapshotFromFile(config.NodeConfig.GetString(config.CfgLocalSnapshotsPath)); err != nil {
+				return err
+			}
+
+		case BootstrapSourceDownload:
+			path := config.NodeConfig.GetString(config.CfgLocalSnapshotsPath)
+
+			// create dir if it not exists
+			if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+				return errors.Wrapf(err, "could not create snapshot dir '%s'", path)
+			}
+
+			urls := config.NodeConfig.GetStringSlice(config.CfgLocalSnapshotsDownloadURLs)
+			log.Infof("Downloading snapshot from one of the provided sources %v", urls)
+			if err := downloadSnapshotFile(path, urls, downloadChecksum); err != nil {
+				log.Warnf("Bootstrap source '%s' failed: %s", source, errors.Wrap(err, "Error downloading snapshot file"))
+				continue
+			}
+			log.Info("Snapshot download finished")
+
+			downloadDeltaSnapshotFile()
+
+			if err := LoadSnapshotFromFile(path); err != nil {
+				return err
+			}
+
+		case BootstrapSourceGlobal:
+			if err := LoadGlobalSnapshot(config.NodeConfig.GetString(config.CfgGlobalSnapshotPath),
+				config.NodeConfig.GetStringSlice(config.CfgGlobalSnapshotSpentAddressesPaths),
+				milestone.Index(config.NodeConfig.GetInt(config.CfgGlobalSnapshotIndex))); err != nil {
+				return err
+			}
+		}
+
+		log.Infof("Bootstrapping from source '%s' ... done", source)
+		return nil
+	}
+
+	return errors.Wrapf(ErrNoBootstrapSourceAvailable, "bootstrap plan: %s", plan)
+}
@@ -0,0 +1,103 @@
+package snapshot
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/config"
+)
+
+// sets the given config options and restores the previous values at the end of the test.
+func setTestConfig(t *testing.T, values map[string]interface{}) {
+	for key, value := range values {
+		previous := config.NodeConfig.Get(key)
+		config.NodeConfig.Set(key, value)
+
+		key := key
+		t.Cleanup(func() { config.NodeConfig.Set(key, previous) })
+	}
+}
+
+func TestBootstrapOrder(t *testing.T) {
+	setTestConfig(t, map[string]interface{}{config.CfgSnapshotLoadType: "local"})
+
+	// the order is derived from the snapshot load type if none was configured
+	setTestConfig(t, map[string]interface{}{config.CfgSnapshotBootstrapOrder: []string{}})
+	plan, err := bootstrapOrder()
+	require.NoError(t, err)
+	assert.Equal(t, bootstrapPlan{BootstrapSourceDatabase, BootstrapSourceLocal, BootstrapSourceDownload}, plan)
+
+	setTestConfig(t, map[string]interface{}{config.CfgSnapshotLoadType: "global"})
+	plan, err = bootstrapOrder()
+	require.NoError(t, err)
+	assert.Equal(t, bootstrapPlan{BootstrapSourceDatabase, BootstrapSourceGlobal}, plan)
+
+	setTestConfig(t, map[string]interface{}{config.CfgSnapshotLoadType: "unknown"})
+	_, err = bootstrapOrder()
+	assert.Error(t, err)
+
+	// the configured order is used case-insensitively
+	setTestConfig(t, map[string]interface{}{config.CfgSnapshotBootstrapOrder: []string{"Database", "download", "LOCAL"}})
+	plan, err = bootstrapOrder()
+	require.NoError(t, err)
+	assert.Equal(t, bootstrapPlan{BootstrapSourceDatabase, BootstrapSourceDownload, BootstrapSourceLocal}, plan)
+	assert.Equal(t, "database -> download -> local", plan.String())
+
+	setTestConfig(t, map[string]interface{}{config.CfgSnapshotBootstrapOrder: []string{"database", "peers"}})
+	_, err = bootstrapOrder()
+	assert.True(t, errors.Is(err, ErrUnknownBootstrapSource))
+
+	// an existing database is never overwritten by a snapshot
+	setTestConfig(t, map[string]interface{}{config.CfgSnapshotBootstrapOrder: []string{"download", "database"}})
+	_, err = bootstrapOrder()
+	assert.True(t, errors.Is(err, ErrInvalidBootstrapOrder))
+
+	// unless the global snapshot is enforced
+	*forceGlobalSnapshot = true
+	defer func() { *forceGlobalSnapshot = false }()
+	plan, err = bootstrapOrder()
+	require.NoError(t, err)
+	assert.Equal(t, bootstrapPlan{BootstrapSourceGlobal}, plan)
+}
+
+func TestAvailableBootstrapSources(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bootstrap")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	localSnapshotPath := filepath.Join(dir, "export.bin")
+	globalSnapshotPath := filepath.Join(dir, "snapshot.txt")
+	require.NoError(t, ioutil.WriteFile(globalSnapshotPath, nil, 0600))
+
+	setTestConfig(t, map[string]interface{}{
+		config.CfgLocalSnapshotsPath:         localSnapshotPath,
+		config.CfgLocalSnapshotsDownloadURLs: []string{},
+		config.CfgGlobalSnapshotPath:         globalSnapshotPath,
+	})
+
+	plan := bootstrapPlan{BootstrapSourceDatabase, BootstrapSourceLocal, BootstrapSourceDownload, BootstrapSourceGlobal}
+
+	// an existing database is preferred
+	available, skipped := availableBootstrapSources(plan, true)
+	assert.Equal(t, bootstrapPlan{BootstrapSourceDatabase, BootstrapSourceGlobal}, available)
+	assert.Contains(t, skipped, BootstrapSourceLocal)
+	assert.Contains(t, skipped, BootstrapSourceDownload)
+
+	// the snapshot sources are used in the order of the plan
+	require.NoError(t, ioutil.WriteFile(localSnapshotPath, nil, 0600))
+	setTestConfig(t, map[string]interface{}{config.CfgLocalSnapshotsDownloadURLs: []string{"https://example.com/export.bin"}})
+
+	available, skipped = availableBootstrapSources(plan, false)
+	assert.Equal(t, bootstrapPlan{BootstrapSourceLocal, BootstrapSourceDownload, BootstrapSourceGlobal}, available)
+	assert.Equal(t, map[BootstrapSource]string{BootstrapSourceDatabase: "no existing database found"}, skipped)
+
+	// no source is available
+	available, _ = availableBootstrapSources(bootstrapPlan{BootstrapSourceDatabase}, false)
+	assert.Empty(t, available)
+}
@@ -0,0 +1,215 @@
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+)
+
+const (
+	// the amount of milestones whose cones are walked for solid entry points between two checkpoints.
+	snapshotCheckpointInterval = 10
+)
+
+var (
+	// ErrSnapshotCheckpointMismatch is returned when the checkpoint belongs to another snapshot creation.
+	ErrSnapshotCheckpointMismatch = errors.New("snapshot checkpoint belongs to another target milestone")
+
+	// the path to the checkpoint file of the snapshot creation, empty if the snapshot creation isn't resumable.
+	checkpointPath string
+)
+
+// configureSnapshotCheckpoint loads the path to the checkpoint file of the snapshot creation.
+func configureSnapshotCheckpoint() {
+	checkpointPath = config.NodeConfig.GetString(config.CfgLocalSnapshotsCheckpointPath)
+}
+
+// snapshotCheckpointHeader identifies the snapshot creation a checkpoint belongs to.
+type snapshotCheckpointHeader struct {
+	TargetIndex   milestone.Index
+	MilestoneHash hornet.Hash
+}
+
+// snapshotCheckpointState is the intermediate result of a snapshot creation.
+type snapshotCheckpointState struct {
+	// the next milestone whose cone has to be walked for solid entry points.
+	SolidEntryPointsIndex milestone.Index
+	// the solid entry points found so far.
+	SolidEntryPoints map[string]milestone.Index
+	// the ledger state of the target milestone, nil if it wasn't calculated yet.
+	Balances map[string]uint64
+}
+
+// snapshotCheckpoint holds the intermediate results of a snapshot creation, so that a snapshot creation
+// which was aborted or interrupted by a restart of the node resumes where it stopped instead of starting over.
+// The snapshot file itself is always written from scratch.
+type snapshotCheckpoint struct {
+	snapshotCheckpointHeader
+	snapshotCheckpointState
+	// the path the checkpoint is saved to, empty if the checkpoint isn't saved.
+	path string
+}
+
+// newSnapshotCheckpoint creates an empty checkpoint for the given target milestone.
+func newSnapshotCheckpoint(targetIndex milestone.Index, msHash hornet.Hash, path string) *snapshotCheckpoint {
+	return &snapshotCheckpoint{
+		snapshotCheckpointHeader: snapshotCheckpointHeader{
+			TargetIndex:   targetIndex,
+			MilestoneHash: msHash,
+		},
+		snapshotCheckpointState: snapshotCheckpointState{
+			SolidEntryPointsIndex: targetIndex - SolidEntryPointCheckThresholdPast,
+			SolidEntryPoints:      make(map[string]milestone.Index),
+		},
+		path: path,
+	}
+}
+
+// loadSnapshotCheckpoint returns the checkpoint of the snapshot creation for the given target milestone.
+// If there is no valid checkpoint for the target milestone, an empty checkpoint is returned.
+func loadSnapshotCheckpoint(targetIndex milestone.Index, msHash hornet.Hash) *snapshotCheckpoint {
+	if checkpointPath == "" {
+		return newSnapshotCheckpoint(targetIndex, msHash, "")
+	}
+
+	checkpoint, err := readSnapshotCheckpoint(checkpointPath, targetIndex, msHash)
+	if err != nil {
+		if !os.IsNotExist(errors.Cause(err)) && !errors.Is(err, ErrSnapshotCheckpointMismatch) {
+			log.Warnf("ignoring snapshot checkpoint '%s': %v", checkpointPath, err)
+		}
+		return newSnapshotCheckpoint(targetIndex, msHash, checkpointPath)
+	}
+
+	log.Infof("resuming snapshot creation for target index %d from checkpoint (solid entry points up to milestone %d, ledger state: %v)",
+		targetIndex, checkpoint.SolidEntryPointsIndex-1, checkpoint.Balances != nil)
+
+	return checkpoint
+}
+
+// readSnapshotCheckpointHeader reads the header of the checkpoint file.
+func readSnapshotCheckpointHeader(path string) (*snapshotCheckpointHeader, *gob.Decoder, *os.File, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	decoder := gob.NewDecoder(bufio.NewReader(file))
+
+	header := &snapshotCheckpointHeader{}
+	if err := decoder.Decode(header); err != nil {
+		file.Close()
+		return nil, nil, nil, errors.Wrap(err, "unable to decode header")
+	}
+
+	return header, decoder, file, nil
+}
+
+// readSnapshotCheckpoint reads the checkpoint file if it belongs to the snapshot creation for the given target milestone.
+func readSnapshotCheckpoint(path string, targetIndex milestone.Index, msHash hornet.Hash) (*snapshotCheckpoint, error) {
+	header, decoder, file, err := readSnapshotCheckpointHeader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if header.TargetIndex != targetIndex || !bytes.Equal(header.MilestoneHash, msHash) {
+		return nil, errors.Wrapf(ErrSnapshotCheckpointMismatch, "checkpoint target index: %d, target index: %d", header.TargetIndex, targetIndex)
+	}
+
+	checkpoint := &snapshotCheckpoint{snapshotCheckpointHeader: *header, path: path}
+	if err := decoder.Decode(&checkpoint.snapshotCheckpointState); err != nil {
+		return nil, errors.Wrap(err, "unable to decode state")
+	}
+
+	if checkpoint.SolidEntryPoints == nil {
+		checkpoint.SolidEntryPoints = make(map[string]milestone.Index)
+	}
+
+	return checkpoint, nil
+}
+
+// save writes the checkpoint to its file. A checkpoint which isn't saved only costs
+// the progress since the last checkpoint, so failures are logged instead of aborting the snapshot creation.
+func (c *snapshotCheckpoint) save() {
+	if c.path == "" {
+		return
+	}
+
+	if err := c.write(); err != nil {
+		log.Warnf("saving snapshot checkpoint '%s' failed: %v", c.path, err)
+	}
+}
+
+// write writes the checkpoint to a temporary file, which replaces the checkpoint file afterwards,
+// so that an interrupted write doesn't destroy the last checkpoint.
+func (c *snapshotCheckpoint) write() error {
+	pathTmp := c.path + "_tmp"
+
+	file, err := os.OpenFile(pathTmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(file)
+	encoder := gob.NewEncoder(writer)
+
+	if err := encoder.Encode(&c.snapshotCheckpointHeader); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := encoder.Encode(&c.snapshotCheckpointState); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(pathTmp, c.path)
+}
+
+// remove removes the checkpoint file after the snapshot creation succeeded.
+func (c *snapshotCheckpoint) remove() {
+	if c.path == "" {
+		return
+	}
+
+	os.Remove(c.path)
+	os.Remove(c.path + "_tmp")
+}
+
+// resumableSnapshotTargetIndex returns the target index of the checkpoint of an interrupted snapshot creation,
+// if the snapshot can still be created and isn't newer than the given target index.
+func resumableSnapshotTargetIndex(targetIndex milestone.Index) (milestone.Index, bool) {
+	if checkpointPath == "" {
+		return 0, false
+	}
+
+	header, _, file, err := readSnapshotCheckpointHeader(checkpointPath)
+	if err != nil {
+		return 0, false
+	}
+	file.Close()
+
+	snapshotInfo := tangle.GetSnapshotInfo()
+	if snapshotInfo == nil || header.TargetIndex <= snapshotInfo.SnapshotIndex || header.TargetIndex > targetIndex {
+		return 0, false
+	}
+
+	return header.TargetIndex, true
+}
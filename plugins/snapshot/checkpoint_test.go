@@ -0,0 +1,119 @@
+package snapshot
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/hive.go/logger"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/profile"
+)
+
+// configures the checkpoint file in a temporary directory and returns its path.
+func setTestCheckpointPath(t *testing.T) string {
+	log = logger.NewExampleLogger("Snapshot")
+	checkpointPath = filepath.Join(t.TempDir(), "checkpoint.bin")
+	t.Cleanup(func() { log, checkpointPath = nil, "" })
+	return checkpointPath
+}
+
+func TestSnapshotCheckpoint(t *testing.T) {
+	path := setTestCheckpointPath(t)
+	msHash := testSnapshotHash(1000)
+
+	// a new snapshot creation starts with an empty checkpoint
+	checkpoint := loadSnapshotCheckpoint(1000, msHash)
+	assert.Equal(t, milestone.Index(1000)-SolidEntryPointCheckThresholdPast, checkpoint.SolidEntryPointsIndex)
+	assert.Empty(t, checkpoint.SolidEntryPoints)
+	assert.Nil(t, checkpoint.Balances)
+	_, err := os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	// the intermediate results are written to the checkpoint file
+	checkpoint.SolidEntryPointsIndex = 990
+	checkpoint.SolidEntryPoints[string(testSnapshotHash(1))] = 985
+	checkpoint.save()
+	require.FileExists(t, path)
+	assert.NoFileExists(t, path+"_tmp")
+
+	// the snapshot creation for the same target milestone resumes from the checkpoint
+	resumed := loadSnapshotCheckpoint(1000, msHash)
+	assert.Equal(t, milestone.Index(990), resumed.SolidEntryPointsIndex)
+	assert.Equal(t, map[string]milestone.Index{string(testSnapshotHash(1)): 985}, resumed.SolidEntryPoints)
+	assert.Nil(t, resumed.Balances)
+
+	resumed.Balances = map[string]uint64{string(testSnapshotHash(2)): 100}
+	resumed.save()
+	assert.Equal(t, resumed.Balances, loadSnapshotCheckpoint(1000, msHash).Balances)
+
+	// the checkpoint is discarded if the target milestone changed
+	discarded := loadSnapshotCheckpoint(1010, testSnapshotHash(1010))
+	assert.Equal(t, milestone.Index(1010)-SolidEntryPointCheckThresholdPast, discarded.SolidEntryPointsIndex)
+	assert.Empty(t, discarded.SolidEntryPoints)
+	assert.Nil(t, discarded.Balances)
+
+	// or if the milestone of the target index changed
+	discarded = loadSnapshotCheckpoint(1000, testSnapshotHash(1001))
+	assert.Empty(t, discarded.SolidEntryPoints)
+	assert.Nil(t, discarded.Balances)
+
+	// the checkpoint of the new target milestone replaces the discarded one
+	discarded.save()
+	assert.Empty(t, loadSnapshotCheckpoint(1000, testSnapshotHash(1001)).SolidEntryPoints)
+	assert.Empty(t, loadSnapshotCheckpoint(1000, msHash).SolidEntryPoints)
+
+	// the checkpoint is removed once the snapshot was created
+	discarded.remove()
+	assert.NoFileExists(t, path)
+}
+
+func TestSnapshotCheckpointCorrupted(t *testing.T) {
+	path := setTestCheckpointPath(t)
+	require.NoError(t, ioutil.WriteFile(path, []byte("corrupted"), 0600))
+
+	// a corrupted checkpoint is ignored
+	checkpoint := loadSnapshotCheckpoint(1000, testSnapshotHash(1000))
+	assert.Empty(t, checkpoint.SolidEntryPoints)
+	assert.Equal(t, path, checkpoint.path)
+
+	_, resumable := resumableSnapshotTargetIndex(1000)
+	assert.False(t, resumable)
+}
+
+func TestResumableSnapshotTargetIndex(t *testing.T) {
+	tangle.ConfigureStorages(mapdb.NewMapDB(), mapdb.NewMapDB(), mapdb.NewMapDB(), profile.Caches{})
+	defer tangle.ShutdownStorages()
+	tangle.SetSnapshotInfo(&tangle.SnapshotInfo{CoordinatorAddress: hornet.NullHashBytes, Hash: hornet.NullHashBytes, SnapshotIndex: 900})
+
+	path := setTestCheckpointPath(t)
+
+	// nothing to resume without a checkpoint
+	_, resumable := resumableSnapshotTargetIndex(1010)
+	assert.False(t, resumable)
+
+	loadSnapshotCheckpoint(1000, testSnapshotHash(1000)).save()
+	require.FileExists(t, path)
+
+	// an interrupted snapshot creation is resumed for its target index
+	targetIndex, resumable := resumableSnapshotTargetIndex(1010)
+	assert.True(t, resumable)
+	assert.Equal(t, milestone.Index(1000), targetIndex)
+
+	// but not if its target index is newer than the current one
+	_, resumable = resumableSnapshotTargetIndex(990)
+	assert.False(t, resumable)
+
+	// or a newer snapshot was created in the meantime
+	tangle.SetSnapshotInfo(&tangle.SnapshotInfo{CoordinatorAddress: hornet.NullHashBytes, Hash: hornet.NullHashBytes, SnapshotIndex: 1000})
+	_, resumable = resumableSnapshotTargetIndex(1010)
+	assert.False(t, resumable)
+}
@@ -0,0 +1,361 @@
+package snapshot
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/iota.go/consts"
+
+	"github.com/iotaledger/hive.go/daemon"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	snapshotfile "github.com/gohornet/hornet/pkg/snapshot"
+)
+
+var (
+	// ErrDeltaSnapshotBaseMismatch is returned if a delta snapshot file was not created on top of the given local snapshot.
+	ErrDeltaSnapshotBaseMismatch = errors.New("delta snapshot file does not belong to the local snapshot file")
+)
+
+// readSnapshotFileHeader reads the header of the given local snapshot file.
+func readSnapshotFileHeader(filePath string) (*snapshotfile.Header, error) {
+
+	file, err := os.OpenFile(filePath, os.O_RDONLY, 0666)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	snapshotReader, err := snapshotfile.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshotReader.Header(), nil
+}
+
+// createScheduledSnapshotWithoutLocking creates a delta snapshot file on top of the local snapshot file,
+// if delta snapshots are enabled and the full snapshot interval is not reached yet.
+// Otherwise a full local snapshot file is created, which makes the existing delta snapshot file obsolete.
+func createScheduledSnapshotWithoutLocking(targetIndex milestone.Index, abortSignal <-chan struct{}) error {
+
+	// an interrupted snapshot creation is resumed for its target index instead of starting over for the current one
+	if checkpointIndex, resumable := resumableSnapshotTargetIndex(targetIndex); resumable {
+		targetIndex = checkpointIndex
+	}
+
+	localSnapshotPath := config.NodeConfig.GetString(config.CfgLocalSnapshotsPath)
+	deltaSnapshotPath := config.NodeConfig.GetString(config.CfgLocalSnapshotsDeltaPath)
+
+	base, reason := deltaSnapshotBase(localSnapshotPath, deltaSnapshotPath, targetIndex)
+	if base != nil {
+		return createSnapshotWithoutLocking(targetIndex, deltaSnapshotPath, base, true, abortSignal)
+	}
+
+	if fullSnapshotInterval != 0 {
+		log.Infof("creating full local snapshot instead of a delta snapshot: %s", reason)
+	}
+
+	if err := createLocalSnapshotWithoutLocking(targetIndex, localSnapshotPath, true, abortSignal); err != nil {
+		return err
+	}
+
+	if deltaSnapshotPath != "" {
+		os.Remove(deltaSnapshotPath)
+		os.Remove(deltaSnapshotPath + SignatureFileSuffix)
+	}
+
+	return nil
+}
+
+// deltaSnapshotBase returns the header of the local snapshot file the delta snapshot for the given target index is created on.
+// If no delta snapshot can be created, the reason is returned.
+func deltaSnapshotBase(localSnapshotPath string, deltaSnapshotPath string, targetIndex milestone.Index) (*snapshotfile.Header, string) {
+
+	if fullSnapshotInterval == 0 {
+		return nil, "delta snapshots are disabled"
+	}
+
+	if deltaSnapshotPath == "" {
+		return nil, "no delta snapshot path configured"
+	}
+
+	base, err := readSnapshotFileHeader(localSnapshotPath)
+	if err != nil {
+		return nil, fmt.Sprintf("local snapshot file '%s' can't be read: %v", localSnapshotPath, err)
+	}
+
+	if targetIndex <= base.MilestoneIndex {
+		return nil, fmt.Sprintf("local snapshot file is not older than the target index %d", targetIndex)
+	}
+
+	if targetIndex-base.MilestoneIndex >= fullSnapshotInterval {
+		return nil, fmt.Sprintf("full snapshot interval of %d milestones reached", fullSnapshotInterval)
+	}
+
+	// the ledger changes since the local snapshot are collected from the ledger diffs
+	if base.MilestoneIndex < tangle.GetSnapshotInfo().PruningIndex {
+		return nil, "the ledger diffs since the local snapshot file were already pruned"
+	}
+
+	cachedMs := tangle.GetMilestoneOrNil(base.MilestoneIndex) // bundle +1
+	if cachedMs == nil {
+		return nil, fmt.Sprintf("milestone %d of the local snapshot file not found", base.MilestoneIndex)
+	}
+	defer cachedMs.Release(true) // bundle -1
+
+	if !bytes.Equal(cachedMs.GetBundle().GetTailHash(), base.MilestoneHash) {
+		return nil, fmt.Sprintf("local snapshot file does not match milestone %d in the database", base.MilestoneIndex)
+	}
+
+	return base, ""
+}
+
+// getLedgerChanges sums up the ledger diffs of the milestones after the base index up to the target index.
+// The addresses with outgoing transfers are returned as spent addresses.
+func getLedgerChanges(baseIndex milestone.Index, targetIndex milestone.Index, abortSignal <-chan struct{}) (map[string]int64, map[string]struct{}, error) {
+
+	changes := make(map[string]int64)
+	spentAddresses := make(map[string]struct{})
+
+	ledgerDiffs := tangle.NewLedgerDiffIterator()
+	for msIndex := baseIndex + 1; msIndex <= targetIndex; msIndex++ {
+		diff, err := ledgerDiffs.LedgerDiff(msIndex, abortSignal)
+		if err != nil {
+			if err == tangle.ErrOperationAborted {
+				return nil, nil, ErrSnapshotCreationWasAborted
+			}
+			return nil, nil, errors.Wrap(ErrCritical, err.Error())
+		}
+
+		for addr, change := range diff {
+			changes[addr] += change
+			if change < 0 {
+				spentAddresses[addr] = struct{}{}
+			}
+		}
+	}
+
+	for addr, change := range changes {
+		if change == 0 {
+			delete(changes, addr)
+		}
+	}
+
+	return changes, spentAddresses, nil
+}
+
+// createDeltaSnapshotFile writes the ledger changes since the base local snapshot up to the milestone of the header into a delta snapshot file.
+// The solid entry points and seen milestones are written completely, since they replace the ones of the base.
+// The file format is described in pkg/snapshot.
+func createDeltaSnapshotFile(filePath string, base *snapshotfile.Header, lsh *localSnapshotHeader, abortSignal <-chan struct{}) ([]byte, error) {
+
+	changes, spentAddresses, err := getLedgerChanges(base.MilestoneIndex, lsh.msIndex, abortSignal)
+	if err != nil {
+		return nil, err
+	}
+
+	if !tangle.GetSnapshotInfo().IsSpentAddressesEnabled() || !config.NodeConfig.GetBool(config.CfgSpentAddressesEnabled) {
+		spentAddresses = nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
+		return nil, err
+	}
+
+	exportFile, err := os.OpenFile(filePath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0660)
+	if err != nil {
+		return nil, err
+	}
+	defer exportFile.Close()
+
+	deltaWriter, err := snapshotfile.NewDeltaWriter(exportFile, &snapshotfile.DeltaHeader{
+		BaseMilestoneHash:     base.MilestoneHash,
+		BaseMilestoneIndex:    base.MilestoneIndex,
+		MilestoneHash:         lsh.msHash,
+		MilestoneIndex:        lsh.msIndex,
+		MilestoneTimestamp:    lsh.msTimestamp,
+		SolidEntryPointsCount: int32(len(lsh.solidEntryPoints)),
+		SeenMilestonesCount:   int32(len(lsh.seenMilestones)),
+		LedgerChangesCount:    int32(len(changes)),
+		SpentAddressesCount:   int32(len(spentAddresses)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeIndexEntries(lsh.solidEntryPoints, deltaWriter.WriteSolidEntryPoint, abortSignal); err != nil {
+		return nil, err
+	}
+
+	if err := writeIndexEntries(lsh.seenMilestones, deltaWriter.WriteSeenMilestone, abortSignal); err != nil {
+		return nil, err
+	}
+
+	var written int
+	for addr, change := range changes {
+		select {
+		case <-abortSignal:
+			return nil, ErrSnapshotCreationWasAborted
+		default:
+		}
+
+		if written++; written%snapshotPauseBatchSize == 0 && !pauseSnapshotting(abortSignal) {
+			return nil, ErrSnapshotCreationWasAborted
+		}
+
+		if err := deltaWriter.WriteLedgerChange(hornet.Hash(addr), change); err != nil {
+			return nil, err
+		}
+	}
+
+	for addr := range spentAddresses {
+		if err := deltaWriter.WriteSpentAddress(hornet.Hash(addr)); err != nil {
+			return nil, err
+		}
+	}
+
+	return deltaWriter.Close()
+}
+
+// downloadDeltaSnapshotFile downloads the delta snapshot file from one of the configured delta download URLs,
+// so that it is chained onto the downloaded local snapshot file.
+// If the download fails, a former delta snapshot file is only chained if it belongs to the downloaded local snapshot file.
+func downloadDeltaSnapshotFile() {
+
+	urls := config.NodeConfig.GetStringSlice(config.CfgLocalSnapshotsDeltaDownloadURLs)
+	deltaSnapshotPath := config.NodeConfig.GetString(config.CfgLocalSnapshotsDeltaPath)
+	if len(urls) == 0 || deltaSnapshotPath == "" {
+		return
+	}
+
+	log.Infof("Downloading delta snapshot from one of the provided sources %v", urls)
+	if err := downloadSnapshotFile(deltaSnapshotPath, urls, nil); err != nil {
+		log.Warnf("Downloading the delta snapshot file failed, loading the snapshot without it: %s", err)
+		return
+	}
+	log.Info("Delta snapshot download finished")
+}
+
+// chainDeltaSnapshotFile applies the configured delta snapshot file to the staged local snapshot, if it exists.
+// A delta snapshot file which was created on top of another local snapshot file is ignored.
+func chainDeltaSnapshotFile(staged *stagedSnapshot) error {
+
+	deltaSnapshotPath := config.NodeConfig.GetString(config.CfgLocalSnapshotsDeltaPath)
+	if deltaSnapshotPath == "" {
+		return nil
+	}
+
+	if _, err := os.Stat(deltaSnapshotPath); os.IsNotExist(err) {
+		return nil
+	}
+
+	baseIndex := staged.msIndex
+	if err := stageDeltaSnapshotFile(deltaSnapshotPath, staged); err != nil {
+		if errors.Is(err, ErrDeltaSnapshotBaseMismatch) {
+			log.Warnf("Ignoring delta snapshot file '%s': %s", deltaSnapshotPath, err)
+			return nil
+		}
+		return err
+	}
+
+	log.Infof("Applied delta snapshot file '%s', snapshot index %d -> %d", deltaSnapshotPath, baseIndex, staged.msIndex)
+
+	return nil
+}
+
+// stageDeltaSnapshotFile reads and verifies the given delta snapshot file and applies it to the staged local snapshot.
+// The spent addresses are imported once the staged snapshot is applied, like the ones of the local snapshot file.
+func stageDeltaSnapshotFile(filePath string, staged *stagedSnapshot) error {
+
+	if err := verifySnapshotFile(filePath); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(filePath, os.O_RDONLY, 0666)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	deltaReader, err := snapshotfile.NewDeltaReader(file)
+	if err != nil {
+		return err
+	}
+	header := deltaReader.Header()
+
+	if header.BaseMilestoneIndex != staged.msIndex || !bytes.Equal(header.BaseMilestoneHash, staged.msHash) {
+		return errors.Wrapf(ErrDeltaSnapshotBaseMismatch, "base milestone %d, local snapshot milestone %d", header.BaseMilestoneIndex, staged.msIndex)
+	}
+
+	log.Info("reading delta solid entry points")
+
+	solidEntryPoints := make(map[string]milestone.Index)
+	if err := deltaReader.ReadSolidEntryPoints(snapshotReadChunkSize, stageIndexEntries(solidEntryPoints)); err != nil {
+		return wrapSnapshotImportError(err, "solidEntryPoints")
+	}
+
+	log.Info("reading delta seen milestones")
+
+	seenMilestones := make(map[string]milestone.Index)
+	if err := deltaReader.ReadSeenMilestones(snapshotReadChunkSize, stageIndexEntries(seenMilestones)); err != nil {
+		return wrapSnapshotImportError(err, "seenMilestones")
+	}
+
+	log.Info("reading delta ledger changes")
+
+	// the changes are applied to a copy, so that the staged ledger state stays untouched if the delta is invalid
+	ledgerState := make(map[string]uint64, len(staged.ledgerState))
+	for addr, balance := range staged.ledgerState {
+		ledgerState[addr] = balance
+	}
+
+	if err := deltaReader.ReadLedgerChanges(snapshotReadChunkSize, func(entries []*snapshotfile.LedgerChangeEntry) error {
+		if daemon.IsStopped() {
+			return ErrSnapshotImportWasAborted
+		}
+
+		for _, entry := range entries {
+			balance := int64(ledgerState[string(entry.Address)]) + entry.Change
+			if balance < 0 {
+				return fmt.Errorf("negative balance for address %s", entry.Address.Trytes())
+			}
+
+			if balance == 0 {
+				delete(ledgerState, string(entry.Address))
+				continue
+			}
+			ledgerState[string(entry.Address)] = uint64(balance)
+		}
+		return nil
+	}); err != nil {
+		return wrapSnapshotImportError(err, "ledgerChanges")
+	}
+
+	var total uint64
+	for _, value := range ledgerState {
+		total += value
+	}
+
+	if total != consts.TotalSupply {
+		return errors.Wrapf(ErrInvalidBalance, "%d != %d", total, consts.TotalSupply)
+	}
+
+	staged.msHash = header.MilestoneHash
+	staged.msIndex = header.MilestoneIndex
+	staged.msTimestamp = header.MilestoneTimestamp
+	staged.solidEntryPoints = solidEntryPoints
+	staged.seenMilestones = seenMilestones
+	staged.ledgerState = ledgerState
+	staged.spentAddrsCount += header.SpentAddressesCount
+	staged.files = append(staged.files, &tangle.StagedSnapshotFile{Path: filePath, Delta: true})
+
+	return nil
+}
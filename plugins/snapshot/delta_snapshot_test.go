@@ -0,0 +1,156 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	snapshotfile "github.com/gohornet/hornet/pkg/snapshot"
+)
+
+func testSnapshotHash(i int64) hornet.Hash {
+	return hornet.HashFromHashTrytes(trinary.IntToTrytes(i, consts.HashTrytesSize))
+}
+
+// writes a local snapshot file with a solid entry point, a seen milestone and the given balances.
+func writeTestLocalSnapshotFile(t *testing.T, filePath string, msIndex milestone.Index, balances map[int64]uint64) {
+	file, err := os.OpenFile(filePath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0660)
+	require.NoError(t, err)
+	defer file.Close()
+
+	w, err := snapshotfile.NewWriter(file, &snapshotfile.Header{
+		MilestoneHash:         testSnapshotHash(int64(msIndex)),
+		MilestoneIndex:        msIndex,
+		MilestoneTimestamp:    1600000000,
+		SolidEntryPointsCount: 1,
+		SeenMilestonesCount:   1,
+		LedgerEntriesCount:    int32(len(balances)),
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, w.WriteSolidEntryPoint(testSnapshotHash(int64(msIndex)), msIndex))
+	require.NoError(t, w.WriteSeenMilestone(testSnapshotHash(int64(msIndex+1)), msIndex+1))
+	for addr, balance := range balances {
+		require.NoError(t, w.WriteBalance(testSnapshotHash(addr), balance))
+	}
+	_, err = w.Close()
+	require.NoError(t, err)
+}
+
+// writes a delta snapshot file with a solid entry point and the given ledger changes on top of the given base milestone.
+func writeTestDeltaSnapshotFile(t *testing.T, filePath string, baseIndex milestone.Index, baseHash hornet.Hash, msIndex milestone.Index, changes map[int64]int64) {
+	file, err := os.OpenFile(filePath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0660)
+	require.NoError(t, err)
+	defer file.Close()
+
+	w, err := snapshotfile.NewDeltaWriter(file, &snapshotfile.DeltaHeader{
+		BaseMilestoneHash:     baseHash,
+		BaseMilestoneIndex:    baseIndex,
+		MilestoneHash:         testSnapshotHash(int64(msIndex)),
+		MilestoneIndex:        msIndex,
+		MilestoneTimestamp:    1600001000,
+		SolidEntryPointsCount: 1,
+		LedgerChangesCount:    int32(len(changes)),
+		SpentAddressesCount:   1,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, w.WriteSolidEntryPoint(testSnapshotHash(int64(msIndex)), msIndex))
+	for addr, change := range changes {
+		require.NoError(t, w.WriteLedgerChange(testSnapshotHash(addr), change))
+	}
+	require.NoError(t, w.WriteSpentAddress(testSnapshotHash(100)))
+	_, err = w.Close()
+	require.NoError(t, err)
+}
+
+func TestStageDeltaSnapshotFile(t *testing.T) {
+	log = logger.NewExampleLogger("Snapshot")
+	defer func() { log = nil }()
+
+	dir := t.TempDir()
+	localSnapshotPath := filepath.Join(dir, "export.bin")
+	deltaSnapshotPath := filepath.Join(dir, "export_delta.bin")
+
+	writeTestLocalSnapshotFile(t, localSnapshotPath, 1000, map[int64]uint64{1: consts.TotalSupply - 100, 2: 100})
+
+	stage := func() *stagedSnapshot {
+		staged, err := stageSnapshotFile(localSnapshotPath)
+		require.NoError(t, err)
+		return staged
+	}
+
+	// the staged snapshot is left untouched if the delta is refused
+	assertUntouched := func(staged *stagedSnapshot) {
+		assert.Equal(t, milestone.Index(1000), staged.msIndex)
+		assert.Equal(t, map[string]uint64{
+			string(testSnapshotHash(1)): consts.TotalSupply - 100,
+			string(testSnapshotHash(2)): 100,
+		}, staged.ledgerState)
+		assert.Len(t, staged.files, 1)
+	}
+
+	t.Run("applied", func(t *testing.T) {
+		writeTestDeltaSnapshotFile(t, deltaSnapshotPath, 1000, testSnapshotHash(1000), 1010, map[int64]int64{1: 50, 2: -100, 3: 50})
+
+		staged := stage()
+		require.NoError(t, stageDeltaSnapshotFile(deltaSnapshotPath, staged))
+
+		assert.Equal(t, milestone.Index(1010), staged.msIndex)
+		assert.Equal(t, testSnapshotHash(1010), staged.msHash)
+		assert.Equal(t, int64(1600001000), staged.msTimestamp)
+
+		// emptied addresses are removed from the ledger state
+		assert.Equal(t, map[string]uint64{
+			string(testSnapshotHash(1)): consts.TotalSupply - 50,
+			string(testSnapshotHash(3)): 50,
+		}, staged.ledgerState)
+
+		// the solid entry points and seen milestones of the base are replaced
+		assert.Equal(t, map[string]milestone.Index{string(testSnapshotHash(1010)): 1010}, staged.solidEntryPoints)
+		assert.Empty(t, staged.seenMilestones)
+
+		// the spent addresses of both files are imported
+		assert.EqualValues(t, 1, staged.spentAddrsCount)
+		require.Len(t, staged.files, 2)
+		assert.Equal(t, deltaSnapshotPath, staged.files[1].Path)
+		assert.True(t, staged.files[1].Delta)
+	})
+
+	t.Run("base mismatch", func(t *testing.T) {
+		writeTestDeltaSnapshotFile(t, deltaSnapshotPath, 990, testSnapshotHash(990), 1010, map[int64]int64{})
+		staged := stage()
+		assert.True(t, errors.Is(stageDeltaSnapshotFile(deltaSnapshotPath, staged), ErrDeltaSnapshotBaseMismatch))
+		assertUntouched(staged)
+
+		// the base milestone has to match by its hash as well
+		writeTestDeltaSnapshotFile(t, deltaSnapshotPath, 1000, testSnapshotHash(990), 1010, map[int64]int64{})
+		staged = stage()
+		assert.True(t, errors.Is(stageDeltaSnapshotFile(deltaSnapshotPath, staged), ErrDeltaSnapshotBaseMismatch))
+		assertUntouched(staged)
+	})
+
+	t.Run("negative balance", func(t *testing.T) {
+		writeTestDeltaSnapshotFile(t, deltaSnapshotPath, 1000, testSnapshotHash(1000), 1010, map[int64]int64{1: 101, 2: -101})
+		staged := stage()
+		assert.True(t, errors.Is(stageDeltaSnapshotFile(deltaSnapshotPath, staged), ErrSnapshotImportFailed))
+		assertUntouched(staged)
+	})
+
+	t.Run("total supply", func(t *testing.T) {
+		writeTestDeltaSnapshotFile(t, deltaSnapshotPath, 1000, testSnapshotHash(1000), 1010, map[int64]int64{2: -50})
+		staged := stage()
+		assert.True(t, errors.Is(stageDeltaSnapshotFile(deltaSnapshotPath, staged), ErrInvalidBalance))
+		assertUntouched(staged)
+	})
+}
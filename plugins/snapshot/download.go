@@ -1,15 +1,40 @@
 package snapshot
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/dustin/go-humanize"
+	"github.com/pkg/errors"
+
 	"github.com/iotaledger/hive.go/daemon"
+
+	"github.com/gohornet/hornet/pkg/config"
+)
+
+const (
+	// the amount of attempts to download the snapshot file from a single source, an interrupted download is resumed.
+	downloadAttemptsPerSource = 3
+	// the suffix of the file which holds the partial download of a snapshot file.
+	downloadTmpSuffix = ".tmp"
+	// the suffix of the file next to the partial download which holds the URL it is downloaded from.
+	downloadSourceSuffix = ".src"
+)
+
+var (
+	// ErrSnapshotChecksumMismatch is returned if a downloaded snapshot file doesn't match the configured sha256 checksum.
+	ErrSnapshotChecksumMismatch = errors.New("snapshot file checksum mismatch")
+
+	// the expected sha256 checksum of the local snapshot file to download, nil if the checksum is not verified.
+	downloadChecksum []byte
 )
 
 // WriteCounter counts the number of bytes written to it. It implements to the io.Writer interface
@@ -51,62 +76,203 @@ func (wc *WriteCounter) PrintProgress() {
 	fmt.Printf("\rDownloading... %s/%s (%s/s)", humanize.Bytes(wc.Total), humanize.Bytes(wc.Expected), humanize.Bytes(bytesPerSecond))
 }
 
-func downloadSnapshotFile(filepath string, urls []string) error {
+// downloadSnapshotFile downloads the snapshot file from the first of the given sources which provides a valid file.
+// An interrupted download is resumed from the same source. If an expected sha256 checksum is given, the file must match it.
+func downloadSnapshotFile(filePath string, urls []string, expectedChecksum []byte) error {
+
+	// the file gets a tmp file extension, this means we won't overwrite a
+	// file until it's downloaded and verified, but we'll remove the tmp extension afterwards.
+	tmpFilePath := filePath + downloadTmpSuffix
 
 	// Try to download a snapshot from one of the provided sources, break if download was successful
-	downloadOK := false
 	for _, url := range urls {
 		log.Infof("Downloading snapshot from %s", url)
 
-		// Create the file, but give it a tmp file extension, this means we won't overwrite a
-		// file until it's downloaded, but we'll remove the tmp extension once downloaded.
-		out, err := os.Create(filepath + ".tmp")
-		if err != nil {
-			return err
-		}
-
-		// Get the data
-		resp, err := http.Get(url)
-		if err != nil {
+		if err := downloadSnapshotFileFromSource(tmpFilePath, url); err != nil {
 			log.Warnf("Downloading snapshot from %s failed with %v", url, err)
-			out.Close()
+			if errors.Is(err, ErrSnapshotDownloadWasAborted) {
+				return err
+			}
 			continue
 		}
-		if resp.StatusCode != http.StatusOK {
-			log.Warnf("Downloading snapshot from %s failed. Server returned %d", url, resp.StatusCode)
-			out.Close()
+
+		if err := verifyDownloadedSnapshotFile(tmpFilePath, url, expectedChecksum); err != nil {
+			log.Warnf("Verifying snapshot from %s failed with %v", url, err)
+			removePartialDownload(tmpFilePath)
 			continue
 		}
 
-		defer resp.Body.Close()
+		if err := os.Rename(tmpFilePath, filePath); err != nil {
+			return err
+		}
 
-		// Create our progress reporter and pass it to be used alongside our writer
-		counter := &WriteCounter{
-			Expected: uint64(resp.ContentLength),
+		if len(trustedKeys) > 0 {
+			if err := os.Rename(tmpFilePath+SignatureFileSuffix, filePath+SignatureFileSuffix); err != nil {
+				return err
+			}
 		}
-		if _, err = io.Copy(out, io.TeeReader(resp.Body, counter)); err != nil {
-			log.Warnf("Downloading snapshot from %s failed with %v", url, err)
-			out.Close()
-			continue
+
+		os.Remove(tmpFilePath + downloadSourceSuffix)
+		return nil
+	}
+
+	// No download possible
+	return ErrSnapshotDownloadNoValidSource
+}
+
+// downloadSnapshotFileFromSource downloads the snapshot file from the given source.
+// A partial download of the same source is resumed, the partial download of another source is discarded.
+func downloadSnapshotFileFromSource(tmpFilePath string, url string) error {
+
+	if source, err := ioutil.ReadFile(tmpFilePath + downloadSourceSuffix); err != nil || string(source) != url {
+		removePartialDownload(tmpFilePath)
+	}
+
+	if err := ioutil.WriteFile(tmpFilePath+downloadSourceSuffix, []byte(url), 0660); err != nil {
+		return err
+	}
+
+	var err error
+	for attempt := 1; attempt <= downloadAttemptsPerSource; attempt++ {
+		if err = downloadToFile(tmpFilePath, url); err == nil || errors.Is(err, ErrSnapshotDownloadWasAborted) {
+			return err
 		}
 
-		// The progress use the same line so print a new line once it's finished downloading
-		fmt.Print("\n")
+		if attempt < downloadAttemptsPerSource {
+			log.Warnf("Downloading snapshot from %s failed with %v, resuming (attempt %d/%d)", url, err, attempt+1, downloadAttemptsPerSource)
+		}
+	}
+	return err
+}
 
-		downloadOK = true
+// downloadToFile downloads the given URL into the file. If the file already contains data, only the remaining part is requested.
+func downloadToFile(filePath string, url string) error {
 
-		// Close the file without defer so it can happen before Rename()
-		out.Close()
-		break
+	out, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE, 0660)
+	if err != nil {
+		return err
 	}
+	defer out.Close()
 
-	// No download possible
-	if !downloadOK {
-		return fmt.Errorf(ErrSnapshotDownloadNoValidSource.Error())
+	offset, err := out.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		log.Infof("Resuming snapshot download at %s", humanize.Bytes(uint64(offset)))
+
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// the partial download is already complete
+		return nil
+
+	case resp.StatusCode == http.StatusOK:
+		// the server doesn't support resuming, start from the beginning
+		if err := out.Truncate(0); err != nil {
+			return err
+		}
+		if _, err := out.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		offset = 0
+
+	default:
+		return fmt.Errorf("server returned %d", resp.StatusCode)
 	}
 
-	if err := os.Rename(filepath+".tmp", filepath); err != nil {
+	// Create our progress reporter and pass it to be used alongside our writer
+	counter := &WriteCounter{
+		Expected: uint64(offset + resp.ContentLength),
+		Total:    uint64(offset),
+		Last:     uint64(offset),
+	}
+	_, err = io.Copy(out, io.TeeReader(resp.Body, counter))
+
+	// The progress use the same line so print a new line once it's finished downloading
+	fmt.Print("\n")
+
+	return err
+}
+
+// verifyDownloadedSnapshotFile checks the sha256 hash at the end of the downloaded snapshot file, to detect corrupted downloads,
+// the expected sha256 checksum of the whole file, if given, and the signature of the file, if trusted keys are configured.
+func verifyDownloadedSnapshotFile(filePath string, url string, expectedChecksum []byte) error {
+
+	if _, err := snapshotFileHash(filePath); err != nil {
 		return err
 	}
+
+	if len(expectedChecksum) > 0 {
+		checksum, err := fileChecksum(filePath)
+		if err != nil {
+			return err
+		}
+
+		if !bytes.Equal(checksum, expectedChecksum) {
+			return errors.Wrapf(ErrSnapshotChecksumMismatch, "expected %x, got %x", expectedChecksum, checksum)
+		}
+	}
+
+	if err := downloadSnapshotSignatureFile(filePath+SignatureFileSuffix, url); err != nil {
+		return errors.Wrapf(err, "downloading snapshot signature from %s failed", url+SignatureFileSuffix)
+	}
+
+	return verifySnapshotFile(filePath)
+}
+
+// fileChecksum returns the sha256 checksum of the whole file.
+func fileChecksum(filePath string) ([]byte, error) {
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	checksum := sha256.New()
+	if _, err := io.Copy(checksum, file); err != nil {
+		return nil, err
+	}
+
+	return checksum.Sum(nil), nil
+}
+
+// removePartialDownload removes the partial download of a snapshot file along with its source and signature.
+func removePartialDownload(tmpFilePath string) {
+	os.Remove(tmpFilePath)
+	os.Remove(tmpFilePath + downloadSourceSuffix)
+	os.Remove(tmpFilePath + SignatureFileSuffix)
+}
+
+// configureSnapshotDownload loads the expected checksum of the local snapshot file to download.
+func configureSnapshotDownload() error {
+
+	checksumHex := config.NodeConfig.GetString(config.CfgLocalSnapshotsDownloadSHA256)
+	if checksumHex == "" {
+		return nil
+	}
+
+	checksum, err := hex.DecodeString(checksumHex)
+	if err != nil || len(checksum) != sha256.Size {
+		return fmt.Errorf("invalid sha256 checksum under config option '%s': %s", config.CfgLocalSnapshotsDownloadSHA256, checksumHex)
+	}
+
+	downloadChecksum = checksum
 	return nil
 }
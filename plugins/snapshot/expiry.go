@@ -0,0 +1,206 @@
+package snapshot
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/guards"
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/gohornet/hornet/pkg/metrics"
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+)
+
+var (
+	// ErrInvalidExpiryRule is returned if an expiry rule could not be parsed.
+	ErrInvalidExpiryRule = errors.New("invalid expiry rule")
+	// ErrExpiryAborted is returned if the expiry of transactions was aborted.
+	ErrExpiryAborted = errors.New("expiry was aborted")
+)
+
+// expiryRule deletes zero-value transactions with the given tag prefix
+// after they were confirmed for the given amount of milestones.
+type expiryRule struct {
+	tagPrefix trinary.Trytes
+	// the binary representation of all full bytes of the tag prefix.
+	// it is used to narrow the iteration over the tags storage.
+	tagPrefixBytes []byte
+	milestones     milestone.Index
+}
+
+// parseExpiryRules parses the expiry rules in the format 'TAGPREFIX:milestones'.
+func parseExpiryRules(rules []string) ([]*expiryRule, error) {
+
+	expiryRules := make([]*expiryRule, 0, len(rules))
+	for _, rule := range rules {
+		parts := strings.Split(rule, ":")
+		if len(parts) != 2 {
+			return nil, errors.Wrapf(ErrInvalidExpiryRule, "'%s': expected format 'TAGPREFIX:milestones'", rule)
+		}
+
+		tagPrefix := strings.ToUpper(parts[0])
+		if len(tagPrefix) == 0 || len(tagPrefix) > consts.TagTrinarySize/3 || !guards.IsTrytes(tagPrefix) {
+			return nil, errors.Wrapf(ErrInvalidExpiryRule, "'%s': invalid tag prefix", rule)
+		}
+
+		milestones, err := strconv.ParseUint(parts[1], 10, 32)
+		if err != nil || milestones == 0 {
+			return nil, errors.Wrapf(ErrInvalidExpiryRule, "'%s': invalid amount of milestones", rule)
+		}
+
+		// every byte of the t5b1 encoding holds 5 trits
+		paddedTag := trinary.MustPad(tagPrefix, consts.TagTrinarySize/3)
+		tagPrefixBytes := hornet.HashFromTagTrytes(paddedTag)[:len(tagPrefix)*3/5]
+
+		expiryRules = append(expiryRules, &expiryRule{
+			tagPrefix:      tagPrefix,
+			tagPrefixBytes: tagPrefixBytes,
+			milestones:     milestone.Index(milestones),
+		})
+	}
+
+	return expiryRules, nil
+}
+
+// expiredTxHashes returns the hashes of all transactions matching the rule, which were confirmed
+// at or before the target index.
+func (r *expiryRule) expiredTxHashes(targetIndex milestone.Index, abortSignal <-chan struct{}) (hornet.Hashes, error) {
+
+	var txHashes hornet.Hashes
+	var aborted bool
+
+	tangle.ForEachTagWithPrefix(r.tagPrefixBytes, func(txTag hornet.Hash, txHash hornet.Hash) bool {
+		select {
+		case <-abortSignal:
+			aborted = true
+			return false
+		default:
+		}
+
+		if !strings.HasPrefix(txTag.Trytes(), r.tagPrefix) {
+			return true
+		}
+
+		cachedTxMeta := tangle.GetCachedTxMetadataOrNil(txHash) // meta +1
+		if cachedTxMeta == nil {
+			return true
+		}
+		defer cachedTxMeta.Release(true) // meta -1
+
+		if confirmed, at := cachedTxMeta.GetMetadata().GetConfirmed(); !confirmed || at > targetIndex {
+			return true
+		}
+
+		txHashes = append(txHashes, txHash)
+		return true
+	}, false)
+
+	if aborted {
+		return nil, ErrExpiryAborted
+	}
+
+	return txHashes, nil
+}
+
+// expirableBundles returns the bundles of the given transaction if all of them can be expired.
+// Bundles which move funds and milestones are never expired.
+// bundle +1
+func expirableBundles(txHash hornet.Hash) tangle.CachedBundles {
+
+	cachedBndls := tangle.GetBundlesOfTransactionOrNil(txHash, true) // bundle +1
+	if cachedBndls == nil {
+		return nil
+	}
+
+	for _, cachedBndl := range cachedBndls {
+		bndl := cachedBndl.GetBundle()
+		if !bndl.IsConfirmed() || !bndl.IsValueSpam() || bndl.IsMilestone() {
+			cachedBndls.Release(true) // bundle -1
+			return nil
+		}
+
+		for _, bndlTxHash := range bndl.GetTxHashes() {
+			cachedTxMeta := tangle.GetCachedTxMetadataOrNil(bndlTxHash) // meta +1
+			if cachedTxMeta == nil {
+				continue
+			}
+			isValue := cachedTxMeta.GetMetadata().IsValue()
+			cachedTxMeta.Release(true) // meta -1
+
+			if isValue || tangle.SolidEntryPointsContain(bndlTxHash) {
+				cachedBndls.Release(true) // bundle -1
+				return nil
+			}
+		}
+	}
+
+	return cachedBndls
+}
+
+// expireTransactions deletes the transactions of all bundles which match the expiry rules.
+// The metadata of the transactions is kept, so that the tangle can still be walked.
+// It is removed by the regular pruning afterwards.
+func expireTransactions(solidMilestoneIndex milestone.Index, rules []*expiryRule, abortSignal <-chan struct{}) error {
+
+	for _, rule := range rules {
+		if solidMilestoneIndex <= rule.milestones {
+			// Not enough history
+			continue
+		}
+		targetIndex := solidMilestoneIndex - rule.milestones
+
+		ts := time.Now()
+
+		txHashes, err := rule.expiredTxHashes(targetIndex, abortSignal)
+		if err != nil {
+			return err
+		}
+
+		txsToDeleteMap := make(map[string]struct{})
+		for _, txHash := range txHashes {
+			if _, exists := txsToDeleteMap[string(txHash)]; exists {
+				continue
+			}
+
+			cachedBndls := expirableBundles(txHash) // bundle +1
+			if cachedBndls == nil {
+				continue
+			}
+
+			for _, cachedBndl := range cachedBndls {
+				cachedTailTxMeta := cachedBndl.GetBundle().GetTailMetadata() // meta +1
+				for txToRemove := range tangle.RemoveTransactionFromBundle(cachedTailTxMeta.GetMetadata()) {
+					txsToDeleteMap[txToRemove] = struct{}{}
+				}
+				cachedTailTxMeta.Release(true) // meta -1
+			}
+			cachedBndls.Release(true) // bundle -1
+		}
+
+		for txHashToDelete := range txsToDeleteMap {
+			cachedTx := tangle.GetCachedTransactionOrNil(hornet.Hash(txHashToDelete)) // tx +1
+			if cachedTx == nil {
+				continue
+			}
+
+			cachedTx.ConsumeTransaction(func(tx *hornet.Transaction) { // tx -1
+				tangle.DeleteTag(tx.GetTag(), tx.GetTxHash())
+				tangle.DeleteAddress(tx.GetAddress(), tx.GetTxHash())
+				tangle.DeleteTransactionKeepMetadata(tx.GetTxHash())
+			})
+			metrics.SharedServerMetrics.ExpiredTransactions.Inc()
+		}
+
+		if len(txsToDeleteMap) > 0 {
+			log.Infof("Expiry of tag prefix '%s' up to milestone (%d) took %v. Expired %d transactions.", rule.tagPrefix, targetIndex, time.Since(ts), len(txsToDeleteMap))
+		}
+	}
+
+	return nil
+}
@@ -1,11 +1,6 @@
 package snapshot
 
 import (
-	"bufio"
-	"crypto/sha256"
-	"encoding/binary"
-	"io"
-	"math"
 	"os"
 	"path/filepath"
 	"time"
@@ -21,6 +16,8 @@ import (
 	"github.com/gohornet/hornet/pkg/model/hornet"
 	"github.com/gohornet/hornet/pkg/model/milestone"
 	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/scheduler"
+	snapshotfile "github.com/gohornet/hornet/pkg/snapshot"
 	"github.com/gohornet/hornet/plugins/gossip"
 	tanglePlugin "github.com/gohornet/hornet/plugins/tangle"
 )
@@ -29,14 +26,14 @@ const (
 	SpentAddressesImportBatchSize       = 100000
 	SolidEntryPointCheckThresholdPast   = 50
 	SolidEntryPointCheckThresholdFuture = 50
+
+	// the amount of entries which are read from a snapshot file at once.
+	snapshotReadChunkSize = 10000
 )
 
 var (
-	SupportedLocalSnapshotFileVersions = []byte{4}
-
-	ErrCritical                 = errors.New("critical error")
-	ErrUnsupportedLSFileVersion = errors.New("unsupported local snapshot file version")
-	ErrApproverTxNotFound       = errors.New("approver transaction not found")
+	ErrCritical           = errors.New("critical error")
+	ErrApproverTxNotFound = errors.New("approver transaction not found")
 )
 
 // isSolidEntryPoint checks whether any direct approver of the given transaction was confirmed by a milestone which is above the target milestone.
@@ -173,16 +170,25 @@ func shouldTakeSnapshot(solidMilestoneIndex milestone.Index) bool {
 	return solidMilestoneIndex-(snapshotDepth+snapshotInterval) >= snapshotInfo.SnapshotIndex
 }
 
-func getSolidEntryPoints(targetIndex milestone.Index, abortSignal <-chan struct{}) (map[string]milestone.Index, error) {
+// getSolidEntryPoints walks the cones of the milestones below the target index to find the solid entry points.
+// If a checkpoint is given, the walk resumes at the checkpoint and the checkpoint is saved regularly.
+func getSolidEntryPoints(targetIndex milestone.Index, checkpoint *snapshotCheckpoint, progress *progressTracker, abortSignal <-chan struct{}) (map[string]milestone.Index, error) {
+
+	if checkpoint == nil {
+		checkpoint = newSnapshotCheckpoint(targetIndex, nil, "")
+	}
+
+	solidEntryPoints := checkpoint.SolidEntryPoints
+	startIndex := checkpoint.SolidEntryPointsIndex
 
-	solidEntryPoints := make(map[string]milestone.Index)
+	progress.startStep(ProgressStepSolidEntryPoints, int(targetIndex-startIndex)+1)
 
 	// HINT: Check if "old solid entry points are still valid" is skipped in HORNET,
 	//		 since they should all be found by iterating the milestones to a certain depth under targetIndex, because the tipselection for COO was changed.
 	//		 When local snapshots were introduced in IRI, there was the problem that COO approved really old tx as valid tips, which is not the case anymore.
 
 	// Iterate from a reasonable old milestone to the target index to check for solid entry points
-	for milestoneIndex := targetIndex - SolidEntryPointCheckThresholdPast; milestoneIndex <= targetIndex; milestoneIndex++ {
+	for milestoneIndex := startIndex; milestoneIndex <= targetIndex; milestoneIndex++ {
 		select {
 		case <-abortSignal:
 			return nil, ErrSnapshotCreationWasAborted
@@ -203,6 +209,10 @@ func getSolidEntryPoints(targetIndex milestone.Index, abortSignal <-chan struct{
 			return nil, err
 		}
 
+		if !pauseSnapshotting(abortSignal) {
+			return nil, ErrSnapshotCreationWasAborted
+		}
+
 		for _, approvee := range approvees {
 			select {
 			case <-abortSignal:
@@ -234,6 +244,13 @@ func getSolidEntryPoints(targetIndex milestone.Index, abortSignal <-chan struct{
 				}
 			}
 		}
+
+		checkpoint.SolidEntryPointsIndex = milestoneIndex + 1
+		if (milestoneIndex-startIndex+1)%snapshotCheckpointInterval == 0 || milestoneIndex == targetIndex {
+			checkpoint.save()
+		}
+
+		progress.milestoneProcessed(milestoneIndex, 0)
 	}
 
 	return solidEntryPoints, nil
@@ -309,66 +326,86 @@ func createSnapshotFile(filePath string, lsh *localSnapshotHeader, abortSignal <
 	}
 	defer exportFile.Close()
 
-	// write into the file with an 8MB buffer
-	fileBufWriter := bufio.NewWriterSize(exportFile, 4096*2)
-
 	// write header, SEPs, seen milestones and ledger
 	// with a WRONG spent addresses count
-	if err := lsh.WriteToBuffer(fileBufWriter, abortSignal); err != nil {
+	snapshotWriter, err := snapshotfile.NewWriter(exportFile, &snapshotfile.Header{
+		MilestoneHash:         lsh.msHash,
+		MilestoneIndex:        lsh.msIndex,
+		MilestoneTimestamp:    lsh.msTimestamp,
+		SolidEntryPointsCount: int32(len(lsh.solidEntryPoints)),
+		SeenMilestonesCount:   int32(len(lsh.seenMilestones)),
+		LedgerEntriesCount:    int32(len(lsh.balances)),
+		SpentAddressesCount:   lsh.spentAddressesCount,
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	// flush remains of header and content without spent addresses to file
-	if err := fileBufWriter.Flush(); err != nil {
+	if err := writeIndexEntries(lsh.solidEntryPoints, snapshotWriter.WriteSolidEntryPoint, abortSignal); err != nil {
 		return nil, err
 	}
 
-	if tangle.GetSnapshotInfo().IsSpentAddressesEnabled() &&
-		config.NodeConfig.GetBool(config.CfgSpentAddressesEnabled) {
+	if err := writeIndexEntries(lsh.seenMilestones, snapshotWriter.WriteSeenMilestone, abortSignal); err != nil {
+		return nil, err
+	}
 
-		// stream spent addresses into the file
-		spentAddressesCount, err := tangle.StreamSpentAddressesToWriter(fileBufWriter, abortSignal)
-		if err != nil {
-			return nil, err
+	var written int
+	for addr, val := range lsh.balances {
+		select {
+		case <-abortSignal:
+			return nil, ErrSnapshotCreationWasAborted
+		default:
 		}
 
-		if err := fileBufWriter.Flush(); err != nil {
+		if written++; written%snapshotPauseBatchSize == 0 && !pauseSnapshotting(abortSignal) {
+			return nil, ErrSnapshotCreationWasAborted
+		}
+
+		if err := snapshotWriter.WriteBalance(hornet.Hash(addr), val); err != nil {
 			return nil, err
 		}
+	}
 
-		if spentAddressesCount > 0 {
-			// seek to spent addresses count in the header:
-			// 1 (version) + 49 (ms hash) + 4 (ms index) + 8 (ms timestamp) +
-			// 4 (SEPs count) + 4 (seen ms count) + 4 (ledger entries) = 74
-			if _, err := exportFile.Seek(74, 0); err != nil {
-				return nil, err
-			}
+	if tangle.GetSnapshotInfo().IsSpentAddressesEnabled() &&
+		config.NodeConfig.GetBool(config.CfgSpentAddressesEnabled) {
 
-			// override 0 spent addresses count with actual count
-			if err := binary.Write(exportFile, binary.LittleEndian, spentAddressesCount); err != nil {
-				return nil, err
-			}
+		// stream spent addresses into the file
+		if _, err := tangle.StreamSpentAddresses(snapshotWriter.WriteSpentAddress, abortSignal); err != nil {
+			return nil, err
 		}
 	}
 
-	// seek back to the beginning of the file
-	if _, err := exportFile.Seek(0, 0); err != nil {
+	spentAddressesCount, err := snapshotWriter.Close()
+	if err != nil {
 		return nil, err
 	}
 
-	// compute sha256 of file
-	lsHash := sha256.New()
-	if _, err := io.Copy(lsHash, exportFile); err != nil {
-		return nil, err
+	if spentAddressesCount != lsh.spentAddressesCount {
+		// override the spent addresses count in the header with actual count
+		if err := snapshotfile.PatchSpentAddressesCount(exportFile, spentAddressesCount); err != nil {
+			return nil, err
+		}
 	}
 
 	// write sha256 hash into the file
-	sha256Hash := lsHash.Sum(nil)
-	if err := binary.Write(exportFile, binary.LittleEndian, sha256Hash); err != nil {
-		return nil, err
+	return snapshotfile.AppendChecksum(exportFile)
+}
+
+// writeIndexEntries writes the given solid entry points or seen milestones with the given write function.
+func writeIndexEntries(entries map[string]milestone.Index, write func(hash hornet.Hash, msIndex milestone.Index) error, abortSignal <-chan struct{}) error {
+	for hash, index := range entries {
+		select {
+		case <-abortSignal:
+			return ErrSnapshotCreationWasAborted
+		default:
+		}
+
+		if err := write(hornet.Hash(hash), index); err != nil {
+			return err
+		}
 	}
 
-	return sha256Hash, nil
+	return nil
 }
 
 func setIsSnapshotting(value bool) {
@@ -378,8 +415,18 @@ func setIsSnapshotting(value bool) {
 }
 
 func createLocalSnapshotWithoutLocking(targetIndex milestone.Index, filePath string, writeToDatabase bool, abortSignal <-chan struct{}) error {
+	return createSnapshotWithoutLocking(targetIndex, filePath, nil, writeToDatabase, abortSignal)
+}
 
-	log.Infof("creating local snapshot for targetIndex %d", targetIndex)
+// createSnapshotWithoutLocking creates a full local snapshot file, or a delta snapshot file on top of the given base.
+func createSnapshotWithoutLocking(targetIndex milestone.Index, filePath string, base *snapshotfile.Header, writeToDatabase bool, abortSignal <-chan struct{}) error {
+
+	snapshotType := "local snapshot"
+	if base != nil {
+		snapshotType = "delta snapshot"
+	}
+
+	log.Infof("creating %s for targetIndex %d", snapshotType, targetIndex)
 
 	ts := time.Now()
 
@@ -395,27 +442,43 @@ func createLocalSnapshotWithoutLocking(targetIndex milestone.Index, filePath str
 	setIsSnapshotting(true)
 	defer setIsSnapshotting(false)
 
+	snapshotProgress.start(targetIndex)
+	defer snapshotProgress.finish()
+
 	cachedTargetMs := tangle.GetMilestoneOrNil(targetIndex) // bundle +1
 	if cachedTargetMs == nil {
 		return errors.Wrapf(ErrCritical, "target milestone (%d) not found", targetIndex)
 	}
 	defer cachedTargetMs.Release(true) // bundle -1
 
-	newBalances, ledgerIndex, err := tangle.GetLedgerStateForMilestone(targetIndex, abortSignal)
+	// the intermediate results are kept in a checkpoint, so that an interrupted snapshot creation can be resumed
+	checkpoint := loadSnapshotCheckpoint(targetIndex, cachedTargetMs.GetBundle().GetTailHash())
+
+	newSolidEntryPoints, err := getSolidEntryPoints(targetIndex, checkpoint, snapshotProgress, abortSignal)
 	if err != nil {
-		if err == tangle.ErrOperationAborted {
-			return err
-		}
-		return errors.Wrap(ErrCritical, err.Error())
+		return err
 	}
 
-	if ledgerIndex != targetIndex {
-		return errors.Wrapf(ErrCritical, "ledger index wrong! %d/%d", ledgerIndex, targetIndex)
-	}
+	snapshotProgress.startStep(ProgressStepLedger, 0)
 
-	newSolidEntryPoints, err := getSolidEntryPoints(targetIndex, abortSignal)
-	if err != nil {
-		return err
+	newBalances := checkpoint.Balances
+	if newBalances == nil {
+		// the ledger diffs can't be pruned in the meantime, since the localSnapshotLock is held
+		var ledgerIndex milestone.Index
+		newBalances, ledgerIndex, err = tangle.GetLedgerStateForMilestoneWithoutBlocking(targetIndex, abortSignal)
+		if err != nil {
+			if err == tangle.ErrOperationAborted {
+				return err
+			}
+			return errors.Wrap(ErrCritical, err.Error())
+		}
+
+		if ledgerIndex != targetIndex {
+			return errors.Wrapf(ErrCritical, "ledger index wrong! %d/%d", ledgerIndex, targetIndex)
+		}
+
+		checkpoint.Balances = newBalances
+		checkpoint.save()
 	}
 
 	seenMilestones, err := getSeenMilestones(targetIndex, abortSignal)
@@ -435,20 +498,40 @@ func createLocalSnapshotWithoutLocking(targetIndex milestone.Index, filePath str
 		balances:         newBalances,
 	}
 
+	snapshotProgress.startStep(ProgressStepWriteFile, 0)
+
 	filePathTmp := filePath + "_tmp"
 
 	// Remove old temp file
 	os.Remove(filePathTmp)
 
-	hash, err := createSnapshotFile(filePathTmp, lsh, abortSignal)
+	var hash []byte
+	if base == nil {
+		hash, err = createSnapshotFile(filePathTmp, lsh, abortSignal)
+	} else {
+		hash, err = createDeltaSnapshotFile(filePathTmp, base, lsh, abortSignal)
+	}
 	if err != nil {
 		return err
 	}
 
+	if err := writeSnapshotSignatureFile(filePathTmp+SignatureFileSuffix, hash); err != nil {
+		return err
+	}
+
 	if err := os.Rename(filePathTmp, filePath); err != nil {
 		return err
 	}
 
+	if signingKey != nil {
+		if err := os.Rename(filePathTmp+SignatureFileSuffix, filePath+SignatureFileSuffix); err != nil {
+			return err
+		}
+	} else {
+		// remove the signature of the previous local snapshot file
+		os.Remove(filePath + SignatureFileSuffix)
+	}
+
 	if writeToDatabase {
 		// This has to be done before acquiring the SolidEntryPoints Lock, otherwise there is a race condition with "solidifyMilestone"
 		// In "solidifyMilestone" the LedgerLock is acquired, but by traversing the tangle, the SolidEntryPoint Lock is also acquired.
@@ -466,7 +549,11 @@ func createLocalSnapshotWithoutLocking(targetIndex milestone.Index, filePath str
 		tanglePlugin.Events.SnapshotMilestoneIndexChanged.Trigger(targetIndex)
 	}
 
-	log.Infof("created local snapshot for target index %d (sha256: %x), took %v", targetIndex, hash, time.Since(ts))
+	checkpoint.remove()
+
+	log.Infof("created %s for target index %d (sha256: %x), took %v", snapshotType, targetIndex, hash, time.Since(ts))
+
+	enqueueSnapshotUpload(filePath)
 
 	return nil
 }
@@ -474,7 +561,10 @@ func createLocalSnapshotWithoutLocking(targetIndex milestone.Index, filePath str
 func CreateLocalSnapshot(targetIndex milestone.Index, filePath string, writeToDatabase bool, abortSignal <-chan struct{}) error {
 	localSnapshotLock.Lock()
 	defer localSnapshotLock.Unlock()
-	return createLocalSnapshotWithoutLocking(targetIndex, filePath, writeToDatabase, abortSignal)
+
+	return scheduler.Run("Local snapshot", abortSignal, func(jobAbortSignal <-chan struct{}) error {
+		return createLocalSnapshotWithoutLocking(targetIndex, filePath, writeToDatabase, jobAbortSignal)
+	})
 }
 
 type localSnapshotHeader struct {
@@ -487,284 +577,283 @@ type localSnapshotHeader struct {
 	spentAddressesCount int32
 }
 
-func (ls *localSnapshotHeader) WriteToBuffer(buf io.Writer, abortSignal <-chan struct{}) error {
-	var err error
-
-	if err = binary.Write(buf, binary.LittleEndian, SupportedLocalSnapshotFileVersions[0]); err != nil {
-		return err
-	}
-
-	if err = binary.Write(buf, binary.LittleEndian, ls.msHash[:49]); err != nil {
-		return err
-	}
+// stagedSnapshot is the content of a local snapshot file which was read and verified completely,
+// before it gets applied to the database.
+type stagedSnapshot struct {
+	msHash           hornet.Hash
+	msIndex          milestone.Index
+	msTimestamp      int64
+	solidEntryPoints map[string]milestone.Index
+	seenMilestones   map[string]milestone.Index
+	ledgerState      map[string]uint64
+	spentAddrsCount  int32
+	// the files whose spent addresses are imported after the staged snapshot was applied
+	files []*tangle.StagedSnapshotFile
+}
 
-	if err = binary.Write(buf, binary.LittleEndian, ls.msIndex); err != nil {
-		return err
-	}
+func LoadSnapshotFromFile(filePath string) error {
+	log.Info("Loading snapshot file...")
 
-	if err = binary.Write(buf, binary.LittleEndian, ls.msTimestamp); err != nil {
+	staged, err := stageSnapshotFile(filePath)
+	if err != nil {
 		return err
 	}
 
-	if err = binary.Write(buf, binary.LittleEndian, int32(len(ls.solidEntryPoints))); err != nil {
+	if err := chainDeltaSnapshotFile(staged); err != nil {
 		return err
 	}
 
-	if err = binary.Write(buf, binary.LittleEndian, int32(len(ls.seenMilestones))); err != nil {
+	if err := storeStagedSnapshot(staged); err != nil {
 		return err
 	}
 
-	if err = binary.Write(buf, binary.LittleEndian, int32(len(ls.balances))); err != nil {
+	if err := commitStagedSnapshot(staged); err != nil {
 		return err
 	}
 
-	if err = binary.Write(buf, binary.LittleEndian, ls.spentAddressesCount); err != nil {
+	if err := applyStagedSnapshot(staged); err != nil {
 		return err
 	}
 
-	for hash, val := range ls.solidEntryPoints {
-		select {
-		case <-abortSignal:
-			return ErrSnapshotCreationWasAborted
-		default:
-		}
-
-		if err = binary.Write(buf, binary.LittleEndian, hornet.Hash(hash)[:49]); err != nil {
-			return err
-		}
+	log.Info("finished loading snapshot")
 
-		if err = binary.Write(buf, binary.LittleEndian, val); err != nil {
-			return err
-		}
-	}
+	tanglePlugin.Events.SnapshotMilestoneIndexChanged.Trigger(staged.msIndex)
 
-	for hash, val := range ls.seenMilestones {
-		select {
-		case <-abortSignal:
-			return ErrSnapshotCreationWasAborted
-		default:
-		}
+	return nil
+}
 
-		if err = binary.Write(buf, binary.LittleEndian, hornet.Hash(hash)[:49]); err != nil {
-			return err
-		}
+// stageSnapshotFile reads and verifies the given local snapshot file.
+// The spent addresses are imported once the staged snapshot is applied.
+func stageSnapshotFile(filePath string) (*stagedSnapshot, error) {
 
-		if err = binary.Write(buf, binary.LittleEndian, val); err != nil {
-			return err
-		}
+	if err := verifySnapshotFile(filePath); err != nil {
+		return nil, err
 	}
-
-	for addr, val := range ls.balances {
-		select {
-		case <-abortSignal:
-			return ErrSnapshotCreationWasAborted
-		default:
-		}
-
-		if err = binary.Write(buf, binary.LittleEndian, hornet.Hash(addr)[:49]); err != nil {
-			return err
-		}
-
-		if err = binary.Write(buf, binary.LittleEndian, val); err != nil {
-			return err
-		}
+	if len(trustedKeys) > 0 {
+		log.Info("Snapshot file signature verified")
 	}
 
-	return nil
-}
-
-func LoadSnapshotFromFile(filePath string) error {
-	log.Info("Loading snapshot file...")
-
 	file, err := os.OpenFile(filePath, os.O_RDONLY, 0666)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer file.Close()
 
-	// check file version
-	var fileVersion byte
-	if err := binary.Read(file, binary.LittleEndian, &fileVersion); err != nil {
-		return err
+	snapshotReader, err := snapshotfile.NewReader(file)
+	if err != nil {
+		return nil, err
 	}
+	header := snapshotReader.Header()
 
-	var supported bool
-	for _, v := range SupportedLocalSnapshotFileVersions {
-		if v == fileVersion {
-			supported = true
-			break
-		}
-	}
-	if !supported {
-		return errors.Wrapf(ErrUnsupportedLSFileVersion, "local snapshot file version is %d but this HORNET version only supports %v", fileVersion, SupportedLocalSnapshotFileVersions)
+	staged := &stagedSnapshot{
+		msHash:           header.MilestoneHash,
+		msIndex:          header.MilestoneIndex,
+		msTimestamp:      header.MilestoneTimestamp,
+		solidEntryPoints: make(map[string]milestone.Index),
+		seenMilestones:   make(map[string]milestone.Index),
+		ledgerState:      make(map[string]uint64),
+		spentAddrsCount:  header.SpentAddressesCount,
 	}
 
-	msHash := make(hornet.Hash, 49)
-	if _, err := file.Read(msHash); err != nil {
-		return err
-	}
+	log.Info("reading solid entry points")
 
-	tangle.WriteLockSolidEntryPoints()
-	tangle.ResetSolidEntryPoints()
+	if err := snapshotReader.ReadSolidEntryPoints(snapshotReadChunkSize, stageIndexEntries(staged.solidEntryPoints)); err != nil {
+		return nil, wrapSnapshotImportError(err, "solidEntryPoints")
+	}
 
-	var msIndex int32
-	var msTimestamp int64
-	var solidEntryPointsCount, seenMilestonesCount, ledgerEntriesCount, spentAddrsCount int32
+	log.Info("reading seen milestones")
 
-	if err := binary.Read(file, binary.LittleEndian, &msIndex); err != nil {
-		return err
+	if err := snapshotReader.ReadSeenMilestones(snapshotReadChunkSize, stageIndexEntries(staged.seenMilestones)); err != nil {
+		return nil, wrapSnapshotImportError(err, "seenMilestones")
 	}
 
-	if err := binary.Read(file, binary.LittleEndian, &msTimestamp); err != nil {
-		return err
-	}
+	log.Info("reading ledger state")
 
-	if err := binary.Read(file, binary.LittleEndian, &solidEntryPointsCount); err != nil {
-		return err
-	}
+	if err := snapshotReader.ReadBalances(snapshotReadChunkSize, func(entries []*snapshotfile.BalanceEntry) error {
+		if daemon.IsStopped() {
+			return ErrSnapshotImportWasAborted
+		}
 
-	if err := binary.Read(file, binary.LittleEndian, &seenMilestonesCount); err != nil {
-		return err
+		for _, entry := range entries {
+			staged.ledgerState[string(entry.Address)] = entry.Balance
+		}
+		return nil
+	}); err != nil {
+		return nil, wrapSnapshotImportError(err, "ledgerEntries")
 	}
 
-	if err := binary.Read(file, binary.LittleEndian, &ledgerEntriesCount); err != nil {
-		return err
+	var total uint64
+	for _, value := range staged.ledgerState {
+		total += value
 	}
 
-	if err := binary.Read(file, binary.LittleEndian, &spentAddrsCount); err != nil {
-		return err
+	if total != consts.TotalSupply {
+		return nil, errors.Wrapf(ErrInvalidBalance, "%d != %d", total, consts.TotalSupply)
 	}
 
-	coordinatorAddress := hornet.HashFromAddressTrytes(config.NodeConfig.GetString(config.CfgCoordinatorAddress))
-	tangle.SetSnapshotMilestone(coordinatorAddress, msHash, milestone.Index(msIndex), milestone.Index(msIndex), milestone.Index(msIndex), msTimestamp, spentAddrsCount != 0 && config.NodeConfig.GetBool("spentAddresses.enabled"))
-	tangle.SolidEntryPointsAdd(msHash, milestone.Index(msIndex))
-	tangle.SetLatestSeenMilestoneIndexFromSnapshot(milestone.Index(msIndex))
+	staged.files = append(staged.files, &tangle.StagedSnapshotFile{Path: filePath})
 
-	log.Info("importing solid entry points")
+	return staged, nil
+}
 
-	for i := 0; i < int(solidEntryPointsCount); i++ {
+// stageIndexEntries returns a consumer which adds the read solid entry points or seen milestones to the given map.
+func stageIndexEntries(indexes map[string]milestone.Index) snapshotfile.IndexEntriesConsumer {
+	return func(entries []*snapshotfile.IndexEntry) error {
 		if daemon.IsStopped() {
 			return ErrSnapshotImportWasAborted
 		}
 
-		var val int32
-		txHashBuf := make(hornet.Hash, 49)
-
-		if err := binary.Read(file, binary.LittleEndian, txHashBuf); err != nil {
-			return errors.Wrapf(ErrSnapshotImportFailed, "solidEntryPoints: %v", err)
-		}
-
-		if err := binary.Read(file, binary.LittleEndian, &val); err != nil {
-			return errors.Wrapf(ErrSnapshotImportFailed, "solidEntryPoints: %v", err)
+		for _, entry := range entries {
+			indexes[string(entry.Hash)] = entry.Index
 		}
-
-		tangle.SolidEntryPointsAdd(txHashBuf, milestone.Index(val))
+		return nil
 	}
+}
 
-	tangle.StoreSolidEntryPoints()
-	tangle.WriteUnlockSolidEntryPoints()
-
-	log.Info("importing seen milestones")
+// wrapSnapshotImportError marks errors of reading the given section of a snapshot file as failed import, unless the import was aborted.
+func wrapSnapshotImportError(err error, name string) error {
+	if errors.Is(err, ErrSnapshotImportWasAborted) {
+		return err
+	}
+	return errors.Wrapf(ErrSnapshotImportFailed, "%s: %v", name, err)
+}
 
-	for i := 0; i < int(seenMilestonesCount); i++ {
+// importSpentAddresses reads the spent addresses of a snapshot file in batches and marks them as spent.
+func importSpentAddresses(readSpentAddresses func(chunkSize int, consumer snapshotfile.AddressesConsumer) error, spentAddrsCount int32) error {
+	var processed int
+	return wrapSnapshotImportError(readSpentAddresses(SpentAddressesImportBatchSize, func(addresses hornet.Hashes) error {
 		if daemon.IsStopped() {
 			return ErrSnapshotImportWasAborted
 		}
 
-		var val int32
-		txHashBuf := make(hornet.Hash, 49)
-
-		if err := binary.Read(file, binary.LittleEndian, txHashBuf); err != nil {
-			return errors.Wrapf(ErrSnapshotImportFailed, "seenMilestones: %v", err)
+		tangle.WriteLockSpentAddresses()
+		for _, address := range addresses {
+			tangle.MarkAddressAsSpentWithoutLocking(address)
 		}
+		tangle.WriteUnlockSpentAddresses()
 
-		if err := binary.Read(file, binary.LittleEndian, &val); err != nil {
-			return errors.Wrapf(ErrSnapshotImportFailed, "seenMilestones: %v", err)
-		}
+		processed += len(addresses)
+		log.Infof("processed %d/%d spent addresses", processed, spentAddrsCount)
+		return nil
+	}), "spentAddrs")
+}
 
-		tangle.SetLatestSeenMilestoneIndexFromSnapshot(milestone.Index(val))
-		// request the milestone and prevent the request from being discarded from the request queue
-		gossip.Request(txHashBuf, milestone.Index(val), true)
-	}
+// storeStagedSnapshot stores the given staged snapshot in the database, separated from the state of the node.
+func storeStagedSnapshot(staged *stagedSnapshot) error {
 
-	log.Info("importing ledger state")
+	log.Info("storing staged snapshot")
 
-	ledgerState := make(map[string]uint64)
-	for i := 0; i < int(ledgerEntriesCount); i++ {
-		if daemon.IsStopped() {
-			return ErrSnapshotImportWasAborted
-		}
+	coordinatorAddress := hornet.HashFromAddressTrytes(config.NodeConfig.GetString(config.CfgCoordinatorAddress))
+	info := &tangle.SnapshotInfo{
+		CoordinatorAddress: coordinatorAddress,
+		Hash:               staged.msHash,
+		SnapshotIndex:      staged.msIndex,
+		EntryPointIndex:    staged.msIndex,
+		PruningIndex:       staged.msIndex,
+		Timestamp:          staged.msTimestamp,
+	}
+	info.SetSpentAddressesEnabled(staged.spentAddrsCount != 0 && config.NodeConfig.GetBool(config.CfgSpentAddressesEnabled))
 
-		var val uint64
-		addrBuf := make(hornet.Hash, 49)
+	solidEntryPoints := hornet.NewSolidEntryPoints()
+	solidEntryPoints.Add(staged.msHash, staged.msIndex)
+	for txHash, index := range staged.solidEntryPoints {
+		solidEntryPoints.Add(hornet.Hash(txHash), index)
+	}
 
-		if err := binary.Read(file, binary.LittleEndian, addrBuf); err != nil {
-			return errors.Wrapf(ErrSnapshotImportFailed, "ledgerEntries: %v", err)
-		}
+	if err := tangle.StoreStagedSnapshot(info, solidEntryPoints, staged.ledgerState); err != nil {
+		return errors.Wrapf(ErrSnapshotImportFailed, "staged snapshot: %v", err)
+	}
 
-		if err := binary.Read(file, binary.LittleEndian, &val); err != nil {
-			return errors.Wrapf(ErrSnapshotImportFailed, "ledgerEntries: %v", err)
-		}
+	return nil
+}
 
-		ledgerState[string(addrBuf)] = val
+// commitStagedSnapshot switches the state of the node over to the stored staged snapshot with a single write.
+func commitStagedSnapshot(staged *stagedSnapshot) error {
+	if err := tangle.CommitStagedSnapshot(&tangle.StagedSnapshotCommit{Files: staged.files}); err != nil {
+		return errors.Wrapf(ErrSnapshotImportFailed, "staged snapshot: %v", err)
 	}
+	return nil
+}
 
-	var total uint64
-	for _, value := range ledgerState {
-		total += value
+// applyStagedSnapshot applies the committed staged snapshot and requests the seen milestones of the snapshot.
+func applyStagedSnapshot(staged *stagedSnapshot) error {
+
+	if err := finishStagedSnapshot(); err != nil {
+		return err
 	}
 
-	if total != consts.TotalSupply {
-		return errors.Wrapf(ErrInvalidBalance, "%d != %d", total, consts.TotalSupply)
+	log.Info("importing seen milestones")
+
+	tangle.SetLatestSeenMilestoneIndexFromSnapshot(staged.msIndex)
+	for txHash, index := range staged.seenMilestones {
+		tangle.SetLatestSeenMilestoneIndexFromSnapshot(index)
+		// request the milestone and prevent the request from being discarded from the request queue
+		gossip.Request(hornet.Hash(txHash), index, true)
 	}
 
-	err = tangle.StoreSnapshotBalancesInDatabase(ledgerState, milestone.Index(msIndex))
+	return nil
+}
+
+// finishStagedSnapshot applies the committed staged snapshot, if there is one, and imports its spent addresses afterwards.
+// The commit is only removed once the spent addresses were imported, so that an interrupted apply is finished at the next startup.
+func finishStagedSnapshot() error {
+
+	commit, err := tangle.GetStagedSnapshotCommit()
 	if err != nil {
-		return errors.Wrapf(ErrSnapshotImportFailed, "snapshot ledgerEntries: %s", err)
+		return err
 	}
 
-	err = tangle.StoreLedgerBalancesInDatabase(ledgerState, milestone.Index(msIndex))
-	if err != nil {
-		return errors.Wrapf(ErrSnapshotImportFailed, "ledgerEntries: %v", err)
+	if commit == nil {
+		// no committed staged snapshot
+		return nil
 	}
 
-	if config.NodeConfig.GetBool(config.CfgSpentAddressesEnabled) {
-		log.Infof("importing %d spent addresses. this can take a while...", spentAddrsCount)
+	log.Info("applying staged snapshot")
 
-		batchAmount := int(math.Ceil(float64(spentAddrsCount) / float64(SpentAddressesImportBatchSize)))
-		for i := 0; i < batchAmount; i++ {
-			if daemon.IsStopped() {
-				return ErrSnapshotImportWasAborted
-			}
+	info, err := tangle.ApplyStagedSnapshot()
+	if err != nil {
+		return errors.Wrapf(ErrSnapshotImportFailed, "staged snapshot: %v", err)
+	}
 
-			batchStart := int32(i * SpentAddressesImportBatchSize)
-			batchEnd := batchStart + SpentAddressesImportBatchSize
+	// set the solid milestone index based on the snapshot milestone
+	tangle.SetSolidMilestoneIndex(info.SnapshotIndex, false)
 
-			if batchEnd > spentAddrsCount {
-				batchEnd = spentAddrsCount
+	if info.IsSpentAddressesEnabled() {
+		for _, file := range commit.Files {
+			if err := importSpentAddressesFromFile(file); err != nil {
+				return err
 			}
+		}
+	}
 
-			for j := batchStart; j < batchEnd; j++ {
-
-				spentAddrBuf := make(hornet.Hash, 49)
-				err = binary.Read(file, binary.BigEndian, spentAddrBuf)
-				if err != nil {
-					return errors.Wrapf(ErrSnapshotImportFailed, "spentAddrs: %v", err)
-				}
+	return tangle.DeleteStagedSnapshot()
+}
 
-				tangle.MarkAddressAsSpentWithoutLocking(spentAddrBuf)
-			}
+// importSpentAddressesFromFile marks the spent addresses of the given local or delta snapshot file as spent.
+// The other sections of the file are skipped.
+func importSpentAddressesFromFile(stagedFile *tangle.StagedSnapshotFile) error {
 
-			log.Infof("processed %d/%d spent addresses", batchEnd, spentAddrsCount)
-		}
+	file, err := os.OpenFile(stagedFile.Path, os.O_RDONLY, 0666)
+	if err != nil {
+		return err
 	}
+	defer file.Close()
 
-	// set the solid milestone index based on the snapshot milestone
-	tangle.SetSolidMilestoneIndex(milestone.Index(msIndex), false)
+	if stagedFile.Delta {
+		deltaReader, err := snapshotfile.NewDeltaReader(file)
+		if err != nil {
+			return err
+		}
 
-	log.Info("finished loading snapshot")
+		log.Infof("importing %d delta spent addresses", deltaReader.Header().SpentAddressesCount)
+		return importSpentAddresses(deltaReader.ReadSpentAddresses, deltaReader.Header().SpentAddressesCount)
+	}
 
-	tanglePlugin.Events.SnapshotMilestoneIndexChanged.Trigger(milestone.Index(msIndex))
+	snapshotReader, err := snapshotfile.NewReader(file)
+	if err != nil {
+		return err
+	}
 
-	return nil
+	log.Infof("importing %d spent addresses. this can take a while...", snapshotReader.Header().SpentAddressesCount)
+	return importSpentAddresses(snapshotReader.ReadSpentAddresses, snapshotReader.Header().SpentAddressesCount)
 }
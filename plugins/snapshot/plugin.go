@@ -3,8 +3,7 @@ package snapshot
 import (
 	"bytes"
 	"fmt"
-	"os"
-	"path/filepath"
+	"runtime"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -20,6 +19,8 @@ import (
 	"github.com/gohornet/hornet/pkg/model/hornet"
 	"github.com/gohornet/hornet/pkg/model/milestone"
 	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/profile"
+	"github.com/gohornet/hornet/pkg/scheduler"
 	"github.com/gohornet/hornet/pkg/shutdown"
 	"github.com/gohornet/hornet/plugins/gossip"
 	tanglePlugin "github.com/gohornet/hornet/plugins/tangle"
@@ -45,9 +46,11 @@ var (
 	ErrNotEnoughHistory                = errors.New("not enough history.")
 	ErrNoPruningNeeded                 = errors.New("no pruning needed.")
 	ErrPruningAborted                  = errors.New("pruning was aborted.")
+	ErrPruningDisabledPermanode        = errors.New("pruning is disabled on a permanode")
 	ErrUnconfirmedTxInSubtangle        = errors.New("unconfirmed tx in subtangle")
 	ErrInvalidBalance                  = errors.New("invalid balance! total does not match supply:")
 	ErrWrongCoordinatorAddressDatabase = errors.New("configured coordinator address does not match database information")
+	ErrSnapshotNotNewer                = errors.New("snapshot is not newer than the solid milestone")
 
 	localSnapshotLock       = syncutils.Mutex{}
 	newSolidMilestoneSignal = make(chan milestone.Index)
@@ -55,9 +58,15 @@ var (
 	snapshotDepth            milestone.Index
 	snapshotIntervalSynced   milestone.Index
 	snapshotIntervalUnsynced milestone.Index
+	fullSnapshotInterval     milestone.Index
 
-	pruningEnabled bool
-	pruningDelay   milestone.Index
+	pruningEnabled  bool
+	pruningDelay    milestone.Index
+	pruningDelayMin milestone.Index
+
+	pruningWorkerCount int
+
+	expiryRules []*expiryRule
 
 	statusLock     syncutils.RWMutex
 	isSnapshotting bool
@@ -74,16 +83,60 @@ func configure(plugin *node.Plugin) {
 	}
 	snapshotIntervalSynced = milestone.Index(config.NodeConfig.GetInt(config.CfgLocalSnapshotsIntervalSynced))
 	snapshotIntervalUnsynced = milestone.Index(config.NodeConfig.GetInt(config.CfgLocalSnapshotsIntervalUnsynced))
+	fullSnapshotInterval = milestone.Index(config.NodeConfig.GetInt(config.CfgLocalSnapshotsFullSnapshotInterval))
 
 	pruningEnabled = config.NodeConfig.GetBool(config.CfgPruningEnabled)
 	pruningDelay = milestone.Index(config.NodeConfig.GetInt(config.CfgPruningDelay))
-	pruningDelayMin := snapshotDepth + SolidEntryPointCheckThresholdPast + AdditionalPruningThreshold + 1
+	pruningDelayMin = snapshotDepth + SolidEntryPointCheckThresholdPast + AdditionalPruningThreshold + 1
 	if pruningDelay < pruningDelayMin {
 		log.Warnf("Parameter '%s' is too small (%d). Value was changed to %d", config.CfgPruningDelay, pruningDelay, pruningDelayMin)
 		pruningDelay = pruningDelayMin
 	}
 
+	pruningWorkerCount = config.NodeConfig.GetInt(config.CfgPruningWorkerCount)
+	if pruningWorkerCount <= 0 {
+		pruningWorkerCount = runtime.NumCPU()
+	}
+
+	configureThrottling()
+	configureSnapshotCheckpoint()
+
+	if err := configurePruningTarget(); err != nil {
+		log.Fatal(err)
+	}
+
+	rules, err := parseExpiryRules(config.NodeConfig.GetStringSlice(config.CfgPruningExpiryRules))
+	if err != nil {
+		log.Fatal(err)
+	}
+	expiryRules = rules
+
+	if profile.LoadProfile().Permanode {
+		if pruningEnabled || len(expiryRules) != 0 {
+			log.Infof("Running as a permanode, pruning and expiry rules are disabled")
+		}
+		pruningEnabled = false
+		expiryRules = nil
+	}
+
+	if err := configureSnapshotSigning(); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := configureSnapshotDownload(); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := configureScheduledSnapshots(); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := configureSnapshotUpload(); err != nil {
+		log.Fatal(err)
+	}
+
 	gossip.AddRequestBackpressureSignal(isSnapshottingOrPruning)
+	tanglePlugin.AddSnapshotInProgressSignal(isSnapshottingActive)
 
 	snapshotInfo := tangle.GetSnapshotInfo()
 	if snapshotInfo != nil {
@@ -100,62 +153,39 @@ func configure(plugin *node.Plugin) {
 			tangle.SetSnapshotInfo(snapshotInfo)
 		}
 
-		if !*forceGlobalSnapshot {
-			// If we don't enforce loading of a global snapshot,
-			// we can check the ledger state of current database and start the node.
-			tangle.GetLedgerStateForLSMI(nil)
-			return
+		// a committed snapshot which replaces the state has to be applied before anything else
+		if err := finishStagedSnapshot(); err != nil {
+			tangle.MarkDatabaseCorrupted()
+			log.Panic(err.Error())
 		}
-	}
 
-	snapshotTypeToLoad := strings.ToLower(config.NodeConfig.GetString(config.CfgSnapshotLoadType))
+		if err := finishPruningIntent(); err != nil {
+			tangle.MarkDatabaseCorrupted()
+			log.Panic(err.Error())
+		}
+	}
 
-	if *forceGlobalSnapshot && snapshotTypeToLoad != "global" {
+	if *forceGlobalSnapshot && strings.ToLower(config.NodeConfig.GetString(config.CfgSnapshotLoadType)) != "global" {
 		log.Fatalf("global snapshot enforced but wrong snapshot type under config option '%s': %s", config.CfgSnapshotLoadType, config.NodeConfig.GetString(config.CfgSnapshotLoadType))
 	}
 
-	var err = ErrNoSnapshotSpecified
-	switch snapshotTypeToLoad {
-	case "global":
-		if path := config.NodeConfig.GetString(config.CfgGlobalSnapshotPath); path != "" {
-			err = LoadGlobalSnapshot(path,
-				config.NodeConfig.GetStringSlice(config.CfgGlobalSnapshotSpentAddressesPaths),
-				milestone.Index(config.NodeConfig.GetInt(config.CfgGlobalSnapshotIndex)))
-		}
-	case "local":
-		if path := config.NodeConfig.GetString(config.CfgLocalSnapshotsPath); path != "" {
-
-			if _, fileErr := os.Stat(path); os.IsNotExist(fileErr) {
-				// create dir if it not exists
-				if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
-					log.Fatalf("could not create snapshot dir '%s'", path)
-				}
-				if urls := config.NodeConfig.GetStringSlice(config.CfgLocalSnapshotsDownloadURLs); len(urls) > 0 {
-					log.Infof("Downloading snapshot from one of the provided sources %v", urls)
-					downloadErr := downloadSnapshotFile(path, urls)
-					if downloadErr != nil {
-						err = errors.Wrap(downloadErr, "Error downloading snapshot file")
-						break
-					}
-					log.Info("Snapshot download finished")
-				} else {
-					err = ErrNoSnapshotDownloadURL
-					break
-				}
-			}
-
-			err = LoadSnapshotFromFile(path)
-		}
-	default:
-		log.Fatalf("invalid snapshot type under config option '%s': %s", config.CfgSnapshotLoadType, config.NodeConfig.GetString(config.CfgSnapshotLoadType))
+	plan, err := bootstrapOrder()
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	if err != nil {
+	if err := bootstrap(plan, snapshotInfo != nil); err != nil {
 		tangle.MarkDatabaseCorrupted()
 		log.Panic(err.Error())
 	}
 }
 
+func isSnapshottingActive() bool {
+	statusLock.RLock()
+	defer statusLock.RUnlock()
+	return isSnapshotting
+}
+
 func isSnapshottingOrPruning() bool {
 	statusLock.RLock()
 	defer statusLock.RUnlock()
@@ -164,6 +194,9 @@ func isSnapshottingOrPruning() bool {
 
 func run(_ *node.Plugin) {
 
+	runScheduledSnapshots()
+	runSnapshotUpload()
+
 	onSolidMilestoneIndexChanged := events.NewClosure(func(msIndex milestone.Index) {
 		select {
 		case newSolidMilestoneSignal <- msIndex:
@@ -188,8 +221,9 @@ func run(_ *node.Plugin) {
 				localSnapshotLock.Lock()
 
 				if shouldTakeSnapshot(solidMilestoneIndex) {
-					localSnapshotPath := config.NodeConfig.GetString(config.CfgLocalSnapshotsPath)
-					if err := createLocalSnapshotWithoutLocking(solidMilestoneIndex-snapshotDepth, localSnapshotPath, true, shutdownSignal); err != nil {
+					if err := scheduler.Run("Local snapshot", shutdownSignal, func(abortSignal <-chan struct{}) error {
+						return createScheduledSnapshotWithoutLocking(solidMilestoneIndex-snapshotDepth, abortSignal)
+					}); err != nil {
 						if errors.Is(err, ErrCritical) {
 							log.Panic(errors.Wrap(ErrSnapshotCreationFailed, err.Error()))
 						}
@@ -197,14 +231,25 @@ func run(_ *node.Plugin) {
 					}
 				}
 
+				if len(expiryRules) > 0 {
+					if err := scheduler.Run("Transaction expiry", shutdownSignal, func(abortSignal <-chan struct{}) error {
+						return expireTransactions(solidMilestoneIndex, expiryRules, abortSignal)
+					}); err != nil {
+						log.Debugf("expiry aborted: %v", err.Error())
+					}
+				}
+
 				if pruningEnabled {
-					if solidMilestoneIndex <= pruningDelay {
+					targetIndex, needed := pruningTargetIndex(solidMilestoneIndex)
+					if !needed {
 						// Not enough history
 						localSnapshotLock.Unlock()
 						continue
 					}
 
-					if err := pruneDatabase(solidMilestoneIndex-pruningDelay, shutdownSignal); err != nil {
+					if err := scheduler.Run("Database pruning", shutdownSignal, func(abortSignal <-chan struct{}) error {
+						return pruneDatabase(targetIndex, abortSignal)
+					}); err != nil {
 						log.Debugf("pruning aborted: %v", err.Error())
 					}
 				}
@@ -216,6 +261,10 @@ func run(_ *node.Plugin) {
 }
 
 func PruneDatabaseByDepth(depth milestone.Index) error {
+	if profile.LoadProfile().Permanode {
+		return ErrPruningDisabledPermanode
+	}
+
 	localSnapshotLock.Lock()
 	defer localSnapshotLock.Unlock()
 
@@ -226,12 +275,20 @@ func PruneDatabaseByDepth(depth milestone.Index) error {
 		return ErrNotEnoughHistory
 	}
 
-	return pruneDatabase(solidMilestoneIndex-depth, nil)
+	return scheduler.Run("Database pruning", nil, func(abortSignal <-chan struct{}) error {
+		return pruneDatabase(solidMilestoneIndex-depth, abortSignal)
+	})
 }
 
 func PruneDatabaseByTargetIndex(targetIndex milestone.Index) error {
+	if profile.LoadProfile().Permanode {
+		return ErrPruningDisabledPermanode
+	}
+
 	localSnapshotLock.Lock()
 	defer localSnapshotLock.Unlock()
 
-	return pruneDatabase(targetIndex, nil)
+	return scheduler.Run("Database pruning", nil, func(abortSignal <-chan struct{}) error {
+		return pruneDatabase(targetIndex, abortSignal)
+	})
 }
@@ -0,0 +1,177 @@
+package snapshot
+
+import (
+	"sync"
+	"time"
+
+	"github.com/iotaledger/hive.go/events"
+
+	"github.com/gohornet/hornet/pkg/model/milestone"
+)
+
+const (
+	// ProgressJobPruning is the job of the progress of the pruning.
+	ProgressJobPruning = "pruning"
+	// ProgressJobSnapshot is the job of the progress of the snapshot creation.
+	ProgressJobSnapshot = "snapshot"
+
+	// ProgressStepSolidEntryPoints is the step in which the solid entry points are calculated.
+	ProgressStepSolidEntryPoints = "solidEntryPoints"
+	// ProgressStepLedger is the step in which the ledger state of the target milestone is calculated.
+	ProgressStepLedger = "ledger"
+	// ProgressStepWriteFile is the step in which the snapshot file is written.
+	ProgressStepWriteFile = "writeFile"
+	// ProgressStepPruneMilestones is the step in which the milestones are pruned.
+	ProgressStepPruneMilestones = "pruneMilestones"
+)
+
+func ProgressCaller(handler interface{}, params ...interface{}) {
+	handler.(func(progress *Progress))(params[0].(*Progress))
+}
+
+var Events = pluginEvents{
+	PruningProgress:  events.NewEvent(ProgressCaller),
+	SnapshotProgress: events.NewEvent(ProgressCaller),
+}
+
+type pluginEvents struct {
+	// triggered when the pruning starts, proceeds or finishes.
+	PruningProgress *events.Event
+	// triggered when the snapshot creation starts, proceeds or finishes.
+	SnapshotProgress *events.Event
+}
+
+// Progress is the progress of a running pruning or snapshot creation.
+type Progress struct {
+	// the job, either ProgressJobPruning or ProgressJobSnapshot.
+	Job string `json:"job"`
+	// the current step of the job.
+	Step string `json:"step"`
+	// the unix timestamp when the job was started.
+	StartTime int64 `json:"startTime"`
+	// the target milestone of the job.
+	TargetIndex milestone.Index `json:"targetIndex"`
+	// the milestone which was processed last in the current step.
+	CurrentIndex milestone.Index `json:"currentIndex"`
+	// the amount of milestones which were processed in the current step.
+	ProcessedMilestones int `json:"processedMilestones"`
+	// the amount of milestones which have to be processed in the current step, 0 if the step doesn't process milestones.
+	TotalMilestones int `json:"totalMilestones"`
+	// the amount of transactions which were deleted by the pruning so far.
+	DeletedTransactions int `json:"deletedTransactions"`
+	// the estimated time until the current step is finished, -1 if unknown.
+	EstimatedSecondsRemaining int64 `json:"estimatedSecondsRemaining"`
+	// whether the job is finished (or was aborted).
+	Finished bool `json:"finished"`
+}
+
+var (
+	pruningProgress  = &progressTracker{job: ProgressJobPruning, event: Events.PruningProgress}
+	snapshotProgress = &progressTracker{job: ProgressJobSnapshot, event: Events.SnapshotProgress}
+)
+
+// GetPruningProgress returns the progress of the running pruning, or nil if the database isn't pruned at the moment.
+func GetPruningProgress() *Progress {
+	return pruningProgress.get()
+}
+
+// GetSnapshotProgress returns the progress of the running snapshot creation, or nil if no snapshot is created at the moment.
+func GetSnapshotProgress() *Progress {
+	return snapshotProgress.get()
+}
+
+// progressTracker keeps track of the progress of a job and triggers its event on every change.
+type progressTracker struct {
+	sync.RWMutex
+	job       string
+	event     *events.Event
+	progress  *Progress
+	stepStart time.Time
+}
+
+// get returns a copy of the current progress, or nil if the job isn't running.
+func (t *progressTracker) get() *Progress {
+	t.RLock()
+	defer t.RUnlock()
+
+	if t.progress == nil {
+		return nil
+	}
+
+	progress := *t.progress
+	return &progress
+}
+
+// update applies the given change to the current progress and triggers the event with a copy of it.
+func (t *progressTracker) update(change func(progress *Progress)) {
+	t.Lock()
+	if t.progress == nil {
+		t.Unlock()
+		return
+	}
+	change(t.progress)
+	progress := *t.progress
+	t.Unlock()
+
+	t.event.Trigger(&progress)
+}
+
+// start starts tracking the progress of a new job with the given target milestone.
+func (t *progressTracker) start(targetIndex milestone.Index) {
+	t.Lock()
+	t.progress = &Progress{
+		Job:                       t.job,
+		StartTime:                 time.Now().Unix(),
+		TargetIndex:               targetIndex,
+		EstimatedSecondsRemaining: -1,
+	}
+	progress := *t.progress
+	t.Unlock()
+
+	t.event.Trigger(&progress)
+}
+
+// startStep starts the given step of the job, which processes the given amount of milestones.
+func (t *progressTracker) startStep(step string, totalMilestones int) {
+	t.update(func(progress *Progress) {
+		t.stepStart = time.Now()
+		progress.Step = step
+		progress.CurrentIndex = 0
+		progress.ProcessedMilestones = 0
+		progress.TotalMilestones = totalMilestones
+		progress.EstimatedSecondsRemaining = -1
+	})
+}
+
+// milestoneProcessed marks the given milestone of the current step as processed
+// and estimates the remaining time of the step from the average time per milestone.
+func (t *progressTracker) milestoneProcessed(msIndex milestone.Index, deletedTxs int) {
+	t.update(func(progress *Progress) {
+		progress.CurrentIndex = msIndex
+		progress.ProcessedMilestones++
+		progress.DeletedTransactions += deletedTxs
+
+		remaining := progress.TotalMilestones - progress.ProcessedMilestones
+		if remaining < 0 {
+			remaining = 0
+		}
+		timePerMilestone := time.Since(t.stepStart) / time.Duration(progress.ProcessedMilestones)
+		progress.EstimatedSecondsRemaining = int64((timePerMilestone * time.Duration(remaining)).Seconds())
+	})
+}
+
+// finish marks the job as finished and stops tracking its progress.
+func (t *progressTracker) finish() {
+	t.Lock()
+	if t.progress == nil {
+		t.Unlock()
+		return
+	}
+	t.progress.Finished = true
+	t.progress.EstimatedSecondsRemaining = 0
+	progress := *t.progress
+	t.progress = nil
+	t.Unlock()
+
+	t.event.Trigger(&progress)
+}
@@ -1,6 +1,8 @@
 package snapshot
 
 import (
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
@@ -17,6 +19,9 @@ const (
 	// AdditionalPruningThreshold is needed, because the transactions in the getMilestoneApprovees call in getSolidEntryPoints
 	// can reference older transactions as well
 	AdditionalPruningThreshold = 50
+
+	// the amount of items a pruning worker takes at once.
+	pruningWorkerBatchSize = 100
 )
 
 // pruneUnconfirmedTransactions prunes all unconfirmed tx from the database for the given milestone
@@ -80,11 +85,57 @@ func pruneMilestone(milestoneIndex milestone.Index) {
 	tangle.DeleteMilestone(milestoneIndex)
 }
 
-// pruneTransactions prunes the approvers, bundles, bundle txs, addresses, tags and transaction metadata from the database
-func pruneTransactions(txsToCheckMap map[string]struct{}) int {
+// runPruningWorkers calls the given function for the indexes of all items on the pruning workers
+// and waits until all items were processed. The workers take the items in batches.
+func runPruningWorkers(itemCount int, fn func(i int)) {
 
-	txsToDeleteMap := make(map[string]struct{})
+	workerCount := pruningWorkerCount
+	if maxWorkerCount := (itemCount + pruningWorkerBatchSize - 1) / pruningWorkerBatchSize; workerCount > maxWorkerCount {
+		workerCount = maxWorkerCount
+	}
+
+	if workerCount <= 1 {
+		for i := 0; i < itemCount; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	var nextBatch int64
+	var wg sync.WaitGroup
+	wg.Add(workerCount)
+	for worker := 0; worker < workerCount; worker++ {
+		go func() {
+			defer wg.Done()
+
+			for {
+				batchEnd := int(atomic.AddInt64(&nextBatch, pruningWorkerBatchSize))
+				batchStart := batchEnd - pruningWorkerBatchSize
+				if batchStart >= itemCount {
+					return
+				}
+				if batchEnd > itemCount {
+					batchEnd = itemCount
+				}
 
+				for i := batchStart; i < batchEnd; i++ {
+					fn(i)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// pruneTransactions prunes the approvers, bundles, bundle txs, addresses, tags and transaction metadata from the database.
+// The work is split up on the pruning workers in two steps:
+// first the transactions are removed from their bundles, the transactions of the same bundle are handled by the same worker,
+// since the removal depends on the remaining transactions of the bundle.
+// Afterwards the transactions which are no longer part of a bundle are deleted independently of each other.
+func pruneTransactions(txsToCheckMap map[string]struct{}) int {
+
+	// group the transactions to check by their bundle
+	txsToCheckPerBundle := make(map[string]hornet.Hashes)
 	for txHashToCheck := range txsToCheckMap {
 
 		cachedTxMeta := tangle.GetCachedTxMetadataOrNil(hornet.Hash(txHashToCheck)) // tx +1
@@ -93,18 +144,57 @@ func pruneTransactions(txsToCheckMap map[string]struct{}) int {
 			continue
 		}
 
-		for txToRemove := range tangle.RemoveTransactionFromBundle(cachedTxMeta.GetMetadata()) {
-			txsToDeleteMap[txToRemove] = struct{}{}
-		}
+		bundleHash := string(cachedTxMeta.GetMetadata().GetBundleHash())
+		txsToCheckPerBundle[bundleHash] = append(txsToCheckPerBundle[bundleHash], hornet.Hash(txHashToCheck))
+
 		// since it gets loaded below again it doesn't make sense to force release here
 		cachedTxMeta.Release() // tx -1
 	}
 
+	bundles := make([]hornet.Hashes, 0, len(txsToCheckPerBundle))
+	for _, txHashes := range txsToCheckPerBundle {
+		bundles = append(bundles, txHashes)
+	}
+
+	var txsToDeleteLock sync.Mutex
+	txsToDeleteMap := make(map[string]struct{})
+
+	runPruningWorkers(len(bundles), func(i int) {
+		for _, txHashToCheck := range bundles[i] {
+
+			cachedTxMeta := tangle.GetCachedTxMetadataOrNil(txHashToCheck) // tx +1
+			if cachedTxMeta == nil {
+				continue
+			}
+
+			txsToRemove := tangle.RemoveTransactionFromBundle(cachedTxMeta.GetMetadata())
+
+			// since it gets loaded below again it doesn't make sense to force release here
+			cachedTxMeta.Release() // tx -1
+
+			txsToDeleteLock.Lock()
+			for txToRemove := range txsToRemove {
+				txsToDeleteMap[txToRemove] = struct{}{}
+			}
+			txsToDeleteLock.Unlock()
+		}
+	})
+
+	txsToDelete := make(hornet.Hashes, 0, len(txsToDeleteMap))
 	for txHashToDelete := range txsToDeleteMap {
+		txsToDelete = append(txsToDelete, hornet.Hash(txHashToDelete))
+	}
+
+	runPruningWorkers(len(txsToDelete), func(i int) {
+		txHashToDelete := txsToDelete[i]
+
+		pruningThrottle.wait()
 
-		cachedTx := tangle.GetCachedTransactionOrNil(hornet.Hash(txHashToDelete)) // tx +1
+		cachedTx := tangle.GetCachedTransactionOrNil(txHashToDelete) // tx +1
 		if cachedTx == nil {
-			continue
+			// the transaction could have been deleted by the expiry rules already, but the metadata is kept
+			pruneExpiredTransactionMetadata(txHashToDelete)
+			return
 		}
 
 		cachedTx.ConsumeTransaction(func(tx *hornet.Transaction) { // tx -1
@@ -117,9 +207,102 @@ func pruneTransactions(txsToCheckMap map[string]struct{}) int {
 			tangle.DeleteApprovers(tx.GetTxHash())
 			tangle.DeleteTransaction(tx.GetTxHash())
 		})
+	})
+
+	return len(txsToDelete)
+}
+
+// pruneExpiredTransactionMetadata prunes the approvers and the metadata of a transaction which was deleted by the expiry rules.
+func pruneExpiredTransactionMetadata(txHash hornet.Hash) {
+
+	cachedTxMeta := tangle.GetCachedTxMetadataOrNil(txHash) // meta +1
+	if cachedTxMeta == nil {
+		return
 	}
 
-	return len(txsToDeleteMap)
+	cachedTxMeta.ConsumeMetadata(func(metadata *hornet.TransactionMetadata) { // meta -1
+		// Delete the reference in the approvees
+		tangle.DeleteApprover(metadata.GetTrunkHash(), txHash)
+		tangle.DeleteApprover(metadata.GetBranchHash(), txHash)
+
+		tangle.DeleteApprovers(txHash)
+		tangle.DeleteTransactionMetadata(txHash)
+	})
+}
+
+// pruneMilestoneWithIntent writes a pruning intent for the given milestone before its transactions
+// and the milestone itself are pruned. The intent is removed after the pruning index was updated.
+func pruneMilestoneWithIntent(snapshotInfo *tangle.SnapshotInfo, milestoneIndex milestone.Index, txsToCheckMap map[string]struct{}) (int, error) {
+
+	intent := &tangle.PruningIntent{
+		MilestoneIndex: milestoneIndex,
+		TxHashes:       make(hornet.Hashes, 0, len(txsToCheckMap)),
+	}
+	for txHash := range txsToCheckMap {
+		intent.TxHashes = append(intent.TxHashes, hornet.Hash(txHash))
+	}
+
+	if err := tangle.StorePruningIntent(intent); err != nil {
+		return 0, err
+	}
+
+	txCountDeleted := pruneTransactions(txsToCheckMap)
+	pruneMilestone(milestoneIndex)
+
+	if snapshotInfo.PruningIndex < milestoneIndex {
+		snapshotInfo.PruningIndex = milestoneIndex
+		tangle.SetSnapshotInfo(snapshotInfo)
+	}
+
+	if err := tangle.DeletePruningIntent(); err != nil {
+		return txCountDeleted, err
+	}
+
+	return txCountDeleted, nil
+}
+
+// finishPruningIntent finishes the pruning of a milestone which was interrupted by a crash of the node.
+func finishPruningIntent() error {
+
+	intent, err := tangle.GetPruningIntent()
+	if err != nil {
+		return err
+	}
+
+	if intent == nil {
+		// no interrupted pruning
+		return nil
+	}
+
+	snapshotInfo := tangle.GetSnapshotInfo()
+	if snapshotInfo == nil {
+		return tangle.DeletePruningIntent()
+	}
+
+	log.Infof("Finishing interrupted pruning of milestone (%d)...", intent.MilestoneIndex)
+
+	ts := time.Now()
+	txCountDeleted, txCountChecked := pruneUnconfirmedTransactions(intent.MilestoneIndex)
+
+	txsToCheckMap := make(map[string]struct{})
+	for _, txHash := range intent.TxHashes {
+		// skip the transactions which were already pruned before the interruption
+		if !tangle.ContainsTransaction(txHash) {
+			continue
+		}
+		txsToCheckMap[string(txHash)] = struct{}{}
+	}
+	txCountChecked += len(txsToCheckMap)
+
+	prunedCount, err := pruneMilestoneWithIntent(snapshotInfo, intent.MilestoneIndex, txsToCheckMap)
+	if err != nil {
+		return err
+	}
+	txCountDeleted += prunedCount
+
+	log.Infof("Finishing interrupted pruning of milestone (%d) took %v. Pruned %d/%d transactions. ", intent.MilestoneIndex, time.Since(ts), txCountDeleted, txCountChecked)
+
+	return nil
 }
 
 func setIsPruning(value bool) {
@@ -158,8 +341,11 @@ func pruneDatabase(targetIndex milestone.Index, abortSignal <-chan struct{}) err
 	setIsPruning(true)
 	defer setIsPruning(false)
 
+	pruningProgress.start(targetIndex)
+	defer pruningProgress.finish()
+
 	// calculate solid entry points for the new end of the tangle history
-	newSolidEntryPoints, err := getSolidEntryPoints(targetIndex, abortSignal)
+	newSolidEntryPoints, err := getSolidEntryPoints(targetIndex, nil, pruningProgress, abortSignal)
 	if err != nil {
 		return err
 	}
@@ -180,6 +366,8 @@ func pruneDatabase(targetIndex milestone.Index, abortSignal <-chan struct{}) err
 	// unconfirmed txs have to be pruned for PruningIndex as well, since this could be LSI at startup of the node
 	pruneUnconfirmedTransactions(snapshotInfo.PruningIndex)
 
+	pruningProgress.startStep(ProgressStepPruneMilestones, int(targetIndex-snapshotInfo.PruningIndex))
+
 	// Iterate through all milestones that have to be pruned
 	for milestoneIndex := snapshotInfo.PruningIndex + 1; milestoneIndex <= targetIndex; milestoneIndex++ {
 		select {
@@ -198,6 +386,7 @@ func pruneDatabase(targetIndex milestone.Index, abortSignal <-chan struct{}) err
 		if cachedMs == nil {
 			// Milestone not found, pruning impossible
 			log.Warnf("Pruning milestone (%d) failed! Milestone not found!", milestoneIndex)
+			pruningProgress.milestoneProcessed(milestoneIndex, txCountDeleted)
 			continue
 		}
 
@@ -230,19 +419,21 @@ func pruneDatabase(targetIndex milestone.Index, abortSignal <-chan struct{}) err
 		cachedMs.Release(true) // milestone -1
 		if err != nil {
 			log.Warnf("Pruning milestone (%d) failed! Error: %v", milestoneIndex, err)
+			pruningProgress.milestoneProcessed(milestoneIndex, txCountDeleted)
 			continue
 		}
 
 		txCountChecked += len(txsToCheckMap)
-		txCountDeleted += pruneTransactions(txsToCheckMap)
 
-		pruneMilestone(milestoneIndex)
-
-		snapshotInfo.PruningIndex = milestoneIndex
-		tangle.SetSnapshotInfo(snapshotInfo)
+		prunedCount, err := pruneMilestoneWithIntent(snapshotInfo, milestoneIndex, txsToCheckMap)
+		if err != nil {
+			return err
+		}
+		txCountDeleted += prunedCount
 
 		log.Infof("Pruning milestone (%d) took %v. Pruned %d/%d transactions. ", milestoneIndex, time.Since(ts), txCountDeleted, txCountChecked)
 
+		pruningProgress.milestoneProcessed(milestoneIndex, txCountDeleted)
 		tanglePlugin.Events.PruningMilestoneIndexChanged.Trigger(milestoneIndex)
 	}
 
@@ -0,0 +1,153 @@
+package snapshot
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+)
+
+var (
+	// the maximum size of the database in bytes, 0 if the database size is not limited.
+	maxDatabaseSize uint64
+	// the duration of history to keep, 0 if the pruning delay is used instead.
+	pruningRetention time.Duration
+)
+
+// configurePruningTarget loads the retention of the history and the maximum size of the database.
+func configurePruningTarget() error {
+	pruningRetention = time.Duration(config.NodeConfig.GetInt(config.CfgPruningRetentionHours)) * time.Hour
+
+	maxSize := config.NodeConfig.GetString(config.CfgPruningMaxDatabaseSize)
+	if maxSize == "" {
+		return nil
+	}
+
+	size, err := humanize.ParseBytes(maxSize)
+	if err != nil {
+		return fmt.Errorf("invalid size under config option '%s': %w", config.CfgPruningMaxDatabaseSize, err)
+	}
+
+	maxDatabaseSize = size
+	return nil
+}
+
+// pruningTargetIndex returns the index up to which the database should be pruned for the given solid milestone,
+// and whether there is enough history to prune at all.
+// By default the pruning delay is kept, or the history of the retention if configured.
+// If the database exceeds its maximum size, more milestones are pruned, but the minimum history for the solid entry points is always kept.
+func pruningTargetIndex(solidMilestoneIndex milestone.Index) (milestone.Index, bool) {
+	if solidMilestoneIndex <= pruningDelayMin {
+		return 0, false
+	}
+
+	var targetIndex milestone.Index
+	switch {
+	case pruningRetention != 0:
+		targetIndex = pruningTargetIndexForRetention(solidMilestoneIndex)
+	case solidMilestoneIndex > pruningDelay:
+		targetIndex = solidMilestoneIndex - pruningDelay
+	}
+
+	if sizeTargetIndex := pruningTargetIndexForDatabaseSize(solidMilestoneIndex); sizeTargetIndex > targetIndex {
+		targetIndex = sizeTargetIndex
+	}
+
+	return targetIndex, targetIndex != 0
+}
+
+// pruningTargetIndexForRetention returns the newest milestone which is older than the retention,
+// relative to the timestamp of the solid milestone, so that the history isn't pruned while the node is catching up.
+// 0 is returned if no milestone above the pruning index is older than the retention.
+func pruningTargetIndexForRetention(solidMilestoneIndex milestone.Index) milestone.Index {
+	solidTimestamp, exists := milestoneTimestamp(solidMilestoneIndex)
+	if !exists {
+		return 0
+	}
+	threshold := solidTimestamp - int64(pruningRetention.Seconds())
+
+	// the milestone timestamps are ascending, so the target can be found by binary search
+	var targetIndex milestone.Index
+	low := tangle.GetSnapshotInfo().PruningIndex + 1
+	high := solidMilestoneIndex - pruningDelayMin
+	for low <= high {
+		mid := low + (high-low)/2
+
+		timestamp, exists := milestoneTimestamp(mid)
+		if !exists {
+			break
+		}
+
+		if timestamp >= threshold {
+			high = mid - 1
+			continue
+		}
+
+		targetIndex = mid
+		low = mid + 1
+	}
+
+	return targetIndex
+}
+
+// milestoneTimestamp returns the timestamp of the milestone with the given index.
+func milestoneTimestamp(msIndex milestone.Index) (int64, bool) {
+	cachedMs := tangle.GetMilestoneOrNil(msIndex) // bundle +1
+	if cachedMs == nil {
+		return 0, false
+	}
+	defer cachedMs.Release(true) // bundle -1
+
+	cachedMsTail := cachedMs.GetBundle().GetTail() // tx +1
+	if cachedMsTail == nil {
+		return 0, false
+	}
+	defer cachedMsTail.Release(true) // tx -1
+
+	return cachedMsTail.GetTransaction().GetTimestamp(), true
+}
+
+// pruningTargetIndexForDatabaseSize returns the index up to which the database has to be pruned to get below its maximum size,
+// or 0 if the database doesn't exceed its maximum size.
+// The size of a milestone is estimated from the average size of the milestones in the tangle database,
+// since the size of the snapshot and spent addresses databases doesn't depend on the amount of milestones.
+func pruningTargetIndexForDatabaseSize(solidMilestoneIndex milestone.Index) milestone.Index {
+	if maxDatabaseSize == 0 {
+		return 0
+	}
+
+	tangleSize, snapshotSize, spentSize := tangle.GetDatabaseUsedSizes()
+	databaseSize := uint64(tangleSize + snapshotSize + spentSize)
+	if databaseSize <= maxDatabaseSize {
+		return 0
+	}
+
+	pruningIndex := tangle.GetSnapshotInfo().PruningIndex
+	if solidMilestoneIndex <= pruningIndex {
+		return 0
+	}
+
+	milestoneSize := uint64(tangleSize) / uint64(solidMilestoneIndex-pruningIndex)
+	if milestoneSize == 0 {
+		return 0
+	}
+
+	// round up, so that the database gets below its maximum size
+	milestonesToPrune := (databaseSize - maxDatabaseSize + milestoneSize - 1) / milestoneSize
+	targetIndex := pruningIndex + milestone.Index(milestonesToPrune)
+
+	if maxTargetIndex := solidMilestoneIndex - pruningDelayMin; targetIndex > maxTargetIndex {
+		log.Warnf("database size %s exceeds the maximum of %s, but only %d milestones can be pruned to keep the minimum history",
+			humanize.Bytes(databaseSize), humanize.Bytes(maxDatabaseSize), maxTargetIndex-pruningIndex)
+		targetIndex = maxTargetIndex
+	}
+
+	log.Infof("database size %s exceeds the maximum of %s, pruning up to milestone %d (about %s per milestone)",
+		humanize.Bytes(databaseSize), humanize.Bytes(maxDatabaseSize), targetIndex, humanize.Bytes(milestoneSize))
+
+	return targetIndex
+}
@@ -0,0 +1,106 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	tanglePlugin "github.com/gohornet/hornet/plugins/tangle"
+)
+
+// ReplaceStateWithSnapshot loads the given local snapshot file into the running node.
+// The snapshot is read, verified and stored separately from the state of the node before the solidifier is paused
+// and the snapshot info, the solid entry points and the ledger state are switched over to the ones of the snapshot
+// with a single commit, so a badly desynced node can be unstuck without a restart.
+// The spent addresses of the snapshot are imported after the switch.
+// The snapshot has to be newer than the current solid milestone. The transactions below the new snapshot index
+// are not touched, they are not referenced by the new state anymore.
+func ReplaceStateWithSnapshot(filePath string) (milestone.Index, error) {
+	localSnapshotLock.Lock()
+	defer localSnapshotLock.Unlock()
+
+	setIsSnapshotting(true)
+	defer setIsSnapshotting(false)
+
+	log.Infof("Replacing the state with snapshot file %s ...", filePath)
+
+	staged, err := stageSnapshotFile(filePath)
+	if err != nil {
+		return 0, err
+	}
+
+	// don't store a snapshot which can't be applied anyway
+	if solidMilestoneIndex := tangle.GetSolidMilestoneIndex(); staged.msIndex <= solidMilestoneIndex {
+		return 0, errors.Wrapf(ErrSnapshotNotNewer, "snapshot index: %d, solid milestone index: %d", staged.msIndex, solidMilestoneIndex)
+	}
+
+	if err := storeStagedSnapshot(staged); err != nil {
+		return 0, err
+	}
+
+	if err := switchToStagedSnapshot(staged); err != nil {
+		return 0, err
+	}
+
+	log.Infof("Replacing the state with snapshot file %s ... done, new solid milestone index: %d", filePath, staged.msIndex)
+
+	tanglePlugin.Events.SnapshotMilestoneIndexChanged.Trigger(staged.msIndex)
+	tanglePlugin.Events.PruningMilestoneIndexChanged.Trigger(staged.msIndex)
+	tanglePlugin.Events.SolidMilestoneIndexChanged.Trigger(staged.msIndex)
+
+	// solidify the milestones following the snapshot
+	tanglePlugin.TriggerSolidifier()
+
+	return staged.msIndex, nil
+}
+
+// ReplaceStateWithDownloadedSnapshot downloads a snapshot file from one of the configured download URLs
+// and loads it into the running node (see ReplaceStateWithSnapshot).
+func ReplaceStateWithDownloadedSnapshot() (milestone.Index, error) {
+	urls := config.NodeConfig.GetStringSlice(config.CfgLocalSnapshotsDownloadURLs)
+	if len(urls) == 0 {
+		return 0, ErrNoSnapshotDownloadURL
+	}
+
+	// the local snapshot file of the node is not overwritten, it might still be needed if the replacement fails
+	path := filepath.Join(filepath.Dir(config.NodeConfig.GetString(config.CfgLocalSnapshotsPath)), "replacement.bin")
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return 0, errors.Wrapf(err, "could not create snapshot dir '%s'", path)
+	}
+
+	log.Infof("Downloading snapshot from one of the provided sources %v", urls)
+	if err := downloadSnapshotFile(path, urls, nil); err != nil {
+		return 0, errors.Wrap(err, "Error downloading snapshot file")
+	}
+
+	return ReplaceStateWithSnapshot(path)
+}
+
+// switches the state of the node over to the staged snapshot while the solidifier is paused.
+func switchToStagedSnapshot(staged *stagedSnapshot) error {
+	tanglePlugin.PauseSolidifier()
+	defer tanglePlugin.ResumeSolidifier()
+
+	// the solidifier might have confirmed further milestones while the snapshot was staged
+	if solidMilestoneIndex := tangle.GetSolidMilestoneIndex(); staged.msIndex <= solidMilestoneIndex {
+		return errors.Wrapf(ErrSnapshotNotNewer, "snapshot index: %d, solid milestone index: %d", staged.msIndex, solidMilestoneIndex)
+	}
+
+	if err := commitStagedSnapshot(staged); err != nil {
+		return err
+	}
+
+	if err := applyStagedSnapshot(staged); err != nil {
+		// the state of the node was already switched over, the committed snapshot is applied at the next startup
+		log.Fatalf("Applying the committed snapshot failed, it is applied at the next startup: %v", err)
+	}
+
+	tangle.SetLatestMilestoneIndex(staged.msIndex)
+	tangle.SetSolidMilestoneIndex(staged.msIndex)
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package snapshot
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/scheduler"
+)
+
+const (
+	// the name of a scheduled snapshot file is made up of the prefix, the milestone index and the suffix.
+	scheduledSnapshotFilePrefix = "export_"
+	scheduledSnapshotFileSuffix = ".bin"
+)
+
+var (
+	scheduledSnapshotCron      string
+	scheduledSnapshotPath      string
+	scheduledSnapshotRetention int
+)
+
+// configureScheduledSnapshots loads the schedule at which additional local snapshot files are created.
+func configureScheduledSnapshots() error {
+	scheduledSnapshotCron = config.NodeConfig.GetString(config.CfgLocalSnapshotsScheduleCron)
+	scheduledSnapshotPath = config.NodeConfig.GetString(config.CfgLocalSnapshotsSchedulePath)
+	scheduledSnapshotRetention = config.NodeConfig.GetInt(config.CfgLocalSnapshotsScheduleRetention)
+
+	if scheduledSnapshotCron == "" {
+		return nil
+	}
+
+	if _, err := scheduler.ParseCron(scheduledSnapshotCron); err != nil {
+		return fmt.Errorf("%w under config option '%s'", err, config.CfgLocalSnapshotsScheduleCron)
+	}
+	return nil
+}
+
+func runScheduledSnapshots() {
+	if scheduledSnapshotCron == "" {
+		return
+	}
+
+	if err := scheduler.ScheduleCron("Scheduled local snapshot", scheduledSnapshotCron, createScheduledSnapshotFile); err != nil {
+		log.Panic(err)
+	}
+}
+
+// createScheduledSnapshotFile creates a local snapshot file for the current solid milestone minus the snapshot depth
+// in the scheduled snapshots directory, and removes the scheduled snapshot files which exceed the retention.
+// The snapshot of the database is not changed.
+func createScheduledSnapshotFile(abortSignal <-chan struct{}) error {
+	localSnapshotLock.Lock()
+	defer localSnapshotLock.Unlock()
+
+	solidMilestoneIndex := tangle.GetSolidMilestoneIndex()
+	if solidMilestoneIndex <= snapshotDepth {
+		return ErrNotEnoughHistory
+	}
+	targetIndex := solidMilestoneIndex - snapshotDepth
+
+	filePath := filepath.Join(scheduledSnapshotPath, scheduledSnapshotFileName(targetIndex))
+	if err := createLocalSnapshotWithoutLocking(targetIndex, filePath, false, abortSignal); err != nil {
+		return err
+	}
+
+	return cleanupScheduledSnapshotFiles(scheduledSnapshotPath, scheduledSnapshotRetention)
+}
+
+// cleanupScheduledSnapshotFiles removes all but the given amount of the most recent scheduled snapshot files in the directory.
+// Nothing is removed if the retention is 0.
+func cleanupScheduledSnapshotFiles(dirPath string, retention int) error {
+	if retention == 0 {
+		return nil
+	}
+
+	fileInfos, err := ioutil.ReadDir(dirPath)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, fileInfo := range fileInfos {
+		if !fileInfo.IsDir() {
+			names = append(names, fileInfo.Name())
+		}
+	}
+
+	for _, index := range expiredScheduledSnapshotFiles(names, retention) {
+		filePath := filepath.Join(dirPath, scheduledSnapshotFileName(index))
+		if err := os.Remove(filePath); err != nil {
+			return err
+		}
+		os.Remove(filePath + SignatureFileSuffix)
+
+		log.Infof("removed scheduled snapshot file '%s'", filePath)
+	}
+
+	return nil
+}
+
+// scheduledSnapshotFileName returns the name of the scheduled snapshot file for the given milestone index.
+func scheduledSnapshotFileName(index milestone.Index) string {
+	return fmt.Sprintf("%s%d%s", scheduledSnapshotFilePrefix, index, scheduledSnapshotFileSuffix)
+}
+
+// expiredScheduledSnapshotFiles returns the milestone indexes of the scheduled snapshot files among the given file names,
+// which exceed the given amount of the most recent ones.
+func expiredScheduledSnapshotFiles(names []string, retention int) []milestone.Index {
+	var indexes []milestone.Index
+	for _, name := range names {
+		if !strings.HasPrefix(name, scheduledSnapshotFilePrefix) || !strings.HasSuffix(name, scheduledSnapshotFileSuffix) {
+			continue
+		}
+
+		index, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(name, scheduledSnapshotFilePrefix), scheduledSnapshotFileSuffix), 10, 32)
+		if err != nil {
+			continue
+		}
+		indexes = append(indexes, milestone.Index(index))
+	}
+
+	if len(indexes) <= retention {
+		return nil
+	}
+
+	// the most recent files first
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i] > indexes[j] })
+
+	return indexes[retention:]
+}
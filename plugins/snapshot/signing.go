@@ -0,0 +1,199 @@
+package snapshot
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/gohornet/hornet/pkg/config"
+)
+
+const (
+	// SignatureFileSuffix is the suffix of the signature file which is stored next to a local snapshot file.
+	SignatureFileSuffix = ".sig"
+
+	// the environment variable which holds the hex encoded ed25519 private key (or seed) to sign local snapshot files.
+	signingKeyEnvironmentVariable = "SNAPSHOT_SIGNING_KEY"
+)
+
+var (
+	// ErrSnapshotSignatureMissing is returned if trusted keys are configured, but no signature file exists for a local snapshot file.
+	ErrSnapshotSignatureMissing = errors.New("snapshot signature missing")
+	// ErrSnapshotSignatureInvalid is returned if the signature of a local snapshot file is invalid or was not created by a trusted key.
+	ErrSnapshotSignatureInvalid = errors.New("snapshot signature invalid")
+	// ErrSnapshotHashMismatch is returned if the sha256 hash at the end of a local snapshot file doesn't match its content.
+	ErrSnapshotHashMismatch = errors.New("snapshot file hash mismatch")
+	// ErrInvalidSnapshotKey is returned if a configured snapshot signing or trusted key is invalid.
+	ErrInvalidSnapshotKey = errors.New("invalid snapshot key")
+
+	// the key to sign created local snapshot files, nil if signing is disabled.
+	signingKey ed25519.PrivateKey
+	// the keys which are trusted to sign local snapshot files. if empty, the signatures are not verified.
+	trustedKeys []ed25519.PublicKey
+)
+
+// configureSnapshotSigning loads the signing key and the trusted keys.
+func configureSnapshotSigning() error {
+
+	if config.NodeConfig.GetBool(config.CfgLocalSnapshotsSigningEnabled) {
+		keyHex, exists := os.LookupEnv(signingKeyEnvironmentVariable)
+		if !exists || len(keyHex) == 0 {
+			return errors.Wrapf(ErrInvalidSnapshotKey, "environment variable '%s' not set", signingKeyEnvironmentVariable)
+		}
+
+		key, err := hex.DecodeString(keyHex)
+		if err != nil {
+			return errors.Wrapf(ErrInvalidSnapshotKey, "environment variable '%s': %v", signingKeyEnvironmentVariable, err)
+		}
+
+		switch len(key) {
+		case ed25519.SeedSize:
+			signingKey = ed25519.NewKeyFromSeed(key)
+		case ed25519.PrivateKeySize:
+			signingKey = ed25519.PrivateKey(key)
+		default:
+			return errors.Wrapf(ErrInvalidSnapshotKey, "environment variable '%s': invalid length %d", signingKeyEnvironmentVariable, len(key))
+		}
+	}
+
+	for _, keyHex := range config.NodeConfig.GetStringSlice(config.CfgLocalSnapshotsTrustedKeys) {
+		key, err := hex.DecodeString(keyHex)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return errors.Wrapf(ErrInvalidSnapshotKey, "'%s' under config option '%s'", keyHex, config.CfgLocalSnapshotsTrustedKeys)
+		}
+		trustedKeys = append(trustedKeys, ed25519.PublicKey(key))
+	}
+
+	return nil
+}
+
+// snapshotFileHash checks the sha256 hash at the end of a local snapshot file against its content and returns it.
+func snapshotFileHash(filePath string) ([]byte, error) {
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	if fileInfo.Size() < sha256.Size {
+		return nil, errors.Wrapf(ErrSnapshotHashMismatch, "file too small: %d bytes", fileInfo.Size())
+	}
+
+	lsHash := sha256.New()
+	if _, err := io.CopyN(lsHash, file, fileInfo.Size()-sha256.Size); err != nil {
+		return nil, err
+	}
+
+	fileHash := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(file, fileHash); err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(lsHash.Sum(nil), fileHash) {
+		return nil, ErrSnapshotHashMismatch
+	}
+
+	return fileHash, nil
+}
+
+// signatureFileContent returns the content of a signature file for the given local snapshot file hash.
+// The signature file contains the public key followed by the signature of the hash.
+func signatureFileContent(key ed25519.PrivateKey, hash []byte) []byte {
+	return append(append([]byte{}, key.Public().(ed25519.PublicKey)...), ed25519.Sign(key, hash)...)
+}
+
+// verifySignatureFileContent checks whether the signature file content is a valid signature of the hash by one of the trusted keys.
+func verifySignatureFileContent(content []byte, hash []byte) error {
+
+	if len(content) != ed25519.PublicKeySize+ed25519.SignatureSize {
+		return errors.Wrapf(ErrSnapshotSignatureInvalid, "invalid length %d", len(content))
+	}
+
+	publicKey := ed25519.PublicKey(content[:ed25519.PublicKeySize])
+	signature := content[ed25519.PublicKeySize:]
+
+	for _, trustedKey := range trustedKeys {
+		if !bytes.Equal(trustedKey, publicKey) {
+			continue
+		}
+
+		if !ed25519.Verify(publicKey, hash, signature) {
+			return ErrSnapshotSignatureInvalid
+		}
+		return nil
+	}
+
+	return errors.Wrapf(ErrSnapshotSignatureInvalid, "key %s is not trusted", hex.EncodeToString(publicKey))
+}
+
+// writeSnapshotSignatureFile signs the hash of a local snapshot file and writes the signature file to the given path.
+// Nothing is written if signing is disabled.
+func writeSnapshotSignatureFile(signatureFilePath string, hash []byte) error {
+	if signingKey == nil {
+		return nil
+	}
+
+	return ioutil.WriteFile(signatureFilePath, signatureFileContent(signingKey, hash), 0660)
+}
+
+// verifySnapshotFile verifies the signature of a local snapshot file against the trusted keys.
+// The signature is not verified if no trusted keys are configured.
+func verifySnapshotFile(filePath string) error {
+	if len(trustedKeys) == 0 {
+		return nil
+	}
+
+	hash, err := snapshotFileHash(filePath)
+	if err != nil {
+		return err
+	}
+
+	content, err := ioutil.ReadFile(filePath + SignatureFileSuffix)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return errors.Wrapf(ErrSnapshotSignatureMissing, "'%s' not found", filePath+SignatureFileSuffix)
+		}
+		return err
+	}
+
+	return verifySignatureFileContent(content, hash)
+}
+
+// downloadSnapshotSignatureFile downloads the signature of a local snapshot file, which is expected next to it on the server.
+// Nothing is downloaded if no trusted keys are configured.
+func downloadSnapshotSignatureFile(signatureFilePath string, url string) error {
+	if len(trustedKeys) == 0 {
+		return nil
+	}
+
+	resp, err := http.Get(url + SignatureFileSuffix)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Wrapf(ErrSnapshotSignatureMissing, "server returned %d", resp.StatusCode)
+	}
+
+	// the signature file has a fixed size
+	content, err := ioutil.ReadAll(io.LimitReader(resp.Body, ed25519.PublicKeySize+ed25519.SignatureSize+1))
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(signatureFilePath, content, 0660)
+}
@@ -0,0 +1,79 @@
+package snapshot
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gohornet/hornet/pkg/config"
+)
+
+const (
+	// the amount of entries written to a local snapshot file between the pauses.
+	snapshotPauseBatchSize = 10000
+)
+
+var (
+	// limits the deletions of the pruning, nil if not limited.
+	pruningThrottle *rateLimiter
+	// the pause after each walked milestone cone and each batch of written entries while creating a local snapshot.
+	snapshotPause time.Duration
+)
+
+// configureThrottling loads the limits of the I/O load of the pruning and the snapshot creation.
+func configureThrottling() {
+	if maxDeletions := config.NodeConfig.GetInt(config.CfgPruningMaxDeletionsPerSecond); maxDeletions > 0 {
+		pruningThrottle = &rateLimiter{limit: maxDeletions}
+	}
+	snapshotPause = time.Duration(config.NodeConfig.GetInt(config.CfgLocalSnapshotsPauseMilliseconds)) * time.Millisecond
+}
+
+// rateLimiter limits the amount of operations per second. It is safe for concurrent use.
+type rateLimiter struct {
+	sync.Mutex
+	limit       int
+	windowStart time.Time
+	count       int
+}
+
+// wait blocks until another operation is allowed. It returns immediately if the rate limiter is nil.
+func (r *rateLimiter) wait() {
+	if r == nil {
+		return
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	now := time.Now()
+	if now.Sub(r.windowStart) >= time.Second {
+		r.windowStart = now
+		r.count = 0
+	}
+
+	if r.count >= r.limit {
+		// the other callers are blocked as well while waiting for the next window
+		time.Sleep(r.windowStart.Add(time.Second).Sub(now))
+		r.windowStart = time.Now()
+		r.count = 0
+	}
+
+	r.count++
+}
+
+// pauseSnapshotting pauses the snapshot creation to reduce the I/O load.
+// It returns false if the given abort signal was closed in the meantime.
+func pauseSnapshotting(abortSignal <-chan struct{}) bool {
+	if snapshotPause == 0 {
+		return true
+	}
+
+	timer := time.NewTimer(snapshotPause)
+	defer timer.Stop()
+
+	select {
+	case <-abortSignal:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,198 @@
+package snapshot
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/daemon"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/shutdown"
+)
+
+const (
+	// the environment variable which holds the secret (S3 secret access key, GCS HMAC secret or WebDAV password) to upload local snapshot files.
+	uploadSecretEnvironmentVariable = "SNAPSHOT_UPLOAD_SECRET"
+
+	// the amount of created local snapshot files which wait for their upload.
+	uploadQueueSize = 10
+)
+
+var (
+	// ErrInvalidUploadConfig is returned if the configuration of the snapshot upload is invalid.
+	ErrInvalidUploadConfig = errors.New("invalid snapshot upload configuration")
+
+	// the storage the created local snapshot files are uploaded to, nil if the upload is disabled.
+	uploader snapshotUploader
+	// the amount of uploaded scheduled snapshot files which are kept.
+	uploadRetention int
+	// the paths of the created local snapshot files which wait for their upload.
+	uploadQueue = make(chan string, uploadQueueSize)
+)
+
+// snapshotUploader uploads files to a remote storage.
+type snapshotUploader interface {
+	// upload uploads the file with the given path under the given name.
+	upload(ctx context.Context, name string, filePath string) error
+	// list returns the names of all uploaded files.
+	list(ctx context.Context) ([]string, error)
+	// remove removes the uploaded file with the given name.
+	remove(ctx context.Context, name string) error
+}
+
+// configureSnapshotUpload loads the storage the created local snapshot files are uploaded to.
+func configureSnapshotUpload() error {
+	uploadType := strings.ToLower(config.NodeConfig.GetString(config.CfgSnapshotUploadType))
+	if uploadType == "" {
+		return nil
+	}
+
+	uploadURL, err := url.Parse(config.NodeConfig.GetString(config.CfgSnapshotUploadURL))
+	if err != nil || (uploadURL.Scheme != "http" && uploadURL.Scheme != "https") || uploadURL.Host == "" {
+		return errors.Wrapf(ErrInvalidUploadConfig, "invalid URL under config option '%s'", config.CfgSnapshotUploadURL)
+	}
+
+	accessKeyID := config.NodeConfig.GetString(config.CfgSnapshotUploadAccessKeyID)
+	secret := os.Getenv(uploadSecretEnvironmentVariable)
+
+	switch uploadType {
+	case "s3", "gcs":
+		if accessKeyID == "" || secret == "" {
+			return errors.Wrapf(ErrInvalidUploadConfig, "config option '%s' and environment variable '%s' have to be set", config.CfgSnapshotUploadAccessKeyID, uploadSecretEnvironmentVariable)
+		}
+
+		region := config.NodeConfig.GetString(config.CfgSnapshotUploadRegion)
+		if uploadType == "gcs" {
+			// the XML API of GCS is compatible with S3 and accepts HMAC keys with the region 'auto'
+			region = "auto"
+		}
+
+		if uploader, err = newS3Uploader(uploadURL, region, accessKeyID, secret); err != nil {
+			return errors.Wrapf(ErrInvalidUploadConfig, "config option '%s': %v", config.CfgSnapshotUploadURL, err)
+		}
+
+	case "webdav":
+		uploader = newWebDAVUploader(uploadURL, accessKeyID, secret)
+
+	default:
+		return errors.Wrapf(ErrInvalidUploadConfig, "unknown type '%s' under config option '%s'", uploadType, config.CfgSnapshotUploadType)
+	}
+
+	uploadRetention = config.NodeConfig.GetInt(config.CfgSnapshotUploadRetention)
+
+	return nil
+}
+
+// runSnapshotUpload starts the worker which uploads the created local snapshot files.
+func runSnapshotUpload() {
+	if uploader == nil {
+		return
+	}
+
+	daemon.BackgroundWorker("SnapshotUpload", func(shutdownSignal <-chan struct{}) {
+		log.Info("Starting SnapshotUpload ... done")
+
+		// running uploads are canceled at shutdown
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go func() {
+			select {
+			case <-shutdownSignal:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		for {
+			select {
+			case <-shutdownSignal:
+				log.Info("Stopping SnapshotUpload...")
+				log.Info("Stopping SnapshotUpload... done")
+				return
+
+			case filePath := <-uploadQueue:
+				if err := uploadSnapshotFile(ctx, filePath); err != nil {
+					log.Warnf("uploading local snapshot file '%s' failed: %v", filePath, err)
+				}
+			}
+		}
+	}, shutdown.PriorityLocalSnapshots)
+}
+
+// enqueueSnapshotUpload queues the created local snapshot file for the upload, if the upload is enabled.
+// The file is skipped if too many files wait for their upload already.
+func enqueueSnapshotUpload(filePath string) {
+	if uploader == nil {
+		return
+	}
+
+	select {
+	case uploadQueue <- filePath:
+	default:
+		log.Warnf("skipping the upload of local snapshot file '%s', too many files wait for their upload", filePath)
+	}
+}
+
+// uploadSnapshotFile uploads the local snapshot file and its signature under their file names,
+// and removes the uploaded scheduled snapshot files which exceed the retention.
+func uploadSnapshotFile(ctx context.Context, filePath string) error {
+	name := filepath.Base(filePath)
+
+	if err := uploader.upload(ctx, name, filePath); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(filePath + SignatureFileSuffix); err == nil {
+		if err := uploader.upload(ctx, name+SignatureFileSuffix, filePath+SignatureFileSuffix); err != nil {
+			return err
+		}
+	}
+
+	log.Infof("uploaded local snapshot file '%s'", filePath)
+
+	if uploadRetention == 0 {
+		return nil
+	}
+
+	names, err := uploader.list(ctx)
+	if err != nil {
+		return errors.Wrap(err, "listing the uploaded files failed")
+	}
+
+	uploaded := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		uploaded[name] = struct{}{}
+	}
+
+	for _, index := range expiredScheduledSnapshotFiles(names, uploadRetention) {
+		name := scheduledSnapshotFileName(index)
+		if err := uploader.remove(ctx, name); err != nil {
+			return err
+		}
+
+		if _, exists := uploaded[name+SignatureFileSuffix]; exists {
+			if err := uploader.remove(ctx, name+SignatureFileSuffix); err != nil {
+				return err
+			}
+		}
+
+		log.Infof("removed uploaded scheduled snapshot file '%s'", name)
+	}
+
+	return nil
+}
+
+// checkUploadResponse returns an error if the response of the storage is not successful.
+func checkUploadResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	return errors.Errorf("%s %s: server returned %s", resp.Request.Method, resp.Request.URL.Redacted(), resp.Status)
+}
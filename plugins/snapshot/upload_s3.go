@@ -0,0 +1,271 @@
+package snapshot
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// the payload hash of requests whose body is not signed, which allows to stream the uploaded files.
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	// the sha256 hash of an empty body.
+	s3EmptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// s3Uploader uploads files to an S3 bucket, or to a GCS bucket via its S3 compatible XML API.
+// The requests are signed with AWS signature version 4.
+type s3Uploader struct {
+	client          *http.Client
+	endpoint        *url.URL
+	bucket          string
+	prefix          string
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+}
+
+// newS3Uploader creates an uploader for the bucket and prefix in the path of the given URL (path-style).
+func newS3Uploader(bucketURL *url.URL, region string, accessKeyID string, secretAccessKey string) (snapshotUploader, error) {
+	path := strings.Trim(bucketURL.Path, "/")
+	if path == "" {
+		return nil, errors.New("no bucket given in the URL")
+	}
+
+	parts := strings.SplitN(path, "/", 2)
+
+	var prefix string
+	if len(parts) == 2 && parts[1] != "" {
+		prefix = parts[1] + "/"
+	}
+
+	return &s3Uploader{
+		client:          &http.Client{},
+		endpoint:        &url.URL{Scheme: bucketURL.Scheme, Host: bucketURL.Host},
+		bucket:          parts[0],
+		prefix:          prefix,
+		region:          region,
+		accessKeyID:     accessKeyID,
+		secretAccessKey: secretAccessKey,
+	}, nil
+}
+
+// objectURL returns the URL of the object with the given name.
+// The path is sent encoded the same way it is signed.
+func (u *s3Uploader) objectURL(name string) *url.URL {
+	objectURL := *u.endpoint
+	objectURL.Path = "/" + u.bucket + "/" + u.prefix + name
+	objectURL.RawPath = s3EscapePath(objectURL.Path)
+	return &objectURL
+}
+
+func (u *s3Uploader) upload(ctx context.Context, name string, filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.objectURL(name).String(), file)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = fileInfo.Size()
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	return u.do(req, s3UnsignedPayload, nil)
+}
+
+// s3ListBucketResult is the response of the ListObjectsV2 request.
+type s3ListBucketResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+func (u *s3Uploader) list(ctx context.Context) ([]string, error) {
+	var names []string
+
+	var continuationToken string
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", u.prefix)
+		if continuationToken != "" {
+			query.Set("continuation-token", continuationToken)
+		}
+
+		listURL := *u.endpoint
+		listURL.Path = "/" + u.bucket + "/"
+		listURL.RawPath = s3EscapePath(listURL.Path)
+		listURL.RawQuery = query.Encode()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, listURL.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		result := &s3ListBucketResult{}
+		if err := u.do(req, s3EmptyPayloadHash, result); err != nil {
+			return nil, err
+		}
+
+		for _, content := range result.Contents {
+			name := strings.TrimPrefix(content.Key, u.prefix)
+			if name == "" || strings.Contains(name, "/") {
+				// objects in sub directories of the prefix
+				continue
+			}
+			names = append(names, name)
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return names, nil
+		}
+		continuationToken = result.NextContinuationToken
+	}
+}
+
+func (u *s3Uploader) remove(ctx context.Context, name string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, u.objectURL(name).String(), nil)
+	if err != nil {
+		return err
+	}
+
+	return u.do(req, s3EmptyPayloadHash, nil)
+}
+
+// do signs and sends the request, and decodes the XML response into the given result, if not nil.
+func (u *s3Uploader) do(req *http.Request, payloadHash string, result interface{}) error {
+	signS3Request(req, payloadHash, u.region, u.accessKeyID, u.secretAccessKey, time.Now())
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := checkUploadResponse(resp); err != nil {
+		return err
+	}
+
+	if result == nil {
+		_, err := io.Copy(ioutil.Discard, resp.Body)
+		return err
+	}
+
+	return xml.NewDecoder(resp.Body).Decode(result)
+}
+
+// signS3Request signs the request with AWS signature version 4.
+// The host and all headers of the request are signed.
+// The path of the request has to be sent encoded like s3EscapePath does, since that is the encoding which is signed.
+func signS3Request(req *http.Request, payloadHash string, region string, accessKeyID string, secretAccessKey string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for key, values := range req.Header {
+		headers[strings.ToLower(key)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+
+	headerNames := make([]string, 0, len(headers))
+	for name := range headers {
+		headerNames = append(headerNames, name)
+	}
+	sort.Strings(headerNames)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range headerNames {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(headerNames, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3EscapePath(req.URL.Path),
+		canonicalS3Query(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalRequestHash[:])
+
+	signingKey := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	for _, part := range []string{region, "s3", "aws4_request"} {
+		signingKey = hmacSHA256(signingKey, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", accessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalS3Query returns the query sorted by key, with keys and values encoded like in RFC 3986.
+func canonicalS3Query(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, s3QueryEscape(key)+"="+s3QueryEscape(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3EscapePath encodes every segment of the path like in RFC 3986, as signature version 4 requires for S3.
+// Other than url.URL.EscapedPath, it also encodes reserved characters like '$' or '+' in the segments.
+func s3EscapePath(path string) string {
+	if path == "" {
+		return "/"
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = s3QueryEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// s3QueryEscape encodes the string like in RFC 3986, which uses '%20' instead of '+' for spaces.
+func s3QueryEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
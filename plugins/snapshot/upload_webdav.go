@@ -0,0 +1,138 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+)
+
+// webDAVUploader uploads files to a directory on a WebDAV server.
+type webDAVUploader struct {
+	client       *http.Client
+	directoryURL *url.URL
+	user         string
+	password     string
+}
+
+// newWebDAVUploader creates an uploader for the directory with the given URL.
+// The requests use basic authentication if a user is given.
+func newWebDAVUploader(directoryURL *url.URL, user string, password string) snapshotUploader {
+	dirURL := *directoryURL
+	if !strings.HasSuffix(dirURL.Path, "/") {
+		dirURL.Path += "/"
+	}
+
+	return &webDAVUploader{
+		client:       &http.Client{},
+		directoryURL: &dirURL,
+		user:         user,
+		password:     password,
+	}
+}
+
+// fileURL returns the URL of the file with the given name.
+func (u *webDAVUploader) fileURL(name string) string {
+	fileURL := *u.directoryURL
+	fileURL.Path += name
+	return fileURL.String()
+}
+
+func (u *webDAVUploader) upload(ctx context.Context, name string, filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.fileURL(name), file)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = fileInfo.Size()
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	return u.do(req, nil)
+}
+
+// webDAVMultistatus is the response of the PROPFIND request.
+type webDAVMultistatus struct {
+	Responses []struct {
+		Href string `xml:"href"`
+	} `xml:"response"`
+}
+
+func (u *webDAVUploader) list(ctx context.Context) ([]string, error) {
+	body := `<?xml version="1.0" encoding="utf-8"?><propfind xmlns="DAV:"><prop><resourcetype/></prop></propfind>`
+
+	req, err := http.NewRequestWithContext(ctx, "PROPFIND", u.directoryURL.String(), strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/xml")
+	req.Header.Set("Depth", "1")
+
+	result := &webDAVMultistatus{}
+	if err := u.do(req, result); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, response := range result.Responses {
+		href, err := url.Parse(response.Href)
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.HasSuffix(href.Path, "/") {
+			// the directory itself and its sub directories
+			continue
+		}
+		names = append(names, path.Base(href.Path))
+	}
+
+	return names, nil
+}
+
+func (u *webDAVUploader) remove(ctx context.Context, name string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, u.fileURL(name), nil)
+	if err != nil {
+		return err
+	}
+
+	return u.do(req, nil)
+}
+
+// do sends the request, and decodes the XML response into the given result, if not nil.
+func (u *webDAVUploader) do(req *http.Request, result interface{}) error {
+	if u.user != "" {
+		req.SetBasicAuth(u.user, u.password)
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := checkUploadResponse(resp); err != nil {
+		return err
+	}
+
+	if result == nil {
+		_, err := io.Copy(ioutil.Discard, resp.Body)
+		return err
+	}
+
+	return xml.NewDecoder(resp.Body).Decode(result)
+}
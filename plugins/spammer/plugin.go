@@ -22,6 +22,7 @@ import (
 	"github.com/gohornet/hornet/pkg/model/tangle"
 	"github.com/gohornet/hornet/pkg/shutdown"
 	"github.com/gohornet/hornet/pkg/spammer"
+	"github.com/gohornet/hornet/pkg/supervisor"
 	"github.com/gohornet/hornet/pkg/utils"
 	"github.com/gohornet/hornet/plugins/coordinator"
 	"github.com/gohornet/hornet/plugins/gossip"
@@ -97,7 +98,7 @@ func configure(plugin *node.Plugin) {
 	)
 }
 
-func run(_ *node.Plugin) {
+func run(plugin *node.Plugin) {
 
 	// do not enable the spammer if URTS is disabled
 	if node.IsSkipped(urts.PLUGIN) {
@@ -105,7 +106,7 @@ func run(_ *node.Plugin) {
 	}
 
 	// create a background worker that "measures" the spammer averages values every second
-	daemon.BackgroundWorker("Spammer Metrics Updater", func(shutdownSignal <-chan struct{}) {
+	supervisor.BackgroundWorker(plugin.Name, "Spammer Metrics Updater", func(shutdownSignal <-chan struct{}) {
 		timeutil.Ticker(measureSpammerMetrics, 1*time.Second, shutdownSignal)
 	}, shutdown.PrioritySpammer)
 
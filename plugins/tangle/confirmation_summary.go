@@ -0,0 +1,71 @@
+package tangle
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/whiteflag"
+)
+
+const (
+	// ConfirmationSummaryHistoryLength is the amount of confirmation summaries which are kept in memory.
+	ConfirmationSummaryHistoryLength = 100
+)
+
+var (
+	confirmationSummaries     []*ConfirmationSummary
+	confirmationSummariesLock sync.RWMutex
+)
+
+// ConfirmationSummary is the summary of the confirmation of a single milestone.
+type ConfirmationSummary struct {
+	MilestoneIndex     milestone.Index                  `json:"milestoneIndex"`
+	Timestamp          int64                            `json:"timestamp"`
+	TailsReferenced    int                              `json:"tailsReferenced"`
+	TxsConfirmed       int                              `json:"txsConfirmed"`
+	TxsValue           int                              `json:"txsValue"`
+	TxsZeroValue       int                              `json:"txsZeroValue"`
+	TxsConflicting     int                              `json:"txsConflicting"`
+	ConflictReasons    map[whiteflag.ConflictReason]int `json:"conflictReasons"`
+	WaitingForLedgerMs int64                            `json:"waitingForLedgerMs"`
+	CollectingMs       int64                            `json:"collectingMs"`
+	DurationMs         int64                            `json:"durationMs"`
+}
+
+func newConfirmationSummary(conf *whiteflag.ConfirmedMilestoneStats) *ConfirmationSummary {
+	return &ConfirmationSummary{
+		MilestoneIndex:     conf.Index,
+		Timestamp:          time.Now().Unix(),
+		TailsReferenced:    conf.TailsReferenced,
+		TxsConfirmed:       conf.TxsConfirmed,
+		TxsValue:           conf.TxsValue,
+		TxsZeroValue:       conf.TxsZeroValue,
+		TxsConflicting:     conf.TxsConflicting,
+		ConflictReasons:    conf.ConflictReasons,
+		WaitingForLedgerMs: conf.WaitingForLedger.Milliseconds(),
+		CollectingMs:       conf.Collecting.Milliseconds(),
+		DurationMs:         conf.Total.Milliseconds(),
+	}
+}
+
+// addConfirmationSummary stores the summary and drops the oldest one if the history is full.
+func addConfirmationSummary(summary *ConfirmationSummary) {
+	confirmationSummariesLock.Lock()
+	defer confirmationSummariesLock.Unlock()
+
+	confirmationSummaries = append(confirmationSummaries, summary)
+	if len(confirmationSummaries) > ConfirmationSummaryHistoryLength {
+		confirmationSummaries = confirmationSummaries[len(confirmationSummaries)-ConfirmationSummaryHistoryLength:]
+	}
+}
+
+// ConfirmationSummaries returns the summaries of the last confirmed milestones, oldest first.
+func ConfirmationSummaries() []*ConfirmationSummary {
+	confirmationSummariesLock.RLock()
+	defer confirmationSummariesLock.RUnlock()
+
+	summaries := make([]*ConfirmationSummary, len(confirmationSummaries))
+	copy(summaries, confirmationSummaries)
+	return summaries
+}
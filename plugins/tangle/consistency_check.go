@@ -0,0 +1,481 @@
+package tangle
+
+import (
+	"errors"
+	"time"
+
+	"github.com/iotaledger/hive.go/syncutils"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+)
+
+const (
+	// ConsistencyIssueOrphanedApprover is an approver entry of a transaction which doesn't exist and wasn't expired.
+	ConsistencyIssueOrphanedApprover = "orphanedApprover"
+	// ConsistencyIssueMetadataWithoutTransaction is the metadata of an unconfirmed transaction which doesn't exist.
+	// The metadata of expired transactions is kept on purpose, so only unconfirmed transactions are reported.
+	ConsistencyIssueMetadataWithoutTransaction = "metadataWithoutTransaction"
+	// ConsistencyIssueConfirmedAboveLedgerIndex is a transaction which was confirmed by a milestone above the ledger index.
+	// It is only reported, since the ledger state has to be revalidated to repair it.
+	ConsistencyIssueConfirmedAboveLedgerIndex = "confirmedAboveLedgerIndex"
+	// ConsistencyIssueOrphanedBundleTransaction is a bundle transaction entry of a transaction which doesn't exist.
+	ConsistencyIssueOrphanedBundleTransaction = "orphanedBundleTransaction"
+	// ConsistencyIssueOrphanedTag is a tag index entry of a transaction which doesn't exist.
+	ConsistencyIssueOrphanedTag = "orphanedTag"
+	// ConsistencyIssueOrphanedAddress is an address index entry of a transaction which doesn't exist.
+	ConsistencyIssueOrphanedAddress = "orphanedAddress"
+
+	// ConsistencyReportMaxExamples is the maximum amount of issues which are listed in the report.
+	ConsistencyReportMaxExamples = 100
+
+	// the maximum amount of found issues which are held in memory before they are resolved.
+	consistencyCheckBatchSize = 1000
+)
+
+var (
+	// ErrConsistencyCheckRunning is returned if a consistency check is started while another one is running.
+	ErrConsistencyCheckRunning = errors.New("database consistency check is already running")
+)
+
+// ConsistencyIssue is a single structural inconsistency found in the database.
+type ConsistencyIssue struct {
+	// The kind of the issue.
+	Kind string `json:"kind"`
+	// The hash of the affected transaction.
+	TxHash string `json:"txHash"`
+	// Whether the issue was repaired.
+	Repaired bool `json:"repaired"`
+}
+
+// ConsistencyReport is the summary of a database consistency check.
+type ConsistencyReport struct {
+	// Whether the found issues are repaired.
+	Repair bool `json:"repair"`
+	// Whether the check is still running.
+	Running bool `json:"running"`
+	// The time the check was started.
+	Started int64 `json:"started"`
+	// The time the check finished.
+	Finished int64 `json:"finished,omitempty"`
+	// The amount of scanned database entries.
+	ScannedEntries int64 `json:"scannedEntries"`
+	// The amount of found issues per kind.
+	Issues map[string]int `json:"issues"`
+	// The amount of repaired issues per kind.
+	Repaired map[string]int `json:"repaired"`
+	// The first found issues.
+	Examples []*ConsistencyIssue `json:"examples"`
+	// The error which stopped the check.
+	Error string `json:"error,omitempty"`
+}
+
+var (
+	consistencyReportLock   syncutils.RWMutex
+	latestConsistencyReport *ConsistencyReport
+)
+
+// LatestConsistencyReport returns a copy of the report of the running or last database consistency check.
+// Returns nil if no check was run yet.
+func LatestConsistencyReport() *ConsistencyReport {
+	consistencyReportLock.RLock()
+	defer consistencyReportLock.RUnlock()
+
+	if latestConsistencyReport == nil {
+		return nil
+	}
+
+	report := *latestConsistencyReport
+	report.Issues = make(map[string]int, len(latestConsistencyReport.Issues))
+	for kind, count := range latestConsistencyReport.Issues {
+		report.Issues[kind] = count
+	}
+	report.Repaired = make(map[string]int, len(latestConsistencyReport.Repaired))
+	for kind, count := range latestConsistencyReport.Repaired {
+		report.Repaired[kind] = count
+	}
+	report.Examples = append([]*ConsistencyIssue{}, latestConsistencyReport.Examples...)
+	return &report
+}
+
+// StartConsistencyCheck prepares a new database consistency check.
+// Returns ErrConsistencyCheckRunning if another check is still running.
+func StartConsistencyCheck(repair bool) error {
+	consistencyReportLock.Lock()
+	defer consistencyReportLock.Unlock()
+
+	if latestConsistencyReport != nil && latestConsistencyReport.Running {
+		return ErrConsistencyCheckRunning
+	}
+
+	latestConsistencyReport = &ConsistencyReport{
+		Repair:   repair,
+		Running:  true,
+		Started:  time.Now().Unix(),
+		Issues:   make(map[string]int),
+		Repaired: make(map[string]int),
+		Examples: make([]*ConsistencyIssue, 0),
+	}
+	return nil
+}
+
+// RunConsistencyCheck scans the database for structural inconsistencies and repairs them if requested
+// in StartConsistencyCheck. The check runs while the node is online, so every found issue is verified
+// again before it is repaired. At most maxEntriesPerSecond database entries are scanned per second (0 = unlimited).
+func RunConsistencyCheck(maxEntriesPerSecond int, abortSignal <-chan struct{}) error {
+	start := time.Now()
+	c := &consistencyCheck{limiter: &entryLimiter{maxEntries: maxEntriesPerSecond, windowStart: start}, batchSize: consistencyCheckBatchSize, abortSignal: abortSignal}
+
+	consistencyReportLock.RLock()
+	c.repair = latestConsistencyReport != nil && latestConsistencyReport.Repair
+	consistencyReportLock.RUnlock()
+
+	log.Infof("Checking the database consistency (repair: %v) ...", c.repair)
+
+	err := c.run()
+
+	consistencyReportLock.Lock()
+	report := latestConsistencyReport
+	report.Running = false
+	report.Finished = time.Now().Unix()
+	if err != nil {
+		report.Error = err.Error()
+	}
+	consistencyReportLock.Unlock()
+
+	if err != nil {
+		log.Warnf("Checking the database consistency failed after %v: %s", time.Since(start).Truncate(time.Millisecond), err)
+		return err
+	}
+
+	log.Infof("Checking the database consistency ... done. Scanned %d entries, found issues: %v, repaired: %v, took %v",
+		report.ScannedEntries, report.Issues, report.Repaired, time.Since(start).Truncate(time.Millisecond))
+	return nil
+}
+
+// limits the amount of database entries which are scanned per second.
+type entryLimiter struct {
+	maxEntries  int
+	windowStart time.Time
+	entries     int
+}
+
+// waits until the next entry may be scanned. returns false if the abort signal was closed in the meantime.
+func (l *entryLimiter) wait(abortSignal <-chan struct{}) bool {
+	if l.maxEntries == 0 {
+		return true
+	}
+
+	l.entries++
+	if l.entries < l.maxEntries {
+		return true
+	}
+
+	if remaining := time.Until(l.windowStart.Add(time.Second)); remaining > 0 {
+		select {
+		case <-abortSignal:
+			return false
+		case <-time.After(remaining):
+		}
+	}
+
+	l.windowStart = time.Now()
+	l.entries = 0
+	return true
+}
+
+// a single run of the database consistency check.
+type consistencyCheck struct {
+	repair      bool
+	limiter     *entryLimiter
+	batchSize   int
+	abortSignal <-chan struct{}
+}
+
+// a found issue which is verified again before it is repaired.
+type pendingIssue struct {
+	txHash hornet.Hash
+	// tells whether the issue still exists
+	verify func() bool
+	// repairs the issue
+	fix func()
+}
+
+// collects the found issues of a kind and resolves them whenever the batch is full,
+// so the memory usage of the check doesn't grow with the size of the database.
+type issueBatch struct {
+	c      *consistencyCheck
+	kind   string
+	issues []*pendingIssue
+	err    error
+}
+
+func (c *consistencyCheck) newIssueBatch(kind string) *issueBatch {
+	return &issueBatch{c: c, kind: kind, issues: make([]*pendingIssue, 0, c.batchSize)}
+}
+
+// adds a found issue to the batch. returns false if resolving the full batch failed.
+func (b *issueBatch) add(issue *pendingIssue) bool {
+	b.issues = append(b.issues, issue)
+	if len(b.issues) < b.c.batchSize {
+		return true
+	}
+	return b.flush()
+}
+
+// resolves the collected issues. returns false if the check was aborted.
+func (b *issueBatch) flush() bool {
+	b.err = b.c.resolve(b.kind, b.issues)
+	b.issues = b.issues[:0]
+	return b.err == nil
+}
+
+// resolves the remaining issues after the scan and returns the error which stopped the scan.
+func (b *issueBatch) finish() error {
+	if b.err != nil {
+		return b.err
+	}
+	if err := b.c.aborted(); err != nil {
+		return err
+	}
+	b.flush()
+	return b.err
+}
+
+func (c *consistencyCheck) run() error {
+	phases := []func() error{
+		c.checkApprovers,
+		c.checkMetadata,
+		c.checkBundleTransactions,
+		c.checkTags,
+		c.checkAddresses,
+	}
+
+	for _, phase := range phases {
+		if err := phase(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanned is called for every scanned entry. returns false if the check was aborted.
+func (c *consistencyCheck) scanned() bool {
+	consistencyReportLock.Lock()
+	latestConsistencyReport.ScannedEntries++
+	consistencyReportLock.Unlock()
+
+	if c.aborted() != nil {
+		return false
+	}
+	return c.limiter.wait(c.abortSignal)
+}
+
+// adds a found issue to the report.
+func (c *consistencyCheck) found(kind string, txHash hornet.Hash, repaired bool) {
+	consistencyReportLock.Lock()
+	defer consistencyReportLock.Unlock()
+
+	latestConsistencyReport.Issues[kind]++
+	if repaired {
+		latestConsistencyReport.Repaired[kind]++
+	}
+	if len(latestConsistencyReport.Examples) < ConsistencyReportMaxExamples {
+		latestConsistencyReport.Examples = append(latestConsistencyReport.Examples, &ConsistencyIssue{Kind: kind, TxHash: txHash.Trytes(), Repaired: repaired})
+	}
+}
+
+// verifies the given issues again and repairs them if requested.
+// issues which vanished in the meantime (e.g. the transaction was stored) are not reported.
+func (c *consistencyCheck) resolve(kind string, issues []*pendingIssue) error {
+	for _, issue := range issues {
+		if err := c.aborted(); err != nil {
+			return err
+		}
+
+		if !issue.verify() {
+			continue
+		}
+
+		if c.repair && issue.fix != nil {
+			issue.fix()
+			c.found(kind, issue.txHash, true)
+			continue
+		}
+		c.found(kind, issue.txHash, false)
+	}
+	return nil
+}
+
+// returns ErrOperationAborted if the check was aborted.
+func (c *consistencyCheck) aborted() error {
+	select {
+	case <-c.abortSignal:
+		return tangle.ErrOperationAborted
+	default:
+		return nil
+	}
+}
+
+// checks for approvers whose approving transaction and its metadata don't exist.
+// approvers of missing approvees are valid, since the approvee might not be solid yet.
+// approvers of expired transactions are valid as well, since the expiry keeps their metadata.
+func (c *consistencyCheck) checkApprovers() error {
+	issues := c.newIssueBatch(ConsistencyIssueOrphanedApprover)
+	tangle.ForEachApprover(func(txHash hornet.Hash, approverHash hornet.Hash) bool {
+		if !c.scanned() {
+			return false
+		}
+
+		if !tangle.TransactionExistsInStore(approverHash) && !tangle.MetadataExistsInStore(approverHash) {
+			txHash, approverHash := copyHash(txHash), copyHash(approverHash)
+			return issues.add(&pendingIssue{
+				txHash: approverHash,
+				verify: func() bool {
+					return tangle.ContainsApprover(txHash, approverHash) &&
+						!tangle.ContainsTransaction(approverHash) &&
+						!tangle.ContainsTransactionMetadata(approverHash)
+				},
+				fix: func() { tangle.DeleteApprover(txHash, approverHash) },
+			})
+		}
+		return true
+	}, true)
+
+	return issues.finish()
+}
+
+// checks for metadata of unconfirmed transactions which don't exist and for transactions
+// which were confirmed by a milestone above the ledger index.
+func (c *consistencyCheck) checkMetadata() error {
+	withoutTx := c.newIssueBatch(ConsistencyIssueMetadataWithoutTransaction)
+	aboveLedgerIndex := c.newIssueBatch(ConsistencyIssueConfirmedAboveLedgerIndex)
+
+	tangle.ForEachTransactionMetadataHash(func(txHash hornet.Hash) bool {
+		if !c.scanned() {
+			return false
+		}
+
+		storedTxMeta := tangle.GetStoredMetadataOrNil(txHash)
+		if storedTxMeta == nil {
+			return true
+		}
+
+		// the milestone following the ledger index might be confirmed right now
+		confirmed, by := storedTxMeta.GetConfirmed()
+		if confirmed && by > tangle.GetSolidMilestoneIndex()+1 {
+			txHash := copyHash(txHash)
+			if !aboveLedgerIndex.add(&pendingIssue{
+				txHash: txHash,
+				verify: func() bool {
+					storedTxMeta := tangle.GetStoredMetadataOrNil(txHash)
+					if storedTxMeta == nil {
+						return false
+					}
+					confirmed, by := storedTxMeta.GetConfirmed()
+					return confirmed && by > tangle.GetSolidMilestoneIndex()+1
+				},
+			}) {
+				return false
+			}
+		}
+
+		if !confirmed && !tangle.TransactionExistsInStore(txHash) {
+			txHash := copyHash(txHash)
+			return withoutTx.add(&pendingIssue{
+				txHash: txHash,
+				verify: func() bool {
+					storedTxMeta := tangle.GetStoredMetadataOrNil(txHash)
+					if storedTxMeta == nil || storedTxMeta.IsConfirmed() {
+						return false
+					}
+					return !tangle.ContainsTransaction(txHash)
+				},
+				fix: func() { tangle.DeleteTransactionMetadata(txHash) },
+			})
+		}
+		return true
+	}, true)
+
+	if err := aboveLedgerIndex.finish(); err != nil {
+		return err
+	}
+	return withoutTx.finish()
+}
+
+// checks for bundle transaction entries whose transaction doesn't exist.
+func (c *consistencyCheck) checkBundleTransactions() error {
+	issues := c.newIssueBatch(ConsistencyIssueOrphanedBundleTransaction)
+	tangle.ForEachBundleTransaction(func(bundleHash hornet.Hash, txHash hornet.Hash, isTail bool) bool {
+		if !c.scanned() {
+			return false
+		}
+
+		if !tangle.TransactionExistsInStore(txHash) {
+			bundleHash, txHash := copyHash(bundleHash), copyHash(txHash)
+			return issues.add(&pendingIssue{
+				txHash: txHash,
+				verify: func() bool {
+					return tangle.ContainsBundleTransaction(bundleHash, txHash, isTail) && !tangle.ContainsTransaction(txHash)
+				},
+				fix: func() { tangle.DeleteBundleTransaction(bundleHash, txHash, isTail) },
+			})
+		}
+		return true
+	}, true)
+
+	return issues.finish()
+}
+
+// checks for tag index entries whose transaction doesn't exist.
+func (c *consistencyCheck) checkTags() error {
+	issues := c.newIssueBatch(ConsistencyIssueOrphanedTag)
+	tangle.ForEachTag(func(txTag hornet.Hash, txHash hornet.Hash) bool {
+		if !c.scanned() {
+			return false
+		}
+
+		if !tangle.TransactionExistsInStore(txHash) {
+			txTag, txHash := copyHash(txTag), copyHash(txHash)
+			return issues.add(&pendingIssue{
+				txHash: txHash,
+				verify: func() bool {
+					return tangle.ContainsTag(txTag, txHash) && !tangle.ContainsTransaction(txHash)
+				},
+				fix: func() { tangle.DeleteTag(txTag, txHash) },
+			})
+		}
+		return true
+	}, true)
+
+	return issues.finish()
+}
+
+// checks for address index entries whose transaction doesn't exist.
+func (c *consistencyCheck) checkAddresses() error {
+	issues := c.newIssueBatch(ConsistencyIssueOrphanedAddress)
+	tangle.ForEachAddress(func(address hornet.Hash, txHash hornet.Hash, isValue bool) bool {
+		if !c.scanned() {
+			return false
+		}
+
+		if !tangle.TransactionExistsInStore(txHash) {
+			address, txHash := copyHash(address), copyHash(txHash)
+			return issues.add(&pendingIssue{
+				txHash: txHash,
+				verify: func() bool {
+					return tangle.ContainsAddress(address, txHash, isValue) && !tangle.ContainsTransaction(txHash)
+				},
+				fix: func() { tangle.DeleteAddress(address, txHash) },
+			})
+		}
+		return true
+	}, true)
+
+	return issues.finish()
+}
+
+// copies the given hash, since the keys passed to the consumers of the storages are only valid within the consumer.
+func copyHash(hash hornet.Hash) hornet.Hash {
+	return append(hornet.Hash{}, hash...)
+}
@@ -0,0 +1,103 @@
+package tangle
+
+import (
+	"testing"
+
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/transaction"
+	"github.com/iotaledger/iota.go/trinary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/profile"
+)
+
+// stores a transaction with the given index as hash.
+func storeTestTransaction(idx int) hornet.Hash {
+	tx := hornet.NewTransactionFromTx(&transaction.Transaction{
+		Hash:              trinary.IntToTrytes(int64(idx), consts.HashTrytesSize),
+		Bundle:            hornet.NullHashBytes.Trytes(),
+		TrunkTransaction:  hornet.NullHashBytes.Trytes(),
+		BranchTransaction: hornet.NullHashBytes.Trytes(),
+	}, nil)
+
+	cachedTx, _ := tangle.StoreTransactionIfAbsent(tx)
+	cachedTx.Release(true)
+	return tx.GetTxHash()
+}
+
+// stores a transaction with the given index as hash and expires it the way the expiry does, which keeps the metadata.
+func storeExpiredTestTransaction(idx int) hornet.Hash {
+	txHash := storeTestTransaction(idx)
+
+	cachedTxMeta := tangle.GetCachedTxMetadataOrNil(txHash)
+	cachedTxMeta.GetMetadata().SetConfirmed(true, 1)
+	cachedTxMeta.Release(true)
+
+	tangle.DeleteTransactionKeepMetadata(txHash)
+	return txHash
+}
+
+func runTestConsistencyCheck(t *testing.T, repair bool) *ConsistencyReport {
+	tangle.FlushStorages()
+
+	require.NoError(t, StartConsistencyCheck(repair))
+	c := &consistencyCheck{repair: repair, limiter: &entryLimiter{}, batchSize: 2, abortSignal: make(chan struct{})}
+	err := c.run()
+
+	consistencyReportLock.Lock()
+	latestConsistencyReport.Running = false
+	consistencyReportLock.Unlock()
+
+	require.NoError(t, err)
+	tangle.FlushStorages()
+
+	return LatestConsistencyReport()
+}
+
+func TestConsistencyCheckApprovers(t *testing.T) {
+	tangle.ConfigureStorages(mapdb.NewMapDB(), mapdb.NewMapDB(), mapdb.NewMapDB(), profile.Caches{})
+	defer tangle.ShutdownStorages()
+
+	approvee := storeTestTransaction(1)
+	approver := storeTestTransaction(2)
+	expiredApprover := storeExpiredTestTransaction(3)
+
+	// more orphaned approvers than fit into a single batch
+	orphanedApprovers := []hornet.Hash{
+		hornet.HashFromHashTrytes(trinary.IntToTrytes(4, consts.HashTrytesSize)),
+		hornet.HashFromHashTrytes(trinary.IntToTrytes(5, consts.HashTrytesSize)),
+		hornet.HashFromHashTrytes(trinary.IntToTrytes(6, consts.HashTrytesSize)),
+	}
+
+	for _, approverHash := range append([]hornet.Hash{approver, expiredApprover}, orphanedApprovers...) {
+		tangle.StoreApprover(approvee, approverHash).Release(true)
+	}
+
+	// the issues are only reported
+	report := runTestConsistencyCheck(t, false)
+	assert.Equal(t, map[string]int{ConsistencyIssueOrphanedApprover: len(orphanedApprovers)}, report.Issues)
+	assert.Empty(t, report.Repaired)
+	for _, approverHash := range orphanedApprovers {
+		assert.True(t, tangle.ContainsApprover(approvee, approverHash))
+	}
+
+	// the issues are repaired
+	report = runTestConsistencyCheck(t, true)
+	assert.Equal(t, map[string]int{ConsistencyIssueOrphanedApprover: len(orphanedApprovers)}, report.Issues)
+	assert.Equal(t, map[string]int{ConsistencyIssueOrphanedApprover: len(orphanedApprovers)}, report.Repaired)
+	for _, approverHash := range orphanedApprovers {
+		assert.False(t, tangle.ContainsApprover(approvee, approverHash))
+	}
+
+	// the approvers of existing and expired transactions are kept
+	assert.True(t, tangle.ContainsApprover(approvee, approver))
+	assert.True(t, tangle.ContainsApprover(approvee, expiredApprover))
+
+	// nothing is left to repair
+	report = runTestConsistencyCheck(t, true)
+	assert.Empty(t, report.Issues)
+}
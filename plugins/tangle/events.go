@@ -3,8 +3,10 @@ package tangle
 import (
 	"github.com/iotaledger/hive.go/events"
 
+	"github.com/gohornet/hornet/pkg/model/hornet"
 	"github.com/gohornet/hornet/pkg/model/milestone"
 	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/partition"
 	"github.com/gohornet/hornet/pkg/whiteflag"
 )
 
@@ -12,10 +14,22 @@ func NewConfirmedMilestoneMetricCaller(handler interface{}, params ...interface{
 	handler.(func(metric *ConfirmedMilestoneMetric))(params[0].(*ConfirmedMilestoneMetric))
 }
 
+func ConfirmationSummaryCaller(handler interface{}, params ...interface{}) {
+	handler.(func(summary *ConfirmationSummary))(params[0].(*ConfirmationSummary))
+}
+
 func ConfirmedMilestoneCaller(handler interface{}, params ...interface{}) {
 	handler.(func(confirmation *whiteflag.Confirmation))(params[0].(*whiteflag.Confirmation))
 }
 
+func PartitionSuspicionCaller(handler interface{}, params ...interface{}) {
+	handler.(func(suspicion *partition.Suspicion))(params[0].(*partition.Suspicion))
+}
+
+func SolidificationStuckCaller(handler interface{}, params ...interface{}) {
+	handler.(func(msIndex milestone.Index, missingTxs hornet.Hashes))(params[0].(milestone.Index), params[1].(hornet.Hashes))
+}
+
 var Events = pluginEvents{
 	ReceivedNewTransaction:        events.NewEvent(tangle.NewTransactionCaller),
 	ReceivedKnownTransaction:      events.NewEvent(tangle.TransactionCaller),
@@ -28,12 +42,16 @@ var Events = pluginEvents{
 	LatestMilestoneChanged:        events.NewEvent(tangle.BundleCaller),
 	LatestMilestoneIndexChanged:   events.NewEvent(milestone.IndexCaller),
 	MilestoneConfirmed:            events.NewEvent(ConfirmedMilestoneCaller),
+	ConfirmationSummary:           events.NewEvent(ConfirmationSummaryCaller),
 	SolidMilestoneChanged:         events.NewEvent(tangle.BundleCaller),
 	SolidMilestoneIndexChanged:    events.NewEvent(milestone.IndexCaller),
 	SnapshotMilestoneIndexChanged: events.NewEvent(milestone.IndexCaller),
 	PruningMilestoneIndexChanged:  events.NewEvent(milestone.IndexCaller),
 	NewConfirmedMilestoneMetric:   events.NewEvent(NewConfirmedMilestoneMetricCaller),
 	MilestoneSolidificationFailed: events.NewEvent(milestone.IndexCaller),
+	SolidificationStuck:           events.NewEvent(SolidificationStuckCaller),
+	PartitionSuspected:            events.NewEvent(PartitionSuspicionCaller),
+	PartitionResolved:             events.NewEvent(events.CallbackCaller),
 }
 
 type pluginEvents struct {
@@ -48,10 +66,14 @@ type pluginEvents struct {
 	LatestMilestoneChanged        *events.Event
 	LatestMilestoneIndexChanged   *events.Event
 	MilestoneConfirmed            *events.Event
+	ConfirmationSummary           *events.Event
 	SolidMilestoneChanged         *events.Event
 	SolidMilestoneIndexChanged    *events.Event
 	SnapshotMilestoneIndexChanged *events.Event
 	PruningMilestoneIndexChanged  *events.Event
 	NewConfirmedMilestoneMetric   *events.Event
 	MilestoneSolidificationFailed *events.Event
+	SolidificationStuck           *events.Event
+	PartitionSuspected            *events.Event
+	PartitionResolved             *events.Event
 }
@@ -0,0 +1,75 @@
+package tangle
+
+import (
+	"time"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/peering/peer"
+	"github.com/gohornet/hornet/pkg/protocol/sting"
+	"github.com/gohornet/hornet/plugins/peering"
+)
+
+var (
+	// the interval in which heartbeats are sent to every neighbor at least.
+	heartbeatSentInterval time.Duration
+	// the interval in which heartbeats are sent while the node isn't synced or a neighbor is catching up (0 = disabled).
+	heartbeatAdaptiveInterval time.Duration
+)
+
+func configureHeartbeats() {
+	heartbeatSentInterval = time.Duration(config.NodeConfig.GetInt(config.CfgNetGossipHeartbeatIntervalSeconds)) * time.Second
+	heartbeatAdaptiveInterval = time.Duration(config.NodeConfig.GetInt(config.CfgNetGossipHeartbeatAdaptiveIntervalSeconds)) * time.Second
+}
+
+// returns the interval in which heartbeats are sent to the given neighbor.
+// in the adaptive mode, heartbeats are sent more frequently while the node isn't synced or the neighbor is catching up,
+// so that both learn about each others sync state sooner.
+func heartbeatIntervalFor(p *peer.Peer) time.Duration {
+	if heartbeatAdaptiveInterval == 0 || heartbeatAdaptiveInterval >= heartbeatSentInterval {
+		return heartbeatSentInterval
+	}
+
+	if !tangle.IsNodeSynced() || !p.IsSynced(tangle.GetLatestMilestoneIndex()) {
+		return heartbeatAdaptiveInterval
+	}
+	return heartbeatSentInterval
+}
+
+// drops the connections to STING neighbors without a negotiated keepalive from which we didn't receive heartbeats lately.
+func dropPeersWithoutHeartbeats() {
+	peerIDsToRemove := make(map[string]struct{})
+	peersToReconnect := make(map[string]*peer.Peer)
+
+	// check if peers are alive by checking whether we received heartbeats lately
+	peering.Manager().ForAllConnected(func(p *peer.Peer) bool {
+		if !p.Protocol.Supports(sting.FeatureSet) || peering.Manager().KeepaliveNegotiated(p) {
+			return true
+		}
+
+		if time.Since(p.HeartbeatReceivedTime) < HeartbeatReceiveTimeout {
+			return true
+		}
+
+		// peer is connected but doesn't seem to be alive
+		if p.Autopeering != nil {
+			// it's better to drop the connection to autopeered peers and free the slots for other peers
+			peerIDsToRemove[p.ID] = struct{}{}
+			log.Infof("dropping autopeered neighbor %s / %s because we didn't receive heartbeats anymore", p.Autopeering.Address(), p.Autopeering.ID())
+			return true
+		}
+
+		// close the connection to static connected peers, so they will be moved into reconnect pool to reestablish the connection
+		log.Infof("closing connection to neighbor %s because we didn't receive heartbeats anymore", p.ID)
+		peersToReconnect[p.ID] = p
+		return true
+	})
+
+	for peerIDToRemove := range peerIDsToRemove {
+		peering.Manager().Remove(peerIDToRemove)
+	}
+
+	for _, p := range peersToReconnect {
+		p.Conn.Close()
+	}
+}
@@ -0,0 +1,92 @@
+package tangle
+
+import (
+	"time"
+
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/syncutils"
+	"github.com/iotaledger/hive.go/timeutil"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/partition"
+	"github.com/gohornet/hornet/pkg/peering/peer"
+	"github.com/gohornet/hornet/pkg/protocol/sting"
+	"github.com/gohornet/hornet/pkg/shutdown"
+	"github.com/gohornet/hornet/plugins/peering"
+)
+
+const (
+	partitionDetectionCheckInterval = 10 * time.Second
+)
+
+var (
+	partitionDetector *partition.Detector
+	// the current suspicion of a network partition, nil if no partition is suspected.
+	partitionSuspicion     *partition.Suspicion
+	partitionSuspicionLock syncutils.RWMutex
+)
+
+func configurePartitionDetection() {
+	staleTimeout := time.Duration(config.NodeConfig.GetInt(config.CfgTanglePartitionDetectionStaleSeconds)) * time.Second
+	if staleTimeout == 0 {
+		return
+	}
+
+	partitionDetector = partition.NewDetector(staleTimeout, config.NodeConfig.GetInt(config.CfgTanglePartitionDetectionMinPeers), peer.IsSyncedThreshold)
+}
+
+// PartitionSuspicion returns the current suspicion of a network partition, nil if no partition is suspected.
+func PartitionSuspicion() *partition.Suspicion {
+	partitionSuspicionLock.RLock()
+	defer partitionSuspicionLock.RUnlock()
+
+	return partitionSuspicion
+}
+
+// checks the latest milestones reported by the peers for a probable network partition
+// and fires an event whenever a partition is suspected or the suspicion is resolved.
+func checkPartition() {
+	var peerIndexes []milestone.Index
+	peering.Manager().ForAllConnected(func(p *peer.Peer) bool {
+		if !p.Protocol.Supports(sting.FeatureSet) || p.LatestHeartbeat == nil {
+			return true
+		}
+
+		// peers which don't send heartbeats anymore don't tell anything about the network
+		if time.Since(p.HeartbeatReceivedTime) > HeartbeatReceiveTimeout {
+			return true
+		}
+
+		peerIndexes = append(peerIndexes, p.LatestHeartbeat.LatestMilestoneIndex)
+		return true
+	})
+
+	suspicion := partitionDetector.Check(time.Now(), tangle.GetLatestMilestoneIndex(), peerIndexes)
+
+	partitionSuspicionLock.Lock()
+	wasSuspected := partitionSuspicion != nil
+	partitionSuspicion = suspicion
+	partitionSuspicionLock.Unlock()
+
+	switch {
+	case suspicion != nil && !wasSuspected:
+		log.Warnf("Network partition suspected (%s): %d of %d peers diverge, latest milestone %d since %v",
+			suspicion.Reason, suspicion.DivergingPeers, suspicion.Peers, suspicion.LatestMilestoneIndex, time.Since(suspicion.LatestMilestoneChanged).Truncate(time.Second))
+		Events.PartitionSuspected.Trigger(suspicion)
+	case suspicion == nil && wasSuspected:
+		log.Info("Network partition resolved")
+		Events.PartitionResolved.Trigger()
+	}
+}
+
+func runPartitionDetection() {
+	if partitionDetector == nil {
+		return
+	}
+
+	daemon.BackgroundWorker("Tangle[PartitionDetection]", func(shutdownSignal <-chan struct{}) {
+		timeutil.Ticker(checkPartition, partitionDetectionCheckInterval, shutdownSignal)
+	}, shutdown.PriorityStatusReport)
+}
@@ -21,15 +21,14 @@ import (
 	"github.com/gohornet/hornet/pkg/model/milestone"
 	"github.com/gohornet/hornet/pkg/model/tangle"
 	"github.com/gohornet/hornet/pkg/peering/peer"
-	"github.com/gohornet/hornet/pkg/protocol/sting"
 	"github.com/gohornet/hornet/pkg/shutdown"
+	"github.com/gohornet/hornet/pkg/whiteflag"
 	"github.com/gohornet/hornet/plugins/database"
 	"github.com/gohornet/hornet/plugins/gossip"
 	"github.com/gohornet/hornet/plugins/peering"
 )
 
 const (
-	HeartbeatSentInterval   = 30 * time.Second
 	HeartbeatReceiveTimeout = 100 * time.Second
 )
 
@@ -59,6 +58,10 @@ func configure(plugin *node.Plugin) {
 
 	updateSyncedAtStartup = *syncedAtStartup
 
+	configureSolidificationEscalation()
+	configurePartitionDetection()
+	configureHeartbeats()
+
 	// Create a background worker that marks the database as corrupted at clean startup.
 	// This has to be done in a background worker, because the Daemon could receive
 	// a shutdown signal during startup. If that is the case, the BackgroundWorker will never be started
@@ -78,6 +81,10 @@ func configure(plugin *node.Plugin) {
 		coordinator.MilestoneMerkleTreeHashFuncWithName(config.NodeConfig.GetString(config.CfgCoordinatorMilestoneMerkleTreeHashFunc)),
 	)
 
+	if err := whiteflag.EnableProtocolFeatures(config.NodeConfig.GetStringSlice(config.CfgProtocolFeatures)...); err != nil {
+		log.Fatal(err.Error())
+	}
+
 	configureEvents()
 	configureTangleProcessor(plugin)
 
@@ -106,48 +113,19 @@ func run(plugin *node.Plugin) {
 		attachHeartbeatEvents()
 
 		checkHeartbeats := func() {
-			// send a new heartbeat message to every neighbor at least every HeartbeatSentInterval
+			// send a new heartbeat message to every neighbor at least every heartbeat interval,
+			// and to idle neighbors as a ping to detect dead connections
 			gossip.BroadcastHeartbeat(func(p *peer.Peer) bool {
-				return time.Since(p.HeartbeatSentTime) > HeartbeatSentInterval
+				return time.Since(p.HeartbeatSentTime) > heartbeatIntervalFor(p) || peering.Manager().NeedsPing(p)
 			})
 
-			peerIDsToRemove := make(map[string]struct{})
-			peersToReconnect := make(map[string]*peer.Peer)
-
-			// check if peers are alive by checking whether we received heartbeats lately
-			peering.Manager().ForAllConnected(func(p *peer.Peer) bool {
-				if !p.Protocol.Supports(sting.FeatureSet) {
-					return true
-				}
-
-				if time.Since(p.HeartbeatReceivedTime) < HeartbeatReceiveTimeout {
-					return true
-				}
-
-				// peer is connected but doesn't seem to be alive
-				if p.Autopeering != nil {
-					// it's better to drop the connection to autopeered peers and free the slots for other peers
-					peerIDsToRemove[p.ID] = struct{}{}
-					log.Infof("dropping autopeered neighbor %s / %s because we didn't receive heartbeats anymore", p.Autopeering.Address(), p.Autopeering.ID())
-					return true
-				}
-
-				// close the connection to static connected peers, so they will be moved into reconnect pool to reestablish the connection
-				log.Infof("closing connection to neighbor %s because we didn't receive heartbeats anymore", p.ID)
-				peersToReconnect[p.ID] = p
-				return true
-			})
-
-			for peerIDToRemove := range peerIDsToRemove {
-				peering.Manager().Remove(peerIDToRemove)
-			}
-
-			for _, p := range peersToReconnect {
-				p.Conn.Close()
-			}
+			// drop the connections to peers with a negotiated keepalive from which we didn't receive anything lately
+			peering.Manager().TerminateUnresponsive()
 
+			// the heartbeats are the fallback for the other peers
+			dropPeersWithoutHeartbeats()
 		}
-		timeutil.Ticker(checkHeartbeats, 5*time.Second, shutdownSignal)
+		timeutil.Ticker(checkHeartbeats, 1*time.Second, shutdownSignal)
 
 		detachHeartbeatEvents()
 	}, shutdown.PriorityHeartbeats)
@@ -177,6 +155,12 @@ func run(plugin *node.Plugin) {
 
 	runTangleProcessor(plugin)
 
+	daemon.BackgroundWorker("Tangle[SolidificationEscalation]", func(shutdownSignal <-chan struct{}) {
+		timeutil.Ticker(checkSolidificationEscalation, solidificationEscalationCheckInterval, shutdownSignal)
+	}, shutdown.PriorityMilestoneSolidifier)
+
+	runPartitionDetection()
+
 	// create a background worker that prints a status message every second
 	daemon.BackgroundWorker("Tangle status reporter", func(shutdownSignal <-chan struct{}) {
 		timeutil.Ticker(printStatus, 1*time.Second, shutdownSignal)
@@ -0,0 +1,121 @@
+package tangle
+
+import (
+	"strings"
+	"time"
+
+	"github.com/iotaledger/hive.go/syncutils"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/plugins/gossip"
+)
+
+const (
+	solidificationEscalationCheckInterval = 1 * time.Second
+)
+
+// the stages of the escalation policy for milestones which couldn't be solidified.
+const (
+	escalationStageNone = iota
+	escalationStageWidenFanOut
+	escalationStagePreferFullHistory
+	escalationStageStuck
+)
+
+var (
+	// the milestone which couldn't be solidified, 0 if no solidification failed.
+	escalationMilestoneIndex milestone.Index
+	// the time the solidification of the milestone failed for the first time.
+	escalationStartTime time.Time
+	// the transactions which were missing in the cone of the milestone at the last solidifier run.
+	escalationMissingTxs hornet.Hashes
+	escalationStage      int
+	escalationLock       syncutils.Mutex
+
+	widenFanOutTimeout       time.Duration
+	preferFullHistoryTimeout time.Duration
+	stuckTimeout             time.Duration
+)
+
+func configureSolidificationEscalation() {
+	widenFanOutTimeout = time.Duration(config.NodeConfig.GetInt(config.CfgTangleSolidifierEscalationWidenFanOutSeconds)) * time.Second
+	preferFullHistoryTimeout = time.Duration(config.NodeConfig.GetInt(config.CfgTangleSolidifierEscalationPreferFullHistorySeconds)) * time.Second
+	stuckTimeout = time.Duration(config.NodeConfig.GetInt(config.CfgTangleSolidifierEscalationStuckSeconds)) * time.Second
+}
+
+// markSolidificationFailed remembers the missing transactions of a milestone which couldn't be solidified.
+// The escalation deadlines start with the first failure of the milestone.
+func markSolidificationFailed(msIndex milestone.Index, missingTxs hornet.Hashes) {
+	escalationLock.Lock()
+	defer escalationLock.Unlock()
+
+	if escalationMilestoneIndex != msIndex {
+		escalationMilestoneIndex = msIndex
+		escalationStartTime = time.Now()
+		escalationStage = escalationStageNone
+		gossip.SetRequestEscalation(0, gossip.RequestEscalationNone)
+	}
+	escalationMissingTxs = missingTxs
+}
+
+// checkSolidificationEscalation escalates the solidification of a milestone which couldn't be solidified
+// within the configured deadlines, or resets the escalation once the milestone became solid.
+func checkSolidificationEscalation() {
+	escalationLock.Lock()
+	defer escalationLock.Unlock()
+
+	if escalationMilestoneIndex == 0 {
+		return
+	}
+
+	if tangle.GetSolidMilestoneIndex() >= escalationMilestoneIndex {
+		if escalationStage != escalationStageNone {
+			log.Infof("Milestone %d was solidified after %v", escalationMilestoneIndex, time.Since(escalationStartTime).Truncate(time.Second))
+		}
+		escalationMilestoneIndex = 0
+		escalationMissingTxs = nil
+		escalationStage = escalationStageNone
+		gossip.SetRequestEscalation(0, gossip.RequestEscalationNone)
+		return
+	}
+
+	elapsed := time.Since(escalationStartTime)
+	reached := func(timeout time.Duration) bool {
+		return timeout != 0 && elapsed >= timeout
+	}
+
+	if escalationStage < escalationStageWidenFanOut && reached(widenFanOutTimeout) {
+		escalationStage = escalationStageWidenFanOut
+		gossip.SetRequestEscalation(escalationMilestoneIndex, gossip.RequestEscalationWidenFanOut)
+		log.Warnf("Milestone %d couldn't be solidified for %v, requesting missing txs from all neighbors", escalationMilestoneIndex, elapsed.Truncate(time.Second))
+	}
+
+	if escalationStage < escalationStagePreferFullHistory && reached(preferFullHistoryTimeout) {
+		escalationStage = escalationStagePreferFullHistory
+		gossip.SetRequestEscalation(escalationMilestoneIndex, gossip.RequestEscalationPreferFullHistory)
+		log.Warnf("Milestone %d couldn't be solidified for %v, requesting missing txs from neighbors with full history", escalationMilestoneIndex, elapsed.Truncate(time.Second))
+	}
+
+	if escalationStage < escalationStageStuck && reached(stuckTimeout) {
+		escalationStage = escalationStageStuck
+
+		// only report the transactions which are still missing
+		var missingTxs hornet.Hashes
+		for _, txHash := range escalationMissingTxs {
+			if !tangle.ContainsTransaction(txHash) {
+				missingTxs = append(missingTxs, txHash)
+			}
+		}
+
+		trytes := make([]string, len(missingTxs))
+		for i, txHash := range missingTxs {
+			trytes[i] = string(txHash.Trytes())
+		}
+		log.Errorf("Solidification of milestone %d is stuck since %v, missing txs (%d): %s", escalationMilestoneIndex, elapsed.Truncate(time.Second), len(missingTxs), strings.Join(trytes, ", "))
+
+		Events.SolidificationStuck.Trigger(escalationMilestoneIndex, missingTxs)
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/gohornet/hornet/pkg/model/milestone"
 	"github.com/gohornet/hornet/pkg/model/tangle"
 	"github.com/gohornet/hornet/pkg/utils"
+	"github.com/gohornet/hornet/pkg/watchdog"
 	"github.com/gohornet/hornet/pkg/whiteflag"
 	"github.com/gohornet/hornet/plugins/gossip"
 )
@@ -28,6 +29,7 @@ var (
 	milestoneSolidifierWorkerCount = 2 // must be two, so a new request can abort another, in case it is an older milestone
 	milestoneSolidifierQueueSize   = 2
 	milestoneSolidifierWorkerPool  *workerpool.WorkerPool
+	milestoneSolidifierLoop        = watchdog.RegisterLoop("Tangle[MilestoneSolidifier]")
 
 	signalChanMilestoneStopSolidification     chan struct{}
 	signalChanMilestoneStopSolidificationLock syncutils.Mutex
@@ -43,6 +45,8 @@ var (
 	// Index of the first milestone that was sync after node start
 	firstSyncedMilestone = milestone.Index(0)
 
+	snapshotInProgressSignals [](func() bool)
+
 	ErrMilestoneNotFound     = errors.New("milestone not found")
 	ErrDivisionByZero        = errors.New("division by zero")
 	ErrMissingMilestoneFound = errors.New("missing milestone found")
@@ -56,11 +60,38 @@ type ConfirmedMilestoneMetric struct {
 	TimeSinceLastMilestone float64         `json:"time_since_last_ms"`
 }
 
+// AddSnapshotInProgressSignal adds a signal which reports whether a local snapshot is currently created.
+// It is used to measure the confirmation delay caused by local snapshots.
+func AddSnapshotInProgressSignal(signal func() bool) {
+	snapshotInProgressSignals = append(snapshotInProgressSignals, signal)
+}
+
+func isSnapshotInProgress() bool {
+	for _, signal := range snapshotInProgressSignals {
+		if signal() {
+			return true
+		}
+	}
+	return false
+}
+
 // TriggerSolidifier can be used to manually trigger the solidifier from other plugins.
 func TriggerSolidifier() {
 	milestoneSolidifierWorkerPool.TrySubmit(milestone.Index(0), true)
 }
 
+// PauseSolidifier aborts a running milestone solidification and prevents new ones until ResumeSolidifier is called.
+// It is used to replace the state of the running node, e.g. with a newer snapshot.
+func PauseSolidifier() {
+	abortMilestoneSolidification()
+	solidifierLock.Lock()
+}
+
+// ResumeSolidifier allows milestone solidifications again after PauseSolidifier was called.
+func ResumeSolidifier() {
+	solidifierLock.Unlock()
+}
+
 func markTransactionAsSolid(cachedTxMeta *tangle.CachedMetadata) {
 	defer cachedTxMeta.Release(true)
 
@@ -185,7 +216,8 @@ func solidQueueCheck(cachedTxMetas map[string]*tangle.CachedMetadata, milestoneI
 		for txHash := range txsToRequest {
 			txHashes = append(txHashes, hornet.Hash(txHash))
 		}
-		requested := gossip.RequestMultiple(txHashes, milestoneIndex, true)
+		requested := gossip.PriorityRequestMultiple(txHashes, milestoneIndex)
+		markSolidificationFailed(milestoneIndex, txHashes)
 		log.Warnf("Stopped solidifier due to missing tx -> Requested missing txs (%d/%d), collect: %v", requested, len(txHashes), tCollect.Sub(ts).Truncate(time.Millisecond))
 		return false, false
 	}
@@ -433,6 +465,9 @@ func solidifyMilestone(newMilestoneIndex milestone.Index, force bool) {
 		return
 	}
 
+	// hold back fresh gossip while the milestone cone is confirmed to reduce the contention on the storage layer
+	gossip.Processor().PauseIngest()
+	snapshotInProgress := isSnapshotInProgress()
 	conf, err := whiteflag.ConfirmMilestone(cachedTxMetas, cachedMsToSolidify.Retain(), func(txMeta *tangle.CachedMetadata, index milestone.Index, confTime int64) {
 		Events.TransactionConfirmed.Trigger(txMeta, index, confTime)
 	}, func(confirmation *whiteflag.Confirmation) {
@@ -441,21 +476,32 @@ func solidifyMilestone(newMilestoneIndex milestone.Index, force bool) {
 		Events.SolidMilestoneIndexChanged.Trigger(milestoneIndexToSolidify)
 		Events.MilestoneConfirmed.Trigger(confirmation)
 	})
+	gossip.Processor().ResumeIngest()
 
 	if err != nil {
 		log.Panic(err)
 	}
 
-	log.Infof("Milestone confirmed (%d): txsConfirmed: %v, txsValue: %v, txsZeroValue: %v, txsConflicting: %v, collect: %v, total: %v",
+	if snapshotInProgress {
+		metrics.SharedServerMetrics.SnapshotDelayedMilestones.Inc()
+		metrics.SharedServerMetrics.SnapshotConfirmationDelay.Add(uint64(conf.WaitingForLedger.Milliseconds()))
+	}
+
+	log.Infof("Milestone confirmed (%d): txsConfirmed: %v, txsValue: %v, txsZeroValue: %v, txsConflicting: %v, waitingForLedger: %v, collect: %v, total: %v",
 		conf.Index,
 		conf.TxsConfirmed,
 		conf.TxsValue,
 		conf.TxsZeroValue,
 		conf.TxsConflicting,
+		conf.WaitingForLedger.Truncate(time.Millisecond),
 		conf.Collecting.Truncate(time.Millisecond),
 		conf.Total.Truncate(time.Millisecond),
 	)
 
+	summary := newConfirmationSummary(conf)
+	addConfirmationSummary(summary)
+	Events.ConfirmationSummary.Trigger(summary)
+
 	var ctpsMessage string
 	if metric, err := getConfirmedMilestoneMetric(cachedMsToSolidify.GetBundle().GetTail(), conf.Index); err == nil {
 		if tangle.IsNodeSynced() {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"runtime"
 	"sync"
+	"time"
 
 	"github.com/iotaledger/hive.go/daemon"
 	"github.com/iotaledger/hive.go/events"
@@ -26,6 +27,12 @@ var (
 	receiveTxQueueSize   = 10000
 	receiveTxWorkerPool  *workerpool.WorkerPool
 
+	// transactions submitted by the operator are processed by their own workers,
+	// so they don't have to wait behind the transactions received via gossip.
+	receivePriorityTxWorkerCount = runtime.NumCPU()
+	receivePriorityTxQueueSize   = 1000
+	receivePriorityTxWorkerPool  *workerpool.WorkerPool
+
 	lastIncomingTPS uint32
 	lastNewTPS      uint32
 	lastOutgoingTPS uint32
@@ -37,15 +44,25 @@ func configureTangleProcessor(_ *node.Plugin) {
 
 	receiveTxWorkerPool = workerpool.New(func(task workerpool.Task) {
 		processIncomingTx(task.Param(0).(*hornet.Transaction), task.Param(1).(*rqueue.Request), task.Param(2).(*peer.Peer))
+		metrics.SharedServerMetrics.RegularTransactionStoreLatency.Observe(task.Param(3).(time.Time))
 		task.Return(nil)
 	}, workerpool.WorkerCount(receiveTxWorkerCount), workerpool.QueueSize(receiveTxQueueSize))
 
+	receivePriorityTxWorkerPool = workerpool.New(func(task workerpool.Task) {
+		processIncomingTx(task.Param(0).(*hornet.Transaction), task.Param(1).(*rqueue.Request), task.Param(2).(*peer.Peer))
+		metrics.SharedServerMetrics.PriorityTransactionStoreLatency.Observe(task.Param(3).(time.Time))
+		task.Return(nil)
+	}, workerpool.WorkerCount(receivePriorityTxWorkerCount), workerpool.QueueSize(receivePriorityTxQueueSize))
+
 	processValidMilestoneWorkerPool = workerpool.New(func(task workerpool.Task) {
 		processValidMilestone(task.Param(0).(*tangle.CachedBundle)) // bundle pass +1
 		task.Return(nil)
 	}, workerpool.WorkerCount(processValidMilestoneWorkerCount), workerpool.QueueSize(processValidMilestoneQueueSize), workerpool.FlushTasksAtShutdown(true))
 
 	milestoneSolidifierWorkerPool = workerpool.New(func(task workerpool.Task) {
+		end := milestoneSolidifierLoop.Begin()
+		defer end()
+
 		solidifyMilestone(task.Param(0).(milestone.Index), task.Param(1).(bool))
 		task.Return(nil)
 	}, workerpool.WorkerCount(milestoneSolidifierWorkerCount), workerpool.QueueSize(milestoneSolidifierQueueSize))
@@ -57,7 +74,11 @@ func runTangleProcessor(_ *node.Plugin) {
 	startWaitGroup.Add(4)
 
 	onTransactionProcessed := events.NewClosure(func(transaction *hornet.Transaction, request *rqueue.Request, p *peer.Peer) {
-		receiveTxWorkerPool.Submit(transaction, request, p)
+		receiveTxWorkerPool.Submit(transaction, request, p, time.Now())
+	})
+
+	onPriorityTransactionProcessed := events.NewClosure(func(transaction *hornet.Transaction, request *rqueue.Request, p *peer.Peer) {
+		receivePriorityTxWorkerPool.Submit(transaction, request, p, time.Now())
 	})
 
 	onTPSMetricsUpdated := events.NewClosure(func(tpsMetrics *metricsplugin.TPSMetrics) {
@@ -88,12 +109,16 @@ func runTangleProcessor(_ *node.Plugin) {
 	daemon.BackgroundWorker("TangleProcessor[ReceiveTx]", func(shutdownSignal <-chan struct{}) {
 		log.Info("Starting TangleProcessor[ReceiveTx] ... done")
 		gossip.Processor().Events.TransactionProcessed.Attach(onTransactionProcessed)
+		gossip.Processor().Events.PriorityTransactionProcessed.Attach(onPriorityTransactionProcessed)
 		receiveTxWorkerPool.Start()
+		receivePriorityTxWorkerPool.Start()
 		startWaitGroup.Done()
 		<-shutdownSignal
 		log.Info("Stopping TangleProcessor[ReceiveTx] ...")
 		gossip.Processor().Events.TransactionProcessed.Detach(onTransactionProcessed)
+		gossip.Processor().Events.PriorityTransactionProcessed.Detach(onPriorityTransactionProcessed)
 		receiveTxWorkerPool.StopAndWait()
+		receivePriorityTxWorkerPool.StopAndWait()
 		log.Info("Stopping TangleProcessor[ReceiveTx] ... done")
 	}, shutdown.PriorityReceiveTxWorker)
 
@@ -153,8 +178,13 @@ func processIncomingTx(incomingTx *hornet.Transaction, request *rqueue.Request,
 		// since we only add the approvees if there was a source request, we only
 		// request them for transactions which should be part of milestone cones
 		if request != nil {
-			// add this newly received transaction's approvees to the request queue
-			gossip.RequestApprovees(cachedTx.Retain(), request.MilestoneIndex, true)
+			// add this newly received transaction's approvees to the request queue.
+			// the approvees of transactions blocking the solidification are prioritized as well.
+			if request.Priority {
+				gossip.PriorityRequestApprovees(cachedTx.Retain(), request.MilestoneIndex)
+			} else {
+				gossip.RequestApprovees(cachedTx.Retain(), request.MilestoneIndex, true)
+			}
 		}
 
 		solidMilestoneIndex := tangle.GetSolidMilestoneIndex()
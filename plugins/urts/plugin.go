@@ -47,6 +47,11 @@ func configure(plugin *node.Plugin) {
 		config.NodeConfig.GetInt(config.CfgTipSelSemiLazy+config.CfgTipSelSpammerTipsThreshold),
 	)
 
+	dag.ConfigureRootSnapshotIndexesWriteBack(
+		config.NodeConfig.GetInt(config.CfgTipSelWriteBackMaxEntries),
+		time.Duration(config.NodeConfig.GetInt(config.CfgTipSelWriteBackMaxDelayMilliseconds))*time.Millisecond,
+	)
+
 	configureEvents()
 }
 
@@ -55,6 +60,7 @@ func run(_ *node.Plugin) {
 		attachEvents()
 		<-shutdownSignal
 		detachEvents()
+		dag.FlushRootSnapshotIndexes()
 	}, shutdown.PriorityTipselection)
 
 	daemon.BackgroundWorker("Tipselection[Cleanup]", func(shutdownSignal <-chan struct{}) {
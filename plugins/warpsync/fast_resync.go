@@ -0,0 +1,218 @@
+package warpsync
+
+import (
+	"errors"
+	"time"
+
+	"go.uber.org/atomic"
+
+	"github.com/iotaledger/hive.go/events"
+
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/peering/peer"
+	"github.com/gohornet/hornet/pkg/protocol/helpers"
+	"github.com/gohornet/hornet/pkg/protocol/sting"
+	"github.com/gohornet/hornet/plugins/gossip"
+	peeringplugin "github.com/gohornet/hornet/plugins/peering"
+	tangleplugin "github.com/gohornet/hornet/plugins/tangle"
+)
+
+var (
+	// ErrFastResyncNoPeer is returned if no connected peer has the data for a missing milestone.
+	ErrFastResyncNoPeer = errors.New("no peer found which has the data for the milestone")
+	// ErrFastResyncTimeout is returned if a milestone didn't become solid in time.
+	ErrFastResyncTimeout = errors.New("milestone didn't become solid in time")
+	// ErrFastResyncAborted is returned if the fast resync was aborted.
+	ErrFastResyncAborted = errors.New("fast resync was aborted")
+)
+
+var (
+	fastResyncEnabled          bool
+	fastResyncMaxMilestones    milestone.Index
+	fastResyncMilestoneTimeout time.Duration
+
+	// the fast resync is only tried once for the first target after startup.
+	fastResyncAttempted atomic.Bool
+	fastResyncActive    atomic.Bool
+	// the highest target which was seen while the fast resync was active.
+	fastResyncHighestTarget atomic.Uint32
+	fastResyncTargetSignal  = make(chan milestone.Index, 1)
+	fastResyncUpdateSignal  = make(chan struct{}, 1)
+)
+
+// tryFastResync starts the fast resync if the node is only a few milestones behind the given target after startup.
+// Returns whether the target was taken over by the fast resync and must not be passed to the warpsync.
+func tryFastResync(target milestone.Index) bool {
+	if !fastResyncEnabled {
+		return false
+	}
+
+	if fastResyncActive.Load() {
+		raiseFastResyncHighestTarget(target)
+
+		// the fast resync may have finished in the meantime, then the warpsync has to take over the target
+		return fastResyncActive.Load()
+	}
+
+	solidMilestoneIndex := tangle.GetSolidMilestoneIndex()
+	if target <= solidMilestoneIndex+1 {
+		// nothing to sync, the warpsync ignores these targets as well
+		return false
+	}
+
+	if !fastResyncAttempted.CAS(false, true) {
+		return false
+	}
+
+	if target-solidMilestoneIndex > fastResyncMaxMilestones {
+		return false
+	}
+
+	fastResyncActive.Store(true)
+	fastResyncHighestTarget.Store(uint32(target))
+	fastResyncTargetSignal <- target
+	return true
+}
+
+// raiseFastResyncHighestTarget sets the highest target seen by the fast resync to the given one, if it is higher.
+func raiseFastResyncHighestTarget(target milestone.Index) {
+	for {
+		highestTarget := fastResyncHighestTarget.Load()
+		if uint32(target) <= highestTarget || fastResyncHighestTarget.CAS(highestTarget, uint32(target)) {
+			return
+		}
+	}
+}
+
+// signalFastResyncUpdate wakes up the fast resync if a milestone was received or became solid.
+func signalFastResyncUpdate() {
+	select {
+	case fastResyncUpdateSignal <- struct{}{}:
+	default:
+	}
+}
+
+// bestPeerForMilestone returns the connected peer with the highest solid milestone index, which has the data for the given milestone.
+// Peers with a congested send queue are only chosen if no other peer has the data.
+func bestPeerForMilestone(msIndex milestone.Index) *peer.Peer {
+	var bestPeer, bestCongestedPeer *peer.Peer
+
+	peeringplugin.Manager().ForAllConnected(func(p *peer.Peer) bool {
+		if !p.Protocol.Supports(sting.FeatureSet) || !p.GossipEnabled() || !p.HasDataFor(msIndex) {
+			return true
+		}
+
+		if p.SendQueueCongested() {
+			if bestCongestedPeer == nil || p.LatestHeartbeat.SolidMilestoneIndex > bestCongestedPeer.LatestHeartbeat.SolidMilestoneIndex {
+				bestCongestedPeer = p
+			}
+			return true
+		}
+
+		if bestPeer == nil || p.LatestHeartbeat.SolidMilestoneIndex > bestPeer.LatestHeartbeat.SolidMilestoneIndex {
+			bestPeer = p
+		}
+		return true
+	})
+
+	if bestPeer == nil {
+		return bestCongestedPeer
+	}
+	return bestPeer
+}
+
+// waitForFastResyncCondition blocks until the condition is met, the timeout is reached or the shutdown signal was received.
+func waitForFastResyncCondition(condition func() bool, timeout time.Duration, shutdownSignal <-chan struct{}) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for !condition() {
+		select {
+		case <-shutdownSignal:
+			return ErrFastResyncAborted
+		case <-timer.C:
+			return ErrFastResyncTimeout
+		case <-fastResyncUpdateSignal:
+		}
+	}
+
+	return nil
+}
+
+// fastResync fetches the missing milestones up to the target one after another from the best peer
+// and solidifies them directly, without going through the warpsync checkpoints.
+func fastResync(target milestone.Index, shutdownSignal <-chan struct{}) error {
+
+	requestMissingMilestoneApprovees := gossip.MemoizedRequestMissingMilestoneApprovees(true)
+
+	for msIndex := tangle.GetSolidMilestoneIndex() + 1; msIndex <= target; msIndex++ {
+
+		if !tangle.ContainsMilestone(msIndex) {
+			p := bestPeerForMilestone(msIndex)
+			if p == nil {
+				return ErrFastResyncNoPeer
+			}
+
+			helpers.SendMilestoneRequest(p, msIndex)
+
+			if err := waitForFastResyncCondition(func() bool {
+				return tangle.ContainsMilestone(msIndex)
+			}, fastResyncMilestoneTimeout, shutdownSignal); err != nil {
+				return err
+			}
+		}
+
+		requestMissingMilestoneApprovees(msIndex)
+		tangleplugin.TriggerSolidifier()
+
+		if err := waitForFastResyncCondition(func() bool {
+			return tangle.GetSolidMilestoneIndex() >= msIndex
+		}, fastResyncMilestoneTimeout, shutdownSignal); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func runFastResync(shutdownSignal <-chan struct{}) {
+
+	onMilestoneUpdate := events.NewClosure(func(_ milestone.Index) {
+		signalFastResyncUpdate()
+	})
+	onReceivedNewMilestone := events.NewClosure(func(cachedBndl *tangle.CachedBundle) {
+		cachedBndl.Release() // bundle -1
+		signalFastResyncUpdate()
+	})
+
+	tangleplugin.Events.SolidMilestoneIndexChanged.Attach(onMilestoneUpdate)
+	defer tangleplugin.Events.SolidMilestoneIndexChanged.Detach(onMilestoneUpdate)
+	tangleplugin.Events.ReceivedNewMilestone.Attach(onReceivedNewMilestone)
+	defer tangleplugin.Events.ReceivedNewMilestone.Detach(onReceivedNewMilestone)
+
+	select {
+	case <-shutdownSignal:
+		return
+
+	case target := <-fastResyncTargetSignal:
+		start := tangle.GetSolidMilestoneIndex()
+		ts := time.Now()
+
+		log.Infof("Fast resync to milestone %d", target)
+		if err := fastResync(target, shutdownSignal); err != nil {
+			if errors.Is(err, ErrFastResyncAborted) {
+				return
+			}
+			log.Warnf("Fast resync to milestone %d failed, falling back to warpsync: %s", target, err)
+		} else {
+			log.Infof("Fast resynchronized %d milestones in %v", target-start, time.Since(ts))
+		}
+
+		fastResyncActive.Store(false)
+
+		// pass the highest target to the warpsync, it is ignored if the node is synced already
+		warpSync.UpdateCurrent(tangle.GetSolidMilestoneIndex())
+		warpSync.UpdateTarget(milestone.Index(fastResyncHighestTarget.Load()))
+	}
+}
@@ -38,6 +38,10 @@ func configure(plugin *node.Plugin) {
 	log = logger.NewLogger(plugin.Name)
 	warpSync = warpsync.New(config.NodeConfig.GetInt(config.CfgWarpSyncAdvancementRange))
 
+	fastResyncEnabled = config.NodeConfig.GetBool(config.CfgWarpSyncFastResyncEnabled)
+	fastResyncMaxMilestones = milestone.Index(config.NodeConfig.GetInt(config.CfgWarpSyncFastResyncMaxMilestones))
+	fastResyncMilestoneTimeout = time.Duration(config.NodeConfig.GetInt(config.CfgWarpSyncFastResyncMilestoneTimeoutSeconds)) * time.Second
+
 	configureEvents()
 }
 
@@ -48,6 +52,10 @@ func run(plugin *node.Plugin) {
 		<-shutdownSignal
 		detachEvents()
 	}, shutdown.PriorityWarpSync)
+
+	if fastResyncEnabled {
+		daemon.BackgroundWorker("WarpSync[FastResync]", runFastResync, shutdown.PriorityWarpSync)
+	}
 }
 
 func configureEvents() {
@@ -59,6 +67,9 @@ func configureEvents() {
 
 		p.Events.HeartbeatUpdated.Attach(events.NewClosure(func(hb *sting.Heartbeat) {
 			warpSync.UpdateCurrent(tangle.GetSolidMilestoneIndex())
+			if tryFastResync(hb.SolidMilestoneIndex) {
+				return
+			}
 			warpSync.UpdateTarget(hb.SolidMilestoneIndex)
 		}))
 	})
@@ -0,0 +1,23 @@
+package watchdog
+
+import (
+	"github.com/iotaledger/hive.go/events"
+
+	"github.com/gohornet/hornet/pkg/watchdog"
+)
+
+var Events = pluginEvents{
+	LoopStalled:   events.NewEvent(LoopStalledCaller),
+	LoopRecovered: events.NewEvent(events.StringCaller),
+}
+
+type pluginEvents struct {
+	// Fired when a core event loop didn't finish its current iteration within the deadline.
+	LoopStalled *events.Event
+	// Fired when a stalled loop finished its iterations again.
+	LoopRecovered *events.Event
+}
+
+func LoopStalledCaller(handler interface{}, params ...interface{}) {
+	handler.(func(stall *watchdog.Stall, goroutineStacks []byte))(params[0].(*watchdog.Stall), params[1].([]byte))
+}
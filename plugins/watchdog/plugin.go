@@ -0,0 +1,73 @@
+package watchdog
+
+import (
+	"time"
+
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/hive.go/node"
+	"github.com/iotaledger/hive.go/timeutil"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/shutdown"
+	"github.com/gohornet/hornet/pkg/watchdog"
+)
+
+const (
+	checkInterval = 5 * time.Second
+)
+
+var (
+	PLUGIN = node.NewPlugin("Watchdog", node.Enabled, configure, run)
+	log    *logger.Logger
+
+	deadline time.Duration
+
+	// the loops which were reported as stalled and didn't recover yet.
+	stalledLoops = make(map[string]struct{})
+)
+
+func configure(plugin *node.Plugin) {
+	log = logger.NewLogger(plugin.Name)
+
+	deadline = time.Duration(config.NodeConfig.GetInt(config.CfgWatchdogDeadlineSeconds)) * time.Second
+}
+
+func run(_ *node.Plugin) {
+	daemon.BackgroundWorker("Watchdog", func(shutdownSignal <-chan struct{}) {
+		timeutil.Ticker(checkLoops, checkInterval, shutdownSignal)
+	}, shutdown.PriorityWatchdog)
+}
+
+// checks the core event loops for stalls and reports each stall once.
+func checkLoops() {
+	stalls := watchdog.Stalled(deadline)
+
+	stalled := make(map[string]struct{}, len(stalls))
+	var goroutineStacks []byte
+	for _, stall := range stalls {
+		stalled[stall.Loop] = struct{}{}
+		if _, reported := stalledLoops[stall.Loop]; reported {
+			continue
+		}
+		stalledLoops[stall.Loop] = struct{}{}
+
+		// the stacks are only collected once per check, even if several loops stalled
+		if goroutineStacks == nil {
+			goroutineStacks = watchdog.GoroutineStacks()
+		}
+
+		log.Errorf("%s is stalled since %v, goroutine stacks:\n%s", stall.Loop, time.Since(stall.Since).Truncate(time.Second), goroutineStacks)
+		Events.LoopStalled.Trigger(stall, goroutineStacks)
+	}
+
+	for loop := range stalledLoops {
+		if _, stillStalled := stalled[loop]; stillStalled {
+			continue
+		}
+		delete(stalledLoops, loop)
+
+		log.Infof("%s recovered", loop)
+		Events.LoopRecovered.Trigger(loop)
+	}
+}
@@ -61,6 +61,18 @@ func webAPIRoute() {
 			}
 		}
 
+		if err := checkSafeMode(cmd); err != nil {
+			c.JSON(http.StatusServiceUnavailable, ErrorReturn{Error: fmt.Sprintf("command [%v] refused: %v", originCmd, err)})
+			return
+		}
+
+		done, err := beginRequest(cmd)
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, ErrorReturn{Error: fmt.Sprintf("command [%v] refused: %v", originCmd, err)})
+			return
+		}
+		defer done()
+
 		implementation(&request, c, serverShutdownSignal)
 	})
 }
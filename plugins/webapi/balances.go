@@ -43,7 +43,7 @@ func getBalances(i interface{}, c *gin.Context, _ <-chan struct{}) {
 		}
 	}
 
-	if !tangle.WaitForNodeSynced(waitForNodeSyncedTimeout) {
+	if !waitForNodeSynced() {
 		e.Error = ErrNodeNotSync.Error()
 		c.JSON(http.StatusBadRequest, e)
 		return
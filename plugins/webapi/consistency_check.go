@@ -0,0 +1,51 @@
+package webapi
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/scheduler"
+	tangleplugin "github.com/gohornet/hornet/plugins/tangle"
+)
+
+func init() {
+	addEndpoint("checkDatabaseConsistency", checkDatabaseConsistency, implementedAPIcalls)
+	addEndpoint("getDatabaseConsistencyReport", getDatabaseConsistencyReport, implementedAPIcalls)
+}
+
+func checkDatabaseConsistency(i interface{}, c *gin.Context, _ <-chan struct{}) {
+	e := ErrorReturn{}
+	query := &CheckDatabaseConsistency{}
+
+	if err := mapstructure.Decode(i, query); err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if err := tangleplugin.StartConsistencyCheck(query.Repair); err != nil {
+		e.Error = err.Error()
+		if errors.Is(err, tangleplugin.ErrConsistencyCheckRunning) {
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	maxEntriesPerSecond := config.NodeConfig.GetInt(config.CfgDatabaseConsistencyCheckMaxEntriesPerSecond)
+	jobID := scheduler.Submit("Database consistency check", func(abortSignal <-chan struct{}) error {
+		return tangleplugin.RunConsistencyCheck(maxEntriesPerSecond, abortSignal)
+	})
+
+	c.JSON(http.StatusOK, CheckDatabaseConsistencyReturn{JobID: jobID})
+}
+
+func getDatabaseConsistencyReport(_ interface{}, c *gin.Context, _ <-chan struct{}) {
+	c.JSON(http.StatusOK, GetDatabaseConsistencyReportReturn{Report: tangleplugin.LatestConsistencyReport()})
+}
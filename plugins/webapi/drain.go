@@ -0,0 +1,81 @@
+package webapi
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"go.uber.org/atomic"
+)
+
+var (
+	// ErrNodeDraining is returned when a long-running request is refused because the node is draining.
+	ErrNodeDraining = errors.New("node is draining")
+
+	// whether the node is draining, which means it doesn't accept new long-running requests anymore.
+	draining atomic.Bool
+	// the amount of long-running requests which are currently processed.
+	inFlightLongRunningRequests atomic.Int32
+
+	// the API calls which are refused while the node is draining.
+	longRunningAPIcalls = map[string]struct{}{
+		"attachtotangle":           {},
+		"findtransactions":         {},
+		"getledgerdiff":            {},
+		"getledgerdiffext":         {},
+		"getledgerstate":           {},
+		"createsnapshotfile":       {},
+		"prunedatabase":            {},
+		"searchconfirmedapprover":  {},
+		"searchentrypoints":        {},
+		"getfundsonspentaddresses": {},
+		"checkconsistency":         {},
+	}
+)
+
+func init() {
+	addEndpoint("setDrainMode", setDrainMode, implementedAPIcalls)
+}
+
+// IsDraining returns whether the node is draining.
+func IsDraining() bool {
+	return draining.Load()
+}
+
+// beginRequest checks whether the given API call may be processed and tracks it if it is long-running.
+// The returned function must be called once the request was processed.
+func beginRequest(cmd string) (done func(), err error) {
+	if _, longRunning := longRunningAPIcalls[cmd]; !longRunning {
+		return func() {}, nil
+	}
+
+	if draining.Load() {
+		return nil, ErrNodeDraining
+	}
+
+	inFlightLongRunningRequests.Inc()
+	return func() { inFlightLongRunningRequests.Dec() }, nil
+}
+
+func setDrainMode(i interface{}, c *gin.Context, _ <-chan struct{}) {
+	e := ErrorReturn{}
+	query := &SetDrainMode{}
+
+	if err := mapstructure.Decode(i, query); err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if draining.Swap(query.Enabled) != query.Enabled {
+		if query.Enabled {
+			log.Info("Drain mode enabled, new long-running API requests are refused")
+		} else {
+			log.Info("Drain mode disabled")
+		}
+	}
+
+	c.JSON(http.StatusOK, SetDrainModeReturn{Draining: draining.Load(), InFlightRequests: inFlightLongRunningRequests.Load()})
+}
@@ -0,0 +1,17 @@
+package webapi
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	fleetPlugin "github.com/gohornet/hornet/plugins/fleet"
+)
+
+func init() {
+	addEndpoint("getFleetStatus", getFleetStatus, implementedAPIcalls)
+}
+
+func getFleetStatus(_ interface{}, c *gin.Context, _ <-chan struct{}) {
+	c.JSON(http.StatusOK, GetFleetStatusReturn{Nodes: fleetPlugin.Nodes()})
+}
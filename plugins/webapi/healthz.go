@@ -1,14 +1,25 @@
 package webapi
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/plugins/cli"
+	"github.com/gohornet/hornet/plugins/peering"
+	replicaPlugin "github.com/gohornet/hornet/plugins/replica"
 	"github.com/gohornet/hornet/plugins/tangle"
 )
 
+var (
+	// ErrStaticPeersUnreachable is returned when static peers are unreachable for longer than the alert threshold.
+	ErrStaticPeersUnreachable = errors.New("static peers are unreachable")
+)
+
 func healthzRoute() {
 	api.GET("/healthz", func(c *gin.Context) {
 
@@ -20,12 +31,44 @@ func healthzRoute() {
 			}
 		}
 
+		// the node is taken out of the load balancer while draining
+		if IsDraining() {
+			c.JSON(http.StatusServiceUnavailable, ErrorReturn{Error: ErrNodeDraining.Error()})
+			return
+		}
+
+		// a read-only replica is healthy as long as it serves up to date data
+		if replicaPlugin.IsReplica() {
+			if !replicaPlugin.Status().IsSynced {
+				c.JSON(http.StatusServiceUnavailable, ErrorReturn{Error: ErrReplicaNotSynced.Error()})
+				return
+			}
+			c.Status(http.StatusOK)
+			return
+		}
+
+		// the node doesn't take part in the network in safe mode
+		if cli.IsSafeMode() {
+			c.JSON(http.StatusServiceUnavailable, ErrorReturn{Error: ErrSafeMode.Error()})
+			return
+		}
+
 		// autopeering entrypoint mode
 		if config.NodeConfig.GetBool(config.CfgNetAutopeeringRunAsEntryNode) {
 			c.Status(http.StatusOK)
 			return
 		}
 
+		// losing static peers silently degrades the node
+		if unreachable := peering.Manager().UnreachableStaticPeers(); len(unreachable) > 0 {
+			names := make([]string, 0, len(unreachable))
+			for _, unreachablePeer := range unreachable {
+				names = append(names, unreachablePeer.Name())
+			}
+			c.JSON(http.StatusServiceUnavailable, ErrorReturn{Error: fmt.Sprintf("%s: %s", ErrStaticPeersUnreachable, strings.Join(names, ", "))})
+			return
+		}
+
 		// node mode
 		if !tangle.IsNodeHealthy() {
 			c.Status(http.StatusServiceUnavailable)
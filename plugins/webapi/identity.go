@@ -0,0 +1,51 @@
+package webapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gohornet/hornet/plugins/autopeering"
+)
+
+func init() {
+	addEndpoint("exportIdentity", exportIdentity, implementedAPIcalls)
+	addEndpoint("rotateIdentity", rotateIdentity, implementedAPIcalls)
+}
+
+func exportIdentity(_ interface{}, c *gin.Context, _ <-chan struct{}) {
+	e := ErrorReturn{}
+
+	publicKey, data, err := autopeering.ExportIdentity()
+	if err != nil {
+		e.Error = err.Error()
+		if errors.Is(err, autopeering.ErrIdentityKeystoreDisabled) {
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	c.JSON(http.StatusOK, ExportIdentityReturn{PublicKey: publicKey.String(), Keystore: json.RawMessage(data)})
+}
+
+func rotateIdentity(_ interface{}, c *gin.Context, _ <-chan struct{}) {
+	e := ErrorReturn{}
+
+	publicKey, err := autopeering.RotateIdentity()
+	if err != nil {
+		e.Error = err.Error()
+		if errors.Is(err, autopeering.ErrIdentityKeystoreDisabled) {
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	log.Infof("rotated the identity, the new identity %s is used after a restart", publicKey)
+	c.JSON(http.StatusOK, RotateIdentityReturn{PublicKey: publicKey.String(), RestartRequired: true})
+}
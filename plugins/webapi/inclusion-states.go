@@ -35,7 +35,7 @@ func getInclusionStates(i interface{}, c *gin.Context, _ <-chan struct{}) {
 		}
 	}
 
-	if !tangle.WaitForNodeSynced(waitForNodeSyncedTimeout) {
+	if !waitForNodeSynced() {
 		e.Error = ErrNodeNotSync.Error()
 		c.JSON(http.StatusBadRequest, e)
 		return
@@ -11,6 +11,7 @@ import (
 	"github.com/gohornet/hornet/pkg/config"
 	"github.com/gohornet/hornet/pkg/metrics"
 	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/profile"
 	"github.com/gohornet/hornet/plugins/cli"
 	"github.com/gohornet/hornet/plugins/gossip"
 	"github.com/gohornet/hornet/plugins/peering"
@@ -68,7 +69,12 @@ func getNodeInfo(_ interface{}, c *gin.Context, _ <-chan struct{}) {
 	if snapshotInfo != nil {
 		result.MilestoneStartIndex = snapshotInfo.PruningIndex
 		result.LastSnapshottedMilestoneIndex = snapshotInfo.SnapshotIndex
+
+		// History range, the milestones after the pruning index up to the solid milestone are available
+		result.HistoryStartIndex = snapshotInfo.PruningIndex + 1
+		result.HistoryEndIndex = smi
 	}
+	result.IsPermanode = profile.LoadProfile().Permanode
 
 	// System time
 	result.Time = time.Now().Unix() * 1000
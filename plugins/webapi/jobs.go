@@ -0,0 +1,45 @@
+package webapi
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+
+	"github.com/gohornet/hornet/pkg/scheduler"
+)
+
+func init() {
+	addEndpoint("getJobs", getJobs, implementedAPIcalls)
+	addEndpoint("cancelJob", cancelJob, implementedAPIcalls)
+}
+
+func getJobs(_ interface{}, c *gin.Context, _ <-chan struct{}) {
+	scheduled, running, finished := scheduler.Jobs()
+	c.JSON(http.StatusOK, GetJobsReturn{Scheduled: scheduled, Running: running, Finished: finished})
+}
+
+func cancelJob(i interface{}, c *gin.Context, _ <-chan struct{}) {
+	e := ErrorReturn{}
+	query := &CancelJob{}
+
+	if err := mapstructure.Decode(i, query); err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if err := scheduler.Cancel(query.ID); err != nil {
+		e.Error = err.Error()
+		if errors.Is(err, scheduler.ErrJobNotFound) {
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	c.JSON(http.StatusOK, CancelJobReturn{})
+}
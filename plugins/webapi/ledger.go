@@ -12,12 +12,14 @@ import (
 	"github.com/gohornet/hornet/pkg/model/hornet"
 	"github.com/gohornet/hornet/pkg/model/milestone"
 	"github.com/gohornet/hornet/pkg/model/tangle"
+	tanglePlugin "github.com/gohornet/hornet/plugins/tangle"
 )
 
 func init() {
 	addEndpoint("getLedgerDiff", getLedgerDiff, implementedAPIcalls)
 	addEndpoint("getLedgerDiffExt", getLedgerDiffExt, implementedAPIcalls)
 	addEndpoint("getLedgerState", getLedgerState, implementedAPIcalls)
+	addEndpoint("getConfirmationSummaries", getConfirmationSummaries, implementedAPIcalls)
 }
 
 func getLedgerDiff(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
@@ -226,3 +228,7 @@ func getLedgerState(i interface{}, c *gin.Context, abortSignal <-chan struct{})
 
 	c.JSON(http.StatusOK, GetLedgerStateReturn{Balances: balancesTrytes, MilestoneIndex: index})
 }
+
+func getConfirmationSummaries(_ interface{}, c *gin.Context, _ <-chan struct{}) {
+	c.JSON(http.StatusOK, GetConfirmationSummariesReturn{Summaries: tanglePlugin.ConfirmationSummaries()})
+}
@@ -1,14 +1,20 @@
 package webapi
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mitchellh/mapstructure"
 
 	"github.com/gohornet/hornet/pkg/config"
+	peeringpkg "github.com/gohornet/hornet/pkg/peering"
+	peerpkg "github.com/gohornet/hornet/pkg/peering/peer"
+	"github.com/gohornet/hornet/pkg/protocol/sting"
+	"github.com/gohornet/hornet/plugins/gossip"
 	"github.com/gohornet/hornet/plugins/peering"
 )
 
@@ -16,6 +22,13 @@ func init() {
 	addEndpoint("addNeighbors", addNeighbors, implementedAPIcalls)
 	addEndpoint("removeNeighbors", removeNeighbors, implementedAPIcalls)
 	addEndpoint("getNeighbors", getNeighbors, implementedAPIcalls)
+	addEndpoint("getNeighborSuggestions", getNeighborSuggestions, implementedAPIcalls)
+	addEndpoint("getNeighborCapabilities", getNeighborCapabilities, implementedAPIcalls)
+	addEndpoint("banNeighbor", banNeighbor, implementedAPIcalls)
+	addEndpoint("unbanNeighbor", unbanNeighbor, implementedAPIcalls)
+	addEndpoint("getBannedNeighbors", getBannedNeighbors, implementedAPIcalls)
+	addEndpoint("exportNeighbors", exportNeighbors, implementedAPIcalls)
+	addEndpoint("importNeighbors", importNeighbors, implementedAPIcalls)
 }
 
 func addNeighbors(i interface{}, c *gin.Context, _ <-chan struct{}) {
@@ -50,11 +63,12 @@ func addNeighbors(i interface{}, c *gin.Context, _ <-chan struct{}) {
 
 	for _, uri := range query.Uris {
 
-		if strings.Contains(uri, "tcp://") {
-			uri = uri[6:]
-		} else if strings.Contains(uri, "://") {
+		// WebSocket peers keep their scheme
+		transport, addr := peerpkg.ParseTransportAddress(uri)
+		if strings.Contains(addr, "://") {
 			continue
 		}
+		uri = transport.Address(addr)
 
 		contains := false
 		for _, cn := range configPeers {
@@ -102,11 +116,12 @@ func addNeighborsWithAlias(s *AddNeighborsHornet, c *gin.Context) {
 
 	for _, peer := range s.Neighbors {
 
-		if strings.Contains(peer.Identity, "tcp://") {
-			peer.Identity = peer.Identity[6:]
-		} else if strings.Contains(peer.Identity, "://") {
+		// WebSocket peers keep their scheme
+		transport, addr := peerpkg.ParseTransportAddress(peer.Identity)
+		if strings.Contains(addr, "://") {
 			continue
 		}
+		peer.Identity = transport.Address(addr)
 
 		contains := false
 		for _, cn := range configPeers {
@@ -218,3 +233,149 @@ func removeNeighbors(i interface{}, c *gin.Context, _ <-chan struct{}) {
 func getNeighbors(i interface{}, c *gin.Context, _ <-chan struct{}) {
 	c.JSON(http.StatusOK, GetNeighborsReturn{Neighbors: peering.Manager().PeerInfos()})
 }
+
+func getNeighborSuggestions(i interface{}, c *gin.Context, _ <-chan struct{}) {
+	c.JSON(http.StatusOK, GetNeighborSuggestionsReturn{Suggestions: gossip.NeighborSuggestions()})
+}
+
+func getNeighborCapabilities(i interface{}, c *gin.Context, _ <-chan struct{}) {
+	e := ErrorReturn{}
+	query := &GetNeighborCapabilities{}
+
+	if err := mapstructure.Decode(i, query); err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	services, err := sting.ParseServiceNames(query.Services)
+	if err != nil {
+		e.Error = fmt.Sprintf("%v: %v", err, query.Services)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	c.JSON(http.StatusOK, GetNeighborCapabilitiesReturn{Neighbors: gossip.NeighborsCapabilities(services)})
+}
+
+func banNeighbor(i interface{}, c *gin.Context, _ <-chan struct{}) {
+	e := ErrorReturn{}
+	query := &BanNeighbor{}
+
+	if err := mapstructure.Decode(i, query); err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if err := peering.Manager().Ban(query.ID, time.Duration(query.DurationSeconds)*time.Second, query.Reason); err != nil {
+		e.Error = err.Error()
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	c.JSON(http.StatusOK, BanNeighborReturn{})
+}
+
+func unbanNeighbor(i interface{}, c *gin.Context, _ <-chan struct{}) {
+	e := ErrorReturn{}
+	query := &UnbanNeighbor{}
+
+	if err := mapstructure.Decode(i, query); err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if err := peering.Manager().Unban(query.ID); err != nil {
+		e.Error = err.Error()
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	c.JSON(http.StatusOK, UnbanNeighborReturn{})
+}
+
+func getBannedNeighbors(_ interface{}, c *gin.Context, _ <-chan struct{}) {
+	c.JSON(http.StatusOK, GetBannedNeighborsReturn{Bans: peering.Manager().Bans()})
+}
+
+func exportNeighbors(_ interface{}, c *gin.Context, _ <-chan struct{}) {
+	e := ErrorReturn{}
+
+	export, err := peering.Manager().ExportPeers()
+	if err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	c.JSON(http.StatusOK, ExportNeighborsReturn{Export: export})
+}
+
+func importNeighbors(i interface{}, c *gin.Context, _ <-chan struct{}) {
+	e := ErrorReturn{}
+	query := &ImportNeighbors{}
+
+	if err := mapstructure.Decode(i, query); err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	data, err := json.Marshal(query.Export)
+	if err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	export, err := peeringpkg.ParsePeerExport(data)
+	if err != nil {
+		e.Error = err.Error()
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	importedPeers, err := peering.Manager().ImportPeers(data)
+	if err != nil {
+		e.Error = err.Error()
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	// the imported peers are kept across restarts
+	var configPeers []config.PeerConfig
+	if err := config.PeeringConfig.UnmarshalKey(config.CfgPeers, &configPeers); err != nil {
+		log.Warn(err)
+	}
+
+	added := false
+	for _, p := range export.StaticPeers() {
+		contains := false
+		for _, cn := range configPeers {
+			if cn.ID == p.Address {
+				contains = true
+				break
+			}
+		}
+
+		if !contains {
+			configPeers = append(configPeers, config.PeerConfig{
+				ID:         p.Address,
+				Alias:      p.Alias,
+				PreferIPv6: p.PreferIPv6,
+			})
+			added = true
+		}
+	}
+
+	if added {
+		config.DenyPeeringConfigHotReload()
+		config.PeeringConfig.Set(config.CfgPeers, configPeers)
+		config.PeeringConfig.WriteConfig()
+		config.AllowPeeringConfigHotReload()
+	}
+
+	c.JSON(http.StatusOK, ImportNeighborsReturn{ImportedNeighbors: importedPeers})
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/gohornet/hornet/pkg/config"
 	"github.com/gohornet/hornet/pkg/model/tangle"
 	"github.com/gohornet/hornet/pkg/shutdown"
+	"github.com/gohornet/hornet/plugins/cli"
 )
 
 const (
@@ -166,7 +167,7 @@ func configure(plugin *node.Plugin) {
 		webAPIRoute()
 
 		// only handle spammer api calls if the spammer plugin is enabled
-		if !node.IsSkipped(spammer.PLUGIN) {
+		if !cli.IsSafeMode() && !node.IsSkipped(spammer.PLUGIN) {
 			spammerRoute()
 		}
 	}
@@ -186,7 +187,7 @@ func run(_ *node.Plugin) {
 			features = append(features, "RemotePOW")
 		}
 
-		if tangle.GetSnapshotInfo().IsSpentAddressesEnabled() {
+		if snapshotInfo := tangle.GetSnapshotInfo(); snapshotInfo != nil && snapshotInfo.IsSpentAddressesEnabled() {
 			features = append(features, "WereAddressesSpentFrom")
 		}
 	}
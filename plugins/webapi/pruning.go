@@ -12,6 +12,7 @@ import (
 
 func init() {
 	addEndpoint("pruneDatabase", pruneDatabase, implementedAPIcalls)
+	addEndpoint("getPruningAndSnapshotProgress", getPruningAndSnapshotProgress, implementedAPIcalls)
 }
 
 func pruneDatabase(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
@@ -46,3 +47,7 @@ func pruneDatabase(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
 
 	c.JSON(http.StatusOK, PruneDatabaseReturn{})
 }
+
+func getPruningAndSnapshotProgress(_ interface{}, c *gin.Context, _ <-chan struct{}) {
+	c.JSON(http.StatusOK, GetPruningAndSnapshotProgressReturn{Pruning: snapshot.GetPruningProgress(), Snapshot: snapshot.GetSnapshotProgress()})
+}
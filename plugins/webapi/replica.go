@@ -0,0 +1,30 @@
+package webapi
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	replicaPlugin "github.com/gohornet/hornet/plugins/replica"
+)
+
+var (
+	// ErrNotReplica is returned when the replica status is requested from a node which doesn't run as a read-only replica.
+	ErrNotReplica = errors.New("node is not running as a read-only replica")
+	// ErrReplicaNotSynced is returned when a read-only replica lags behind its primary or lost the connection to it.
+	ErrReplicaNotSynced = errors.New("replica is not synced with its primary")
+)
+
+func init() {
+	addEndpoint("getReplicaStatus", getReplicaStatus, implementedAPIcalls)
+}
+
+func getReplicaStatus(_ interface{}, c *gin.Context, _ <-chan struct{}) {
+	if !replicaPlugin.IsReplica() {
+		c.JSON(http.StatusBadRequest, ErrorReturn{Error: ErrNotReplica.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, GetReplicaStatusReturn{Status: replicaPlugin.Status()})
+}
@@ -0,0 +1,67 @@
+package webapi
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/whiteflag"
+)
+
+func init() {
+	addEndpoint("revalidateMilestoneCone", revalidateMilestoneCone, implementedAPIcalls)
+}
+
+func revalidateMilestoneCone(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	e := ErrorReturn{}
+	query := &RevalidateMilestoneCone{}
+
+	if err := mapstructure.Decode(i, query); err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	smi := tangle.GetSolidMilestoneIndex()
+	requestedIndex := milestone.Index(query.MilestoneIndex)
+	if requestedIndex == 0 || requestedIndex > smi {
+		e.Error = fmt.Sprintf("Invalid milestone index supplied, lsmi is %d", smi)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	revalidation, err := whiteflag.RevalidateMilestoneCone(requestedIndex, abortSignal)
+	if err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	divergences := make([]*LedgerDivergence, len(revalidation.Divergences))
+	for i, divergence := range revalidation.Divergences {
+		divergences[i] = &LedgerDivergence{
+			Address:  divergence.Address.Trytes(),
+			Computed: divergence.Computed,
+			Stored:   divergence.Stored,
+		}
+	}
+
+	mutations := revalidation.Mutations
+	c.JSON(http.StatusOK, RevalidateMilestoneConeReturn{
+		MilestoneIndex:           requestedIndex,
+		Consistent:               len(divergences) == 0 && revalidation.MerkleTreeHashMatches(),
+		MerkleTreeHash:           hex.EncodeToString(mutations.MerkleTreeHash),
+		MilestoneMerkleTreeHash:  hex.EncodeToString(revalidation.MilestoneMerkleTreeHash),
+		MerkleTreeHashMatches:    revalidation.MerkleTreeHashMatches(),
+		TailsIncluded:            len(mutations.TailsIncluded),
+		TailsExcludedConflicting: len(mutations.TailsExcludedConflicting),
+		TailsExcludedZeroValue:   len(mutations.TailsExcludedZeroValue),
+		TailsReferenced:          len(mutations.TailsReferenced),
+		Divergences:              divergences,
+	})
+}
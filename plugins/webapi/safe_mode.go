@@ -0,0 +1,63 @@
+package webapi
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/plugins/cli"
+	replicaPlugin "github.com/gohornet/hornet/plugins/replica"
+)
+
+var (
+	// ErrSafeMode is returned when an API call is refused because the node was started in safe mode.
+	ErrSafeMode = errors.New("node is running in safe mode")
+
+	// the API calls which are allowed in safe mode.
+	// they only read the database and don't depend on the gossip or the tangle plugin.
+	readOnlyAPIcalls = map[string]struct{}{
+		"getbalances":                  {},
+		"getinclusionstates":           {},
+		"wereaddressesspentfrom":       {},
+		"findtransactions":             {},
+		"gettrytes":                    {},
+		"getledgerdiff":                {},
+		"getledgerdiffext":             {},
+		"getledgerstate":               {},
+		"searchconfirmedapprover":      {},
+		"searchentrypoints":            {},
+		"getfundsonspentaddresses":     {},
+		"getnodeapiconfiguration":      {},
+		"revalidatemilestonecone":      {},
+		"getreplicastatus":             {},
+		"getjobs":                      {},
+		"getdatabaseconsistencyreport": {},
+	}
+)
+
+// checkSafeMode returns ErrSafeMode if the node was started in safe mode and the given API call is not read-only.
+func checkSafeMode(cmd string) error {
+	if !cli.IsSafeMode() {
+		return nil
+	}
+
+	if _, readOnly := readOnlyAPIcalls[cmd]; !readOnly {
+		return ErrSafeMode
+	}
+
+	return nil
+}
+
+// waitForNodeSynced waits at most "waitForNodeSyncedTimeout" for the node to become synced.
+// The node never becomes synced in safe mode, so the data of the database is served as it is,
+// unless the node runs as a read-only replica which knows the state of its primary.
+func waitForNodeSynced() bool {
+	if replicaPlugin.IsReplica() {
+		return replicaPlugin.Status().IsSynced
+	}
+
+	if cli.IsSafeMode() {
+		return true
+	}
+
+	return tangle.WaitForNodeSynced(waitForNodeSyncedTimeout)
+}
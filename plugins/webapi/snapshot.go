@@ -1,9 +1,11 @@
 package webapi
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"path/filepath"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mitchellh/mapstructure"
@@ -15,6 +17,7 @@ import (
 
 func init() {
 	addEndpoint("createSnapshotFile", createSnapshotFile, implementedAPIcalls)
+	addEndpoint("replaceStateWithSnapshot", replaceStateWithSnapshot, implementedAPIcalls)
 }
 
 func createSnapshotFile(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
@@ -37,3 +40,36 @@ func createSnapshotFile(i interface{}, c *gin.Context, abortSignal <-chan struct
 
 	c.JSON(http.StatusOK, CreateSnapshotFileReturn{})
 }
+
+func replaceStateWithSnapshot(i interface{}, c *gin.Context, _ <-chan struct{}) {
+	e := ErrorReturn{}
+	query := &ReplaceStateWithSnapshot{}
+
+	if err := mapstructure.Decode(i, query); err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	ts := time.Now()
+
+	var snapshotIndex milestone.Index
+	var err error
+	if query.FilePath == "" {
+		snapshotIndex, err = snapshot.ReplaceStateWithDownloadedSnapshot()
+	} else {
+		snapshotIndex, err = snapshot.ReplaceStateWithSnapshot(query.FilePath)
+	}
+
+	if err != nil {
+		e.Error = err.Error()
+		if errors.Is(err, snapshot.ErrSnapshotNotNewer) {
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	c.JSON(http.StatusOK, ReplaceStateWithSnapshotReturn{SnapshotIndex: snapshotIndex, Duration: int(time.Since(ts).Milliseconds())})
+}
@@ -0,0 +1,48 @@
+package webapi
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/gohornet/hornet/plugins/gossip"
+)
+
+const (
+	// the amount of spam sources which are returned if no limit was given.
+	defaultSpamSourcesLimit = 20
+)
+
+func init() {
+	addEndpoint("getSpamStatistics", getSpamStatistics, implementedAPIcalls)
+}
+
+func getSpamStatistics(i interface{}, c *gin.Context, _ <-chan struct{}) {
+	e := ErrorReturn{}
+	query := &GetSpamStatistics{}
+
+	detector := gossip.SpamDetector()
+	if detector == nil {
+		e.Error = "spam detection is not enabled on this node"
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	if err := mapstructure.Decode(i, query); err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultSpamSourcesLimit
+	}
+
+	c.JSON(http.StatusOK, GetSpamStatisticsReturn{
+		Sources:        detector.TopSources(limit),
+		FilterCounters: detector.FilterCounters(),
+	})
+}
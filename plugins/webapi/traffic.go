@@ -0,0 +1,101 @@
+package webapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+)
+
+func init() {
+	addEndpoint("getPeerTrafficReport", getPeerTrafficReport, implementedAPIcalls)
+}
+
+func getPeerTrafficReport(i interface{}, c *gin.Context, _ <-chan struct{}) {
+	e := ErrorReturn{}
+	query := &GetPeerTrafficReport{}
+
+	if err := mapstructure.Decode(i, query); err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if !config.NodeConfig.GetBool(config.CfgNetGossipTrafficHistoryEnabled) {
+		e.Error = "Peer traffic history is disabled"
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	var resolution tangle.PeerTrafficResolution
+	switch query.Resolution {
+	case "", "daily":
+		resolution = tangle.PeerTrafficDaily
+	case "hourly":
+		resolution = tangle.PeerTrafficHourly
+	default:
+		e.Error = fmt.Sprintf("Invalid resolution supplied: %s, must be \"hourly\" or \"daily\"", query.Resolution)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	to := time.Now()
+	if query.To != 0 {
+		to = time.Unix(query.To, 0)
+	}
+
+	from := to.Add(-24 * time.Hour)
+	if query.From != 0 {
+		from = time.Unix(query.From, 0)
+	}
+
+	if !from.Before(to) {
+		e.Error = "Invalid time range supplied, from must be before to"
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	// the periods containing the boundaries are included completely
+	from = resolution.PeriodStart(from)
+
+	peers := make(map[string]*tangle.PeerTraffic)
+	total := &tangle.PeerTraffic{}
+
+	if err := tangle.ForEachPeerTraffic(resolution, from, to, func(_ time.Time, peerID string, traffic *tangle.PeerTraffic) bool {
+		peerTraffic, exists := peers[peerID]
+		if !exists {
+			peerTraffic = &tangle.PeerTraffic{}
+			peers[peerID] = peerTraffic
+		}
+		peerTraffic.Add(traffic)
+		total.Add(traffic)
+		return true
+	}); err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	result := GetPeerTrafficReportReturn{
+		From:  from.Unix(),
+		To:    to.Unix(),
+		Peers: make([]*PeerTrafficReport, 0, len(peers)),
+		Total: total,
+	}
+
+	for peerID, traffic := range peers {
+		result.Peers = append(result.Peers, &PeerTrafficReport{PeerID: peerID, PeerTraffic: traffic})
+	}
+
+	sort.Slice(result.Peers, func(i, j int) bool {
+		return result.Peers[i].PeerID < result.Peers[j].PeerID
+	})
+
+	c.JSON(http.StatusOK, result)
+}
@@ -1,10 +1,21 @@
 package webapi
 
 import (
+	"encoding/json"
+
 	"github.com/iotaledger/iota.go/trinary"
 
+	"github.com/gohornet/hornet/pkg/fleet"
 	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/peering"
 	"github.com/gohornet/hornet/pkg/peering/peer"
+	"github.com/gohornet/hornet/pkg/replica"
+	"github.com/gohornet/hornet/pkg/scheduler"
+	"github.com/gohornet/hornet/pkg/spamfilter"
+	"github.com/gohornet/hornet/plugins/gossip"
+	"github.com/gohornet/hornet/plugins/snapshot"
+	tanglePlugin "github.com/gohornet/hornet/plugins/tangle"
 )
 
 //////////////////// addNeighbors /////////////////////////////////
@@ -146,6 +157,92 @@ type GetNeighborsReturn struct {
 	Duration  int          `json:"duration"`
 }
 
+////////////////// getNeighborSuggestions /////////////////////////
+
+// GetNeighborSuggestions struct
+type GetNeighborSuggestions struct {
+	Command string `mapstructure:"command"`
+}
+
+// GetNeighborSuggestionsReturn struct
+type GetNeighborSuggestionsReturn struct {
+	Suggestions []*gossip.NeighborSuggestion `json:"suggestions"`
+	Duration    int                          `json:"duration"`
+}
+
+////////////////// getNeighborCapabilities /////////////////////////
+
+// GetNeighborCapabilities struct
+type GetNeighborCapabilities struct {
+	Command string `mapstructure:"command"`
+	// Only neighbors which offer all of these services are returned ("snapshots", "publicAPI", "permanode").
+	Services []string `mapstructure:"services"`
+}
+
+// GetNeighborCapabilitiesReturn struct
+type GetNeighborCapabilitiesReturn struct {
+	Neighbors []*gossip.NeighborCapabilities `json:"neighbors"`
+	Duration  int                            `json:"duration"`
+}
+
+////////////////////// banNeighbor ///////////////////////////////
+
+// BanNeighbor struct
+type BanNeighbor struct {
+	Command         string `mapstructure:"command"`
+	ID              string `mapstructure:"id"`
+	DurationSeconds int    `mapstructure:"durationSeconds"`
+	Reason          string `mapstructure:"reason"`
+}
+
+// BanNeighborReturn struct
+type BanNeighborReturn struct {
+	Duration int `json:"duration"`
+}
+
+///////////////////// unbanNeighbor //////////////////////////////
+
+// UnbanNeighbor struct
+type UnbanNeighbor struct {
+	Command string `mapstructure:"command"`
+	ID      string `mapstructure:"id"`
+}
+
+// UnbanNeighborReturn struct
+type UnbanNeighborReturn struct {
+	Duration int `json:"duration"`
+}
+
+////////////////// getBannedNeighbors ////////////////////////////
+
+// GetBannedNeighborsReturn struct
+type GetBannedNeighborsReturn struct {
+	Bans     []*peering.Ban `json:"bans"`
+	Duration int            `json:"duration"`
+}
+
+/////////////////// exportNeighbors //////////////////////////////
+
+// ExportNeighborsReturn struct
+type ExportNeighborsReturn struct {
+	Export   json.RawMessage `json:"export"`
+	Duration int             `json:"duration"`
+}
+
+/////////////////// importNeighbors //////////////////////////////
+
+// ImportNeighbors struct
+type ImportNeighbors struct {
+	Command string                 `mapstructure:"command"`
+	Export  map[string]interface{} `mapstructure:"export"`
+}
+
+// ImportNeighborsReturn struct
+type ImportNeighborsReturn struct {
+	ImportedNeighbors int `json:"importedNeighbors"`
+	Duration          int `json:"duration"`
+}
+
 /////////////////////// getNodeInfo ///////////////////////////////
 
 // GetNodeInfo struct
@@ -166,6 +263,9 @@ type GetNodeInfoReturn struct {
 	Health                             bool            `json:"isHealthy"`
 	MilestoneStartIndex                milestone.Index `json:"milestoneStartIndex"`
 	LastSnapshottedMilestoneIndex      milestone.Index `json:"lastSnapshottedMilestoneIndex"`
+	IsPermanode                        bool            `json:"isPermanode"`
+	HistoryStartIndex                  milestone.Index `json:"historyStartIndex"`
+	HistoryEndIndex                    milestone.Index `json:"historyEndIndex"`
 	Neighbors                          uint            `json:"neighbors"`
 	Time                               int64           `json:"time"`
 	Tips                               uint32          `json:"tips"`
@@ -345,6 +445,44 @@ type GetLedgerStateReturn struct {
 	Duration       int                     `json:"duration"`
 }
 
+/////////////////// getConfirmationSummaries ////////////////////////
+
+// GetConfirmationSummariesReturn struct
+type GetConfirmationSummariesReturn struct {
+	Summaries []*tanglePlugin.ConfirmationSummary `json:"summaries"`
+	Duration  int                                 `json:"duration"`
+}
+
+/////////////////// getSpamStatistics ////////////////////////
+
+// GetSpamStatistics struct
+type GetSpamStatistics struct {
+	Command string `mapstructure:"command"`
+	Limit   int    `mapstructure:"limit,omitempty"`
+}
+
+// GetSpamStatisticsReturn struct
+type GetSpamStatisticsReturn struct {
+	Sources        []*spamfilter.Source         `json:"sources"`
+	FilterCounters map[spamfilter.Filter]uint64 `json:"filterCounters"`
+	Duration       int                          `json:"duration"`
+}
+
+/////////////////// setDrainMode ////////////////////////
+
+// SetDrainMode struct
+type SetDrainMode struct {
+	Command string `mapstructure:"command"`
+	Enabled bool   `mapstructure:"enabled"`
+}
+
+// SetDrainModeReturn struct
+type SetDrainModeReturn struct {
+	Draining         bool  `json:"draining"`
+	InFlightRequests int32 `json:"inFlightRequests"`
+	Duration         int   `json:"duration"`
+}
+
 /////////////////// createSnapshotFile ////////////////////////
 
 // CreateSnapshotFile struct
@@ -358,6 +496,21 @@ type CreateSnapshotFileReturn struct {
 	Duration int `json:"duration"`
 }
 
+/////////////////// replaceStateWithSnapshot ////////////////////////
+
+// ReplaceStateWithSnapshot struct
+type ReplaceStateWithSnapshot struct {
+	Command string `mapstructure:"command"`
+	// The path of the snapshot file, the snapshot is downloaded from the configured URLs if empty.
+	FilePath string `mapstructure:"filePath"`
+}
+
+// ReplaceStateWithSnapshotReturn struct
+type ReplaceStateWithSnapshotReturn struct {
+	SnapshotIndex milestone.Index `json:"snapshotIndex"`
+	Duration      int             `json:"duration"`
+}
+
 /////////////////// pruneDatabase ////////////////////////
 
 // PruneDatabase struct
@@ -372,6 +525,15 @@ type PruneDatabaseReturn struct {
 	Duration int `json:"duration"`
 }
 
+/////////////////// getPruningAndSnapshotProgress ////////////////////////
+
+// GetPruningAndSnapshotProgressReturn struct
+type GetPruningAndSnapshotProgressReturn struct {
+	Pruning  *snapshot.Progress `json:"pruning"`
+	Snapshot *snapshot.Progress `json:"snapshot"`
+	Duration int                `json:"duration"`
+}
+
 ///////////////////// getRequests /////////////////////////////////
 
 // GetRequests struct
@@ -461,3 +623,137 @@ type AddressWithBalance struct {
 	Address trinary.Hash `mapstructure:"address"`
 	Balance uint64       `mapstructure:"balance"`
 }
+
+/////////////////// revalidateMilestoneCone //////////////////////////////
+
+// RevalidateMilestoneCone struct
+type RevalidateMilestoneCone struct {
+	Command        string          `mapstructure:"command"`
+	MilestoneIndex milestone.Index `mapstructure:"milestoneIndex"`
+}
+
+// LedgerDivergence struct
+type LedgerDivergence struct {
+	Address  trinary.Hash `json:"address"`
+	Computed int64        `json:"computed"`
+	Stored   int64        `json:"stored"`
+}
+
+// RevalidateMilestoneConeReturn struct
+type RevalidateMilestoneConeReturn struct {
+	MilestoneIndex           milestone.Index     `json:"milestoneIndex"`
+	Consistent               bool                `json:"consistent"`
+	MerkleTreeHash           string              `json:"merkleTreeHash"`
+	MilestoneMerkleTreeHash  string              `json:"milestoneMerkleTreeHash"`
+	MerkleTreeHashMatches    bool                `json:"merkleTreeHashMatches"`
+	TailsIncluded            int                 `json:"tailsIncluded"`
+	TailsExcludedConflicting int                 `json:"tailsExcludedConflicting"`
+	TailsExcludedZeroValue   int                 `json:"tailsExcludedZeroValue"`
+	TailsReferenced          int                 `json:"tailsReferenced"`
+	Divergences              []*LedgerDivergence `json:"divergences"`
+	Duration                 int                 `json:"duration"`
+}
+
+/////////////////// getFleetStatus //////////////////////////////
+
+// GetFleetStatusReturn struct
+type GetFleetStatusReturn struct {
+	Nodes    []*fleet.NodeStatus `json:"nodes"`
+	Duration int                 `json:"duration"`
+}
+
+/////////////////// getReplicaStatus //////////////////////////////
+
+// GetReplicaStatusReturn struct
+type GetReplicaStatusReturn struct {
+	*replica.Status
+	Duration int `json:"duration"`
+}
+
+/////////////////// getPeerTrafficReport //////////////////////////////
+
+// GetPeerTrafficReport struct
+type GetPeerTrafficReport struct {
+	Command    string `mapstructure:"command"`
+	From       int64  `mapstructure:"from"`
+	To         int64  `mapstructure:"to"`
+	Resolution string `mapstructure:"resolution"`
+}
+
+// PeerTrafficReport struct
+type PeerTrafficReport struct {
+	PeerID string `json:"peerId"`
+	*tangle.PeerTraffic
+}
+
+// GetPeerTrafficReportReturn struct
+type GetPeerTrafficReportReturn struct {
+	From     int64                `json:"from"`
+	To       int64                `json:"to"`
+	Peers    []*PeerTrafficReport `json:"peers"`
+	Total    *tangle.PeerTraffic  `json:"total"`
+	Duration int                  `json:"duration"`
+}
+
+/////////////////// getJobs //////////////////////////////
+
+// GetJobsReturn struct
+type GetJobsReturn struct {
+	Scheduled []*scheduler.JobInfo `json:"scheduled"`
+	Running   []*scheduler.JobInfo `json:"running"`
+	Finished  []*scheduler.JobInfo `json:"finished"`
+	Duration  int                  `json:"duration"`
+}
+
+/////////////////// cancelJob //////////////////////////////
+
+// CancelJob struct
+type CancelJob struct {
+	Command string `mapstructure:"command"`
+	ID      uint64 `mapstructure:"id"`
+}
+
+// CancelJobReturn struct
+type CancelJobReturn struct {
+	Duration int `json:"duration"`
+}
+
+/////////////////// checkDatabaseConsistency //////////////////////////////
+
+// CheckDatabaseConsistency struct
+type CheckDatabaseConsistency struct {
+	Command string `mapstructure:"command"`
+	Repair  bool   `mapstructure:"repair"`
+}
+
+// CheckDatabaseConsistencyReturn struct
+type CheckDatabaseConsistencyReturn struct {
+	JobID    uint64 `json:"jobId"`
+	Duration int    `json:"duration"`
+}
+
+/////////////////// getDatabaseConsistencyReport //////////////////////////////
+
+// GetDatabaseConsistencyReportReturn struct
+type GetDatabaseConsistencyReportReturn struct {
+	Report   *tanglePlugin.ConsistencyReport `json:"report"`
+	Duration int                             `json:"duration"`
+}
+
+/////////////////// exportIdentity //////////////////////////////
+
+// ExportIdentityReturn struct
+type ExportIdentityReturn struct {
+	PublicKey string          `json:"publicKey"`
+	Keystore  json.RawMessage `json:"keystore"`
+	Duration  int             `json:"duration"`
+}
+
+/////////////////// rotateIdentity //////////////////////////////
+
+// RotateIdentityReturn struct
+type RotateIdentityReturn struct {
+	PublicKey       string `json:"publicKey"`
+	RestartRequired bool   `json:"restartRequired"`
+	Duration        int    `json:"duration"`
+}
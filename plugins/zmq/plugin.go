@@ -5,8 +5,6 @@ import (
 
 	"github.com/iotaledger/iota.go/trinary"
 
-	"github.com/iotaledger/hive.go/daemon"
-	"github.com/iotaledger/hive.go/events"
 	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/hive.go/node"
 	"github.com/iotaledger/hive.go/timeutil"
@@ -16,6 +14,7 @@ import (
 	"github.com/gohornet/hornet/pkg/model/milestone"
 	tanglePackage "github.com/gohornet/hornet/pkg/model/tangle"
 	"github.com/gohornet/hornet/pkg/shutdown"
+	"github.com/gohornet/hornet/pkg/supervisor"
 	"github.com/gohornet/hornet/plugins/tangle"
 )
 
@@ -53,36 +52,46 @@ func configure(plugin *node.Plugin) {
 	log = logger.NewLogger(plugin.Name)
 
 	newTxWorkerPool = workerpool.New(func(task workerpool.Task) {
-		onNewTx(task.Param(0).(*tanglePackage.CachedTransaction)) // tx pass +1
+		supervisor.Protect(plugin.Name, "ZMQ[NewTxWorker]", func() {
+			onNewTx(task.Param(0).(*tanglePackage.CachedTransaction)) // tx pass +1
+		})
 		task.Return(nil)
 	}, workerpool.WorkerCount(newTxWorkerCount), workerpool.QueueSize(newTxWorkerQueueSize), workerpool.FlushTasksAtShutdown(true))
 
 	confirmedTxWorkerPool = workerpool.New(func(task workerpool.Task) {
-		onConfirmedTx(task.Param(0).(*tanglePackage.CachedMetadata), task.Param(1).(milestone.Index), task.Param(2).(int64)) // meta pass +1
+		supervisor.Protect(plugin.Name, "ZMQ[ConfirmedTxWorker]", func() {
+			onConfirmedTx(task.Param(0).(*tanglePackage.CachedMetadata), task.Param(1).(milestone.Index), task.Param(2).(int64)) // meta pass +1
+		})
 		task.Return(nil)
 	}, workerpool.WorkerCount(confirmedTxWorkerCount), workerpool.QueueSize(confirmedTxWorkerQueueSize), workerpool.FlushTasksAtShutdown(true))
 
 	newLatestMilestoneWorkerPool = workerpool.New(func(task workerpool.Task) {
-		onNewLatestMilestone(task.Param(0).(*tanglePackage.CachedBundle)) // bundle pass +1
+		supervisor.Protect(plugin.Name, "ZMQ[NewLatestMilestoneWorker]", func() {
+			onNewLatestMilestone(task.Param(0).(*tanglePackage.CachedBundle)) // bundle pass +1
+		})
 		task.Return(nil)
 	}, workerpool.WorkerCount(newLatestMilestoneWorkerCount), workerpool.QueueSize(newLatestMilestoneWorkerQueueSize), workerpool.FlushTasksAtShutdown(true))
 
 	newSolidMilestoneWorkerPool = workerpool.New(func(task workerpool.Task) {
-		onNewSolidMilestone(task.Param(0).(*tanglePackage.CachedBundle)) // bundle pass +1
+		supervisor.Protect(plugin.Name, "ZMQ[NewSolidMilestoneWorker]", func() {
+			onNewSolidMilestone(task.Param(0).(*tanglePackage.CachedBundle)) // bundle pass +1
+		})
 		task.Return(nil)
 	}, workerpool.WorkerCount(newSolidMilestoneWorkerCount), workerpool.QueueSize(newSolidMilestoneWorkerQueueSize), workerpool.FlushTasksAtShutdown(true))
 
 	spentAddressWorkerPool = workerpool.New(func(task workerpool.Task) {
-		onSpentAddress(task.Param(0).(trinary.Hash))
+		supervisor.Protect(plugin.Name, "ZMQ[SpentAddressWorker]", func() {
+			onSpentAddress(task.Param(0).(trinary.Hash))
+		})
 		task.Return(nil)
 	}, workerpool.WorkerCount(spentAddressWorkerCount), workerpool.QueueSize(spentAddressWorkerQueueSize))
 }
 
 // Start the zmq plugin
-func run(_ *node.Plugin) {
+func run(plugin *node.Plugin) {
 	log.Info("Starting ZMQ Publisher ...")
 
-	onReceivedNewTransaction := events.NewClosure(func(cachedTx *tanglePackage.CachedTransaction, latestMilestoneIndex milestone.Index, latestSolidMilestoneIndex milestone.Index) {
+	onReceivedNewTransaction := supervisor.NewClosure(plugin.Name, "ZMQ[onReceivedNewTransaction]", func(cachedTx *tanglePackage.CachedTransaction, latestMilestoneIndex milestone.Index, latestSolidMilestoneIndex milestone.Index) {
 		if !wasSyncBefore {
 			if !tanglePackage.IsNodeSyncedWithThreshold() {
 				cachedTx.Release(true) // tx -1
@@ -97,7 +106,7 @@ func run(_ *node.Plugin) {
 		cachedTx.Release(true) // tx -1
 	})
 
-	onTransactionConfirmed := events.NewClosure(func(cachedMeta *tanglePackage.CachedMetadata, msIndex milestone.Index, confTime int64) {
+	onTransactionConfirmed := supervisor.NewClosure(plugin.Name, "ZMQ[onTransactionConfirmed]", func(cachedMeta *tanglePackage.CachedMetadata, msIndex milestone.Index, confTime int64) {
 		if !wasSyncBefore {
 			// Not sync
 			cachedMeta.Release(true) // meta -1
@@ -112,7 +121,7 @@ func run(_ *node.Plugin) {
 		cachedMeta.Release(true) // meta -1
 	})
 
-	onLatestMilestoneChanged := events.NewClosure(func(cachedBndl *tanglePackage.CachedBundle) {
+	onLatestMilestoneChanged := supervisor.NewClosure(plugin.Name, "ZMQ[onLatestMilestoneChanged]", func(cachedBndl *tanglePackage.CachedBundle) {
 		if !wasSyncBefore {
 			// Not sync
 			cachedBndl.Release(true) // bundle -1
@@ -125,7 +134,7 @@ func run(_ *node.Plugin) {
 		cachedBndl.Release(true) // bundle -1
 	})
 
-	onSolidMilestoneChanged := events.NewClosure(func(cachedBndl *tanglePackage.CachedBundle) {
+	onSolidMilestoneChanged := supervisor.NewClosure(plugin.Name, "ZMQ[onSolidMilestoneChanged]", func(cachedBndl *tanglePackage.CachedBundle) {
 		if !wasSyncBefore {
 			// Not sync
 			cachedBndl.Release(true) // bundle -1
@@ -138,11 +147,11 @@ func run(_ *node.Plugin) {
 		cachedBndl.Release(true) // bundle -1
 	})
 
-	onAddressSpent := events.NewClosure(func(addr trinary.Hash) {
+	onAddressSpent := supervisor.NewClosure(plugin.Name, "ZMQ[onAddressSpent]", func(addr trinary.Hash) {
 		spentAddressWorkerPool.TrySubmit(addr)
 	})
 
-	daemon.BackgroundWorker("ZMQ Publisher", func(shutdownSignal <-chan struct{}) {
+	supervisor.BackgroundWorker(plugin.Name, "ZMQ Publisher", func(shutdownSignal <-chan struct{}) {
 		log.Info("Starting ZMQ Publisher ... done")
 		log.Infof("You can now listen to ZMQ via: %s://%s", config.NodeConfig.GetString(config.CfgZMQProtocol), config.NodeConfig.GetString(config.CfgZMQBindAddress))
 
@@ -162,11 +171,11 @@ func run(_ *node.Plugin) {
 		}
 	}, shutdown.PriorityMetricsPublishers)
 
-	daemon.BackgroundWorker("ZMQ address topic updater", func(shutdownSignal <-chan struct{}) {
+	supervisor.BackgroundWorker(plugin.Name, "ZMQ address topic updater", func(shutdownSignal <-chan struct{}) {
 		timeutil.Ticker(updateAddressTopics, 5*time.Second, shutdownSignal)
 	}, shutdown.PriorityMetricsPublishers)
 
-	daemon.BackgroundWorker("ZMQ[NewTxWorker]", func(shutdownSignal <-chan struct{}) {
+	supervisor.BackgroundWorker(plugin.Name, "ZMQ[NewTxWorker]", func(shutdownSignal <-chan struct{}) {
 		log.Info("Starting ZMQ[NewTxWorker] ... done")
 		tangle.Events.ReceivedNewTransaction.Attach(onReceivedNewTransaction)
 		newTxWorkerPool.Start()
@@ -177,7 +186,7 @@ func run(_ *node.Plugin) {
 		log.Info("Stopping ZMQ[NewTxWorker] ... done")
 	}, shutdown.PriorityMetricsPublishers)
 
-	daemon.BackgroundWorker("ZMQ[ConfirmedTxWorker]", func(shutdownSignal <-chan struct{}) {
+	supervisor.BackgroundWorker(plugin.Name, "ZMQ[ConfirmedTxWorker]", func(shutdownSignal <-chan struct{}) {
 		log.Info("Starting ZMQ[ConfirmedTxWorker] ... done")
 		tangle.Events.TransactionConfirmed.Attach(onTransactionConfirmed)
 		confirmedTxWorkerPool.Start()
@@ -188,7 +197,7 @@ func run(_ *node.Plugin) {
 		log.Info("Stopping ZMQ[ConfirmedTxWorker] ... done")
 	}, shutdown.PriorityMetricsPublishers)
 
-	daemon.BackgroundWorker("ZMQ[NewLatestMilestoneWorker]", func(shutdownSignal <-chan struct{}) {
+	supervisor.BackgroundWorker(plugin.Name, "ZMQ[NewLatestMilestoneWorker]", func(shutdownSignal <-chan struct{}) {
 		log.Info("Starting ZMQ[NewLatestMilestoneWorker] ... done")
 		tangle.Events.LatestMilestoneChanged.Attach(onLatestMilestoneChanged)
 		newLatestMilestoneWorkerPool.Start()
@@ -199,7 +208,7 @@ func run(_ *node.Plugin) {
 		log.Info("Stopping ZMQ[NewLatestMilestoneWorker] ... done")
 	}, shutdown.PriorityMetricsPublishers)
 
-	daemon.BackgroundWorker("ZMQ[NewSolidMilestoneWorker]", func(shutdownSignal <-chan struct{}) {
+	supervisor.BackgroundWorker(plugin.Name, "ZMQ[NewSolidMilestoneWorker]", func(shutdownSignal <-chan struct{}) {
 		log.Info("Starting ZMQ[NewSolidMilestoneWorker] ... done")
 		tangle.Events.SolidMilestoneChanged.Attach(onSolidMilestoneChanged)
 		newSolidMilestoneWorkerPool.Start()
@@ -210,7 +219,7 @@ func run(_ *node.Plugin) {
 		log.Info("Stopping ZMQ[NewSolidMilestoneWorker] ... done")
 	}, shutdown.PriorityMetricsPublishers)
 
-	daemon.BackgroundWorker("ZMQ[SpentAddress]", func(shutdownSignal <-chan struct{}) {
+	supervisor.BackgroundWorker(plugin.Name, "ZMQ[SpentAddress]", func(shutdownSignal <-chan struct{}) {
 		log.Info("Starting ZMQ[SpentAddress] ... done")
 		tanglePackage.Events.AddressSpent.Attach(onAddressSpent)
 		spentAddressWorkerPool.Start()